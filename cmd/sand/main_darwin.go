@@ -5,12 +5,12 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net"
 	"os"
 	"os/exec"
-	"os/signal"
 	"path/filepath"
 	"strings"
 	"syscall"
@@ -20,6 +20,7 @@ import (
 	kongyaml "github.com/alecthomas/kong-yaml"
 	"github.com/banksean/sand/internal/cli"
 	"github.com/banksean/sand/internal/daemon"
+	"github.com/banksean/sand/internal/hostops"
 	"github.com/banksean/sand/internal/observability"
 	"github.com/banksean/sand/internal/runtimedeps"
 	"github.com/banksean/sand/internal/sandboxlog"
@@ -31,33 +32,49 @@ type Outie struct {
 	LogFile    string                    `default:"/tmp/sand/outie/log" placeholder:"<log-file-path>" help:"location of log file (leave empty for a random tmp/ path)"`
 	LogLevel   string                    `default:"info" placeholder:"<debug|info|warn|error>" help:"the logging level (debug, info, warn, error)"`
 	AppBaseDir string                    `default:"" placeholder:"<app-base-dir>" help:"root dir to store sandbox clones of working directories. Leave unset to use '~/Library/Application Support/Sand'"`
-	Timeout    time.Duration             `default:"0s" help:"if set to anything other than 0s, overrides the default timeout for an operation"`
-	Completion kongcompletion.Completion `cmd:"" help:"Outputs shell code for initialising tab completions"`
+	Timeout    time.Duration             `default:"0s" help:"give up on the command, killing anything it started, after this long (0s waits indefinitely)"`
+	Completion kongcompletion.Completion `cmd:"" aliases:"completions" help:"Outputs shell code for initialising tab completions (bash, zsh or fish)"`
 	Version    cli.VersionFlag           `name:"version" help:"Print version and exit."`
 	DryRun     bool                      `default:"false" help:"just print out the operations instead of executing them"`
+	Quiet      bool                      `short:"q" xor:"verbosity" help:"only print notices and errors while preparing sandboxes, not progress"`
+	Verbose    bool                      `short:"v" xor:"verbosity" help:"print every step taken while preparing sandboxes, such as each dotfile copied"`
 	Caches     cli.CacheFlags            `embed:"" prefix:"caches-"`
+	Daemon     cli.DaemonFlags           `embed:"" prefix:"daemon-"`
 
 	New                cli.NewCmd                `cmd:"" help:"create a new sandbox and shell into its container"`
 	Oneshot            cli.OneshotCmd            `cmd:"" help:"run an AI agent non-interactively with a prompt"`
 	Shell              cli.ShellCmd              `cmd:"" help:"shell into a sandbox container (and start the container, if necessary)"`
 	Exec               cli.ExecCmd               `cmd:"" help:"execute a single command in a sandbox"`
 	Ls                 cli.LsCmd                 `cmd:"" help:"list sandboxes"`
+	Get                cli.GetCmd                `cmd:"" help:"print details about a sandbox"`
+	Attach             cli.AttachCmd             `cmd:"" help:"print how to ssh into a sandbox, for tools that open their own connections"`
+	Env                cli.EnvCmd                `cmd:"" help:"print the environment commands see in a sandbox"`
 	Log                cli.SandboxLogCmd         `cmd:"" help:"print sandbox lifecycle and daemon events"`
 	Rm                 cli.RmCmd                 `cmd:"" help:"remove sandbox container and its clone directory"`
 	Expunge            cli.ExpungeCmd            `cmd:"" help:"hard-delete soft-deleted sandboxes"`
 	Recover            cli.RecoverCmd            `cmd:"" help:"recover a soft-deleted sandbox"`
 	Stop               cli.StopCmd               `cmd:"" help:"stop sandbox container"`
+	Kill               cli.KillCmd               `cmd:"" help:"kill a sandbox container that won't stop"`
 	Start              cli.StartCmd              `cmd:"" help:"start sandbox container"`
 	Rename             cli.RenameCmd             `cmd:"" help:"rename a stopped sandbox"`
+	Clone              cli.CloneCmd              `cmd:"" help:"create a new sandbox from a copy of an existing one"`
+	Snapshot           cli.SnapshotCmd           `cmd:"" help:"save a sandbox's working tree as a restore point"`
+	Restore            cli.RestoreCmd            `cmd:"" help:"roll a sandbox's working tree back to a snapshot"`
 	Git                cli.GitCmd                `cmd:"" help:"git operations with sandboxes"`
 	Cache              cli.CacheCmd              `cmd:"" help:"manage shared cache services"`
+	Image              cli.ImageCmd              `cmd:"" help:"manage container images used by sandboxes"`
 	Doc                DocCmd                    `cmd:"" help:"print complete command help formatted as markdown"`
 	BuildInfo          cli.BuildInfoCmd          `cmd:"" help:"print version information about this command"`
 	Vsc                cli.VscCmd                `cmd:"" help:"launch a vscode remote window connected to the sandbox's container"`
 	InstallEBPFSupport cli.InstallEBPFSupportCmd `cmd:"" help:"install the BPFFS-enabled kernel build"`
 	ExportFS           cli.ExportCmd             `cmd:"" help:"export a container's filesystem"`
 	Stats              cli.StatsCmd              `cmd:"" help:"list container stats for sandboxes"`
+	Df                 cli.DfCmd                 `cmd:"" help:"report how much disk space sandbox clone directories use"`
+	Sync               cli.SyncSandboxesCmd      `cmd:"" help:"re-check every sandbox's clone directory and container and report the degraded ones"`
+	Watch              cli.WatchCmd              `cmd:"" help:"stream sandbox lifecycle events as they happen"`
+	Forward            cli.ForwardCmd            `cmd:"" help:"forward a port between the host and a sandbox container over ssh"`
 	Config             cli.ConfigCmd             `cmd:"" help:"list, get, or set default values for flags"`
+	Doctor             cli.DoctorCmd             `cmd:"" help:"check that sand's dependencies and configuration are healthy"`
 }
 
 func (c *Outie) initSlog() {
@@ -167,7 +184,7 @@ func resolveSanddPath() (string, error) {
 
 // ensureDaemon attempts to verify that the sandd daemon is running, and if not,
 // starts a new instance of it.
-func ensureDaemon(ctx context.Context, appBaseDir string) error {
+func ensureDaemon(ctx context.Context, appBaseDir string, daemonFlags cli.DaemonFlags) error {
 	socketPath := filepath.Join(appBaseDir, daemon.DefaultGRPCSocketFile)
 	slog.Info("EnsureDaemon", "socketPath", socketPath)
 
@@ -194,7 +211,8 @@ func ensureDaemon(ctx context.Context, appBaseDir string) error {
 	if err != nil {
 		return err
 	}
-	cmd := exec.Command(sanddPath, "start", "--app-base-dir", appBaseDir)
+	args := append([]string{"start", "--app-base-dir", appBaseDir}, daemonFlags.SanddArgs()...)
+	cmd := exec.Command(sanddPath, args...)
 	slog.Info("EnsureDaemon", "cmd", strings.Join(cmd.Args, " "))
 	cmd.Stdout = nil
 	cmd.Stderr = nil
@@ -258,13 +276,11 @@ func main() {
 	var app Outie
 	slog.SetLogLoggerLevel(slog.LevelError)
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
 	// Catch control-C so if you break out of "sand new" because it's taking too long
 	// to download a container image (which runs in exec.CommandContext subprocess),
 	// we also kill any subprocesses that were started with ctx.
-	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
-	defer stop()
+	ctx, cancel := cli.NotifyContext(context.Background())
+	defer cancel()
 
 	kongApp := kong.Must(&app)
 	namePredictor := cli.NewLazySandboxNamePredictor(func() (daemon.Client, error) {
@@ -320,27 +336,33 @@ func main() {
 		}()
 	}
 
-	if err := runtimedeps.VerifyWithOptions(ctx,
-		appBaseDir,
-		runtimedeps.VerifyOptions{
-			Stdin:            os.Stdin,
-			Stdout:           os.Stdout,
-			PromptRemedies:   true,
-			DefaultDNSDomain: runtimedeps.DefaultDNSDomain,
-		},
-		runtimedeps.MacOS,
-		runtimedeps.MacOSVersion,
-		runtimedeps.ContainerSystemRunning,
-		runtimedeps.ContainerCommand,
-		runtimedeps.ContainerSystemDNSDomain,
-		runtimedeps.ContainerSystemDNSRegistration); err != nil {
-		fmt.Fprintf(os.Stderr, "error: %s\n", err)
-		os.Exit(1)
+	// doctor reports on the same prerequisites and the daemon itself, so it
+	// must not exit early when they are broken.
+	doctor := kongCtx.Command() == "doctor"
+
+	if !doctor {
+		if err := runtimedeps.VerifyWithOptions(ctx,
+			appBaseDir,
+			runtimedeps.VerifyOptions{
+				Stdin:            os.Stdin,
+				Stdout:           os.Stdout,
+				PromptRemedies:   true,
+				DefaultDNSDomain: runtimedeps.DefaultDNSDomain,
+			},
+			runtimedeps.MacOS,
+			runtimedeps.MacOSVersion,
+			runtimedeps.ContainerSystemRunning,
+			runtimedeps.ContainerCommand,
+			runtimedeps.ContainerSystemDNSDomain,
+			runtimedeps.ContainerSystemDNSRegistration); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %s\n", err)
+			os.Exit(1)
+		}
 	}
 
 	slog.Info("main", "appBaseDir", appBaseDir)
 
-	if err := ensureDaemon(ctx, appBaseDir); err != nil {
+	if err := ensureDaemon(ctx, appBaseDir, app.Daemon); err != nil && !doctor {
 		fmt.Fprintf(os.Stderr, "daemon not running, and failed to start it. error: %v\n", err)
 		os.Exit(1)
 	}
@@ -350,10 +372,8 @@ func main() {
 		fmt.Fprintf(os.Stderr, "Failed to create sandd client, error: %v\n", err)
 		os.Exit(1)
 	}
-	if app.Timeout != 0 {
-		ctx, cancel = context.WithTimeout(ctx, app.Timeout)
-		defer cancel()
-	}
+	ctx, cancelTimeout := cli.WithTimeout(ctx, app.Timeout)
+	defer cancelTimeout()
 
 	err = kongCtx.Run(&cli.CLIContext{
 		Daemon:       mc,
@@ -363,6 +383,13 @@ func main() {
 		LogLevel:     app.LogLevel,
 		CloneRoot:    app.AppBaseDir,
 		SharedCaches: app.Caches.SharedCacheConfig(),
+		MessageLevel: hostops.MessageLevelFor(app.Quiet, app.Verbose),
 	})
+	err = cli.CommandError(ctx, err)
+	// The remote command has already reported its own failure.
+	var exitStatus *cli.ExitStatus
+	if errors.As(err, &exitStatus) {
+		os.Exit(exitStatus.Code)
+	}
 	kongCtx.FatalIfErrorf(err)
 }
@@ -7,15 +7,15 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
-	"os/signal"
 	"path/filepath"
-	"syscall"
+	"time"
 
 	"github.com/alecthomas/kong"
 	kongyaml "github.com/alecthomas/kong-yaml"
 	"github.com/banksean/sand/internal/cli"
 	"github.com/banksean/sand/internal/daemon"
 	"github.com/banksean/sand/internal/observability"
+	"github.com/banksean/sand/internal/runtimepaths"
 	kongcompletion "github.com/jotaen/kong-completion"
 )
 
@@ -32,7 +32,8 @@ type Innie struct {
 	LogFile    string                    `default:"/tmp/sand/innie/log" placeholder:"<log-file-path>" help:"location of log file (leave empty for a random tmp/ path)"`
 	LogLevel   string                    `default:"info" placeholder:"<debug|info|warn|error>" help:"the logging level (debug, info, warn, error)"`
 	AppBaseDir string                    `default:"" placeholder:"<app-base-dir>" help:"root dir to store sandbox clones of working directories. Leave unset to use '~/Library/Application Support/Sand'"`
-	Completion kongcompletion.Completion `cmd:"" help:"Outputs shell code for initialising tab completions"`
+	Timeout    time.Duration             `default:"0s" help:"give up on the command, killing anything it started, after this long (0s waits indefinitely)"`
+	Completion kongcompletion.Completion `cmd:"" aliases:"completions" help:"Outputs shell code for initialising tab completions (bash, zsh or fish)"`
 	Version    cli.VersionFlag           `name:"version" help:"Print version and exit."`
 	Caches     cli.CacheFlags            `embed:"" prefix:"caches-"`
 
@@ -43,6 +44,7 @@ type Innie struct {
 	Expunge   cli.ExpungeCmd    `cmd:"" help:"hard-delete soft-deleted sandboxes"`
 	Recover   cli.RecoverCmd    `cmd:"" help:"recover a soft-deleted sandbox"`
 	Stop      cli.StopCmd       `cmd:"" help:"stop sandbox container"`
+	Kill      cli.KillCmd       `cmd:"" help:"kill a sandbox container that won't stop"`
 	Git       cli.GitCmd        `cmd:"" help:"git operations with sandboxes"`
 	Cache     cli.CacheCmd      `cmd:"" help:"manage shared cache services"`
 	BuildInfo cli.BuildInfoCmd  `cmd:"" help:"print version information about this command"`
@@ -96,18 +98,16 @@ func main() {
 	var app Innie
 	slog.SetLogLoggerLevel(slog.LevelError)
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
 	// Catch control-C so if you break out of "sand new" because it's taking too long
 	// to download a container image (which runs in exec.CommandContext subprocess),
 	// we also kill any subprocesses that were started with ctx.
-	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
-	defer stop()
+	ctx, cancel := cli.NotifyContext(context.Background())
+	defer cancel()
 
 	// connect to the sandd process running on the host via unix domain socket.
 	// The sandd.grpc.sock file in this directory should have been created by sandd
 	// and attached to this container via --mount flag.
-	appBaseDir := "/run/host-services"
+	appBaseDir := runtimepaths.HostServicesDir
 
 	shutdownTracing, tracingEnabled, err := observability.InitTracing(ctx, "sand-innie")
 	if err != nil {
@@ -154,6 +154,9 @@ func main() {
 		fmt.Fprintf(os.Stderr, "Failed to create sandd client, error: %v\n", err)
 		os.Exit(1)
 	}
+	ctx, cancelTimeout := cli.WithTimeout(ctx, app.Timeout)
+	defer cancelTimeout()
+
 	err = kongCtx.Run(&cli.CLIContext{
 		Daemon:       mc,
 		Context:      ctx,
@@ -163,5 +166,5 @@ func main() {
 		CloneRoot:    app.AppBaseDir,
 		SharedCaches: app.Caches.SharedCacheConfig(),
 	})
-	kongCtx.FatalIfErrorf(err)
+	kongCtx.FatalIfErrorf(cli.CommandError(ctx, err))
 }
@@ -11,6 +11,7 @@ import (
 	"os/signal"
 	"path/filepath"
 	"syscall"
+	"time"
 
 	"github.com/alecthomas/kong"
 	kongyaml "github.com/alecthomas/kong-yaml"
@@ -43,16 +44,28 @@ type App struct {
 }
 
 type DaemonCmd struct {
-	LogFile    string          `default:"/tmp/sand/daemon/log" placeholder:"<log-file-path>" help:"location of log file"`
-	LogLevel   string          `default:"info" placeholder:"<debug|info|warn|error>" help:"the logging level (debug, info, warn, error)"`
-	AppBaseDir string          `default:"" placeholder:"<app-base-dir>" help:"root dir to store sandbox clones of working directories. Leave unset to use '~/Library/Application Support/Sand'"`
-	Version    cli.VersionFlag `name:"version" help:"Print version and exit."`
-	Action     string          `arg:"" optional:"" default:"status" enum:"start,stop,status,build-info" help:"Action to perform: start, stop, or status (default). Shows daemon status if omitted."`
+	LogFile               string          `default:"/tmp/sand/daemon/log" placeholder:"<log-file-path>" help:"location of log file"`
+	LogLevel              string          `default:"info" placeholder:"<debug|info|warn|error>" help:"the logging level (debug, info, warn, error)"`
+	AppBaseDir            string          `default:"" placeholder:"<app-base-dir>" help:"root dir to store sandbox clones of working directories. Leave unset to use '~/Library/Application Support/Sand'"`
+	IdleTimeout           time.Duration   `default:"0" placeholder:"<duration>" help:"stop sandbox containers that have not been used for this long, e.g. 2h (0 disables)"`
+	IdleCheckInterval     time.Duration   `default:"5m" placeholder:"<duration>" help:"how often to check for idle sandbox containers"`
+	ShutdownGracePeriod   time.Duration   `default:"30s" placeholder:"<duration>" help:"how long to let in-flight requests finish when stopping before closing them"`
+	ContainerReadyTimeout time.Duration   `default:"30s" placeholder:"<duration>" help:"how long a started sandbox container has to become ready before its start hooks run"`
+	ContainerCacheTTL     time.Duration   `default:"2s" placeholder:"<duration>" help:"how long to reuse container status when listing sandboxes before asking the container service again (0 disables)"`
+	MetricsAddr           string          `default:"" placeholder:"<host:port>" help:"serve Prometheus metrics at http://<host:port>/metrics (empty disables)"`
+	Version               cli.VersionFlag `name:"version" help:"Print version and exit."`
+	Follow                bool            `short:"f" help:"with logs, keep printing new log lines as they are written"`
+	Lines                 int             `short:"n" default:"100" placeholder:"<count>" help:"with logs, how many of the most recent log lines to print first"`
+	Action                string          `arg:"" optional:"" default:"status" enum:"start,stop,status,logs,build-info" help:"Action to perform: start, stop, status (default) or logs. Shows daemon status if omitted."`
 }
 
 // Run handles all daemon command variants
 func (c *DaemonCmd) Run(cctx *App) error {
 	ctx := cctx.Context
+	if c.Action == "logs" {
+		// Reading the log doesn't need the container system, so skip its checks.
+		return daemon.TailLog(ctx, c.LogFile, os.Stdout, c.Lines, c.Follow)
+	}
 
 	localDomain, err := runtimedeps.EffectiveDNSDomain(ctx, runtimedeps.VerifyOptions{
 		DefaultDNSDomain: runtimedeps.DefaultDNSDomain,
@@ -63,6 +76,14 @@ func (c *DaemonCmd) Run(cctx *App) error {
 	slog.InfoContext(ctx, "DaemonCmd.Run", "localDomain", localDomain)
 	server := daemon.NewDaemon(cctx.AppBaseDir, localDomain)
 	server.LogFile = cctx.LogFile
+	server.IdleStop = daemon.IdleStopOptions{
+		Timeout:  c.IdleTimeout,
+		Interval: c.IdleCheckInterval,
+	}
+	server.ShutdownGracePeriod = c.ShutdownGracePeriod
+	server.ContainerReadyTimeout = c.ContainerReadyTimeout
+	server.ContainerCacheTTL = c.ContainerCacheTTL
+	server.MetricsAddr = c.MetricsAddr
 
 	switch c.Action {
 	case "start":
@@ -149,19 +170,12 @@ func (c *DaemonCmd) cliVersion(ctx context.Context) error {
 }
 
 func (c *DaemonCmd) checkStatus(ctx context.Context, server *daemon.Daemon) error {
-	mc, err := daemon.NewUnixSocketClient(ctx, c.AppBaseDir)
-	if err != nil {
-		fmt.Println("Daemon is not running")
-		return nil
+	var client daemon.Client
+	if mc, err := daemon.NewUnixSocketClient(ctx, c.AppBaseDir); err == nil {
+		client = mc
 	}
-
-	if err := mc.Ping(ctx); err != nil {
-		fmt.Println("Daemon is not running")
-		return nil
-	}
-
-	fmt.Println("Daemon is running")
-	return nil
+	status := daemon.GetStatus(ctx, server.AppBaseDir, c.LogFile, client)
+	return status.Write(os.Stdout, time.Now())
 }
 
 func (c *DaemonCmd) startDaemon(ctx context.Context, server *daemon.Daemon) error {
@@ -547,6 +547,23 @@ func (c *Client) PullImage(ctx context.Context, reference string, opts ImagePull
 	return image, nil
 }
 
+// DeleteImage deletes the image named reference. With garbageCollect set, the
+// image service also removes content no remaining image refers to.
+func (c *Client) DeleteImage(ctx context.Context, reference string, garbageCollect bool) error {
+	if reference == "" {
+		return fmt.Errorf("image reference cannot be empty")
+	}
+	_, err := c.Send(ctx, XPCRouteImageDelete, func(message *Message) error {
+		message.SetString(XPCKeyImageReference, reference)
+		message.SetBool(XPCKeyGarbageCollect, garbageCollect)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("delete image %q: %w", reference, err)
+	}
+	return nil
+}
+
 func (c *Client) GetContentPath(ctx context.Context, digest string) (string, error) {
 	if digest == "" {
 		return "", fmt.Errorf("digest cannot be empty")
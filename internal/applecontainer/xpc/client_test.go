@@ -216,6 +216,30 @@ func TestGetContentPath(t *testing.T) {
 	}
 }
 
+func TestDeleteImage(t *testing.T) {
+	sender := &fakeSender{handler: func(request *Message) (*Message, error) {
+		if request.Route() != XPCRouteImageDelete {
+			t.Fatalf("route = %q", request.Route())
+		}
+		assertStringKey(t, request, XPCKeyImageReference, "example.com/app:latest")
+		if !request.Bool(XPCKeyGarbageCollect) {
+			t.Fatal("garbageCollect not set")
+		}
+		return newEmptyMessage(), nil
+	}}
+	client := newFakeClient(t, sender)
+
+	if err := client.DeleteImage(context.Background(), "example.com/app:latest", true); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.DeleteImage(context.Background(), "", true); err == nil {
+		t.Fatal("DeleteImage(\"\") error = nil, want error")
+	}
+	if len(sender.requests) != 1 {
+		t.Fatalf("sent %d requests, want 1", len(sender.requests))
+	}
+}
+
 func TestContainerScalarRoutes(t *testing.T) {
 	tests := []struct {
 		name  string
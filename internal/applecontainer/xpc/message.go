@@ -221,6 +221,12 @@ type XPCError struct {
 	Message string `json:"message"`
 }
 
+// Codes ContainerXPC sets on an XPCError, from ContainerizationError.Code.
+const (
+	XPCErrorCodeExists   = "exists"
+	XPCErrorCodeNotFound = "notFound"
+)
+
 func (e XPCError) Error() string {
 	if e.Code == "" {
 		return e.Message
@@ -1096,6 +1096,7 @@ const (
 	XPCKeyImageDescriptions               XPCKey = "imageDescriptions"
 	XPCKeyImageDescription                XPCKey = "imageDescription"
 	XPCKeyImageReference                  XPCKey = "imageReference"
+	XPCKeyGarbageCollect                  XPCKey = "garbageCollect"
 	XPCKeyOCIPlatform                     XPCKey = "ociPlatform"
 	XPCKeyInsecureFlag                    XPCKey = "insecureFlag"
 	XPCKeyMaxConcurrentDownloads          XPCKey = "maxConcurrentDownloads"
@@ -1160,6 +1161,7 @@ const (
 	XPCRouteContainerExport        XPCRoute = "containerExport"
 	XPCRouteImageList              XPCRoute = "imageList"
 	XPCRouteImagePull              XPCRoute = "imagePull"
+	XPCRouteImageDelete            XPCRoute = "imageDelete"
 	XPCRouteContentGet             XPCRoute = "contentGet"
 	XPCRouteNetworkCreate          XPCRoute = "networkCreate"
 	XPCRouteNetworkDelete          XPCRoute = "networkDelete"
@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"strings"
+
+	"github.com/banksean/sand/internal/sandtypes"
+	"github.com/banksean/sand/internal/sshimmer"
+)
+
+var attachCmdStdout io.Writer = os.Stdout
+
+type AttachCmd struct {
+	SandboxNameFlag
+	JSON bool `name:"json" help:"print the connection details as a JSON object instead of an ssh command"`
+}
+
+// sshConnectionDetails is what an external tool needs to open its own ssh
+// connection into a sandbox.
+type sshConnectionDetails struct {
+	Sandbox         string `json:"sandbox"`
+	Host            string `json:"host"`
+	User            string `json:"user"`
+	IdentityFile    string `json:"identityFile"`
+	CertificateFile string `json:"certificateFile"`
+	KnownHostsFile  string `json:"knownHostsFile"`
+	SSHConfigFile   string `json:"sshConfigFile"`
+}
+
+func (c *AttachCmd) Run(cctx *CLIContext) error {
+	sbox, err := cctx.Daemon.GetSandbox(cctx.Context, c.SandboxName)
+	if err != nil {
+		return err
+	}
+	if sbox == nil {
+		return fmt.Errorf("sandbox not found: %s", c.SandboxName)
+	}
+	details, err := sandboxSSHConnection(sbox)
+	if err != nil {
+		return err
+	}
+	if c.JSON {
+		return writeJSON(attachCmdStdout, details)
+	}
+	_, err = fmt.Fprintln(attachCmdStdout, strings.Join(details.sshCommand(), " "))
+	return err
+}
+
+func sandboxSSHConnection(sbox *sandtypes.Box) (*sshConnectionDetails, error) {
+	if sbox.NoSSH {
+		return nil, fmt.Errorf("sandbox %s was created with --no-ssh and has no sshd to connect to", sbox.Name)
+	}
+	if sbox.Container == nil || sbox.Container.Status.State != "running" {
+		return nil, fmt.Errorf("sandbox %s is not running; start it with sand start %s", sbox.Name, sbox.Name)
+	}
+	username := sbox.Username
+	if username == "" {
+		// Legacy sandboxes may not have a stored username; shells use the current user.
+		u, err := user.Current()
+		if err != nil {
+			return nil, err
+		}
+		username = u.Username
+	}
+	files := sshimmer.UserClientFiles(username)
+	return &sshConnectionDetails{
+		Sandbox:         sbox.Name,
+		Host:            sandtypes.GetContainerHostname(sbox.Container),
+		User:            username,
+		IdentityFile:    files.IdentityFile,
+		CertificateFile: files.CertificateFile,
+		KnownHostsFile:  files.KnownHostsFile,
+		SSHConfigFile:   files.ConfigFile,
+	}, nil
+}
+
+// sshCommand is an ssh invocation that connects with d's files alone, without
+// relying on sand's Include in ~/.ssh/config.
+func (d *sshConnectionDetails) sshCommand() []string {
+	return []string{
+		"ssh",
+		"-i", shellQuote(d.IdentityFile),
+		"-o", shellQuote("CertificateFile=" + d.CertificateFile),
+		"-o", shellQuote("UserKnownHostsFile=" + d.KnownHostsFile),
+		shellQuote(d.User + "@" + d.Host),
+	}
+}
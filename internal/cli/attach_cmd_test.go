@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/banksean/sand/internal/daemon/daemontest"
+	"github.com/banksean/sand/internal/hostops"
+	"github.com/banksean/sand/internal/sandtypes"
+	"github.com/banksean/sand/internal/sshimmer"
+)
+
+// runAttachCmd runs sand attach against a daemon holding box, whose container
+// inspects as state, and returns what it printed.
+func runAttachCmd(t *testing.T, box *sandtypes.Box, state string, jsonOut bool) (string, error) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	client := daemontest.StartDaemon(t, daemontest.Deps{
+		ContainerService: &hostops.MockContainerOps{
+			InspectFunc: func(_ context.Context, containerID string) ([]sandtypes.Container, error) {
+				return []sandtypes.Container{{
+					Status:   sandtypes.ContainerStatus{State: state},
+					Networks: []sandtypes.ContainerNetworkStatus{{Network: "default", Hostname: "attach-box.sand.test"}},
+				}}, nil
+			},
+		},
+	}, func(ctx context.Context, s daemontest.SandboxStore) {
+		s.SaveSandbox(ctx, box)
+	})
+	var stdout bytes.Buffer
+	prev := attachCmdStdout
+	attachCmdStdout = &stdout
+	t.Cleanup(func() { attachCmdStdout = prev })
+
+	cmd := &AttachCmd{SandboxNameFlag: SandboxNameFlag{SandboxName: box.Name}, JSON: jsonOut}
+	err := cmd.Run(&CLIContext{Context: context.Background(), Daemon: client})
+	return stdout.String(), err
+}
+
+func attachTestBox() *sandtypes.Box {
+	box := newTestBox("attach-box")
+	box.Name = "attach-box"
+	box.Username = "dev"
+	return box
+}
+
+func TestAttachCmdJSONMatchesSSHimmerFiles(t *testing.T) {
+	out, err := runAttachCmd(t, attachTestBox(), "running", true)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	var got sshConnectionDetails
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("unmarshal %q: %v", out, err)
+	}
+	files := sshimmer.UserClientFiles("dev")
+	want := sshConnectionDetails{
+		Sandbox:         "attach-box",
+		Host:            "attach-box.sand.test",
+		User:            "dev",
+		IdentityFile:    files.IdentityFile,
+		CertificateFile: files.CertificateFile,
+		KnownHostsFile:  files.KnownHostsFile,
+		SSHConfigFile:   files.ConfigFile,
+	}
+	if got != want {
+		t.Fatalf("details = %+v, want %+v", got, want)
+	}
+}
+
+func TestAttachCmdPrintsSSHCommand(t *testing.T) {
+	out, err := runAttachCmd(t, attachTestBox(), "running", false)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	files := sshimmer.UserClientFiles("dev")
+	for _, want := range []string{
+		"ssh -i " + shellQuote(files.IdentityFile),
+		shellQuote("UserKnownHostsFile=" + files.KnownHostsFile),
+		shellQuote("dev@attach-box.sand.test"),
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output = %q, want %q", out, want)
+		}
+	}
+}
+
+func TestAttachCmdRefusesUnreachableSandboxes(t *testing.T) {
+	noSSH := attachTestBox()
+	noSSH.NoSSH = true
+	for _, tc := range []struct {
+		name  string
+		box   *sandtypes.Box
+		state string
+		want  string
+	}{
+		{name: "stopped", box: attachTestBox(), state: "stopped", want: "is not running"},
+		{name: "no ssh", box: noSSH, state: "running", want: "--no-ssh"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := runAttachCmd(t, tc.box, tc.state, true)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("Run() error = %v, want %q", err, tc.want)
+			}
+			if out != "" {
+				t.Fatalf("output = %q, want nothing", out)
+			}
+		})
+	}
+}
@@ -1,18 +1,70 @@
 package cli
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/banksean/sand/internal/version"
 )
 
-type BuildInfoCmd struct{}
+const (
+	// releaseCheckTimeout bounds how long --check waits on GitHub.
+	releaseCheckTimeout = 5 * time.Second
+	// releaseCheckTTL is how long --check reuses the last release it fetched.
+	releaseCheckTTL = time.Hour
+)
+
+var (
+	buildInfoCmdStdout   io.Writer = os.Stdout
+	latestReleaseFetcher           = func(cctx *CLIContext) version.ReleaseFetcher {
+		return &version.CachedReleaseFetcher{
+			Fetcher: &version.GitHubReleaseFetcher{},
+			Path:    filepath.Join(cctx.AppBaseDir, "latest-release.json"),
+			TTL:     releaseCheckTTL,
+		}
+	}
+)
+
+type BuildInfoCmd struct {
+	Check bool `help:"also check GitHub for a newer release of sand (the answer is cached for an hour)"`
+}
 
 func (c *BuildInfoCmd) Run(cctx *CLIContext) error {
 	printBuildInfo()
+	if !c.Check {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(cctx.Context, releaseCheckTimeout)
+	defer cancel()
+	check, err := version.CheckForUpdate(ctx, version.Get(), latestReleaseFetcher(cctx))
+	if err != nil {
+		// Being offline shouldn't make build-info fail.
+		fmt.Fprintf(buildInfoCmdStdout, "Could not check for a newer release: %v\n", err)
+		return nil
+	}
+	printUpdateCheck(buildInfoCmdStdout, check)
 	return nil
 }
 
+func printUpdateCheck(w io.Writer, check version.UpdateCheck) {
+	switch {
+	case !check.Comparable:
+		fmt.Fprintf(w, "Latest Release: %s (this build, %q, is not a release)\n", check.Latest.Tag, check.Current)
+	case check.UpdateAvailable:
+		fmt.Fprintf(w, "Update Available: %s -> %s\n", check.Current, check.Latest.Tag)
+	default:
+		fmt.Fprintf(w, "Up To Date: %s is the latest release\n", check.Current)
+		return
+	}
+	if check.Latest.URL != "" {
+		fmt.Fprintf(w, "Release URL: %s\n", check.Latest.URL)
+	}
+}
+
 func printBuildInfo() {
 	versionInfo := version.Get()
 	if versionInfo.DevBuild {
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/banksean/sand/internal/version"
+)
+
+type stubReleaseFetcher struct {
+	release version.Release
+	err     error
+}
+
+func (f stubReleaseFetcher) LatestRelease(context.Context) (version.Release, error) {
+	return f.release, f.err
+}
+
+func runBuildInfoCheck(t *testing.T, fetcher version.ReleaseFetcher) string {
+	t.Helper()
+	var stdout bytes.Buffer
+	oldStdout, oldFetcher := buildInfoCmdStdout, latestReleaseFetcher
+	buildInfoCmdStdout = &stdout
+	latestReleaseFetcher = func(*CLIContext) version.ReleaseFetcher { return fetcher }
+	t.Cleanup(func() { buildInfoCmdStdout, latestReleaseFetcher = oldStdout, oldFetcher })
+
+	if err := (&BuildInfoCmd{Check: true}).Run(&CLIContext{Context: context.Background(), AppBaseDir: t.TempDir()}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	return stdout.String()
+}
+
+func TestBuildInfoCheckToleratesBeingOffline(t *testing.T) {
+	out := runBuildInfoCheck(t, stubReleaseFetcher{err: errors.New("dial tcp: no route to host")})
+	if !strings.Contains(out, "Could not check for a newer release: dial tcp: no route to host") {
+		t.Fatalf("output = %q, want the fetch error reported", out)
+	}
+}
+
+func TestPrintUpdateCheck(t *testing.T) {
+	latest := version.Release{Tag: "v0.4.0", URL: "https://github.com/banksean/sand/releases/tag/v0.4.0"}
+	for _, tc := range []struct {
+		name  string
+		check version.UpdateCheck
+		want  string
+	}{
+		{
+			name:  "update available",
+			check: version.UpdateCheck{Current: "v0.3.1", Latest: latest, Comparable: true, UpdateAvailable: true},
+			want:  "Update Available: v0.3.1 -> v0.4.0\nRelease URL: " + latest.URL + "\n",
+		},
+		{
+			name:  "up to date",
+			check: version.UpdateCheck{Current: "v0.4.0", Latest: latest, Comparable: true},
+			want:  "Up To Date: v0.4.0 is the latest release\n",
+		},
+		{
+			name:  "not a release",
+			check: version.UpdateCheck{Current: "main", Latest: latest},
+			want:  "Latest Release: v0.4.0 (this build, \"main\", is not a release)\nRelease URL: " + latest.URL + "\n",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			printUpdateCheck(&buf, tc.check)
+			if buf.String() != tc.want {
+				t.Fatalf("printUpdateCheck() = %q, want %q", buf.String(), tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// castHeader is the first line of an asciinema v2 cast file.
+// See https://docs.asciinema.org/manual/asciicast/v2/.
+type castHeader struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp,omitempty"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// castWriter records what is written to it as the output events of an
+// asciinema v2 cast, each timed from when the writer was made. Each event is
+// written as it happens, so a session that ends abruptly still leaves a
+// playable file.
+type castWriter struct {
+	w     io.Writer
+	now   func() time.Time
+	start time.Time
+	// partial holds the start of a UTF-8 sequence split across writes, so
+	// events never carry half a character.
+	partial []byte
+	err     error
+}
+
+func newCastWriter(w io.Writer, header castHeader, now func() time.Time) (*castWriter, error) {
+	if now == nil {
+		now = time.Now
+	}
+	cw := &castWriter{w: w, now: now, start: now()}
+	header.Version = 2
+	if header.Timestamp == 0 {
+		header.Timestamp = cw.start.Unix()
+	}
+	if err := cw.writeLine(header); err != nil {
+		return nil, fmt.Errorf("writing cast header: %w", err)
+	}
+	return cw, nil
+}
+
+// Write records p as one output event. Once writing the cast fails, later
+// writes are dropped and Err reports the failure.
+func (cw *castWriter) Write(p []byte) (int, error) {
+	if cw.err != nil {
+		return 0, cw.err
+	}
+	data := append(cw.partial, p...)
+	cw.partial = nil
+	if cut := incompleteUTF8Suffix(data); cut > 0 {
+		cw.partial = append([]byte(nil), data[len(data)-cut:]...)
+		data = data[:len(data)-cut]
+	}
+	if len(data) == 0 {
+		return len(p), nil
+	}
+	if err := cw.event(data); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close records anything held back from the last write.
+func (cw *castWriter) Close() error {
+	if cw.err == nil && len(cw.partial) > 0 {
+		cw.event(cw.partial) //nolint:errcheck
+		cw.partial = nil
+	}
+	return cw.err
+}
+
+// Err reports the first error writing the cast.
+func (cw *castWriter) Err() error {
+	return cw.err
+}
+
+func (cw *castWriter) event(data []byte) error {
+	elapsed := cw.now().Sub(cw.start).Seconds()
+	return cw.writeLine([]any{json.Number(strconv.FormatFloat(elapsed, 'f', 6, 64)), "o", string(data)})
+}
+
+func (cw *castWriter) writeLine(v any) error {
+	enc := json.NewEncoder(cw.w)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		cw.err = err
+		return err
+	}
+	return nil
+}
+
+// incompleteUTF8Suffix returns the length of the unfinished UTF-8 sequence at
+// the end of p, or 0 if p ends on a character boundary or with bytes that can
+// never be completed.
+func incompleteUTF8Suffix(p []byte) int {
+	for n := 1; n < utf8.UTFMax && n <= len(p); n++ {
+		c := p[len(p)-n]
+		if utf8.RuneStart(c) {
+			if !utf8.FullRune(p[len(p)-n:]) {
+				return n
+			}
+			return 0
+		}
+	}
+	return 0
+}
+
+// recordingWriter shows output on the terminal and copies it to a cast. The
+// terminal always gets every byte; a cast that can't be written stops being
+// recorded without interrupting the session.
+type recordingWriter struct {
+	terminal io.Writer
+	cast     *castWriter
+}
+
+func (rw *recordingWriter) Write(p []byte) (int, error) {
+	n, err := rw.terminal.Write(p)
+	if n > 0 {
+		rw.cast.Write(p[:n]) //nolint:errcheck
+	}
+	return n, err
+}
+
+// castTerminalSize returns the size of the terminal on f, or 80x24 if f isn't
+// one.
+func castTerminalSize(f *os.File) (width, height int) {
+	width, height, err := term.GetSize(int(f.Fd()))
+	if err != nil || width <= 0 || height <= 0 {
+		return 80, 24
+	}
+	return width, height
+}
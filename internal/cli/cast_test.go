@@ -0,0 +1,125 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// castClock returns a clock that starts at start and advances by each of steps
+// in turn, one step per reading after the first.
+func castClock(start time.Time, steps ...time.Duration) func() time.Time {
+	now := start
+	calls := 0
+	return func() time.Time {
+		if calls > 0 && calls-1 < len(steps) {
+			now = now.Add(steps[calls-1])
+		}
+		calls++
+		return now
+	}
+}
+
+func castLines(t *testing.T, cast string) []string {
+	t.Helper()
+	if !strings.HasSuffix(cast, "\n") {
+		t.Fatalf("cast = %q, want newline-terminated lines", cast)
+	}
+	return strings.Split(strings.TrimSuffix(cast, "\n"), "\n")
+}
+
+func TestCastWriterFramesTimedOutputEvents(t *testing.T) {
+	var buf bytes.Buffer
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	cw, err := newCastWriter(&buf, castHeader{Width: 120, Height: 40, Env: map[string]string{"SHELL": "/bin/zsh"}},
+		castClock(start, 250*time.Millisecond, 1500*time.Millisecond, 1234567*time.Microsecond))
+	if err != nil {
+		t.Fatalf("newCastWriter() error = %v", err)
+	}
+	for _, out := range []string{"$ ls\r\n", "<a & b>\t\"quoted\"\r\n", "\x1b[1mdone\x1b[0m"} {
+		if n, err := cw.Write([]byte(out)); err != nil || n != len(out) {
+			t.Fatalf("Write(%q) = %d, %v", out, n, err)
+		}
+	}
+	if err := cw.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	want := []string{
+		`{"version":2,"width":120,"height":40,"timestamp":1772366400,"env":{"SHELL":"/bin/zsh"}}`,
+		`[0.250000,"o","$ ls\r\n"]`,
+		`[1.750000,"o","<a & b>\t\"quoted\"\r\n"]`,
+		`[2.984567,"o","\u001b[1mdone\u001b[0m"]`,
+	}
+	if got := castLines(t, buf.String()); !reflect.DeepEqual(got, want) {
+		t.Fatalf("cast =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestCastWriterKeepsCharactersSplitAcrossWrites(t *testing.T) {
+	var buf bytes.Buffer
+	cw, err := newCastWriter(&buf, castHeader{Width: 80, Height: 24, Timestamp: 1}, castClock(time.Unix(1, 0), time.Second, time.Second, time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	snowman := []byte("☃") // three bytes
+	cw.Write(append([]byte("a"), snowman[:1]...))
+	cw.Write(snowman[1:2])
+	cw.Write(append(snowman[2:], 'b'))
+	// A sequence still unfinished when the session ends is flushed by Close,
+	// as the replacement characters JSON strings can carry.
+	cw.Write(snowman[:2])
+	if err := cw.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	want := []string{
+		`{"version":2,"width":80,"height":24,"timestamp":1}`,
+		`[1.000000,"o","a"]`,
+		`[2.000000,"o","☃b"]`,
+		`[3.000000,"o","` + "\uFFFD\uFFFD" + `"]`,
+	}
+	if got := castLines(t, buf.String()); !reflect.DeepEqual(got, want) {
+		t.Fatalf("cast =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+// failingWriter accepts limit bytes and then fails.
+type failingWriter struct {
+	buf   bytes.Buffer
+	limit int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.buf.Len()+len(p) > w.limit {
+		return 0, errors.New("disk full")
+	}
+	return w.buf.Write(p)
+}
+
+func TestRecordingWriterKeepsTheSessionGoingWhenTheCastFails(t *testing.T) {
+	castFile := &failingWriter{limit: 100}
+	cw, err := newCastWriter(castFile, castHeader{Width: 80, Height: 24}, castClock(time.Unix(1, 0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var terminal bytes.Buffer
+	rw := &recordingWriter{terminal: &terminal, cast: cw}
+	for _, out := range []string{"first\r\n", strings.Repeat("x", 100), "last\r\n"} {
+		if n, err := rw.Write([]byte(out)); err != nil || n != len(out) {
+			t.Fatalf("Write(%q) = %d, %v; want the terminal write's result", out[:5], n, err)
+		}
+	}
+	if want := "first\r\n" + strings.Repeat("x", 100) + "last\r\n"; terminal.String() != want {
+		t.Errorf("terminal = %q, want every byte of the session", terminal.String())
+	}
+	if err := cw.Close(); err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Errorf("Close() error = %v, want the cast's write error", err)
+	}
+	if lines := castLines(t, castFile.buf.String()); len(lines) != 2 || !strings.Contains(lines[1], "first") {
+		t.Errorf("cast = %q, want the header and the events before the failure", lines)
+	}
+}
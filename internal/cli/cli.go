@@ -10,7 +10,15 @@ package cli
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
 
+	"github.com/banksean/sand/internal/hostops"
 	"github.com/banksean/sand/internal/sandtypes"
 
 	"github.com/banksean/sand/internal/daemon"
@@ -24,6 +32,19 @@ type CLIContext struct {
 	Context      context.Context
 	Daemon       daemon.Client
 	SharedCaches sandtypes.SharedCacheConfig
+	// MessageLevel is the least important progress message to show, from the
+	// global --quiet and --verbose flags.
+	MessageLevel hostops.MessageLevel
+	// GitOps runs git on the host for commands that read sandbox clones. Nil
+	// means hostops.NewDefaultGitOps.
+	GitOps hostops.GitOps
+}
+
+func (c *CLIContext) gitOps() hostops.GitOps {
+	if c.GitOps != nil {
+		return c.GitOps
+	}
+	return hostops.NewDefaultGitOps()
 }
 
 const (
@@ -32,7 +53,7 @@ const (
 
 // ShellFlags are shared by commands that exec a shell inside a container.
 type ShellFlags struct {
-	Shell string `short:"s" default:"/bin/zsh" placeholder:"<shell-command>" help:"shell command to exec in the container"`
+	Shell string `short:"s" placeholder:"<shell-command>" help:"shell command to exec in the container (default: the sandbox's saved shell, then the first of /bin/zsh, /bin/bash, /bin/sh that exists)"`
 	Tmux  bool   `short:"t" help:"create or reconnect to a container-side tmux session"`
 	Atch  bool   `help:"create or reconnect to a container-side atch session"`
 }
@@ -41,23 +62,130 @@ type SSHAgentFlag struct {
 	SSHAgent bool `help:"enable ssh-agent forwarding for the container"`
 }
 
+type PullAlwaysFlag struct {
+	PullAlways bool `help:"pull the container image even if an image with the same tag is already present"`
+}
+
 type ProjectEnvFlag struct {
 	ProjectEnv bool `help:"pass project-scoped profile env to plain shell/exec/git commands"`
 }
 
+// EnvFlag sets extra environment variables for a shell or exec command.
+type EnvFlag struct {
+	Env []string `name:"env" sep:"none" placeholder:"<KEY=VALUE>" help:"set an environment variable for the command, overriding the env file (can be specified multiple times)"`
+}
+
+// RootFlag runs a shell or exec command as root instead of the sandbox's
+// default user.
+type RootFlag struct {
+	Root bool `help:"run as root instead of the sandbox's default user"`
+}
+
+// apply makes sessions on sbox log in as root if --root was given.
+func (f RootFlag) apply(sbox *sandtypes.Box) {
+	if f.Root {
+		sbox.Username = "root"
+		sbox.Uid = "0"
+	}
+}
+
+// containerAppDir is where a sandbox's clone of the project is mounted in its
+// container, and where shell and exec sessions start by default.
+const containerAppDir = "/app"
+
+// WorkDirFlag picks the directory in the container a shell or exec command
+// runs in.
+type WorkDirFlag struct {
+	WorkDir string `name:"workdir" default:"/app" placeholder:"<dir>" help:"directory in the container to run in"`
+}
+
+func (f WorkDirFlag) validate() error {
+	if f.WorkDir != "" && !path.IsAbs(f.WorkDir) {
+		return fmt.Errorf("--workdir %s: must be an absolute path", f.WorkDir)
+	}
+	return nil
+}
+
+// dir is the directory to run in: --workdir, or containerAppDir if unset.
+func (f WorkDirFlag) dir() string {
+	if f.WorkDir == "" {
+		return containerAppDir
+	}
+	return f.WorkDir
+}
+
 // SandboxCreationFlags are shared by commands that create a sandbox.
 type SandboxCreationFlags struct {
 	SSHAgentFlag
 	ImageName          string   `name:"image" short:"i" placeholder:"<container-image-name>" help:"name of base container image to use"`
-	CloneFromDir       string   `short:"d" placeholder:"<project-dir>" help:"directory to clone into the sandbox. Defaults to current working directory, if unset."`
+	CloneFromDir       string   `short:"d" aliases:"cwd" placeholder:"<project-dir>" help:"directory to clone into the sandbox. Defaults to current working directory, if unset."`
 	ProfileName        string   `name:"profile" default:"default" placeholder:"<profile-name>" help:"profile policy from .sand.yaml to associate with the sandbox"`
 	EnvFile            string   `short:"e" default:".env" placeholder:"<file-path>" help:"legacy env file path used when no default profile is configured"`
 	Rm                 bool     `help:"remove the sandbox after the command terminates"`
 	AllowedDomainsFile string   `placeholder:"<file-path>" help:"path to allowed-domains.txt file for DNS egress filtering (overrides the init image default)"`
 	Mount              []string `sep:"none" placeholder:"<source=...,target=...[,readonly]>" help:"bind mount a host directory (can be specified multiple times)"`
 	CloneMount         []string `sep:"none" placeholder:"<source=...,target=...[,readonly]>" help:"copy-on-write clone a host directory and bind mount the clone (can be specified multiple times)"`
+	Label              []string `sep:"none" placeholder:"<key=value>" help:"label the sandbox for filtering with 'sand ls --filter label=...' (can be specified multiple times)"`
+	Network            string   `placeholder:"<network>" help:"container network to attach the sandbox to, e.g. one made with 'container network create' to isolate it from other sandboxes (default: the container system's default network)"`
 	CPU                int      `help:"number of CPUs to allocate to the container" default:"2"`
 	Memory             int      `help:"how much memory in MiB to allocate to the container" default:"1024"`
+	Force              bool     `help:"create the sandbox even if --cpu or --memory is more than this host has"`
+}
+
+// hostCapacity reports the host's CPUs and memory for checkResources.
+var hostCapacity = hostops.HostCapacity
+
+// checkResources refuses a --cpu or --memory the host can't provide, since
+// the container would otherwise fail to start with an error that doesn't say
+// why. With --force it writes the same complaint to warn and lets the create
+// go ahead. A host whose capacity can't be read isn't checked.
+func (f *SandboxCreationFlags) checkResources(ctx context.Context, warn io.Writer) error {
+	cpus, memoryBytes, err := hostCapacity()
+	if err != nil {
+		slog.DebugContext(ctx, "hostCapacity", "error", err)
+		return nil
+	}
+	var problems []string
+	if cpus > 0 && f.CPU > cpus {
+		problems = append(problems, fmt.Sprintf("--cpu %d is more than the %d CPUs this host has", f.CPU, cpus))
+	}
+	if hostMiB := memoryBytes / (1024 * 1024); hostMiB > 0 && f.Memory > 0 && uint64(f.Memory) > hostMiB {
+		problems = append(problems, fmt.Sprintf("--memory %d is more than the %d MiB of memory this host has", f.Memory, hostMiB))
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	msg := strings.Join(problems, "; ")
+	if f.Force {
+		fmt.Fprintf(warn, "warning: %s; creating the sandbox anyway because of --force\n", msg)
+		return nil
+	}
+	return fmt.Errorf("%s (pass --force to try anyway)", msg)
+}
+
+// resolveCloneFromDir makes CloneFromDir absolute, relative to cwd, defaulting
+// it to cwd. sandd runs elsewhere, so it can't resolve a relative path itself.
+func (f *SandboxCreationFlags) resolveCloneFromDir(cwd string) error {
+	if f.CloneFromDir == "" {
+		f.CloneFromDir = cwd
+		return nil
+	}
+	dir := f.CloneFromDir
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(cwd, dir)
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("--clone-from-dir %s: no such directory", f.CloneFromDir)
+		}
+		return fmt.Errorf("--clone-from-dir %s: %w", f.CloneFromDir, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("--clone-from-dir %s: not a directory", f.CloneFromDir)
+	}
+	f.CloneFromDir = filepath.Clean(dir)
+	return nil
 }
 
 // SandboxNameFlag is shared by commands that require a single sandbox name argument.
@@ -70,3 +198,36 @@ type MultiSandboxNameFlags struct {
 	SandboxNames []string `arg:"" completion-predictor:"sandbox-name" optional:"" help:"names of the sandboxes"`
 	All          bool     `short:"a" help:"all sandboxes"`
 }
+
+// AllFilterFlag narrows --all to the sandboxes with matching labels.
+type AllFilterFlag struct {
+	Filter []string `sep:"none" placeholder:"label=<key>[=<value>]" help:"with --all, only sandboxes with a matching label (can be specified multiple times; all must match)"`
+}
+
+// selectSandboxes returns the sandboxes a bulk command operates on: those
+// named on the command line, or with --all every active sandbox matching
+// --filter. Named sandboxes are returned with only their Name set.
+func selectSandboxes(ctx context.Context, mc daemon.Client, names MultiSandboxNameFlags, filter AllFilterFlag) ([]sandtypes.Box, error) {
+	if !names.All {
+		if len(filter.Filter) > 0 {
+			return nil, fmt.Errorf("--filter requires --all")
+		}
+		if len(names.SandboxNames) == 0 {
+			return nil, fmt.Errorf("sandbox name required unless --all is set")
+		}
+		boxes := make([]sandtypes.Box, len(names.SandboxNames))
+		for i, name := range names.SandboxNames {
+			boxes[i].Name = name
+		}
+		return boxes, nil
+	}
+	selectors, err := parseLsFilters(filter.Filter)
+	if err != nil {
+		return nil, err
+	}
+	bxs, err := mc.ListSandboxes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return filterSandboxesByLabels(bxs, selectors), nil
+}
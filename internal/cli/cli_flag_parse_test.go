@@ -31,8 +31,8 @@ func TestShellFlagsDefaults(t *testing.T) {
 		Shell ShellFlags `embed:""`
 	}
 	kongParse(t, &cli, []string{})
-	if cli.Shell.Shell != "/bin/zsh" {
-		t.Errorf("expected default shell /bin/zsh, got %q", cli.Shell.Shell)
+	if cli.Shell.Shell != "" {
+		t.Errorf("expected no default shell so the sandbox can choose, got %q", cli.Shell.Shell)
 	}
 	if cli.Shell.Atch {
 		t.Error("expected Atch=false by default")
@@ -277,8 +277,8 @@ func TestNewCmdDefaults(t *testing.T) {
 		New NewCmd `cmd:""`
 	}
 	kongParse(t, &cli, []string{"new"})
-	if cli.New.Shell != "/bin/zsh" {
-		t.Errorf("expected default shell /bin/zsh, got %q", cli.New.Shell)
+	if cli.New.Shell != "" {
+		t.Errorf("expected no default shell so the sandbox can choose, got %q", cli.New.Shell)
 	}
 	if cli.New.EnvFile != ".env" {
 		t.Errorf("expected default EnvFile .env, got %q", cli.New.EnvFile)
@@ -337,6 +337,16 @@ func TestNewCmdFlags(t *testing.T) {
 	}
 }
 
+func TestNewCmdUserAlias(t *testing.T) {
+	var cli struct {
+		New NewCmd `cmd:""`
+	}
+	kongParse(t, &cli, []string{"new", "--user", "alice", "sandbox-42"})
+	if cli.New.Username != "alice" {
+		t.Errorf("expected Username alice from --user, got %q", cli.New.Username)
+	}
+}
+
 func TestLogCmdWithSandboxName(t *testing.T) {
 	var cli struct {
 		Log SandboxLogCmd `cmd:""`
@@ -347,6 +357,16 @@ func TestLogCmdWithSandboxName(t *testing.T) {
 	}
 }
 
+func TestNewCmdCwdAlias(t *testing.T) {
+	var cli struct {
+		New NewCmd `cmd:""`
+	}
+	kongParse(t, &cli, []string{"new", "--cwd", "../other-project", "my-box"})
+	if cli.New.CloneFromDir != "../other-project" {
+		t.Errorf("CloneFromDir = %q, want the --cwd value", cli.New.CloneFromDir)
+	}
+}
+
 func TestShellCmdDefaults(t *testing.T) {
 	var cli struct {
 		Shell ShellCmd `cmd:""`
@@ -370,6 +390,16 @@ func TestShellCmdSSHAgentFlag(t *testing.T) {
 	}
 }
 
+func TestShellCmdImageFlag(t *testing.T) {
+	var cli struct {
+		Shell ShellCmd `cmd:""`
+	}
+	kongParse(t, &cli, []string{"shell", "--image", "ghcr.io/banksean/sand/default:v2", "my-box"})
+	if cli.Shell.Image != "ghcr.io/banksean/sand/default:v2" {
+		t.Errorf("Image = %q, want the --image value", cli.Shell.Image)
+	}
+}
+
 func TestShellCmdProjectEnvFlag(t *testing.T) {
 	var cli struct {
 		Shell ShellCmd `cmd:""`
@@ -380,6 +410,29 @@ func TestShellCmdProjectEnvFlag(t *testing.T) {
 	}
 }
 
+func TestShellCmdTrailingCommand(t *testing.T) {
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{args: []string{"shell", "my-box", "--", "nvim", "-R", "."}, want: []string{"nvim", "-R", "."}},
+		{args: []string{"shell", "my-box", "htop"}, want: []string{"htop"}},
+		{args: []string{"shell", "my-box"}, want: nil},
+	}
+	for _, tt := range tests {
+		var cli struct {
+			Shell ShellCmd `cmd:""`
+		}
+		kongParse(t, &cli, tt.args)
+		if cli.Shell.SandboxName != "my-box" {
+			t.Errorf("%v: expected SandboxName my-box, got %q", tt.args, cli.Shell.SandboxName)
+		}
+		if !slices.Equal(cli.Shell.Cmd, tt.want) {
+			t.Errorf("%v: expected Cmd %q, got %q", tt.args, tt.want, cli.Shell.Cmd)
+		}
+	}
+}
+
 func TestStopCmdSandboxName(t *testing.T) {
 	var cli struct {
 		Stop StopCmd `cmd:""`
@@ -530,3 +583,33 @@ func TestSandboxCreationFlagsRejectsVolume(t *testing.T) {
 		t.Fatal("expected --volume to be rejected")
 	}
 }
+
+func TestForwardCmdFlags(t *testing.T) {
+	var cli struct {
+		Forward ForwardCmd `cmd:""`
+	}
+	kongParse(t, &cli, []string{"forward", "my-box", "9222:9223", "-R"})
+	if cli.Forward.SandboxName != "my-box" || cli.Forward.Spec != "9222:9223" || !cli.Forward.Remote {
+		t.Fatalf("forward flags = %+v, want my-box 9222:9223 remote", cli.Forward)
+	}
+
+	cli.Forward = ForwardCmd{}
+	kongParse(t, &cli, []string{"forward", "my-box", "--list"})
+	if !cli.Forward.List || cli.Forward.Spec != "" {
+		t.Fatalf("forward --list flags = %+v", cli.Forward)
+	}
+}
+
+func TestKillCmdSignalFlag(t *testing.T) {
+	var cli struct {
+		Kill KillCmd `cmd:""`
+	}
+	kongParse(t, &cli, []string{"kill", "box"})
+	if cli.Kill.Signal != "KILL" || !slices.Equal(cli.Kill.SandboxNames, []string{"box"}) {
+		t.Errorf("default kill = %+v, want KILL for [box]", cli.Kill)
+	}
+	kongParse(t, &cli, []string{"kill", "-s", "TERM", "box"})
+	if cli.Kill.Signal != "TERM" {
+		t.Errorf("expected Signal=TERM with -s TERM, got %q", cli.Kill.Signal)
+	}
+}
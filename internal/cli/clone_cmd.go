@@ -0,0 +1,20 @@
+package cli
+
+import "fmt"
+
+type CloneCmd struct {
+	SourceName string `arg:"" required:"" help:"name of the sandbox to copy" completion-predictor:"sandbox-name"`
+	NewName    string `arg:"" required:"" help:"name for the new sandbox"`
+}
+
+func (c *CloneCmd) Run(cctx *CLIContext) error {
+	ctx := cctx.Context
+	mc := cctx.Daemon
+
+	_, err := mc.ForkSandbox(ctx, c.SourceName, c.NewName)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s\n", c.NewName)
+	return nil
+}
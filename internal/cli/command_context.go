@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// ErrTimeout is the cause of a command context that ran past --timeout.
+var ErrTimeout = errors.New("timed out")
+
+// errInterrupted is the cause of a command context cancelled by SIGINT or SIGTERM.
+var errInterrupted = errors.New("interrupted")
+
+// NotifyContext returns a context that is cancelled when the process gets
+// SIGINT or SIGTERM, so that breaking out of a slow command (an image pull, a
+// git fetch) kills the subprocesses it started with exec.CommandContext and
+// lets the command's deferred cleanup run. Once the first signal has cancelled
+// the context, a second one terminates the process as usual.
+func NotifyContext(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(parent)
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case sig := <-signals:
+			cancel(fmt.Errorf("%w by %s", errInterrupted, sig))
+		case <-ctx.Done():
+		}
+		signal.Stop(signals)
+	}()
+	return ctx, func() { cancel(context.Canceled) }
+}
+
+// WithTimeout bounds ctx by the global --timeout flag. A zero timeout leaves
+// ctx unbounded.
+func WithTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeoutCause(ctx, timeout, fmt.Errorf("%w after %s (--timeout)", ErrTimeout, timeout))
+}
+
+// CommandError explains err in terms of why ctx ended, if it did, so a
+// command cut short by --timeout or Ctrl-C says so rather than just
+// "context deadline exceeded".
+func CommandError(ctx context.Context, err error) error {
+	if err == nil || ctx.Err() == nil {
+		return err
+	}
+	cause := context.Cause(ctx)
+	if !errors.Is(cause, ErrTimeout) && !errors.Is(cause, errInterrupted) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", cause, err)
+}
+
+// ExitStatus is the error a command returns when sand should exit with the
+// status of a command it ran in a sandbox, such as sand exec's remote command.
+// It implements kong's ExitCoder.
+type ExitStatus struct {
+	Code int
+}
+
+func (e *ExitStatus) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// ExitCode returns the status sand should exit with.
+func (e *ExitStatus) ExitCode() int {
+	return e.Code
+}
+
+// exitStatusError turns the *exec.ExitError of a command that ran to
+// completion into an *ExitStatus. Other errors, including a command killed by
+// a signal, are returned unchanged.
+func exitStatusError(err error) error {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() < 0 {
+		return err
+	}
+	return &ExitStatus{Code: exitErr.ExitCode()}
+}
@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// runLongOperation stands in for a command like sand new: it starts a
+// subprocess with ctx and cleans up after itself however it ends.
+func runLongOperation(ctx context.Context, cleanedUp *bool) error {
+	defer func() { *cleanedUp = true }()
+	return exec.CommandContext(ctx, "sleep", "30").Run()
+}
+
+func TestWithTimeoutAbortsLongOperation(t *testing.T) {
+	ctx, cancel := WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	var cleanedUp bool
+	start := time.Now()
+	err := CommandError(ctx, runLongOperation(ctx, &cleanedUp))
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Fatalf("operation ran for %s, want it killed at the timeout", elapsed)
+	}
+	if !errors.Is(err, ErrTimeout) || !strings.Contains(err.Error(), "timed out after 50ms (--timeout)") {
+		t.Fatalf("error = %v, want timeout error", err)
+	}
+	if !cleanedUp {
+		t.Fatal("deferred cleanup did not run")
+	}
+}
+
+func TestWithTimeoutZeroLeavesContextUnbounded(t *testing.T) {
+	ctx, cancel := WithTimeout(context.Background(), 0)
+	defer cancel()
+	if _, ok := ctx.Deadline(); ok {
+		t.Fatal("context has a deadline, want none for a zero timeout")
+	}
+}
+
+func TestNotifyContextCancelsOnSignal(t *testing.T) {
+	ctx, cancel := NotifyContext(context.Background())
+	defer cancel()
+
+	var cleanedUp bool
+	done := make(chan error, 1)
+	go func() { done <- runLongOperation(ctx, &cleanedUp) }()
+	// Give the subprocess a moment to start so the signal interrupts it mid-run.
+	time.Sleep(50 * time.Millisecond)
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-done:
+		err = CommandError(ctx, err)
+		if err == nil || !strings.Contains(err.Error(), "interrupted by terminated") {
+			t.Fatalf("error = %v, want interrupted error", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("operation kept running after SIGTERM")
+	}
+	if !cleanedUp {
+		t.Fatal("deferred cleanup did not run")
+	}
+}
+
+func TestCommandErrorLeavesOtherErrorsAlone(t *testing.T) {
+	want := errors.New("boom")
+	if got := CommandError(context.Background(), want); got != want {
+		t.Fatalf("CommandError() = %v, want %v", got, want)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if got := CommandError(ctx, want); got != want {
+		t.Fatalf("CommandError() after plain cancel = %v, want %v", got, want)
+	}
+}
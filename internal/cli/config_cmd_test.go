@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
@@ -449,3 +450,50 @@ oneshot:
 		}
 	}
 }
+
+func TestProjectConfigSetsNewDefaultsAndFlagsWin(t *testing.T) {
+	type target struct {
+		New NewCmd `cmd:""`
+	}
+	cfgPath := filepath.Join(t.TempDir(), ".sand.yaml")
+	if err := os.WriteFile(cfgPath, []byte(`
+new:
+  image: ghcr.io/example/dev:latest
+  cpu: 6
+  memory: 8192
+  mount:
+    - source=/data,target=/data,readonly
+  profile: team
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	parse := func(args ...string) NewCmd {
+		t.Helper()
+		var parsed target
+		parser := kong.Must(&parsed, kong.Configuration(kongyaml.Loader, cfgPath))
+		if err := ValidateConfigFiles(parser, cfgPath); err != nil {
+			t.Fatalf("ValidateConfigFiles: %v", err)
+		}
+		if _, err := parser.Parse(args); err != nil {
+			t.Fatalf("Parse(%v): %v", args, err)
+		}
+		return parsed.New
+	}
+
+	got := parse("new", "box")
+	if got.ImageName != "ghcr.io/example/dev:latest" || got.CPU != 6 || got.Memory != 8192 || got.ProfileName != "team" {
+		t.Errorf("config defaults = image %q cpu %d memory %d profile %q, want values from %s", got.ImageName, got.CPU, got.Memory, got.ProfileName, cfgPath)
+	}
+	if want := []string{"source=/data,target=/data,readonly"}; !slices.Equal(got.Mount, want) {
+		t.Errorf("Mount = %v, want %v", got.Mount, want)
+	}
+
+	got = parse("new", "--image", "alpine:latest", "--cpu", "2", "--mount", "source=/src,target=/src", "box")
+	if got.ImageName != "alpine:latest" || got.CPU != 2 || got.Memory != 8192 {
+		t.Errorf("with flags = image %q cpu %d memory %d, want flags to override the config file", got.ImageName, got.CPU, got.Memory)
+	}
+	if want := []string{"source=/src,target=/src"}; !slices.Equal(got.Mount, want) {
+		t.Errorf("Mount with --mount = %v, want %v", got.Mount, want)
+	}
+}
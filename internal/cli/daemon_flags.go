@@ -0,0 +1,39 @@
+package cli
+
+import "time"
+
+// DaemonFlags defines global configuration for the sandd process that sand
+// starts on demand. Like CacheFlags, it can be loaded by Kong from
+// ~/.sand.yaml without introducing a "daemon" subcommand.
+type DaemonFlags struct {
+	IdleTimeout           time.Duration `name:"idle-timeout" default:"0s" help:"stop sandbox containers that have not been used for this long, e.g. 2h (0s disables)"`
+	IdleCheckInterval     time.Duration `name:"idle-check-interval" default:"5m" help:"how often the daemon checks for idle sandbox containers"`
+	ShutdownGracePeriod   time.Duration `name:"shutdown-grace-period" default:"0s" help:"how long the daemon lets in-flight requests finish when stopping (0s uses the sandd default of 30s)"`
+	ContainerReadyTimeout time.Duration `name:"container-ready-timeout" default:"0s" help:"how long a started sandbox container has to become ready before its start hooks run (0s uses the sandd default of 30s)"`
+	ContainerCacheTTL     time.Duration `name:"container-cache-ttl" default:"0s" help:"how long the daemon reuses container status when listing sandboxes (0s uses the sandd default of 2s)"`
+	MetricsAddr           string        `name:"metrics-addr" default:"" help:"TCP address, e.g. 127.0.0.1:9464, where the daemon serves Prometheus metrics at /metrics (empty disables)"`
+}
+
+// SanddArgs returns the extra "sandd start" arguments for these flags.
+func (f DaemonFlags) SanddArgs() []string {
+	var args []string
+	if f.IdleTimeout > 0 {
+		args = append(args, "--idle-timeout", f.IdleTimeout.String())
+	}
+	if f.IdleCheckInterval > 0 {
+		args = append(args, "--idle-check-interval", f.IdleCheckInterval.String())
+	}
+	if f.ShutdownGracePeriod > 0 {
+		args = append(args, "--shutdown-grace-period", f.ShutdownGracePeriod.String())
+	}
+	if f.ContainerReadyTimeout > 0 {
+		args = append(args, "--container-ready-timeout", f.ContainerReadyTimeout.String())
+	}
+	if f.ContainerCacheTTL > 0 {
+		args = append(args, "--container-cache-ttl", f.ContainerCacheTTL.String())
+	}
+	if f.MetricsAddr != "" {
+		args = append(args, "--metrics-addr", f.MetricsAddr)
+	}
+	return args
+}
@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/alecthomas/kong"
+	kongyaml "github.com/alecthomas/kong-yaml"
+)
+
+func TestDaemonFlagsLoadedByKongYAML(t *testing.T) {
+	type cli struct {
+		Daemon DaemonFlags `embed:"" prefix:"daemon-"`
+	}
+
+	homeDir := t.TempDir()
+	configPath := filepath.Join(homeDir, ".sand.yaml")
+	if err := os.WriteFile(configPath, []byte("daemon:\n  idle-timeout: 2h\n  idle-check-interval: 10m\n  shutdown-grace-period: 2m\n  container-ready-timeout: 1m\n  container-cache-ttl: 500ms\n  metrics-addr: 127.0.0.1:9464\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var parsed cli
+	parser := kong.Must(&parsed, kong.Configuration(kongyaml.Loader, configPath))
+	if _, err := parser.Parse([]string{}); err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	want := []string{"--idle-timeout", "2h0m0s", "--idle-check-interval", "10m0s", "--shutdown-grace-period", "2m0s", "--container-ready-timeout", "1m0s", "--container-cache-ttl", "500ms", "--metrics-addr", "127.0.0.1:9464"}
+	if got := parsed.Daemon.SanddArgs(); !slices.Equal(got, want) {
+		t.Fatalf("SanddArgs() = %v, want %v", got, want)
+	}
+}
+
+func TestDaemonFlagsIdleStopDisabledByDefault(t *testing.T) {
+	type cli struct {
+		Daemon DaemonFlags `embed:"" prefix:"daemon-"`
+	}
+
+	var parsed cli
+	kongParse(t, &parsed, []string{})
+	if parsed.Daemon.IdleTimeout != 0 {
+		t.Fatalf("IdleTimeout = %v, want 0", parsed.Daemon.IdleTimeout)
+	}
+	for _, arg := range parsed.Daemon.SanddArgs() {
+		if arg == "--idle-timeout" {
+			t.Fatalf("SanddArgs() = %v, want no --idle-timeout by default", parsed.Daemon.SanddArgs())
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/banksean/sand/internal/hostops"
+	"github.com/banksean/sand/internal/sandtypes"
+)
+
+var dfCmdStdout io.Writer = os.Stdout
+
+type DfCmd struct {
+	SandboxNames []string `arg:"" optional:"" completion-predictor:"sandbox-name" help:"names of the sandboxes (default: all)"`
+}
+
+// dfRow is one sandbox's clone directory usage, or why it couldn't be measured.
+type dfRow struct {
+	Name  string
+	Usage hostops.DiskUsage
+	Err   error
+}
+
+func (c *DfCmd) Run(cctx *CLIContext) error {
+	ctx := cctx.Context
+	boxes, err := cctx.Daemon.ListSandboxes(ctx)
+	if err != nil {
+		return err
+	}
+	if len(c.SandboxNames) > 0 {
+		var named []sandtypes.Box
+		for _, name := range c.SandboxNames {
+			i := slices.IndexFunc(boxes, func(b sandtypes.Box) bool { return b.Name == name })
+			if i < 0 {
+				return fmt.Errorf("sandbox %q not found", name)
+			}
+			named = append(named, boxes[i])
+		}
+		boxes = named
+	}
+
+	rows := make([]dfRow, len(boxes))
+	for i, box := range boxes {
+		rows[i].Name = box.Name
+		rows[i].Usage, rows[i].Err = hostops.MeasureDiskUsage(box.SandboxWorkDir)
+	}
+	return renderDfTable(dfCmdStdout, rows)
+}
+
+func renderDfTable(w io.Writer, rows []dfRow) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tLOGICAL\tALLOCATED\tFILES")
+	var total hostops.DiskUsage
+	for _, row := range rows {
+		if row.Err != nil {
+			fmt.Fprintf(tw, "%s\t-\t-\t-\t%v\n", row.Name, row.Err)
+			continue
+		}
+		total = total.Add(row.Usage)
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\n", row.Name, formatBytes(int(row.Usage.Logical)), formatBytes(int(row.Usage.Allocated)), row.Usage.Files)
+	}
+	if len(rows) > 1 {
+		fmt.Fprintf(tw, "TOTAL\t%s\t%s\t%d\n", formatBytes(int(total.Logical)), formatBytes(int(total.Allocated)), total.Files)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	_, err := fmt.Fprintln(w, "\nClones made with copy-on-write share unchanged blocks with the directory they were cloned from,\nso both columns can far exceed the space removing the sandboxes would free.")
+	return err
+}
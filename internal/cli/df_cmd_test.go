@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/banksean/sand/internal/daemon/daemontest"
+)
+
+func TestDfCmdReportsEachSandboxAndTotal(t *testing.T) {
+	workDirs := map[string]string{}
+	for _, name := range []string{"alpha", "beta"} {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "data"), bytes.Repeat([]byte("x"), 2048), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		workDirs[name] = dir
+	}
+	workDirs["gone"] = filepath.Join(t.TempDir(), "missing")
+	client := daemontest.StartDaemon(t, daemontest.Deps{}, func(ctx context.Context, s daemontest.SandboxStore) {
+		for name, dir := range workDirs {
+			box := newTestBox(name + "-id")
+			box.Name = name
+			box.SandboxWorkDir = dir
+			s.SaveSandbox(ctx, box)
+		}
+	})
+	var out bytes.Buffer
+	oldStdout := dfCmdStdout
+	dfCmdStdout = &out
+	defer func() { dfCmdStdout = oldStdout }()
+
+	if err := (&DfCmd{}).Run(&CLIContext{Context: context.Background(), Daemon: client}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	rows := map[string][]string{}
+	for _, line := range strings.Split(out.String(), "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			rows[fields[0]] = fields
+		}
+	}
+	for _, name := range []string{"NAME", "alpha", "beta", "gone", "TOTAL"} {
+		if rows[name] == nil {
+			t.Errorf("output = %q, want a %s row", out.String(), name)
+		}
+	}
+	if gone := rows["gone"]; gone != nil && gone[1] != "-" {
+		t.Errorf("gone row = %v, want no usage for a missing clone", gone)
+	}
+	if total := rows["TOTAL"]; total != nil && total[3] != "4" {
+		t.Errorf("TOTAL row = %v, want the two measured sandboxes' 4 entries", total)
+	}
+}
+
+func TestDfCmdRejectsUnknownSandbox(t *testing.T) {
+	client := daemontest.StartDaemon(t, daemontest.Deps{}, nil)
+	err := (&DfCmd{SandboxNames: []string{"nope"}}).Run(&CLIContext{Context: context.Background(), Daemon: client})
+	if err == nil || !strings.Contains(err.Error(), `"nope" not found`) {
+		t.Fatalf("Run() error = %v, want not found", err)
+	}
+}
@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/banksean/sand/internal/runtimedeps"
+	"github.com/banksean/sand/internal/sshimmer"
+)
+
+// DoctorCmd runs a series of setup checks and reports which ones fail, with
+// hints for fixing them. Unlike other commands it runs even when the container
+// system or daemon is unhealthy, so that it can report on them.
+type DoctorCmd struct{}
+
+// doctorCheck is a single named diagnostic run by sand doctor.
+type doctorCheck struct {
+	Name string
+	// Hint tells the user how to fix a failure of this check.
+	Hint string
+	Run  func(context.Context) error
+}
+
+const doctorDaemonPingTimeout = 2 * time.Second
+
+func (c *DoctorCmd) Run(cctx *CLIContext) error {
+	return runDoctorChecks(cctx.Context, os.Stdout, doctorChecks(cctx))
+}
+
+// runDoctorChecks runs every check, printing a pass/fail line for each to w,
+// and returns an error if any of them failed.
+func runDoctorChecks(ctx context.Context, w io.Writer, checks []doctorCheck) error {
+	failed := 0
+	for _, check := range checks {
+		err := check.Run(ctx)
+		if err == nil {
+			fmt.Fprintf(w, "ok    %s\n", check.Name)
+			continue
+		}
+		failed++
+		fmt.Fprintf(w, "FAIL  %s: %v\n", check.Name, err)
+		if check.Hint != "" {
+			fmt.Fprintf(w, "      hint: %s\n", check.Hint)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(checks))
+	}
+	return nil
+}
+
+func doctorChecks(cctx *CLIContext) []doctorCheck {
+	home, _ := os.UserHomeDir()
+	sandConfigDir := filepath.Join(home, ".config", "sand")
+	sshConfig := filepath.Join(home, ".ssh", "config")
+	cloneRoot := filepath.Join(cctx.AppBaseDir, "clones")
+
+	checks := []doctorCheck{
+		{
+			Name: "container CLI is on PATH",
+			Hint: "install apple/container " + runtimedeps.AppleContainerVersion + " from " + runtimedeps.AppleContainerInstallerURL(),
+			Run: func(context.Context) error {
+				_, err := exec.LookPath("container")
+				return err
+			},
+		},
+	}
+	runtimeHints := map[runtimedeps.PrerequID]string{
+		runtimedeps.ContainerCommand:               "install apple/container " + runtimedeps.AppleContainerVersion + " from " + runtimedeps.AppleContainerInstallerURL(),
+		runtimedeps.ContainerSystemRunning:         "run: " + runtimedeps.ContainerSystemStartCommand(),
+		runtimedeps.ContainerSystemDNSDomain:       "set dns.domain in ~/.config/container/config.toml, e.g. to " + runtimedeps.DefaultDNSDomain,
+		runtimedeps.ContainerSystemDNSRegistration: "run: sudo container system dns create <dns.domain>",
+	}
+	for _, id := range []runtimedeps.PrerequID{
+		runtimedeps.MacOS,
+		runtimedeps.MacOSVersion,
+		runtimedeps.ContainerCommand,
+		runtimedeps.ContainerSystemRunning,
+		runtimedeps.ContainerSystemDNSDomain,
+		runtimedeps.ContainerSystemDNSRegistration,
+	} {
+		checks = append(checks, doctorCheck{
+			Name: runtimedeps.Describe(id),
+			Hint: runtimeHints[id],
+			Run: func(ctx context.Context) error {
+				return runtimedeps.Check(ctx, cctx.AppBaseDir, runtimedeps.VerifyOptions{DefaultDNSDomain: runtimedeps.DefaultDNSDomain}, id)
+			},
+		})
+	}
+	return append(checks,
+		doctorCheck{
+			Name: sandConfigDir + " is writable",
+			Hint: "check the ownership and permissions of " + sandConfigDir,
+			Run: func(context.Context) error {
+				return checkDirWritable(sandConfigDir)
+			},
+		},
+		doctorCheck{
+			Name: sshConfig + " includes sand's ssh config",
+			Hint: "add \"Include " + filepath.Join(sandConfigDir, "ssh_config") + "\" at the top of " + sshConfig + ", or run sand shell and accept the prompt",
+			Run: func(ctx context.Context) error {
+				return checkSSHInclude(ctx, sshConfig)
+			},
+		},
+		doctorCheck{
+			Name: "sandd daemon is reachable",
+			Hint: "run sandd stop, then any sand command to restart it; see " + cctx.LogFile + " for startup errors",
+			Run: func(ctx context.Context) error {
+				if cctx.Daemon == nil {
+					return fmt.Errorf("no daemon client")
+				}
+				ctx, cancel := context.WithTimeout(ctx, doctorDaemonPingTimeout)
+				defer cancel()
+				return cctx.Daemon.Ping(ctx)
+			},
+		},
+		doctorCheck{
+			Name: "clone root " + cloneRoot + " is writable",
+			Hint: "check the ownership and permissions of " + cloneRoot + ", or pass a different --app-base-dir",
+			Run: func(context.Context) error {
+				return checkDirWritable(cloneRoot)
+			},
+		},
+	)
+}
+
+// checkSSHInclude reports an error if sshConfig does not include sand's ssh
+// config. It never modifies sshConfig.
+func checkSSHInclude(ctx context.Context, sshConfig string) error {
+	// CheckForIncludeWithOptions creates a missing config file, so check for it first.
+	if _, err := os.Stat(sshConfig); err != nil {
+		return err
+	}
+	update, err := sshimmer.CheckForIncludeWithOptions(ctx, &sshimmer.RealFileSystem{}, sshimmer.IncludeCheckOptions{FixPosition: true})
+	if err != nil {
+		return err
+	}
+	if update != nil {
+		return fmt.Errorf("sand's ssh config Include line is missing or not at the top of the file")
+	}
+	return nil
+}
+
+// checkDirWritable creates dir if needed and verifies that files can be created in it.
+func checkDirWritable(dir string) error {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, ".sand-doctor-*")
+	if err != nil {
+		return err
+	}
+	name := f.Name()
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Remove(name)
+}
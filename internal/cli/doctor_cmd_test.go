@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunDoctorChecksReportsEveryCheck(t *testing.T) {
+	var ran []string
+	check := func(name string, err error) doctorCheck {
+		return doctorCheck{
+			Name: name,
+			Hint: "fix " + name,
+			Run: func(context.Context) error {
+				ran = append(ran, name)
+				return err
+			},
+		}
+	}
+	var out bytes.Buffer
+	err := runDoctorChecks(context.Background(), &out, []doctorCheck{
+		check("first", nil),
+		check("second", errors.New("broken")),
+		check("third", nil),
+		check("fourth", errors.New("also broken")),
+	})
+	if err == nil {
+		t.Fatal("runDoctorChecks() error = nil, want failure")
+	}
+	if got, want := err.Error(), "2 of 4 checks failed"; got != want {
+		t.Fatalf("runDoctorChecks() error = %q, want %q", got, want)
+	}
+	if got := strings.Join(ran, ","); got != "first,second,third,fourth" {
+		t.Fatalf("ran checks %s, want all four in order", got)
+	}
+	want := strings.Join([]string{
+		"ok    first",
+		"FAIL  second: broken",
+		"      hint: fix second",
+		"ok    third",
+		"FAIL  fourth: also broken",
+		"      hint: fix fourth",
+		"",
+	}, "\n")
+	if out.String() != want {
+		t.Fatalf("output =\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestRunDoctorChecksAllPass(t *testing.T) {
+	var out bytes.Buffer
+	err := runDoctorChecks(context.Background(), &out, []doctorCheck{
+		{Name: "only", Hint: "unused", Run: func(context.Context) error { return nil }},
+	})
+	if err != nil {
+		t.Fatalf("runDoctorChecks() error = %v", err)
+	}
+	if strings.Contains(out.String(), "hint") {
+		t.Fatalf("output includes a hint for a passing check: %q", out.String())
+	}
+}
+
+func TestCheckDirWritable(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "clones")
+	if err := checkDirWritable(dir); err != nil {
+		t.Fatalf("checkDirWritable(%s) error = %v", dir, err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("checkDirWritable left files behind: %v, %v", entries, err)
+	}
+
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkDirWritable(file); err == nil {
+		t.Fatal("checkDirWritable(file) error = nil, want error")
+	}
+}
+
+func TestCheckSSHIncludeDoesNotCreateMissingConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	sshConfig := filepath.Join(home, ".ssh", "config")
+
+	if err := checkSSHInclude(context.Background(), sshConfig); err == nil {
+		t.Fatal("checkSSHInclude() error = nil, want error for missing config")
+	}
+	if _, err := os.Stat(sshConfig); !os.IsNotExist(err) {
+		t.Fatalf("checkSSHInclude created %s: %v", sshConfig, err)
+	}
+}
+
+func TestCheckSSHInclude(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	sshConfig := filepath.Join(home, ".ssh", "config")
+	if err := os.MkdirAll(filepath.Dir(sshConfig), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(sshConfig, []byte("Host example\n  User me\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkSSHInclude(context.Background(), sshConfig); err == nil {
+		t.Fatal("checkSSHInclude() error = nil, want error without Include line")
+	}
+
+	include := "Include " + filepath.Join(home, ".config", "sand", "ssh_config") + "\n"
+	if err := os.WriteFile(sshConfig, []byte(include+"Host example\n  User me\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkSSHInclude(context.Background(), sshConfig); err != nil {
+		t.Fatalf("checkSSHInclude() error = %v", err)
+	}
+}
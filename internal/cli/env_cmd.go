@@ -0,0 +1,147 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/banksean/sand/internal/sandtypes"
+)
+
+var envCmdStdout io.Writer = os.Stdout
+
+type EnvCmd struct {
+	SandboxNameFlag
+	ProjectEnvFlag
+	EnvFlag
+	JSON bool `name:"json" help:"print the environment as a JSON object instead of KEY=VALUE lines"`
+}
+
+// sandboxEnv is what sand env reports: the environment a plain sand exec sees
+// in the sandbox's container, and the env file sand read it from.
+type sandboxEnv struct {
+	EnvFile string          `json:"envFile,omitempty"`
+	Vars    []sandboxEnvVar `json:"vars"`
+}
+
+// sandboxEnvVar is one variable of a sandboxEnv. Source names what set it when
+// sand did, e.g. "env-file" or "--env"; it is empty for variables that came
+// from the container itself.
+type sandboxEnvVar struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Source string `json:"source,omitempty"`
+}
+
+// envSource is a set of variables sand passes to commands in a sandbox.
+type envSource struct {
+	name string
+	env  map[string]string
+}
+
+func (c *EnvCmd) Run(cctx *CLIContext) error {
+	ctx := cctx.Context
+	mc := cctx.Daemon
+
+	flagEnv, err := parseEnvFlags(c.Env)
+	if err != nil {
+		return err
+	}
+	sbox, err := mc.GetSandbox(ctx, c.SandboxName)
+	if err != nil {
+		slog.ErrorContext(ctx, "GetSandbox", "error", err, "name", c.SandboxName)
+		return err
+	}
+	if sbox == nil {
+		return fmt.Errorf("sandbox not found: %s", c.SandboxName)
+	}
+	projectEnv, err := plainCommandProjectEnv(sbox, c.ProjectEnv)
+	if err != nil {
+		return err
+	}
+	defer projectEnv.Cleanup()
+	fileEnv, err := readEnvFile(projectEnv.EnvFile)
+	if err != nil {
+		return err
+	}
+
+	out, err := runSSHOutput(ctx, sbox, projectEnv.EnvFile, mergeEnv(projectEnv.Env, flagEnv), "env")
+	if err != nil {
+		return fmt.Errorf("running env in sandbox %s: %w: %s", sbox.Name, err, strings.TrimSpace(out))
+	}
+	env := sandboxEnv{
+		Vars: attributeEnv(parseEnvOutput(out),
+			envSource{name: "--env", env: flagEnv},
+			envSource{name: "project-env", env: projectEnv.Env},
+			envSource{name: "sand", env: mergeEnv(sandboxProxyEnv(sbox), map[string]string{"HOSTNAME": sandtypes.GetContainerHostname(sbox.Container)})},
+			envSource{name: "env-file", env: fileEnv},
+		),
+	}
+	// A project env file is a temporary copy, gone once this command exits.
+	if projectEnv.EnvFile != "" && projectEnv.EnvFile == sbox.EnvFile {
+		env.EnvFile = sbox.EnvFile
+	}
+	if c.JSON {
+		return writeJSON(envCmdStdout, env)
+	}
+	return renderSandboxEnv(envCmdStdout, env)
+}
+
+// parseEnvOutput parses the output of env(1). A line that doesn't start a new
+// NAME= assignment continues the value on the line before it, since values
+// may contain newlines.
+func parseEnvOutput(out string) map[string]string {
+	env := map[string]string{}
+	last := ""
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\n"), "\n") {
+		if key, value, ok := strings.Cut(line, "="); ok && validEnvKey(key) {
+			env[key] = value
+			last = key
+			continue
+		}
+		if last != "" {
+			env[last] += "\n" + line
+		}
+	}
+	return env
+}
+
+// attributeEnv lists env sorted by name, crediting each variable to the
+// first of sources, in precedence order, that set it to the value the
+// container reported.
+func attributeEnv(env map[string]string, sources ...envSource) []sandboxEnvVar {
+	vars := make([]sandboxEnvVar, 0, len(env))
+	for name, value := range env {
+		v := sandboxEnvVar{Name: name, Value: value}
+		for _, source := range sources {
+			if sourceValue, ok := source.env[name]; ok && sourceValue == value {
+				v.Source = source.name
+				break
+			}
+		}
+		vars = append(vars, v)
+	}
+	sort.Slice(vars, func(i, j int) bool { return vars[i].Name < vars[j].Name })
+	return vars
+}
+
+func renderSandboxEnv(w io.Writer, env sandboxEnv) error {
+	if env.EnvFile != "" {
+		if _, err := fmt.Fprintf(w, "# env file: %s\n", env.EnvFile); err != nil {
+			return err
+		}
+	}
+	for _, v := range env.Vars {
+		line := v.Name + "=" + v.Value
+		if v.Source != "" {
+			line += "  # " + v.Source
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
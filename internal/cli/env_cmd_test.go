@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/banksean/sand/internal/daemon/daemontest"
+	"github.com/banksean/sand/internal/hostops"
+	"github.com/banksean/sand/internal/sandtypes"
+)
+
+func TestParseEnvOutput(t *testing.T) {
+	got := parseEnvOutput("HOME=/home/alice\nMOTD=line one\nline two\nEMPTY=\nURL=http://x?a=b\n")
+	want := map[string]string{
+		"HOME":  "/home/alice",
+		"MOTD":  "line one\nline two",
+		"EMPTY": "",
+		"URL":   "http://x?a=b",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("parseEnvOutput() = %#v, want %#v", got, want)
+	}
+}
+
+func TestAttributeEnvCreditsHighestPrecedenceMatchingSource(t *testing.T) {
+	got := attributeEnv(
+		map[string]string{"PATH": "/bin", "FOO": "flag", "BAR": "file", "BAZ": "changed-by-profile"},
+		envSource{name: "--env", env: map[string]string{"FOO": "flag"}},
+		envSource{name: "env-file", env: map[string]string{"FOO": "file", "BAR": "file", "BAZ": "file"}},
+	)
+	want := []sandboxEnvVar{
+		{Name: "BAR", Value: "file", Source: "env-file"},
+		{Name: "BAZ", Value: "changed-by-profile"},
+		{Name: "FOO", Value: "flag", Source: "--env"},
+		{Name: "PATH", Value: "/bin"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("attributeEnv() = %#v, want %#v", got, want)
+	}
+}
+
+func TestEnvCmdMergesContainerEnvWithEnvFile(t *testing.T) {
+	envFile := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(envFile, []byte("FOO=from-file\nBAR=from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	client := daemontest.StartDaemon(t, daemontest.Deps{
+		ContainerService: &hostops.MockContainerOps{
+			InspectFunc: func(ctx context.Context, containerID string) ([]sandtypes.Container, error) {
+				return []sandtypes.Container{{
+					Configuration: sandtypes.ContainerConfig{ID: "target.local"},
+					Status:        sandtypes.ContainerStatus{State: "running"},
+				}}, nil
+			},
+		},
+	}, func(ctx context.Context, s daemontest.SandboxStore) {
+		sbox := newTestBox("target")
+		sbox.EnvFile = envFile
+		s.SaveSandbox(ctx, sbox)
+	})
+	cctx := &CLIContext{Context: context.Background(), Daemon: client}
+
+	var calls [][]string
+	restore := stubSSH(t, &calls, []string{"PATH=/usr/bin:/bin\nFOO=from-file\nBAR=override\nHOSTNAME=target.local\n"}, []int{0})
+	defer restore()
+	var stdout bytes.Buffer
+	oldStdout := envCmdStdout
+	envCmdStdout = &stdout
+	defer func() { envCmdStdout = oldStdout }()
+
+	cmd := &EnvCmd{
+		SandboxNameFlag: SandboxNameFlag{SandboxName: "target"},
+		ProjectEnvFlag:  ProjectEnvFlag{ProjectEnv: true},
+		EnvFlag:         EnvFlag{Env: []string{"BAR=override"}},
+		JSON:            true,
+	}
+	if err := cmd.Run(cctx); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	wantRemote := "cd '/app' && env 'BAR=override' 'FOO=from-file' 'HOSTNAME=target.local' 'env'"
+	if len(calls) != 1 || calls[0][len(calls[0])-1] != wantRemote {
+		t.Fatalf("ssh calls = %#v, want one running %q", calls, wantRemote)
+	}
+
+	var got sandboxEnv
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal %q: %v", stdout.String(), err)
+	}
+	want := sandboxEnv{
+		EnvFile: envFile,
+		Vars: []sandboxEnvVar{
+			{Name: "BAR", Value: "override", Source: "--env"},
+			{Name: "FOO", Value: "from-file", Source: "env-file"},
+			{Name: "HOSTNAME", Value: "target.local", Source: "sand"},
+			{Name: "PATH", Value: "/usr/bin:/bin"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("sand env --json = %+v, want %+v", got, want)
+	}
+}
+
+func TestRenderSandboxEnv(t *testing.T) {
+	var buf bytes.Buffer
+	err := renderSandboxEnv(&buf, sandboxEnv{
+		EnvFile: "/home/alice/project/.env",
+		Vars: []sandboxEnvVar{
+			{Name: "FOO", Value: "bar", Source: "env-file"},
+			{Name: "PATH", Value: "/bin"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# env file: /home/alice/project/.env\nFOO=bar  # env-file\nPATH=/bin\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("renderSandboxEnv() = %q, want %q", got, want)
+	}
+}
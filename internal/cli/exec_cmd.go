@@ -2,36 +2,53 @@ package cli
 
 import (
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"os/user"
 	"path/filepath"
-	"time"
 
 	"github.com/banksean/sand/internal/daemon"
-	"github.com/goombaio/namegenerator"
 )
 
 type ExecCmd struct {
 	SandboxCreationFlags
 	ProjectEnvFlag
+	EnvFlag
+	RootFlag
+	WorkDirFlag
 	SandboxNameFlag
 	Username string   `help:"name of user to exec as (defaults to $USER)"`
 	Uid      string   `help:"id of user to exec as (defaults to $UID)"`
+	NoTTY    bool     `name:"no-tty" help:"don't allocate a pseudo-terminal, even if stdin and stdout are terminals"`
 	Arg      []string `arg:"" passthrough:"" help:"command args to exec in the container"`
 }
 
+// execUsesTTY reports whether sand exec should allocate a pseudo-terminal for
+// the remote command. A pty is only useful when both ends are a terminal; piped
+// input or output gets raw bytes so it stays free of terminal control sequences.
+func execUsesTTY(noTTY bool, stdin, stdout *os.File) bool {
+	return !noTTY && isTerminal(stdin) && isTerminal(stdout)
+}
+
 func (c *ExecCmd) Run(cctx *CLIContext) error {
 	ctx := cctx.Context
 	mc := cctx.Daemon
 
+	flagEnv, err := parseEnvFlags(c.Env)
+	if err != nil {
+		return err
+	}
+	if err := c.WorkDirFlag.validate(); err != nil {
+		return err
+	}
 	cwd, err := os.Getwd()
 	if err != nil {
 		slog.ErrorContext(ctx, "os.Getwd", "error", err)
 		return err
 	}
-	if c.CloneFromDir == "" {
-		c.CloneFromDir = cwd
+	if err := c.resolveCloneFromDir(cwd); err != nil {
+		return err
 	}
 	if c.EnvFile != "" && !filepath.IsAbs(c.EnvFile) {
 		c.EnvFile = filepath.Join(c.CloneFromDir, c.EnvFile)
@@ -39,10 +56,9 @@ func (c *ExecCmd) Run(cctx *CLIContext) error {
 
 	// Generate a name if not provided
 	if c.SandboxName == "" {
-		seed := time.Now().UTC().UnixNano()
-		nameGenerator := namegenerator.NewNameGenerator(seed)
-
-		c.SandboxName = nameGenerator.Generate()
+		if c.SandboxName, err = newSandboxName(ctx, mc); err != nil {
+			return err
+		}
 	}
 
 	if c.ImageName == "" {
@@ -58,10 +74,20 @@ func (c *ExecCmd) Run(cctx *CLIContext) error {
 	if c.Uid == "" {
 		c.Uid = userInfo.Uid
 	}
+	tty := execUsesTTY(c.NoTTY, os.Stdin, os.Stdout)
+	// Keep stdout clean for the command's output when it is being piped.
+	var progress io.Writer = os.Stdout
+	if !tty {
+		progress = os.Stderr
+	}
+
 	// Try to get existing sandbox
 	sbox, err := mc.GetSandbox(ctx, c.SandboxName)
 	if sbox == nil || err != nil {
 		// Sandbox doesn't exist, create it via daemon
+		if err := c.checkResources(ctx, os.Stderr); err != nil {
+			return err
+		}
 		slog.InfoContext(ctx, "Creating new sandbox via daemon", "name", c.SandboxName)
 		sbox, err = mc.CreateSandbox(ctx, daemon.CreateSandboxOpts{
 			Name:         c.SandboxName,
@@ -70,6 +96,7 @@ func (c *ExecCmd) Run(cctx *CLIContext) error {
 			ImageName:    c.ImageName,
 			EnvFile:      c.EnvFile,
 			SSHAgent:     c.SSHAgent,
+			Network:      c.Network,
 			Mounts:       c.Mount,
 			CloneMounts:  c.CloneMount,
 			SharedCaches: cctx.SharedCaches,
@@ -77,7 +104,8 @@ func (c *ExecCmd) Run(cctx *CLIContext) error {
 			Memory:       c.Memory,
 			Username:     c.Username,
 			Uid:          c.Uid,
-		}, os.Stdout)
+			MessageLevel: cctx.MessageLevel,
+		}, progress)
 		if err != nil {
 			slog.ErrorContext(ctx, "CreateSandbox", "error", err)
 			return err
@@ -89,6 +117,9 @@ func (c *ExecCmd) Run(cctx *CLIContext) error {
 	}
 
 	// At this point the sandbox and container exist and are running (created by daemon)
+	// --username only picks the default user of a sandbox this creates;
+	// --root picks who this one command runs as.
+	c.RootFlag.apply(sbox)
 
 	slog.InfoContext(ctx, "main: sbox.exec starting")
 
@@ -104,9 +135,10 @@ func (c *ExecCmd) Run(cctx *CLIContext) error {
 		return err
 	}
 	defer projectEnv.Cleanup()
-	out, err := runSSHOutput(ctx, sbox, projectEnv.EnvFile, projectEnv.Env, c.Arg[0], args...)
-	if err != nil {
-		slog.ErrorContext(ctx, "sbox.exec", "error", err, "out", out)
+	markSandboxUsed(ctx, mc, sbox)
+	execErr := runSSHExec(ctx, sbox, tty, c.dir(), projectEnv.EnvFile, mergeEnv(projectEnv.Env, flagEnv), c.Arg[0], args...)
+	if execErr != nil {
+		slog.ErrorContext(ctx, "sbox.exec", "error", execErr, "tty", tty)
 	}
 
 	if c.Rm {
@@ -117,6 +149,6 @@ func (c *ExecCmd) Run(cctx *CLIContext) error {
 		}
 		slog.InfoContext(ctx, "Cleanup complete. Exiting.")
 	}
-	fmt.Printf("%s\n", out)
-	return nil
+	// Exit with the command's own status so sand exec can be used in scripts.
+	return exitStatusError(execErr)
 }
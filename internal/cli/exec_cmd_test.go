@@ -0,0 +1,249 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/banksean/sand/internal/daemon/daemontest"
+	"github.com/banksean/sand/internal/hostops"
+	"github.com/banksean/sand/internal/sandtypes"
+)
+
+func TestExecUsesTTY(t *testing.T) {
+	stdin, stdout, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdin.Close()
+	defer stdout.Close()
+	tests := []struct {
+		name     string
+		noTTY    bool
+		terminal map[*os.File]bool
+		want     bool
+	}{
+		{name: "both terminals", terminal: map[*os.File]bool{stdin: true, stdout: true}, want: true},
+		{name: "no-tty flag", noTTY: true, terminal: map[*os.File]bool{stdin: true, stdout: true}, want: false},
+		{name: "piped stdin", terminal: map[*os.File]bool{stdout: true}, want: false},
+		{name: "piped stdout", terminal: map[*os.File]bool{stdin: true}, want: false},
+		{name: "no terminals", terminal: map[*os.File]bool{}, want: false},
+	}
+	oldIsTerminal := isTerminal
+	defer func() { isTerminal = oldIsTerminal }()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isTerminal = func(f *os.File) bool { return tt.terminal[f] }
+			if got := execUsesTTY(tt.noTTY, stdin, stdout); got != tt.want {
+				t.Fatalf("execUsesTTY() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunSSHExecAllocatesPTYOnlyForTTY(t *testing.T) {
+	sbox := &sandtypes.Box{
+		ID:   "sb-123",
+		Name: "sb-123",
+		Container: &sandtypes.Container{
+			Configuration: sandtypes.ContainerConfig{ID: "sb-123.local"},
+		},
+	}
+	remote := "cd '/app' && env 'HOSTNAME=sb-123.local' 'cat'"
+	for _, tt := range []struct {
+		name string
+		tty  bool
+		want []string
+	}{
+		{name: "tty", tty: true, want: []string{"-tt", "sb-123.local", remote}},
+		{name: "pipe", tty: false, want: []string{"sb-123.local", remote}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls [][]string
+			restore := stubSSH(t, &calls, []string{""}, []int{0})
+			defer restore()
+
+			if err := runSSHExec(context.Background(), sbox, tt.tty, "/app", "", nil, "cat"); err != nil {
+				t.Fatalf("runSSHExec() error = %v", err)
+			}
+			if len(calls) != 1 || !reflect.DeepEqual(calls[0], tt.want) {
+				t.Fatalf("ssh calls = %#v, want [%#v]", calls, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunSSHExecLogsInAsSandboxUser(t *testing.T) {
+	sbox := &sandtypes.Box{
+		ID:       "sb-123",
+		Name:     "sb-123",
+		Username: "alice",
+		Container: &sandtypes.Container{
+			Configuration: sandtypes.ContainerConfig{ID: "sb-123.local"},
+		},
+	}
+	var calls [][]string
+	restore := stubSSH(t, &calls, []string{""}, []int{0})
+	defer restore()
+
+	if err := runSSHExec(context.Background(), sbox, false, "/app", "", nil, "id"); err != nil {
+		t.Fatalf("runSSHExec() error = %v", err)
+	}
+	want := []string{"alice@sb-123.local", "cd '/app' && env 'HOSTNAME=sb-123.local' 'id'"}
+	if len(calls) != 1 || !reflect.DeepEqual(calls[0], want) {
+		t.Fatalf("ssh calls = %#v, want [%#v]", calls, want)
+	}
+}
+
+func TestRunSSHExecRunsInWorkDir(t *testing.T) {
+	sbox := &sandtypes.Box{
+		ID:   "sb-123",
+		Name: "sb-123",
+		Container: &sandtypes.Container{
+			Configuration: sandtypes.ContainerConfig{ID: "sb-123.local"},
+		},
+	}
+	var calls [][]string
+	restore := stubSSH(t, &calls, []string{""}, []int{0})
+	defer restore()
+
+	if err := runSSHExec(context.Background(), sbox, false, "/srv/api", "", nil, "make", "test"); err != nil {
+		t.Fatalf("runSSHExec() error = %v", err)
+	}
+	want := []string{"sb-123.local", "cd '/srv/api' && env 'HOSTNAME=sb-123.local' 'make' 'test'"}
+	if len(calls) != 1 || !reflect.DeepEqual(calls[0], want) {
+		t.Fatalf("ssh calls = %#v, want [%#v]", calls, want)
+	}
+}
+
+func TestExecCmdRejectsRelativeWorkDir(t *testing.T) {
+	cmd := &ExecCmd{WorkDirFlag: WorkDirFlag{WorkDir: "srv/api"}, Arg: []string{"true"}}
+	err := cmd.Run(&CLIContext{Context: context.Background()})
+	if err == nil || !strings.Contains(err.Error(), "--workdir srv/api: must be an absolute path") {
+		t.Fatalf("Run() error = %v, want a --workdir error", err)
+	}
+}
+
+func TestExecCmdExitsWithRemoteExitStatus(t *testing.T) {
+	client := daemontest.StartDaemon(t, daemontest.Deps{
+		ContainerService: &hostops.MockContainerOps{
+			InspectFunc: func(ctx context.Context, containerID string) ([]sandtypes.Container, error) {
+				return []sandtypes.Container{{
+					Configuration: sandtypes.ContainerConfig{ID: "target.local"},
+					Status:        sandtypes.ContainerStatus{State: "running"},
+				}}, nil
+			},
+		},
+	}, func(ctx context.Context, s daemontest.SandboxStore) {
+		s.SaveSandbox(ctx, newTestBox("target"))
+	})
+	cctx := &CLIContext{Context: context.Background(), Daemon: client}
+
+	for _, tt := range []struct {
+		name     string
+		exitCode int
+		wantErr  error
+	}{
+		{name: "success", exitCode: 0, wantErr: nil},
+		{name: "failure", exitCode: 3, wantErr: &ExitStatus{Code: 3}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls [][]string
+			restore := stubSSH(t, &calls, []string{""}, []int{tt.exitCode})
+			defer restore()
+
+			cmd := &ExecCmd{
+				SandboxNameFlag: SandboxNameFlag{SandboxName: "target"},
+				NoTTY:           true,
+				Arg:             []string{"test", "-f", "foo"},
+			}
+			err := cmd.Run(cctx)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Fatalf("Run() error = %#v, want %#v", err, tt.wantErr)
+			}
+			if len(calls) != 1 {
+				t.Fatalf("ssh calls = %#v, want one", calls)
+			}
+		})
+	}
+}
+
+func TestExecCmdRootOverridesSandboxUser(t *testing.T) {
+	client := daemontest.StartDaemon(t, daemontest.Deps{
+		ContainerService: &hostops.MockContainerOps{
+			InspectFunc: func(ctx context.Context, containerID string) ([]sandtypes.Container, error) {
+				return []sandtypes.Container{{
+					Configuration: sandtypes.ContainerConfig{ID: "target.local"},
+					Status:        sandtypes.ContainerStatus{State: "running"},
+				}}, nil
+			},
+		},
+	}, func(ctx context.Context, s daemontest.SandboxStore) {
+		sbox := newTestBox("target")
+		sbox.Username = "alice"
+		sbox.Uid = "501"
+		s.SaveSandbox(ctx, sbox)
+	})
+	cctx := &CLIContext{Context: context.Background(), Daemon: client}
+
+	for _, tt := range []struct {
+		name string
+		root bool
+		want string
+	}{
+		{name: "default user", root: false, want: "alice@target.local"},
+		{name: "root", root: true, want: "root@target.local"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls [][]string
+			restore := stubSSH(t, &calls, []string{""}, []int{0})
+			defer restore()
+
+			cmd := &ExecCmd{
+				SandboxNameFlag: SandboxNameFlag{SandboxName: "target"},
+				RootFlag:        RootFlag{Root: tt.root},
+				NoTTY:           true,
+				Arg:             []string{"apk", "add", "htop"},
+			}
+			if err := cmd.Run(cctx); err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if len(calls) != 1 || calls[0][0] != tt.want {
+				t.Fatalf("ssh calls = %#v, want one to %s", calls, tt.want)
+			}
+		})
+	}
+}
+
+func TestExitStatusError(t *testing.T) {
+	if err := exitStatusError(nil); err != nil {
+		t.Fatalf("exitStatusError(nil) = %v, want nil", err)
+	}
+	other := errors.New("ssh: connect failed")
+	if err := exitStatusError(other); err != other {
+		t.Fatalf("exitStatusError(%v) = %v, want it unchanged", other, err)
+	}
+	runErr := exec.Command("sh", "-c", "exit 42").Run()
+	var exitStatus *ExitStatus
+	if err := exitStatusError(runErr); !errors.As(err, &exitStatus) || exitStatus.ExitCode() != 42 {
+		t.Fatalf("exitStatusError(%v) = %v, want exit status 42", runErr, err)
+	}
+}
+
+func TestParseEnvFlags(t *testing.T) {
+	got, err := parseEnvFlags([]string{"FOO=1", "URL=http://x?a=b", "FOO=2", "_EMPTY="})
+	if err != nil {
+		t.Fatalf("parseEnvFlags() error = %v", err)
+	}
+	want := map[string]string{"FOO": "2", "URL": "http://x?a=b", "_EMPTY": ""}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("parseEnvFlags() = %v, want %v", got, want)
+	}
+	if got, err := parseEnvFlags(nil); got != nil || err != nil {
+		t.Fatalf("parseEnvFlags(nil) = %v, %v; want nil, nil", got, err)
+	}
+}
@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/banksean/sand/internal/sandtypes"
+)
+
+var forwardCmdStdout io.Writer = os.Stdout
+
+type ForwardCmd struct {
+	SandboxNameFlag
+	Spec   string `arg:"" optional:"" placeholder:"<host-port:container-port>" help:"ports to forward, e.g. 8080:3000; a single port forwards the same port on both ends"`
+	Remote bool   `short:"R" help:"forward the container port to the host port (ssh -R) instead of the host port into the container (ssh -L)"`
+	List   bool   `short:"l" help:"list the sandbox's port forwards"`
+	Stop   bool   `help:"stop the forward given by the spec, or all of the sandbox's forwards if no spec is given"`
+}
+
+func (c *ForwardCmd) Run(cctx *CLIContext) error {
+	ctx := cctx.Context
+	mc := cctx.Daemon
+
+	if c.List && c.Stop {
+		return fmt.Errorf("--list and --stop cannot be combined")
+	}
+	var fwd *sandtypes.PortForward
+	if c.Spec != "" {
+		f, err := sandtypes.ParsePortForward(c.Spec, c.Remote)
+		if err != nil {
+			return err
+		}
+		fwd = &f
+	}
+
+	switch {
+	case c.List:
+		if fwd != nil {
+			return fmt.Errorf("--list does not take a forward spec")
+		}
+		forwards, err := mc.ListPortForwards(ctx, c.SandboxName)
+		if err != nil {
+			return err
+		}
+		renderPortForwards(forwardCmdStdout, forwards)
+	case c.Stop:
+		stopped, err := mc.StopPortForwards(ctx, c.SandboxName, fwd)
+		if err != nil {
+			return err
+		}
+		for _, f := range stopped {
+			fmt.Fprintf(forwardCmdStdout, "stopped %s\n", describePortForward(f))
+		}
+	default:
+		if fwd == nil {
+			return fmt.Errorf("a forward spec such as 8080:3000 is required unless --list or --stop is set")
+		}
+		started, err := mc.StartPortForward(ctx, c.SandboxName, *fwd)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(forwardCmdStdout, "%s (pid %d)\n", describePortForward(started), started.PID)
+	}
+	return nil
+}
+
+// describePortForward says which way traffic flows through fwd.
+func describePortForward(fwd sandtypes.PortForward) string {
+	if fwd.Remote {
+		return fmt.Sprintf("sandbox localhost:%d -> host localhost:%d", fwd.ContainerPort, fwd.HostPort)
+	}
+	return fmt.Sprintf("host localhost:%d -> sandbox localhost:%d", fwd.HostPort, fwd.ContainerPort)
+}
+
+func renderPortForwards(out io.Writer, forwards []sandtypes.PortForward) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "FLAG\tHOST PORT\tCONTAINER PORT\tPID")
+	for _, f := range forwards {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\n", f.Flag(), f.HostPort, f.ContainerPort, f.PID)
+	}
+	w.Flush()
+}
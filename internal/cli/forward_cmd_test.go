@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/banksean/sand/internal/daemon/daemontest"
+	"github.com/banksean/sand/internal/sandtypes"
+)
+
+func restoreForwardCmdStdout(t *testing.T, out *bytes.Buffer) {
+	t.Helper()
+	prev := forwardCmdStdout
+	forwardCmdStdout = out
+	t.Cleanup(func() { forwardCmdStdout = prev })
+}
+
+func TestForwardCmdListsAndStopsRecordedForward(t *testing.T) {
+	var out bytes.Buffer
+	restoreForwardCmdStdout(t, &out)
+
+	// A stand-in for the ssh process sandd would have started.
+	tunnel := exec.Command("sleep", "60")
+	tunnel.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if err := tunnel.Start(); err != nil {
+		t.Fatalf("start stand-in forward: %v", err)
+	}
+	exited := make(chan error, 1)
+	go func() { exited <- tunnel.Wait() }()
+	t.Cleanup(func() { tunnel.Process.Kill() })
+
+	fwd := sandtypes.PortForward{HostPort: 8080, ContainerPort: 3000}
+	cctx := newTestCLIContext(t, func(ctx context.Context, s daemontest.SandboxStore) {
+		box := newTestBox("web")
+		box.Name = "web"
+		if err := s.SaveSandbox(ctx, box); err != nil {
+			t.Fatalf("SaveSandbox() error = %v", err)
+		}
+		recorder := s.(interface {
+			RecordHostProcess(context.Context, *sandtypes.Box, string, int) error
+		})
+		if err := recorder.RecordHostProcess(ctx, box, fwd.ProcessName(), tunnel.Process.Pid); err != nil {
+			t.Fatalf("RecordHostProcess() error = %v", err)
+		}
+	})
+
+	list := &ForwardCmd{SandboxNameFlag: SandboxNameFlag{SandboxName: "web"}, List: true}
+	if err := list.Run(cctx); err != nil {
+		t.Fatalf("forward --list error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], "PID") {
+		t.Fatalf("forward --list output = %q, want a header and one forward", out.String())
+	}
+	if got := strings.Fields(lines[1]); len(got) != 4 || got[0] != "-L" || got[1] != "8080" || got[2] != "3000" || got[3] != strconv.Itoa(tunnel.Process.Pid) {
+		t.Fatalf("forward --list row = %q, want -L 8080 3000 %d", lines[1], tunnel.Process.Pid)
+	}
+
+	out.Reset()
+	stop := &ForwardCmd{SandboxNameFlag: SandboxNameFlag{SandboxName: "web"}, Spec: "8080:3000", Stop: true}
+	if err := stop.Run(cctx); err != nil {
+		t.Fatalf("forward --stop error = %v", err)
+	}
+	if got, want := out.String(), "stopped host localhost:8080 -> sandbox localhost:3000\n"; got != want {
+		t.Fatalf("forward --stop output = %q, want %q", got, want)
+	}
+	select {
+	case <-exited:
+	case <-time.After(5 * time.Second):
+		t.Fatal("forward process still running after forward --stop")
+	}
+}
+
+func TestForwardCmdRejectsBadInvocations(t *testing.T) {
+	cctx := newTestCLIContext(t, nil)
+	for _, cmd := range []*ForwardCmd{
+		{SandboxNameFlag: SandboxNameFlag{SandboxName: "web"}},
+		{SandboxNameFlag: SandboxNameFlag{SandboxName: "web"}, Spec: "web:3000"},
+		{SandboxNameFlag: SandboxNameFlag{SandboxName: "web"}, List: true, Stop: true},
+		{SandboxNameFlag: SandboxNameFlag{SandboxName: "web"}, List: true, Spec: "8080"},
+	} {
+		if err := cmd.Run(cctx); err == nil {
+			t.Errorf("ForwardCmd%+v.Run() error = nil, want error", *cmd)
+		}
+	}
+}
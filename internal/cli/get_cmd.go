@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/banksean/sand/internal/sandtypes"
+)
+
+var getCmdStdout io.Writer = os.Stdout
+
+type GetCmd struct {
+	SandboxNameFlag
+	JSON  bool   `name:"json" help:"print the sandbox as a JSON object instead of a table"`
+	Field string `placeholder:"<name>" help:"print only this field, e.g. ContainerID, SandboxWorkDir or SSHHostname"`
+}
+
+// getFields are the values sand get --field can print, keyed by lowercased
+// name. Most are Box fields of the same name.
+var getFields = map[string]func(*sandtypes.Box) (string, error){
+	"id":             func(b *sandtypes.Box) (string, error) { return b.ID, nil },
+	"name":           func(b *sandtypes.Box) (string, error) { return b.Name, nil },
+	"state":          func(b *sandtypes.Box) (string, error) { return b.State, nil },
+	"agenttype":      func(b *sandtypes.Box) (string, error) { return b.AgentType, nil },
+	"profilename":    func(b *sandtypes.Box) (string, error) { return b.ProfileName, nil },
+	"containerid":    func(b *sandtypes.Box) (string, error) { return b.ContainerID, nil },
+	"hostorigindir":  func(b *sandtypes.Box) (string, error) { return b.HostOriginDir, nil },
+	"sandboxworkdir": func(b *sandtypes.Box) (string, error) { return b.SandboxWorkDir, nil },
+	"imagename":      func(b *sandtypes.Box) (string, error) { return b.ImageName, nil },
+	"imagedigest":    func(b *sandtypes.Box) (string, error) { return b.ImageDigest, nil },
+	"dnsdomain":      func(b *sandtypes.Box) (string, error) { return b.DNSDomain, nil },
+	"username":       func(b *sandtypes.Box) (string, error) { return b.Username, nil },
+	"sshhostname": func(b *sandtypes.Box) (string, error) {
+		if b.Container == nil {
+			return "", errors.New("sandbox has no container; start it with sand start")
+		}
+		return sandtypes.GetContainerHostname(b.Container), nil
+	},
+}
+
+// getFieldNames are the --field names as users write them, for error messages.
+var getFieldNames = []string{
+	"AgentType", "ContainerID", "DNSDomain", "HostOriginDir", "ID", "ImageDigest", "ImageName",
+	"Name", "ProfileName", "SandboxWorkDir", "SSHHostname", "State", "Username",
+}
+
+func lookupGetField(field string) (func(*sandtypes.Box) (string, error), error) {
+	value, ok := getFields[strings.ToLower(field)]
+	if !ok {
+		return nil, fmt.Errorf("unknown field %q: want one of %s", field, strings.Join(getFieldNames, ", "))
+	}
+	return value, nil
+}
+
+func (c *GetCmd) Run(cctx *CLIContext) error {
+	ctx := cctx.Context
+	mc := cctx.Daemon
+
+	var fieldValue func(*sandtypes.Box) (string, error)
+	if c.Field != "" {
+		var err error
+		if fieldValue, err = lookupGetField(c.Field); err != nil {
+			return err
+		}
+	}
+
+	sbox, err := mc.GetSandbox(ctx, c.SandboxName)
+	if err != nil {
+		slog.ErrorContext(ctx, "GetSandbox", "error", err, "name", c.SandboxName)
+		return err
+	}
+	if sbox == nil {
+		return fmt.Errorf("sandbox not found: %s", c.SandboxName)
+	}
+	if fieldValue != nil {
+		value, err := fieldValue(sbox)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(getCmdStdout, value)
+		return err
+	}
+	if c.JSON {
+		return writeJSON(getCmdStdout, sbox)
+	}
+
+	userHomeDir, _ := os.UserHomeDir()
+	row := rowFromSandbox(*sbox, userHomeDir, nil)
+	if sbox.State == "deleted" {
+		return renderLsTable(getCmdStdout, nil, nil, []lsRow{row}, false)
+	}
+	return renderLsTable(getCmdStdout, []lsRow{row}, nil, nil, false)
+}
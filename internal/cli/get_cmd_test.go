@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/banksean/sand/internal/daemon/daemontest"
+	"github.com/banksean/sand/internal/sandtypes"
+)
+
+func restoreGetCmdStdout(t *testing.T, stdout *bytes.Buffer) {
+	t.Helper()
+	prev := getCmdStdout
+	getCmdStdout = stdout
+	t.Cleanup(func() { getCmdStdout = prev })
+}
+
+func TestGetCmdJSON(t *testing.T) {
+	cctx := newTestCLIContext(t, func(ctx context.Context, s daemontest.SandboxStore) {
+		s.SaveSandbox(ctx, newTestBox("target"))
+	})
+	var stdout bytes.Buffer
+	restoreGetCmdStdout(t, &stdout)
+
+	cmd := &GetCmd{SandboxNameFlag: SandboxNameFlag{SandboxName: "target"}, JSON: true}
+	if err := cmd.Run(cctx); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	var got sandtypes.Box
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal %q: %v", stdout.String(), err)
+	}
+	if got.ID != "target" || got.ImageName != "test-image:latest" {
+		t.Fatalf("got sandbox %+v, want target", got)
+	}
+}
+
+func TestGetCmdTable(t *testing.T) {
+	cctx := newTestCLIContext(t, func(ctx context.Context, s daemontest.SandboxStore) {
+		s.SaveSandbox(ctx, newTestBox("target"))
+	})
+	var stdout bytes.Buffer
+	restoreGetCmdStdout(t, &stdout)
+
+	cmd := &GetCmd{SandboxNameFlag: SandboxNameFlag{SandboxName: "target"}}
+	if err := cmd.Run(cctx); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if out := stdout.String(); !strings.HasPrefix(out, "NAME") || !strings.Contains(out, "target") {
+		t.Fatalf("stdout = %q, want table with target", out)
+	}
+}
+
+func TestGetCmdMissingSandbox(t *testing.T) {
+	cctx := newTestCLIContext(t, nil)
+	restoreGetCmdStdout(t, &bytes.Buffer{})
+
+	cmd := &GetCmd{SandboxNameFlag: SandboxNameFlag{SandboxName: "missing"}, JSON: true}
+	if err := cmd.Run(cctx); err == nil {
+		t.Fatal("Run() error = nil, want error for missing sandbox")
+	}
+}
+
+func TestGetCmdField(t *testing.T) {
+	cctx := newTestCLIContext(t, func(ctx context.Context, s daemontest.SandboxStore) {
+		sbox := newTestBox("target")
+		sbox.Name = "target-name"
+		sbox.ProfileName = "work"
+		sbox.ImageDigest = "sha256:abc"
+		sbox.DNSDomain = "sand.test"
+		sbox.Username = "alice"
+		s.SaveSandbox(ctx, sbox)
+	})
+
+	for field, want := range map[string]string{
+		"ID":             "target",
+		"Name":           "target-name",
+		"State":          "active",
+		"AgentType":      "default",
+		"ProfileName":    "work",
+		"ContainerID":    "ctr-target",
+		"HostOriginDir":  "/home/user/project",
+		"SandboxWorkDir": "/tmp/target",
+		"ImageName":      "test-image:latest",
+		"ImageDigest":    "sha256:abc",
+		"DNSDomain":      "sand.test",
+		"Username":       "alice",
+		"sandboxworkdir": "/tmp/target",
+	} {
+		var stdout bytes.Buffer
+		restoreGetCmdStdout(t, &stdout)
+		cmd := &GetCmd{SandboxNameFlag: SandboxNameFlag{SandboxName: "target-name"}, Field: field}
+		if err := cmd.Run(cctx); err != nil {
+			t.Fatalf("Run(--field %s) error = %v", field, err)
+		}
+		if got := stdout.String(); got != want+"\n" {
+			t.Errorf("--field %s printed %q, want %q", field, got, want+"\n")
+		}
+	}
+}
+
+func TestGetCmdFieldSSHHostname(t *testing.T) {
+	sbox := &sandtypes.Box{Container: &sandtypes.Container{Networks: []sandtypes.ContainerNetworkStatus{{Network: "default", Hostname: "target.sand.test."}}}}
+	value, err := lookupGetField("SSHHostname")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := value(sbox); err != nil || got != "target.sand.test" {
+		t.Fatalf("SSHHostname = %q, %v; want target.sand.test", got, err)
+	}
+	if _, err := value(&sandtypes.Box{}); err == nil {
+		t.Fatal("SSHHostname error = nil for a sandbox without a container")
+	}
+}
+
+func TestGetCmdUnknownField(t *testing.T) {
+	cctx := newTestCLIContext(t, func(ctx context.Context, s daemontest.SandboxStore) {
+		s.SaveSandbox(ctx, newTestBox("target"))
+	})
+	var stdout bytes.Buffer
+	restoreGetCmdStdout(t, &stdout)
+
+	cmd := &GetCmd{SandboxNameFlag: SandboxNameFlag{SandboxName: "target"}, Field: "Password"}
+	err := cmd.Run(cctx)
+	if err == nil || !strings.Contains(err.Error(), `unknown field "Password"`) || !strings.Contains(err.Error(), "SandboxWorkDir") {
+		t.Fatalf("Run() error = %v, want unknown field error listing the valid fields", err)
+	}
+	if stdout.Len() != 0 {
+		t.Fatalf("stdout = %q, want nothing", stdout.String())
+	}
+}
+
+func TestGetFieldNamesMatchGetFields(t *testing.T) {
+	if len(getFieldNames) != len(getFields) {
+		t.Fatalf("getFieldNames has %d names, getFields has %d", len(getFieldNames), len(getFields))
+	}
+	for _, name := range getFieldNames {
+		if _, err := lookupGetField(name); err != nil {
+			t.Errorf("getFieldNames lists %q, which getFields lacks", name)
+		}
+	}
+}
@@ -3,21 +3,29 @@ package cli
 import (
 	"context"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 
+	"github.com/banksean/sand/internal/cloning"
+	"github.com/banksean/sand/internal/hostops"
 	"github.com/banksean/sand/internal/runtimedeps"
+	"github.com/banksean/sand/internal/runtimepaths"
+	"github.com/banksean/sand/internal/sandtypes"
 )
 
 type GitCmd struct {
-	Diff     DiffCmd     `cmd:"" help:"diff current working directory with sandbox clone"`
-	Status   StatusCmd   `cmd:"" help:"show git status of sandbox working tree"`
-	Log      LogCmd      `cmd:"" help:"show git log of sandbox working tree"`
-	Sync     SyncCmd     `cmd:"" help:"pull committed sandbox changes into the host worktree"`
-	SyncHost SyncHostCmd `cmd:"" name:"sync-host" help:"update the shared mirror for a sandbox's original host repo"`
+	Diff      DiffCmd      `cmd:"" help:"diff current working directory with sandbox clone"`
+	Status    StatusCmd    `cmd:"" help:"show git status of sandbox working tree"`
+	Log       LogCmd       `cmd:"" help:"show git log of sandbox working tree"`
+	Sync      SyncCmd      `cmd:"" help:"pull committed sandbox changes into the host worktree"`
+	SyncHost  SyncHostCmd  `cmd:"" name:"sync-host" help:"update the shared mirror for a sandbox's original host repo"`
+	FetchHost FetchHostCmd `cmd:"" name:"fetch-host" help:"update the shared host mirror and fetch it into the sandbox clone"`
+	GC        GitGCCmd     `cmd:"" name:"gc" help:"remove sand/* remotes left in this repository by sandboxes that no longer exist"`
 }
 
 type StatusCmd struct {
@@ -26,12 +34,41 @@ type StatusCmd struct {
 
 type LogCmd struct {
 	SandboxNameFlag
+	GitFetchFlag
+}
+
+// GitFetchFlag is shared by git commands that read the sandbox clone's refs
+// from a cache they refresh with git fetch first. The fetch's output is shown
+// when it fails, or always under the global --verbose.
+type GitFetchFlag struct {
+	NoFetch bool `name:"no-fetch" help:"skip fetching from the sandbox clone and use the refs fetched last time"`
+}
+
+func (f GitFetchFlag) inspectionCache(cctx *CLIContext, sbox *sandtypes.Box) gitInspectionCache {
+	cache := newGitInspectionCache(cctx.Context, cctx.AppBaseDir, sbox)
+	cache.gitOps = cctx.gitOps()
+	cache.noFetch = f.NoFetch
+	if cctx.MessageLevel <= hostops.MessageDebug {
+		cache.fetchOutput = os.Stderr
+	}
+	return cache
 }
 
 type SyncHostCmd struct {
 	SandboxNameFlag
 }
 
+type FetchHostCmd struct {
+	SandboxName string `arg:"" optional:"" completion-predictor:"sandbox-name" help:"name of the sandbox (default: the current sandbox, when run inside one)"`
+	Remote      string `default:"origin" placeholder:"<remote>" help:"git remote in the sandbox clone to fetch"`
+}
+
+var gitGCStdout io.Writer = os.Stdout
+
+type GitGCCmd struct {
+	DryRun bool `help:"print the remotes that would be removed without removing them"`
+}
+
 type SyncCmd struct {
 	SandboxName   string `arg:"" completion-predictor:"sandbox-name" help:"name of the sandbox"`
 	HostBranch    string `arg:"" optional:"" placeholder:"<host branch name>" help:"host branch to create or update (default: sandbox name)"`
@@ -42,11 +79,34 @@ type DiffCmd struct {
 	SandboxNameFlag
 	Branch             string `short:"b" placeholder:"<branch name>" help:"remote branch to diff against (default: active git branch name in cwd)"`
 	IncludeUncommitted bool   `short:"u" default:"false" help:"include uncommitted changes from sandbox working tree"`
+	Stat               bool   `help:"show a diffstat instead of the patch"`
+	NameOnly           bool   `name:"name-only" help:"show only the names of changed files"`
+	GitFetchFlag
+	GitArgs []string `arg:"" optional:"" passthrough:"" placeholder:"-- <git diff args>" help:"extra arguments for git diff, after --"`
+}
+
+// gitDiffArgs returns the requested git diff format options, which go ahead
+// of the two snapshot directories being compared.
+func (c *DiffCmd) gitDiffArgs() []string {
+	var args []string
+	if c.Stat {
+		args = append(args, "--stat")
+	}
+	if c.NameOnly {
+		args = append(args, "--name-only")
+	}
+	// kong keeps the -- that introduces the passthrough arguments.
+	extra := c.GitArgs
+	if len(extra) > 0 && extra[0] == "--" {
+		extra = extra[1:]
+	}
+	return append(args, extra...)
 }
 
 func (c *DiffCmd) Run(cctx *CLIContext) error {
 	ctx := cctx.Context
 	mc := cctx.Daemon
+	gitOps := cctx.gitOps()
 
 	if err := runtimedeps.Verify(ctx, cctx.AppBaseDir, runtimedeps.GitDir, runtimedeps.GitRemoteIsSSH); err != nil {
 		return err
@@ -65,14 +125,9 @@ func (c *DiffCmd) Run(cctx *CLIContext) error {
 	}
 
 	if c.Branch == "" {
-		// get the active branch name in cwd.
-		cwdBranchCmd := exec.CommandContext(ctx, "git", "branch", "--show-current")
-		cwdBranchCmd.Dir = cwd
-		out, err := cwdBranchCmd.CombinedOutput()
-		if err != nil {
-			return fmt.Errorf("git branch --show-current failed: %w", err)
-		}
-		c.Branch = strings.TrimSpace(string(out))
+		// Diff against the sandbox branch named like the one checked out in
+		// cwd, or the sandbox's HEAD when cwd's HEAD is detached.
+		c.Branch = gitOps.Branch(ctx, cwd)
 	}
 
 	diffRoot, err := os.MkdirTemp("", "sand-diff-*")
@@ -100,7 +155,7 @@ func (c *DiffCmd) Run(cctx *CLIContext) error {
 			return fmt.Errorf("snapshot sandbox worktree: %w", err)
 		}
 	} else {
-		cache := newGitInspectionCache(ctx, cctx.AppBaseDir, sbox)
+		cache := c.inspectionCache(cctx, sbox)
 		cacheDir, err := cache.ensureUpdated()
 		if err != nil {
 			return err
@@ -114,14 +169,8 @@ func (c *DiffCmd) Run(cctx *CLIContext) error {
 		return fmt.Errorf("snapshot host worktree: %w", err)
 	}
 
-	gitDiff := newHardenedGit(ctx).command(diffRoot, "diff", "--no-index", "--no-ext-diff", "--src-prefix=sandbox/", "--dst-prefix=host/", "sandbox", "host")
-	slog.InfoContext(ctx, "GitCmd.DiffCmd", "gitDiff", strings.Join(gitDiff.Args, " "))
-	gitDiff.Stdout = os.Stdout
-	gitDiff.Stderr = os.Stderr
-	if err := gitDiff.Run(); err != nil {
-		if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() != 1 {
-			return fmt.Errorf("git diff failed: %w", err)
-		}
+	if _, err := gitOps.Diff(ctx, diffRoot, "sandbox", "host", c.gitDiffArgs(), os.Stdout, os.Stderr); err != nil {
+		return err
 	}
 
 	if sandboxHadUncommittedChanges {
@@ -167,12 +216,8 @@ func (c *StatusCmd) Run(cctx *CLIContext) error {
 
 	// Run git status in the sandbox working directory
 	sandboxAppDir := filepath.Join(sbox.SandboxWorkDir, "app")
-	gitStatus := newHardenedGit(ctx).command(sandboxAppDir, "status")
-	slog.InfoContext(ctx, "GitCmd.StatusCmd", "gitStatus", strings.Join(gitStatus.Args, " "), "dir", sandboxAppDir)
-	gitStatus.Stdout = os.Stdout
-	gitStatus.Stderr = os.Stderr
-	if err := gitStatus.Run(); err != nil {
-		return fmt.Errorf("git status failed: %w", err)
+	if err := cctx.gitOps().Status(ctx, sandboxAppDir, os.Stdout, os.Stderr); err != nil {
+		return err
 	}
 
 	// Print information about the sandbox
@@ -201,18 +246,14 @@ func (c *LogCmd) Run(cctx *CLIContext) error {
 		return fmt.Errorf("could not find sandbox named %s: %w", c.SandboxName, err)
 	}
 
-	cache := newGitInspectionCache(ctx, cctx.AppBaseDir, sbox)
+	cache := c.inspectionCache(cctx, sbox)
 	cacheDir, err := cache.ensureUpdated()
 	if err != nil {
 		return err
 	}
 
-	gitLog := newHardenedGit(ctx).command("", "--git-dir", cacheDir, "log", inspectionHeadRef)
-	slog.InfoContext(ctx, "GitCmd.LogCmd", "gitLog", strings.Join(gitLog.Args, " "), "dir", cacheDir)
-	gitLog.Stdout = os.Stdout
-	gitLog.Stderr = os.Stderr
-	if err := gitLog.Run(); err != nil {
-		return fmt.Errorf("git log failed: %w", err)
+	if err := cctx.gitOps().Log(ctx, cacheDir, inspectionHeadRef, os.Stdout, os.Stderr); err != nil {
+		return err
 	}
 
 	// Print information about the sandbox
@@ -239,6 +280,107 @@ func (c *SyncHostCmd) Run(cctx *CLIContext) error {
 	return nil
 }
 
+func (c *FetchHostCmd) Run(cctx *CLIContext) error {
+	ctx := cctx.Context
+	mc := cctx.Daemon
+
+	mirrorPath, err := mc.FetchHostChanges(ctx, c.SandboxName, c.Remote)
+	if err != nil {
+		return fmt.Errorf("fetch host changes: %w", err)
+	}
+	// The fetch itself runs in the container, where origin is the read-only
+	// mount of the mirror. Running it on the host would mean running git
+	// with the sandbox clone's config and hooks.
+	var out string
+	if cctx.AppBaseDir == runtimepaths.HostServicesDir {
+		out, err = fetchHostInContainer(ctx, c.Remote)
+	} else {
+		var sbox *sandtypes.Box
+		sbox, err = mc.GetSandbox(ctx, c.SandboxName)
+		if err != nil {
+			return fmt.Errorf("could not find sandbox named %s: %w", c.SandboxName, err)
+		}
+		out, err = runSSHOutput(ctx, sbox, "", nil, "git", "fetch", c.Remote)
+	}
+	if err != nil {
+		return fmt.Errorf("git fetch %s in sandbox: %w (output: %s)", c.Remote, err, strings.TrimSpace(out))
+	}
+	fmt.Fprintf(os.Stdout, "fetched %s from host git mirror: %s\n", c.Remote, mirrorPath)
+	return nil
+}
+
+// fetchHostInContainer runs git fetch remote in /app when sand git fetch-host
+// runs inside the sandbox's own container.
+var fetchHostInContainer = func(ctx context.Context, remote string) (string, error) {
+	out, err := exec.CommandContext(ctx, "git", "-C", containerAppDir, "fetch", remote).CombinedOutput()
+	return string(out), err
+}
+
+func (c *GitGCCmd) Run(cctx *CLIContext) error {
+	ctx := cctx.Context
+	gitOps := cctx.gitOps()
+
+	if err := runtimedeps.Verify(ctx, cctx.AppBaseDir, runtimedeps.GitDir); err != nil {
+		return err
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("could not get current working directory: %w", err)
+	}
+	hostRoot := gitOps.TopLevel(ctx, cwd)
+	if hostRoot == "" {
+		return fmt.Errorf("%s is not inside a git working tree", cwd)
+	}
+	remotes, err := gitOps.Remotes(ctx, hostRoot)
+	if err != nil {
+		return err
+	}
+	boxes, err := cctx.Daemon.ListSandboxes(ctx)
+	if err != nil {
+		return fmt.Errorf("listing sandboxes: %w", err)
+	}
+
+	for _, name := range orphanedSandboxRemotes(hostRoot, remotes, boxes) {
+		if c.DryRun {
+			fmt.Fprintf(gitGCStdout, "would remove %s (%s)\n", name, remotes[name])
+			continue
+		}
+		if err := gitOps.RemoveRemote(ctx, hostRoot, name); err != nil {
+			return fmt.Errorf("remove git remote %s: %w", name, err)
+		}
+		fmt.Fprintf(gitGCStdout, "removed %s (%s)\n", name, remotes[name])
+	}
+	return nil
+}
+
+// orphanedSandboxRemotes returns, sorted, the sand/* remotes in the repository
+// at hostRoot that no sandbox in boxes owns. A remote is a sandbox's if it is
+// named after the sandbox and points at its clone, so a remote left behind by
+// a deleted sandbox is orphaned even if a sandbox from another repository
+// reuses the name.
+func orphanedSandboxRemotes(hostRoot string, remotes map[string]string, boxes []sandtypes.Box) []string {
+	live := map[string]string{}
+	for _, box := range boxes {
+		name := box.Name
+		if name == "" {
+			name = box.ID
+		}
+		live[cloning.ClonedWorkDirGitRemotePrefix+name] = filepath.Join(box.SandboxWorkDir, "app")
+	}
+	var orphaned []string
+	for name, url := range remotes {
+		if !strings.HasPrefix(name, cloning.ClonedWorkDirGitRemotePrefix) {
+			continue
+		}
+		if appDir, ok := live[name]; ok && sameFilesystemPath(hostRoot, url, appDir) {
+			continue
+		}
+		orphaned = append(orphaned, name)
+	}
+	slices.Sort(orphaned)
+	return orphaned
+}
+
 func (c *SyncCmd) Run(cctx *CLIContext) error {
 	ctx := cctx.Context
 	mc := cctx.Daemon
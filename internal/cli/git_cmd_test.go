@@ -0,0 +1,263 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/banksean/sand/internal/daemon/daemontest"
+	"github.com/banksean/sand/internal/hostops"
+	"github.com/banksean/sand/internal/runtimepaths"
+	"github.com/banksean/sand/internal/sandtypes"
+)
+
+// gitOpsCall is one GitOps call a git command made, with the arguments that
+// name what it read.
+type gitOpsCall struct {
+	method string
+	args   []string
+}
+
+// recordingGitOps records the FetchRefs, Status, Log, Diff and Branch calls
+// made through it. Fetches still run, so commands that read the fetched refs
+// afterwards find them.
+func recordingGitOps(calls *[]gitOpsCall, branch string) *hostops.MockGitOps {
+	real := hostops.NewDefaultGitOps()
+	return &hostops.MockGitOps{
+		BranchFunc: func(_ context.Context, dir string) string {
+			*calls = append(*calls, gitOpsCall{"Branch", []string{dir}})
+			return branch
+		},
+		FetchRefsFunc: func(ctx context.Context, gitDir, source string, refspecs []string, output io.Writer) error {
+			*calls = append(*calls, gitOpsCall{"FetchRefs", append([]string{gitDir, source}, refspecs...)})
+			return real.FetchRefs(ctx, gitDir, source, refspecs, output)
+		},
+		StatusFunc: func(_ context.Context, dir string, _, _ io.Writer) error {
+			*calls = append(*calls, gitOpsCall{"Status", []string{dir}})
+			return nil
+		},
+		LogFunc: func(_ context.Context, gitDir, ref string, _, _ io.Writer) error {
+			*calls = append(*calls, gitOpsCall{"Log", []string{gitDir, ref}})
+			return nil
+		},
+		DiffFunc: func(_ context.Context, dir, a, b string, args []string, _, _ io.Writer) (bool, error) {
+			*calls = append(*calls, gitOpsCall{"Diff", append([]string{filepath.Base(dir), a, b}, args...)})
+			return true, nil
+		},
+	}
+}
+
+// gitCmdTestContext sets up a host checkout authenticated to its origin with
+// ssh, as the git commands require, and sandbox "box" cloned from it with a
+// "feature" branch, and runs from the checkout.
+func gitCmdTestContext(t *testing.T, calls *[]gitOpsCall, branch string) (cctx *CLIContext, hostDir, sandboxWorkDir string) {
+	t.Helper()
+	hostDir, sandboxWorkDir = setupSyncRepos(t, "feature")
+	git(t, hostDir, "remote", "add", "origin", "git@github.com:banksean/sand.git")
+	chdir(t, hostDir)
+	cctx = syncTestCLIContext(t, "box", hostDir, sandboxWorkDir)
+	cctx.AppBaseDir = t.TempDir()
+	cctx.GitOps = recordingGitOps(calls, branch)
+	return cctx, hostDir, sandboxWorkDir
+}
+
+func TestStatusCmdRunsStatusInTheSandboxClone(t *testing.T) {
+	var calls []gitOpsCall
+	cctx, _, sandboxWorkDir := gitCmdTestContext(t, &calls, "")
+	if err := (&StatusCmd{SandboxNameFlag: SandboxNameFlag{SandboxName: "box"}}).Run(cctx); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	want := []gitOpsCall{{"Status", []string{filepath.Join(sandboxWorkDir, "app")}}}
+	if !reflect.DeepEqual(calls, want) {
+		t.Fatalf("GitOps calls = %q, want %q", calls, want)
+	}
+}
+
+func TestLogCmdFetchesThenLogsTheSandboxHead(t *testing.T) {
+	var calls []gitOpsCall
+	cctx, _, sandboxWorkDir := gitCmdTestContext(t, &calls, "")
+	if err := (&LogCmd{SandboxNameFlag: SandboxNameFlag{SandboxName: "box"}}).Run(cctx); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	cacheDir := filepath.Join(cctx.AppBaseDir, "git-inspection", "id-box.git")
+	want := []gitOpsCall{
+		{"FetchRefs", []string{cacheDir, filepath.Join(sandboxWorkDir, "app"), "+refs/heads/*:refs/remotes/sandbox/*", "+HEAD:refs/sand/HEAD"}},
+		{"Log", []string{cacheDir, "refs/sand/HEAD"}},
+	}
+	if !reflect.DeepEqual(calls, want) {
+		t.Fatalf("GitOps calls = %q, want %q", calls, want)
+	}
+}
+
+func TestLogCmdNoFetchSkipsFetch(t *testing.T) {
+	var calls []gitOpsCall
+	cctx, _, _ := gitCmdTestContext(t, &calls, "")
+	cmd := &LogCmd{SandboxNameFlag: SandboxNameFlag{SandboxName: "box"}}
+	if err := cmd.Run(cctx); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	calls = nil
+	cmd.NoFetch = true
+	if err := cmd.Run(cctx); err != nil {
+		t.Fatalf("Run() with --no-fetch error = %v", err)
+	}
+	if len(calls) != 1 || calls[0].method != "Log" {
+		t.Fatalf("GitOps calls = %q, want only Log", calls)
+	}
+}
+
+func TestDiffCmdDiffsTheHostBranchAgainstTheSandboxBranch(t *testing.T) {
+	var calls []gitOpsCall
+	cctx, hostDir, sandboxWorkDir := gitCmdTestContext(t, &calls, "feature")
+	cmd := &DiffCmd{SandboxNameFlag: SandboxNameFlag{SandboxName: "box"}, Stat: true}
+	if err := cmd.Run(cctx); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	cacheDir := filepath.Join(cctx.AppBaseDir, "git-inspection", "id-box.git")
+	want := []gitOpsCall{
+		{"Branch", []string{hostDir}},
+		{"FetchRefs", []string{cacheDir, filepath.Join(sandboxWorkDir, "app"), "+refs/heads/*:refs/remotes/sandbox/*", "+HEAD:refs/sand/HEAD"}},
+		{"Diff", []string{"", "sandbox", "host", "--stat"}},
+	}
+	// Diff runs in a temporary directory; only its arguments are stable.
+	if len(calls) == len(want) {
+		calls[2].args[0] = ""
+	}
+	if !reflect.DeepEqual(calls, want) {
+		t.Fatalf("GitOps calls = %q, want %q", calls, want)
+	}
+}
+
+// gitGCTestRepo returns a checkout, run from, with an origin and a sand/*
+// remote for each of: a live sandbox, a deleted one, and a deleted one whose
+// name another repository's sandbox has since reused.
+func gitGCTestRepo(t *testing.T) (cctx *CLIContext, hostDir string) {
+	t.Helper()
+	hostDir = t.TempDir()
+	git(t, hostDir, "init", "-b", "main")
+	git(t, hostDir, "remote", "add", "origin", "git@github.com:banksean/sand.git")
+	liveWorkDir, reusedWorkDir := t.TempDir(), t.TempDir()
+	git(t, hostDir, "remote", "add", "sand/live", filepath.Join(liveWorkDir, "app"))
+	git(t, hostDir, "remote", "add", "sand/gone", filepath.Join(t.TempDir(), "app"))
+	git(t, hostDir, "remote", "add", "sand/reused", filepath.Join(t.TempDir(), "app"))
+	chdir(t, hostDir)
+
+	client := daemontest.StartDaemon(t, daemontest.Deps{}, func(ctx context.Context, s daemontest.SandboxStore) {
+		for name, workDir := range map[string]string{"live": liveWorkDir, "reused": reusedWorkDir} {
+			box := newTestBox(name)
+			box.Name = name
+			box.SandboxWorkDir = workDir
+			if err := s.SaveSandbox(ctx, box); err != nil {
+				t.Fatalf("SaveSandbox: %v", err)
+			}
+		}
+	})
+	return &CLIContext{Context: context.Background(), Daemon: client, AppBaseDir: t.TempDir()}, hostDir
+}
+
+func runGitGC(t *testing.T, cctx *CLIContext, cmd *GitGCCmd) string {
+	t.Helper()
+	var out bytes.Buffer
+	old := gitGCStdout
+	gitGCStdout = &out
+	defer func() { gitGCStdout = old }()
+	if err := cmd.Run(cctx); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	return out.String()
+}
+
+func TestGitGCRemovesOnlyRemotesWithoutALiveSandbox(t *testing.T) {
+	cctx, hostDir := gitGCTestRepo(t)
+	out := runGitGC(t, cctx, &GitGCCmd{})
+
+	if got := strings.Fields(gitOutput(t, hostDir, "remote")); !reflect.DeepEqual(got, []string{"origin", "sand/live"}) {
+		t.Fatalf("remotes after gc = %q, want origin and sand/live", got)
+	}
+	if !strings.Contains(out, "removed sand/gone") || !strings.Contains(out, "removed sand/reused") || strings.Contains(out, "sand/live") {
+		t.Fatalf("output = %q, want sand/gone and sand/reused reported as removed", out)
+	}
+}
+
+func TestGitGCDryRunKeepsRemotes(t *testing.T) {
+	cctx, hostDir := gitGCTestRepo(t)
+	out := runGitGC(t, cctx, &GitGCCmd{DryRun: true})
+
+	if got := strings.Fields(gitOutput(t, hostDir, "remote")); len(got) != 4 {
+		t.Fatalf("remotes after gc --dry-run = %q, want all four kept", got)
+	}
+	if !strings.Contains(out, "would remove sand/gone") || !strings.Contains(out, "would remove sand/reused") {
+		t.Fatalf("output = %q, want the orphaned remotes listed", out)
+	}
+}
+
+func TestFetchHostCmdFetchesInTheContainer(t *testing.T) {
+	box := newTestBox("sb-fetch")
+	box.Name = "sb-fetch"
+	box.Username = "dev"
+	box.HostOriginDir = t.TempDir()
+	box.SandboxWorkDir = t.TempDir()
+	client := daemontest.StartDaemon(t, daemontest.Deps{
+		ContainerService: &hostops.MockContainerOps{
+			InspectFunc: func(_ context.Context, containerID string) ([]sandtypes.Container, error) {
+				return []sandtypes.Container{{
+					Configuration: sandtypes.ContainerConfig{ID: "sb-fetch.local"},
+					Status:        sandtypes.ContainerStatus{State: "running"},
+				}}, nil
+			},
+		},
+		GitOps: &hostops.MockGitOps{
+			TopLevelFunc: func(_ context.Context, dir string) string { return dir },
+			FetchFunc: func(_ context.Context, dir, remote string) error {
+				t.Errorf("git fetch %s ran on the host in %s", remote, dir)
+				return nil
+			},
+			CloneMirrorFunc: func(_ context.Context, sourceDir, mirrorDir string) error {
+				return os.MkdirAll(mirrorDir, 0o755)
+			},
+		},
+		FileOps: &hostops.MockFileOps{StatFunc: os.Stat},
+	}, func(ctx context.Context, s daemontest.SandboxStore) {
+		if err := s.SaveSandbox(ctx, box); err != nil {
+			t.Fatalf("SaveSandbox: %v", err)
+		}
+	})
+
+	t.Run("from the host", func(t *testing.T) {
+		var calls [][]string
+		restore := stubSSH(t, &calls, nil, nil)
+		defer restore()
+
+		cmd := &FetchHostCmd{SandboxName: "sb-fetch", Remote: "origin"}
+		if err := cmd.Run(&CLIContext{Context: context.Background(), Daemon: client}); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		want := []string{"dev@sb-fetch.local", "cd '/app' && env 'HOSTNAME=sb-fetch.local' 'git' 'fetch' 'origin'"}
+		if len(calls) != 1 || !reflect.DeepEqual(calls[0], want) {
+			t.Fatalf("ssh calls = %q, want [%q]", calls, want)
+		}
+	})
+
+	t.Run("inside the sandbox", func(t *testing.T) {
+		var fetched []string
+		old := fetchHostInContainer
+		fetchHostInContainer = func(_ context.Context, remote string) (string, error) {
+			fetched = append(fetched, remote)
+			return "", nil
+		}
+		defer func() { fetchHostInContainer = old }()
+
+		cmd := &FetchHostCmd{SandboxName: "sb-fetch", Remote: "upstream"}
+		if err := cmd.Run(&CLIContext{Context: context.Background(), Daemon: client, AppBaseDir: runtimepaths.HostServicesDir}); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		if !reflect.DeepEqual(fetched, []string{"upstream"}) {
+			t.Fatalf("fetched in the container = %q, want [upstream]", fetched)
+		}
+	})
+}
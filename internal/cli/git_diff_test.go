@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffCmdGitDiffArgs(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{name: "plain", args: []string{"diff", "sb"}, want: nil},
+		{name: "stat", args: []string{"diff", "--stat", "sb"}, want: []string{"--stat"}},
+		{name: "name-only with uncommitted", args: []string{"diff", "-u", "--name-only", "sb"}, want: []string{"--name-only"}},
+		{name: "passthrough", args: []string{"diff", "sb", "--stat", "--", "-w", "--", "--word-diff"}, want: []string{"--stat", "-w", "--", "--word-diff"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var cli struct {
+				Diff DiffCmd `cmd:""`
+			}
+			kongParse(t, &cli, tt.args)
+			if got := cli.Diff.gitDiffArgs(); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("gitDiffArgs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -6,12 +6,12 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 
+	"github.com/banksean/sand/internal/hostops"
 	"github.com/banksean/sand/internal/sandtypes"
 )
 
@@ -29,55 +29,19 @@ func newHardenedGit(ctx context.Context) hardenedGit {
 }
 
 func (g hardenedGit) command(dir string, args ...string) *exec.Cmd {
-	base := []string{
-		"-c", "core.pager=cat",
-		"-c", "pager.status=false",
-		"-c", "pager.log=false",
-		"-c", "core.fsmonitor=false",
-		"-c", "core.untrackedCache=false",
-		"-c", "core.hooksPath=/dev/null",
-		"-c", "core.attributesFile=/dev/null",
-		"-c", "diff.external=",
-	}
-	cmd := exec.CommandContext(g.ctx, "git", append(base, args...)...)
-	cmd.Dir = dir
-	cmd.Env = hardenedGitEnv()
-	return cmd
-}
-
-func hardenedGitEnv() []string {
-	env := make([]string, 0, len(os.Environ())+12)
-	for _, kv := range os.Environ() {
-		key, _, ok := strings.Cut(kv, "=")
-		if !ok {
-			continue
-		}
-		if strings.HasPrefix(key, "GIT_") || key == "PAGER" || key == "SSH_ASKPASS" {
-			continue
-		}
-		env = append(env, kv)
-	}
-	return append(
-		env,
-		"HOME=/dev/null",
-		"XDG_CONFIG_HOME=/dev/null",
-		"GIT_CONFIG_NOSYSTEM=1",
-		"GIT_CONFIG_GLOBAL=/dev/null",
-		"GIT_CONFIG_SYSTEM=/dev/null",
-		"GIT_ATTR_NOSYSTEM=1",
-		"GIT_TERMINAL_PROMPT=0",
-		"GIT_PAGER=cat",
-		"PAGER=cat",
-		"GIT_EXTERNAL_DIFF=",
-		"GIT_ASKPASS=/bin/false",
-		"SSH_ASKPASS=/bin/false",
-	)
+	return hostops.HardenedGitCommand(g.ctx, dir, args...)
 }
 
 type gitInspectionCache struct {
 	appBaseDir string
 	sandbox    *sandtypes.Box
 	git        hardenedGit
+	gitOps     hostops.GitOps
+	// noFetch reuses the refs fetched last time instead of fetching again.
+	noFetch bool
+	// fetchOutput, if set, also gets the fetch's output, which is otherwise
+	// only reported when the fetch fails.
+	fetchOutput io.Writer
 }
 
 func newGitInspectionCache(ctx context.Context, appBaseDir string, sandbox *sandtypes.Box) gitInspectionCache {
@@ -85,6 +49,7 @@ func newGitInspectionCache(ctx context.Context, appBaseDir string, sandbox *sand
 		appBaseDir: appBaseDir,
 		sandbox:    sandbox,
 		git:        newHardenedGit(ctx),
+		gitOps:     hostops.NewDefaultGitOps(),
 	}
 }
 
@@ -115,15 +80,17 @@ func (c gitInspectionCache) ensureUpdated() (string, error) {
 		return "", fmt.Errorf("stat git inspection cache: %w", err)
 	}
 
+	if c.noFetch {
+		if c.git.command("", "--git-dir", cacheDir, "rev-parse", "--verify", "--quiet", inspectionHeadRef).Run() != nil {
+			return "", fmt.Errorf("no refs have been fetched from sandbox %s yet; run again without --no-fetch", c.sandbox.Name)
+		}
+		return cacheDir, nil
+	}
+
 	sandboxAppDir := filepath.Join(c.sandbox.SandboxWorkDir, "app")
-	cmd := c.git.command(
-		"", "--git-dir", cacheDir, "fetch", "--prune", sandboxAppDir,
-		"+refs/heads/*:"+inspectionRemoteRef+"*",
-		"+HEAD:"+inspectionHeadRef,
-	)
-	slog.InfoContext(c.git.ctx, "git inspection cache fetch", "cache", cacheDir, "sandbox", sandboxAppDir, "cmd", strings.Join(cmd.Args, " "))
-	if output, err := cmd.CombinedOutput(); err != nil {
-		return "", fmt.Errorf("fetch sandbox refs into git inspection cache: %w (output: %s)", err, output)
+	refspecs := []string{"+refs/heads/*:" + inspectionRemoteRef + "*", "+HEAD:" + inspectionHeadRef}
+	if err := c.gitOps.FetchRefs(c.git.ctx, cacheDir, sandboxAppDir, refspecs, c.fetchOutput); err != nil {
+		return "", fmt.Errorf("fetch sandbox refs into git inspection cache: %w", err)
 	}
 	return cacheDir, nil
 }
@@ -146,6 +113,10 @@ func (c gitInspectionCache) exportRef(cacheDir, ref, dst string) error {
 	return untar(&tarBytes, dst)
 }
 
+// sandboxWorktreeSnapshot copies the sandbox worktree, uncommitted changes
+// included, into dst using only read-only git commands. It never commits,
+// resets or stages anything in the sandbox, so a diff that is interrupted
+// part way leaves the sandbox's HEAD and index exactly as they were.
 func sandboxWorktreeSnapshot(ctx context.Context, sandboxAppDir, dst string) error {
 	git := newHardenedGit(ctx)
 	head := git.command(sandboxAppDir, "rev-parse", "--verify", "HEAD")
@@ -186,7 +157,9 @@ func sandboxWorktreeSnapshot(ctx context.Context, sandboxAppDir, dst string) err
 
 func sandboxHasUncommittedChanges(ctx context.Context, sandboxAppDir string) (bool, error) {
 	git := newHardenedGit(ctx)
-	cmd := git.command(sandboxAppDir, "status", "--porcelain", "--untracked-files=all")
+	// --no-optional-locks keeps status from refreshing the sandbox's index,
+	// and from leaving index.lock behind if sand is killed mid-call.
+	cmd := git.command(sandboxAppDir, "--no-optional-locks", "status", "--porcelain", "--untracked-files=all")
 	output, err := cmd.Output()
 	if err != nil {
 		return false, fmt.Errorf("git status --porcelain failed: %w", err)
@@ -1,43 +1,17 @@
 package cli
 
 import (
+	"bytes"
 	"context"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
-)
+	"time"
 
-func TestHardenedGitEnvScrubsGitEnvironment(t *testing.T) {
-	t.Setenv("GIT_DIR", "/tmp/hostile.git")
-	t.Setenv("GIT_CONFIG_GLOBAL", "/tmp/hostile-config")
-	t.Setenv("GIT_EXTERNAL_DIFF", "cat")
-	t.Setenv("PAGER", "less")
-
-	env := strings.Join(hardenedGitEnv(), "\n")
-	for _, forbidden := range []string{
-		"GIT_DIR=/tmp/hostile.git",
-		"GIT_CONFIG_GLOBAL=/tmp/hostile-config",
-		"GIT_EXTERNAL_DIFF=cat",
-		"PAGER=less",
-	} {
-		if strings.Contains(env, forbidden) {
-			t.Fatalf("hardened env contains %q:\n%s", forbidden, env)
-		}
-	}
-	for _, want := range []string{
-		"GIT_CONFIG_NOSYSTEM=1",
-		"GIT_CONFIG_GLOBAL=/dev/null",
-		"GIT_CONFIG_SYSTEM=/dev/null",
-		"GIT_TERMINAL_PROMPT=0",
-		"GIT_EXTERNAL_DIFF=",
-	} {
-		if !strings.Contains(env, want) {
-			t.Fatalf("hardened env missing %q:\n%s", want, env)
-		}
-	}
-}
+	"github.com/banksean/sand/internal/sandtypes"
+)
 
 func TestSandboxWorktreeSnapshotIncludesUncommittedWithoutMovingHead(t *testing.T) {
 	repo := t.TempDir()
@@ -66,6 +40,58 @@ func TestSandboxWorktreeSnapshotIncludesUncommittedWithoutMovingHead(t *testing.
 	}
 }
 
+func TestSandboxWorktreeSnapshotLeavesIndexUntouched(t *testing.T) {
+	repo := t.TempDir()
+	git(t, repo, "init", "-q", "-b", "main")
+	writeFile(t, filepath.Join(repo, "tracked.txt"), "committed\n")
+	writeFile(t, filepath.Join(repo, "clean.txt"), "clean\n")
+	git(t, repo, "add", "tracked.txt", "clean.txt")
+	git(t, repo, "-c", "user.name=Sand", "-c", "user.email=sand@example.com", "commit", "-q", "-m", "initial")
+
+	// Leave the sandbox with both staged and unstaged work, and make the
+	// index's stat data for an unchanged file stale, so that any git command
+	// that refreshes the index would rewrite it.
+	writeFile(t, filepath.Join(repo, "tracked.txt"), "staged\n")
+	git(t, repo, "add", "tracked.txt")
+	writeFile(t, filepath.Join(repo, "tracked.txt"), "unstaged\n")
+	writeFile(t, filepath.Join(repo, "untracked.txt"), "new\n")
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(filepath.Join(repo, "clean.txt"), past, past); err != nil {
+		t.Fatal(err)
+	}
+	indexPath := filepath.Join(repo, ".git", "index")
+	indexBefore := readFile(t, indexPath)
+	headBefore := gitOutput(t, repo, "rev-parse", "HEAD")
+	stagedBefore := gitOutput(t, repo, "diff", "--cached", "--name-status")
+
+	// Run the diff's repo inspection twice, as a diff rerun after an
+	// interrupted one would, and check the sandbox never changed.
+	for range 2 {
+		if _, err := sandboxHasUncommittedChanges(context.Background(), repo); err != nil {
+			t.Fatalf("sandboxHasUncommittedChanges: %v", err)
+		}
+		if err := sandboxWorktreeSnapshot(context.Background(), repo, t.TempDir()); err != nil {
+			t.Fatalf("sandboxWorktreeSnapshot: %v", err)
+		}
+	}
+
+	if got := readFile(t, indexPath); got != indexBefore {
+		t.Fatal("sandbox index was rewritten")
+	}
+	if _, err := os.Stat(filepath.Join(repo, ".git", "index.lock")); !os.IsNotExist(err) {
+		t.Fatalf("index.lock left behind: %v", err)
+	}
+	if got := gitOutput(t, repo, "rev-parse", "HEAD"); got != headBefore {
+		t.Fatalf("HEAD changed from %s to %s", headBefore, got)
+	}
+	if got := gitOutput(t, repo, "diff", "--cached", "--name-status"); got != stagedBefore {
+		t.Fatalf("staged changes = %q, want %q", got, stagedBefore)
+	}
+	if got := readFile(t, filepath.Join(repo, "tracked.txt")); got != "unstaged\n" {
+		t.Fatalf("worktree tracked.txt = %q", got)
+	}
+}
+
 func TestSandboxHasUncommittedChanges(t *testing.T) {
 	repo := t.TempDir()
 	git(t, repo, "init", "-q", "-b", "main")
@@ -101,6 +127,101 @@ func TestSandboxHasUncommittedChanges(t *testing.T) {
 	}
 }
 
+// newInspectionTestSandbox returns a sandbox whose clone has one commit.
+func newInspectionTestSandbox(t *testing.T) *sandtypes.Box {
+	t.Helper()
+	workDir := t.TempDir()
+	app := filepath.Join(workDir, "app")
+	if err := os.MkdirAll(app, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	git(t, app, "init", "-q", "-b", "main")
+	writeFile(t, filepath.Join(app, "tracked.txt"), "committed\n")
+	git(t, app, "add", "tracked.txt")
+	git(t, app, "-c", "user.name=Sand", "-c", "user.email=sand@example.com", "commit", "-q", "-m", "initial")
+	return &sandtypes.Box{ID: "inspect-id", Name: "inspect", SandboxWorkDir: workDir}
+}
+
+// captureStderr returns what f writes to os.Stderr.
+func captureStderr(t *testing.T, f func()) string {
+	t.Helper()
+	tmp, err := os.CreateTemp(t.TempDir(), "stderr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tmp.Close()
+	old := os.Stderr
+	os.Stderr = tmp
+	f()
+	os.Stderr = old
+	data, err := os.ReadFile(tmp.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestGitInspectionCacheCapturesFetchOutput(t *testing.T) {
+	sbox := newInspectionTestSandbox(t)
+	cache := newGitInspectionCache(context.Background(), t.TempDir(), sbox)
+	stderr := captureStderr(t, func() {
+		if _, err := cache.ensureUpdated(); err != nil {
+			t.Fatalf("ensureUpdated: %v", err)
+		}
+	})
+	if stderr != "" {
+		t.Fatalf("fetch streamed %q to stderr, want it captured", stderr)
+	}
+
+	var verbose bytes.Buffer
+	cache.fetchOutput = &verbose
+	git(t, filepath.Join(sbox.SandboxWorkDir, "app"), "branch", "feature")
+	if _, err := cache.ensureUpdated(); err != nil {
+		t.Fatalf("ensureUpdated: %v", err)
+	}
+	if !strings.Contains(verbose.String(), "feature") {
+		t.Fatalf("fetch output = %q, want the new branch reported", verbose.String())
+	}
+}
+
+func TestGitInspectionCacheReportsFetchOutputOnFailure(t *testing.T) {
+	sbox := &sandtypes.Box{ID: "broken-id", Name: "broken", SandboxWorkDir: t.TempDir()}
+	cache := newGitInspectionCache(context.Background(), t.TempDir(), sbox)
+	var err error
+	stderr := captureStderr(t, func() { _, err = cache.ensureUpdated() })
+	if err == nil || !strings.Contains(err.Error(), "does not appear to be a git repository") {
+		t.Fatalf("ensureUpdated() error = %v, want git's output in it", err)
+	}
+	if stderr != "" {
+		t.Fatalf("fetch streamed %q to stderr, want it only in the error", stderr)
+	}
+}
+
+func TestGitInspectionCacheNoFetchReusesLastFetch(t *testing.T) {
+	sbox := newInspectionTestSandbox(t)
+	appBaseDir := t.TempDir()
+	noFetch := newGitInspectionCache(context.Background(), appBaseDir, sbox)
+	noFetch.noFetch = true
+	if _, err := noFetch.ensureUpdated(); err == nil || !strings.Contains(err.Error(), "without --no-fetch") {
+		t.Fatalf("ensureUpdated() before any fetch error = %v, want a hint to fetch", err)
+	}
+
+	cacheDir, err := newGitInspectionCache(context.Background(), appBaseDir, sbox).ensureUpdated()
+	if err != nil {
+		t.Fatalf("ensureUpdated: %v", err)
+	}
+	fetched := gitOutput(t, "", "--git-dir", cacheDir, "rev-parse", inspectionHeadRef)
+
+	app := filepath.Join(sbox.SandboxWorkDir, "app")
+	git(t, app, "-c", "user.name=Sand", "-c", "user.email=sand@example.com", "commit", "-q", "--allow-empty", "-m", "second")
+	if _, err := noFetch.ensureUpdated(); err != nil {
+		t.Fatalf("ensureUpdated() with noFetch: %v", err)
+	}
+	if got := gitOutput(t, "", "--git-dir", cacheDir, "rev-parse", inspectionHeadRef); got != fetched {
+		t.Fatalf("%s = %s after --no-fetch, want the last fetched %s", inspectionHeadRef, got, fetched)
+	}
+}
+
 func git(t *testing.T, dir string, args ...string) {
 	t.Helper()
 	cmd := exec.Command("git", args...)
@@ -17,6 +17,7 @@ import (
 	"github.com/banksean/sand/internal/sandtypes"
 	"github.com/banksean/sand/internal/sshimmer"
 	"github.com/posener/complete"
+	"golang.org/x/term"
 )
 
 type sandboxNamePredictor struct {
@@ -56,6 +57,14 @@ func NewLazySandboxNamePredictor(clientFor func() (daemon.Client, error)) comple
 	return &sandboxNamePredictor{clientFor: clientFor}
 }
 
+// markSandboxUsed bumps the sandbox's last-used time. Failures are logged
+// rather than returned so they never block a shell or exec.
+func markSandboxUsed(ctx context.Context, mc daemon.Client, sbox *sandtypes.Box) {
+	if err := mc.MarkSandboxUsed(ctx, sbox.Name); err != nil {
+		slog.WarnContext(ctx, "MarkSandboxUsed", "name", sbox.Name, "error", err)
+	}
+}
+
 func buildInteractiveEnv(hostname string, scrubSSHAgent bool, extraEnv map[string]string) map[string]string {
 	env := map[string]string{
 		"HOSTNAME": hostname,
@@ -285,18 +294,20 @@ func envScopeAllowsProject(scope sandtypes.EnvScope) bool {
 var (
 	sshCommand           = exec.CommandContext
 	checkSSHReachability = sshimmer.CheckSSHReachability
+	isTerminal           = func(f *os.File) bool { return term.IsTerminal(int(f.Fd())) }
 )
 
-// runShell executes an interactive shell or command in sbox's container over SSH,
-// connecting the current process's stdin/stdout/stderr. Non-zero shell exit is
-// logged but not returned as an error — an interactive session ending with a
-// non-zero code is not a CLI failure.
-func runShell(ctx context.Context, sbox *sandtypes.Box, shell string, args []string, scrubSSHAgent bool, envFile string, extraEnv map[string]string) error {
+// runShell executes an interactive shell or command in sbox's container over SSH
+// (container exec for a --no-ssh sandbox),
+// connecting the current process's stdin and stderr, and its stdout through
+// stdout. Non-zero shell exit is logged but not returned as an error — an
+// interactive session ending with a non-zero code is not a CLI failure.
+func runShell(ctx context.Context, sbox *sandtypes.Box, stdout io.Writer, workDir, shell string, args []string, scrubSSHAgent bool, envFile string, extraEnv map[string]string) error {
 	if sbox.Container == nil {
 		return fmt.Errorf("sandbox %s has no container", sbox.ID)
 	}
 	hostname := sandtypes.GetContainerHostname(sbox.Container)
-	if err := ensureSSHReachability(ctx, hostname); err != nil {
+	if err := ensureReachable(ctx, sbox, hostname); err != nil {
 		return err
 	}
 
@@ -304,7 +315,8 @@ func runShell(ctx context.Context, sbox *sandtypes.Box, shell string, args []str
 	if err != nil {
 		return err
 	}
-	cmd := sshStreamCommand(ctx, hostname, true, env, shell, args)
+	cmd := streamCommand(ctx, sbox, hostname, true, workDir, env, shell, args)
+	cmd.Stdout = stdout
 	slog.InfoContext(ctx, "runShell: ssh", "sandbox", sbox.ID, "hostname", hostname, "shell", shell)
 	if err := cmd.Run(); err != nil {
 		slog.WarnContext(ctx, "runShell: shell exited with error", "sandbox", sbox.ID, "error", err)
@@ -317,14 +329,17 @@ func runSSHOutput(ctx context.Context, sbox *sandtypes.Box, envFile string, extr
 		return "", fmt.Errorf("sandbox %s has no container", sbox.ID)
 	}
 	hostname := sandtypes.GetContainerHostname(sbox.Container)
-	if err := ensureSSHReachability(ctx, hostname); err != nil {
+	if err := ensureReachable(ctx, sbox, hostname); err != nil {
 		return "", err
 	}
 	env, err := sshCommandEnv(hostname, envFile, mergeEnv(sandboxProxyEnv(sbox), extraEnv))
 	if err != nil {
 		return "", err
 	}
-	cmd := sshOutputCommand(ctx, hostname, env, shell, args)
+	cmd := sshOutputCommand(ctx, sshDestination(sbox, hostname), containerAppDir, env, shell, args)
+	if sbox.NoSSH {
+		cmd = containerExecCommand(ctx, sbox, false, containerAppDir, env, shell, args)
+	}
 	slog.InfoContext(ctx, "runSSHOutput: ssh", "sandbox", sbox.ID, "hostname", hostname, "shell", shell)
 	out, err := cmd.CombinedOutput()
 	return string(out), err
@@ -335,28 +350,87 @@ func runSSHStream(ctx context.Context, sbox *sandtypes.Box, tty bool, envFile st
 		return fmt.Errorf("sandbox %s has no container", sbox.ID)
 	}
 	hostname := sandtypes.GetContainerHostname(sbox.Container)
-	if err := ensureSSHReachability(ctx, hostname); err != nil {
+	if err := ensureReachable(ctx, sbox, hostname); err != nil {
 		return err
 	}
 	env, err := interactiveSSHEnv(hostname, true, envFile, mergeEnv(sandboxProxyEnv(sbox), extraEnv))
 	if err != nil {
 		return err
 	}
-	cmd := sshStreamCommand(ctx, hostname, tty, env, shell, args)
+	cmd := streamCommand(ctx, sbox, hostname, tty, containerAppDir, env, shell, args)
 	slog.InfoContext(ctx, "runSSHStream: ssh", "sandbox", sbox.ID, "hostname", hostname, "shell", shell, "tty", tty)
 	return cmd.Run()
 }
 
-func sshOutputCommand(ctx context.Context, hostname string, env map[string]string, shell string, args []string) *exec.Cmd {
-	return sshCommand(ctx, "ssh", hostname, remoteInteractiveCommand(env, shell, args))
+// runSSHExec runs a command in sbox's container over SSH, in workDir, with the
+// current process's stdin/stdout/stderr attached. Unlike runSSHStream it passes
+// the environment through unchanged, matching runSSHOutput.
+func runSSHExec(ctx context.Context, sbox *sandtypes.Box, tty bool, workDir, envFile string, extraEnv map[string]string, shell string, args ...string) error {
+	if sbox.Container == nil {
+		return fmt.Errorf("sandbox %s has no container", sbox.ID)
+	}
+	hostname := sandtypes.GetContainerHostname(sbox.Container)
+	if err := ensureReachable(ctx, sbox, hostname); err != nil {
+		return err
+	}
+	env, err := sshCommandEnv(hostname, envFile, mergeEnv(sandboxProxyEnv(sbox), extraEnv))
+	if err != nil {
+		return err
+	}
+	cmd := streamCommand(ctx, sbox, hostname, tty, workDir, env, shell, args)
+	slog.InfoContext(ctx, "runSSHExec: ssh", "sandbox", sbox.ID, "hostname", hostname, "shell", shell, "tty", tty)
+	return cmd.Run()
+}
+
+// sshDestination is the ssh destination for sbox's container. Naming the
+// sandbox's user explicitly keeps ssh from picking whichever User line comes
+// first in sand's shared ssh_config when sandboxes were created for different
+// users.
+func sshDestination(sbox *sandtypes.Box, hostname string) string {
+	if sbox.Username == "" {
+		return hostname
+	}
+	return sbox.Username + "@" + hostname
 }
 
-func sshStreamCommand(ctx context.Context, hostname string, tty bool, env map[string]string, shell string, args []string) *exec.Cmd {
+// streamCommand returns the command that runs shell in sbox's container with
+// the current process's stdin/stdout/stderr attached: ssh, or container exec
+// for a sandbox created without sshd.
+func streamCommand(ctx context.Context, sbox *sandtypes.Box, hostname string, tty bool, workDir string, env map[string]string, shell string, args []string) *exec.Cmd {
+	if !sbox.NoSSH {
+		return sshStreamCommand(ctx, sshDestination(sbox, hostname), tty, workDir, env, shell, args)
+	}
+	cmd := containerExecCommand(ctx, sbox, tty, workDir, env, shell, args)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd
+}
+
+// containerExecCommand runs the same remote command line ssh would, through
+// `container exec` as the sandbox's user.
+func containerExecCommand(ctx context.Context, sbox *sandtypes.Box, tty bool, workDir string, env map[string]string, shell string, args []string) *exec.Cmd {
+	execArgs := []string{"exec", "--interactive"}
+	if tty {
+		execArgs = append(execArgs, "--tty")
+	}
+	if sbox.Username != "" {
+		execArgs = append(execArgs, "--user", sbox.Username)
+	}
+	execArgs = append(execArgs, sbox.ContainerID, "sh", "-c", remoteInteractiveCommand(workDir, env, shell, args))
+	return sshCommand(ctx, "container", execArgs...)
+}
+
+func sshOutputCommand(ctx context.Context, hostname, workDir string, env map[string]string, shell string, args []string) *exec.Cmd {
+	return sshCommand(ctx, "ssh", hostname, remoteInteractiveCommand(workDir, env, shell, args))
+}
+
+func sshStreamCommand(ctx context.Context, hostname string, tty bool, workDir string, env map[string]string, shell string, args []string) *exec.Cmd {
 	sshArgs := []string{}
 	if tty {
 		sshArgs = append(sshArgs, "-tt")
 	}
-	sshArgs = append(sshArgs, hostname, remoteInteractiveCommand(env, shell, args))
+	sshArgs = append(sshArgs, hostname, remoteInteractiveCommand(workDir, env, shell, args))
 	cmd := sshCommand(ctx, "ssh", sshArgs...)
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
@@ -364,6 +438,15 @@ func sshStreamCommand(ctx context.Context, hostname string, tty bool, env map[st
 	return cmd
 }
 
+// ensureReachable checks that sbox can be connected to; sandboxes without
+// sshd are reached by container exec, which needs no ssh config.
+func ensureReachable(ctx context.Context, sbox *sandtypes.Box, hostname string) error {
+	if sbox.NoSSH {
+		return nil
+	}
+	return ensureSSHReachability(ctx, hostname)
+}
+
 func ensureSSHReachability(ctx context.Context, hostname string) error {
 	updateSSHConfFunc, err := checkSSHReachability(ctx, hostname)
 	if err != nil {
@@ -374,7 +457,7 @@ func ensureSSHReachability(ctx context.Context, hostname string) error {
 	}
 	if os.Getenv("SMOKE_TEST") == "" {
 		reader := bufio.NewReader(os.Stdin)
-		fmt.Printf("\nTo enable you to use ssh to connect to local sand containers, we need to add one line to the top of your ssh config. Proceed [y/N]? ")
+		fmt.Printf("\nTo enable you to use ssh to connect to local sand containers, we need to add one line to the top of your ssh config (or move it there). Proceed [y/N]? ")
 		text, err := reader.ReadString('\n')
 		if err != nil {
 			return fmt.Errorf("couldn't read from stdin: %w", err)
@@ -417,6 +500,35 @@ func sshCommandEnv(hostname string, envFile string, extraEnv map[string]string)
 	return env, nil
 }
 
+// parseEnvFlags parses repeated --env KEY=VALUE flags. A later flag for the
+// same key wins.
+func parseEnvFlags(specs []string) (map[string]string, error) {
+	if len(specs) == 0 {
+		return nil, nil
+	}
+	env := make(map[string]string, len(specs))
+	for _, spec := range specs {
+		key, value, ok := strings.Cut(spec, "=")
+		if !ok || !validEnvKey(key) {
+			return nil, fmt.Errorf("invalid --env %q: want KEY=VALUE with KEY made of letters, digits and underscores", spec)
+		}
+		env[key] = value
+	}
+	return env, nil
+}
+
+func validEnvKey(key string) bool {
+	if key == "" || (key[0] >= '0' && key[0] <= '9') {
+		return false
+	}
+	for _, r := range key {
+		if r != '_' && (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
+
 func readEnvFile(path string) (map[string]string, error) {
 	env := map[string]string{}
 	if strings.TrimSpace(path) == "" {
@@ -453,8 +565,10 @@ func readEnvFile(path string) (map[string]string, error) {
 	return env, nil
 }
 
-func remoteInteractiveCommand(env map[string]string, shell string, args []string) string {
-	parts := []string{"cd", shellQuote("/app"), "&&", "env"}
+// remoteInteractiveCommand is the shell command line that runs shell with args
+// in workDir, with env set.
+func remoteInteractiveCommand(workDir string, env map[string]string, shell string, args []string) string {
+	parts := []string{"cd", shellQuote(workDir), "&&", "env"}
 	keys := make([]string, 0, len(env))
 	for key := range env {
 		if key != "" {
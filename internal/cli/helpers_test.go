@@ -1,12 +1,20 @@
 package cli
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
+	"github.com/alecthomas/kong"
+	"github.com/banksean/sand/internal/daemon"
+	"github.com/banksean/sand/internal/daemon/daemontest"
 	"github.com/banksean/sand/internal/sandtypes"
+	kongcompletion "github.com/jotaen/kong-completion"
+	"github.com/posener/complete"
 )
 
 func TestBuildInteractiveEnv(t *testing.T) {
@@ -39,6 +47,7 @@ func TestBuildInteractiveEnv(t *testing.T) {
 
 func TestRemoteInteractiveCommandQuotesEnvAndArgs(t *testing.T) {
 	got := remoteInteractiveCommand(
+		"/app",
 		map[string]string{
 			"TERM":  "xterm-256color",
 			"QUOTE": "can't",
@@ -52,6 +61,24 @@ func TestRemoteInteractiveCommandQuotesEnvAndArgs(t *testing.T) {
 	}
 }
 
+func TestContainerExecCommandRunsAsSandboxUser(t *testing.T) {
+	sbox := &sandtypes.Box{ContainerID: "ctr-1", Username: "sean"}
+	cmd := containerExecCommand(context.Background(), sbox, true, "/app", nil, "/bin/zsh", nil)
+	want := []string{"container", "exec", "--interactive", "--tty", "--user", "sean", "ctr-1", "sh", "-c", "cd '/app' && env '/bin/zsh'"}
+	if !slices.Equal(cmd.Args, want) {
+		t.Fatalf("containerExecCommand() args = %q, want %q", cmd.Args, want)
+	}
+}
+
+func TestContainerExecCommandRunsInWorkDir(t *testing.T) {
+	sbox := &sandtypes.Box{ContainerID: "ctr-1", Username: "sean", NoSSH: true}
+	cmd := streamCommand(context.Background(), sbox, "ctr-1.local", false, "/srv/api", nil, "make", []string{"test"})
+	want := []string{"container", "exec", "--interactive", "--user", "sean", "ctr-1", "sh", "-c", "cd '/srv/api' && env 'make' 'test'"}
+	if !slices.Equal(cmd.Args, want) {
+		t.Fatalf("streamCommand() args = %q, want %q", cmd.Args, want)
+	}
+}
+
 func TestInteractiveSSHEnvMergesEnvFileThenExplicitEnv(t *testing.T) {
 	dir := t.TempDir()
 	envFile := filepath.Join(dir, ".env")
@@ -317,3 +344,57 @@ func TestSelectedProfileEnvPolicyRejectsMissingNamedProfile(t *testing.T) {
 		t.Fatal("expected missing profile error")
 	}
 }
+
+// completionTestCLI holds a few of the commands whose positional arguments
+// complete sandbox names.
+type completionTestCLI struct {
+	Shell  ShellCmd  `cmd:""`
+	Stop   StopCmd   `cmd:""`
+	Rename RenameCmd `cmd:""`
+	Clone  CloneCmd  `cmd:""`
+}
+
+func completionTestPredictor(t *testing.T, names ...string) complete.Predictor {
+	t.Helper()
+	client := daemontest.StartDaemon(t, daemontest.Deps{}, func(ctx context.Context, s daemontest.SandboxStore) {
+		for _, name := range names {
+			box := newTestBox(name + "-id")
+			box.Name = name
+			s.SaveSandbox(ctx, box)
+		}
+	})
+	return NewSandboxNamePredictor(client)
+}
+
+func TestSandboxNamePredictorListsSandboxes(t *testing.T) {
+	predictor := completionTestPredictor(t, "alpha", "beta")
+	got := predictor.Predict(complete.Args{})
+	slices.Sort(got)
+	if want := []string{"alpha", "beta"}; !slices.Equal(got, want) {
+		t.Fatalf("Predict() = %v, want %v", got, want)
+	}
+}
+
+func TestLazySandboxNamePredictorWithoutDaemon(t *testing.T) {
+	predictor := NewLazySandboxNamePredictor(func() (daemon.Client, error) {
+		return nil, errors.New("no daemon")
+	})
+	if got := predictor.Predict(complete.Args{}); len(got) != 0 {
+		t.Fatalf("Predict() = %v, want nothing when the daemon is unreachable", got)
+	}
+}
+
+func TestSandboxNameArgumentsComplete(t *testing.T) {
+	parser := kong.Must(&completionTestCLI{})
+	cmd, err := kongcompletion.Command(parser, kongcompletion.WithPredictor("sandbox-name", completionTestPredictor(t, "alpha", "beta")))
+	if err != nil {
+		t.Fatalf("kongcompletion.Command() error = %v", err)
+	}
+	for _, sub := range []string{"shell", "stop", "rename", "clone"} {
+		got := cmd.Predict(complete.Args{All: []string{sub, ""}, Completed: []string{sub}, LastCompleted: sub})
+		slices.Sort(got)
+		if want := []string{"alpha", "beta"}; !slices.Equal(got, want) {
+			t.Errorf("completing %q = %v, want %v", "sand "+sub+" ", got, want)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/banksean/sand/internal/daemon"
+)
+
+type ImageCmd struct {
+	Prune ImagePruneCmd `cmd:"" help:"remove local container images sand fetched that no sandbox uses"`
+}
+
+type ImagePruneCmd struct {
+	DryRun bool `help:"print the images that would be removed without removing them"`
+	Force  bool `short:"f" help:"also remove the default sandbox image if no sandbox uses it"`
+}
+
+func (c *ImagePruneCmd) Run(cctx *CLIContext) error {
+	opts := daemon.PruneImagesOpts{DryRun: c.DryRun}
+	if !c.Force {
+		opts.Keep = []string{DefaultImageName}
+	}
+	images, err := cctx.Daemon.PruneImages(cctx.Context, opts)
+	for _, name := range images {
+		if c.DryRun {
+			fmt.Printf("would remove %s\n", name)
+		} else {
+			fmt.Printf("removed %s\n", name)
+		}
+	}
+	return err
+}
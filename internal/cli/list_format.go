@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"path/filepath"
@@ -22,6 +23,13 @@ type lsRow struct {
 	Stats      *sandtypes.ContainerStats
 }
 
+// writeJSON writes v to w as indented JSON, for --json output.
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
 const deletedSandboxesHeader = "--- deleted sandboxes (to remove: sand expunge [-f]) ---"
 
 func renderLsTable(w io.Writer, currentRows, otherRows, deletedRows []lsRow, long bool) error {
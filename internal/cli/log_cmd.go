@@ -1,20 +1,130 @@
 package cli
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
 	"log/slog"
+	"math"
 	"os"
+	"strings"
+	"time"
 )
 
+var (
+	logCmdStdout io.Writer = os.Stdout
+	logCmdNow              = time.Now
+)
+
+// containerLogLines is how much of each in-container log file --container-logs
+// prints.
+const containerLogLines = 100
+
 type SandboxLogCmd struct {
 	SandboxNameFlag
+	Since            time.Duration `placeholder:"<duration>" help:"only show events, and in-container log files written to, within this long ago, e.g. 10m"`
+	ContainerLogs    bool          `name:"container-logs" help:"also print the ends of log files inside the sandbox, where agents record errors that never reach the container's output"`
+	ContainerLogPath []string      `name:"container-log-path" default:"~/.local/share/opencode/log" placeholder:"<path>" help:"file or directory inside the sandbox for --container-logs to read (can be specified multiple times)"`
 	// TODO: add -f for following a la slogtail.
 }
 
 func (c *SandboxLogCmd) Run(cctx *CLIContext) error {
 	ctx := cctx.Context
-	if err := cctx.Daemon.LogSandbox(ctx, c.SandboxName, os.Stdout); err != nil {
+	var hostLog bytes.Buffer
+	if err := cctx.Daemon.LogSandbox(ctx, c.SandboxName, &hostLog); err != nil {
 		slog.ErrorContext(ctx, "LogSandbox", "error", err, "sandbox_id", c.SandboxName)
 		return err
 	}
-	return nil
+	hostEvents := hostLog.Bytes()
+	if c.Since > 0 {
+		hostEvents = filterLogSince(hostEvents, logCmdNow().Add(-c.Since))
+	}
+	if !c.ContainerLogs {
+		_, err := logCmdStdout.Write(hostEvents)
+		return err
+	}
+
+	sbox, err := cctx.Daemon.GetSandbox(ctx, c.SandboxName)
+	if err != nil {
+		return err
+	}
+	if sbox == nil {
+		return fmt.Errorf("sandbox not found: %s", c.SandboxName)
+	}
+	if sbox.Container == nil || sbox.Container.Status.State != "running" {
+		if _, err := logCmdStdout.Write(hostEvents); err != nil {
+			return err
+		}
+		return fmt.Errorf("sandbox %s is not running, so its in-container logs can't be read; start it with sand start %s", sbox.Name, sbox.Name)
+	}
+	containerLogs, err := runSSHOutput(ctx, sbox, "", nil, "sh", "-c", containerLogTailScript(c.ContainerLogPath, c.Since, containerLogLines))
+	if err != nil {
+		return fmt.Errorf("reading in-container logs: %w: %s", err, strings.TrimSpace(containerLogs))
+	}
+	return writeSandboxLogs(logCmdStdout, hostEvents, c.ContainerLogPath, containerLogs)
+}
+
+// filterLogSince drops the daemon's JSON log entries from before cutoff. Lines
+// without a readable time are kept, so nothing is hidden for being malformed.
+func filterLogSince(data []byte, cutoff time.Time) []byte {
+	var out bytes.Buffer
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		var entry struct {
+			Time time.Time `json:"time"`
+		}
+		if json.Unmarshal(line, &entry) == nil && !entry.Time.IsZero() && entry.Time.Before(cutoff) {
+			continue
+		}
+		out.Write(line)
+		out.WriteByte('\n')
+	}
+	return out.Bytes()
+}
+
+// containerLogTailScript is the sh script that prints the last lines of each
+// regular file under paths, skipping paths that don't exist. With since, only
+// files modified within it are printed. A leading ~/ is the sandbox user's
+// home.
+func containerLogTailScript(paths []string, since time.Duration, lines int) string {
+	quoted := make([]string, len(paths))
+	for i, p := range paths {
+		if rest, ok := strings.CutPrefix(p, "~/"); ok {
+			quoted[i] = `"$HOME"/` + shellQuote(rest)
+		} else {
+			quoted[i] = shellQuote(p)
+		}
+	}
+	find := `find "$p" -type f`
+	if since > 0 {
+		find += fmt.Sprintf(" -mmin -%d", int(math.Ceil(since.Minutes())))
+	}
+	return fmt.Sprintf(`for p in %s; do [ -e "$p" ] && %s -exec tail -n %d {} +; done; true`, strings.Join(quoted, " "), find, lines)
+}
+
+// writeSandboxLogs writes the daemon's events for a sandbox followed by what
+// was read from its in-container logs, each under a heading.
+func writeSandboxLogs(w io.Writer, hostEvents []byte, containerLogPaths []string, containerLogs string) error {
+	if _, err := fmt.Fprintln(w, "--- sand events ---"); err != nil {
+		return err
+	}
+	if _, err := w.Write(hostEvents); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "--- in-container logs (%s) ---\n", strings.Join(containerLogPaths, ", ")); err != nil {
+		return err
+	}
+	if strings.TrimSpace(containerLogs) == "" {
+		_, err := fmt.Fprintln(w, "(no log files found)")
+		return err
+	}
+	_, err := io.WriteString(w, containerLogs)
+	if err == nil && !strings.HasSuffix(containerLogs, "\n") {
+		_, err = fmt.Fprintln(w)
+	}
+	return err
 }
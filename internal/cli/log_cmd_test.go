@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/banksean/sand/internal/daemon"
+	"github.com/banksean/sand/internal/sandtypes"
+)
+
+// logTestClient serves a fixed sandbox and daemon log, which a test daemon
+// doesn't keep.
+type logTestClient struct {
+	daemon.Client
+	log  string
+	sbox *sandtypes.Box
+}
+
+func (c *logTestClient) LogSandbox(ctx context.Context, name string, w io.Writer) error {
+	_, err := io.WriteString(w, c.log)
+	return err
+}
+
+func (c *logTestClient) GetSandbox(ctx context.Context, name string) (*sandtypes.Box, error) {
+	return c.sbox, nil
+}
+
+var logCmdTestNow = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+const logCmdTestEvents = `{"time":"2026-03-01T10:00:00Z","level":"INFO","msg":"old event"}
+{"time":"2026-03-01T11:55:00Z","level":"ERROR","msg":"recent event"}
+not json
+`
+
+func runSandboxLogCmd(t *testing.T, cmd *SandboxLogCmd, client *logTestClient) string {
+	t.Helper()
+	var stdout bytes.Buffer
+	oldStdout, oldNow := logCmdStdout, logCmdNow
+	logCmdStdout = &stdout
+	logCmdNow = func() time.Time { return logCmdTestNow }
+	t.Cleanup(func() { logCmdStdout, logCmdNow = oldStdout, oldNow })
+
+	if err := cmd.Run(&CLIContext{Context: context.Background(), Daemon: client}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	return stdout.String()
+}
+
+func TestFilterLogSince(t *testing.T) {
+	got := string(filterLogSince([]byte(logCmdTestEvents), logCmdTestNow.Add(-10*time.Minute)))
+	want := `{"time":"2026-03-01T11:55:00Z","level":"ERROR","msg":"recent event"}
+not json
+`
+	if got != want {
+		t.Fatalf("filterLogSince() = %q, want %q", got, want)
+	}
+}
+
+func TestContainerLogTailScript(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		paths []string
+		since time.Duration
+		want  string
+	}{
+		{
+			name:  "home relative",
+			paths: []string{"~/.local/share/opencode/log"},
+			want:  `for p in "$HOME"/'.local/share/opencode/log'; do [ -e "$p" ] && find "$p" -type f -exec tail -n 50 {} +; done; true`,
+		},
+		{
+			name:  "since rounds up to whole minutes",
+			paths: []string{"/var/log/agent.log", "/tmp/it's here"},
+			since: 90 * time.Second,
+			want:  `for p in '/var/log/agent.log' '/tmp/it'"'"'s here'; do [ -e "$p" ] && find "$p" -type f -mmin -2 -exec tail -n 50 {} +; done; true`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := containerLogTailScript(tc.paths, tc.since, 50); got != tc.want {
+				t.Fatalf("containerLogTailScript() =\n%s\nwant\n%s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSandboxLogCmdMergesContainerLogs(t *testing.T) {
+	sbox := newTestBox("target")
+	sbox.Name = "target"
+	sbox.Container = &sandtypes.Container{
+		Configuration: sandtypes.ContainerConfig{ID: "target.local"},
+		Status:        sandtypes.ContainerStatus{State: "running"},
+	}
+	var calls [][]string
+	restore := stubSSH(t, &calls, []string{"ERROR provider auth failed\n"}, []int{0})
+	defer restore()
+
+	out := runSandboxLogCmd(t, &SandboxLogCmd{
+		SandboxNameFlag:  SandboxNameFlag{SandboxName: "target"},
+		Since:            10 * time.Minute,
+		ContainerLogs:    true,
+		ContainerLogPath: []string{"~/.local/share/opencode/log"},
+	}, &logTestClient{log: logCmdTestEvents, sbox: sbox})
+
+	want := `--- sand events ---
+{"time":"2026-03-01T11:55:00Z","level":"ERROR","msg":"recent event"}
+not json
+--- in-container logs (~/.local/share/opencode/log) ---
+ERROR provider auth failed
+`
+	if out != want {
+		t.Fatalf("output =\n%s\nwant\n%s", out, want)
+	}
+	if len(calls) != 1 {
+		t.Fatalf("ssh calls = %v, want one", calls)
+	}
+	script := containerLogTailScript([]string{"~/.local/share/opencode/log"}, 10*time.Minute, containerLogLines)
+	if remote := calls[0][len(calls[0])-1]; !strings.Contains(remote, shellQuote(script)) {
+		t.Fatalf("remote command = %q, want it to run %q", remote, script)
+	}
+}
+
+func TestSandboxLogCmdHostEventsOnly(t *testing.T) {
+	var calls [][]string
+	restore := stubSSH(t, &calls, nil, nil)
+	defer restore()
+
+	out := runSandboxLogCmd(t, &SandboxLogCmd{SandboxNameFlag: SandboxNameFlag{SandboxName: "target"}},
+		&logTestClient{log: logCmdTestEvents})
+	if out != logCmdTestEvents {
+		t.Fatalf("output = %q, want the daemon log unchanged", out)
+	}
+	if len(calls) != 0 {
+		t.Fatalf("ssh calls = %v, want none without --container-logs", calls)
+	}
+}
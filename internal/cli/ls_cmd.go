@@ -3,9 +3,11 @@ package cli
 import (
 	"context"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/banksean/sand/internal/daemon"
@@ -13,20 +15,38 @@ import (
 	"github.com/banksean/sand/internal/sandtypes"
 )
 
+var lsCmdStdout io.Writer = os.Stdout
+
 type LsCmd struct {
-	Long bool `short:"l" help:"show resource usage columns"`
-	All  bool `short:"a" help:"include soft-deleted sandboxes"`
+	Long bool   `short:"l" help:"show resource usage columns"`
+	All  bool   `short:"a" help:"include stopped, broken and soft-deleted sandboxes"`
+	Sort string `default:"created" enum:"created,age,recent" help:"sort order: created (newest first), age (oldest first), or recent (most recently used first)"`
+	JSON bool   `name:"json" help:"print sandboxes as a JSON array instead of a table, stopped and broken ones included"`
+	// Filter uses a kind=... prefix so other filter kinds can be added later.
+	Filter []string `sep:"none" placeholder:"label=<key>[=<value>]" help:"only show sandboxes with a matching label (can be specified multiple times; all must match)"`
 }
 
 func (c *LsCmd) Run(cctx *CLIContext) error {
 	ctx := cctx.Context
 	mc := cctx.Daemon
 
+	selectors, err := parseLsFilters(c.Filter)
+	if err != nil {
+		return err
+	}
+
 	list, err := mc.ListSandboxes(ctx)
 	if err != nil {
 		slog.ErrorContext(ctx, "ListSandboxes", "error", err)
 		return err
 	}
+	list = filterSandboxesByLabels(list, selectors)
+	// Only the table hides stopped sandboxes; scripts reading --json get them
+	// all.
+	var hidden int
+	if !c.All && !c.JSON {
+		list, hidden = onlyRunningSandboxes(list)
+	}
 
 	var deleted []sandtypes.Box
 	if c.All {
@@ -35,10 +55,17 @@ func (c *LsCmd) Run(cctx *CLIContext) error {
 			slog.ErrorContext(ctx, "ListDeletedSandboxes", "error", err)
 			return err
 		}
+		deleted = filterSandboxesByLabels(deleted, selectors)
+	}
+
+	sortSandboxes(list, c.Sort)
+	if c.JSON {
+		all := make([]sandtypes.Box, 0, len(list)+len(deleted))
+		return writeJSON(lsCmdStdout, append(append(all, list...), deleted...))
 	}
 
 	if len(list) == 0 && len(deleted) == 0 {
-		return nil
+		return printHiddenSandboxes(hidden)
 	}
 
 	currentWorkspace := currentWorkspaceDir(ctx)
@@ -61,7 +88,80 @@ func (c *LsCmd) Run(cctx *CLIContext) error {
 	for _, sbox := range deleted {
 		deletedRows = append(deletedRows, rowFromSandbox(sbox, userHomeDir, nil))
 	}
-	return renderLsTable(os.Stdout, currentRows, otherRows, deletedRows, c.Long)
+	if err := renderLsTable(lsCmdStdout, currentRows, otherRows, deletedRows, c.Long); err != nil {
+		return err
+	}
+	return printHiddenSandboxes(hidden)
+}
+
+// onlyRunningSandboxes keeps the sandboxes whose container is running and
+// returns how many were dropped. The daemon reports container state with
+// every listed sandbox, so this needs no extra round trips.
+func onlyRunningSandboxes(list []sandtypes.Box) ([]sandtypes.Box, int) {
+	n := len(list)
+	list = slices.DeleteFunc(list, func(sbox sandtypes.Box) bool {
+		return !isRunningContainer(sbox.Container)
+	})
+	return list, n - len(list)
+}
+
+func printHiddenSandboxes(hidden int) error {
+	if hidden == 0 {
+		return nil
+	}
+	noun := "sandboxes"
+	if hidden == 1 {
+		noun = "sandbox"
+	}
+	_, err := fmt.Fprintf(lsCmdStdout, "%d stopped %s not shown; use --all to include them\n", hidden, noun)
+	return err
+}
+
+func parseLsFilters(filters []string) ([]sandtypes.LabelSelector, error) {
+	var selectors []sandtypes.LabelSelector
+	for _, filter := range filters {
+		kind, expr, _ := strings.Cut(filter, "=")
+		if kind != "label" {
+			return nil, fmt.Errorf("unsupported filter %q: want label=<key>[=<value>]", filter)
+		}
+		selector, err := sandtypes.ParseLabelSelector(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid filter %q: %w", filter, err)
+		}
+		selectors = append(selectors, selector)
+	}
+	return selectors, nil
+}
+
+// filterSandboxesByLabels keeps the sandboxes matching every selector.
+func filterSandboxesByLabels(list []sandtypes.Box, selectors []sandtypes.LabelSelector) []sandtypes.Box {
+	if len(selectors) == 0 {
+		return list
+	}
+	return slices.DeleteFunc(list, func(sbox sandtypes.Box) bool {
+		for _, selector := range selectors {
+			if !selector.Matches(sbox.Labels) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// sortSandboxes orders list in place for the given --sort value. The daemon
+// already returns sandboxes newest first, so "created" leaves list alone.
+func sortSandboxes(list []sandtypes.Box, order string) {
+	switch order {
+	case "age":
+		slices.SortStableFunc(list, func(a, b sandtypes.Box) int {
+			return a.CreatedAt.Compare(b.CreatedAt)
+		})
+	case "recent":
+		// Never-used sandboxes have a zero LastUsedAt and sort last.
+		slices.SortStableFunc(list, func(a, b sandtypes.Box) int {
+			return b.LastUsedAt.Compare(a.LastUsedAt)
+		})
+	}
 }
 
 func rowFromSandbox(sbox sandtypes.Box, userHomeDir string, stats *sandtypes.ContainerStats) lsRow {
@@ -78,6 +178,9 @@ func rowFromSandbox(sbox sandtypes.Box, userHomeDir string, stats *sandtypes.Con
 	if sbox.SandboxWorkDirError != "" {
 		status = append(status, sbox.SandboxWorkDirError)
 	}
+	if sbox.SandboxResourceWarning != "" {
+		status = append(status, sbox.SandboxResourceWarning)
+	}
 	imgName := strings.TrimPrefix(sbox.ImageName, "ghcr.io/banksean/sand/")
 
 	return lsRow{
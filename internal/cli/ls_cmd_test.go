@@ -3,13 +3,18 @@ package cli
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"reflect"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/banksean/sand/internal/daemon/daemontest"
+	"github.com/banksean/sand/internal/hostops"
 	"github.com/banksean/sand/internal/sandtypes"
 )
 
@@ -43,6 +48,85 @@ func TestShortSandboxIDLeavesNonUUIDAlone(t *testing.T) {
 	}
 }
 
+func TestSortSandboxes(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	newList := func() []sandtypes.Box {
+		// Daemon order: newest first.
+		return []sandtypes.Box{
+			{Name: "newest", CreatedAt: base.Add(2 * time.Hour)},
+			{Name: "middle", CreatedAt: base.Add(time.Hour), LastUsedAt: base.Add(5 * time.Hour)},
+			{Name: "oldest", CreatedAt: base, LastUsedAt: base.Add(3 * time.Hour)},
+		}
+	}
+	names := func(list []sandtypes.Box) []string {
+		var ret []string
+		for _, sbox := range list {
+			ret = append(ret, sbox.Name)
+		}
+		return ret
+	}
+
+	for _, tc := range []struct {
+		order string
+		want  []string
+	}{
+		{order: "created", want: []string{"newest", "middle", "oldest"}},
+		{order: "age", want: []string{"oldest", "middle", "newest"}},
+		{order: "recent", want: []string{"middle", "oldest", "newest"}},
+	} {
+		list := newList()
+		sortSandboxes(list, tc.order)
+		if got := names(list); !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("sortSandboxes(%q) = %v, want %v", tc.order, got, tc.want)
+		}
+	}
+}
+
+func TestFilterSandboxesByLabels(t *testing.T) {
+	newList := func() []sandtypes.Box {
+		return []sandtypes.Box{
+			{Name: "api", Labels: map[string]string{"project": "sand", "team": "infra"}},
+			{Name: "web", Labels: map[string]string{"project": "sand"}},
+			{Name: "other", Labels: map[string]string{"project": "other"}},
+			{Name: "unlabeled"},
+		}
+	}
+	names := func(list []sandtypes.Box) []string {
+		var ret []string
+		for _, sbox := range list {
+			ret = append(ret, sbox.Name)
+		}
+		return ret
+	}
+
+	for _, tc := range []struct {
+		filters []string
+		want    []string
+	}{
+		{filters: nil, want: []string{"api", "web", "other", "unlabeled"}},
+		{filters: []string{"label=project"}, want: []string{"api", "web", "other"}},
+		{filters: []string{"label=project=sand"}, want: []string{"api", "web"}},
+		{filters: []string{"label=project=sand", "label=team"}, want: []string{"api"}},
+		{filters: []string{"label=missing"}, want: nil},
+	} {
+		selectors, err := parseLsFilters(tc.filters)
+		if err != nil {
+			t.Fatalf("parseLsFilters(%v) error = %v", tc.filters, err)
+		}
+		if got := names(filterSandboxesByLabels(newList(), selectors)); !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("filter %v = %v, want %v", tc.filters, got, tc.want)
+		}
+	}
+}
+
+func TestParseLsFiltersRejectsUnsupportedFilters(t *testing.T) {
+	for _, filter := range []string{"name=api", "label", "label=", "label=sand.id"} {
+		if _, err := parseLsFilters([]string{filter}); err == nil {
+			t.Errorf("parseLsFilters(%q) error = nil, want error", filter)
+		}
+	}
+}
+
 func TestSamePathCanonicalizesSymlinks(t *testing.T) {
 	dir := t.TempDir()
 	target := filepath.Join(dir, "repo")
@@ -195,3 +279,141 @@ func TestRowFromSandboxUsesDeletedStatus(t *testing.T) {
 		t.Fatalf("row ImageName = %q, want base:latest", row.ImageName)
 	}
 }
+
+func TestRowFromSandboxFlagsMemoryPressure(t *testing.T) {
+	row := rowFromSandbox(sandtypes.Box{
+		ID:                     "busy-id",
+		Name:                   "busy",
+		Container:              &sandtypes.Container{Status: sandtypes.ContainerStatus{State: "running"}},
+		SandboxResourceWarning: "MEMORY 93% OF LIMIT",
+	}, "/home/user", nil)
+	if row.Status != "running, MEMORY 93% OF LIMIT" {
+		t.Fatalf("row status = %q, want the memory warning after the state", row.Status)
+	}
+}
+
+func TestWriteJSONOmitsRuntimeOnlyBoxFields(t *testing.T) {
+	var buf bytes.Buffer
+	err := writeJSON(&buf, sandtypes.Box{
+		ID:                    "box-id",
+		SandboxContainerError: "container missing",
+		SharedCacheMounts:     sandtypes.SharedCacheMounts{MiseCacheHostDir: "/host/mise"},
+	})
+	if err != nil {
+		t.Fatalf("writeJSON() error = %v", err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal %q: %v", buf.String(), err)
+	}
+	if got["SandboxContainerError"] != "container missing" {
+		t.Fatalf("SandboxContainerError = %v, want %q", got["SandboxContainerError"], "container missing")
+	}
+	if _, ok := got["SharedCacheMounts"]; ok {
+		t.Fatalf("JSON includes runtime-only SharedCacheMounts: %s", buf.String())
+	}
+	if strings.Contains(buf.String(), "/host/mise") {
+		t.Fatalf("JSON leaks shared cache host dir: %s", buf.String())
+	}
+}
+
+func TestLsCmdJSON(t *testing.T) {
+	cctx := newTestCLIContext(t, func(ctx context.Context, s daemontest.SandboxStore) {
+		s.SaveSandbox(ctx, newTestBox("one"))
+		s.SaveSandbox(ctx, newTestBox("two"))
+	})
+	var stdout bytes.Buffer
+	prev := lsCmdStdout
+	lsCmdStdout = &stdout
+	t.Cleanup(func() { lsCmdStdout = prev })
+
+	if err := (&LsCmd{Sort: "created", JSON: true}).Run(cctx); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	var got []map[string]any
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal %q: %v", stdout.String(), err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d sandboxes, want 2: %s", len(got), stdout.String())
+	}
+	for _, key := range []string{"ID", "Name", "ImageName", "CreatedAt", "SandboxWorkDirError", "SandboxContainerError"} {
+		if _, ok := got[0][key]; !ok {
+			t.Fatalf("JSON sandbox missing %q: %v", key, got[0])
+		}
+	}
+}
+
+func TestLsCmdJSONEmptyList(t *testing.T) {
+	cctx := newTestCLIContext(t, nil)
+	var stdout bytes.Buffer
+	prev := lsCmdStdout
+	lsCmdStdout = &stdout
+	t.Cleanup(func() { lsCmdStdout = prev })
+
+	if err := (&LsCmd{Sort: "created", JSON: true}).Run(cctx); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if got := strings.TrimSpace(stdout.String()); got != "[]" {
+		t.Fatalf("stdout = %q, want []", got)
+	}
+}
+
+func TestLsCmdHidesStoppedSandboxesUnlessAll(t *testing.T) {
+	client := daemontest.StartDaemon(t, daemontest.Deps{
+		ContainerService: &hostops.MockContainerOps{
+			InspectFunc: func(_ context.Context, containerID string) ([]sandtypes.Container, error) {
+				switch containerID {
+				case "ctr-up":
+					return []sandtypes.Container{{Status: sandtypes.ContainerStatus{State: "running"}}}, nil
+				case "ctr-down":
+					return []sandtypes.Container{{Status: sandtypes.ContainerStatus{State: "stopped"}}}, nil
+				}
+				return nil, nil
+			},
+		},
+	}, func(ctx context.Context, s daemontest.SandboxStore) {
+		s.SaveSandbox(ctx, newTestBox("up"))
+		s.SaveSandbox(ctx, newTestBox("down"))
+		s.SaveSandbox(ctx, newTestBox("gone"))
+	})
+	cctx := &CLIContext{Context: context.Background(), Daemon: client}
+	prev := lsCmdStdout
+	t.Cleanup(func() { lsCmdStdout = prev })
+
+	for _, tc := range []struct {
+		all  bool
+		want []string
+	}{
+		// --json lists every sandbox either way, so scripts see stopped ones.
+		{all: false, want: []string{"down", "gone", "up"}},
+		{all: true, want: []string{"down", "gone", "up"}},
+	} {
+		var stdout bytes.Buffer
+		lsCmdStdout = &stdout
+		if err := (&LsCmd{Sort: "created", JSON: true, All: tc.all}).Run(cctx); err != nil {
+			t.Fatalf("Run(All=%v) error = %v", tc.all, err)
+		}
+		var got []sandtypes.Box
+		if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+			t.Fatalf("unmarshal %q: %v", stdout.String(), err)
+		}
+		var names []string
+		for _, sbox := range got {
+			names = append(names, sbox.ID)
+		}
+		slices.Sort(names)
+		if !reflect.DeepEqual(names, tc.want) {
+			t.Errorf("ls All=%v = %v, want %v", tc.all, names, tc.want)
+		}
+	}
+
+	var stdout bytes.Buffer
+	lsCmdStdout = &stdout
+	if err := (&LsCmd{Sort: "created"}).Run(cctx); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if out := stdout.String(); !strings.Contains(out, "2 stopped sandboxes not shown; use --all") || strings.Contains(out, "down") {
+		t.Errorf("table output = %q, want only running sandboxes and a hint about the hidden ones", out)
+	}
+}
@@ -8,8 +8,8 @@ import (
 	"os"
 	"os/user"
 	"path/filepath"
+	"slices"
 	"strings"
-	"time"
 
 	"github.com/alecthomas/kong"
 	"github.com/banksean/sand/internal/cli/agentlaunch"
@@ -17,18 +17,75 @@ import (
 	"github.com/banksean/sand/internal/hostops"
 	"github.com/banksean/sand/internal/runtimedeps"
 	"github.com/banksean/sand/internal/sandtypes"
-	"github.com/goombaio/namegenerator"
 )
 
 type NewCmd struct {
 	SandboxCreationFlags
+	PullAlwaysFlag
 	ProjectEnvFlag
 	ShellFlags
-	Agent       string `short:"a" placeholder:"<claude|codex|gemini|opencode>" help:"name of coding agent to use"`
-	Branch      bool   `short:"b" default:"false" help:"create a new git branch, with the same name as the sandbox, inside the sandbox _container_ (not on your host workdir)"`
-	Username    string `help:"name of default user to create (defaults to $USER)"`
-	Uid         string `help:"id of default user to create (defaults to $UID)"`
-	SandboxName string `arg:"" optional:"" help:"name of the sandbox to create"`
+	Agent                string   `short:"a" placeholder:"<claude|codex|gemini|opencode>" help:"name of coding agent to use"`
+	Branch               bool     `short:"b" default:"false" help:"create a new git branch, with the same name as the sandbox, inside the sandbox _container_ (not on your host workdir)"`
+	Username             string   `aliases:"user" help:"name of default user to create; sand shell and sand exec log in as this user (defaults to $USER)"`
+	Uid                  string   `help:"id of default user to create (defaults to $UID)"`
+	Dockerfile           string   `name:"dockerfile" placeholder:"<dir>" help:"build the container image from the Dockerfile in this directory instead of pulling it (tagged with --image, or sand-local/<sandbox-name>:latest)"`
+	CloneRoot            string   `placeholder:"<dir>" help:"directory to create this sandbox's clone in, e.g. on a faster or larger volume (default: the clones directory under the app base dir)"`
+	FromBranch           string   `placeholder:"<branch>" help:"start the sandbox's clone from a clean checkout of this local branch instead of your host workdir's current checkout"`
+	NoDotfiles           bool     `help:"don't copy your dotfiles or git config into the sandbox (agents also start without sand's generated config)"`
+	NoSSH                bool     `help:"don't set up ssh keys or start sshd in the sandbox; sand shell and sand exec use container exec instead, and port forwards and sand vsc are unavailable"`
+	Publish              []string `placeholder:"<[host-ip:]host-port:container-port[/proto]>" help:"publish a container port on the host (can be specified multiple times); the mapping is kept when the container is recreated"`
+	DNS                  []string `name:"dns" placeholder:"<ip>" help:"nameserver for the container to use (can be specified multiple times)"`
+	DNSSearch            []string `name:"dns-search" placeholder:"<domain>" help:"search domain for the container's resolver (can be specified multiple times)"`
+	DNSOption            []string `name:"dns-option" placeholder:"<option>" help:"resolv.conf option for the container's resolver, e.g. ndots:2 (can be specified multiple times)"`
+	NoDNS                bool     `name:"no-dns" help:"don't configure the container's resolver, leaving the image's /etc/resolv.conf alone"`
+	AllowEmulation       bool     `help:"run an image with no variant for your Mac's architecture under emulation (Rosetta for amd64 images) instead of refusing it"`
+	IgnoreOnCreateErrors bool     `name:"ignore-oncreate-errors" help:"warn instead of failing when an oncreate.sh script exits non-zero"`
+	SandboxName          string   `arg:"" optional:"" help:"name of the sandbox to create"`
+}
+
+// newSandboxName generates a friendly name that no existing sandbox uses.
+func newSandboxName(ctx context.Context, mc daemon.Client) (string, error) {
+	boxes, err := mc.ListSandboxes(ctx)
+	if err != nil {
+		return "", fmt.Errorf("listing sandboxes to pick a name: %w", err)
+	}
+	return sandtypes.NewSandboxName(func(name string) (bool, error) {
+		return slices.ContainsFunc(boxes, func(b sandtypes.Box) bool { return b.Name == name }), nil
+	})
+}
+
+// ensureImageOpts fills in c.ImageName and returns how to get that image:
+// pulled by default, or built from --dockerfile.
+func (c *NewCmd) ensureImageOpts() (daemon.EnsureImageOpts, error) {
+	if c.Dockerfile == "" {
+		if c.ImageName == "" {
+			c.ImageName = DefaultImageName
+		}
+		return daemon.EnsureImageOpts{ImageName: c.ImageName, PullAlways: c.PullAlways}, nil
+	}
+	// sandd resolves paths relative to its own working directory.
+	dir, err := filepath.Abs(c.Dockerfile)
+	if err != nil {
+		return daemon.EnsureImageOpts{}, err
+	}
+	if c.ImageName == "" {
+		c.ImageName = "sand-local/" + c.SandboxName + ":latest"
+	}
+	return daemon.EnsureImageOpts{
+		ImageName:     c.ImageName,
+		DockerfileDir: dir,
+		BuildArgs:     map[string]string{"USERNAME": c.Username},
+	}, nil
+}
+
+// dnsConfig is the container resolver configuration from c's DNS flags.
+func (c *NewCmd) dnsConfig() sandtypes.DNSConfig {
+	return sandtypes.DNSConfig{
+		Nameservers:   c.DNS,
+		SearchDomains: c.DNSSearch,
+		Options:       c.DNSOption,
+		Disabled:      c.NoDNS,
+	}
 }
 
 func (c *NewCmd) Run(k *kong.Kong, cctx *CLIContext) error {
@@ -59,18 +116,25 @@ func (c *NewCmd) Run(k *kong.Kong, cctx *CLIContext) error {
 		})
 	}
 
-	if err := runtimedeps.Verify(ctx, cctx.AppBaseDir, runtimedeps.GitDir); err != nil {
+	if err := hostops.ValidatePublish(c.Publish); err != nil {
+		return err
+	}
+	if err := c.dnsConfig().Validate(); err != nil {
 		return err
 	}
-
 	cwd, err := os.Getwd()
 	if err != nil {
 		slog.ErrorContext(ctx, "os.Getwd", "error", err)
 		return err
 	}
-
-	if c.CloneFromDir == "" {
-		c.CloneFromDir = cwd
+	if err := c.resolveCloneFromDir(cwd); err != nil {
+		return err
+	}
+	if err := runtimedeps.VerifyWithOptions(ctx, cctx.AppBaseDir, runtimedeps.VerifyOptions{Dir: c.CloneFromDir}, runtimedeps.GitDir); err != nil {
+		return err
+	}
+	if err := c.checkResources(ctx, os.Stderr); err != nil {
+		return err
 	}
 	userInfo, err := user.Current()
 	if err != nil {
@@ -84,14 +148,17 @@ func (c *NewCmd) Run(k *kong.Kong, cctx *CLIContext) error {
 	}
 	// Generate a new ID if one was not provided
 	if c.SandboxName == "" {
-		seed := time.Now().UTC().UnixNano()
-		nameGenerator := namegenerator.NewNameGenerator(seed)
-		c.SandboxName = nameGenerator.Generate()
+		if c.SandboxName, err = newSandboxName(ctx, mc); err != nil {
+			return err
+		}
 	}
 
 	if c.EnvFile != "" && !filepath.IsAbs(c.EnvFile) {
 		c.EnvFile = filepath.Join(c.CloneFromDir, c.EnvFile)
 	}
+	if c.CloneRoot != "" && !filepath.IsAbs(c.CloneRoot) {
+		c.CloneRoot = filepath.Join(cwd, c.CloneRoot)
+	}
 
 	if c.Branch {
 		if err := validateNewSandboxBranch(ctx, hostops.NewDefaultGitOps(), c.CloneFromDir, c.SandboxName); err != nil {
@@ -99,11 +166,16 @@ func (c *NewCmd) Run(k *kong.Kong, cctx *CLIContext) error {
 		}
 	}
 
-	if c.ImageName == "" {
-		c.ImageName = DefaultImageName
+	labels, err := sandtypes.ParseLabels(c.Label)
+	if err != nil {
+		return err
 	}
 
-	if err := mc.EnsureImage(ctx, c.ImageName, os.Stdout); err != nil {
+	ensureOpts, err := c.ensureImageOpts()
+	if err != nil {
+		return err
+	}
+	if err := mc.EnsureImage(ctx, ensureOpts, os.Stdout); err != nil {
 		return fmt.Errorf("ensuring image %s: %w", c.ImageName, err)
 	}
 
@@ -127,21 +199,33 @@ func (c *NewCmd) Run(k *kong.Kong, cctx *CLIContext) error {
 		// Sandbox doesn't exist, create it via daemon
 		slog.InfoContext(ctx, "Creating new sandbox via daemon", "name", c.SandboxName)
 		sbox, err = mc.CreateSandbox(ctx, daemon.CreateSandboxOpts{
-			Name:           c.SandboxName,
-			CloneFromDir:   c.CloneFromDir,
-			ProfileName:    c.ProfileName,
-			ImageName:      c.ImageName,
-			EnvFile:        c.EnvFile,
-			Agent:          c.Agent,
-			SSHAgent:       c.SSHAgent,
-			AllowedDomains: allowedDomains,
-			Mounts:         c.Mount,
-			CloneMounts:    c.CloneMount,
-			SharedCaches:   cctx.SharedCaches,
-			CPUs:           c.CPU,
-			Memory:         c.Memory,
-			Username:       c.Username,
-			Uid:            c.Uid,
+			Name:                 c.SandboxName,
+			CloneFromDir:         c.CloneFromDir,
+			ProfileName:          c.ProfileName,
+			ImageName:            c.ImageName,
+			EnvFile:              c.EnvFile,
+			Agent:                c.Agent,
+			SSHAgent:             c.SSHAgent,
+			AllowedDomains:       allowedDomains,
+			Labels:               labels,
+			Shell:                c.Shell,
+			Network:              c.Network,
+			CloneRoot:            c.CloneRoot,
+			FromBranch:           c.FromBranch,
+			NoDotfiles:           c.NoDotfiles,
+			NoSSH:                c.NoSSH,
+			AllowEmulation:       c.AllowEmulation,
+			IgnoreOnCreateErrors: c.IgnoreOnCreateErrors,
+			Publish:              c.Publish,
+			DNS:                  c.dnsConfig(),
+			Mounts:               c.Mount,
+			CloneMounts:          c.CloneMount,
+			SharedCaches:         cctx.SharedCaches,
+			CPUs:                 c.CPU,
+			Memory:               c.Memory,
+			Username:             c.Username,
+			Uid:                  c.Uid,
+			MessageLevel:         cctx.MessageLevel,
 		}, os.Stdout)
 		if err != nil {
 			slog.ErrorContext(ctx, "CreateSandbox", "error", err)
@@ -180,7 +264,14 @@ func (c *NewCmd) Run(k *kong.Kong, cctx *CLIContext) error {
 	}
 
 	// TODO: Sort out how "new" and "shell" should work when invoked inside a container.
-	shell, args, err := agentlaunch.BuildInteractiveExec(c.Agent, c.Shell, sbox.Name, hostname, c.Tmux, c.Atch)
+	shell := c.Shell
+	if !c.Tmux {
+		shell, err = selectShell(ctx, sbox, c.Shell)
+		if err != nil {
+			return err
+		}
+	}
+	shell, args, err := agentlaunch.BuildInteractiveExec(c.Agent, shell, sbox.Name, hostname, c.Tmux, c.Atch)
 	if err != nil {
 		return err
 	}
@@ -202,7 +293,8 @@ func (c *NewCmd) Run(k *kong.Kong, cctx *CLIContext) error {
 		defer shellEnv.Cleanup()
 	}
 
-	if err := runShell(ctx, sbox, shell, args, c.Agent != "", shellEnv.EnvFile, mergeEnv(shellEnv.Env, agentEnv)); err != nil {
+	markSandboxUsed(ctx, mc, sbox)
+	if err := runShell(ctx, sbox, os.Stdout, containerAppDir, shell, args, c.Agent != "", shellEnv.EnvFile, mergeEnv(shellEnv.Env, agentEnv)); err != nil {
 		return err
 	}
 
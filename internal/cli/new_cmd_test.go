@@ -1,9 +1,13 @@
 package cli
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
@@ -50,7 +54,7 @@ func TestCheckoutSandboxBranch(t *testing.T) {
 				t.Fatalf("expected 2 ssh calls, got %d", len(calls))
 			}
 			wantFirst := []string{
-				"sb-123.local",
+				"alice@sb-123.local",
 				"cd '/app' && env 'HOSTNAME=sb-123.local' 'git' 'config' '--global' '--add' 'safe.directory' '/app'",
 			}
 			if tt.projectEnv.Env["PROJECT_NAME"] != "" {
@@ -60,7 +64,7 @@ func TestCheckoutSandboxBranch(t *testing.T) {
 				t.Fatalf("config ssh call = %#v, want %#v", calls[0], wantFirst)
 			}
 			wantSecond := []string{
-				"sb-123.local",
+				"alice@sb-123.local",
 				"cd '/app' && env 'HOSTNAME=sb-123.local' 'git' 'checkout' '-b' 'sb-123'",
 			}
 			if tt.projectEnv.Env["PROJECT_NAME"] != "" {
@@ -157,3 +161,117 @@ func TestValidateNewSandboxBranch(t *testing.T) {
 		}
 	})
 }
+
+func TestNewCmdEnsureImageOpts(t *testing.T) {
+	c := &NewCmd{SandboxName: "box", Username: "alice"}
+	c.PullAlways = true
+	opts, err := c.ensureImageOpts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.ImageName != DefaultImageName || !opts.PullAlways || opts.DockerfileDir != "" {
+		t.Errorf("ensureImageOpts() without --dockerfile = %+v, want a pull of %s", opts, DefaultImageName)
+	}
+
+	c = &NewCmd{SandboxName: "box", Username: "alice", Dockerfile: "image"}
+	opts, err = c.ensureImageOpts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cwd, _ := os.Getwd()
+	if opts.ImageName != "sand-local/box:latest" || opts.DockerfileDir != filepath.Join(cwd, "image") || opts.BuildArgs["USERNAME"] != "alice" {
+		t.Errorf("ensureImageOpts() with --dockerfile = %+v, want a build of sand-local/box:latest from ./image", opts)
+	}
+	if c.ImageName != opts.ImageName {
+		t.Errorf("ImageName = %q, want the built tag %q", c.ImageName, opts.ImageName)
+	}
+}
+
+func TestCheckResources(t *testing.T) {
+	const gib = 1024 * 1024 * 1024
+	tests := []struct {
+		name        string
+		cpus        int
+		memoryBytes uint64
+		capacityErr error
+		flags       SandboxCreationFlags
+		wantErr     string
+		wantWarning string
+	}{
+		{name: "fits", cpus: 8, memoryBytes: 16 * gib, flags: SandboxCreationFlags{CPU: 8, Memory: 16 * 1024}},
+		{name: "too many cpus", cpus: 8, memoryBytes: 16 * gib, flags: SandboxCreationFlags{CPU: 12, Memory: 1024},
+			wantErr: "--cpu 12 is more than the 8 CPUs this host has (pass --force to try anyway)"},
+		{name: "too much memory", cpus: 8, memoryBytes: 16 * gib, flags: SandboxCreationFlags{CPU: 2, Memory: 32 * 1024},
+			wantErr: "--memory 32768 is more than the 16384 MiB of memory this host has (pass --force to try anyway)"},
+		{name: "both", cpus: 4, memoryBytes: 8 * gib, flags: SandboxCreationFlags{CPU: 6, Memory: 9000},
+			wantErr: "--cpu 6 is more than the 4 CPUs this host has; --memory 9000 is more than the 8192 MiB of memory this host has (pass --force to try anyway)"},
+		{name: "forced", cpus: 8, memoryBytes: 16 * gib, flags: SandboxCreationFlags{CPU: 12, Memory: 1024, Force: true},
+			wantWarning: "warning: --cpu 12 is more than the 8 CPUs this host has; creating the sandbox anyway because of --force\n"},
+		{name: "capacity unknown", capacityErr: errors.ErrUnsupported, flags: SandboxCreationFlags{CPU: 1000, Memory: 1 << 30}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			old := hostCapacity
+			hostCapacity = func() (int, uint64, error) { return tt.cpus, tt.memoryBytes, tt.capacityErr }
+			defer func() { hostCapacity = old }()
+
+			var warn bytes.Buffer
+			err := tt.flags.checkResources(context.Background(), &warn)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("checkResources() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("checkResources() error = %v", err)
+			}
+			if warn.String() != tt.wantWarning {
+				t.Fatalf("checkResources() warning = %q, want %q", warn.String(), tt.wantWarning)
+			}
+		})
+	}
+}
+
+func TestResolveCloneFromDir(t *testing.T) {
+	cwd := t.TempDir()
+	sibling := filepath.Join(filepath.Dir(cwd), filepath.Base(cwd)+"-sibling")
+	if err := os.Mkdir(sibling, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(sibling) })
+	if err := os.WriteFile(filepath.Join(cwd, "notes.txt"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		dir     string
+		want    string
+		wantErr string
+	}{
+		{name: "unset", dir: "", want: cwd},
+		{name: "absolute", dir: sibling, want: sibling},
+		{name: "relative to cwd", dir: "../" + filepath.Base(sibling) + "/", want: sibling},
+		{name: "missing", dir: "../no-such-project", wantErr: "--clone-from-dir ../no-such-project: no such directory"},
+		{name: "file", dir: "notes.txt", wantErr: "--clone-from-dir notes.txt: not a directory"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &SandboxCreationFlags{CloneFromDir: tt.dir}
+			err := f.resolveCloneFromDir(cwd)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("resolveCloneFromDir() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveCloneFromDir() error = %v", err)
+			}
+			if f.CloneFromDir != tt.want {
+				t.Fatalf("CloneFromDir = %q, want %q", f.CloneFromDir, tt.want)
+			}
+		})
+	}
+}
@@ -6,18 +6,18 @@ import (
 	"os"
 	"os/user"
 	"path/filepath"
-	"time"
 
 	"github.com/banksean/sand/internal/cli/agentlaunch"
 	"github.com/banksean/sand/internal/daemon"
 	"github.com/banksean/sand/internal/runtimedeps"
-	"github.com/goombaio/namegenerator"
+	"github.com/banksean/sand/internal/sandtypes"
 )
 
 // OneshotCmd creates a sandbox (or reuses an existing one) and runs an AI agent
 // non-interactively with the given prompt, streaming output to stdout.
 type OneshotCmd struct {
 	SandboxCreationFlags
+	PullAlwaysFlag
 	Agent       string `short:"a" required:"" placeholder:"<claude|codex|gemini|opencode>" help:"coding agent to use"`
 	Username    string `help:"name of default user to create (defaults to $USER)"`
 	Uid         string `help:"id of default user to create (defaults to $UID)"`
@@ -30,16 +30,18 @@ func (c *OneshotCmd) Run(cctx *CLIContext) error {
 	ctx := cctx.Context
 	mc := cctx.Daemon
 
-	if err := runtimedeps.Verify(ctx, cctx.AppBaseDir, runtimedeps.GitDir); err != nil {
-		return err
-	}
-
 	cwd, err := os.Getwd()
 	if err != nil {
 		return err
 	}
-	if c.CloneFromDir == "" {
-		c.CloneFromDir = cwd
+	if err := c.resolveCloneFromDir(cwd); err != nil {
+		return err
+	}
+	if err := runtimedeps.VerifyWithOptions(ctx, cctx.AppBaseDir, runtimedeps.VerifyOptions{Dir: c.CloneFromDir}, runtimedeps.GitDir); err != nil {
+		return err
+	}
+	if err := c.checkResources(ctx, os.Stderr); err != nil {
+		return err
 	}
 
 	userInfo, err := user.Current()
@@ -54,19 +56,25 @@ func (c *OneshotCmd) Run(cctx *CLIContext) error {
 	}
 
 	if c.SandboxName == "" {
-		seed := time.Now().UTC().UnixNano()
-		c.SandboxName = namegenerator.NewNameGenerator(seed).Generate()
+		if c.SandboxName, err = newSandboxName(ctx, mc); err != nil {
+			return err
+		}
 	}
 
 	if c.EnvFile != "" && !filepath.IsAbs(c.EnvFile) {
 		c.EnvFile = filepath.Join(c.CloneFromDir, c.EnvFile)
 	}
 
+	labels, err := sandtypes.ParseLabels(c.Label)
+	if err != nil {
+		return err
+	}
+
 	if c.ImageName == "" {
 		c.ImageName = DefaultImageName
 	}
 
-	if err := mc.EnsureImage(ctx, c.ImageName, os.Stdout); err != nil {
+	if err := mc.EnsureImage(ctx, daemon.EnsureImageOpts{ImageName: c.ImageName, PullAlways: c.PullAlways}, os.Stdout); err != nil {
 		return fmt.Errorf("ensuring image %s: %w", c.ImageName, err)
 	}
 
@@ -100,6 +108,8 @@ func (c *OneshotCmd) Run(cctx *CLIContext) error {
 			Agent:          c.Agent,
 			SSHAgent:       c.SSHAgent,
 			AllowedDomains: allowedDomains,
+			Labels:         labels,
+			Network:        c.Network,
 			Mounts:         c.Mount,
 			CloneMounts:    c.CloneMount,
 			SharedCaches:   cctx.SharedCaches,
@@ -107,6 +117,7 @@ func (c *OneshotCmd) Run(cctx *CLIContext) error {
 			Memory:         c.Memory,
 			Username:       c.Username,
 			Uid:            c.Uid,
+			MessageLevel:   cctx.MessageLevel,
 		}, os.Stdout)
 		if err != nil {
 			return fmt.Errorf("creating sandbox: %w", err)
@@ -123,6 +134,7 @@ func (c *OneshotCmd) Run(cctx *CLIContext) error {
 		env = map[string]string{}
 	}
 	env["SAND_ONESHOT_PROMPT"] = c.Prompt
+	markSandboxUsed(ctx, mc, sbox)
 	if err := runSSHStream(ctx, sbox, true, "", env, "/bin/sh", "-c", agentCmd); err != nil {
 		return fmt.Errorf("starting agent in sandbox %s: %w", sbox.ID, err)
 	}
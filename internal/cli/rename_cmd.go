@@ -3,7 +3,7 @@ package cli
 import "fmt"
 
 type RenameCmd struct {
-	OldName string `arg:"" required:"" help:"current sandbox name" predictor:"sandbox-name"`
+	OldName string `arg:"" required:"" help:"current sandbox name" completion-predictor:"sandbox-name"`
 	NewName string `arg:"" required:"" help:"new sandbox name"`
 }
 
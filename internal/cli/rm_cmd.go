@@ -18,6 +18,7 @@ var (
 
 type RmCmd struct {
 	MultiSandboxNameFlags
+	AllFilterFlag
 	Force bool `short:"f" help:"move sandbox to trash without confirmation"`
 }
 
@@ -31,22 +32,13 @@ func (c *RmCmd) Run(cctx *CLIContext) error {
 		name string
 		id   string
 	}
+	bxs, err := selectSandboxes(ctx, mc, c.MultiSandboxNameFlags, c.AllFilterFlag)
+	if err != nil {
+		return err
+	}
 	targets := []rmTarget{}
-	if !c.All {
-		if len(c.SandboxNames) == 0 {
-			return fmt.Errorf("sandbox name required unless --all is set")
-		}
-		for _, name := range c.SandboxNames {
-			targets = append(targets, rmTarget{name: name})
-		}
-	} else {
-		bxs, err := mc.ListSandboxes(ctx)
-		if err != nil {
-			return err
-		}
-		for _, bx := range bxs {
-			targets = append(targets, rmTarget{name: bx.Name, id: bx.ID})
-		}
+	for _, bx := range bxs {
+		targets = append(targets, rmTarget{name: bx.Name, id: bx.ID})
 	}
 
 	if !c.Force {
@@ -74,7 +66,7 @@ func (c *RmCmd) Run(cctx *CLIContext) error {
 			if target.id == "" {
 				sbox, err := mc.GetSandbox(ctx, target.name)
 				if err != nil {
-					errChan <- err
+					errChan <- fmt.Errorf("remove %s: %w", target.name, err)
 					return
 				}
 				if sbox != nil {
@@ -82,7 +74,7 @@ func (c *RmCmd) Run(cctx *CLIContext) error {
 				}
 			}
 			if err := mc.RemoveSandbox(ctx, target.name); err != nil {
-				errChan <- err
+				errChan <- fmt.Errorf("remove %s: %w", target.name, err)
 				return
 			}
 			if target.id != "" {
@@ -96,12 +88,12 @@ func (c *RmCmd) Run(cctx *CLIContext) error {
 	wg.Wait()
 	close(errChan)
 
-	// Return the first error if any occurred
+	// Every sandbox was attempted; report all of the failures together.
+	var errs []error
 	for err := range errChan {
-		return err
+		errs = append(errs, err)
 	}
-
-	return nil
+	return errors.Join(errs...)
 }
 
 func confirmSandboxRemoval(id string, reader *bufio.Reader, stdout io.Writer) (bool, error) {
@@ -100,3 +100,30 @@ func testBoxIDs(boxes []sandtypes.Box) []string {
 	}
 	return ids
 }
+
+func TestRmCmd_AllFilterRemovesOnlyMatchingSandboxes(t *testing.T) {
+	cctx := newTestCLIContext(t, func(ctx context.Context, s daemontest.SandboxStore) {
+		for id, team := range map[string]string{"infra": "infra", "web": "web"} {
+			box := newTestBox(id)
+			box.Labels = map[string]string{"team": team}
+			s.SaveSandbox(ctx, box)
+		}
+	})
+
+	cmd := &RmCmd{
+		MultiSandboxNameFlags: MultiSandboxNameFlags{All: true},
+		AllFilterFlag:         AllFilterFlag{Filter: []string{"label=team=infra"}},
+		Force:                 true,
+	}
+	if err := cmd.Run(cctx); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	boxes, err := cctx.Daemon.ListSandboxes(context.Background())
+	if err != nil {
+		t.Fatalf("ListSandboxes() error = %v", err)
+	}
+	if len(boxes) != 1 || boxes[0].ID != "web" {
+		t.Fatalf("expected only web to remain, got %v", testBoxIDs(boxes))
+	}
+}
@@ -1,24 +1,54 @@
 package cli
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"log/slog"
+	"os"
 	"os/user"
+	"slices"
+	"strings"
 
 	"github.com/banksean/sand/internal/daemon"
+	"github.com/banksean/sand/internal/sandtypes"
 )
 
+// fallbackShells are tried in order when neither --shell nor the sandbox's
+// saved shell can be exec'd in its container.
+var fallbackShells = []string{"/bin/zsh", "/bin/bash", "/bin/sh"}
+
+var shellCmdStdout io.Writer = os.Stdout
+
+// shellSessionStdout is where the session's own output goes. Leaving it
+// os.Stdout hands ssh the terminal itself.
+var shellSessionStdout io.Writer = os.Stdout
+
 type ShellCmd struct {
 	ShellFlags
 	ProjectEnvFlag
-	SSHAgent bool `help:"enable ssh-agent forwarding for the container"`
+	EnvFlag
+	RootFlag
+	WorkDirFlag
+	SSHAgent bool   `help:"enable ssh-agent forwarding for the container"`
+	Resync   bool   `help:"copy files changed on the host since the last sync into the sandbox clone before attaching"`
+	Record   string `placeholder:"<file.cast>" help:"also record the session's output to an asciinema v2 cast file"`
+	Image    string `placeholder:"<container-image-name>" help:"move the sandbox onto another base image: its container is recreated from the image, keeping the sandbox's clone and git state"`
 	SandboxNameFlag
+	Cmd []string `arg:"" optional:"" help:"interactive command to run with a TTY instead of a shell; put it after -- if it has flags, e.g. sand shell <name> -- nvim -R ."`
 }
 
 func (c *ShellCmd) Run(cctx *CLIContext) error {
 	ctx := cctx.Context
 	mc := cctx.Daemon
 
+	flagEnv, err := parseEnvFlags(c.Env)
+	if err != nil {
+		return err
+	}
+	if err := c.WorkDirFlag.validate(); err != nil {
+		return err
+	}
 	sbox, err := mc.GetSandbox(ctx, c.SandboxName)
 	if err != nil {
 		slog.ErrorContext(ctx, "GetSandbox", "error", err, "name", c.SandboxName)
@@ -38,14 +68,41 @@ func (c *ShellCmd) Run(cctx *CLIContext) error {
 		sbox.Uid = userInfo.Uid
 	}
 
+	if c.Resync {
+		result, err := mc.ResyncWorkspace(ctx, sbox.Name)
+		if err != nil {
+			return fmt.Errorf("could not resync %s from %s: %w", sbox.Name, sbox.HostOriginDir, err)
+		}
+		fmt.Fprintf(shellCmdStdout, "resynced %d changed file(s) from %s\n", len(result.Copied), sbox.HostOriginDir)
+		for _, path := range result.Conflicts {
+			fmt.Fprintf(shellCmdStdout, "warning: skipped %s: it changed both on the host and inside %s\n", path, sbox.Name)
+		}
+	}
+
 	slog.InfoContext(ctx, "main: sbox.shell starting")
 
 	// sbox.Container is populated by GetSandbox; its Status is fresh enough to
 	// decide whether to start the container without a redundant Inspect call.
-	if sbox.Container == nil || sbox.Container.Status.State != "running" {
+	// A missing container is recreated from the sandbox's clone by StartSandbox,
+	// but one GetSandbox couldn't inspect may still exist, so that is an error.
+	changeImage := c.Image != "" && c.Image != sbox.ImageName
+	if changeImage {
+		if err := mc.EnsureImage(ctx, daemon.EnsureImageOpts{ImageName: c.Image}, shellCmdStdout); err != nil {
+			return fmt.Errorf("ensuring image %s: %w", c.Image, err)
+		}
+		fmt.Fprintf(shellCmdStdout, "[sand] recreating the container for %s from %s (was %s)\n", sbox.Name, c.Image, sbox.ImageName)
+	}
+	if sbox.Container == nil && sbox.SandboxContainerError != "" {
+		return fmt.Errorf("could not inspect container %s for %s (%s)", sbox.ContainerID, sbox.Name, sbox.SandboxContainerError)
+	}
+	if changeImage || sbox.Container == nil || sbox.Container.Status.State != "running" {
+		if sbox.Container == nil && !changeImage {
+			fmt.Fprintf(shellCmdStdout, "[sand] container for %s not found; recreating it\n", sbox.Name)
+		}
 		if err := mc.StartSandbox(ctx, daemon.StartSandboxOpts{
 			Name:     sbox.Name,
 			SSHAgent: c.SSHAgent,
+			Image:    c.Image,
 		}); err != nil {
 			return fmt.Errorf("could not start container for %s: %w", sbox.Name, err)
 		}
@@ -56,20 +113,34 @@ func (c *ShellCmd) Run(cctx *CLIContext) error {
 	}
 
 	if c.SSHAgent && sbox.Container != nil && !sbox.Container.Configuration.SSH {
-		fmt.Printf("warning: %s is already running without ssh-agent forwarding; stop it and run `sand shell %s --ssh-agent` again to recreate it with ssh-agent enabled\n", sbox.Name, sbox.Name)
+		fmt.Fprintf(shellCmdStdout, "warning: %s is already running without ssh-agent forwarding; stop it and run `sand shell %s --ssh-agent` again to recreate it with ssh-agent enabled\n", sbox.Name, sbox.Name)
 	}
 
-	shell := c.Shell
-	var args []string
+	c.RootFlag.apply(sbox)
+
 	if c.Tmux && c.Atch {
 		return fmt.Errorf("--tmux and --atch cannot be used together")
 	}
-	if c.Tmux {
+	if len(c.Cmd) > 0 && (c.Tmux || c.Atch) {
+		return fmt.Errorf("a command cannot be combined with --tmux or --atch")
+	}
+	var shell string
+	var args []string
+	if len(c.Cmd) > 0 {
+		// Run the command itself as the session, so it exits with the command.
+		shell, args = c.Cmd[0], c.Cmd[1:]
+	} else if c.Tmux {
 		shell = "/usr/bin/tmux"
 		args = []string{"new-session", "-A"}
-	} else if c.Atch {
-		shell = "/usr/local/bin/atch"
-		args = []string{sbox.Name, c.Shell}
+	} else {
+		shell, err = selectShell(ctx, sbox, c.Shell)
+		if err != nil {
+			return err
+		}
+		if c.Atch {
+			args = []string{sbox.Name, shell}
+			shell = "/usr/local/bin/atch"
+		}
 	}
 
 	projectEnv, err := plainCommandProjectEnv(sbox, c.ProjectEnv)
@@ -77,5 +148,84 @@ func (c *ShellCmd) Run(cctx *CLIContext) error {
 		return err
 	}
 	defer projectEnv.Cleanup()
-	return runShell(ctx, sbox, shell, args, false, projectEnv.EnvFile, projectEnv.Env)
+
+	stdout := shellSessionStdout
+	if c.Record != "" {
+		recorder, finish, err := recordShellSession(c.Record, sbox, shell, args)
+		if err != nil {
+			return err
+		}
+		defer finish()
+		stdout = recorder
+	}
+	markSandboxUsed(ctx, mc, sbox)
+	return runShell(ctx, sbox, stdout, c.dir(), shell, args, false, projectEnv.EnvFile, mergeEnv(projectEnv.Env, flagEnv))
+}
+
+// recordShellSession creates a cast file at path and returns the writer that
+// shows the session on shellSessionStdout while recording it there. finish
+// completes the recording and says where it is.
+func recordShellSession(path string, sbox *sandtypes.Box, shell string, args []string) (io.Writer, func(), error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("creating session recording: %w", err)
+	}
+	header := castHeader{
+		Title: strings.Join(append([]string{"sand shell", sbox.Name, shell}, args...), " "),
+		Env:   map[string]string{"SHELL": shell},
+	}
+	header.Width, header.Height = castTerminalSize(os.Stdin)
+	if termName := os.Getenv("TERM"); termName != "" {
+		header.Env["TERM"] = termName
+	}
+	cast, err := newCastWriter(f, header, nil)
+	if err != nil {
+		f.Close()
+		return nil, nil, fmt.Errorf("recording session to %s: %w", path, err)
+	}
+	finish := func() {
+		err := cast.Close()
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			fmt.Fprintf(shellCmdStdout, "warning: session recording %s is incomplete: %v\n", path, err)
+			return
+		}
+		fmt.Fprintf(shellCmdStdout, "[sand] recorded session to %s\n", path)
+	}
+	return &recordingWriter{terminal: shellSessionStdout, cast: cast}, finish, nil
+}
+
+// shellProbeScript prints each of its arguments that names a command in the
+// container, one per line.
+const shellProbeScript = `for shell do command -v "$shell" >/dev/null 2>&1 && echo "$shell"; done; exit 0`
+
+// selectShell returns the shell to exec in sbox's container. Candidates are
+// tried in order: preferred (from --shell), the shell saved on the sandbox,
+// then fallbackShells. They are all looked up in one exec over SSH, so an
+// image that lacks zsh still gets a usable shell.
+func selectShell(ctx context.Context, sbox *sandtypes.Box, preferred string) (string, error) {
+	var candidates []string
+	for _, shell := range append([]string{preferred, sbox.Shell}, fallbackShells...) {
+		if shell != "" && !slices.Contains(candidates, shell) {
+			candidates = append(candidates, shell)
+		}
+	}
+	out, err := runSSHOutput(ctx, sbox, "", nil, "/bin/sh", append([]string{"-c", shellProbeScript, "sh"}, candidates...)...)
+	if err != nil {
+		return "", fmt.Errorf("look up shells in sandbox %s: %w (output: %s)", sbox.Name, err, strings.TrimSpace(out))
+	}
+	available := strings.Fields(out)
+	for _, shell := range candidates {
+		if !slices.Contains(available, shell) {
+			slog.InfoContext(ctx, "selectShell: shell not available", "sandbox", sbox.ID, "shell", shell)
+			continue
+		}
+		if shell != candidates[0] && (preferred != "" || sbox.Shell != "") {
+			fmt.Fprintf(shellCmdStdout, "warning: %s is not available in %s; using %s\n", candidates[0], sbox.Name, shell)
+		}
+		return shell, nil
+	}
+	return "", fmt.Errorf("none of %s could be run in sandbox %s; pass --shell with a shell installed in its image", strings.Join(candidates, ", "), sbox.Name)
 }
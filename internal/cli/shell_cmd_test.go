@@ -0,0 +1,516 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/banksean/sand/internal/daemon/daemontest"
+	"github.com/banksean/sand/internal/hostops"
+	"github.com/banksean/sand/internal/sandtypes"
+)
+
+func shellProbeBox(saved string) *sandtypes.Box {
+	return &sandtypes.Box{
+		ID:        "sb-1",
+		Name:      "sb",
+		Shell:     saved,
+		Container: &sandtypes.Container{Configuration: sandtypes.ContainerConfig{ID: "sb-1.local"}},
+	}
+}
+
+// probedShells returns the shells the stubbed ssh calls looked up, in order.
+func probedShells(t *testing.T, calls [][]string) []string {
+	t.Helper()
+	if len(calls) != 1 {
+		t.Fatalf("ssh calls = %q, want one shell probe", calls)
+	}
+	remote := calls[0][len(calls[0])-1]
+	_, args, ok := strings.Cut(remote, shellQuote(shellProbeScript)+" 'sh' ")
+	if !ok {
+		t.Fatalf("probe command = %q, want the shell probe script", remote)
+	}
+	var shells []string
+	for _, arg := range strings.Fields(args) {
+		shells = append(shells, strings.Trim(arg, "'"))
+	}
+	return shells
+}
+
+func TestSelectShellProbesInOrder(t *testing.T) {
+	tests := []struct {
+		name       string
+		preferred  string
+		saved      string
+		available  string
+		wantShell  string
+		wantProbed []string
+	}{
+		{
+			name:       "default prefers zsh",
+			available:  "/bin/zsh\n/bin/bash\n/bin/sh\n",
+			wantShell:  "/bin/zsh",
+			wantProbed: []string{"/bin/zsh", "/bin/bash", "/bin/sh"},
+		},
+		{
+			name:       "falls back when zsh is missing",
+			available:  "/bin/bash\n/bin/sh\n",
+			wantShell:  "/bin/bash",
+			wantProbed: []string{"/bin/zsh", "/bin/bash", "/bin/sh"},
+		},
+		{
+			name:       "falls back to sh last",
+			available:  "/bin/sh\n",
+			wantShell:  "/bin/sh",
+			wantProbed: []string{"/bin/zsh", "/bin/bash", "/bin/sh"},
+		},
+		{
+			name:       "saved shell is tried first",
+			saved:      "/bin/bash",
+			available:  "/bin/bash\n/bin/zsh\n/bin/sh\n",
+			wantShell:  "/bin/bash",
+			wantProbed: []string{"/bin/bash", "/bin/zsh", "/bin/sh"},
+		},
+		{
+			name:       "flag overrides saved shell",
+			preferred:  "/opt/fish",
+			saved:      "/bin/bash",
+			available:  "/opt/fish\n/bin/bash\n/bin/sh\n",
+			wantShell:  "/opt/fish",
+			wantProbed: []string{"/opt/fish", "/bin/bash", "/bin/zsh", "/bin/sh"},
+		},
+		{
+			name:       "missing flag shell falls back without retrying duplicates",
+			preferred:  "/bin/bash",
+			saved:      "/bin/bash",
+			available:  "/bin/zsh\n/bin/sh\n",
+			wantShell:  "/bin/zsh",
+			wantProbed: []string{"/bin/bash", "/bin/zsh", "/bin/sh"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls [][]string
+			restore := stubSSH(t, &calls, []string{tt.available}, nil)
+			defer restore()
+
+			got, err := selectShell(context.Background(), shellProbeBox(tt.saved), tt.preferred)
+			if err != nil {
+				t.Fatalf("selectShell() error = %v", err)
+			}
+			if got != tt.wantShell {
+				t.Errorf("selectShell() = %q, want %q", got, tt.wantShell)
+			}
+			if probed := probedShells(t, calls); strings.Join(probed, ",") != strings.Join(tt.wantProbed, ",") {
+				t.Errorf("probed %v, want %v", probed, tt.wantProbed)
+			}
+		})
+	}
+}
+
+func TestSelectShellErrorsWhenNoShellExists(t *testing.T) {
+	var calls [][]string
+	restore := stubSSH(t, &calls, nil, nil)
+	defer restore()
+
+	_, err := selectShell(context.Background(), shellProbeBox(""), "")
+	if err == nil {
+		t.Fatal("selectShell() error = nil, want error")
+	}
+	if !strings.Contains(err.Error(), "/bin/zsh, /bin/bash, /bin/sh") || !strings.Contains(err.Error(), "--shell") {
+		t.Fatalf("selectShell() error = %v, want the candidates and a --shell hint", err)
+	}
+}
+
+func TestSelectShellReportsSSHFailures(t *testing.T) {
+	var calls [][]string
+	restore := stubSSH(t, &calls, []string{"ssh: connect to host sb-1.local port 22: Connection refused"}, []int{255})
+	defer restore()
+
+	_, err := selectShell(context.Background(), shellProbeBox(""), "")
+	if err == nil || !strings.Contains(err.Error(), "Connection refused") || strings.Contains(err.Error(), "--shell") {
+		t.Fatalf("selectShell() error = %v, want the ssh failure rather than a missing shell", err)
+	}
+}
+
+func TestShellCmdRunsTrailingCommandWithTTY(t *testing.T) {
+	box := newTestBox("sb-cmd")
+	box.Name = "sb-cmd"
+	box.Shell = "/bin/bash"
+	box.Username = "dev"
+	client := daemontest.StartDaemon(t, daemontest.Deps{
+		ContainerService: &hostops.MockContainerOps{
+			InspectFunc: func(_ context.Context, containerID string) ([]sandtypes.Container, error) {
+				return []sandtypes.Container{{
+					Configuration: sandtypes.ContainerConfig{ID: "sb-cmd.local"},
+					Status:        sandtypes.ContainerStatus{State: "running"},
+				}}, nil
+			},
+		},
+	}, func(ctx context.Context, s daemontest.SandboxStore) {
+		if err := s.SaveSandbox(ctx, box); err != nil {
+			t.Fatalf("SaveSandbox: %v", err)
+		}
+	})
+	var calls [][]string
+	restore := stubSSH(t, &calls, nil, nil)
+	defer restore()
+
+	cmd := &ShellCmd{SandboxNameFlag: SandboxNameFlag{SandboxName: "sb-cmd"}, Cmd: []string{"nvim", "-R", "."}}
+	if err := cmd.Run(&CLIContext{Context: context.Background(), Daemon: client}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if len(calls) != 1 {
+		t.Fatalf("ssh calls = %q, want a single session with no shell probing", calls)
+	}
+	call := calls[0]
+	if call[0] != "-tt" || call[1] != "dev@sb-cmd.local" {
+		t.Errorf("ssh args = %q, want a TTY session as dev on sb-cmd.local", call)
+	}
+	if remote := call[len(call)-1]; !strings.HasSuffix(remote, " 'nvim' '-R' '.'") {
+		t.Errorf("remote command = %q, want it to exec nvim -R . in place of the shell", remote)
+	}
+}
+
+func TestShellCmdRootOverridesSandboxUser(t *testing.T) {
+	box := newTestBox("sb-root")
+	box.Name = "sb-root"
+	box.Shell = "/bin/bash"
+	box.Username = "dev"
+	box.Uid = "1000"
+	client := daemontest.StartDaemon(t, daemontest.Deps{
+		ContainerService: &hostops.MockContainerOps{
+			InspectFunc: func(_ context.Context, containerID string) ([]sandtypes.Container, error) {
+				return []sandtypes.Container{{
+					Configuration: sandtypes.ContainerConfig{ID: "sb-root.local"},
+					Status:        sandtypes.ContainerStatus{State: "running"},
+				}}, nil
+			},
+		},
+	}, func(ctx context.Context, s daemontest.SandboxStore) {
+		if err := s.SaveSandbox(ctx, box); err != nil {
+			t.Fatalf("SaveSandbox: %v", err)
+		}
+	})
+	var calls [][]string
+	restore := stubSSH(t, &calls, []string{"/bin/bash\n"}, nil)
+	defer restore()
+
+	cmd := &ShellCmd{SandboxNameFlag: SandboxNameFlag{SandboxName: "sb-root"}, RootFlag: RootFlag{Root: true}}
+	if err := cmd.Run(&CLIContext{Context: context.Background(), Daemon: client}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	// The shell probe and the session both log in as root.
+	if len(calls) != 2 {
+		t.Fatalf("ssh calls = %q, want a shell probe and a session", calls)
+	}
+	if calls[0][0] != "root@sb-root.local" {
+		t.Errorf("probe ssh args = %q, want it to log in as root", calls[0])
+	}
+	if calls[1][0] != "-tt" || calls[1][1] != "root@sb-root.local" {
+		t.Errorf("session ssh args = %q, want a TTY session as root", calls[1])
+	}
+}
+
+func TestShellCmdWorkDirOverridesAppDir(t *testing.T) {
+	box := newTestBox("sb-workdir")
+	box.Name = "sb-workdir"
+	box.Username = "dev"
+	client := daemontest.StartDaemon(t, daemontest.Deps{
+		ContainerService: &hostops.MockContainerOps{
+			InspectFunc: func(_ context.Context, containerID string) ([]sandtypes.Container, error) {
+				return []sandtypes.Container{{
+					Configuration: sandtypes.ContainerConfig{ID: "sb-workdir.local"},
+					Status:        sandtypes.ContainerStatus{State: "running"},
+				}}, nil
+			},
+		},
+	}, func(ctx context.Context, s daemontest.SandboxStore) {
+		if err := s.SaveSandbox(ctx, box); err != nil {
+			t.Fatalf("SaveSandbox: %v", err)
+		}
+	})
+	var calls [][]string
+	restore := stubSSH(t, &calls, nil, nil)
+	defer restore()
+
+	cmd := &ShellCmd{SandboxNameFlag: SandboxNameFlag{SandboxName: "sb-workdir"}, WorkDirFlag: WorkDirFlag{WorkDir: "/srv/api"}, Cmd: []string{"pwd"}}
+	if err := cmd.Run(&CLIContext{Context: context.Background(), Daemon: client}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if len(calls) != 1 {
+		t.Fatalf("ssh calls = %q, want one", calls)
+	}
+	if remote := calls[0][len(calls[0])-1]; !strings.HasPrefix(remote, "cd '/srv/api' && ") {
+		t.Errorf("remote command = %q, want it to start in /srv/api", remote)
+	}
+}
+
+func TestShellCmdRejectsRelativeWorkDir(t *testing.T) {
+	cmd := &ShellCmd{WorkDirFlag: WorkDirFlag{WorkDir: "../etc"}}
+	err := cmd.Run(&CLIContext{Context: context.Background()})
+	if err == nil || !strings.Contains(err.Error(), "--workdir ../etc: must be an absolute path") {
+		t.Fatalf("Run() error = %v, want a --workdir error", err)
+	}
+}
+
+func TestShellCmdRejectsCommandWithTmux(t *testing.T) {
+	cmd := &ShellCmd{Cmd: []string{"htop"}}
+	cmd.Tmux = true
+	client := daemontest.StartDaemon(t, daemontest.Deps{}, func(ctx context.Context, s daemontest.SandboxStore) {
+		box := newTestBox("sb-tmux")
+		box.Name = "sb-tmux"
+		if err := s.SaveSandbox(ctx, box); err != nil {
+			t.Fatalf("SaveSandbox: %v", err)
+		}
+	})
+	cmd.SandboxName = "sb-tmux"
+	err := cmd.Run(&CLIContext{Context: context.Background(), Daemon: client})
+	if err == nil || !strings.Contains(err.Error(), "--tmux") {
+		t.Fatalf("Run() error = %v, want a --tmux conflict error", err)
+	}
+}
+
+func TestShellCmdEnvFlagsOverrideEnvFile(t *testing.T) {
+	envFile := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(envFile, []byte("FOO=from-file\nBAR=kept\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	box := newTestBox("sb-env")
+	box.Name = "sb-env"
+	box.Username = "dev"
+	box.EnvFile = envFile
+	client := daemontest.StartDaemon(t, daemontest.Deps{
+		ContainerService: &hostops.MockContainerOps{
+			InspectFunc: func(_ context.Context, containerID string) ([]sandtypes.Container, error) {
+				return []sandtypes.Container{{
+					Configuration: sandtypes.ContainerConfig{ID: "sb-env.local"},
+					Status:        sandtypes.ContainerStatus{State: "running"},
+				}}, nil
+			},
+		},
+	}, func(ctx context.Context, s daemontest.SandboxStore) {
+		if err := s.SaveSandbox(ctx, box); err != nil {
+			t.Fatalf("SaveSandbox: %v", err)
+		}
+	})
+	var calls [][]string
+	restore := stubSSH(t, &calls, nil, nil)
+	defer restore()
+
+	cmd := &ShellCmd{SandboxNameFlag: SandboxNameFlag{SandboxName: "sb-env"}, Cmd: []string{"printenv"}}
+	cmd.ProjectEnv = true
+	cmd.Env = []string{"FOO=from-flag", "EMPTY="}
+	if err := cmd.Run(&CLIContext{Context: context.Background(), Daemon: client}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if len(calls) != 1 {
+		t.Fatalf("ssh calls = %q, want one", calls)
+	}
+	remote := calls[0][len(calls[0])-1]
+	for _, want := range []string{"'FOO=from-flag'", "'BAR=kept'", "'EMPTY='"} {
+		if !strings.Contains(remote, want) {
+			t.Errorf("remote command = %q, want it to set %s", remote, want)
+		}
+	}
+	if strings.Contains(remote, "from-file") {
+		t.Errorf("remote command = %q, want --env to override the env file", remote)
+	}
+}
+
+func TestShellCmdRejectsMalformedEnv(t *testing.T) {
+	for _, spec := range []string{"NOVALUE", "=value", "1FOO=x", "FOO-BAR=x"} {
+		cmd := &ShellCmd{EnvFlag: EnvFlag{Env: []string{spec}}}
+		err := cmd.Run(&CLIContext{Context: context.Background()})
+		if err == nil || !strings.Contains(err.Error(), "invalid --env") {
+			t.Errorf("Run() with --env %q error = %v, want invalid --env error", spec, err)
+		}
+	}
+}
+
+// runShellCmdWithContainers runs `sand shell sb-attach -- true` against a
+// daemon whose runtime reports the containers in running, and returns the
+// ssh sessions it opened and what it printed.
+func runShellCmdWithContainers(t *testing.T, containers *hostops.MockContainerOps, running map[string]bool) ([][]string, string) {
+	t.Helper()
+	containers.InspectFunc = func(_ context.Context, containerID string) ([]sandtypes.Container, error) {
+		if !running[containerID] {
+			return nil, fmt.Errorf("container %s: %w", containerID, hostops.ErrContainerNotFound)
+		}
+		return []sandtypes.Container{{Status: sandtypes.ContainerStatus{State: "running"}}}, nil
+	}
+	calls, out, err := runShellCmd(t, containers)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	return calls, out
+}
+
+// runShellCmd runs `sand shell sb-attach -- true` against a daemon backed by
+// containers, and returns the ssh sessions it opened, what it printed, and
+// Run's error.
+func runShellCmd(t *testing.T, containers *hostops.MockContainerOps) ([][]string, string, error) {
+	t.Helper()
+	box := newTestBox("sb-attach")
+	box.Name = "sb-attach"
+	box.Username = "dev"
+	box.SandboxWorkDir = t.TempDir()
+	box.HostOriginDir = ""
+	// daemontest has no image service to inspect an image with.
+	box.ImageName = ""
+	box.ContainerBootstrapped = true
+	box.StartHooksRan = true
+	client := daemontest.StartDaemon(t, daemontest.Deps{ContainerService: containers}, func(ctx context.Context, s daemontest.SandboxStore) {
+		if err := s.SaveSandbox(ctx, box); err != nil {
+			t.Fatalf("SaveSandbox: %v", err)
+		}
+	})
+	var calls [][]string
+	restore := stubSSH(t, &calls, nil, nil)
+	defer restore()
+	var out bytes.Buffer
+	oldStdout := shellCmdStdout
+	shellCmdStdout = &out
+	defer func() { shellCmdStdout = oldStdout }()
+
+	cmd := &ShellCmd{SandboxNameFlag: SandboxNameFlag{SandboxName: "sb-attach"}, Cmd: []string{"true"}}
+	err := cmd.Run(&CLIContext{Context: context.Background(), Daemon: client})
+	return calls, out.String(), err
+}
+
+func TestShellCmdRecreatesMissingContainerBeforeAttaching(t *testing.T) {
+	running := map[string]bool{}
+	var created, started []string
+	containers := &hostops.MockContainerOps{
+		CreateFunc: func(_ context.Context, opts *hostops.CreateContainer, _ string, _ []string) (string, error) {
+			created = append(created, opts.ManagementOptions.Name)
+			running["ctr-new"] = true
+			return "ctr-new", nil
+		},
+		StartFunc: func(_ context.Context, _ *hostops.StartContainer, containerID string) (string, error) {
+			started = append(started, containerID)
+			return "started", nil
+		},
+	}
+
+	calls, out := runShellCmdWithContainers(t, containers, running)
+	if len(created) != 1 || len(started) != 1 || started[0] != "ctr-new" {
+		t.Fatalf("created %q, started %q; want one new container created and started", created, started)
+	}
+	if !strings.Contains(out, "container for sb-attach not found; recreating it") {
+		t.Errorf("output = %q, want a note that the container is being recreated", out)
+	}
+	if len(calls) != 1 || calls[0][1] != "dev@ctr-new" {
+		t.Fatalf("ssh calls = %q, want one session on the new container", calls)
+	}
+}
+
+func TestShellCmdReportsAContainerItCouldNotInspect(t *testing.T) {
+	var created []string
+	containers := &hostops.MockContainerOps{
+		InspectFunc: func(context.Context, string) ([]sandtypes.Container, error) {
+			return nil, errors.New("container service unavailable")
+		},
+		CreateFunc: func(_ context.Context, opts *hostops.CreateContainer, _ string, _ []string) (string, error) {
+			created = append(created, opts.ManagementOptions.Name)
+			return "ctr-new", nil
+		},
+	}
+
+	calls, out, err := runShellCmd(t, containers)
+	if err == nil || !strings.Contains(err.Error(), "could not inspect container") {
+		t.Fatalf("Run() error = %v, want a could not inspect container error", err)
+	}
+	if strings.Contains(out, "recreating") {
+		t.Errorf("output = %q, want no note about recreating the container", out)
+	}
+	if len(created) != 0 || len(calls) != 0 {
+		t.Fatalf("created %q, ssh calls %q; want neither for a container that couldn't be inspected", created, calls)
+	}
+}
+
+func TestShellCmdAttachesToRunningContainer(t *testing.T) {
+	var created, started []string
+	containers := &hostops.MockContainerOps{
+		CreateFunc: func(_ context.Context, opts *hostops.CreateContainer, _ string, _ []string) (string, error) {
+			created = append(created, opts.ManagementOptions.Name)
+			return "ctr-new", nil
+		},
+		StartFunc: func(_ context.Context, _ *hostops.StartContainer, containerID string) (string, error) {
+			started = append(started, containerID)
+			return "started", nil
+		},
+	}
+
+	calls, out := runShellCmdWithContainers(t, containers, map[string]bool{"ctr-sb-attach": true})
+	if len(created) != 0 || len(started) != 0 {
+		t.Fatalf("created %q, started %q; want the running container used as is", created, started)
+	}
+	if out != "" {
+		t.Errorf("output = %q, want none", out)
+	}
+	if len(calls) != 1 || calls[0][1] != "dev@ctr-sb-attach" {
+		t.Fatalf("ssh calls = %q, want one session on the running container", calls)
+	}
+}
+
+func TestShellCmdRecordsTheSession(t *testing.T) {
+	box := newTestBox("sb-rec")
+	box.Name = "sb-rec"
+	box.Username = "dev"
+	client := daemontest.StartDaemon(t, daemontest.Deps{
+		ContainerService: &hostops.MockContainerOps{
+			InspectFunc: func(_ context.Context, containerID string) ([]sandtypes.Container, error) {
+				return []sandtypes.Container{{
+					Configuration: sandtypes.ContainerConfig{ID: "sb-rec.local"},
+					Status:        sandtypes.ContainerStatus{State: "running"},
+				}}, nil
+			},
+		},
+	}, func(ctx context.Context, s daemontest.SandboxStore) {
+		if err := s.SaveSandbox(ctx, box); err != nil {
+			t.Fatalf("SaveSandbox: %v", err)
+		}
+	})
+	var calls [][]string
+	restore := stubSSH(t, &calls, []string{"hello from sb-rec\r\n"}, nil)
+	defer restore()
+	var terminal, out bytes.Buffer
+	oldSession, oldStdout := shellSessionStdout, shellCmdStdout
+	shellSessionStdout, shellCmdStdout = &terminal, &out
+	defer func() { shellSessionStdout, shellCmdStdout = oldSession, oldStdout }()
+
+	castPath := filepath.Join(t.TempDir(), "session.cast")
+	cmd := &ShellCmd{SandboxNameFlag: SandboxNameFlag{SandboxName: "sb-rec"}, Cmd: []string{"htop"}, Record: castPath}
+	if err := cmd.Run(&CLIContext{Context: context.Background(), Daemon: client}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if terminal.String() != "hello from sb-rec\r\n" {
+		t.Errorf("terminal = %q, want the session's output", terminal.String())
+	}
+	data, err := os.ReadFile(castPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := castLines(t, string(data))
+	if len(lines) != 2 || !strings.Contains(lines[0], `"version":2`) || !strings.Contains(lines[0], `"title":"sand shell sb-rec htop"`) {
+		t.Fatalf("cast = %q, want a v2 header and one event", lines)
+	}
+	if !strings.HasSuffix(lines[1], `,"o","hello from sb-rec\r\n"]`) {
+		t.Errorf("cast event = %s, want the session's output", lines[1])
+	}
+	if !strings.Contains(out.String(), "recorded session to "+castPath) {
+		t.Errorf("output = %q, want where the recording is", out.String())
+	}
+}
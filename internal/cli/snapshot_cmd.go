@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/banksean/sand/internal/sandtypes"
+)
+
+// snapshotTagPrefix namespaces the tags snapshots are kept as in a sandbox's
+// clone, apart from the project's own tags.
+const snapshotTagPrefix = "sand-snapshot/"
+
+// snapshotScript records the working tree of /app as a commit on top of
+// HEAD, tagged $1 with message $2, and prints the commit. The working tree is
+// staged through an index of its own so the checkout's index, and what the
+// user has staged in it, isn't touched. An empty old value makes update-ref
+// refuse to replace an existing tag.
+const snapshotScript = `set -e
+head=$(git rev-parse --verify HEAD^{commit})
+index=$(mktemp -d)
+trap 'rm -rf "$index"' EXIT
+export GIT_INDEX_FILE="$index/index"
+git read-tree "$head"
+git add --all
+tree=$(git write-tree)
+unset GIT_INDEX_FILE
+commit=$(git -c user.name=sand -c user.email=sand@localhost commit-tree "$tree" -p "$head" -m "$2")
+git update-ref "refs/tags/$1" "$commit" ""
+echo "$commit"`
+
+// restoreScript puts the working tree of /app back to the snapshot tagged $1:
+// the snapshot's files overwrite the ones in the way, files it didn't have
+// are removed, and HEAD moves back to where the snapshot was taken, leaving
+// the snapshot's changes in the working tree, unstaged.
+const restoreScript = `set -e
+commit=$(git rev-parse --verify --quiet "refs/tags/$1^{commit}") || { echo "no snapshot $1" >&2; exit 1; }
+git read-tree -u --reset "$commit"
+git clean -ffdq
+git reset --quiet "$commit~1"`
+
+// snapshotStdout is where sand snapshot and sand restore report what they did.
+var snapshotStdout io.Writer = os.Stdout
+
+// snapshotNow names a snapshot taken without a name.
+var snapshotNow = time.Now
+
+// runSnapshotScript runs script with args in sbox's container. Snapshots run
+// git there rather than on the host: the clone's git config and attributes
+// are the sandbox's to write, and can make git run programs.
+var runSnapshotScript = func(ctx context.Context, sbox *sandtypes.Box, script string, args ...string) (string, error) {
+	return runSSHOutput(ctx, sbox, "", nil, "sh", append([]string{"-c", script, "sh"}, args...)...)
+}
+
+type SnapshotCmd struct {
+	SandboxNameFlag
+	Snapshot string `arg:"" optional:"" placeholder:"<snapshot>" help:"name for the snapshot (default: the current time, e.g. 20260314-150405)"`
+	Message  string `short:"m" placeholder:"<message>" help:"message to record with the snapshot"`
+}
+
+func (c *SnapshotCmd) Run(cctx *CLIContext) error {
+	ctx := cctx.Context
+	sbox, err := cctx.Daemon.GetSandbox(ctx, c.SandboxName)
+	if err != nil {
+		slog.ErrorContext(ctx, "GetSandbox", "error", err, "name", c.SandboxName)
+		return fmt.Errorf("could not find sandbox named %s: %w", c.SandboxName, err)
+	}
+
+	name := c.Snapshot
+	if name == "" {
+		name = snapshotNow().Format("20060102-150405")
+	}
+	message := c.Message
+	if message == "" {
+		message = fmt.Sprintf("sand snapshot %s of %s", name, sbox.Name)
+	}
+	out, err := runSnapshotScript(ctx, sbox, snapshotScript, snapshotTagPrefix+name, message)
+	if err != nil {
+		return fmt.Errorf("snapshot %s: %w (output: %s)", sbox.Name, err, strings.TrimSpace(out))
+	}
+	// Warnings from git add come ahead of the commit on the last line.
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	commit := strings.TrimSpace(lines[len(lines)-1])
+	fmt.Fprintf(snapshotStdout, "created snapshot %s of %s at %.12s\nroll back to it with: sand restore %s %s\n", name, sbox.Name, commit, sbox.Name, name)
+	return nil
+}
+
+type RestoreCmd struct {
+	SandboxNameFlag
+	Snapshot string `arg:"" placeholder:"<snapshot>" help:"name of the snapshot to restore"`
+}
+
+func (c *RestoreCmd) Run(cctx *CLIContext) error {
+	ctx := cctx.Context
+	sbox, err := cctx.Daemon.GetSandbox(ctx, c.SandboxName)
+	if err != nil {
+		slog.ErrorContext(ctx, "GetSandbox", "error", err, "name", c.SandboxName)
+		return fmt.Errorf("could not find sandbox named %s: %w", c.SandboxName, err)
+	}
+
+	if out, err := runSnapshotScript(ctx, sbox, restoreScript, snapshotTagPrefix+c.Snapshot); err != nil {
+		return fmt.Errorf("restore %s: %w (output: %s)", sbox.Name, err, strings.TrimSpace(out))
+	}
+	fmt.Fprintf(snapshotStdout, "restored %s to snapshot %s\n", sbox.Name, c.Snapshot)
+	return nil
+}
@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/banksean/sand/internal/sandtypes"
+)
+
+func runSnapshotCmd(t *testing.T, cctx *CLIContext, cmd interface{ Run(*CLIContext) error }) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	old := snapshotStdout
+	snapshotStdout = &out
+	defer func() { snapshotStdout = old }()
+	err := cmd.Run(cctx)
+	return out.String(), err
+}
+
+// runSnapshotScriptsIn makes snapshot scripts run with sh in appDir, standing
+// in for the sandbox's container and its /app.
+func runSnapshotScriptsIn(t *testing.T, appDir string) {
+	t.Helper()
+	old := runSnapshotScript
+	runSnapshotScript = func(ctx context.Context, sbox *sandtypes.Box, script string, args ...string) (string, error) {
+		cmd := exec.CommandContext(ctx, "sh", append([]string{"-c", script, "sh"}, args...)...)
+		cmd.Dir = appDir
+		out, err := cmd.CombinedOutput()
+		return string(out), err
+	}
+	t.Cleanup(func() { runSnapshotScript = old })
+}
+
+func TestSnapshotAndRestoreCmds(t *testing.T) {
+	hostDir, sandboxWorkDir := setupSyncRepos(t, "feature")
+	cctx := syncTestCLIContext(t, "box", hostDir, sandboxWorkDir)
+	appDir := filepath.Join(sandboxWorkDir, "app")
+	runSnapshotScriptsIn(t, appDir)
+	oldNow := snapshotNow
+	snapshotNow = func() time.Time { return time.Date(2026, 3, 14, 15, 4, 5, 0, time.UTC) }
+	defer func() { snapshotNow = oldNow }()
+
+	writeFile(t, filepath.Join(appDir, ".gitignore"), "*.log\n")
+	git(t, appDir, "add", ".gitignore")
+	git(t, appDir, "-c", "user.name=Sand", "-c", "user.email=sand@example.com", "commit", "-q", "-m", "ignore logs")
+	head := gitOutput(t, appDir, "rev-parse", "HEAD")
+	writeFile(t, filepath.Join(appDir, "tracked.txt"), "before the agent\n")
+	writeFile(t, filepath.Join(appDir, "notes.txt"), "untracked\n")
+	writeFile(t, filepath.Join(appDir, "staged.txt"), "staged\n")
+	writeFile(t, filepath.Join(appDir, "build.log"), "ignored\n")
+	git(t, appDir, "add", "staged.txt")
+	statusBefore := gitOutput(t, appDir, "status", "--porcelain")
+
+	out, err := runSnapshotCmd(t, cctx, &SnapshotCmd{SandboxNameFlag: SandboxNameFlag{SandboxName: "box"}})
+	if err != nil {
+		t.Fatalf("SnapshotCmd.Run() error = %v", err)
+	}
+	if !strings.Contains(out, "created snapshot 20260314-150405 of box") || !strings.Contains(out, "sand restore box 20260314-150405") {
+		t.Fatalf("SnapshotCmd output = %q, want the snapshot's name and how to restore it", out)
+	}
+	if got := gitOutput(t, appDir, "tag", "--list"); got != "sand-snapshot/20260314-150405" {
+		t.Fatalf("tags in the sandbox clone = %q, want the snapshot's", got)
+	}
+	tag := "sand-snapshot/20260314-150405"
+	if got := gitOutput(t, appDir, "log", "-1", "--format=%s", tag); got != "sand snapshot 20260314-150405 of box" {
+		t.Errorf("snapshot message = %q", got)
+	}
+	if got := gitOutput(t, appDir, "rev-parse", tag+"~1"); got != head {
+		t.Errorf("snapshot parent = %s, want HEAD %s", got, head)
+	}
+	if got := gitOutput(t, appDir, "ls-tree", "--name-only", tag); got != ".gitignore\nnotes.txt\nstaged.txt\ntracked.txt" {
+		t.Errorf("snapshot files = %q, want the tracked and untracked files but not the ignored one", got)
+	}
+	if gitOutput(t, appDir, "rev-parse", "HEAD") != head || gitOutput(t, appDir, "status", "--porcelain") != statusBefore {
+		t.Errorf("snapshot changed HEAD or the index; status now %q", gitOutput(t, appDir, "status", "--porcelain"))
+	}
+	if _, err := runSnapshotCmd(t, cctx, &SnapshotCmd{SandboxNameFlag: SandboxNameFlag{SandboxName: "box"}}); err == nil {
+		t.Error("SnapshotCmd.Run() of an existing snapshot error = nil, want a refusal to replace it")
+	}
+
+	// The risky change: a commit, an edit, a deletion and a new file.
+	writeFile(t, filepath.Join(appDir, "tracked.txt"), "broken by the agent\n")
+	writeFile(t, filepath.Join(appDir, "scratch.txt"), "junk\n")
+	writeFile(t, filepath.Join(appDir, "build.log"), "rebuilt\n")
+	git(t, appDir, "-c", "user.name=Sand", "-c", "user.email=sand@example.com", "commit", "-q", "-am", "agent commit")
+	if err := os.Remove(filepath.Join(appDir, "notes.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err = runSnapshotCmd(t, cctx, &RestoreCmd{SandboxNameFlag: SandboxNameFlag{SandboxName: "box"}, Snapshot: "20260314-150405"})
+	if err != nil {
+		t.Fatalf("RestoreCmd.Run() error = %v", err)
+	}
+	if out != "restored box to snapshot 20260314-150405\n" {
+		t.Errorf("RestoreCmd output = %q", out)
+	}
+	if got := gitOutput(t, appDir, "rev-parse", "HEAD"); got != head {
+		t.Errorf("HEAD after restore = %s, want %s", got, head)
+	}
+	for name, want := range map[string]string{"tracked.txt": "before the agent\n", "notes.txt": "untracked\n", "staged.txt": "staged\n", "build.log": "rebuilt\n"} {
+		if got := readFile(t, filepath.Join(appDir, name)); got != want {
+			t.Errorf("%s after restore = %q, want %q", name, got, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(appDir, "scratch.txt")); !os.IsNotExist(err) {
+		t.Errorf("scratch.txt survived the restore: %v", err)
+	}
+	if got := gitOutput(t, appDir, "status", "--porcelain"); got != "M tracked.txt\n?? notes.txt\n?? staged.txt" {
+		t.Errorf("status after restore = %q, want the snapshot's changes unstaged", got)
+	}
+
+	if _, err := runSnapshotCmd(t, cctx, &RestoreCmd{SandboxNameFlag: SandboxNameFlag{SandboxName: "box"}, Snapshot: "missing"}); err == nil || !strings.Contains(err.Error(), "no snapshot sand-snapshot/missing") {
+		t.Errorf("RestoreCmd.Run(missing) error = %v, want no snapshot", err)
+	}
+}
+
+func TestSnapshotCmdsRunNoGitOnTheHost(t *testing.T) {
+	hostDir, sandboxWorkDir := setupSyncRepos(t, "feature")
+	cctx := syncTestCLIContext(t, "box", hostDir, sandboxWorkDir)
+	appDir := filepath.Join(sandboxWorkDir, "app")
+	// A clean filter the sandbox set up for itself, which git on the host
+	// would run when staging or checking out files.
+	marker := filepath.Join(t.TempDir(), "filter-ran")
+	git(t, appDir, "config", "filter.evil.clean", "touch "+marker+"; cat")
+	git(t, appDir, "config", "filter.evil.smudge", "touch "+marker+"; cat")
+	writeFile(t, filepath.Join(appDir, ".gitattributes"), "* filter=evil\n")
+
+	var scripts []string
+	old := runSnapshotScript
+	runSnapshotScript = func(ctx context.Context, sbox *sandtypes.Box, script string, args ...string) (string, error) {
+		if sbox.Name != "box" {
+			t.Errorf("script ran in sandbox %s, want box", sbox.Name)
+		}
+		scripts = append(scripts, script)
+		return "0123456789abcdef\n", nil
+	}
+	defer func() { runSnapshotScript = old }()
+
+	if _, err := runSnapshotCmd(t, cctx, &SnapshotCmd{SandboxNameFlag: SandboxNameFlag{SandboxName: "box"}, Snapshot: "s"}); err != nil {
+		t.Fatalf("SnapshotCmd.Run() error = %v", err)
+	}
+	if _, err := runSnapshotCmd(t, cctx, &RestoreCmd{SandboxNameFlag: SandboxNameFlag{SandboxName: "box"}, Snapshot: "s"}); err != nil {
+		t.Fatalf("RestoreCmd.Run() error = %v", err)
+	}
+	if len(scripts) != 2 || scripts[0] != snapshotScript || scripts[1] != restoreScript {
+		t.Fatalf("scripts run in the container = %q, want the snapshot and restore scripts", scripts)
+	}
+	if _, err := os.Stat(marker); !os.IsNotExist(err) {
+		t.Fatalf("the sandbox's git filter ran on the host: %v", err)
+	}
+}
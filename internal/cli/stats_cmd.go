@@ -2,17 +2,28 @@ package cli
 
 import (
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"strings"
 	"text/tabwriter"
+	"time"
+
+	"github.com/banksean/sand/internal/sandtypes"
 )
 
+var statsCmdStdout io.Writer = os.Stdout
+
 type StatsCmd struct {
 	MultiSandboxNameFlags
+	Watch    bool          `short:"w" help:"keep refreshing stats, showing CPU% since the previous sample"`
+	Interval time.Duration `default:"2s" placeholder:"<duration>" help:"how often to refresh stats with --watch"`
 }
 
 func (c *StatsCmd) Run(cctx *CLIContext) error {
+	if c.Watch && c.Interval <= 0 {
+		return fmt.Errorf("--interval %s: must be positive", c.Interval)
+	}
 	ctx := cctx.Context
 	mc := cctx.Daemon
 	names := []string{}
@@ -29,17 +40,45 @@ func (c *StatsCmd) Run(cctx *CLIContext) error {
 			}
 		}
 	}
-	list, err := mc.Stats(ctx, names...)
-	if err != nil {
-		slog.ErrorContext(ctx, "Stats", "error", err)
-		return err
-	}
 
-	if len(list) == 0 {
-		return nil
+	var prev map[string]sandtypes.ContainerStats
+	var prevAt time.Time
+	for {
+		list, err := mc.Stats(ctx, names...)
+		if err != nil {
+			slog.ErrorContext(ctx, "Stats", "error", err)
+			return err
+		}
+		now := time.Now()
+
+		if c.Watch && isTerminalWriter(statsCmdStdout) {
+			// Clear the screen so each refresh redraws the table in place.
+			fmt.Fprint(statsCmdStdout, "\033[H\033[2J")
+		}
+		if len(list) > 0 {
+			renderStatsTable(statsCmdStdout, list, prev, now.Sub(prevAt))
+		}
+		if !c.Watch {
+			return nil
+		}
+
+		prev = make(map[string]sandtypes.ContainerStats, len(list))
+		for _, s := range list {
+			prev[s.ID] = s
+		}
+		prevAt = now
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(c.Interval):
+		}
 	}
+}
 
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+// renderStatsTable writes one row per container. CPU% is computed against
+// prev, the previous sample taken elapsed ago, and is "-" without one.
+func renderStatsTable(out io.Writer, list []sandtypes.ContainerStats, prev map[string]sandtypes.ContainerStats, elapsed time.Duration) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
 	colHeadings := []string{
 		"SANDBOX NAME",
 		"CPU",
@@ -47,6 +86,8 @@ func (c *StatsCmd) Run(cctx *CLIContext) error {
 		"MEM",
 		"BLOCK R/W",
 		"NET TX/RX",
+		"CPU%",
+		"UPTIME",
 	}
 	fmt.Fprintln(w, strings.Join(colHeadings, "\t"))
 	for _, ctr := range list {
@@ -54,8 +95,36 @@ func (c *StatsCmd) Run(cctx *CLIContext) error {
 			ctr.ID,
 		}
 		row = append(row, formatStatsColumns(&ctr)...)
+		cpuPct := "-"
+		if p, ok := prev[ctr.ID]; ok {
+			if pct, ok := cpuPercent(p, ctr, elapsed); ok {
+				cpuPct = fmt.Sprintf("%.1f%%", pct)
+			}
+		}
+		row = append(row, cpuPct, formatUptime(ctr.UptimeSeconds))
 		fmt.Fprintf(w, "%s\n", strings.Join(row, "\t"))
 	}
 	w.Flush()
-	return nil
+}
+
+// cpuPercent is the CPU time cur used since prev as a percentage of elapsed
+// wall time. Like top, it can exceed 100% on containers with several CPUs.
+func cpuPercent(prev, cur sandtypes.ContainerStats, elapsed time.Duration) (float64, bool) {
+	if elapsed <= 0 || prev.CPUUsageUsec < 0 || cur.CPUUsageUsec < prev.CPUUsageUsec {
+		return 0, false
+	}
+	used := time.Duration(cur.CPUUsageUsec-prev.CPUUsageUsec) * time.Microsecond
+	return float64(used) / float64(elapsed) * 100, true
+}
+
+func formatUptime(seconds int) string {
+	if seconds <= 0 {
+		return "-"
+	}
+	return (time.Duration(seconds) * time.Second).String()
+}
+
+func isTerminalWriter(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && isTerminal(f)
 }
@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/banksean/sand/internal/sandtypes"
+)
+
+func TestCPUPercent(t *testing.T) {
+	prev := sandtypes.ContainerStats{CPUUsageUsec: 1_000_000}
+	cur := sandtypes.ContainerStats{CPUUsageUsec: 3_000_000}
+
+	got, ok := cpuPercent(prev, cur, 4*time.Second)
+	if !ok || got != 50 {
+		t.Fatalf("cpuPercent() = %v, %v; want 50, true", got, ok)
+	}
+	if _, ok := cpuPercent(prev, cur, 0); ok {
+		t.Error("cpuPercent() with zero elapsed ok = true, want false")
+	}
+	if _, ok := cpuPercent(cur, prev, time.Second); ok {
+		t.Error("cpuPercent() with counter reset ok = true, want false")
+	}
+	if _, ok := cpuPercent(sandtypes.ContainerStats{CPUUsageUsec: -1}, cur, time.Second); ok {
+		t.Error("cpuPercent() with unknown previous usage ok = true, want false")
+	}
+}
+
+func TestRenderStatsTable(t *testing.T) {
+	list := []sandtypes.ContainerStats{{
+		ID:               "alpha",
+		CPUUsageUsec:     2_500_000,
+		MemoryUsageBytes: 1024,
+		MemoryLimitBytes: 2048,
+		NumProcesses:     3,
+		BlockReadBytes:   -1,
+		BlockWriteBytes:  -1,
+		NetworkRxBytes:   -1,
+		NetworkTxBytes:   -1,
+		UptimeSeconds:    90,
+	}}
+	prev := map[string]sandtypes.ContainerStats{"alpha": {ID: "alpha", CPUUsageUsec: 2_000_000}}
+
+	var out bytes.Buffer
+	renderStatsTable(&out, list, nil, 0)
+	if !strings.Contains(out.String(), "CPU%") || !strings.Contains(out.String(), "1m30s") {
+		t.Fatalf("single sample table = %q, want CPU%% and UPTIME columns", out.String())
+	}
+
+	out.Reset()
+	renderStatsTable(&out, list, prev, time.Second)
+	if !strings.Contains(out.String(), "50.0%") {
+		t.Fatalf("watch table = %q, want 50.0%% CPU", out.String())
+	}
+}
+
+func TestStatsCmdWatchRejectsNonPositiveInterval(t *testing.T) {
+	for _, interval := range []time.Duration{0, -time.Second} {
+		err := (&StatsCmd{Watch: true, Interval: interval}).Run(&CLIContext{Context: context.Background()})
+		if err == nil || !strings.Contains(err.Error(), "--interval") {
+			t.Errorf("Run(--watch --interval %s) error = %v, want an --interval error", interval, err)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"sync"
@@ -8,51 +9,41 @@ import (
 
 type StopCmd struct {
 	MultiSandboxNameFlags
+	AllFilterFlag
 }
 
 func (c *StopCmd) Run(cctx *CLIContext) error {
 	ctx := cctx.Context
 	mc := cctx.Daemon
 
-	names := []string{}
-	if !c.All {
-		names = append(names, c.SandboxNames...)
-		if len(names) == 0 {
-			return fmt.Errorf("sandbox name required unless --all is set")
-		}
-	} else {
-		bxs, err := mc.ListSandboxes(ctx)
-		if err != nil {
-			return err
-		}
-		for _, bx := range bxs {
-			names = append(names, bx.Name)
-		}
+	bxs, err := selectSandboxes(ctx, mc, c.MultiSandboxNameFlags, c.AllFilterFlag)
+	if err != nil {
+		return err
 	}
 
 	var wg sync.WaitGroup
-	errChan := make(chan error, len(names))
+	errChan := make(chan error, len(bxs))
 
-	for _, name := range names {
+	for _, bx := range bxs {
 		wg.Add(1)
 		go func(name string) {
 			defer wg.Done()
 			if err := mc.StopSandbox(ctx, name); err != nil {
 				slog.ErrorContext(ctx, "StopSandbox", "error", err, "name", name)
-				errChan <- err
+				errChan <- fmt.Errorf("stop %s: %w", name, err)
 				return
 			}
 			fmt.Printf("%s\n", name)
-		}(name)
+		}(bx.Name)
 	}
 
 	wg.Wait()
 	close(errChan)
 
-	// Return the first error if any occurred
+	// Every sandbox was attempted; report all of the failures together.
+	var errs []error
 	for err := range errChan {
-		return err
+		errs = append(errs, err)
 	}
-
-	return nil
+	return errors.Join(errs...)
 }
@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/banksean/sand/internal/daemon/daemontest"
+	"github.com/banksean/sand/internal/hostops"
+)
+
+// recordingStops returns container ops that record the containers they are
+// asked to stop, in sorted order, and refuse to stop those in fail.
+func recordingStops(fail ...string) (*hostops.MockContainerOps, func() []string) {
+	var mu sync.Mutex
+	var stopped []string
+	containers := &hostops.MockContainerOps{
+		StopFunc: func(ctx context.Context, opts *hostops.StopContainer, containerID string) (string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			stopped = append(stopped, containerID)
+			if slices.Contains(fail, containerID) {
+				return "", errors.New("stop refused")
+			}
+			return "stopped", nil
+		},
+	}
+	return containers, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Sorted(slices.Values(stopped))
+	}
+}
+
+func TestStopCmd_AllAttemptsEverySandboxAndReportsFailures(t *testing.T) {
+	containers, stopped := recordingStops("ctr-bad", "ctr-worse")
+	client := daemontest.StartDaemon(t, daemontest.Deps{ContainerService: containers}, func(ctx context.Context, s daemontest.SandboxStore) {
+		for _, id := range []string{"bad", "good", "worse"} {
+			s.SaveSandbox(ctx, newTestBox(id))
+		}
+	})
+	cctx := &CLIContext{Context: context.Background(), Daemon: client}
+
+	err := (&StopCmd{MultiSandboxNameFlags: MultiSandboxNameFlags{All: true}}).Run(cctx)
+	if err == nil {
+		t.Fatal("Run() error = nil, want the failed stops")
+	}
+	for _, name := range []string{"bad", "worse"} {
+		if !strings.Contains(err.Error(), "stop "+name+":") {
+			t.Errorf("Run() error = %q, want it to report %s", err, name)
+		}
+	}
+	if strings.Contains(err.Error(), "good") {
+		t.Errorf("Run() error = %q, want no error for good", err)
+	}
+	if got, want := stopped(), []string{"ctr-bad", "ctr-good", "ctr-worse"}; !slices.Equal(got, want) {
+		t.Fatalf("stopped containers = %v, want %v", got, want)
+	}
+}
+
+func TestStopCmd_AllFilterStopsOnlyMatchingSandboxes(t *testing.T) {
+	containers, stopped := recordingStops()
+	client := daemontest.StartDaemon(t, daemontest.Deps{ContainerService: containers}, func(ctx context.Context, s daemontest.SandboxStore) {
+		for id, team := range map[string]string{"infra-one": "infra", "infra-two": "infra", "web": "web"} {
+			box := newTestBox(id)
+			box.Labels = map[string]string{"team": team}
+			s.SaveSandbox(ctx, box)
+		}
+	})
+	cctx := &CLIContext{Context: context.Background(), Daemon: client}
+
+	cmd := &StopCmd{MultiSandboxNameFlags: MultiSandboxNameFlags{All: true}, AllFilterFlag: AllFilterFlag{Filter: []string{"label=team=infra"}}}
+	if err := cmd.Run(cctx); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if got := stopped(); !slices.Equal(got, []string{"ctr-infra-one", "ctr-infra-two"}) {
+		t.Fatalf("stopped containers = %v, want only the infra sandboxes", got)
+	}
+}
+
+func TestStopCmd_FilterRequiresAll(t *testing.T) {
+	cctx := newTestCLIContext(t, func(ctx context.Context, s daemontest.SandboxStore) {
+		s.SaveSandbox(ctx, newTestBox("one"))
+	})
+	cmd := &StopCmd{MultiSandboxNameFlags: MultiSandboxNameFlags{SandboxNames: []string{"one"}}, AllFilterFlag: AllFilterFlag{Filter: []string{"label=team"}}}
+	if err := cmd.Run(cctx); err == nil || !strings.Contains(err.Error(), "--filter requires --all") {
+		t.Fatalf("Run() error = %v, want --filter requires --all", err)
+	}
+}
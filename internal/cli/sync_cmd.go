@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/banksean/sand/internal/sandtypes"
+)
+
+var syncCmdStdout io.Writer = os.Stdout
+
+type SyncSandboxesCmd struct{}
+
+func (c *SyncSandboxesCmd) Run(cctx *CLIContext) error {
+	ctx := cctx.Context
+	mc := cctx.Daemon
+
+	degraded, err := mc.SyncSandboxes(ctx)
+	if err != nil {
+		return fmt.Errorf("sync sandboxes: %w", err)
+	}
+	if len(degraded) == 0 {
+		fmt.Fprintln(syncCmdStdout, "All sandboxes are in sync.")
+		return nil
+	}
+	for _, sbox := range degraded {
+		name := sbox.Name
+		if name == "" {
+			name = sbox.ID
+		}
+		fmt.Fprintf(syncCmdStdout, "%s: %s\n", name, strings.Join(degradedReasons(sbox), "; "))
+	}
+	return fmt.Errorf("%d sandbox(es) degraded", len(degraded))
+}
+
+// degradedReasons explains the error states Sync recorded for sbox.
+func degradedReasons(sbox sandtypes.Box) []string {
+	var reasons []string
+	if sbox.SandboxWorkDirError != "" {
+		reasons = append(reasons, fmt.Sprintf("clone directory %s is missing (%s)", sbox.SandboxWorkDir, sbox.SandboxWorkDirError))
+	}
+	if sbox.SandboxContainerError != "" {
+		reasons = append(reasons, fmt.Sprintf("container %s could not be inspected (%s)", sbox.ContainerID, sbox.SandboxContainerError))
+	}
+	return reasons
+}
@@ -0,0 +1,36 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/banksean/sand/internal/daemon/daemontest"
+)
+
+func TestSyncSandboxesCmdReportsDegradedSandboxes(t *testing.T) {
+	healthy := newTestBox("healthy")
+	healthy.Name = "healthy"
+	healthy.SandboxWorkDir = t.TempDir()
+	cctx := newTestCLIContext(t, func(ctx context.Context, s daemontest.SandboxStore) {
+		s.SaveSandbox(ctx, healthy)
+		gone := newTestBox("gone")
+		gone.Name = "gone"
+		gone.SandboxWorkDir = "/nonexistent/gone"
+		s.SaveSandbox(ctx, gone)
+	})
+
+	var stdout bytes.Buffer
+	prev := syncCmdStdout
+	syncCmdStdout = &stdout
+	t.Cleanup(func() { syncCmdStdout = prev })
+
+	err := (&SyncSandboxesCmd{}).Run(cctx)
+	if err == nil || !strings.Contains(err.Error(), "1 sandbox(es) degraded") {
+		t.Fatalf("Run() error = %v, want one degraded sandbox", err)
+	}
+	if got, want := stdout.String(), "gone: clone directory /nonexistent/gone is missing (NO CLONE DIR)\n"; got != want {
+		t.Fatalf("stdout = %q, want %q", got, want)
+	}
+}
@@ -27,6 +27,10 @@ func (c *VscCmd) Run(cctx *CLIContext) error {
 		return fmt.Errorf("could not find sandbox named %s: %w", c.SandboxName, err)
 	}
 
+	if sbox.NoSSH {
+		return fmt.Errorf("sandbox %s was created with --no-ssh; VS Code connects over ssh", c.SandboxName)
+	}
+
 	ctr := sbox.Container
 
 	if ctr == nil || ctr.Status.State != "running" {
@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/banksean/sand/internal/sandtypes"
+)
+
+var watchCmdStdout io.Writer = os.Stdout
+
+type WatchCmd struct {
+	JSON bool `name:"json" help:"print one JSON object per event instead of text lines"`
+}
+
+func (c *WatchCmd) Run(cctx *CLIContext) error {
+	ctx := cctx.Context
+	events, err := cctx.Daemon.Watch(ctx)
+	if err != nil {
+		return fmt.Errorf("watch sandbox events: %w", err)
+	}
+	enc := json.NewEncoder(watchCmdStdout)
+	for event := range events {
+		if c.JSON {
+			if err := enc.Encode(event); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := fmt.Fprintln(watchCmdStdout, formatSandboxEvent(event)); err != nil {
+			return err
+		}
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	return fmt.Errorf("sandd closed the event stream")
+}
+
+func formatSandboxEvent(event sandtypes.SandboxEvent) string {
+	line := fmt.Sprintf("%s %-10s %s", event.Time.Local().Format(time.RFC3339), event.Type, event.SandboxName)
+	if event.Error != "" {
+		line += ": " + event.Error
+	}
+	return line
+}
@@ -0,0 +1,22 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/banksean/sand/internal/sandtypes"
+)
+
+func TestFormatSandboxEvent(t *testing.T) {
+	at := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	got := formatSandboxEvent(sandtypes.SandboxEvent{Type: sandtypes.SandboxStarted, SandboxName: "api", Time: at})
+	if want := at.Local().Format(time.RFC3339) + " started    api"; got != want {
+		t.Fatalf("formatSandboxEvent() = %q, want %q", got, want)
+	}
+
+	got = formatSandboxEvent(sandtypes.SandboxEvent{Type: sandtypes.SandboxSyncError, SandboxName: "api", Time: at, Error: "clone missing"})
+	if !strings.HasSuffix(got, "sync-error api: clone missing") {
+		t.Fatalf("formatSandboxEvent() = %q, want error suffix", got)
+	}
+}
@@ -4,15 +4,21 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/fs"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/banksean/sand/internal/hostops"
 	"github.com/banksean/sand/internal/sandtypes"
 )
 
+// ErrSandboxRootExists is returned by Prepare when the directory a sandbox's
+// clone would be made in is already there.
+var ErrSandboxRootExists = errors.New("clone directory already exists")
+
 // BaseWorkspacePreparation implements the default workspace preparation behavior.
 // It clones the host workspace directory, sets up dotfiles, and configures git remotes.
 type BaseWorkspacePreparation struct {
@@ -21,6 +27,16 @@ type BaseWorkspacePreparation struct {
 	gitSetup  *GitSetup
 	gitMirror *GitMirror
 	fileOps   hostops.FileOps
+
+	// homeDir returns the host user's home directory, which dotfile paths
+	// are relative to.
+	homeDir func() string
+	// freeSpace reports the space available on the volume holding a path.
+	freeSpace func(path string) (uint64, error)
+	// minFreeSpace is the free space required before cloning; 0 disables the check.
+	minFreeSpace uint64
+	// progressInterval is how often to report on a running workspace copy; 0 disables it.
+	progressInterval time.Duration
 }
 
 // NewBaseWorkspacePreparation creates a new base workspace preparation instance.
@@ -31,22 +47,42 @@ func NewBaseWorkspacePreparation(cloneRoot string, messenger hostops.UserMesseng
 		gitSetup:  NewGitSetup(gitOps),
 		gitMirror: NewGitMirror(DefaultGitMirrorRoot(cloneRoot), gitOps, fileOps),
 		fileOps:   fileOps,
+
+		homeDir:          hostops.HomeDir,
+		freeSpace:        availableSpace,
+		minFreeSpace:     minFreeSpaceFromEnv(),
+		progressInterval: DefaultCopyProgressInterval,
 	}
 }
 
 func (p *BaseWorkspacePreparation) Prepare(ctx context.Context, req CloneRequest) (*CloneArtifacts, error) {
 	slog.InfoContext(ctx, "BaseWorkspacePreparation.Prepare", "req", req)
 
-	sandboxRoot := filepath.Join(p.cloneRoot, req.ID)
+	cloneRoot := p.cloneRoot
+	if req.CloneRoot != "" {
+		cloneRoot = req.CloneRoot
+	}
+	sandboxRoot := filepath.Join(cloneRoot, req.ID)
 	pathRegistry := NewStandardPathRegistry(sandboxRoot)
 
-	// Create sandbox root directory
-	if err := p.fileOps.MkdirAll(sandboxRoot, 0o750); err != nil {
+	// Create sandbox root directory. It has to be a new one: a failed create
+	// removes it again, which must not take a directory that was already
+	// there with it.
+	if err := p.fileOps.MkdirAll(cloneRoot, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create clone directory for sandbox %s: %w", req.ID, err)
+	}
+	if err := p.fileOps.Mkdir(sandboxRoot, 0o750); errors.Is(err, fs.ErrExist) {
+		return nil, fmt.Errorf("failed to create clone directory for sandbox %s: %s: %w", req.ID, sandboxRoot, ErrSandboxRootExists)
+	} else if err != nil {
 		return nil, fmt.Errorf("failed to create clone directory for sandbox %s: %w", req.ID, err)
 	}
 
+	if err := p.checkFreeSpace(ctx, cloneRoot); err != nil {
+		return nil, fmt.Errorf("failed to clone workdir for sandbox %s: %w", req.ID, err)
+	}
+
 	// Clone workspace directory
-	hostWorkDir, hostGitMirrorDir, err := p.cloneWorkDir(ctx, req.ID, req.Name, req.HostWorkDir, pathRegistry)
+	hostWorkDir, hostGitMirrorDir, copyOnWrite, err := p.cloneWorkDir(ctx, cloneRoot, req.ID, req.Name, req.HostWorkDir, req.FromBranch, pathRegistry)
 	if err != nil {
 		return nil, fmt.Errorf("failed to clone workdir for sandbox %s: %w", req.ID, err)
 	}
@@ -56,13 +92,15 @@ func (p *BaseWorkspacePreparation) Prepare(ctx context.Context, req CloneRequest
 		return nil, fmt.Errorf("failed to create dotfiles directory for sandbox %s: %w", req.ID, err)
 	}
 
-	// Clone dotfiles
-	if err := p.cloneDotfiles(ctx, req, pathRegistry); err != nil {
-		return nil, fmt.Errorf("failed to clone dotfiles for sandbox %s: %w", req.ID, err)
-	}
+	if !req.NoDotfiles {
+		// Clone dotfiles
+		if err := p.cloneDotfiles(ctx, req, pathRegistry); err != nil {
+			return nil, fmt.Errorf("failed to clone dotfiles for sandbox %s: %w", req.ID, err)
+		}
 
-	if err := p.prepareGitConfig(ctx, req, pathRegistry); err != nil {
-		return nil, fmt.Errorf("failed to prepare git config for sandbox %s: %w", req.ID, err)
+		if err := p.prepareGitConfig(ctx, req, pathRegistry); err != nil {
+			return nil, fmt.Errorf("failed to prepare git config for sandbox %s: %w", req.ID, err)
+		}
 	}
 
 	return &CloneArtifacts{
@@ -73,11 +111,12 @@ func (p *BaseWorkspacePreparation) Prepare(ctx context.Context, req CloneRequest
 		Username:          req.Username,
 		Uid:               req.Uid,
 		SharedCacheMounts: req.SharedCacheMounts,
+		CopyOnWrite:       copyOnWrite,
 	}, nil
 }
 
-func (p *BaseWorkspacePreparation) cloneWorkDir(ctx context.Context, id, name, hostWorkDir string, pathRegistry PathRegistry) (string, string, error) {
-	p.messenger.Message(ctx, "Cloning "+hostWorkDir)
+func (p *BaseWorkspacePreparation) cloneWorkDir(ctx context.Context, cloneRoot, id, name, hostWorkDir, fromBranch string, pathRegistry PathRegistry) (string, string, bool, error) {
+	p.message(ctx, hostops.MessageProgress, "Cloning "+hostWorkDir)
 
 	// Check if hostWorkDir is part of a git repository
 	gitTopLevel := p.gitSetup.GetGitTopLevel(ctx, hostWorkDir)
@@ -87,6 +126,12 @@ func (p *BaseWorkspacePreparation) cloneWorkDir(ctx context.Context, id, name, h
 		// Clone from git top level instead
 		hostWorkDir = gitTopLevel
 	}
+	if fromBranch != "" {
+		// Check before the copy, which can take a while.
+		if err := p.gitSetup.ValidateBranch(ctx, gitTopLevel, fromBranch); err != nil {
+			return "", "", false, err
+		}
+	}
 
 	// Copy files from host to sandbox
 	hostCloneDir := pathRegistry.WorkDir()
@@ -94,45 +139,73 @@ func (p *BaseWorkspacePreparation) cloneWorkDir(ctx context.Context, id, name, h
 
 	workDirVol, err := p.fileOps.Volume(hostWorkDir)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to get volume info for work dir %s: %v", hostWorkDir, err)
+		return "", "", false, fmt.Errorf("failed to get volume info for work dir %s: %v", hostWorkDir, err)
 	}
 
-	cloneDirVol, err := p.fileOps.Volume(p.cloneRoot)
+	cloneDirVol, err := p.fileOps.Volume(cloneRoot)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to get volume info for clone root dir %s: %v", p.cloneRoot, err)
+		return "", "", false, fmt.Errorf("failed to get volume info for clone root dir %s: %v", cloneRoot, err)
 	}
 
-	if workDirVol.DeviceID != cloneDirVol.DeviceID {
-		return "", "", fmt.Errorf("can't clone dirs across volumes: workdir volume %s vs clone dir volume %s", workDirVol.MountPoint, cloneDirVol.MountPoint)
+	// clonefile(2) can't share blocks across volumes, so this clone will be a
+	// full copy even if cp doesn't report an error.
+	sameVolume := workDirVol.DeviceID == cloneDirVol.DeviceID
+	if !sameVolume {
+		slog.WarnContext(ctx, "BaseWorkspacePreparation.cloneWorkDir workdir and clone root are on different volumes; copy-on-write is unavailable",
+			"workDirVolume", workDirVol.MountPoint, "cloneDirVolume", cloneDirVol.MountPoint)
 	}
 
 	if gitTopLevel != "" {
 		var err error
 		hostGitMirrorDir, err = p.gitMirror.EnsureUpdated(ctx, gitTopLevel)
 		if err != nil {
-			return "", "", err
+			return "", "", false, err
 		}
 	}
 
-	if err := p.fileOps.Copy(ctx, hostWorkDir, hostCloneDir); err != nil {
-		return "", "", fmt.Errorf("failed to copy workdir %s to %s for sandbox %s: %w", hostWorkDir, hostCloneDir, id, err)
+	// Links within the workspace stay links, so the clone matches what git
+	// has recorded; absolute ones are made relative, since the host paths
+	// they name don't exist in the container. Links out of the workspace
+	// can't be followed there at all.
+	copyResult, err := p.copyWorkDir(ctx, hostWorkDir, hostCloneDir, hostops.CopyOpts{Symlinks: hostops.SymlinksWithinSource})
+	if err != nil {
+		return "", "", false, fmt.Errorf("failed to copy workdir %s to %s for sandbox %s: %w", hostWorkDir, hostCloneDir, id, err)
+	}
+	for _, link := range copyResult.SkippedSymlinks {
+		p.message(ctx, hostops.MessageInfo, fmt.Sprintf("Skipped symlink %s: it points outside %s", link, hostWorkDir))
+	}
+
+	// The copy still carries the host's changes; the branch checkout replaces
+	// them. Do it before setting up remotes so the checked-out branch is the
+	// one that gets an upstream.
+	if fromBranch != "" {
+		p.message(ctx, hostops.MessageProgress, "Checking out branch "+fromBranch)
+		if err := p.gitSetup.CheckoutBranch(ctx, hostCloneDir, fromBranch); err != nil {
+			return "", "", false, err
+		}
 	}
 
 	// Set up git remotes if this is a git repository
 	if gitTopLevel != "" {
 		if err := p.gitSetup.SetupGitRemotes(ctx, id, name, gitTopLevel, hostCloneDir, hostGitMirrorDir); err != nil {
-			return "", "", err
+			return "", "", false, err
 		}
 	}
 
-	return hostWorkDir, hostGitMirrorDir, nil
+	return hostWorkDir, hostGitMirrorDir, copyResult.CopyOnWrite && sameVolume, nil
+}
+
+// message tells the user msg, through the messenger ctx carries for this
+// clone if there is one, else p's own.
+func (p *BaseWorkspacePreparation) message(ctx context.Context, level hostops.MessageLevel, msg string) {
+	hostops.ContextMessenger(ctx, p.messenger).Message(ctx, level, msg)
 }
 
 func (p *BaseWorkspacePreparation) cloneDotfiles(ctx context.Context, req CloneRequest, pathRegistry PathRegistry) error {
-	p.messenger.Message(ctx, "Cloning dotfiles...")
+	p.message(ctx, hostops.MessageProgress, "Cloning dotfiles...")
 
 	for _, rule := range dotfileRules(req.Profile.Dotfiles) {
-		source, target, err := normalizeDotfileRule(req.HostWorkDir, rule)
+		source, target, err := normalizeDotfileRule(p.homeDir(), req.HostWorkDir, rule)
 		if err != nil {
 			return err
 		}
@@ -155,8 +228,8 @@ func dotfileRules(policy sandtypes.DotfilePolicy) []sandtypes.DotfileRule {
 	}
 }
 
-func normalizeDotfileRule(hostWorkDir string, rule sandtypes.DotfileRule) (string, string, error) {
-	source := expandHome(rule.Source)
+func normalizeDotfileRule(home, hostWorkDir string, rule sandtypes.DotfileRule) (string, string, error) {
+	source := expandHome(home, rule.Source)
 	if source == "" {
 		return "", "", fmt.Errorf("dotfile source is required")
 	}
@@ -169,8 +242,8 @@ func normalizeDotfileRule(hostWorkDir string, rule sandtypes.DotfileRule) (strin
 		target = filepath.Base(source)
 	}
 	target = strings.TrimPrefix(target, "~/")
+	target = strings.TrimPrefix(target, "$HOME/")
 	if filepath.IsAbs(target) {
-		home := os.Getenv("HOME")
 		rel, err := filepath.Rel(home, target)
 		if err != nil || strings.HasPrefix(rel, "..") || rel == "." {
 			return "", "", fmt.Errorf("dotfile target %q must be inside $HOME", rule.Target)
@@ -185,12 +258,12 @@ func normalizeDotfileRule(hostWorkDir string, rule sandtypes.DotfileRule) (strin
 	return source, target, nil
 }
 
-func expandHome(path string) string {
+func expandHome(home, path string) string {
 	if path == "~" {
-		return os.Getenv("HOME")
+		return home
 	}
 	if strings.HasPrefix(path, "~/") {
-		return filepath.Join(os.Getenv("HOME"), strings.TrimPrefix(path, "~/"))
+		return filepath.Join(home, strings.TrimPrefix(path, "~/"))
 	}
 	return path
 }
@@ -208,11 +281,11 @@ func (p *BaseWorkspacePreparation) cloneDotfileRule(ctx context.Context, id stri
 		return fmt.Errorf("failed to create dotfile directory %s for sandbox %s: %w", cloneDir, id, err)
 	}
 
-	if err := p.fileOps.Copy(ctx, original, clone); err != nil {
+	if _, err := p.fileOps.Copy(ctx, original, clone, hostops.CopyOpts{}); err != nil {
 		return fmt.Errorf("failed to copy dotfile %s for sandbox %s: %w", target, id, err)
 	}
 
-	p.messenger.Message(ctx, "cloned "+original)
+	p.message(ctx, hostops.MessageDebug, "cloned "+original)
 	return nil
 }
 
@@ -241,7 +314,7 @@ func (p *BaseWorkspacePreparation) resolveDotfileSource(ctx context.Context, sou
 		destination = filepath.Join(filepath.Dir(source), destination)
 	}
 	destination = filepath.Clean(destination)
-	if !rule.AllowOutsideHome && !pathInsideHome(destination) {
+	if !rule.AllowOutsideHome && !pathInsideHome(p.homeDir(), destination) {
 		return "", fmt.Errorf("symlink target %q is outside $HOME", destination)
 	}
 	if _, err := p.fileOps.Lstat(destination); err != nil {
@@ -252,8 +325,7 @@ func (p *BaseWorkspacePreparation) resolveDotfileSource(ctx context.Context, sou
 	return destination, nil
 }
 
-func pathInsideHome(path string) bool {
-	home := os.Getenv("HOME")
+func pathInsideHome(home, path string) bool {
 	rel, err := filepath.Rel(home, path)
 	return err == nil && !strings.HasPrefix(rel, "..")
 }
@@ -268,7 +340,7 @@ func (p *BaseWorkspacePreparation) prepareGitConfig(ctx context.Context, req Clo
 			Target:       "~/.gitconfig",
 			AllowSymlink: true,
 		}
-		source, target, err := normalizeDotfileRule(req.HostWorkDir, rule)
+		source, target, err := normalizeDotfileRule(p.homeDir(), req.HostWorkDir, rule)
 		if err != nil {
 			return err
 		}
@@ -281,11 +353,11 @@ func (p *BaseWorkspacePreparation) prepareGitConfig(ctx context.Context, req Clo
 }
 
 func (p *BaseWorkspacePreparation) writeSanitizedGitConfig(ctx context.Context, req CloneRequest, pathRegistry PathRegistry) error {
-	source := expandHome("~/.gitconfig")
+	source := expandHome(p.homeDir(), "~/.gitconfig")
 	target := filepath.Join(pathRegistry.DotfilesDir(), ".gitconfig")
 
 	if _, err := p.fileOps.Lstat(source); errors.Is(err, os.ErrNotExist) {
-		p.messenger.Message(ctx, "skipping "+source)
+		p.message(ctx, hostops.MessageDebug, "skipping "+source)
 		return p.fileOps.RemoveAll(target)
 	} else if err != nil {
 		return err
@@ -301,7 +373,7 @@ func (p *BaseWorkspacePreparation) writeSanitizedGitConfig(ctx context.Context,
 	if err := p.fileOps.WriteFile(target, sanitizeGitConfig(data), 0o640); err != nil {
 		return err
 	}
-	p.messenger.Message(ctx, "sanitized "+source)
+	p.message(ctx, hostops.MessageDebug, "sanitized "+source)
 	return nil
 }
 
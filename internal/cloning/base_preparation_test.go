@@ -1,9 +1,12 @@
 package cloning
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
@@ -16,7 +19,6 @@ func TestBaseWorkspacePreparationUsesSharedGitMirror(t *testing.T) {
 	hostWorkDir := t.TempDir()
 	cloneRoot := filepath.Join(t.TempDir(), "Sand", "clones")
 	home := t.TempDir()
-	t.Setenv("HOME", home)
 
 	var clonedMirror string
 	gitOps := &hostops.MockGitOps{
@@ -42,12 +44,13 @@ func TestBaseWorkspacePreparationUsesSharedGitMirror(t *testing.T) {
 		VolumeFunc: func(path string) (*hostops.VolumeInfo, error) {
 			return &hostops.VolumeInfo{DeviceID: 1, MountPoint: "/"}, nil
 		},
-		CopyFunc: func(ctx context.Context, src, dst string) error {
-			return os.MkdirAll(dst, 0o750)
+		CopyFunc: func(ctx context.Context, src, dst string, opts hostops.CopyOpts) (hostops.CopyResult, error) {
+			return hostops.CopyResult{CopyOnWrite: true}, os.MkdirAll(dst, 0o750)
 		},
 	}
 
 	prep := NewBaseWorkspacePreparation(cloneRoot, hostops.NewTerminalMessenger(nil), gitOps, fileOps)
+	prep.homeDir = func() string { return home }
 	artifacts, err := prep.Prepare(ctx, CloneRequest{ID: "sandbox-1", Name: "friendly", HostWorkDir: hostWorkDir})
 	if err != nil {
 		t.Fatalf("Prepare() error = %v", err)
@@ -71,12 +74,12 @@ func TestBaseWorkspacePreparationDefaultProfileDoesNotCopyZshrc(t *testing.T) {
 	hostWorkDir := t.TempDir()
 	cloneRoot := filepath.Join(t.TempDir(), "clones")
 	home := t.TempDir()
-	t.Setenv("HOME", home)
 	if err := os.WriteFile(filepath.Join(home, ".zshrc"), []byte("secret shell hook\n"), 0o644); err != nil {
 		t.Fatal(err)
 	}
 
 	prep := newDotfileTestPreparation(t, cloneRoot)
+	prep.homeDir = func() string { return home }
 	artifacts, err := prep.Prepare(ctx, CloneRequest{
 		ID:          "sandbox-default",
 		Name:        "sandbox-default",
@@ -97,12 +100,12 @@ func TestBaseWorkspacePreparationCopiesAllowlistedDotfiles(t *testing.T) {
 	hostWorkDir := t.TempDir()
 	cloneRoot := filepath.Join(t.TempDir(), "clones")
 	home := t.TempDir()
-	t.Setenv("HOME", home)
 	if err := os.WriteFile(filepath.Join(home, ".zshrc.sand"), []byte("sandbox shell\n"), 0o644); err != nil {
 		t.Fatal(err)
 	}
 
 	prep := newDotfileTestPreparation(t, cloneRoot)
+	prep.homeDir = func() string { return home }
 	artifacts, err := prep.Prepare(ctx, CloneRequest{
 		ID:          "sandbox-allowlist",
 		Name:        "sandbox-allowlist",
@@ -131,13 +134,90 @@ func TestBaseWorkspacePreparationCopiesAllowlistedDotfiles(t *testing.T) {
 	}
 }
 
+func TestBaseWorkspacePreparationMessagesEachDotfileOnlyWhenVerbose(t *testing.T) {
+	home := t.TempDir()
+	if err := os.WriteFile(filepath.Join(home, ".zshrc"), []byte("shell\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		level      hostops.MessageLevel
+		wantCloned bool
+	}{
+		{level: hostops.MessageProgress, wantCloned: false},
+		{level: hostops.MessageDebug, wantCloned: true},
+	} {
+		prep := newDotfileTestPreparation(t, filepath.Join(t.TempDir(), "clones"))
+		prep.homeDir = func() string { return home }
+		var out bytes.Buffer
+		ctx := hostops.WithMessenger(context.Background(), hostops.NewLeveledTerminalMessenger(&out, tc.level))
+		if _, err := prep.Prepare(ctx, CloneRequest{
+			ID:          "sandbox-messages",
+			Name:        "sandbox-messages",
+			HostWorkDir: t.TempDir(),
+			Profile: sandtypes.Profile{
+				Name: sandtypes.DefaultProfileName,
+				Dotfiles: sandtypes.DotfilePolicy{
+					Mode:  sandtypes.DotfileModeAllowlist,
+					Files: []sandtypes.DotfileRule{{Source: "~/.zshrc"}},
+				},
+			},
+		}); err != nil {
+			t.Fatalf("Prepare() error = %v", err)
+		}
+		if !strings.Contains(out.String(), "Cloning dotfiles...") {
+			t.Errorf("level %d: messages = %q, want the dotfile progress step", tc.level, out.String())
+		}
+		if got := strings.Contains(out.String(), "cloned "+filepath.Join(home, ".zshrc")); got != tc.wantCloned {
+			t.Errorf("level %d: messages = %q, want per-file message shown = %v", tc.level, out.String(), tc.wantCloned)
+		}
+	}
+}
+
+func TestBaseWorkspacePreparationSkipsDotfilesWhenDisabled(t *testing.T) {
+	ctx := context.Background()
+	hostWorkDir := t.TempDir()
+	cloneRoot := filepath.Join(t.TempDir(), "clones")
+	home := t.TempDir()
+	for _, name := range []string{".zshrc", ".gitconfig"} {
+		if err := os.WriteFile(filepath.Join(home, name), []byte("host\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	prep := newDotfileTestPreparation(t, cloneRoot)
+	prep.homeDir = func() string { return home }
+	artifacts, err := prep.Prepare(ctx, CloneRequest{
+		ID:          "sandbox-no-dotfiles",
+		Name:        "sandbox-no-dotfiles",
+		HostWorkDir: hostWorkDir,
+		NoDotfiles:  true,
+		Profile: sandtypes.Profile{
+			Name: sandtypes.DefaultProfileName,
+			Dotfiles: sandtypes.DotfilePolicy{
+				Mode:  sandtypes.DotfileModeAllowlist,
+				Files: []sandtypes.DotfileRule{{Source: "~/.zshrc"}},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Prepare() error = %v", err)
+	}
+
+	entries, err := os.ReadDir(artifacts.PathRegistry.DotfilesDir())
+	if err != nil {
+		t.Fatalf("ReadDir dotfiles dir: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("dotfiles dir holds %v, want nothing copied from the host", entries)
+	}
+}
+
 func TestBaseWorkspacePreparationRejectsSymlinkOutsideHomeByDefault(t *testing.T) {
 	ctx := context.Background()
 	hostWorkDir := t.TempDir()
 	cloneRoot := filepath.Join(t.TempDir(), "clones")
 	home := t.TempDir()
 	outside := t.TempDir()
-	t.Setenv("HOME", home)
 	outsideTarget := filepath.Join(outside, "gitconfig")
 	if err := os.WriteFile(outsideTarget, []byte("[credential]\n"), 0o644); err != nil {
 		t.Fatal(err)
@@ -147,6 +227,7 @@ func TestBaseWorkspacePreparationRejectsSymlinkOutsideHomeByDefault(t *testing.T
 	}
 
 	prep := newDotfileTestPreparation(t, cloneRoot)
+	prep.homeDir = func() string { return home }
 	_, err := prep.Prepare(ctx, CloneRequest{
 		ID:          "sandbox-symlink",
 		Name:        "sandbox-symlink",
@@ -176,7 +257,6 @@ func TestBaseWorkspacePreparationSanitizesGitConfig(t *testing.T) {
 	hostWorkDir := t.TempDir()
 	cloneRoot := filepath.Join(t.TempDir(), "clones")
 	home := t.TempDir()
-	t.Setenv("HOME", home)
 	if err := os.WriteFile(filepath.Join(home, ".gitconfig"), []byte(`[user]
 	name = Ada Lovelace
 	email = ada@example.com
@@ -199,6 +279,7 @@ func TestBaseWorkspacePreparationSanitizesGitConfig(t *testing.T) {
 	}
 
 	prep := newDotfileTestPreparation(t, cloneRoot)
+	prep.homeDir = func() string { return home }
 	artifacts, err := prep.Prepare(ctx, CloneRequest{
 		ID:          "sandbox-gitconfig",
 		Name:        "sandbox-gitconfig",
@@ -255,6 +336,7 @@ func newDotfileTestPreparation(t *testing.T, cloneRoot string) *BaseWorkspacePre
 	t.Helper()
 	fileOps := &hostops.MockFileOps{
 		MkdirAllFunc:  os.MkdirAll,
+		MkdirFunc:     os.Mkdir,
 		StatFunc:      os.Stat,
 		LstatFunc:     os.Lstat,
 		ReadlinkFunc:  os.Readlink,
@@ -264,23 +346,278 @@ func newDotfileTestPreparation(t *testing.T, cloneRoot string) *BaseWorkspacePre
 		VolumeFunc: func(path string) (*hostops.VolumeInfo, error) {
 			return &hostops.VolumeInfo{DeviceID: 1, MountPoint: "/"}, nil
 		},
-		CopyFunc: func(ctx context.Context, src, dst string) error {
+		CopyFunc: func(ctx context.Context, src, dst string, opts hostops.CopyOpts) (hostops.CopyResult, error) {
 			info, err := os.Stat(src)
 			if err != nil {
-				return err
+				return hostops.CopyResult{}, err
 			}
 			if info.IsDir() {
-				return os.MkdirAll(dst, 0o750)
+				return hostops.CopyResult{CopyOnWrite: true}, os.MkdirAll(dst, 0o750)
 			}
 			if err := os.MkdirAll(filepath.Dir(dst), 0o750); err != nil {
-				return err
+				return hostops.CopyResult{}, err
 			}
 			data, err := os.ReadFile(src)
 			if err != nil {
-				return err
+				return hostops.CopyResult{}, err
 			}
-			return os.WriteFile(dst, data, info.Mode())
+			return hostops.CopyResult{CopyOnWrite: true}, os.WriteFile(dst, data, info.Mode())
 		},
 	}
 	return NewBaseWorkspacePreparation(cloneRoot, hostops.NewTerminalMessenger(nil), &hostops.MockGitOps{}, fileOps)
 }
+
+func TestBaseWorkspacePreparationReportsCopyOnWrite(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		cloneRootDev int32
+		copyResult   hostops.CopyResult
+		want         bool
+	}{
+		{name: "cloned", cloneRootDev: 1, copyResult: hostops.CopyResult{CopyOnWrite: true}, want: true},
+		{name: "copy fell back", cloneRootDev: 1, copyResult: hostops.CopyResult{}, want: false},
+		{name: "across volumes", cloneRootDev: 2, copyResult: hostops.CopyResult{CopyOnWrite: true}, want: false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			hostWorkDir := t.TempDir()
+			cloneRoot := filepath.Join(t.TempDir(), "clones")
+			home := t.TempDir()
+			fileOps := &hostops.MockFileOps{
+				MkdirAllFunc: os.MkdirAll,
+				StatFunc:     os.Stat,
+				LstatFunc:    os.Lstat,
+				CreateFunc:   os.Create,
+				VolumeFunc: func(path string) (*hostops.VolumeInfo, error) {
+					if path == cloneRoot {
+						return &hostops.VolumeInfo{DeviceID: tc.cloneRootDev, MountPoint: "/Volumes/Other"}, nil
+					}
+					return &hostops.VolumeInfo{DeviceID: 1, MountPoint: "/"}, nil
+				},
+				CopyFunc: func(ctx context.Context, src, dst string, opts hostops.CopyOpts) (hostops.CopyResult, error) {
+					return tc.copyResult, os.MkdirAll(dst, 0o750)
+				},
+			}
+
+			prep := NewBaseWorkspacePreparation(cloneRoot, hostops.NewTerminalMessenger(nil), &hostops.MockGitOps{}, fileOps)
+			prep.homeDir = func() string { return home }
+			artifacts, err := prep.Prepare(context.Background(), CloneRequest{ID: "sandbox-1", Name: "friendly", HostWorkDir: hostWorkDir})
+			if err != nil {
+				t.Fatalf("Prepare() error = %v", err)
+			}
+			if artifacts.CopyOnWrite != tc.want {
+				t.Fatalf("CopyOnWrite = %v, want %v", artifacts.CopyOnWrite, tc.want)
+			}
+		})
+	}
+}
+
+func TestBaseWorkspacePreparationUsesRequestCloneRoot(t *testing.T) {
+	hostWorkDir := t.TempDir()
+	defaultRoot := filepath.Join(t.TempDir(), "clones")
+	customRoot := filepath.Join(t.TempDir(), "fast-clones")
+	home := t.TempDir()
+	fileOps := &hostops.MockFileOps{
+		MkdirAllFunc: os.MkdirAll,
+		StatFunc:     os.Stat,
+		LstatFunc:    os.Lstat,
+		CreateFunc:   os.Create,
+		VolumeFunc: func(path string) (*hostops.VolumeInfo, error) {
+			if path == customRoot {
+				return &hostops.VolumeInfo{DeviceID: 2, MountPoint: "/Volumes/Fast"}, nil
+			}
+			return &hostops.VolumeInfo{DeviceID: 1, MountPoint: "/"}, nil
+		},
+		CopyFunc: func(ctx context.Context, src, dst string, opts hostops.CopyOpts) (hostops.CopyResult, error) {
+			return hostops.CopyResult{CopyOnWrite: true}, os.MkdirAll(dst, 0o750)
+		},
+	}
+
+	prep := NewBaseWorkspacePreparation(defaultRoot, hostops.NewTerminalMessenger(nil), &hostops.MockGitOps{}, fileOps)
+	prep.homeDir = func() string { return home }
+	var freeSpacePaths []string
+	prep.minFreeSpace = 1
+	prep.freeSpace = func(path string) (uint64, error) {
+		freeSpacePaths = append(freeSpacePaths, path)
+		return 1 << 30, nil
+	}
+	artifacts, err := prep.Prepare(context.Background(), CloneRequest{ID: "sandbox-1", Name: "friendly", HostWorkDir: hostWorkDir, CloneRoot: customRoot})
+	if err != nil {
+		t.Fatalf("Prepare() error = %v", err)
+	}
+	if want := filepath.Join(customRoot, "sandbox-1"); artifacts.SandboxWorkDir != want {
+		t.Fatalf("SandboxWorkDir = %q, want %q", artifacts.SandboxWorkDir, want)
+	}
+	if _, err := os.Stat(artifacts.PathRegistry.WorkDir()); err != nil {
+		t.Fatalf("clone workdir: %v", err)
+	}
+	if _, err := os.Stat(defaultRoot); !os.IsNotExist(err) {
+		t.Fatalf("default clone root was created: err=%v", err)
+	}
+	if !slices.Equal(freeSpacePaths, []string{customRoot}) {
+		t.Fatalf("free space checked under %v, want [%s]", freeSpacePaths, customRoot)
+	}
+	if artifacts.CopyOnWrite {
+		t.Fatal("CopyOnWrite = true, want false for a clone root on another volume")
+	}
+}
+
+func TestBaseWorkspacePreparationCopiesWorkDirSymlinksWithinSource(t *testing.T) {
+	hostWorkDir := t.TempDir()
+	cloneRoot := filepath.Join(t.TempDir(), "clones")
+	home := t.TempDir()
+	var workDirPolicy hostops.SymlinkPolicy = -1
+	fileOps := &hostops.MockFileOps{
+		MkdirAllFunc: os.MkdirAll,
+		StatFunc:     os.Stat,
+		LstatFunc:    os.Lstat,
+		CreateFunc:   os.Create,
+		VolumeFunc: func(path string) (*hostops.VolumeInfo, error) {
+			return &hostops.VolumeInfo{DeviceID: 1, MountPoint: "/"}, nil
+		},
+		CopyFunc: func(ctx context.Context, src, dst string, opts hostops.CopyOpts) (hostops.CopyResult, error) {
+			if src != hostWorkDir {
+				return hostops.CopyResult{CopyOnWrite: true}, os.MkdirAll(dst, 0o750)
+			}
+			workDirPolicy = opts.Symlinks
+			return hostops.CopyResult{
+				CopyOnWrite:     true,
+				SkippedSymlinks: []string{filepath.Join(src, "external")},
+			}, os.MkdirAll(dst, 0o750)
+		},
+	}
+	var messages bytes.Buffer
+	prep := NewBaseWorkspacePreparation(cloneRoot, hostops.NewTerminalMessenger(&messages), &hostops.MockGitOps{}, fileOps)
+	prep.homeDir = func() string { return home }
+	if _, err := prep.Prepare(context.Background(), CloneRequest{ID: "sandbox-1", Name: "friendly", HostWorkDir: hostWorkDir}); err != nil {
+		t.Fatalf("Prepare() error = %v", err)
+	}
+	if workDirPolicy != hostops.SymlinksWithinSource {
+		t.Fatalf("workdir copy symlink policy = %v, want SymlinksWithinSource", workDirPolicy)
+	}
+	if want := "Skipped symlink " + filepath.Join(hostWorkDir, "external"); !strings.Contains(messages.String(), want) {
+		t.Fatalf("messages = %q, want %q", messages.String(), want)
+	}
+}
+
+func fromBranchPreparation(t *testing.T, gitOps *hostops.MockGitOps) (*BaseWorkspacePreparation, string, *int) {
+	t.Helper()
+	hostWorkDir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(hostWorkDir, ".git"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	home := t.TempDir()
+	gitOps.TopLevelFunc = func(ctx context.Context, dir string) string {
+		return hostWorkDir
+	}
+	var copies int
+	fileOps := &hostops.MockFileOps{
+		MkdirAllFunc: os.MkdirAll,
+		StatFunc:     os.Stat,
+		LstatFunc:    os.Lstat,
+		CreateFunc:   os.Create,
+		VolumeFunc: func(path string) (*hostops.VolumeInfo, error) {
+			return &hostops.VolumeInfo{DeviceID: 1, MountPoint: "/"}, nil
+		},
+		CopyFunc: func(ctx context.Context, src, dst string, opts hostops.CopyOpts) (hostops.CopyResult, error) {
+			copies++
+			return hostops.CopyResult{CopyOnWrite: true}, os.CopyFS(dst, os.DirFS(src))
+		},
+	}
+	prep := NewBaseWorkspacePreparation(filepath.Join(t.TempDir(), "clones"), hostops.NewTerminalMessenger(nil), gitOps, fileOps)
+	prep.homeDir = func() string { return home }
+	return prep, hostWorkDir, &copies
+}
+
+func TestBaseWorkspacePreparationChecksOutFromBranch(t *testing.T) {
+	var checkouts [][2]string
+	gitOps := &hostops.MockGitOps{
+		LocalBranchExistsFunc: func(ctx context.Context, dir, branch string) bool {
+			return branch == "release"
+		},
+		CheckoutCleanFunc: func(ctx context.Context, dir, branch string) error {
+			checkouts = append(checkouts, [2]string{dir, branch})
+			return nil
+		},
+	}
+	prep, hostWorkDir, _ := fromBranchPreparation(t, gitOps)
+
+	artifacts, err := prep.Prepare(context.Background(), CloneRequest{ID: "sandbox-1", HostWorkDir: hostWorkDir, FromBranch: "release"})
+	if err != nil {
+		t.Fatalf("Prepare() error = %v", err)
+	}
+	want := [2]string{artifacts.PathRegistry.WorkDir(), "release"}
+	if len(checkouts) != 1 || checkouts[0] != want {
+		t.Fatalf("CheckoutClean calls = %v, want [%v]", checkouts, want)
+	}
+}
+
+func TestBaseWorkspacePreparationRejectsUnknownFromBranch(t *testing.T) {
+	gitOps := &hostops.MockGitOps{
+		LocalBranchExistsFunc: func(ctx context.Context, dir, branch string) bool {
+			return false
+		},
+		CheckoutCleanFunc: func(ctx context.Context, dir, branch string) error {
+			t.Fatalf("CheckoutClean(%q, %q) called for an unknown branch", dir, branch)
+			return nil
+		},
+	}
+	prep, hostWorkDir, copies := fromBranchPreparation(t, gitOps)
+
+	_, err := prep.Prepare(context.Background(), CloneRequest{ID: "sandbox-1", HostWorkDir: hostWorkDir, FromBranch: "nope"})
+	if err == nil || !strings.Contains(err.Error(), "branch nope not found") {
+		t.Fatalf("Prepare() error = %v, want unknown branch error", err)
+	}
+	if *copies != 0 {
+		t.Fatalf("Copy calls = %d, want none for an unknown branch", *copies)
+	}
+}
+
+func TestNormalizeDotfileRuleResolvesAgainstGivenHome(t *testing.T) {
+	const home = "/Users/ada"
+	for _, tc := range []struct {
+		rule                   sandtypes.DotfileRule
+		wantSource, wantTarget string
+		wantErr                bool
+	}{
+		{rule: sandtypes.DotfileRule{Source: "~/.zshrc"}, wantSource: "/Users/ada/.zshrc", wantTarget: ".zshrc"},
+		{rule: sandtypes.DotfileRule{Source: "~/.zshrc.sand", Target: "~/.zshrc"}, wantSource: "/Users/ada/.zshrc.sand", wantTarget: ".zshrc"},
+		{rule: sandtypes.DotfileRule{Source: "/opt/cfg", Target: "/Users/ada/.config/cfg"}, wantSource: "/opt/cfg", wantTarget: ".config/cfg"},
+		{rule: sandtypes.DotfileRule{Source: "~/.config/nvim.sand", Target: "$HOME/.config/nvim"}, wantSource: "/Users/ada/.config/nvim.sand", wantTarget: ".config/nvim"},
+		{rule: sandtypes.DotfileRule{Source: "~/.zshrc", Target: "/etc/zshrc"}, wantErr: true},
+	} {
+		source, target, err := normalizeDotfileRule(home, "/work", tc.rule)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("normalizeDotfileRule(%+v) = %q, %q; want an error", tc.rule, source, target)
+			}
+			continue
+		}
+		if err != nil || source != tc.wantSource || target != tc.wantTarget {
+			t.Errorf("normalizeDotfileRule(%+v) = %q, %q, %v; want %q, %q", tc.rule, source, target, err, tc.wantSource, tc.wantTarget)
+		}
+	}
+	if !pathInsideHome(home, "/Users/ada/.gitconfig") || pathInsideHome(home, "/Users/bob/.gitconfig") {
+		t.Error("pathInsideHome doesn't follow the given home")
+	}
+}
+
+func TestBaseWorkspacePreparationRefusesExistingSandboxRoot(t *testing.T) {
+	cloneRoot := filepath.Join(t.TempDir(), "clones")
+	existing := filepath.Join(cloneRoot, "sandbox-1")
+	if err := os.MkdirAll(existing, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(existing, "keep.txt"), []byte("not sand's\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	prep := newDotfileTestPreparation(t, cloneRoot)
+	_, err := prep.Prepare(context.Background(), CloneRequest{ID: "sandbox-1", HostWorkDir: t.TempDir()})
+	if !errors.Is(err, ErrSandboxRootExists) {
+		t.Fatalf("Prepare() error = %v, want %v", err, ErrSandboxRootExists)
+	}
+	entries, err := os.ReadDir(existing)
+	if err != nil || len(entries) != 1 || entries[0].Name() != "keep.txt" {
+		t.Fatalf("existing directory now holds %v (error %v), want it untouched", entries, err)
+	}
+}
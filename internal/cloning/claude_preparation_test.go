@@ -29,13 +29,13 @@ func TestClaudeWorkspacePreparationWritesOnlyNonSecretStartupState(t *testing.T)
 	if err := os.WriteFile(filepath.Join(home, ".claude.json"), []byte(`{"secret":"host-state"}`), 0o600); err != nil {
 		t.Fatalf("WriteFile .claude.json: %v", err)
 	}
-	t.Setenv("HOME", home)
 
 	definition, ok := agentdefs.Lookup("claude")
 	if !ok {
 		t.Fatal("missing claude definition")
 	}
 	prep := NewDefinitionWorkspacePreparation(definition, cloneRoot, hostops.NewNullMessenger(), &hostops.MockGitOps{}, newPreparationTestFileOps(t))
+	prep.base.homeDir = func() string { return home }
 	artifacts, err := prep.Prepare(context.Background(), CloneRequest{
 		ID:          "box",
 		HostWorkDir: work,
@@ -29,13 +29,13 @@ func TestCodexWorkspacePreparationWritesTelemetryConfigOnly(t *testing.T) {
 	if err := os.WriteFile(filepath.Join(home, ".codex", "config.toml"), []byte("model = \"host-state\"\n"), 0o600); err != nil {
 		t.Fatalf("WriteFile config.toml: %v", err)
 	}
-	t.Setenv("HOME", home)
 
 	definition, ok := agentdefs.Lookup("codex")
 	if !ok {
 		t.Fatal("missing codex definition")
 	}
 	prep := NewDefinitionWorkspacePreparation(definition, cloneRoot, hostops.NewNullMessenger(), &hostops.MockGitOps{}, newPreparationTestFileOps(t))
+	prep.base.homeDir = func() string { return home }
 	artifacts, err := prep.Prepare(context.Background(), CloneRequest{
 		ID:          "box",
 		HostWorkDir: work,
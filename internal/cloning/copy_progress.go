@@ -0,0 +1,66 @@
+package cloning
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/banksean/sand/internal/hostops"
+)
+
+// DefaultCopyProgressInterval is how often a running workspace copy reports
+// which entry it is on.
+const DefaultCopyProgressInterval = 2 * time.Second
+
+// copyWorkDir copies src to dst, messaging the user every progressInterval
+// with the top-level entry being copied so a large clone doesn't look hung.
+func (p *BaseWorkspacePreparation) copyWorkDir(ctx context.Context, src, dst string, opts hostops.CopyOpts) (hostops.CopyResult, error) {
+	if p.progressInterval <= 0 {
+		return p.fileOps.Copy(ctx, src, dst, opts)
+	}
+	var mu sync.Mutex
+	var current hostops.CopyProgress
+	var entryStart time.Time
+	copyCtx := hostops.WithCopyProgress(ctx, func(progress hostops.CopyProgress) {
+		mu.Lock()
+		defer mu.Unlock()
+		current = progress
+		if !progress.Finished {
+			entryStart = time.Now()
+		}
+	})
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(p.progressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				mu.Lock()
+				progress, elapsed := current, time.Since(entryStart)
+				mu.Unlock()
+				if progress.Total == 0 || progress.Finished {
+					continue
+				}
+				p.message(ctx, hostops.MessageProgress, fmt.Sprintf("Cloning workspace: %s (%d of %d, %s)...",
+					progress.Entry, progress.Done+1, progress.Total, elapsed.Round(time.Second)))
+			}
+		}
+	}()
+
+	start := time.Now()
+	result, err := p.fileOps.Copy(copyCtx, src, dst, opts)
+	close(done)
+	wg.Wait()
+	if elapsed := time.Since(start); err == nil && elapsed >= p.progressInterval {
+		p.message(ctx, hostops.MessageProgress, fmt.Sprintf("Cloned workspace in %s", elapsed.Round(100*time.Millisecond)))
+	}
+	return result, err
+}
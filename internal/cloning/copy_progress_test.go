@@ -0,0 +1,78 @@
+package cloning
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/banksean/sand/internal/hostops"
+)
+
+type recordingMessenger struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func (m *recordingMessenger) Message(ctx context.Context, level hostops.MessageLevel, msg string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, msg)
+}
+
+func TestCopyWorkDirMessagesEntryInProgress(t *testing.T) {
+	messenger := &recordingMessenger{}
+	fileOps := &hostops.MockFileOps{
+		CopyFunc: func(ctx context.Context, src, dst string, opts hostops.CopyOpts) (hostops.CopyResult, error) {
+			report := hostops.CopyProgressReporter(ctx)
+			if report == nil {
+				t.Fatal("Copy called without a progress reporter")
+			}
+			for i, entry := range []string{"README.md", "node_modules", "src"} {
+				report(hostops.CopyProgress{Entry: entry, Done: i, Total: 3})
+				if entry == "node_modules" {
+					time.Sleep(100 * time.Millisecond)
+				}
+				report(hostops.CopyProgress{Entry: entry, Done: i + 1, Total: 3, Finished: true})
+			}
+			return hostops.CopyResult{CopyOnWrite: true}, nil
+		},
+	}
+	prep := NewBaseWorkspacePreparation(t.TempDir(), messenger, &hostops.MockGitOps{}, fileOps)
+	prep.progressInterval = 20 * time.Millisecond
+
+	result, err := prep.copyWorkDir(context.Background(), "/src", "/dst", hostops.CopyOpts{})
+	if err != nil || !result.CopyOnWrite {
+		t.Fatalf("copyWorkDir() = %+v, %v", result, err)
+	}
+
+	messenger.mu.Lock()
+	defer messenger.mu.Unlock()
+	all := strings.Join(messenger.messages, "\n")
+	if !strings.Contains(all, "Cloning workspace: node_modules (2 of 3") {
+		t.Errorf("messages = %q, want progress for node_modules", messenger.messages)
+	}
+	if strings.Contains(all, "README.md") || strings.Contains(all, "Cloning workspace: src") {
+		t.Errorf("messages = %q, want no progress for entries that copied between ticks", messenger.messages)
+	}
+	if last := messenger.messages[len(messenger.messages)-1]; !strings.HasPrefix(last, "Cloned workspace in ") {
+		t.Errorf("last message = %q, want the total copy time", last)
+	}
+}
+
+func TestCopyWorkDirQuietForFastCopies(t *testing.T) {
+	messenger := &recordingMessenger{}
+	fileOps := &hostops.MockFileOps{
+		CopyFunc: func(ctx context.Context, src, dst string, opts hostops.CopyOpts) (hostops.CopyResult, error) {
+			return hostops.CopyResult{}, nil
+		},
+	}
+	prep := NewBaseWorkspacePreparation(t.TempDir(), messenger, &hostops.MockGitOps{}, fileOps)
+	if _, err := prep.copyWorkDir(context.Background(), "/src", "/dst", hostops.CopyOpts{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(messenger.messages) != 0 {
+		t.Errorf("messages = %q, want none for a quick copy", messenger.messages)
+	}
+}
@@ -30,6 +30,10 @@ func (p *DefinitionWorkspacePreparation) Prepare(ctx context.Context, req CloneR
 	if err != nil {
 		return nil, err
 	}
+	if req.NoDotfiles {
+		// Generated files travel with the dotfiles, which won't be mounted.
+		return artifacts, nil
+	}
 	if err := p.writeGeneratedFiles(artifacts.PathRegistry); err != nil {
 		return nil, err
 	}
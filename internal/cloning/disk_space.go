@@ -0,0 +1,74 @@
+package cloning
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+)
+
+const (
+	// DefaultMinFreeSpace is how much free space the clone root's volume must
+	// have before a workspace is cloned onto it.
+	DefaultMinFreeSpace uint64 = 1 << 30
+
+	// minFreeSpaceEnv overrides DefaultMinFreeSpace, in MiB. 0 disables the check.
+	minFreeSpaceEnv = "SAND_MIN_FREE_SPACE_MB"
+
+	mib = 1 << 20
+)
+
+// minFreeSpaceFromEnv returns the free space threshold, honoring minFreeSpaceEnv.
+func minFreeSpaceFromEnv() uint64 {
+	v, ok := os.LookupEnv(minFreeSpaceEnv)
+	if !ok || v == "" {
+		return DefaultMinFreeSpace
+	}
+	n, err := strconv.ParseUint(v, 10, 64)
+	if err != nil {
+		slog.Warn("ignoring invalid "+minFreeSpaceEnv, "value", v, "error", err)
+		return DefaultMinFreeSpace
+	}
+	return n * mib
+}
+
+// availableSpace returns the bytes available to unprivileged users on the
+// volume holding path. path need not exist yet; its nearest existing
+// ancestor is checked instead.
+func availableSpace(path string) (uint64, error) {
+	for {
+		var fs syscall.Statfs_t
+		err := syscall.Statfs(path, &fs)
+		if err == nil {
+			return uint64(fs.Bavail) * uint64(fs.Bsize), nil
+		}
+		parent := filepath.Dir(path)
+		if !errors.Is(err, syscall.ENOENT) || parent == path {
+			return 0, fmt.Errorf("statfs %s: %w", path, err)
+		}
+		path = parent
+	}
+}
+
+// checkFreeSpace fails before anything is copied if the clone root's volume
+// is too full to hold another clone, rather than letting the copy fail midway.
+func (p *BaseWorkspacePreparation) checkFreeSpace(ctx context.Context, cloneRoot string) error {
+	if p.minFreeSpace == 0 {
+		return nil
+	}
+	avail, err := p.freeSpace(cloneRoot)
+	if err != nil {
+		// Not knowing is no reason to refuse; the copy reports a real shortage.
+		slog.WarnContext(ctx, "BaseWorkspacePreparation.checkFreeSpace", "cloneRoot", cloneRoot, "error", err)
+		return nil
+	}
+	if avail < p.minFreeSpace {
+		return fmt.Errorf("not enough space to clone the workspace: %d MiB free under %s, need at least %d MiB (set %s to change the threshold, 0 to disable)",
+			avail/mib, cloneRoot, p.minFreeSpace/mib, minFreeSpaceEnv)
+	}
+	return nil
+}
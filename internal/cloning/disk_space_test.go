@@ -0,0 +1,107 @@
+package cloning
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/banksean/sand/internal/hostops"
+)
+
+func newDiskSpaceTestPreparation(t *testing.T, cloneRoot string, avail uint64, copies *int, mirrors *int) *BaseWorkspacePreparation {
+	t.Helper()
+	gitOps := &hostops.MockGitOps{
+		TopLevelFunc: func(ctx context.Context, dir string) string { return dir },
+		CloneMirrorFunc: func(ctx context.Context, sourceDir, mirrorDir string) error {
+			*mirrors++
+			return os.MkdirAll(mirrorDir, 0o750)
+		},
+	}
+	fileOps := &hostops.MockFileOps{
+		MkdirAllFunc: os.MkdirAll,
+		StatFunc:     os.Stat,
+		LstatFunc:    os.Lstat,
+		VolumeFunc: func(path string) (*hostops.VolumeInfo, error) {
+			return &hostops.VolumeInfo{DeviceID: 1, MountPoint: "/"}, nil
+		},
+		CopyFunc: func(ctx context.Context, src, dst string, opts hostops.CopyOpts) (hostops.CopyResult, error) {
+			*copies++
+			return hostops.CopyResult{}, os.MkdirAll(dst, 0o750)
+		},
+	}
+	prep := NewBaseWorkspacePreparation(cloneRoot, hostops.NewTerminalMessenger(nil), gitOps, fileOps)
+	prep.minFreeSpace = 1 << 30
+	prep.freeSpace = func(path string) (uint64, error) {
+		if path != cloneRoot {
+			t.Errorf("freeSpace(%q), want clone root %q", path, cloneRoot)
+		}
+		return avail, nil
+	}
+	return prep
+}
+
+func TestBaseWorkspacePreparationAbortsWhenSpaceIsShort(t *testing.T) {
+	home := t.TempDir()
+	cloneRoot := filepath.Join(t.TempDir(), "clones")
+	var copies, mirrors int
+	prep := newDiskSpaceTestPreparation(t, cloneRoot, 100<<20, &copies, &mirrors)
+	prep.homeDir = func() string { return home }
+
+	_, err := prep.Prepare(context.Background(), CloneRequest{ID: "full", Name: "full", HostWorkDir: t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "not enough space") {
+		t.Fatalf("Prepare() error = %v, want not enough space", err)
+	}
+	if !strings.Contains(err.Error(), "100 MiB free") || !strings.Contains(err.Error(), minFreeSpaceEnv) {
+		t.Fatalf("Prepare() error = %q, want the free space and override hint", err)
+	}
+	if copies != 0 || mirrors != 0 {
+		t.Fatalf("Prepare() copied %d times and mirrored %d times, want neither before the space check", copies, mirrors)
+	}
+	if _, err := os.Stat(filepath.Join(cloneRoot, "full")); !os.IsNotExist(err) {
+		t.Fatalf("sandbox root stat error = %v, want it never created", err)
+	}
+}
+
+func TestBaseWorkspacePreparationProceedsWithEnoughSpace(t *testing.T) {
+	home := t.TempDir()
+	cloneRoot := filepath.Join(t.TempDir(), "clones")
+	var copies, mirrors int
+	prep := newDiskSpaceTestPreparation(t, cloneRoot, 2<<30, &copies, &mirrors)
+	prep.homeDir = func() string { return home }
+
+	if _, err := prep.Prepare(context.Background(), CloneRequest{ID: "roomy", Name: "roomy", HostWorkDir: t.TempDir()}); err != nil {
+		t.Fatalf("Prepare() error = %v", err)
+	}
+	if copies == 0 || mirrors != 1 {
+		t.Fatalf("Prepare() copied %d times and mirrored %d times, want the workspace cloned", copies, mirrors)
+	}
+}
+
+func TestMinFreeSpaceFromEnv(t *testing.T) {
+	for _, tc := range []struct {
+		value string
+		want  uint64
+	}{
+		{value: "", want: DefaultMinFreeSpace},
+		{value: "512", want: 512 << 20},
+		{value: "0", want: 0},
+		{value: "lots", want: DefaultMinFreeSpace},
+	} {
+		t.Setenv(minFreeSpaceEnv, tc.value)
+		if got := minFreeSpaceFromEnv(); got != tc.want {
+			t.Errorf("minFreeSpaceFromEnv() with %q = %d, want %d", tc.value, got, tc.want)
+		}
+	}
+}
+
+func TestAvailableSpaceChecksNearestExistingDir(t *testing.T) {
+	avail, err := availableSpace(filepath.Join(t.TempDir(), "not", "yet", "created"))
+	if err != nil {
+		t.Fatalf("availableSpace() error = %v", err)
+	}
+	if avail == 0 {
+		t.Fatal("availableSpace() = 0, want the temp dir volume's free space")
+	}
+}
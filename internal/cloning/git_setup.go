@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"os"
+	"path/filepath"
 
 	"github.com/banksean/sand/internal/hostops"
 )
@@ -87,6 +89,34 @@ func (g *GitSetup) SetupGitRemotes(ctx context.Context, sandboxID, sandboxName,
 	return nil
 }
 
+// ValidateBranch returns an error unless branch is a local branch of the git
+// repository gitTopLevel. An empty gitTopLevel means the directory being
+// cloned isn't a git repository, so it has no branches.
+func (g *GitSetup) ValidateBranch(ctx context.Context, gitTopLevel, branch string) error {
+	if gitTopLevel == "" {
+		return fmt.Errorf("cannot check out branch %s: the workspace is not a git repository", branch)
+	}
+	if !g.gitOps.LocalBranchExists(ctx, gitTopLevel, branch) {
+		return fmt.Errorf("branch %s not found in %s", branch, gitTopLevel)
+	}
+	return nil
+}
+
+// CheckoutBranch replaces the working tree of cloneDir with a clean checkout
+// of branch. cloneDir must have its own .git directory; a copied .git file,
+// as in a linked worktree or submodule, points back into the host repository,
+// which the checkout would change.
+func (g *GitSetup) CheckoutBranch(ctx context.Context, cloneDir, branch string) error {
+	slog.InfoContext(ctx, "GitSetup.CheckoutBranch", "cloneDir", cloneDir, "branch", branch)
+	if fi, err := os.Stat(filepath.Join(cloneDir, ".git")); err != nil || !fi.IsDir() {
+		return fmt.Errorf("cannot check out branch %s in %s: it has no .git directory of its own (linked worktrees and submodules are not supported)", branch, cloneDir)
+	}
+	if err := g.gitOps.CheckoutClean(ctx, cloneDir, branch); err != nil {
+		return fmt.Errorf("failed to check out branch %s in %s: %w", branch, cloneDir, err)
+	}
+	return nil
+}
+
 // GetGitTopLevel returns the top-level directory of the git repository containing the given directory.
 // Returns empty string if the directory is not part of a git repository.
 func (g *GitSetup) GetGitTopLevel(ctx context.Context, dir string) string {
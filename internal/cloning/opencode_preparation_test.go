@@ -31,13 +31,13 @@ func TestOpenCodeWorkspacePreparationDoesNotCopyHostAuthState(t *testing.T) {
 	if err := os.WriteFile(filepath.Join(hostOpenCode, "storage", "state.json"), []byte(`{"secret":"state"}`), 0o600); err != nil {
 		t.Fatalf("WriteFile storage: %v", err)
 	}
-	t.Setenv("HOME", home)
 
 	definition, ok := agentdefs.Lookup("opencode")
 	if !ok {
 		t.Fatal("missing opencode definition")
 	}
 	prep := NewDefinitionWorkspacePreparation(definition, cloneRoot, hostops.NewNullMessenger(), &hostops.MockGitOps{}, newPreparationTestFileOps(t))
+	prep.base.homeDir = func() string { return home }
 	artifacts, err := prep.Prepare(context.Background(), CloneRequest{
 		ID:          "box",
 		HostWorkDir: work,
@@ -69,8 +69,8 @@ func newPreparationTestFileOps(t *testing.T) hostops.FileOps {
 	t.Helper()
 	return &hostops.MockFileOps{
 		MkdirAllFunc: os.MkdirAll,
-		CopyFunc: func(ctx context.Context, src, dst string) error {
-			return copyPathForTest(src, dst)
+		CopyFunc: func(ctx context.Context, src, dst string, opts hostops.CopyOpts) (hostops.CopyResult, error) {
+			return hostops.CopyResult{CopyOnWrite: true}, copyPathForTest(src, dst)
 		},
 		LstatFunc:  os.Lstat,
 		CreateFunc: os.Create,
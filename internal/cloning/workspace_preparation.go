@@ -25,6 +25,15 @@ type CloneRequest struct {
 	Username          string
 	Uid               string
 	SharedCacheMounts sandtypes.SharedCacheMounts
+	// CloneRoot, if set, is the directory to create this sandbox's clone in
+	// instead of the preparation's default clone root.
+	CloneRoot string
+	// FromBranch, if set, is a local branch of HostWorkDir's repository to
+	// check out cleanly in the clone in place of the copied working tree.
+	FromBranch string
+	// NoDotfiles skips copying the host user's dotfiles and git config, and
+	// any files the agent definition generates alongside them.
+	NoDotfiles bool
 }
 
 // CloneArtifacts describes the file system artifacts created during workspace preparation.
@@ -41,4 +50,7 @@ type CloneArtifacts struct {
 	Uid      string
 	// SharedCacheMounts carries host-managed caches that should be mounted into the container.
 	SharedCacheMounts sandtypes.SharedCacheMounts
+	// CopyOnWrite is true if the workdir clone shares storage with the host workdir.
+	// It is false when the clone fell back to a full copy and uses extra disk space.
+	CopyOnWrite bool
 }
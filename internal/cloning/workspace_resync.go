@@ -0,0 +1,248 @@
+package cloning
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/banksean/sand/internal/sandtypes"
+)
+
+// SandIgnoreFile names the file at the root of a host workspace that lists
+// paths a resync should leave alone, one glob pattern per line.
+const SandIgnoreFile = ".sandignore"
+
+// resyncStampFile records, in the sandbox root, when the clone was last
+// resynced from the host.
+const resyncStampFile = "resync-stamp"
+
+// WorkspaceResync incrementally copies host workspace changes into an
+// existing sandbox clone.
+//
+// A host file is considered changed if it was modified after the last sync
+// and differs from the clone's copy in size or mtime. A clone file modified
+// after the last sync is a local change made inside the sandbox; when both
+// sides changed, the clone's copy wins and the path is reported as a conflict.
+// Files are never deleted from the clone, and .git is never touched.
+type WorkspaceResync struct {
+	pathRegistry PathRegistry
+	// now is the clock used to stamp syncs; tests replace it.
+	now func() time.Time
+}
+
+// NewWorkspaceResync creates a WorkspaceResync for the sandbox rooted at sandboxRoot.
+func NewWorkspaceResync(sandboxRoot string) *WorkspaceResync {
+	return &WorkspaceResync{
+		pathRegistry: NewStandardPathRegistry(sandboxRoot),
+		now:          time.Now,
+	}
+}
+
+// Resync copies changed files from hostDir into the clone. createdAt is used
+// as the last sync time if the clone has never been resynced.
+func (r *WorkspaceResync) Resync(ctx context.Context, hostDir string, createdAt time.Time) (*sandtypes.ResyncResult, error) {
+	cloneDir := r.pathRegistry.WorkDir()
+	stampPath := filepath.Join(r.pathRegistry.SandboxRoot(), resyncStampFile)
+	since, err := readResyncStamp(stampPath, createdAt)
+	if err != nil {
+		return nil, err
+	}
+	ignore, err := loadSandIgnore(hostDir)
+	if err != nil {
+		return nil, err
+	}
+	// Everything the sandbox can write is reached through clone, so a
+	// symlink it made in the clone can't send a write outside it.
+	clone, err := os.OpenRoot(cloneDir)
+	if err != nil {
+		return nil, fmt.Errorf("open sandbox clone: %w", err)
+	}
+	defer clone.Close()
+	started := r.now()
+	slog.InfoContext(ctx, "WorkspaceResync.Resync", "hostDir", hostDir, "cloneDir", cloneDir, "since", since)
+
+	result := &sandtypes.ResyncResult{}
+	err = filepath.WalkDir(hostDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(hostDir, path)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		if d.Name() == ".git" || ignore.matches(rel, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		hostInfo, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if !hostInfo.ModTime().After(since) {
+			return nil
+		}
+
+		cloneInfo, err := clone.Lstat(rel)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+		case err != nil:
+			return err
+		case sameFileMetadata(hostInfo, cloneInfo):
+			return nil
+		case !cloneInfo.Mode().IsRegular() || cloneInfo.ModTime().After(since):
+			slog.WarnContext(ctx, "WorkspaceResync.Resync skipping file changed in sandbox", "path", rel)
+			result.Conflicts = append(result.Conflicts, rel)
+			return nil
+		}
+		if err := copyResyncFile(clone, path, rel, hostInfo); err != nil {
+			return fmt.Errorf("copy %s into sandbox clone: %w", rel, err)
+		}
+		result.Copied = append(result.Copied, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("resync %s to %s: %w", hostDir, cloneDir, err)
+	}
+
+	if err := os.WriteFile(stampPath, []byte(started.UTC().Format(time.RFC3339Nano)+"\n"), 0o644); err != nil {
+		return nil, fmt.Errorf("record resync time: %w", err)
+	}
+	return result, nil
+}
+
+func sameFileMetadata(a, b fs.FileInfo) bool {
+	return a.Size() == b.Size() && a.ModTime().Equal(b.ModTime())
+}
+
+func readResyncStamp(path string, fallback time.Time) (time.Time, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fallback, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("read resync stamp: %w", err)
+	}
+	t, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(string(data)))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parse resync stamp %s: %w", path, err)
+	}
+	return t, nil
+}
+
+// copyResyncFile replaces dst, a path in clone, with the contents of src,
+// keeping src's mode and mtime so the next resync sees the two as identical.
+// dst's parent directories are made and resolved inside clone, so the
+// sandbox's symlinks can't lead the copy out of it.
+func copyResyncFile(clone *os.Root, src, dst string, info fs.FileInfo) error {
+	if err := clone.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmpName := filepath.Join(filepath.Dir(dst), ".sand-resync-"+rand.Text())
+	tmp, err := clone.OpenFile(tmpName, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	defer clone.Remove(tmpName)
+	if _, err := io.Copy(tmp, in); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(info.Mode().Perm()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := clone.Chtimes(tmpName, info.ModTime(), info.ModTime()); err != nil {
+		return err
+	}
+	return clone.Rename(tmpName, dst)
+}
+
+// sandIgnore holds the patterns from a workspace's .sandignore. A pattern
+// matches a workspace-relative path or just its base name; a trailing
+// slash restricts it to directories, and a leading slash anchors it to the
+// workspace root.
+type sandIgnore struct {
+	patterns []string
+}
+
+func loadSandIgnore(hostDir string) (*sandIgnore, error) {
+	f, err := os.Open(filepath.Join(hostDir, SandIgnoreFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return &sandIgnore{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", SandIgnoreFile, err)
+	}
+	defer f.Close()
+
+	ignore := &sandIgnore{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if _, err := filepath.Match(strings.Trim(line, "/"), ""); err != nil {
+			return nil, fmt.Errorf("%s: bad pattern %q: %w", SandIgnoreFile, line, err)
+		}
+		ignore.patterns = append(ignore.patterns, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read %s: %w", SandIgnoreFile, err)
+	}
+	return ignore, nil
+}
+
+func (s *sandIgnore) matches(rel string, isDir bool) bool {
+	rel = filepath.ToSlash(rel)
+	for _, pattern := range s.patterns {
+		if strings.HasSuffix(pattern, "/") {
+			if !isDir {
+				continue
+			}
+			pattern = strings.TrimSuffix(pattern, "/")
+		}
+		if anchored, ok := strings.CutPrefix(pattern, "/"); ok {
+			if matched, _ := filepath.Match(anchored, rel); matched {
+				return true
+			}
+			continue
+		}
+		if strings.Contains(pattern, "/") {
+			if matched, _ := filepath.Match(pattern, rel); matched {
+				return true
+			}
+			continue
+		}
+		if matched, _ := filepath.Match(pattern, filepath.Base(rel)); matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,205 @@
+package cloning
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+type resyncFixture struct {
+	hostDir, sandboxRoot, cloneDir string
+	created                        time.Time
+}
+
+func newResyncFixture(t *testing.T) *resyncFixture {
+	t.Helper()
+	sandboxRoot := t.TempDir()
+	f := &resyncFixture{
+		hostDir:     t.TempDir(),
+		sandboxRoot: sandboxRoot,
+		cloneDir:    NewStandardPathRegistry(sandboxRoot).WorkDir(),
+		created:     time.Now().Add(-time.Hour).Truncate(time.Second),
+	}
+	if err := os.MkdirAll(f.cloneDir, 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	return f
+}
+
+// writeResyncFile creates path under dir with content and the given mtime.
+func writeResyncFile(t *testing.T, dir, path, content string, mtime time.Time) {
+	t.Helper()
+	full := filepath.Join(dir, path)
+	if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if err := os.Chtimes(full, mtime, mtime); err != nil {
+		t.Fatalf("Chtimes: %v", err)
+	}
+}
+
+func readResyncFile(t *testing.T, dir, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, path))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	return string(data)
+}
+
+func TestWorkspaceResyncCopiesOnlyHostChanges(t *testing.T) {
+	f := newResyncFixture(t)
+	before := f.created.Add(-time.Minute)
+	after := f.created.Add(time.Minute)
+
+	// Unchanged on both sides since the clone was made.
+	writeResyncFile(t, f.hostDir, "same.txt", "same", before)
+	writeResyncFile(t, f.cloneDir, "same.txt", "same", before)
+	// Edited on the host after creation.
+	writeResyncFile(t, f.hostDir, "pkg/edited.go", "package pkg // v2", after)
+	writeResyncFile(t, f.cloneDir, "pkg/edited.go", "package pkg", before)
+	// New on the host.
+	writeResyncFile(t, f.hostDir, "new.txt", "new", after)
+	// Edited only inside the sandbox; the host copy is older than the clone.
+	writeResyncFile(t, f.hostDir, "local.txt", "host", before)
+	writeResyncFile(t, f.cloneDir, "local.txt", "sandbox edit", after)
+	// Never synced: git internals and .sandignore'd paths.
+	writeResyncFile(t, f.hostDir, ".git/HEAD", "ref: refs/heads/other", after)
+	writeResyncFile(t, f.hostDir, "node_modules/dep/index.js", "dep", after)
+	writeResyncFile(t, f.hostDir, "build/out.bin", "out", after)
+	writeResyncFile(t, f.hostDir, "secrets.env", "TOKEN=x", after)
+	writeResyncFile(t, f.hostDir, SandIgnoreFile, "# comment\nnode_modules/\n/build\n*.env\n", before)
+
+	result, err := NewWorkspaceResync(f.sandboxRoot).Resync(context.Background(), f.hostDir, f.created)
+	if err != nil {
+		t.Fatalf("Resync() error = %v", err)
+	}
+	sort.Strings(result.Copied)
+	if want := []string{"new.txt", filepath.Join("pkg", "edited.go")}; !reflect.DeepEqual(result.Copied, want) {
+		t.Fatalf("Copied = %v, want %v", result.Copied, want)
+	}
+	if len(result.Conflicts) != 0 {
+		t.Fatalf("Conflicts = %v, want none", result.Conflicts)
+	}
+	if got := readResyncFile(t, f.cloneDir, "pkg/edited.go"); got != "package pkg // v2" {
+		t.Errorf("edited.go = %q, want host contents", got)
+	}
+	if got := readResyncFile(t, f.cloneDir, "local.txt"); got != "sandbox edit" {
+		t.Errorf("local.txt = %q, want sandbox edit kept", got)
+	}
+	for _, path := range []string{".git/HEAD", "node_modules/dep/index.js", "build/out.bin", "secrets.env"} {
+		if _, err := os.Stat(filepath.Join(f.cloneDir, path)); !os.IsNotExist(err) {
+			t.Errorf("%s was synced into the clone (stat err = %v)", path, err)
+		}
+	}
+	info, err := os.Stat(filepath.Join(f.cloneDir, "new.txt"))
+	if err != nil {
+		t.Fatalf("Stat(new.txt): %v", err)
+	}
+	if !info.ModTime().Equal(after) {
+		t.Errorf("new.txt mtime = %v, want host mtime %v", info.ModTime(), after)
+	}
+}
+
+func TestWorkspaceResyncSkipsFilesChangedOnBothSides(t *testing.T) {
+	f := newResyncFixture(t)
+	writeResyncFile(t, f.hostDir, "main.go", "host change", f.created.Add(time.Minute))
+	writeResyncFile(t, f.cloneDir, "main.go", "sandbox change", f.created.Add(2*time.Minute))
+
+	result, err := NewWorkspaceResync(f.sandboxRoot).Resync(context.Background(), f.hostDir, f.created)
+	if err != nil {
+		t.Fatalf("Resync() error = %v", err)
+	}
+	if len(result.Copied) != 0 {
+		t.Fatalf("Copied = %v, want none", result.Copied)
+	}
+	if want := []string{"main.go"}; !reflect.DeepEqual(result.Conflicts, want) {
+		t.Fatalf("Conflicts = %v, want %v", result.Conflicts, want)
+	}
+	if got := readResyncFile(t, f.cloneDir, "main.go"); got != "sandbox change" {
+		t.Fatalf("main.go = %q, want sandbox change kept", got)
+	}
+}
+
+func TestWorkspaceResyncUsesLastSyncTime(t *testing.T) {
+	f := newResyncFixture(t)
+	r := NewWorkspaceResync(f.sandboxRoot)
+	firstSync := f.created.Add(10 * time.Minute)
+	r.now = func() time.Time { return firstSync }
+
+	writeResyncFile(t, f.hostDir, "a.txt", "v1", f.created.Add(time.Minute))
+	result, err := r.Resync(context.Background(), f.hostDir, f.created)
+	if err != nil {
+		t.Fatalf("first Resync() error = %v", err)
+	}
+	if want := []string{"a.txt"}; !reflect.DeepEqual(result.Copied, want) {
+		t.Fatalf("first Copied = %v, want %v", result.Copied, want)
+	}
+
+	// Edited in the sandbox after the first sync, then on the host: a conflict,
+	// even though the clone's mtime predates the host's.
+	writeResyncFile(t, f.cloneDir, "a.txt", "sandbox v2", firstSync.Add(time.Minute))
+	writeResyncFile(t, f.hostDir, "a.txt", "host v2", firstSync.Add(2*time.Minute))
+	writeResyncFile(t, f.hostDir, "b.txt", "b", firstSync.Add(time.Minute))
+	result, err = r.Resync(context.Background(), f.hostDir, f.created)
+	if err != nil {
+		t.Fatalf("second Resync() error = %v", err)
+	}
+	if want := []string{"b.txt"}; !reflect.DeepEqual(result.Copied, want) {
+		t.Errorf("second Copied = %v, want %v", result.Copied, want)
+	}
+	if want := []string{"a.txt"}; !reflect.DeepEqual(result.Conflicts, want) {
+		t.Errorf("second Conflicts = %v, want %v", result.Conflicts, want)
+	}
+}
+
+func TestSandIgnoreMatches(t *testing.T) {
+	ignore := &sandIgnore{patterns: []string{"*.log", "/dist", "tmp/", "docs/*.pdf"}}
+	for _, tt := range []struct {
+		rel   string
+		isDir bool
+		want  bool
+	}{
+		{"debug.log", false, true},
+		{"sub/debug.log", false, true},
+		{"dist", true, true},
+		{"sub/dist", true, false},
+		{"tmp", true, true},
+		{"tmp", false, false},
+		{"docs/guide.pdf", false, true},
+		{"other/docs/guide.pdf", false, false},
+		{"main.go", false, false},
+	} {
+		if got := ignore.matches(tt.rel, tt.isDir); got != tt.want {
+			t.Errorf("matches(%q, dir=%v) = %v, want %v", tt.rel, tt.isDir, got, tt.want)
+		}
+	}
+}
+
+func TestWorkspaceResyncStaysInsideTheClone(t *testing.T) {
+	f := newResyncFixture(t)
+	outside := t.TempDir()
+	// The sandbox points a directory of its clone at a host directory.
+	if err := os.Symlink(outside, filepath.Join(f.cloneDir, "pkg")); err != nil {
+		t.Fatal(err)
+	}
+	writeResyncFile(t, f.hostDir, "pkg/evil.txt", "host file", f.created.Add(time.Minute))
+
+	if _, err := NewWorkspaceResync(f.sandboxRoot).Resync(context.Background(), f.hostDir, f.created); err == nil {
+		t.Fatal("Resync() through a symlink out of the clone succeeded, want error")
+	}
+	entries, err := os.ReadDir(outside)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("Resync() wrote %v outside the clone", entries)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"path"
+	"strings"
 
 	"github.com/banksean/sand/internal/hookscript"
 	"github.com/banksean/sand/internal/sandtypes"
@@ -35,6 +37,10 @@ type containerBootstrapFlavor struct {
 	prepareSSHD      func(*containerHookRunner)
 }
 
+// hookOutputLines is how much of a failed command's output the hook's error
+// carries.
+const hookOutputLines = 20
+
 type containerHookRunner struct {
 	ctx      context.Context
 	exec     sandtypes.HookStreamer
@@ -56,7 +62,7 @@ func (r *containerHookRunner) run(step, wrap string, cmd ...string) bool {
 	out, err := r.exec.Exec(r.ctx, cmd[0], cmd[1:]...)
 	if err != nil {
 		slog.ErrorContext(r.ctx, r.hookName+" "+step, "error", err, "out", out, "username", r.username)
-		r.errs = append(r.errs, fmt.Errorf("%s: %w", wrap, err))
+		r.errs = append(r.errs, fmt.Errorf("%s: %w", wrap, sandtypes.ErrorWithOutput(err, "output", out, hookOutputLines)))
 		return false
 	}
 	return true
@@ -75,7 +81,7 @@ func (r *containerHookRunner) runStream(step, wrap, cmd string) bool {
 	var buf bytes.Buffer
 	if err := r.exec.ExecStream(r.ctx, &buf, &buf, cmd); err != nil {
 		slog.ErrorContext(r.ctx, r.hookName+" "+step, "error", err, "out", buf.String(), "username", r.username)
-		r.errs = append(r.errs, fmt.Errorf("%s: %w", wrap, err))
+		r.errs = append(r.errs, fmt.Errorf("%s: %w", wrap, sandtypes.ErrorWithOutput(err, "output", buf.String(), hookOutputLines)))
 		return false
 	}
 	return true
@@ -85,7 +91,7 @@ func (r *containerHookRunner) runScript(step, wrap, name, body string) bool {
 	var buf bytes.Buffer
 	if err := hookscript.Execute(r.ctx, r.exec, name, body, &buf); err != nil {
 		slog.ErrorContext(r.ctx, r.hookName+" "+step, "error", err, "out", buf.String(), "username", r.username)
-		r.errs = append(r.errs, fmt.Errorf("%s: %w", wrap, err))
+		r.errs = append(r.errs, fmt.Errorf("%s: %w", wrap, sandtypes.ErrorWithOutput(err, "output", buf.String(), hookOutputLines)))
 		return false
 	}
 	return true
@@ -140,6 +146,40 @@ func ubuntuCreateUser(r *containerHookRunner, username, uid string) {
 	r.run("making user home dir", "making user home dir", "mkdir", "-p", "/home/"+username)
 }
 
+// containerUserHome returns username's home directory from the container's
+// passwd database, so dotfiles and caches land where the user's tools look
+// even when the image already had the user with a home outside /home. It
+// falls back to /home/<username>, where createUser puts new users.
+func containerUserHome(r *containerHookRunner, username string) string {
+	out, err := r.exec.Exec(r.ctx, "getent", "passwd", username)
+	if err != nil {
+		slog.WarnContext(r.ctx, r.hookName+" looking up user home", "error", err, "out", out, "username", username)
+		return "/home/" + username
+	}
+	if home, ok := passwdHome(out, username); ok {
+		return home
+	}
+	return "/home/" + username
+}
+
+// passwdHome reads username's home directory from a passwd entry. Homes that
+// aren't absolute, or are /, are refused: the bootstrap chowns the home
+// recursively.
+func passwdHome(entry, username string) (string, bool) {
+	for line := range strings.SplitSeq(strings.TrimSpace(entry), "\n") {
+		fields := strings.Split(line, ":")
+		if len(fields) < 7 || fields[0] != username {
+			continue
+		}
+		home := path.Clean(fields[5])
+		if !path.IsAbs(fields[5]) || home == "/" {
+			return "", false
+		}
+		return home, true
+	}
+	return "", false
+}
+
 func ubuntuLinkPackageCache(r *containerHookRunner, sharedCaches sandtypes.SharedCacheMounts) {}
 
 func ubuntuPrepareSSHD(r *containerHookRunner) {
@@ -152,22 +192,25 @@ func NewBaseContainerConfiguration() *BaseContainerConfiguration {
 }
 
 func (c *BaseContainerConfiguration) GetMounts(artifacts Artifacts) []sandtypes.MountSpec {
-	mounts := []sandtypes.MountSpec{
-		{
+	var mounts []sandtypes.MountSpec
+	if !artifacts.NoSSH {
+		mounts = append(mounts, sandtypes.MountSpec{
 			Source:   artifacts.SSHKeysDir,
 			Target:   "/sshkeys",
 			ReadOnly: true,
-		},
-		{
+		})
+	}
+	if !artifacts.NoDotfiles {
+		mounts = append(mounts, sandtypes.MountSpec{
 			Source:   artifacts.DotfilesDir,
 			Target:   "/dotfiles",
 			ReadOnly: true,
-		},
-		{
-			Source: artifacts.WorkDir,
-			Target: "/app",
-		},
+		})
 	}
+	mounts = append(mounts, sandtypes.MountSpec{
+		Source: artifacts.WorkDir,
+		Target: "/app",
+	})
 
 	if artifacts.HostGitMirrorDir != "" {
 		mounts = append(mounts, sandtypes.MountSpec{
@@ -200,8 +243,11 @@ func (c *BaseContainerConfiguration) GetMounts(artifacts Artifacts) []sandtypes.
 }
 
 func (c *BaseContainerConfiguration) GetStartHooks(artifacts Artifacts) []sandtypes.ContainerHook {
+	if artifacts.NoSSH {
+		return nil
+	}
 	return []sandtypes.ContainerHook{
-		sandtypes.NewContainerHook("start sshd", func(ctx context.Context, ctr *sandtypes.Container, exec sandtypes.HookStreamer) error {
+		sandtypes.NewPhasedContainerHook("start sshd", sandtypes.HookPhasePre, func(ctx context.Context, ctr *sandtypes.Container, exec sandtypes.HookStreamer) error {
 			flavor, err := c.detectBootstrapFlavor(ctx, exec)
 			if err != nil {
 				return err
@@ -217,20 +263,24 @@ func (c *BaseContainerConfiguration) GetStartHooks(artifacts Artifacts) []sandty
 	}
 }
 
+func (c *BaseContainerConfiguration) GetStopHooks(artifacts Artifacts) []sandtypes.ContainerHook {
+	return nil
+}
+
 func (c *BaseContainerConfiguration) GetFirstStartHooks(artifacts Artifacts) []sandtypes.ContainerHook {
 	return []sandtypes.ContainerHook{
-		c.defaultContainerHook(artifacts.Username, artifacts.Uid, artifacts.SharedCacheMounts),
+		c.defaultContainerHook(artifacts),
 	}
 }
 
-func (c *BaseContainerConfiguration) defaultContainerHook(username, uid string, sharedCaches sandtypes.SharedCacheMounts) sandtypes.ContainerHook {
-	return sandtypes.NewContainerHook("default container bootstrap", func(ctx context.Context, ctr *sandtypes.Container, exec sandtypes.HookStreamer) error {
+func (c *BaseContainerConfiguration) defaultContainerHook(artifacts Artifacts) sandtypes.ContainerHook {
+	return sandtypes.NewPhasedContainerHook("default container bootstrap", sandtypes.HookPhasePre, func(ctx context.Context, ctr *sandtypes.Container, exec sandtypes.HookStreamer) error {
 		flavor, err := c.detectBootstrapFlavor(ctx, exec)
 		if err != nil {
 			return err
 		}
 
-		return c.runDefaultContainerHook(ctx, ctr, exec, flavor, username, uid, sharedCaches)
+		return c.runDefaultContainerHook(ctx, ctr, exec, flavor, artifacts)
 	})
 }
 
@@ -241,17 +291,21 @@ func (c *BaseContainerConfiguration) detectBootstrapFlavor(ctx context.Context,
 	return ubuntuBootstrapFlavor, nil
 }
 
-func (c *BaseContainerConfiguration) runDefaultContainerHook(ctx context.Context, ctr *sandtypes.Container, exec sandtypes.HookStreamer, flavor containerBootstrapFlavor, username, uid string, sharedCaches sandtypes.SharedCacheMounts) error {
+func (c *BaseContainerConfiguration) runDefaultContainerHook(ctx context.Context, ctr *sandtypes.Container, exec sandtypes.HookStreamer, flavor containerBootstrapFlavor, artifacts Artifacts) error {
+	username, uid, sharedCaches := artifacts.Username, artifacts.Uid, artifacts.SharedCacheMounts
 	runner := newContainerHookRunner(ctx, exec, flavor.hookName, username)
 
 	// We create a group and a user with the same name and uid as the the host user.
 	// This avoids potential permissions issues with volumes mounted from host.
 	flavor.createUser(runner, username, uid)
+	home := containerUserHome(runner, username)
 
-	runner.run("copying dotfiles", "copy dotfiles", "cp", "-r", "/dotfiles/.", "/home/"+username+"/.")
+	if !artifacts.NoDotfiles {
+		runner.run("copying dotfiles", "copy dotfiles", "cp", "-r", "/dotfiles/.", home+"/.")
+	}
 
 	// Copy config and known_hosts from /root/.ssh to make sure github host keys are already known for the user.
-	runner.run("copying /root/.ssh to ~/.ssh", "copy /root/.ssh", "cp", "-r", "/root/.ssh", "/home/"+username+"/.ssh")
+	runner.run("copying /root/.ssh to ~/.ssh", "copy /root/.ssh", "cp", "-r", "/root/.ssh", home+"/.ssh")
 
 	if sharedCaches.HTTPProxyURL != "" {
 		body := "write-http-proxy-env " + sharedCaches.HTTPProxyURL + "\n"
@@ -266,44 +320,45 @@ func (c *BaseContainerConfiguration) runDefaultContainerHook(ctx context.Context
 		runner.runScript("configuring root bazel remote cache", "configure root bazel remote cache", "root-bazelrc.txt",
 			"write-managed-bazelrc /root/.bazelrc "+sharedCaches.BazelRemoteCacheURL+"\n")
 		runner.runScript("configuring user bazel remote cache", "configure user bazel remote cache", "user-bazelrc.txt",
-			"write-managed-bazelrc /home/"+username+"/.bazelrc "+sharedCaches.BazelRemoteCacheURL+"\n")
+			"write-managed-bazelrc "+home+"/.bazelrc "+sharedCaches.BazelRemoteCacheURL+"\n")
 	}
 
 	// Create the parent directories before chown so the container user owns them,
 	// but delay the symlink creation until after chown so we don't traverse into
 	// shared host-mounted cache dirs.
 	if sharedCaches.MiseCacheHostDir != "" {
-		runner.run("preparing go module cache parent", "mkdir go module cache parent", "mkdir", "-p", "/home/"+username+"/go/pkg")
-		runner.run("preparing go build cache parent", "mkdir go build cache parent", "mkdir", "-p", "/home/"+username+"/.cache")
+		runner.run("preparing go module cache parent", "mkdir go module cache parent", "mkdir", "-p", home+"/go/pkg")
+		runner.run("preparing go build cache parent", "mkdir go build cache parent", "mkdir", "-p", home+"/.cache")
 	}
 
 	// Fix ownership
-	runner.run("chown homedir", "chown", "chown", "-R", username+":"+username,
-		"/home/"+username)
+	runner.run("chown homedir", "chown", "chown", "-R", username+":"+username, home)
 
 	flavor.linkPackageCache(runner, sharedCaches)
 
 	// mise.sh exports GOMODCACHE/GOCACHE directly, and these symlinks keep
 	// direct process execs aligned with the same mise-backed cache paths.
 	if sharedCaches.MiseCacheHostDir != "" {
-		runner.run("linking go module cache", "link go module cache", "ln", "-sfn", goModCachePath, "/home/"+username+"/go/pkg/mod")
-		runner.run("linking go build cache", "link go build cache", "ln", "-sfn", goBuildCachePath, "/home/"+username+"/.cache/go-build")
+		runner.run("linking go module cache", "link go module cache", "ln", "-sfn", goModCachePath, home+"/go/pkg/mod")
+		runner.run("linking go build cache", "link go build cache", "ln", "-sfn", goBuildCachePath, home+"/.cache/go-build")
 	}
 
-	// Copy SSH keys to /etc/ssh
-	runner.run("copying host keys", "copy host keys", "cp", "-r", "/sshkeys/.", "/etc/ssh/.")
+	if !artifacts.NoSSH {
+		// Copy SSH keys to /etc/ssh
+		runner.run("copying host keys", "copy host keys", "cp", "-r", "/sshkeys/.", "/etc/ssh/.")
 
-	// Set SSH key permissions
-	runner.run("setting host key permissions", "chmod host keys", "chmod", "600",
-		"/etc/ssh/ssh_host_key",
-		"/etc/ssh/ssh_host_key.pub",
-		"/etc/ssh/ssh_host_key.pub-cert",
-		"/etc/ssh/user_ca.pub")
+		// Set SSH key permissions
+		runner.run("setting host key permissions", "chmod host keys", "chmod", "600",
+			"/etc/ssh/ssh_host_key",
+			"/etc/ssh/ssh_host_key.pub",
+			"/etc/ssh/ssh_host_key.pub-cert",
+			"/etc/ssh/user_ca.pub")
 
-	flavor.prepareSSHD(runner)
+		flavor.prepareSSHD(runner)
 
-	// Start sshd
-	runner.run("starting sshd", "start sshd", "/usr/sbin/sshd", "-f", "/etc/ssh/sshd_config")
+		// Start sshd
+		runner.run("starting sshd", "start sshd", "/usr/sbin/sshd", "-f", "/etc/ssh/sshd_config")
+	}
 
 	if sharedCaches.MiseCacheHostDir != "" {
 		if runner.probe("checking for mise.sh", "which", "mise.sh") {
@@ -6,6 +6,7 @@ import (
 	"io"
 	"os"
 	"reflect"
+	"slices"
 	"strings"
 	"testing"
 
@@ -82,10 +83,10 @@ func TestDefaultContainerHook_UsesAlpineFlavorWhenAPKAvailable(t *testing.T) {
 		},
 	}
 
-	hook := cfg.defaultContainerHook("sean", "1000", sandtypes.SharedCacheMounts{
+	hook := cfg.defaultContainerHook(Artifacts{Username: "sean", Uid: "1000", SharedCacheMounts: sandtypes.SharedCacheMounts{
 		MiseCacheHostDir: "/host/mise",
 		APKCacheHostDir:  "/host/apk",
-	})
+	}})
 
 	if err := hook.Run(context.Background(), nil, exec); err != nil {
 		t.Fatalf("hook.Run() error = %v", err)
@@ -97,6 +98,7 @@ func TestDefaultContainerHook_UsesAlpineFlavorWhenAPKAvailable(t *testing.T) {
 		"exec:adduser -u 1000 -D -G sean -s /bin/zsh sean",
 		"exec:passwd -u sean",
 		"exec:addgroup sean wheel",
+		"exec:getent passwd sean",
 		"exec:cp -r /dotfiles/. /home/sean/.",
 		"exec:cp -r /root/.ssh /home/sean/.ssh",
 		"exec:mkdir -p /home/sean/go/pkg",
@@ -244,6 +246,25 @@ func TestStartHook_SkipsSSHDPrepareForAlpineFlavor(t *testing.T) {
 	}
 }
 
+func TestStartHook_FailureIncludesCommandOutput(t *testing.T) {
+	cfg := NewBaseContainerConfiguration()
+	exec := &fakeHookStreamer{
+		execResults: map[string]fakeExecResult{
+			commandKey("which", "apk"): {out: "apk-tools 2.14"},
+			commandKey("/usr/sbin/sshd", "-f", "/etc/ssh/sshd_config"): {
+				out: "sshd: no hostkeys available -- exiting.\n",
+				err: errors.New("exit status 1"),
+			},
+		},
+	}
+
+	err := cfg.GetStartHooks(Artifacts{Uid: "1000"})[0].Run(context.Background(), nil, exec)
+	want := "start sshd: exit status 1\n  output:\n    sshd: no hostkeys available -- exiting."
+	if err == nil || err.Error() != want {
+		t.Fatalf("start hook error = %q, want %q", err, want)
+	}
+}
+
 func TestDefaultContainerHook_UsesUbuntuFlavorWhenAPKUnavailable(t *testing.T) {
 	cfg := NewBaseContainerConfiguration()
 	exec := &fakeHookStreamer{
@@ -256,9 +277,9 @@ func TestDefaultContainerHook_UsesUbuntuFlavorWhenAPKUnavailable(t *testing.T) {
 		},
 	}
 
-	hook := cfg.defaultContainerHook("sean", "1000", sandtypes.SharedCacheMounts{
+	hook := cfg.defaultContainerHook(Artifacts{Username: "sean", Uid: "1000", SharedCacheMounts: sandtypes.SharedCacheMounts{
 		MiseCacheHostDir: "/host/mise",
-	})
+	}})
 
 	if err := hook.Run(context.Background(), nil, exec); err != nil {
 		t.Fatalf("hook.Run() error = %v", err)
@@ -271,6 +292,7 @@ func TestDefaultContainerHook_UsesUbuntuFlavorWhenAPKUnavailable(t *testing.T) {
 		"exec:passwd -d sean",
 		"exec:usermod -a -G sudo sean",
 		"exec:mkdir -p /home/sean",
+		"exec:getent passwd sean",
 		"exec:cp -r /dotfiles/. /home/sean/.",
 		"exec:cp -r /root/.ssh /home/sean/.ssh",
 		"exec:mkdir -p /home/sean/go/pkg",
@@ -294,6 +316,60 @@ func TestDefaultContainerHook_UsesUbuntuFlavorWhenAPKUnavailable(t *testing.T) {
 	}
 }
 
+func TestDefaultContainerHook_CopiesIntoTheUsersHome(t *testing.T) {
+	cfg := NewBaseContainerConfiguration()
+	exec := &fakeHookStreamer{
+		execResults: map[string]fakeExecResult{
+			commandKey("which", "apk"):             {out: "apk-tools 2.14"},
+			commandKey("getent", "passwd", "sean"): {out: "sean:x:1000:1000::/workspace/sean:/bin/zsh\n"},
+		},
+	}
+
+	hook := cfg.defaultContainerHook(Artifacts{Username: "sean", Uid: "1000", NoSSH: true, SharedCacheMounts: sandtypes.SharedCacheMounts{
+		MiseCacheHostDir: "/host/mise",
+	}})
+	if err := hook.Run(context.Background(), nil, exec); err != nil {
+		t.Fatalf("hook.Run() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"exec:cp -r /dotfiles/. /workspace/sean/.",
+		"exec:cp -r /root/.ssh /workspace/sean/.ssh",
+		"exec:chown -R sean:sean /workspace/sean",
+		"exec:ln -sfn /opt/tool-cache/mise/go/mod /workspace/sean/go/pkg/mod",
+	} {
+		if !slices.Contains(exec.calls, want) {
+			t.Errorf("hook.Run() calls missing %q:\n%s", want, strings.Join(exec.calls, "\n"))
+		}
+	}
+	for _, call := range exec.calls {
+		if strings.Contains(call, "/home/sean/") {
+			t.Errorf("hook.Run() still wrote under /home/sean: %s", call)
+		}
+	}
+}
+
+func TestPasswdHome(t *testing.T) {
+	for _, tc := range []struct {
+		entry, username string
+		want            string
+		wantOK          bool
+	}{
+		{entry: "sean:x:1000:1000:Sean:/home/sean:/bin/zsh", username: "sean", want: "/home/sean", wantOK: true},
+		{entry: "root:x:0:0:root:/root:/bin/ash\n", username: "root", want: "/root", wantOK: true},
+		{entry: "dev:x:1001:1001::/srv/dev/:/bin/sh", username: "dev", want: "/srv/dev", wantOK: true},
+		{entry: "nobody:x:65534:65534::/:/sbin/nologin", username: "nobody"},
+		{entry: "odd:x:1002:1002::relative:/bin/sh", username: "odd"},
+		{entry: "other:x:1003:1003::/home/other:/bin/sh", username: "sean"},
+		{entry: "", username: "sean"},
+	} {
+		got, ok := passwdHome(tc.entry, tc.username)
+		if got != tc.want || ok != tc.wantOK {
+			t.Errorf("passwdHome(%q, %q) = %q, %v; want %q, %v", tc.entry, tc.username, got, ok, tc.want, tc.wantOK)
+		}
+	}
+}
+
 func TestDefaultContainerHook_ConfiguresBazelRemoteCacheWhenEnabled(t *testing.T) {
 	cfg := NewBaseContainerConfiguration()
 	exec := &fakeHookStreamer{
@@ -306,9 +382,9 @@ func TestDefaultContainerHook_ConfiguresBazelRemoteCacheWhenEnabled(t *testing.T
 		},
 	}
 
-	hook := cfg.defaultContainerHook("sean", "1000", sandtypes.SharedCacheMounts{
+	hook := cfg.defaultContainerHook(Artifacts{Username: "sean", Uid: "1000", SharedCacheMounts: sandtypes.SharedCacheMounts{
 		BazelRemoteCacheURL: "http://sand-bazel-cache.test.local:8080",
-	})
+	}})
 
 	if err := hook.Run(context.Background(), nil, exec); err != nil {
 		t.Fatalf("hook.Run() error = %v", err)
@@ -341,9 +417,9 @@ func TestDefaultContainerHook_ConfiguresSharedHTTPProxyWhenEnabled(t *testing.T)
 		},
 	}
 
-	hook := cfg.defaultContainerHook("sean", "1000", sandtypes.SharedCacheMounts{
+	hook := cfg.defaultContainerHook(Artifacts{Username: "sean", Uid: "1000", SharedCacheMounts: sandtypes.SharedCacheMounts{
 		HTTPProxyURL: "http://sand-http-cache.test.local:3128",
-	})
+	}})
 
 	if err := hook.Run(context.Background(), nil, exec); err != nil {
 		t.Fatalf("hook.Run() error = %v", err)
@@ -412,9 +488,9 @@ func TestRunDefaultContainerHook_JoinsStepErrors(t *testing.T) {
 		},
 	}
 
-	err := cfg.runDefaultContainerHook(context.Background(), nil, exec, alpineBootstrapFlavor, "sean", "1000", sandtypes.SharedCacheMounts{
+	err := cfg.runDefaultContainerHook(context.Background(), nil, exec, alpineBootstrapFlavor, Artifacts{Username: "sean", Uid: "1000", SharedCacheMounts: sandtypes.SharedCacheMounts{
 		MiseCacheHostDir: "/host/mise",
-	})
+	}})
 	if err == nil {
 		t.Fatal("runDefaultContainerHook() error = nil, want joined error")
 	}
@@ -427,4 +503,44 @@ func TestRunDefaultContainerHook_JoinsStepErrors(t *testing.T) {
 	if !errors.Is(err, miseErr) {
 		t.Fatalf("runDefaultContainerHook() missing mise.sh error: %v", err)
 	}
+	if !strings.Contains(err.Error(), "mise failed\n  output:\n    mise startup failed") {
+		t.Fatalf("runDefaultContainerHook() error = %v, want mise.sh's output", err)
+	}
+}
+
+func TestBaseContainerConfigurationOmitsDotfilesAndSSHWhenDisabled(t *testing.T) {
+	cfg := NewBaseContainerConfiguration()
+	artifacts := Artifacts{
+		WorkDir:     "/clone/app",
+		DotfilesDir: "/clone/dotfiles",
+		SSHKeysDir:  "/clone/sshkeys",
+		Username:    "sean",
+		Uid:         "1000",
+		NoDotfiles:  true,
+		NoSSH:       true,
+	}
+
+	for _, m := range cfg.GetMounts(artifacts) {
+		if m.Target == "/dotfiles" || m.Target == "/sshkeys" {
+			t.Errorf("GetMounts() includes %s, want it left out", m.Target)
+		}
+	}
+	if hooks := cfg.GetStartHooks(artifacts); len(hooks) != 0 {
+		t.Errorf("GetStartHooks() = %d hooks, want none without sshd", len(hooks))
+	}
+
+	exec := &fakeHookStreamer{execResults: map[string]fakeExecResult{
+		commandKey("which", "apk"): {out: "apk-tools 2.14"},
+	}}
+	if err := cfg.defaultContainerHook(artifacts).Run(context.Background(), nil, exec); err != nil {
+		t.Fatalf("hook.Run() error = %v", err)
+	}
+	for _, call := range exec.calls {
+		if strings.Contains(call, "/dotfiles") || strings.Contains(call, "/sshkeys") || strings.Contains(call, "sshd") {
+			t.Errorf("bootstrap ran %q, want dotfile and sshd steps skipped", call)
+		}
+	}
+	if !slices.Contains(exec.calls, "exec:cp -r /root/.ssh /home/sean/.ssh") {
+		t.Errorf("bootstrap calls = %v, want ~/.ssh still seeded with known hosts for git", exec.calls)
+	}
 }
@@ -13,10 +13,15 @@ type Artifacts struct {
 	Username          string
 	Uid               string
 	SharedCacheMounts sandtypes.SharedCacheMounts
+	// NoDotfiles leaves /dotfiles out of the container and its bootstrap.
+	NoDotfiles bool
+	// NoSSH leaves out the ssh host keys and sshd, so the sandbox is only
+	// reachable by exec.
+	NoSSH bool
 }
 
 // ContainerConfiguration handles container runtime configuration such as
-// mount specifications and first-time startup hooks.
+// mount specifications, startup hooks and stop hooks.
 type ContainerConfiguration interface {
 	// GetMounts returns the mount specifications for the container based on the artifacts.
 	GetMounts(artifacts Artifacts) []sandtypes.MountSpec
@@ -26,4 +31,8 @@ type ContainerConfiguration interface {
 
 	// GetStartHooks returns hooks that should run after starting the container any time after the first start.
 	GetStartHooks(artifacts Artifacts) []sandtypes.ContainerHook
+
+	// GetStopHooks returns hooks that should run while the container is still
+	// running, just before it is stopped.
+	GetStopHooks(artifacts Artifacts) []sandtypes.ContainerHook
 }
@@ -50,6 +50,10 @@ func (c *DefinitionContainerConfiguration) GetStartHooks(artifacts Artifacts) []
 	return append(c.base.GetStartHooks(artifacts), c.namedHooks(artifacts)...)
 }
 
+func (c *DefinitionContainerConfiguration) GetStopHooks(artifacts Artifacts) []sandtypes.ContainerHook {
+	return append(c.base.GetStopHooks(artifacts), c.namedStopHooks()...)
+}
+
 func (c *DefinitionContainerConfiguration) installAgentHook() sandtypes.ContainerHook {
 	return sandtypes.NewContainerHook("install "+c.agentName+" agent", func(ctx context.Context, ctr *sandtypes.Container, exec sandtypes.HookStreamer) error {
 		script, err := agentInstallHookScript(c.agentName, *c.install)
@@ -80,7 +84,8 @@ func agentInstallHookScript(agentName string, install agentdefs.InstallSpec) (st
 	case agentdefs.InstallerNPM:
 		return fmt.Sprintf("install-npm-agent %s %s %s\n", install.Command, install.Package, install.Version), nil
 	case agentdefs.InstallerOpenCode:
-		return fmt.Sprintf("install-opencode-agent %s %s\n", install.Command, install.Version), nil
+		// opencode unpacks ripgrep with GNU tar options on first use.
+		return fmt.Sprintf("install-opencode-agent %s %s\nensure-gnu-tar\n", install.Command, install.Version), nil
 	default:
 		return "", fmt.Errorf("unknown installer kind %q for agent %q", install.Kind, agentName)
 	}
@@ -116,6 +121,10 @@ func (c *DefinitionContainerConfiguration) namedHooks(artifacts Artifacts) []san
 	for _, name := range c.startHooks {
 		switch name {
 		case agentdefs.HookOpenCodeTunnel:
+			if artifacts.NoSSH {
+				// The tunnel rides on ssh into the container.
+				continue
+			}
 			hooks = append(hooks, openCodeSSHTunnelHook(artifacts.Username))
 		default:
 			hooks = append(hooks, unknownAgentHook(name))
@@ -124,6 +133,19 @@ func (c *DefinitionContainerConfiguration) namedHooks(artifacts Artifacts) []san
 	return hooks
 }
 
+// namedStopHooks returns the stop hooks that undo the agent's named start
+// hooks. Start hooks with nothing to undo have no stop hook.
+func (c *DefinitionContainerConfiguration) namedStopHooks() []sandtypes.ContainerHook {
+	var hooks []sandtypes.ContainerHook
+	for _, name := range c.startHooks {
+		switch name {
+		case agentdefs.HookOpenCodeTunnel:
+			hooks = append(hooks, openCodeSSHTunnelStopHook())
+		}
+	}
+	return hooks
+}
+
 func unknownAgentHook(name string) sandtypes.ContainerHook {
 	return sandtypes.NewContainerHook("unknown agent hook "+name, func(ctx context.Context, ctr *sandtypes.Container, exec sandtypes.HookStreamer) error {
 		return fmt.Errorf("unknown agent hook %q", name)
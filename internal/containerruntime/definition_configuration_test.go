@@ -74,6 +74,43 @@ func TestDefinitionContainerConfigurationKeepsOpenCodeTunnelAsRecurringHook(t *t
 	}
 }
 
+// killingHookStreamer records the host processes a stop hook kills.
+type killingHookStreamer struct {
+	fakeHookStreamer
+	killed []string
+}
+
+func (k *killingHookStreamer) KillHostProcess(ctx context.Context, name string) error {
+	k.killed = append(k.killed, name)
+	return nil
+}
+
+func TestDefinitionContainerConfigurationClosesOpenCodeTunnelOnStop(t *testing.T) {
+	definition, ok := agentdefs.Lookup("opencode")
+	if !ok {
+		t.Fatal("missing opencode definition")
+	}
+	hooks := NewDefinitionContainerConfiguration(definition).GetStopHooks(Artifacts{Username: "sean"})
+	if got, want := hookNames(hooks), []string{"close remote ssh tunnel for chrome-devtools mcp"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("stop hook names = %#v, want %#v", got, want)
+	}
+	exec := &killingHookStreamer{}
+	if err := hooks[0].Run(context.Background(), nil, exec); err != nil {
+		t.Fatalf("stop hook Run() error = %v", err)
+	}
+	if want := []string{openCodeTunnelProcessName}; !reflect.DeepEqual(exec.killed, want) {
+		t.Fatalf("killed = %#v, want %#v", exec.killed, want)
+	}
+
+	codex, ok := agentdefs.Lookup("codex")
+	if !ok {
+		t.Fatal("missing codex definition")
+	}
+	if hooks := NewDefinitionContainerConfiguration(codex).GetStopHooks(Artifacts{}); len(hooks) != 0 {
+		t.Fatalf("codex stop hooks = %#v, want none", hookNames(hooks))
+	}
+}
+
 func TestAgentInstallHookScriptUsesOpenCodeCommand(t *testing.T) {
 	definition, ok := agentdefs.Lookup("opencode")
 	if !ok {
@@ -83,7 +120,7 @@ func TestAgentInstallHookScriptUsesOpenCodeCommand(t *testing.T) {
 	if err != nil {
 		t.Fatalf("agentInstallHookScript() error = %v", err)
 	}
-	want := "install-opencode-agent opencode 1.18.4\n"
+	want := "install-opencode-agent opencode 1.18.4\nensure-gnu-tar\n"
 	if script != want {
 		t.Fatalf("opencode install script = %q, want %q", script, want)
 	}
@@ -11,9 +11,14 @@ import (
 	"github.com/banksean/sand/internal/sandtypes"
 )
 
+// openCodeTunnelProcessName identifies the chrome-devtools tunnel among a
+// sandbox's recorded host processes.
+const openCodeTunnelProcessName = "opencode-chrome-devtools-tunnel"
+
 // openCodeSSHTunnelHook sets up an SSH reverse tunnel for Chrome DevTools MCP.
+// It runs after sshd is up, so it is a post-phase hook.
 func openCodeSSHTunnelHook(username string) sandtypes.ContainerHook {
-	return sandtypes.NewContainerHook("open remote ssh tunnel for chrome-devtools mcp", func(ctx context.Context, ctr *sandtypes.Container, execFn sandtypes.HookStreamer) error {
+	return sandtypes.NewPhasedContainerHook("open remote ssh tunnel for chrome-devtools mcp", sandtypes.HookPhasePost, func(ctx context.Context, ctr *sandtypes.Container, execFn sandtypes.HookStreamer) error {
 		hostname := getContainerHostname(ctr)
 
 		// No context - this should run in a separate process that outlives the cloner startup hook invocations.
@@ -29,6 +34,12 @@ func openCodeSSHTunnelHook(username string) sandtypes.ContainerHook {
 		}
 
 		slog.InfoContext(ctx, "openSSHTunnelHook ssh remote port forward", "pid", cmd.Process.Pid)
+		if recorder, ok := execFn.(sandtypes.HostProcessRecorder); ok {
+			if err := recorder.RecordHostProcess(ctx, openCodeTunnelProcessName, cmd.Process.Pid); err != nil {
+				// The tunnel still works; it just won't be cleaned up with the sandbox.
+				slog.ErrorContext(ctx, "openSSHTunnelHook recording tunnel pid", "pid", cmd.Process.Pid, "error", err)
+			}
+		}
 
 		go func() {
 			if err := cmd.Wait(); err != nil {
@@ -40,6 +51,17 @@ func openCodeSSHTunnelHook(username string) sandtypes.ContainerHook {
 	})
 }
 
+// openCodeSSHTunnelStopHook closes the tunnel opened by openCodeSSHTunnelHook.
+func openCodeSSHTunnelStopHook() sandtypes.ContainerHook {
+	return sandtypes.NewContainerHook("close remote ssh tunnel for chrome-devtools mcp", func(ctx context.Context, ctr *sandtypes.Container, execFn sandtypes.HookStreamer) error {
+		killer, ok := execFn.(sandtypes.HostProcessKiller)
+		if !ok {
+			return nil
+		}
+		return killer.KillHostProcess(ctx, openCodeTunnelProcessName)
+	})
+}
+
 func getContainerHostname(ctr *sandtypes.Container) string {
 	for _, n := range ctr.Networks {
 		return strings.TrimSuffix(n.Hostname, ".")
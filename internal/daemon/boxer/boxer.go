@@ -14,6 +14,8 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/banksean/sand/internal/agents"
@@ -28,14 +30,20 @@ import (
 	"github.com/banksean/sand/internal/sandtypes"
 	"github.com/banksean/sand/internal/sshimmer"
 	_ "github.com/golang-migrate/migrate/v4/database/sqlite"
+	"golang.org/x/crypto/ssh"
 	_ "modernc.org/sqlite"
 )
 
 const containerGetErrorMsg = "[error getting]"
 
+// stopContainerTimeout bounds how long StopContainer waits for a graceful
+// stop before it kills the container.
+var stopContainerTimeout = 30 * time.Second
+
 // SSHimmer provisions SSH keys for a new sandbox.
 type SSHimmer interface {
 	NewKeys(ctx context.Context, domain, username string) (*sshimmer.Keys, error)
+	RemoveHost(ctx context.Context, hostName string) error
 }
 
 // Boxer manages the lifecycle of sandboxes.
@@ -51,6 +59,24 @@ type Boxer struct {
 	SSHim            SSHimmer
 	AgentRegistry    *agents.AgentRegistry
 	httpProxyService *HTTPProxyCacheService
+	now              func() time.Time
+	// inspectCache, when set, is ContainerService; see CacheContainerInspect.
+	inspectCache *inspectCachingContainerOps
+	// sandboxLocks holds a *sync.Mutex per sandbox ID; see lockSandbox.
+	sandboxLocks sync.Map
+	// imageLocks holds a chan struct{} per image name; see lockImage.
+	imageLocks sync.Map
+	// events fans out lifecycle events to Subscribe callers.
+	events eventHub
+	// metrics counts lifecycle events, clones and image pulls; see WriteMetrics.
+	metrics metrics
+	// killProcessGroup signals a recorded host process group; see killHostProcesses.
+	killProcessGroup func(pgid int) error
+	// processStartTime identifies a host process by when it started; see
+	// RecordHostProcess.
+	processStartTime func(pid int) (string, error)
+	// startForward launches the ssh process for a port forward; see StartPortForward.
+	startForward func(destination string, fwd sandtypes.PortForward) (int, error)
 }
 
 func runtimeArtifactsFromClone(artifacts *cloning.CloneArtifacts) containerruntime.Artifacts {
@@ -76,6 +102,8 @@ type BoxerDeps struct {
 	SSHim            SSHimmer
 	AgentRegistry    *agents.AgentRegistry
 	Messenger        hostops.UserMessenger
+	// Now is the clock used for sandbox timestamps. Defaults to time.Now.
+	Now func() time.Time
 }
 
 // NewBoxerWithDeps creates a Boxer with explicitly provided dependencies and a fresh
@@ -95,17 +123,24 @@ func NewBoxerWithDeps(appRoot string, deps BoxerDeps) (*Boxer, error) {
 	if deps.Messenger == nil {
 		deps.Messenger = hostops.NewTerminalMessenger(nil)
 	}
+	if deps.Now == nil {
+		deps.Now = time.Now
+	}
 	return &Boxer{
 		appRoot:          appRoot,
 		messenger:        deps.Messenger,
 		sqlDB:            sqlDB,
-		queries:          db.New(sqlDB),
+		queries:          db.NewWithBusyRetry(sqlDB),
 		ContainerService: deps.ContainerService,
 		ImageService:     deps.ImageService,
 		GitOps:           deps.GitOps,
 		FileOps:          deps.FileOps,
 		SSHim:            deps.SSHim,
 		AgentRegistry:    deps.AgentRegistry,
+		now:              deps.Now,
+		killProcessGroup: terminateProcessGroup,
+		processStartTime: processStartTime,
+		startForward:     startSSHForward,
 	}, nil
 }
 
@@ -141,13 +176,17 @@ func NewBoxer(appRoot, localDomain string, terminalWriter io.Writer) (*Boxer, er
 		appRoot:          appRoot,
 		messenger:        hostops.NewTerminalMessenger(terminalWriter),
 		sqlDB:            sqlDB,
-		queries:          db.New(sqlDB),
+		queries:          db.NewWithBusyRetry(sqlDB),
 		ContainerService: containerService,
 		ImageService:     imageService,
 		GitOps:           hostops.NewDefaultGitOps(),
 		FileOps:          fileOps,
 		SSHim:            sshim,
 		AgentRegistry:    agentRegistry,
+		now:              time.Now,
+		killProcessGroup: terminateProcessGroup,
+		processStartTime: processStartTime,
+		startForward:     startSSHForward,
 	}
 	return sb, nil
 }
@@ -159,6 +198,59 @@ func (sb *Boxer) Close() error {
 	return nil
 }
 
+// lockSandbox serializes operations on the sandbox with the given ID while
+// leaving operations on other sandboxes free to run in parallel. Call the
+// returned func to release the lock.
+func (sb *Boxer) lockSandbox(id string) func() {
+	for {
+		v, _ := sb.sandboxLocks.LoadOrStore(id, &sync.Mutex{})
+		mu := v.(*sync.Mutex)
+		mu.Lock()
+		if cur, ok := sb.sandboxLocks.Load(id); ok && cur == mu {
+			return mu.Unlock
+		}
+		// forgetSandboxLock dropped this mutex while we waited for it; take
+		// the one now in the map, so we can't run alongside its holder.
+		mu.Unlock()
+	}
+}
+
+// forgetSandboxLock drops the lock of a sandbox that no longer exists, so
+// sandboxLocks doesn't keep an entry for every ID the daemon has seen. The
+// caller must hold the lock.
+func (sb *Boxer) forgetSandboxLock(id string) {
+	sb.sandboxLocks.Delete(id)
+}
+
+// LockSandbox is lockSandbox for the daemon's own multi-step operations on a
+// sandbox, such as starting it. The caller must not call the Boxer methods
+// that take the lock themselves while holding it.
+func (sb *Boxer) LockSandbox(id string) func() {
+	return sb.lockSandbox(id)
+}
+
+// lockImage serializes EnsureImage calls for imageName, so sandboxes created
+// at the same time from an image that isn't present yet share one pull: the
+// first caller pulls while the rest wait, then find the image present. A
+// waiting caller is told so on progress and gives up when ctx is done. Call
+// the returned func to release the lock.
+func (sb *Boxer) lockImage(ctx context.Context, imageName string, progress io.Writer) (func(), error) {
+	v, _ := sb.imageLocks.LoadOrStore(imageName, make(chan struct{}, 1))
+	sem := v.(chan struct{})
+	select {
+	case sem <- struct{}{}:
+	default:
+		slog.InfoContext(ctx, "Boxer.lockImage", "status", "waiting", "imageName", imageName)
+		fmt.Fprintf(progress, "Waiting for another request for %s to finish\n", imageName)
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return func() { <-sem }, nil
+}
+
 func (sb *Boxer) newLifecycleService() *lifecycle.Service {
 	return lifecycle.NewService(lifecycle.Deps{
 		AppRoot:          sb.appRoot,
@@ -169,9 +261,29 @@ func (sb *Boxer) newLifecycleService() *lifecycle.Service {
 	})
 }
 
+// SyncOpts controls SyncSandboxes.
+type SyncOpts struct {
+	// KeepGoing makes SyncSandboxes return every sandbox's sync error, joined,
+	// once it has checked them all. Without it they are only logged.
+	KeepGoing bool
+	// All makes SyncSandboxes return every active sandbox it synced, not
+	// just the degraded ones.
+	All bool
+}
+
 // Sync tells Boxer to synchronize its internal database with the external states of
-// the clone tool directory and local container service.
+// the clone tool directory and local container service. Errors syncing individual
+// sandboxes are logged, not returned.
 func (sb *Boxer) Sync(ctx context.Context) error {
+	_, err := sb.SyncSandboxes(ctx, SyncOpts{})
+	return err
+}
+
+// SyncSandboxes syncs every active sandbox as Sync does and returns those whose
+// clone directory or container is in an error state, with SandboxWorkDirError
+// or SandboxContainerError saying why. With opts.KeepGoing it also returns
+// their sync errors joined.
+func (sb *Boxer) SyncSandboxes(ctx context.Context, opts SyncOpts) ([]sandtypes.Box, error) {
 	slog.InfoContext(ctx, "Boxer.Sync")
 	// First, iterate through the sandbox records in the DB and update the its fiels to
 	// reflect the current state of the filesystem clone root directory and container instance
@@ -180,32 +292,98 @@ func (sb *Boxer) Sync(ctx context.Context) error {
 	if err != nil {
 		slog.ErrorContext(ctx, "Boxer.Sync ListSandboxes", "error", err)
 
-		return err
+		return nil, err
+	}
+
+	boxes := make([]*sandtypes.Box, len(sboxes))
+	for i := range sboxes {
+		slog.InfoContext(ctx, "Boxer.Sync", "box", sboxes[i])
+		boxes[i] = sb.sandboxFromDB(&sboxes[i])
+	}
+	sb.inspectContainers(ctx, boxes)
+
+	// For each sandbox, update the status of its filesystem clone. The checks
+	// are independent stats, so run them concurrently.
+	var wg sync.WaitGroup
+	errs := make([]error, len(boxes))
+	for i, box := range boxes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := sb.SyncBox(ctx, box); err != nil {
+				slog.ErrorContext(ctx, "Boxer.Sync box.Sync", "error", err)
+				errs[i] = err
+			}
+		}()
+	}
+	wg.Wait()
+
+	var degraded []sandtypes.Box
+	for _, box := range boxes {
+		if opts.All || box.SandboxWorkDirError != "" || box.SandboxContainerError != "" {
+			degraded = append(degraded, *box)
+		}
+	}
+	if !opts.KeepGoing {
+		return degraded, nil
 	}
+	return degraded, errors.Join(errs...)
+}
 
-	// For each sandbox, update the status of its filesystem clone and its container instance.
-	for _, dbBox := range sboxes {
-		slog.InfoContext(ctx, "Boxer.Sync", "box", dbBox)
-		box, err := sb.GetByID(ctx, dbBox.ID)
+// inspectContainers fills in each box's Container with a single batched
+// Inspect call, then inspects one at a time only the containers the batch
+// didn't describe, so that a runtime that drops unknown IDs from a batch, or
+// fails it outright, still gets each box its own answer or error.
+func (sb *Boxer) inspectContainers(ctx context.Context, boxes []*sandtypes.Box) {
+	var ids []string
+	for _, box := range boxes {
+		if box.ContainerID != "" {
+			ids = append(ids, box.ContainerID)
+		}
+	}
+	byID := map[string]*sandtypes.Container{}
+	if len(ids) > 1 {
+		ctrs, err := sb.listInspect(ctx, ids...)
 		if err != nil {
-			return err
+			slog.WarnContext(ctx, "Boxer.inspectContainers batch", "error", err)
 		}
-		if err := sb.SyncBox(ctx, box); err != nil {
-			slog.ErrorContext(ctx, "Boxer.Sync box.Sync", "error", err)
+		for i := range ctrs {
+			byID[ctrs[i].Configuration.ID] = &ctrs[i]
 		}
 	}
-	return nil
+	for _, box := range boxes {
+		if ctr, ok := byID[box.ContainerID]; ok {
+			box.Container = ctr
+			continue
+		}
+		ctr, err := sb.listContainer(ctx, box.ContainerID)
+		if err != nil {
+			box.SandboxContainerError = containerGetErrorMsg
+		}
+		box.Container = ctr
+	}
 }
 
+// SyncBox checks that sb's clone directory exists, recording
+// SandboxWorkDirError if it doesn't, and records SandboxResourceWarning for a
+// running container near its memory limit. It returns an error for each
+// problem it or an earlier container inspection recorded in sb; resource
+// warnings aren't errors.
 func (b *Boxer) SyncBox(ctx context.Context, sb *sandtypes.Box) error {
 	ctx = sandboxlog.WithSandboxID(ctx, sb.ID)
+	var errs []error
 	fi, err := os.Stat(sb.SandboxWorkDir)
 	if err != nil || !fi.IsDir() {
 		slog.ErrorContext(ctx, "Boxer.Sync SandboxWorkDir stat", "workdir", sb.SandboxWorkDir, "fi", fi, "error", err)
 		sb.SandboxWorkDirError = "NO CLONE DIR"
+		b.publish(ctx, sandtypes.SandboxSyncError, sb, "sandbox clone directory is missing: "+sb.SandboxWorkDir)
+		errs = append(errs, fmt.Errorf("sandbox %s: clone directory %s is missing", sb.ID, sb.SandboxWorkDir))
 	}
-
-	return nil
+	if sb.SandboxContainerError != "" {
+		errs = append(errs, fmt.Errorf("sandbox %s: could not inspect container %s", sb.ID, sb.ContainerID))
+	}
+	b.syncResourceWarning(ctx, sb)
+	return errors.Join(errs...)
 }
 
 func (b *Boxer) SyncHostGitMirror(ctx context.Context, sb *sandtypes.Box) (string, error) {
@@ -228,6 +406,17 @@ func (b *Boxer) SyncHostGitMirror(ctx context.Context, sb *sandtypes.Box) (strin
 	return mirrorDir, nil
 }
 
+// ResyncWorkspace copies files changed on the host since the last sync into
+// sb's clone, skipping files that were also changed inside the sandbox.
+func (b *Boxer) ResyncWorkspace(ctx context.Context, sb *sandtypes.Box) (*sandtypes.ResyncResult, error) {
+	ctx = sandboxlog.WithSandboxID(ctx, sb.ID)
+	defer b.lockSandbox(sb.ID)()
+	if sb.HostOriginDir == "" {
+		return nil, fmt.Errorf("sandbox %s has no host origin directory to resync from", sb.ID)
+	}
+	return cloning.NewWorkspaceResync(sb.SandboxWorkDir).Resync(ctx, sb.HostOriginDir, sb.CreatedAt)
+}
+
 func (b *Boxer) hydrateMounts(sb *sandtypes.Box, hostGitMirrorDir string) {
 	pathRegistry := cloning.NewStandardPathRegistry(sb.SandboxWorkDir)
 	baseConfig := containerruntime.NewBaseContainerConfiguration()
@@ -240,6 +429,8 @@ func (b *Boxer) hydrateMounts(sb *sandtypes.Box, hostGitMirrorDir string) {
 		Username:          sb.Username,
 		Uid:               sb.Uid,
 		SharedCacheMounts: sb.SharedCacheMounts,
+		NoDotfiles:        sb.NoDotfiles,
+		NoSSH:             sb.NoSSH,
 	})
 }
 
@@ -256,12 +447,38 @@ type NewSandboxOpts struct {
 	Username       string
 	Uid            string
 	AllowedDomains []string
+	Labels         map[string]string
+	Shell          string
+	Network        string
 	Mounts         []string
 	CloneMounts    []string
 	SharedCaches   sandtypes.SharedCacheConfig
 	CPUs           int
 	Memory         int
 	LocalDomain    string
+	// CloneRoot, if set, is an absolute directory to create the sandbox's
+	// clone in instead of the default clone root.
+	CloneRoot string
+	// FromBranch, if set, is a local branch of HostWorkDir's repository to
+	// check out in the clone instead of copying the host's working tree as is.
+	FromBranch string
+	// NoDotfiles leaves the host user's dotfiles out of the sandbox.
+	NoDotfiles bool
+	// NoSSH skips the sandbox's ssh keys and sshd; it is shelled into by exec.
+	NoSSH bool
+	// AllowEmulation lets the sandbox run an image with no variant for the
+	// host's architecture, under emulation.
+	AllowEmulation bool
+	// IgnoreOnCreateErrors makes a failing oncreate script a warning.
+	IgnoreOnCreateErrors bool
+	// Publish maps container ports to host ports; see [hostops.ParsePublishPort].
+	Publish []string
+	// DNS configures the container's resolver.
+	DNS sandtypes.DNSConfig
+	// Progress, if set, receives user-facing warnings about the new sandbox,
+	// and its clone's messages at MessageLevel and above.
+	Progress     io.Writer
+	MessageLevel hostops.MessageLevel
 }
 
 // NewSandbox creates a new sandbox based on a clone of hostWorkDir.
@@ -269,10 +486,43 @@ type NewSandboxOpts struct {
 // commands can keep using a stable copy even if the original file changes.
 func (sb *Boxer) NewSandbox(ctx context.Context, opts NewSandboxOpts) (*sandtypes.Box, error) {
 	ctx = sandboxlog.WithSandboxID(ctx, opts.ID)
+	if opts.Progress != nil {
+		ctx = hostops.WithMessenger(ctx, hostops.NewLeveledTerminalMessenger(opts.Progress, opts.MessageLevel))
+	}
 	slog.InfoContext(ctx, "Boxer.NewSandbox", "hostWorkDir", opts.HostWorkDir, "id", opts.ID, "name", opts.Name, "agentType", opts.AgentType)
+	if !sandtypes.IsValidSandboxID(opts.ID) {
+		return nil, fmt.Errorf("sandbox ID %q is invalid: must be 1-63 lowercase alphanumeric characters or hyphens", opts.ID)
+	}
+	if opts.Name != "" {
+		if err := sandtypes.ValidateSandboxName(opts.Name); err != nil {
+			return nil, err
+		}
+	}
+	if opts.CloneRoot != "" && !filepath.IsAbs(opts.CloneRoot) {
+		return nil, fmt.Errorf("clone root %q must be an absolute path", opts.CloneRoot)
+	}
+	if err := hostops.ValidatePublish(opts.Publish); err != nil {
+		return nil, err
+	}
+	if err := opts.DNS.Validate(); err != nil {
+		return nil, err
+	}
+	if opts.DNS.Disabled && len(opts.AllowedDomains) > 0 {
+		return nil, fmt.Errorf("dns can't be disabled for a sandbox with allowed domains, which resolves names through its domain filter")
+	}
+	defer sb.lockSandbox(opts.ID)()
+
+	// Check under the lock so a concurrent create with the same ID fails here,
+	// before it can touch the winner's clone directory.
+	if _, err := sb.queries.GetSandboxByID(ctx, opts.ID); err == nil {
+		return nil, fmt.Errorf("sandbox %s already exists", opts.ID)
+	} else if err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to check for existing sandbox %s: %w", opts.ID, err)
+	}
 	if opts.ProfileName == "" {
 		opts.ProfileName = sandtypes.DefaultProfileName
 	}
+	opts.LocalDomain = runtimedeps.NormalizeDNSDomain(opts.LocalDomain)
 
 	// Get agent configuration from registry
 	agentConfig := sb.AgentRegistry.Get(opts.AgentType)
@@ -285,6 +535,17 @@ func (sb *Boxer) NewSandbox(ctx context.Context, opts NewSandboxOpts) (*sandtype
 		return nil, err
 	}
 
+	// From here on a failure may leave a partial clone and git remote behind,
+	// which would get in the way of retrying with the same ID. Only what this
+	// create made is removed: if the clone directory was already there,
+	// Prepare fails without touching it and so does the rollback.
+	created, ownsSandboxRoot := false, true
+	defer func() {
+		if !created && ownsSandboxRoot {
+			sb.discardPartialClone(ctx, opts.ID, opts.Name, opts.HostWorkDir, sb.sandboxRoot(opts.CloneRoot, opts.ID))
+		}
+	}()
+
 	// Prepare workspace
 	artifacts, err := agentConfig.Preparation.Prepare(ctx, cloning.CloneRequest{
 		ID:                opts.ID,
@@ -296,22 +557,36 @@ func (sb *Boxer) NewSandbox(ctx context.Context, opts NewSandboxOpts) (*sandtype
 		Username:          opts.Username,
 		Uid:               opts.Uid,
 		SharedCacheMounts: sharedCacheMounts,
+		CloneRoot:         opts.CloneRoot,
+		FromBranch:        opts.FromBranch,
+		NoDotfiles:        opts.NoDotfiles,
 	})
 	if err != nil {
+		ownsSandboxRoot = !errors.Is(err, cloning.ErrSandboxRootExists)
 		return nil, err
 	}
+	sb.metrics.recordClone(artifacts.CopyOnWrite)
+	if !artifacts.CopyOnWrite && opts.Progress != nil {
+		fmt.Fprintf(opts.Progress, "[sand] warning: copy-on-write clone of %s is unavailable; sandbox %s uses a full copy and extra disk space\n", opts.HostWorkDir, opts.Name)
+	}
 
 	// Get mounts and hooks from configuration
-	mounts := agentConfig.Configuration.GetMounts(runtimeArtifactsFromClone(artifacts))
+	runtimeArtifacts := runtimeArtifactsFromClone(artifacts)
+	runtimeArtifacts.NoDotfiles = opts.NoDotfiles
+	runtimeArtifacts.NoSSH = opts.NoSSH
+	mounts := agentConfig.Configuration.GetMounts(runtimeArtifacts)
 	mountRequests, err := sb.prepareMountRequests(ctx, artifacts.PathRegistry, opts.Mounts, opts.CloneMounts)
 	if err != nil {
 		return nil, err
 	}
 
-	// TODO: move this to .Hydrate? Or make it a startup hook?
-	sshKeysMountSpec, result, err, shouldReturn := sb.generateSSHKeysMountSpec(ctx, opts, artifacts)
-	if shouldReturn {
-		return result, err
+	if !opts.NoSSH {
+		// TODO: move this to .Hydrate? Or make it a startup hook?
+		sshKeysMountSpec, result, err, shouldReturn := sb.generateSSHKeysMountSpec(ctx, opts, artifacts)
+		if shouldReturn {
+			return result, err
+		}
+		mounts = append(mounts, sshKeysMountSpec)
 	}
 
 	// hostWorkDir may not be the same as the git root - should we save both here instead of
@@ -328,6 +603,13 @@ func (sb *Boxer) NewSandbox(ctx context.Context, opts NewSandboxOpts) (*sandtype
 		gitBranch = sb.GitOps.Branch(ctx, hostWorkDir)
 		gitCommit = sb.GitOps.Commit(ctx, hostWorkDir)
 		gitIsDirty = sb.GitOps.IsDirty(ctx, hostWorkDir)
+		if opts.FromBranch != "" {
+			// The clone starts from the branch, not the host's checkout.
+			cloneDir := artifacts.PathRegistry.WorkDir()
+			gitBranch = sb.GitOps.Branch(ctx, cloneDir)
+			gitCommit = sb.GitOps.Commit(ctx, cloneDir)
+			gitIsDirty = false
+		}
 		if artifacts.HostGitMirrorDir != "" {
 			mirror := cloning.NewGitMirror(filepath.Join(sb.appRoot, "git-mirrors"), sb.GitOps, sb.FileOps)
 			if err := mirror.WriteSnapshotRef(ctx, artifacts.HostGitMirrorDir, opts.ID, gitCommit); err != nil {
@@ -338,24 +620,34 @@ func (sb *Boxer) NewSandbox(ctx context.Context, opts NewSandboxOpts) (*sandtype
 	}
 
 	ret := &sandtypes.Box{
-		ID:                opts.ID,
-		Name:              opts.Name,
-		State:             "active",
-		AgentType:         opts.AgentType,
-		ProfileName:       opts.ProfileName,
-		HostOriginDir:     hostWorkDir,
-		SandboxWorkDir:    artifacts.SandboxWorkDir,
-		ImageName:         opts.ImageName,
-		DNSDomain:         opts.LocalDomain,
-		EnvFile:           envFile,
-		AllowedDomains:    opts.AllowedDomains,
-		MountRequests:     mountRequests,
-		SharedCacheMounts: sharedCacheMounts,
-		Mounts:            append(mounts, sshKeysMountSpec),
-		CPUs:              opts.CPUs,
-		MemoryMB:          opts.Memory,
-		Username:          opts.Username,
-		Uid:               opts.Uid,
+		ID:                   opts.ID,
+		Name:                 opts.Name,
+		State:                "active",
+		AgentType:            opts.AgentType,
+		ProfileName:          opts.ProfileName,
+		HostOriginDir:        hostWorkDir,
+		SandboxWorkDir:       artifacts.SandboxWorkDir,
+		ImageName:            opts.ImageName,
+		ImageDigest:          sb.recordedImageDigest(ctx, opts.ImageName),
+		DNSDomain:            opts.LocalDomain,
+		EnvFile:              envFile,
+		AllowedDomains:       opts.AllowedDomains,
+		Labels:               opts.Labels,
+		Shell:                opts.Shell,
+		Network:              opts.Network,
+		MountRequests:        mountRequests,
+		SharedCacheMounts:    sharedCacheMounts,
+		NoDotfiles:           opts.NoDotfiles,
+		NoSSH:                opts.NoSSH,
+		AllowEmulation:       opts.AllowEmulation,
+		IgnoreOnCreateErrors: opts.IgnoreOnCreateErrors,
+		Publish:              opts.Publish,
+		DNS:                  opts.DNS,
+		Mounts:               mounts,
+		CPUs:                 opts.CPUs,
+		MemoryMB:             opts.Memory,
+		Username:             opts.Username,
+		Uid:                  opts.Uid,
 		OriginalGitDetails: &sandtypes.GitDetails{
 			RemoteOrigin: gitRemote,
 			Branch:       gitBranch,
@@ -364,9 +656,11 @@ func (sb *Boxer) NewSandbox(ctx context.Context, opts NewSandboxOpts) (*sandtype
 		},
 	}
 
-	if err := sb.SaveSandbox(ctx, ret); err != nil {
+	if err := sb.saveSandbox(ctx, ret); err != nil {
 		return nil, err
 	}
+	created = true
+	sb.publish(ctx, sandtypes.SandboxCreated, ret, "")
 
 	return ret, nil
 }
@@ -392,53 +686,76 @@ func (sb *Boxer) generateSSHKeysMountSpec(ctx context.Context, opts NewSandboxOp
 }
 
 func sandboxSSHHostname(name, domain string) string {
-	domain = strings.Trim(domain, ".")
-	if domain == "" {
-		domain = runtimedeps.DefaultDNSDomain
-	}
-	return name + "." + domain
+	return name + "." + runtimedeps.NormalizeDNSDomain(domain)
 }
 
+// saveSSHKeys writes keys into keysDir for the container's sshd. Each file is
+// read back and parsed after it is written, so a short or corrupt write fails
+// here, naming the bad file, rather than later as an opaque "start sshd" hook
+// failure.
 func (sb *Boxer) saveSSHKeys(keysDir string, keys *sshimmer.Keys) error {
 	if err := sb.FileOps.MkdirAll(keysDir, 0o750); err != nil {
 		return err
 	}
-	hostPrivateKeyFile, err := sb.FileOps.Create(filepath.Join(keysDir, "ssh_host_key"))
-	if err != nil {
-		return err
-	}
-	defer hostPrivateKeyFile.Close()
-	if _, err := hostPrivateKeyFile.Write(keys.HostKey); err != nil {
-		return err
+	for _, f := range []struct {
+		name   string
+		data   []byte
+		verify func([]byte) error
+	}{
+		{"ssh_host_key", keys.HostKey, verifySSHPrivateKey},
+		{"ssh_host_key.pub", keys.HostKeyPub, verifySSHPublicKey},
+		{"ssh_host_key.pub-cert", keys.HostKeyCert, verifySSHCertificate},
+		{"user_ca.pub", keys.UserCAPub, verifySSHPublicKey},
+	} {
+		if err := sb.writeSSHKeyFile(filepath.Join(keysDir, f.name), f.data, f.verify); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	hostPublicKeyFile, err := sb.FileOps.Create(filepath.Join(keysDir, "ssh_host_key.pub"))
+// writeSSHKeyFile writes data to path, then reads the file back and checks
+// its contents with verify.
+func (sb *Boxer) writeSSHKeyFile(path string, data []byte, verify func([]byte) error) error {
+	f, err := sb.FileOps.Create(path)
 	if err != nil {
 		return err
 	}
-	defer hostPublicKeyFile.Close()
-	if _, err := hostPublicKeyFile.Write(keys.HostKeyPub); err != nil {
+	defer f.Close()
+	if _, err := f.Write(data); err != nil {
 		return err
 	}
-
-	hostKeyCertFile, err := sb.FileOps.Create(filepath.Join(keysDir, "ssh_host_key.pub-cert"))
-	if err != nil {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
 		return err
 	}
-	defer hostKeyCertFile.Close()
-	if _, err := hostKeyCertFile.Write(keys.HostKeyCert); err != nil {
-		return err
+	written, err := io.ReadAll(f)
+	if err != nil {
+		return fmt.Errorf("read back ssh key file %s: %w", path, err)
 	}
+	if err := verify(written); err != nil {
+		return fmt.Errorf("ssh key file %s is corrupt: %w", path, err)
+	}
+	return f.Close()
+}
+
+func verifySSHPrivateKey(data []byte) error {
+	_, err := ssh.ParsePrivateKey(data)
+	return err
+}
 
-	userCAFile, err := sb.FileOps.Create(filepath.Join(keysDir, "user_ca.pub"))
+func verifySSHPublicKey(data []byte) error {
+	_, _, _, _, err := ssh.ParseAuthorizedKey(data)
+	return err
+}
+
+func verifySSHCertificate(data []byte) error {
+	key, _, _, _, err := ssh.ParseAuthorizedKey(data)
 	if err != nil {
 		return err
 	}
-	defer userCAFile.Close()
-	if _, err := userCAFile.Write(keys.UserCAPub); err != nil {
-		return err
+	if _, ok := key.(*ssh.Certificate); !ok {
+		return fmt.Errorf("got a %s public key, want a certificate", key.Type())
 	}
-
 	return nil
 }
 
@@ -462,7 +779,7 @@ func (sb *Boxer) List(ctx context.Context) ([]sandtypes.Box, error) {
 	boxes := make([]sandtypes.Box, len(sandboxes))
 	for i, s := range sandboxes {
 		box := sb.sandboxFromDB(&s)
-		ctr, err := sb.GetContainer(ctx, box.ContainerID)
+		ctr, err := sb.listContainer(ctx, box.ContainerID)
 		if err != nil {
 			box.SandboxContainerError = containerGetErrorMsg
 		}
@@ -473,6 +790,22 @@ func (sb *Boxer) List(ctx context.Context) ([]sandtypes.Box, error) {
 	return boxes, nil
 }
 
+// ListSynced syncs every active sandbox and returns them all with their
+// containers, current git details, and any SandboxWorkDirError or
+// SandboxContainerError, so that a client refreshing its view needs neither a
+// Get per sandbox nor a separate SyncSandboxes. Only a failure to sync at all
+// is returned as an error; the rest are reported through the boxes.
+func (sb *Boxer) ListSynced(ctx context.Context) ([]sandtypes.Box, error) {
+	boxes, err := sb.SyncSandboxes(ctx, SyncOpts{KeepGoing: true, All: true})
+	if err != nil && len(boxes) == 0 {
+		return nil, err
+	}
+	for i := range boxes {
+		boxes[i].CurrentGitDetails = sb.getCurrentGitDetails(ctx, &boxes[i])
+	}
+	return boxes, nil
+}
+
 func (sb *Boxer) Get(ctx context.Context, name string) (*sandtypes.Box, error) {
 	slog.InfoContext(ctx, "Boxer.Get", "name", name)
 	sandbox, err := sb.queries.GetActiveSandboxByName(ctx, name)
@@ -484,8 +817,10 @@ func (sb *Boxer) Get(ctx context.Context, name string) (*sandtypes.Box, error) {
 	}
 
 	box := sb.sandboxFromDB(&sandbox)
+	// A container the runtime doesn't know is left nil with no error, so that
+	// callers can tell a missing container from one that couldn't be inspected.
 	ctr, err := sb.GetContainer(ctx, box.ContainerID)
-	if err != nil {
+	if err != nil && !hostops.IsContainerNotFound(err) {
 		box.SandboxContainerError = containerGetErrorMsg
 	}
 	box.Container = ctr
@@ -507,7 +842,7 @@ func (sb *Boxer) GetByID(ctx context.Context, id string) (*sandtypes.Box, error)
 	}
 	box := sb.sandboxFromDB(&sandbox)
 	ctr, err := sb.GetContainer(ctx, box.ContainerID)
-	if err != nil {
+	if err != nil && !hostops.IsContainerNotFound(err) {
 		box.SandboxContainerError = containerGetErrorMsg
 	}
 	box.Container = ctr
@@ -515,17 +850,6 @@ func (sb *Boxer) GetByID(ctx context.Context, id string) (*sandtypes.Box, error)
 	return box, nil
 }
 
-// sandboxNameRe enforces DNS label rules: 1-63 chars, lowercase alnum or hyphens,
-// not starting or ending with a hyphen.
-var sandboxNameRe = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
-
-func validateSandboxName(name string) error {
-	if !sandboxNameRe.MatchString(name) {
-		return fmt.Errorf("sandbox name %q is invalid: must be 1-63 lowercase alphanumeric characters or hyphens, not starting or ending with a hyphen", name)
-	}
-	return nil
-}
-
 // RenameSandbox renames a stopped sandbox. The container is deleted and recreated
 // under the new name (the container ID in apple's runtime IS the sandbox name).
 // The git remote on the host is renamed best-effort; failures are logged but do not
@@ -543,18 +867,31 @@ func (sb *Boxer) RenameSandbox(ctx context.Context, oldName, newName string, pro
 	if sbox.Container != nil && sbox.Container.Status.State == "running" {
 		return nil, fmt.Errorf("sandbox %s is running; stop it before renaming", oldName)
 	}
-	if err := validateSandboxName(newName); err != nil {
+	if err := sandtypes.ValidateSandboxName(newName); err != nil {
 		return nil, err
 	}
+	if newName == oldName {
+		return nil, fmt.Errorf("sandbox %s is already named %s", oldName, newName)
+	}
+	// Check for a collision before anything destructive happens; the unique
+	// name index would otherwise only reject the rename after the old
+	// container has been deleted.
+	if _, err := sb.queries.GetActiveSandboxByName(ctx, newName); err == nil {
+		return nil, fmt.Errorf("sandbox named %s already exists", newName)
+	} else if err != sql.ErrNoRows {
+		return nil, fmt.Errorf("check sandbox name %s: %w", newName, err)
+	}
 
 	enableSSHAgent := sbox.Container != nil && sbox.Container.Configuration.SSH
 	oldRemoteName := sandboxRemoteName(sbox)
-	keys, err := sb.SSHim.NewKeys(ctx, sandboxSSHHostname(newName, sbox.DNSDomain), sbox.Username)
-	if err != nil {
-		return nil, fmt.Errorf("generate ssh keys after rename: %w", err)
-	}
-	if err := sb.saveSSHKeys(cloning.NewStandardPathRegistry(sbox.SandboxWorkDir).SSHKeysDir(), keys); err != nil {
-		return nil, fmt.Errorf("save ssh keys after rename: %w", err)
+	if !sbox.NoSSH {
+		keys, err := sb.SSHim.NewKeys(ctx, sandboxSSHHostname(newName, sbox.DNSDomain), sbox.Username)
+		if err != nil {
+			return nil, fmt.Errorf("generate ssh keys after rename: %w", err)
+		}
+		if err := sb.saveSSHKeys(cloning.NewStandardPathRegistry(sbox.SandboxWorkDir).SSHKeysDir(), keys); err != nil {
+			return nil, fmt.Errorf("save ssh keys after rename: %w", err)
+		}
 	}
 
 	if sbox.ContainerID != "" {
@@ -591,12 +928,15 @@ func (sb *Boxer) RenameSandbox(ctx context.Context, oldName, newName string, pro
 
 func (sb *Boxer) SoftDelete(ctx context.Context, sbox *sandtypes.Box) error {
 	ctx = sandboxlog.WithSandboxID(ctx, sbox.ID)
+	defer sb.lockSandbox(sbox.ID)()
 	slog.InfoContext(ctx, "Boxer.SoftDelete", "id", sbox.ID, "name", sbox.Name)
 
+	sb.runStopHooks(ctx, sbox)
 	out, err := sb.ContainerService.Stop(ctx, nil, sbox.ContainerID)
 	if err != nil {
 		slog.ErrorContext(ctx, "Boxer Containers.Stop", "error", err, "out", out)
 	}
+	sb.killHostProcesses(ctx, sbox)
 
 	out, err = sb.ContainerService.Delete(ctx, nil, sbox.ContainerID)
 	if err != nil {
@@ -606,6 +946,11 @@ func (sb *Boxer) SoftDelete(ctx context.Context, sbox *sandtypes.Box) error {
 	if err := sb.GitOps.RemoveRemote(ctx, sbox.HostOriginDir, cloning.ClonedWorkDirGitRemotePrefix+sandboxRemoteName(sbox)); err != nil {
 		slog.ErrorContext(ctx, "Boxer Containers.Delete failed to remove git remote", "error", err)
 	}
+	if sb.SSHim != nil && sbox.Name != "" {
+		if err := sb.SSHim.RemoveHost(ctx, sandboxSSHHostname(sbox.Name, sbox.DNSDomain)); err != nil {
+			slog.ErrorContext(ctx, "Boxer.SoftDelete failed to remove ssh host entries", "error", err)
+		}
+	}
 
 	trashWorkDir, err := sb.moveSandboxToTrash(ctx, sbox)
 	if err != nil {
@@ -618,6 +963,7 @@ func (sb *Boxer) SoftDelete(ctx context.Context, sbox *sandtypes.Box) error {
 	}); err != nil {
 		return fmt.Errorf("failed to mark sandbox %s deleted in database: %w", sbox.ID, err)
 	}
+	sb.publish(ctx, sandtypes.SandboxRemoved, sbox, "")
 
 	return nil
 }
@@ -637,6 +983,7 @@ func (sb *Boxer) ListDeleted(ctx context.Context) ([]sandtypes.Box, error) {
 func (sb *Boxer) Expunge(ctx context.Context, id string) error {
 	ctx = sandboxlog.WithSandboxID(ctx, id)
 	slog.InfoContext(ctx, "Boxer.Expunge", "id", id)
+	defer sb.lockSandbox(id)()
 
 	sandbox, err := sb.queries.GetSandboxByID(ctx, id)
 	if err == sql.ErrNoRows {
@@ -657,6 +1004,7 @@ func (sb *Boxer) Expunge(ctx context.Context, id string) error {
 	if err := sb.queries.DeleteSandbox(ctx, id); err != nil {
 		return fmt.Errorf("delete sandbox %s from database: %w", id, err)
 	}
+	sb.forgetSandboxLock(id)
 	return nil
 }
 
@@ -737,13 +1085,19 @@ func (sb *Boxer) Recover(ctx context.Context, id string) (*sandtypes.Box, error)
 
 	sbox.Name = name
 	sbox.ContainerBootstrapped = false
-	sb.hydrateMounts(sbox, "")
-	keys, err := sb.SSHim.NewKeys(ctx, sandboxSSHHostname(name, sbox.DNSDomain), sbox.Username)
-	if err != nil {
-		return nil, rollback(fmt.Errorf("generate ssh keys for recovered sandbox: %w", err))
+	sbox.StartHooksRan = false
+	if len(sbox.Mounts) == 0 {
+		// Sandboxes saved before mounts were persisted fall back to the defaults.
+		sb.hydrateMounts(sbox, "")
 	}
-	if err := sb.saveSSHKeys(cloning.NewStandardPathRegistry(sbox.SandboxWorkDir).SSHKeysDir(), keys); err != nil {
-		return nil, rollback(fmt.Errorf("save ssh keys for recovered sandbox: %w", err))
+	if !sbox.NoSSH {
+		keys, err := sb.SSHim.NewKeys(ctx, sandboxSSHHostname(name, sbox.DNSDomain), sbox.Username)
+		if err != nil {
+			return nil, rollback(fmt.Errorf("generate ssh keys for recovered sandbox: %w", err))
+		}
+		if err := sb.saveSSHKeys(cloning.NewStandardPathRegistry(sbox.SandboxWorkDir).SSHKeysDir(), keys); err != nil {
+			return nil, rollback(fmt.Errorf("save ssh keys for recovered sandbox: %w", err))
+		}
 	}
 	if err := sb.newLifecycleService().CreateContainer(ctx, sbox, false); err != nil {
 		return nil, rollback(err)
@@ -774,6 +1128,25 @@ func (sb *Boxer) Recover(ctx context.Context, id string) (*sandtypes.Box, error)
 	return sbox, nil
 }
 
+// NewSandboxName generates a friendly name that no active sandbox uses.
+func (sb *Boxer) NewSandboxName(ctx context.Context) (string, error) {
+	return sandtypes.NewSandboxName(func(name string) (bool, error) {
+		return sb.sandboxNameTaken(ctx, name)
+	})
+}
+
+// sandboxNameTaken reports whether an active sandbox is named name.
+func (sb *Boxer) sandboxNameTaken(ctx context.Context, name string) (bool, error) {
+	_, err := sb.queries.GetActiveSandboxByName(ctx, name)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check sandbox name: %w", err)
+	}
+	return true, nil
+}
+
 func (sb *Boxer) recoveredSandboxName(ctx context.Context, originalName, id string) (string, error) {
 	if existing, err := sb.queries.GetActiveSandboxByName(ctx, originalName); err == sql.ErrNoRows {
 		return originalName, nil
@@ -809,7 +1182,7 @@ func (sb *Boxer) moveDirectory(ctx context.Context, src, dst string) error {
 	if err := sb.FileOps.Rename(src, dst); err == nil {
 		return nil
 	}
-	if err := sb.FileOps.Copy(ctx, src, dst); err != nil {
+	if _, err := sb.FileOps.Copy(ctx, src, dst, hostops.CopyOpts{}); err != nil {
 		_ = sb.FileOps.RemoveAll(dst)
 		return fmt.Errorf("copy %s to %s: %w", src, dst, err)
 	}
@@ -840,7 +1213,7 @@ func (sb *Boxer) moveSandboxToTrash(ctx context.Context, sbox *sandtypes.Box) (s
 	} else {
 		slog.InfoContext(ctx, "Boxer.SoftDelete rename to trash failed; falling back to copy", "from", sbox.SandboxWorkDir, "to", trashWorkDir, "error", err)
 	}
-	if err := sb.FileOps.Copy(ctx, sbox.SandboxWorkDir, trashWorkDir); err != nil {
+	if _, err := sb.FileOps.Copy(ctx, sbox.SandboxWorkDir, trashWorkDir, hostops.CopyOpts{}); err != nil {
 		return "", fmt.Errorf("copy sandbox %s to trash: %w", sbox.ID, err)
 	}
 	if err := sb.FileOps.RemoveAll(sbox.SandboxWorkDir); err != nil {
@@ -895,33 +1268,49 @@ func (sb *Boxer) sandboxFromDB(s *db.Sandbox) *sandtypes.Box {
 		ProfileName:           profileName,
 		ContainerID:           fromNullString(s.ContainerID),
 		ContainerBootstrapped: s.ContainerBootstrapped,
+		StartHooksRan:         s.StartHooksRan,
 		HostOriginDir:         s.HostOriginDir,
 		SandboxWorkDir:        s.SandboxWorkDir,
 		ImageName:             s.ImageName,
 		DNSDomain:             fromNullString(s.DnsDomain),
 		EnvFile:               fromNullString(s.EnvFile),
 		AllowedDomains:        domainsFromNullString(s.AllowedDomains),
+		Labels:                labelsFromNullString(s.Labels),
+		Shell:                 fromNullString(s.Shell),
+		Network:               fromNullString(s.Network),
+		NoDotfiles:            s.NoDotfiles,
+		NoSSH:                 s.NoSsh,
+		AllowEmulation:        s.AllowEmulation,
+		IgnoreOnCreateErrors:  s.IgnoreOncreateErrors,
+		Publish:               publishFromNullString(s.Publish),
+		DNS:                   dnsConfigFromNullString(s.DnsConfig),
+		ImageDigest:           fromNullString(s.ImageDigest),
 		MountRequests:         mountRequests,
+		Mounts:                mountsFromNullString(s.Mounts),
 		OriginalGitDetails: &sandtypes.GitDetails{
 			RemoteOrigin: fromNullString(s.OriginalGitOrigin),
 			Branch:       fromNullString(s.OriginalGitBranch),
 			Commit:       fromNullString(s.OriginalGitCommit),
 			IsDirty:      s.OriginalGitIsDirty,
 		},
-		CPUs:     fromNullInt(s.Cpu),
-		MemoryMB: fromNullInt(s.MemoryMb),
-		Username: fromNullString(s.DefaultUsername),
-		Uid:      fromNullString(s.DefaultUid),
-		DeletedAt: func() time.Time {
-			if s.DeletedAt.Valid {
-				return s.DeletedAt.Time
-			}
-			return time.Time{}
-		}(),
+		CPUs:         fromNullInt(s.Cpu),
+		MemoryMB:     fromNullInt(s.MemoryMb),
+		Username:     fromNullString(s.DefaultUsername),
+		Uid:          fromNullString(s.DefaultUid),
+		DeletedAt:    fromNullTime(s.DeletedAt),
+		CreatedAt:    fromNullTime(s.CreatedAt),
+		LastUsedAt:   fromNullTime(s.LastUsedAt),
 		TrashWorkDir: fromNullString(s.TrashWorkDir),
 	}
 }
 
+func fromNullTime(nt sql.NullTime) time.Time {
+	if nt.Valid {
+		return nt.Time
+	}
+	return time.Time{}
+}
+
 func toNullString(s string) sql.NullString {
 	return sql.NullString{String: s, Valid: s != ""}
 }
@@ -957,6 +1346,102 @@ func mountRequestsFromNullString(ns sql.NullString) []sandtypes.MountRequest {
 	return requests
 }
 
+func mountsToNullString(mounts []sandtypes.MountSpec) sql.NullString {
+	if len(mounts) == 0 {
+		return sql.NullString{}
+	}
+	data, err := json.Marshal(mounts)
+	if err != nil {
+		slog.Warn("failed to marshal mounts", "error", err)
+		return sql.NullString{}
+	}
+	return sql.NullString{String: string(data), Valid: true}
+}
+
+func mountsFromNullString(ns sql.NullString) []sandtypes.MountSpec {
+	if !ns.Valid || ns.String == "" {
+		return nil
+	}
+	var mounts []sandtypes.MountSpec
+	if err := json.Unmarshal([]byte(ns.String), &mounts); err != nil {
+		slog.Warn("failed to unmarshal mounts", "error", err)
+		return nil
+	}
+	return mounts
+}
+
+func labelsToNullString(labels map[string]string) sql.NullString {
+	if len(labels) == 0 {
+		return sql.NullString{}
+	}
+	data, err := json.Marshal(labels)
+	if err != nil {
+		slog.Warn("failed to marshal labels", "error", err)
+		return sql.NullString{}
+	}
+	return sql.NullString{String: string(data), Valid: true}
+}
+
+func labelsFromNullString(ns sql.NullString) map[string]string {
+	if !ns.Valid || ns.String == "" {
+		return nil
+	}
+	var labels map[string]string
+	if err := json.Unmarshal([]byte(ns.String), &labels); err != nil {
+		slog.Warn("failed to unmarshal labels", "error", err)
+		return nil
+	}
+	return labels
+}
+
+func publishToNullString(specs []string) sql.NullString {
+	if len(specs) == 0 {
+		return sql.NullString{}
+	}
+	data, err := json.Marshal(specs)
+	if err != nil {
+		slog.Warn("failed to marshal publish specs", "error", err)
+		return sql.NullString{}
+	}
+	return sql.NullString{String: string(data), Valid: true}
+}
+
+func publishFromNullString(ns sql.NullString) []string {
+	if !ns.Valid || ns.String == "" {
+		return nil
+	}
+	var specs []string
+	if err := json.Unmarshal([]byte(ns.String), &specs); err != nil {
+		slog.Warn("failed to unmarshal publish specs", "error", err)
+		return nil
+	}
+	return specs
+}
+
+func dnsConfigToNullString(config sandtypes.DNSConfig) sql.NullString {
+	if config.IsZero() {
+		return sql.NullString{}
+	}
+	data, err := json.Marshal(config)
+	if err != nil {
+		slog.Warn("failed to marshal dns config", "error", err)
+		return sql.NullString{}
+	}
+	return sql.NullString{String: string(data), Valid: true}
+}
+
+func dnsConfigFromNullString(ns sql.NullString) sandtypes.DNSConfig {
+	var config sandtypes.DNSConfig
+	if !ns.Valid || ns.String == "" {
+		return config
+	}
+	if err := json.Unmarshal([]byte(ns.String), &config); err != nil {
+		slog.Warn("failed to unmarshal dns config", "error", err)
+		return sandtypes.DNSConfig{}
+	}
+	return config
+}
+
 func toNullInt(s int) sql.NullInt64 {
 	return sql.NullInt64{Int64: int64(s), Valid: true}
 }
@@ -1000,6 +1485,19 @@ func (sb *Boxer) getContainer(ctx context.Context, containerID string) (*sandtyp
 	return &ctrs[0], nil
 }
 
+// listContainer is getContainer for List and Sync, which may answer from the
+// inspect cache.
+func (sb *Boxer) listContainer(ctx context.Context, containerID string) (*sandtypes.Container, error) {
+	ctrs, err := sb.listInspect(ctx, containerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect container for sandbox %s: %w", containerID, err)
+	}
+	if len(ctrs) == 0 {
+		return nil, nil
+	}
+	return &ctrs[0], nil
+}
+
 func (sb *Boxer) GetContainer(ctx context.Context, containerID string) (*sandtypes.Container, error) {
 	ctr, err := sb.getContainer(ctx, containerID)
 	if err != nil {
@@ -1014,9 +1512,14 @@ func (sb *Boxer) GetContainer(ctx context.Context, containerID string) (*sandtyp
 func (sb *Boxer) GetContainerStats(ctx context.Context, containerID ...string) ([]sandtypes.ContainerStats, error) {
 	stats, err := sb.ContainerService.Stats(ctx, containerID...)
 	if err != nil {
+		// Fall back to reading /proc in each container before giving up.
+		slog.WarnContext(ctx, "Boxer.GetContainerStats", "error", err)
+		if fallback := sb.withProcStatsFallback(ctx, containerID, nil); len(fallback) > 0 {
+			return fallback, nil
+		}
 		return nil, err
 	}
-	return stats, nil
+	return sb.withProcStatsFallback(ctx, containerID, stats), nil
 }
 
 func (sb *Boxer) ensureSharedCacheMounts(cfg sandtypes.SharedCacheConfig, localDomain string) (sandtypes.SharedCacheMounts, error) {
@@ -1069,11 +1572,38 @@ func httpProxyCacheURL(localDomain string) string {
 	return "http://sand-http-cache." + strings.Trim(localDomain, ".") + ":3128"
 }
 
+// EnsureImageOpts controls when EnsureImage pulls.
+type EnsureImageOpts struct {
+	// PullAlways pulls the image even when a local image with the same
+	// reference is already present.
+	PullAlways bool
+	// DockerfileDir, if set, builds the image from the Dockerfile in this
+	// host directory and tags it with the image name instead of pulling it.
+	DockerfileDir string
+	// BuildArgs are passed to the build when DockerfileDir is set.
+	BuildArgs map[string]string
+}
+
 // EnsureImage makes sure the requested container image is present locally and up to date,
 // pulling it if required. Progress messages are written to w.
-func (sb *Boxer) EnsureImage(ctx context.Context, imageName string, w io.Writer) error {
-	slog.InfoContext(ctx, "Boxer.EnsureImage", "imageName", imageName)
+//
+// imageName may be pinned by digest (repo@sha256:...). A pinned image is only
+// reused if the local copy has exactly that digest, and is verified again after
+// any pull.
+//
+// Calls for the same imageName run one at a time, so concurrent requests for a
+// missing image pull it once; see lockImage.
+func (sb *Boxer) EnsureImage(ctx context.Context, imageName string, opts EnsureImageOpts, w io.Writer) error {
+	slog.InfoContext(ctx, "Boxer.EnsureImage", "imageName", imageName, "pullAlways", opts.PullAlways, "dockerfileDir", opts.DockerfileDir)
 	progress := imageProgressSink(w)
+	unlock, err := sb.lockImage(ctx, imageName, progress)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if opts.DockerfileDir != "" {
+		return sb.buildImage(ctx, imageName, opts, w)
+	}
 
 	images, err := sb.ImageService.List(ctx)
 	if err != nil {
@@ -1083,6 +1613,23 @@ func (sb *Boxer) EnsureImage(ctx context.Context, imageName string, w io.Writer)
 		return fmt.Errorf("failed to list images: %w", err)
 	}
 
+	pinnedDigest := imageDigestFromRef(imageName)
+	if opts.PullAlways {
+		slog.InfoContext(ctx, "Boxer.EnsureImage", "status", "pull-always", "imageName", imageName)
+		return sb.pullAndVerifyImage(ctx, imageName, pinnedDigest, w)
+	}
+	if pinnedDigest != "" {
+		localDigest, err := sb.localImageDigest(ctx, imageName)
+		if err == nil && localDigest == pinnedDigest {
+			slog.InfoContext(ctx, "Boxer.EnsureImage", "status", "digest-match", "imageName", imageName)
+			return nil
+		}
+		if err == nil {
+			fmt.Fprintf(progress, "Local image digest %s doesn't match pinned digest, pulling %s\n", localDigest, imageName)
+		}
+		return sb.pullAndVerifyImage(ctx, imageName, pinnedDigest, w)
+	}
+
 	imagePresent := false
 	slog.InfoContext(ctx, "Boxer.EnsureImage", "image count", len(images))
 	for _, image := range images {
@@ -1118,6 +1665,59 @@ func (sb *Boxer) EnsureImage(ctx context.Context, imageName string, w io.Writer)
 	return nil
 }
 
+// buildImage builds imageName from opts.DockerfileDir, streaming the build
+// output to w. The build cache makes rebuilding an unchanged Dockerfile cheap,
+// so it always builds rather than reusing an image with the same tag.
+func (sb *Boxer) buildImage(ctx context.Context, imageName string, opts EnsureImageOpts, w io.Writer) error {
+	if info, err := os.Stat(opts.DockerfileDir); err != nil {
+		return fmt.Errorf("dockerfile dir: %w", err)
+	} else if !info.IsDir() {
+		return fmt.Errorf("dockerfile dir %s is not a directory", opts.DockerfileDir)
+	}
+	if w == nil {
+		w = io.Discard
+	}
+	fmt.Fprintf(w, "Building %s from %s\n", imageName, opts.DockerfileDir)
+	err := sb.ImageService.Build(ctx, &hostops.BuildImage{Tag: imageName, BuildArg: opts.BuildArgs}, opts.DockerfileDir, w)
+	if err != nil {
+		if runtimedeps.IsContainerSystemNotRunningError(err) {
+			return runtimedeps.ContainerSystemNotRunningError(err)
+		}
+		return fmt.Errorf("failed to build image %s: %w", imageName, err)
+	}
+	sb.recordFetchedImage(ctx, imageName)
+	return nil
+}
+
+// imageDigestFromRef returns the digest an image reference is pinned to
+// ("repo@sha256:..."), or "" for a tag-only reference.
+func imageDigestFromRef(imageName string) string {
+	_, digest, ok := strings.Cut(imageName, "@")
+	if !ok {
+		return ""
+	}
+	return digest
+}
+
+// pullAndVerifyImage pulls imageName and, when wantDigest is set, fails unless
+// the pulled image has that digest.
+func (sb *Boxer) pullAndVerifyImage(ctx context.Context, imageName, wantDigest string, w io.Writer) error {
+	if err := sb.pullImage(ctx, imageName, w); err != nil {
+		return err
+	}
+	if wantDigest == "" {
+		return nil
+	}
+	gotDigest, err := sb.localImageDigest(ctx, imageName)
+	if err != nil {
+		return fmt.Errorf("verifying digest of %s: %w", imageName, err)
+	}
+	if gotDigest != wantDigest {
+		return fmt.Errorf("image %s has digest %s after pull, want %s", imageName, gotDigest, wantDigest)
+	}
+	return nil
+}
+
 func (sb *Boxer) localImageDigest(ctx context.Context, imageName string) (string, error) {
 	imgs, err := sb.ImageService.Inspect(ctx, imageName)
 	if err != nil {
@@ -1129,13 +1729,29 @@ func (sb *Boxer) localImageDigest(ctx context.Context, imageName string) (string
 	return imgs[0].Index.Digest, nil
 }
 
+// recordedImageDigest returns the local digest of imageName to record on a
+// sandbox, or "" if it can't be determined. A missing digest only loses
+// provenance, so it does not fail creating or re-imaging a sandbox.
+func (sb *Boxer) recordedImageDigest(ctx context.Context, imageName string) string {
+	if sb.ImageService == nil || imageName == "" {
+		return ""
+	}
+	digest, err := sb.localImageDigest(ctx, imageName)
+	if err != nil {
+		slog.InfoContext(ctx, "Boxer.recordedImageDigest: could not resolve image digest", "imageName", imageName, "error", err)
+		return ""
+	}
+	return digest
+}
+
 // pullImage pulls imageName and writes progress messages to w.
-func (sb *Boxer) pullImage(ctx context.Context, imageName string, w io.Writer) error {
+func (sb *Boxer) pullImage(ctx context.Context, imageName string, w io.Writer) (err error) {
 	slog.InfoContext(ctx, "Boxer.pullImage", "imageName", imageName)
 	progress := imageProgressSink(w)
 
 	fmt.Fprintf(progress, "Pulling image %s...\n", imageName)
 	start := time.Now()
+	defer func() { sb.metrics.recordPull(time.Since(start), err) }()
 
 	waitFn, err := sb.ImageService.Pull(ctx, imageName, progress)
 	defer func() {
@@ -1145,16 +1761,17 @@ func (sb *Boxer) pullImage(ctx context.Context, imageName string, w io.Writer) e
 	}()
 	if err != nil {
 		slog.ErrorContext(ctx, "Boxer.pullImage", "error", err)
-		return err
+		return hostops.ClassifyPullError(imageName, err)
 	}
 
 	if waitFn != nil {
 		if err := waitFn(); err != nil {
 			slog.ErrorContext(ctx, "Boxer.pullImage wait", "error", err)
-			return err
+			return hostops.ClassifyPullError(imageName, err)
 		}
 	}
 
+	sb.recordFetchedImage(ctx, imageName)
 	fmt.Fprintf(progress, "Done pulling image. Took %v.\n", time.Since(start))
 	return nil
 }
@@ -1168,6 +1785,12 @@ func imageProgressSink(w io.Writer) imageprogress.Sink {
 
 // SaveSandbox persists the Sandbox to the database.
 func (sb *Boxer) SaveSandbox(ctx context.Context, sbox *sandtypes.Box) error {
+	defer sb.lockSandbox(sbox.ID)()
+	return sb.saveSandbox(ctx, sbox)
+}
+
+// saveSandbox is SaveSandbox for callers that already hold the sandbox's lock.
+func (sb *Boxer) saveSandbox(ctx context.Context, sbox *sandtypes.Box) error {
 	ctx = sandboxlog.WithSandboxID(ctx, sbox.ID)
 	slog.InfoContext(ctx, "Boxer.SaveSandbox", "id", sbox.ID)
 	if sbox.Name == "" {
@@ -1193,7 +1816,19 @@ func (sb *Boxer) SaveSandbox(ctx context.Context, sbox *sandtypes.Box) error {
 		ProfileName:           toNullString(sbox.ProfileName),
 		AllowedDomains:        domainsToNullString(sbox.AllowedDomains),
 		MountSpecs:            mountRequestsToNullString(sbox.MountRequests),
+		Mounts:                mountsToNullString(sbox.Mounts),
+		Labels:                labelsToNullString(sbox.Labels),
+		Shell:                 toNullString(sbox.Shell),
+		Network:               toNullString(sbox.Network),
+		NoDotfiles:            sbox.NoDotfiles,
+		NoSsh:                 sbox.NoSSH,
+		AllowEmulation:        sbox.AllowEmulation,
+		IgnoreOncreateErrors:  sbox.IgnoreOnCreateErrors,
+		Publish:               publishToNullString(sbox.Publish),
+		DnsConfig:             dnsConfigToNullString(sbox.DNS),
+		ImageDigest:           toNullString(sbox.ImageDigest),
 		ContainerBootstrapped: sbox.ContainerBootstrapped,
+		StartHooksRan:         sbox.StartHooksRan,
 		Cpu:                   toNullInt(sbox.CPUs),
 		MemoryMb:              toNullInt(sbox.MemoryMB),
 		DefaultUsername:       toNullString(sbox.Username),
@@ -1218,6 +1853,7 @@ func (sb *Boxer) SaveSandbox(ctx context.Context, sbox *sandtypes.Box) error {
 func (sb *Boxer) UpdateContainerID(ctx context.Context, sbox *sandtypes.Box, containerID string) error {
 	sbox.ContainerID = containerID
 	sbox.ContainerBootstrapped = false
+	sbox.StartHooksRan = false
 	err := sb.queries.UpdateContainerID(ctx, db.UpdateContainerIDParams{
 		ContainerID: toNullString(containerID),
 		ID:          sbox.ID,
@@ -1239,19 +1875,124 @@ func (sb *Boxer) UpdateContainerBootstrapped(ctx context.Context, sbox *sandtype
 	return nil
 }
 
+// UpdateStartHooksRan records whether start hooks have run for the container's current run.
+// The lifecycle service sets it once a start has fully succeeded, so that is
+// when the sandbox is reported as started.
+func (sb *Boxer) UpdateStartHooksRan(ctx context.Context, sbox *sandtypes.Box, ran bool) error {
+	sbox.StartHooksRan = ran
+	if err := sb.queries.UpdateStartHooksRan(ctx, db.UpdateStartHooksRanParams{
+		StartHooksRan: ran,
+		ID:            sbox.ID,
+	}); err != nil {
+		return fmt.Errorf("failed to update start hooks state: %w", err)
+	}
+	if ran {
+		sb.publish(ctx, sandtypes.SandboxStarted, sbox, "")
+	}
+	return nil
+}
+
+// UpdateImageName records that sbox's container is now built from imageName,
+// along with that image's local digest.
+func (sb *Boxer) UpdateImageName(ctx context.Context, sbox *sandtypes.Box, imageName string) error {
+	digest := sb.recordedImageDigest(ctx, imageName)
+	if err := sb.queries.UpdateImageName(ctx, db.UpdateImageNameParams{
+		ImageName:   imageName,
+		ImageDigest: toNullString(digest),
+		ID:          sbox.ID,
+	}); err != nil {
+		return fmt.Errorf("failed to update image name: %w", err)
+	}
+	sbox.ImageName = imageName
+	sbox.ImageDigest = digest
+	return nil
+}
+
+// MarkUsed records that a sandbox was just shelled into or exec'd against.
+func (sb *Boxer) MarkUsed(ctx context.Context, sbox *sandtypes.Box) error {
+	now := sb.now().UTC()
+	if err := sb.queries.MarkSandboxUsed(ctx, db.MarkSandboxUsedParams{
+		LastUsedAt: sql.NullTime{Time: now, Valid: true},
+		ID:         sbox.ID,
+	}); err != nil {
+		return fmt.Errorf("failed to update last used time: %w", err)
+	}
+	sbox.LastUsedAt = now
+	return nil
+}
+
 // StopContainer stops a sandbox's container without deleting it.
 func (sb *Boxer) StopContainer(ctx context.Context, sbox *sandtypes.Box) error {
 	ctx = sandboxlog.WithSandboxID(ctx, sbox.ID)
+	defer sb.lockSandbox(sbox.ID)()
 	if sbox.ContainerID == "" {
 		return fmt.Errorf("sandbox %s has no container ID", sbox.ID)
 	}
 
-	out, err := sb.ContainerService.Stop(ctx, nil, sbox.ContainerID)
+	sb.runStopHooks(ctx, sbox)
+	stopCtx, cancel := context.WithTimeout(ctx, stopContainerTimeout)
+	out, err := sb.ContainerService.Stop(stopCtx, nil, sbox.ContainerID)
+	timedOut := stopCtx.Err() == context.DeadlineExceeded
+	cancel()
+	if err != nil && timedOut && ctx.Err() == nil {
+		// A container wedged badly enough to ignore stop gets killed instead.
+		slog.WarnContext(ctx, "Boxer.StopContainer timed out, killing container", "containerID", sbox.ContainerID, "timeout", stopContainerTimeout, "error", err)
+		out, err = sb.ContainerService.Kill(ctx, nil, sbox.ContainerID)
+	}
 	if err != nil {
 		slog.ErrorContext(ctx, "Boxer.StopContainer", "containerID", sbox.ContainerID, "error", err, "out", out)
 		return fmt.Errorf("failed to stop container for sandbox %s: %w", sbox.ID, err)
 	}
 	slog.InfoContext(ctx, "Boxer.StopContainer", "containerID", sbox.ContainerID, "out", out)
+	return sb.containerStopped(ctx, sbox)
+}
+
+// KillContainer sends signal (SIGKILL if empty) to a sandbox's container
+// without waiting for a graceful stop. Only SIGKILL is treated as stopping
+// the sandbox; other signals are left for the container to handle.
+func (sb *Boxer) KillContainer(ctx context.Context, sbox *sandtypes.Box, signal string) error {
+	ctx = sandboxlog.WithSandboxID(ctx, sbox.ID)
+	defer sb.lockSandbox(sbox.ID)()
+	if sbox.ContainerID == "" {
+		return fmt.Errorf("sandbox %s has no container ID", sbox.ID)
+	}
+	sig, err := hostops.ParseSignal(signal)
+	if err != nil {
+		return err
+	}
+
+	out, err := sb.ContainerService.Kill(ctx, &hostops.KillContainer{Signal: signal}, sbox.ContainerID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Boxer.KillContainer", "containerID", sbox.ContainerID, "signal", signal, "error", err, "out", out)
+		return fmt.Errorf("failed to kill container for sandbox %s: %w", sbox.ID, err)
+	}
+	slog.InfoContext(ctx, "Boxer.KillContainer", "containerID", sbox.ContainerID, "signal", signal, "out", out)
+	if sig != syscall.SIGKILL {
+		return nil
+	}
+	return sb.containerStopped(ctx, sbox)
+}
+
+// runStopHooks runs sbox's stop hooks if its container has been started
+// since it was last stopped. Hook failures are logged rather than returned so
+// they never keep a sandbox from stopping.
+func (sb *Boxer) runStopHooks(ctx context.Context, sbox *sandtypes.Box) {
+	if sbox.ContainerID == "" || !sbox.StartHooksRan {
+		return
+	}
+	if err := sb.newLifecycleService().ExecuteStopHooks(ctx, sbox); err != nil {
+		slog.WarnContext(ctx, "Boxer stop hooks", "containerID", sbox.ContainerID, "error", err)
+	}
+}
+
+// containerStopped cleans up after sbox's container has been stopped or killed.
+func (sb *Boxer) containerStopped(ctx context.Context, sbox *sandtypes.Box) error {
+	sb.killHostProcesses(ctx, sbox)
+	// The next start begins a new container run, which needs its hooks again.
+	if err := sb.UpdateStartHooksRan(ctx, sbox, false); err != nil {
+		return err
+	}
+	sb.publish(ctx, sandtypes.SandboxStopped, sbox, "")
 	return nil
 }
 
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"os"
 	"path"
@@ -11,6 +12,8 @@ import (
 	"reflect"
 	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -20,6 +23,7 @@ import (
 	"github.com/banksean/sand/internal/daemon/lifecycle"
 	"github.com/banksean/sand/internal/hostops"
 	"github.com/banksean/sand/internal/imageprogress"
+	"github.com/banksean/sand/internal/runtimedeps"
 	"github.com/banksean/sand/internal/sandtypes"
 	"github.com/banksean/sand/internal/sshimmer"
 )
@@ -28,6 +32,8 @@ type mockImageOps struct {
 	listFunc    func(ctx context.Context) ([]sandtypes.ImageEntry, error)
 	pullFunc    func(ctx context.Context, image string, progress imageprogress.Sink) (func() error, error)
 	inspectFunc func(ctx context.Context, name string) ([]*sandtypes.ImageManifest, error)
+	buildFunc   func(ctx context.Context, opts *hostops.BuildImage, contextDir string, w io.Writer) error
+	removeFunc  func(ctx context.Context, name string) error
 }
 
 // Inspect implements [hostops.ImageOps].
@@ -52,20 +58,35 @@ func (m *mockImageOps) Pull(ctx context.Context, image string, progress imagepro
 	return func() error { return nil }, nil
 }
 
+func (m *mockImageOps) Build(ctx context.Context, opts *hostops.BuildImage, contextDir string, w io.Writer) error {
+	if m.buildFunc != nil {
+		return m.buildFunc(ctx, opts, contextDir, w)
+	}
+	return nil
+}
+
+func (m *mockImageOps) Remove(ctx context.Context, name string) error {
+	if m.removeFunc != nil {
+		return m.removeFunc(ctx, name)
+	}
+	return nil
+}
+
 type mockSSHimmer struct {
-	newKeysFunc func(ctx context.Context, domain, username string) (*sshimmer.Keys, error)
+	newKeysFunc  func(ctx context.Context, domain, username string) (*sshimmer.Keys, error)
+	removedHosts []string
+}
+
+func (m *mockSSHimmer) RemoveHost(ctx context.Context, hostName string) error {
+	m.removedHosts = append(m.removedHosts, hostName)
+	return nil
 }
 
 func (m *mockSSHimmer) NewKeys(ctx context.Context, domain, username string) (*sshimmer.Keys, error) {
 	if m.newKeysFunc != nil {
 		return m.newKeysFunc(ctx, domain, username)
 	}
-	return &sshimmer.Keys{
-		HostKey:     []byte("fake-host-key"),
-		HostKeyPub:  []byte("fake-host-key-pub"),
-		HostKeyCert: []byte("fake-host-key-cert"),
-		UserCAPub:   []byte("fake-user-ca-pub"),
-	}, nil
+	return sshimmer.NewTestKeys(domain)
 }
 
 type recordingHookStreamer struct {
@@ -138,7 +159,11 @@ func TestBoxer_NewSandbox_EndToEnd(t *testing.T) {
 			},
 		}
 
-		mockImage := &mockImageOps{}
+		mockImage := &mockImageOps{
+			inspectFunc: func(ctx context.Context, name string) ([]*sandtypes.ImageManifest, error) {
+				return []*sandtypes.ImageManifest{{Name: name, Index: sandtypes.Index{Digest: "sha256:created"}}}, nil
+			},
+		}
 
 		boxer := newTestBoxer(t, mockContainer, mockImage)
 		boxer.FileOps = &hostops.MockFileOps{
@@ -199,19 +224,140 @@ func TestBoxer_NewSandbox_EndToEnd(t *testing.T) {
 		if loadedBox.DNSDomain != "test.local" {
 			t.Errorf("Expected loaded DNSDomain 'test.local', got %s", loadedBox.DNSDomain)
 		}
+		if loadedBox.ImageDigest != "sha256:created" {
+			t.Errorf("Expected loaded ImageDigest 'sha256:created', got %s", loadedBox.ImageDigest)
+		}
 		if loadedBox.ID != "test-sandbox" {
 			t.Errorf("DB sandbox ID = %s, want 'test-sandbox'", loadedBox.ID)
 		}
 	})
 
+	t.Run("full copy warns on progress", func(t *testing.T) {
+		boxer := newTestBoxer(t, &hostops.MockContainerOps{}, &mockImageOps{})
+		boxer.FileOps = &hostops.MockFileOps{
+			MkdirAllFunc: os.MkdirAll,
+			CreateFunc:   os.Create,
+		}
+		boxer.AgentRegistry.Register(&agents.AgentConfig{
+			Name: "test-full-copy-agent",
+			Preparation: &mockWorkspacePreparation{
+				prepareFunc: func(ctx context.Context, req cloning.CloneRequest) (*cloning.CloneArtifacts, error) {
+					sandboxRoot := filepath.Join(boxer.appRoot, "clones", req.ID)
+					return &cloning.CloneArtifacts{
+						SandboxWorkDir: sandboxRoot,
+						PathRegistry:   cloning.NewStandardPathRegistry(sandboxRoot),
+						CopyOnWrite:    false,
+					}, nil
+				},
+			},
+			Configuration: &mockContainerConfiguration{},
+		})
+
+		var progress bytes.Buffer
+		if _, err := boxer.NewSandbox(ctx, NewSandboxOpts{AgentType: "test-full-copy-agent", ID: "full-copy", Name: "full-copy", HostWorkDir: t.TempDir(), ImageName: "test-image:latest", Progress: &progress}); err != nil {
+			t.Fatalf("NewSandbox() error = %v", err)
+		}
+		if !strings.Contains(progress.String(), "full copy and extra disk space") {
+			t.Fatalf("progress = %q, want full-copy warning", progress.String())
+		}
+	})
+
+	t.Run("dns domain matches ssh certs and container create opts", func(t *testing.T) {
+		for _, tt := range []struct {
+			localDomain string
+			want        string
+		}{
+			{localDomain: "test.", want: "test"},
+			{localDomain: "", want: runtimedeps.DefaultDNSDomain},
+		} {
+			var createOpts *hostops.CreateContainer
+			mockContainer := &hostops.MockContainerOps{
+				CreateFunc: func(ctx context.Context, opts *hostops.CreateContainer, image string, args []string) (string, error) {
+					createOpts = opts
+					return "dns-container", nil
+				},
+			}
+			boxer := newTestBoxer(t, mockContainer, &mockImageOps{})
+			boxer.FileOps = &hostops.MockFileOps{
+				MkdirAllFunc: os.MkdirAll,
+				CreateFunc:   os.Create,
+			}
+			var certHost string
+			boxer.SSHim = &mockSSHimmer{newKeysFunc: func(ctx context.Context, domain, username string) (*sshimmer.Keys, error) {
+				certHost = domain
+				return sshimmer.NewTestKeys(domain)
+			}}
+			boxer.AgentRegistry.Register(&agents.AgentConfig{
+				Name: "test-agent",
+				Preparation: &mockWorkspacePreparation{
+					prepareFunc: func(ctx context.Context, req cloning.CloneRequest) (*cloning.CloneArtifacts, error) {
+						sandboxRoot := filepath.Join(boxer.appRoot, "clones", req.ID)
+						return &cloning.CloneArtifacts{
+							SandboxWorkDir: sandboxRoot,
+							PathRegistry:   cloning.NewStandardPathRegistry(sandboxRoot),
+						}, nil
+					},
+				},
+				Configuration: &mockContainerConfiguration{},
+			})
+
+			sbox, err := boxer.NewSandbox(ctx, NewSandboxOpts{AgentType: "test-agent", ID: "dns-box", Name: "dns-box", HostWorkDir: t.TempDir(), ImageName: "test-image:latest", LocalDomain: tt.localDomain})
+			if err != nil {
+				t.Fatalf("NewSandbox(LocalDomain=%q) error = %v", tt.localDomain, err)
+			}
+			if sbox.DNSDomain != tt.want {
+				t.Errorf("LocalDomain %q: DNSDomain = %q, want %q", tt.localDomain, sbox.DNSDomain, tt.want)
+			}
+			if certHost != "dns-box."+tt.want {
+				t.Errorf("LocalDomain %q: ssh cert host = %q, want %q", tt.localDomain, certHost, "dns-box."+tt.want)
+			}
+			loaded, err := boxer.Get(ctx, "dns-box")
+			if err != nil || loaded == nil {
+				t.Fatalf("Get() = %v, %v", loaded, err)
+			}
+			if loaded.DNSDomain != tt.want {
+				t.Errorf("LocalDomain %q: persisted DNSDomain = %q, want %q", tt.localDomain, loaded.DNSDomain, tt.want)
+			}
+			if err := boxer.newLifecycleService().CreateContainer(ctx, loaded, false); err != nil {
+				t.Fatalf("CreateContainer() error = %v", err)
+			}
+			if createOpts == nil || createOpts.ManagementOptions.DNSDomain != tt.want {
+				t.Errorf("LocalDomain %q: container create opts = %+v, want DNSDomain %q", tt.localDomain, createOpts, tt.want)
+			}
+		}
+	})
+
 	t.Run("preparation error propagates", func(t *testing.T) {
 		mockContainer := &hostops.MockContainerOps{}
 		mockImage := &mockImageOps{}
 		boxer := newTestBoxer(t, mockContainer, mockImage)
+		boxer.FileOps = &hostops.MockFileOps{
+			MkdirAllFunc:  os.MkdirAll,
+			RemoveAllFunc: os.RemoveAll,
+		}
+		cloneDir := filepath.Join(boxer.appRoot, "clones", "test-sandbox")
+		remotes := map[string]string{
+			cloning.ClonedWorkDirGitRemotePrefix + "test-sandbox": filepath.Join(cloneDir, "app"),
+		}
+		boxer.GitOps = &hostops.MockGitOps{
+			TopLevelFunc: func(ctx context.Context, dir string) string { return "/host/work" },
+			RemoteURLFunc: func(ctx context.Context, dir, name string) string {
+				return remotes[name]
+			},
+			RemoveRemoteFunc: func(ctx context.Context, dir, name string) error {
+				delete(remotes, name)
+				return nil
+			},
+		}
 
 		expectedErr := errors.New("preparation failed")
 		testPrep := &mockWorkspacePreparation{
 			prepareFunc: func(ctx context.Context, req cloning.CloneRequest) (*cloning.CloneArtifacts, error) {
+				// Fail after the clone and its remote exist, as a failed
+				// container setup or remote setup would.
+				if err := os.MkdirAll(filepath.Join(cloneDir, "app"), 0o755); err != nil {
+					return nil, err
+				}
 				return nil, expectedErr
 			},
 		}
@@ -237,6 +383,115 @@ func TestBoxer_NewSandbox_EndToEnd(t *testing.T) {
 		if loadedBox != nil {
 			t.Error("Expected sandbox not to be saved after preparation error")
 		}
+		if _, err := os.Stat(cloneDir); !os.IsNotExist(err) {
+			t.Errorf("clone dir still exists after preparation error: %v", err)
+		}
+		if len(remotes) != 0 {
+			t.Errorf("git remotes left after preparation error: %v", remotes)
+		}
+	})
+
+	t.Run("failed create leaves another sandbox's remote alone", func(t *testing.T) {
+		boxer := newTestBoxer(t, &hostops.MockContainerOps{}, &mockImageOps{})
+		boxer.FileOps = &hostops.MockFileOps{RemoveAllFunc: os.RemoveAll}
+		var removed []string
+		boxer.GitOps = &hostops.MockGitOps{
+			TopLevelFunc: func(ctx context.Context, dir string) string { return "/host/work" },
+			RemoteURLFunc: func(ctx context.Context, dir, name string) string {
+				return filepath.Join(boxer.appRoot, "clones", "other-id", "app")
+			},
+			RemoveRemoteFunc: func(ctx context.Context, dir, name string) error {
+				removed = append(removed, name)
+				return nil
+			},
+		}
+		boxer.AgentRegistry.Register(&agents.AgentConfig{
+			Name: "test-error-agent",
+			Preparation: &mockWorkspacePreparation{
+				prepareFunc: func(ctx context.Context, req cloning.CloneRequest) (*cloning.CloneArtifacts, error) {
+					return nil, errors.New("preparation failed")
+				},
+			},
+			Configuration: &mockContainerConfiguration{},
+		})
+
+		if _, err := boxer.NewSandbox(ctx, NewSandboxOpts{AgentType: "test-error-agent", ID: "new-id", Name: "taken", HostWorkDir: "/host/work", ImageName: "test-image"}); err == nil {
+			t.Fatal("NewSandbox() error = nil, want preparation error")
+		}
+		if len(removed) != 0 {
+			t.Errorf("RemoveRemote calls = %v, want none for a remote into another clone", removed)
+		}
+	})
+
+	t.Run("ssh key error after preparation removes the clone", func(t *testing.T) {
+		boxer := newTestBoxer(t, &hostops.MockContainerOps{}, &mockImageOps{})
+		boxer.FileOps = &hostops.MockFileOps{
+			MkdirAllFunc:  os.MkdirAll,
+			CreateFunc:    os.Create,
+			RemoveAllFunc: os.RemoveAll,
+		}
+		keysErr := errors.New("keygen failed")
+		boxer.SSHim = &mockSSHimmer{newKeysFunc: func(ctx context.Context, domain, username string) (*sshimmer.Keys, error) {
+			return nil, keysErr
+		}}
+		cloneDir := filepath.Join(boxer.appRoot, "clones", "keys-id")
+		boxer.AgentRegistry.Register(&agents.AgentConfig{
+			Name: "test-keys-agent",
+			Preparation: &mockWorkspacePreparation{
+				prepareFunc: func(ctx context.Context, req cloning.CloneRequest) (*cloning.CloneArtifacts, error) {
+					if err := os.MkdirAll(cloneDir, 0o755); err != nil {
+						return nil, err
+					}
+					return &cloning.CloneArtifacts{
+						SandboxWorkDir: cloneDir,
+						PathRegistry:   cloning.NewStandardPathRegistry(cloneDir),
+					}, nil
+				},
+			},
+			Configuration: &mockContainerConfiguration{},
+		})
+
+		_, err := boxer.NewSandbox(ctx, NewSandboxOpts{AgentType: "test-keys-agent", ID: "keys-id", HostWorkDir: t.TempDir(), ImageName: "test-image"})
+		if !errors.Is(err, keysErr) {
+			t.Fatalf("NewSandbox() error = %v, want %v", err, keysErr)
+		}
+		if _, err := os.Stat(cloneDir); !os.IsNotExist(err) {
+			t.Errorf("clone dir still exists after ssh key error: %v", err)
+		}
+		if loaded, err := boxer.Get(ctx, "keys-id"); err != nil || loaded != nil {
+			t.Errorf("Get() = %v, %v; want no saved sandbox", loaded, err)
+		}
+	})
+
+	t.Run("invalid names and IDs are rejected before preparation", func(t *testing.T) {
+		boxer := newTestBoxer(t, &hostops.MockContainerOps{}, &mockImageOps{})
+		var prepareCalls int
+		boxer.AgentRegistry.Register(&agents.AgentConfig{
+			Name: "test-name-agent",
+			Preparation: &mockWorkspacePreparation{
+				prepareFunc: func(ctx context.Context, req cloning.CloneRequest) (*cloning.CloneArtifacts, error) {
+					prepareCalls++
+					return nil, errors.New("unexpected Prepare call")
+				},
+			},
+			Configuration: &mockContainerConfiguration{},
+		})
+
+		for _, opts := range []NewSandboxOpts{
+			{ID: "../escape", Name: "escape"},
+			{ID: "ok-id", Name: "../../escape"},
+			{ID: "ok-id", Name: "My Sandbox"},
+			{ID: "ok-id", Name: "has.dots"},
+		} {
+			opts.AgentType = "test-name-agent"
+			opts.HostWorkDir = t.TempDir()
+			if _, err := boxer.NewSandbox(ctx, opts); err == nil || !strings.Contains(err.Error(), "invalid") {
+				t.Errorf("NewSandbox(id=%q, name=%q) error = %v, want invalid name error", opts.ID, opts.Name, err)
+			}
+		}
+		if prepareCalls != 0 {
+			t.Errorf("Prepare called %d times, want 0", prepareCalls)
+		}
 	})
 
 	t.Run("snapshot ref error does not fail sandbox creation", func(t *testing.T) {
@@ -282,6 +537,195 @@ func TestBoxer_NewSandbox_EndToEnd(t *testing.T) {
 	})
 }
 
+func TestBoxer_NewSandboxCustomCloneRoot(t *testing.T) {
+	ctx := context.Background()
+	newBoxer := func(t *testing.T, prepare func(req cloning.CloneRequest) error) *Boxer {
+		boxer := newTestBoxer(t, &hostops.MockContainerOps{}, &mockImageOps{})
+		boxer.FileOps = &hostops.MockFileOps{
+			MkdirAllFunc:  os.MkdirAll,
+			CreateFunc:    os.Create,
+			StatFunc:      os.Stat,
+			RenameFunc:    os.Rename,
+			RemoveAllFunc: os.RemoveAll,
+		}
+		boxer.AgentRegistry.Register(&agents.AgentConfig{
+			Name: "test-clone-root-agent",
+			Preparation: &mockWorkspacePreparation{
+				prepareFunc: func(ctx context.Context, req cloning.CloneRequest) (*cloning.CloneArtifacts, error) {
+					sandboxRoot := filepath.Join(req.CloneRoot, req.ID)
+					if err := os.MkdirAll(cloning.NewStandardPathRegistry(sandboxRoot).WorkDir(), 0o750); err != nil {
+						return nil, err
+					}
+					if err := prepare(req); err != nil {
+						return nil, err
+					}
+					return &cloning.CloneArtifacts{
+						SandboxWorkDir: sandboxRoot,
+						PathRegistry:   cloning.NewStandardPathRegistry(sandboxRoot),
+					}, nil
+				},
+			},
+			Configuration: &mockContainerConfiguration{},
+		})
+		return boxer
+	}
+
+	t.Run("sandbox is findable, syncable and removable", func(t *testing.T) {
+		boxer := newBoxer(t, func(cloning.CloneRequest) error { return nil })
+		cloneRoot := t.TempDir()
+		sandboxRoot := filepath.Join(cloneRoot, "fast")
+		if _, err := boxer.NewSandbox(ctx, NewSandboxOpts{AgentType: "test-clone-root-agent", ID: "fast", Name: "fast", HostWorkDir: t.TempDir(), ImageName: "test-image:latest", CloneRoot: cloneRoot}); err != nil {
+			t.Fatalf("NewSandbox() error = %v", err)
+		}
+
+		boxes, err := boxer.List(ctx)
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		if len(boxes) != 1 || boxes[0].SandboxWorkDir != sandboxRoot {
+			t.Fatalf("List() = %+v, want one sandbox in %s", boxes, sandboxRoot)
+		}
+		box := &boxes[0]
+
+		if err := boxer.SyncBox(ctx, box); err != nil {
+			t.Fatalf("SyncBox() error = %v", err)
+		}
+		if box.SandboxWorkDirError != "" {
+			t.Fatalf("SandboxWorkDirError = %q, want none for a clone outside the default root", box.SandboxWorkDirError)
+		}
+
+		if err := boxer.Cleanup(ctx, box); err != nil {
+			t.Fatalf("Cleanup() error = %v", err)
+		}
+		if _, err := os.Stat(sandboxRoot); !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("Stat(%s) error = %v, want the clone moved to the trash", sandboxRoot, err)
+		}
+		deleted, err := boxer.ListDeleted(ctx)
+		if err != nil {
+			t.Fatalf("ListDeleted() error = %v", err)
+		}
+		if len(deleted) != 1 || deleted[0].TrashWorkDir == "" {
+			t.Fatalf("ListDeleted() = %+v, want the sandbox with its trashed clone", deleted)
+		}
+		if _, err := os.Stat(filepath.Join(deleted[0].TrashWorkDir, "app")); err != nil {
+			t.Fatalf("trashed clone: %v", err)
+		}
+	})
+
+	t.Run("failed create discards the clone under the custom root", func(t *testing.T) {
+		boxer := newBoxer(t, func(cloning.CloneRequest) error { return errors.New("clone failed") })
+		cloneRoot := t.TempDir()
+		if _, err := boxer.NewSandbox(ctx, NewSandboxOpts{AgentType: "test-clone-root-agent", ID: "fast", HostWorkDir: t.TempDir(), ImageName: "test-image:latest", CloneRoot: cloneRoot}); err == nil {
+			t.Fatal("NewSandbox() error = nil, want clone failure")
+		}
+		if _, err := os.Stat(filepath.Join(cloneRoot, "fast")); !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("Stat() error = %v, want partial clone removed", err)
+		}
+	})
+
+	t.Run("failed create leaves a directory that was already there", func(t *testing.T) {
+		boxer := newBoxer(t, func(req cloning.CloneRequest) error {
+			return fmt.Errorf("failed to create clone directory for sandbox %s: %w", req.ID, cloning.ErrSandboxRootExists)
+		})
+		cloneRoot := t.TempDir()
+		keep := filepath.Join(cloneRoot, "fast", "keep.txt")
+		if err := os.MkdirAll(filepath.Dir(keep), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(keep, []byte("not sand's\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := boxer.NewSandbox(ctx, NewSandboxOpts{AgentType: "test-clone-root-agent", ID: "fast", HostWorkDir: t.TempDir(), ImageName: "test-image:latest", CloneRoot: cloneRoot}); !errors.Is(err, cloning.ErrSandboxRootExists) {
+			t.Fatalf("NewSandbox() error = %v, want %v", err, cloning.ErrSandboxRootExists)
+		}
+		if _, err := os.Stat(keep); err != nil {
+			t.Fatalf("Stat(%s) error = %v, want the existing directory kept", keep, err)
+		}
+	})
+
+	t.Run("relative clone root is rejected", func(t *testing.T) {
+		boxer := newBoxer(t, func(cloning.CloneRequest) error { return nil })
+		_, err := boxer.NewSandbox(ctx, NewSandboxOpts{AgentType: "test-clone-root-agent", ID: "fast", HostWorkDir: t.TempDir(), ImageName: "test-image:latest", CloneRoot: "clones"})
+		if err == nil || !strings.Contains(err.Error(), "must be an absolute path") {
+			t.Fatalf("NewSandbox() error = %v, want absolute path error", err)
+		}
+	})
+}
+
+func TestBoxer_NewSandboxSameIDConcurrently(t *testing.T) {
+	ctx := context.Background()
+	boxer := newTestBoxer(t, &hostops.MockContainerOps{}, &mockImageOps{})
+	boxer.FileOps = &hostops.MockFileOps{
+		MkdirAllFunc: os.MkdirAll,
+		CreateFunc:   os.Create,
+	}
+
+	var prepares atomic.Int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+	boxer.AgentRegistry.Register(&agents.AgentConfig{
+		Name: "test-concurrent-agent",
+		Preparation: &mockWorkspacePreparation{
+			prepareFunc: func(ctx context.Context, req cloning.CloneRequest) (*cloning.CloneArtifacts, error) {
+				if prepares.Add(1) == 1 {
+					close(started)
+					<-release
+				}
+				sandboxRoot := filepath.Join(boxer.appRoot, "clones", req.ID)
+				if err := os.MkdirAll(sandboxRoot, 0o750); err != nil {
+					return nil, err
+				}
+				return &cloning.CloneArtifacts{
+					SandboxWorkDir: sandboxRoot,
+					PathRegistry:   cloning.NewStandardPathRegistry(sandboxRoot),
+				}, nil
+			},
+		},
+		Configuration: &mockContainerConfiguration{},
+	})
+
+	opts := NewSandboxOpts{AgentType: "test-concurrent-agent", ID: "same-id", Name: "same-id", HostWorkDir: t.TempDir(), ImageName: "test-image:latest"}
+	errs := make(chan error, 2)
+	go func() {
+		_, err := boxer.NewSandbox(ctx, opts)
+		errs <- err
+	}()
+	<-started
+	go func() {
+		_, err := boxer.NewSandbox(ctx, opts)
+		errs <- err
+	}()
+	// Give the second call time to reach Prepare if it were not serialized.
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	var succeeded int
+	var failures []error
+	for range 2 {
+		if err := <-errs; err != nil {
+			failures = append(failures, err)
+		} else {
+			succeeded++
+		}
+	}
+	if succeeded != 1 || len(failures) != 1 {
+		t.Fatalf("NewSandbox succeeded %d times, failures %v; want exactly one success", succeeded, failures)
+	}
+	if !strings.Contains(failures[0].Error(), "already exists") {
+		t.Errorf("losing NewSandbox error = %v, want already exists", failures[0])
+	}
+	if got := prepares.Load(); got != 1 {
+		t.Errorf("Prepare called %d times, want 1", got)
+	}
+	boxes, err := boxer.List(ctx)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(boxes) != 1 || boxes[0].ID != "same-id" {
+		t.Fatalf("List() = %+v, want one sandbox same-id", boxes)
+	}
+}
+
 func TestBoxer_CreateContainerSSHAgentOptIn(t *testing.T) {
 	ctx := context.Background()
 
@@ -399,6 +843,7 @@ func TestBoxer_RenameSandboxRegeneratesSSHKeysAndMarksReplacementUnbootstrapped(
 		domain   string
 		username string
 	}
+	var newKeys *sshimmer.Keys
 	var deleteCalls []string
 	var createCalls []*hostops.CreateContainer
 	mockContainer := &hostops.MockContainerOps{
@@ -443,12 +888,9 @@ func TestBoxer_RenameSandboxRegeneratesSSHKeysAndMarksReplacementUnbootstrapped(
 				domain   string
 				username string
 			}{domain: domain, username: username})
-			return &sshimmer.Keys{
-				HostKey:     []byte("new-host-key"),
-				HostKeyPub:  []byte("new-host-key-pub"),
-				HostKeyCert: []byte("new-host-key-cert"),
-				UserCAPub:   []byte("new-user-ca-pub"),
-			}, nil
+			var err error
+			newKeys, err = sshimmer.NewTestKeys(domain)
+			return newKeys, err
 		},
 	}
 
@@ -496,8 +938,8 @@ func TestBoxer_RenameSandboxRegeneratesSSHKeysAndMarksReplacementUnbootstrapped(
 	if err != nil {
 		t.Fatalf("ReadFile(%q): %v", keyPath, err)
 	}
-	if string(keyBytes) != "new-host-key" {
-		t.Fatalf("ssh host key = %q, want new-host-key", keyBytes)
+	if !bytes.Equal(keyBytes, newKeys.HostKey) {
+		t.Fatalf("ssh host key = %q, want the newly issued key", keyBytes)
 	}
 
 	loaded, err := boxer.Get(ctx, "mysandbox")
@@ -512,6 +954,111 @@ func TestBoxer_RenameSandboxRegeneratesSSHKeysAndMarksReplacementUnbootstrapped(
 	}
 }
 
+func TestBoxer_RenameSandboxRenamesGitRemote(t *testing.T) {
+	ctx := context.Background()
+	originDir := t.TempDir()
+	mockContainer := &hostops.MockContainerOps{
+		InspectFunc: func(ctx context.Context, containerID string) ([]sandtypes.Container, error) {
+			return []sandtypes.Container{{Status: sandtypes.ContainerStatus{State: "stopped"}}}, nil
+		},
+		CreateFunc: func(ctx context.Context, opts *hostops.CreateContainer, image string, args []string) (string, error) {
+			return opts.ManagementOptions.Name, nil
+		},
+	}
+	var renames [][3]string
+	boxer := newTestBoxer(t, mockContainer, &mockImageOps{})
+	boxer.FileOps = &hostops.MockFileOps{
+		MkdirAllFunc: os.MkdirAll,
+		CreateFunc:   os.Create,
+	}
+	boxer.GitOps = &hostops.MockGitOps{
+		RenameRemoteFunc: func(ctx context.Context, dir, oldName, newName string) error {
+			renames = append(renames, [3]string{dir, oldName, newName})
+			return nil
+		},
+	}
+	if err := boxer.SaveSandbox(ctx, &sandtypes.Box{
+		ID:             "sandbox-id",
+		Name:           "before",
+		ContainerID:    "before",
+		HostOriginDir:  originDir,
+		SandboxWorkDir: t.TempDir(),
+		ImageName:      "test-image:latest",
+	}); err != nil {
+		t.Fatalf("SaveSandbox() error = %v", err)
+	}
+
+	if _, err := boxer.RenameSandbox(ctx, "before", "after", io.Discard); err != nil {
+		t.Fatalf("RenameSandbox() error = %v", err)
+	}
+
+	want := [][3]string{{originDir, cloning.ClonedWorkDirGitRemotePrefix + "before", cloning.ClonedWorkDirGitRemotePrefix + "after"}}
+	if !reflect.DeepEqual(renames, want) {
+		t.Fatalf("RenameRemote calls = %v, want %v", renames, want)
+	}
+	if old, err := boxer.Get(ctx, "before"); err != nil || old != nil {
+		t.Fatalf("Get(before) = %v, %v; want nil, nil", old, err)
+	}
+	loaded, err := boxer.Get(ctx, "after")
+	if err != nil || loaded == nil {
+		t.Fatalf("Get(after) = %v, %v; want renamed sandbox", loaded, err)
+	}
+	if loaded.ID != "sandbox-id" {
+		t.Fatalf("renamed sandbox ID = %q, want sandbox-id", loaded.ID)
+	}
+}
+
+func TestBoxer_RenameSandboxRejectsNameCollision(t *testing.T) {
+	ctx := context.Background()
+	var deleteCalls, createCalls int
+	mockContainer := &hostops.MockContainerOps{
+		InspectFunc: func(ctx context.Context, containerID string) ([]sandtypes.Container, error) {
+			return []sandtypes.Container{{Status: sandtypes.ContainerStatus{State: "stopped"}}}, nil
+		},
+		DeleteFunc: func(ctx context.Context, opts *hostops.DeleteContainer, containerID string) (string, error) {
+			deleteCalls++
+			return "", nil
+		},
+		CreateFunc: func(ctx context.Context, opts *hostops.CreateContainer, image string, args []string) (string, error) {
+			createCalls++
+			return "", nil
+		},
+	}
+	boxer := newTestBoxer(t, mockContainer, &mockImageOps{})
+	newKeysCalls := 0
+	boxer.SSHim = &mockSSHimmer{
+		newKeysFunc: func(ctx context.Context, domain, username string) (*sshimmer.Keys, error) {
+			newKeysCalls++
+			return sshimmer.NewTestKeys(domain)
+		},
+	}
+	for _, name := range []string{"first", "second"} {
+		if err := boxer.SaveSandbox(ctx, &sandtypes.Box{
+			ID:             name + "-id",
+			Name:           name,
+			ContainerID:    name,
+			SandboxWorkDir: t.TempDir(),
+			ImageName:      "test-image:latest",
+		}); err != nil {
+			t.Fatalf("SaveSandbox(%s) error = %v", name, err)
+		}
+	}
+
+	_, err := boxer.RenameSandbox(ctx, "first", "second", io.Discard)
+	if err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("RenameSandbox() error = %v, want collision error", err)
+	}
+	if deleteCalls != 0 || createCalls != 0 || newKeysCalls != 0 {
+		t.Fatalf("delete=%d create=%d newKeys=%d calls, want none after collision", deleteCalls, createCalls, newKeysCalls)
+	}
+	for _, name := range []string{"first", "second"} {
+		loaded, err := boxer.Get(ctx, name)
+		if err != nil || loaded == nil || loaded.ID != name+"-id" {
+			t.Fatalf("Get(%s) = %v, %v; want unchanged sandbox", name, loaded, err)
+		}
+	}
+}
+
 func recoveryFileOps() hostops.FileOps {
 	return &hostops.MockFileOps{
 		MkdirAllFunc:  os.MkdirAll,
@@ -683,6 +1230,7 @@ type mockContainerConfiguration struct {
 	getMountsFunc       func(artifacts containerruntime.Artifacts) []sandtypes.MountSpec
 	getStartupHooksFunc func(artifacts containerruntime.Artifacts) []sandtypes.ContainerHook
 	getStartHooksFunc   func(artifacts containerruntime.Artifacts) []sandtypes.ContainerHook
+	getStopHooksFunc    func(artifacts containerruntime.Artifacts) []sandtypes.ContainerHook
 }
 
 // GetStartHooks implements [containerruntime.ContainerConfiguration].
@@ -693,6 +1241,14 @@ func (m *mockContainerConfiguration) GetStartHooks(artifacts containerruntime.Ar
 	return []sandtypes.ContainerHook{}
 }
 
+// GetStopHooks implements [containerruntime.ContainerConfiguration].
+func (m *mockContainerConfiguration) GetStopHooks(artifacts containerruntime.Artifacts) []sandtypes.ContainerHook {
+	if m.getStopHooksFunc != nil {
+		return m.getStopHooksFunc(artifacts)
+	}
+	return nil
+}
+
 var _ containerruntime.ContainerConfiguration = &mockContainerConfiguration{}
 
 func (m *mockContainerConfiguration) GetMounts(artifacts containerruntime.Artifacts) []sandtypes.MountSpec {
@@ -817,10 +1373,13 @@ func TestBoxer_StartContainersPrependInnieSocketPermissionHook(t *testing.T) {
 				t.Fatalf("%s error = %v", tc.name, err)
 			}
 
-			if len(execCalls) != 7 {
-				t.Fatalf("%s exec calls = %v, want socket checks plus agent hook", tc.name, execCalls)
+			if len(execCalls) != 8 {
+				t.Fatalf("%s exec calls = %v, want readiness probe, socket checks plus agent hook", tc.name, execCalls)
+			}
+			if got, want := execCalls[0], "true"; got != want {
+				t.Fatalf("%s readiness exec call = %q, want %q", tc.name, got, want)
 			}
-			if got, want := execCalls[0], "test -e /run/host-services"; got != want {
+			if got, want := execCalls[1], "test -e /run/host-services"; got != want {
 				t.Fatalf("%s first exec call = %q, want %q", tc.name, got, want)
 			}
 			if !strings.HasPrefix(execCalls[len(execCalls)-1], "agent-hook ") {
@@ -830,6 +1389,81 @@ func TestBoxer_StartContainersPrependInnieSocketPermissionHook(t *testing.T) {
 	}
 }
 
+func TestBoxer_StopContainerRunsStopHooksBeforeStopping(t *testing.T) {
+	var calls []string
+	mockContainer := &hostops.MockContainerOps{
+		ExecFunc: func(ctx context.Context, opts *hostops.ExecContainer, containerID, cmd string, env []string, args ...string) (string, error) {
+			calls = append(calls, strings.Join(append([]string{cmd}, args...), " "))
+			return "", nil
+		},
+		StopFunc: func(ctx context.Context, opts *hostops.StopContainer, containerID string) (string, error) {
+			calls = append(calls, "stop")
+			return containerID, nil
+		},
+	}
+	boxer := newTestBoxer(t, mockContainer, &mockImageOps{})
+	boxer.AgentRegistry.Register(&agents.AgentConfig{
+		Name: "default",
+		Configuration: &mockContainerConfiguration{
+			getStopHooksFunc: func(artifacts containerruntime.Artifacts) []sandtypes.ContainerHook {
+				return []sandtypes.ContainerHook{
+					agentHookForTest("first stop hook"),
+					sandtypes.NewContainerHook("failing stop hook", func(ctx context.Context, ctr *sandtypes.Container, exec sandtypes.HookStreamer) error {
+						return errors.New("flush failed")
+					}),
+					agentHookForTest("last stop hook"),
+				}
+			},
+		},
+	})
+	ctx := context.Background()
+	sbox := &sandtypes.Box{ID: "stop-hooks", AgentType: "default", ContainerID: "ctr-stop", SandboxWorkDir: t.TempDir()}
+	if err := boxer.SaveSandbox(ctx, sbox); err != nil {
+		t.Fatalf("SaveSandbox() error = %v", err)
+	}
+
+	// A container that hasn't started since it was last stopped has nothing to clean up.
+	if err := boxer.StopContainer(ctx, sbox); err != nil {
+		t.Fatalf("StopContainer() error = %v", err)
+	}
+	if want := []string{"stop"}; !reflect.DeepEqual(calls, want) {
+		t.Fatalf("calls = %#v, want %#v", calls, want)
+	}
+
+	calls = nil
+	if err := boxer.UpdateStartHooksRan(ctx, sbox, true); err != nil {
+		t.Fatalf("UpdateStartHooksRan() error = %v", err)
+	}
+	if err := boxer.StopContainer(ctx, sbox); err != nil {
+		t.Fatalf("StopContainer() error = %v, want stop hook failures only logged", err)
+	}
+	want := []string{"agent-hook first stop hook", "agent-hook last stop hook", "stop"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Fatalf("calls = %#v, want %#v", calls, want)
+	}
+}
+
+func TestExecuteStopHooksJoinsHookErrors(t *testing.T) {
+	boxer := newTestBoxer(t, &hostops.MockContainerOps{}, &mockImageOps{})
+	firstErr, secondErr := errors.New("first failed"), errors.New("second failed")
+	boxer.AgentRegistry.Register(&agents.AgentConfig{
+		Name: "default",
+		Configuration: &mockContainerConfiguration{
+			getStopHooksFunc: func(artifacts containerruntime.Artifacts) []sandtypes.ContainerHook {
+				return []sandtypes.ContainerHook{
+					sandtypes.NewContainerHook("first", func(context.Context, *sandtypes.Container, sandtypes.HookStreamer) error { return firstErr }),
+					sandtypes.NewContainerHook("second", func(context.Context, *sandtypes.Container, sandtypes.HookStreamer) error { return secondErr }),
+				}
+			},
+		},
+	})
+	sbox := &sandtypes.Box{ID: "stop-errs", AgentType: "default", ContainerID: "ctr-errs", SandboxWorkDir: t.TempDir()}
+	err := boxer.newLifecycleService().ExecuteStopHooks(context.Background(), sbox)
+	if !errors.Is(err, firstErr) || !errors.Is(err, secondErr) {
+		t.Fatalf("ExecuteStopHooks() error = %v, want both hook errors", err)
+	}
+}
+
 func agentHookForTest(name string) sandtypes.ContainerHook {
 	return sandtypes.NewContainerHook(name, func(ctx context.Context, ctr *sandtypes.Container, exec sandtypes.HookStreamer) error {
 		_, err := exec.Exec(ctx, "agent-hook", name)
@@ -908,6 +1542,115 @@ func TestBoxer_Sync(t *testing.T) {
 			t.Fatalf("Sync() should not return error even if individual syncs fail, got: %v", err)
 		}
 	})
+
+	t.Run("keep going returns every failing sandbox's error", func(t *testing.T) {
+		mockContainer := &hostops.MockContainerOps{
+			InspectFunc: func(ctx context.Context, containerID string) ([]sandtypes.Container, error) {
+				if containerID == "bad-container" {
+					return nil, errors.New("inspect failed")
+				}
+				return []sandtypes.Container{{Configuration: sandtypes.ContainerConfig{ID: containerID}}}, nil
+			},
+		}
+		boxer := newTestBoxer(t, mockContainer, &mockImageOps{})
+		for _, box := range []*sandtypes.Box{
+			{ID: "healthy", Name: "healthy", ContainerID: "good-container", SandboxWorkDir: t.TempDir()},
+			{ID: "no-clone", Name: "no-clone", ContainerID: "good-container", SandboxWorkDir: "/nonexistent/path"},
+			{ID: "no-container", Name: "no-container", ContainerID: "bad-container", SandboxWorkDir: t.TempDir()},
+		} {
+			if err := boxer.SaveSandbox(ctx, box); err != nil {
+				t.Fatalf("SaveSandbox() error = %v", err)
+			}
+		}
+
+		degraded, err := boxer.SyncSandboxes(ctx, SyncOpts{KeepGoing: true})
+		if err == nil {
+			t.Fatal("SyncSandboxes() error = nil, want the failing sandboxes' errors")
+		}
+		for _, id := range []string{"no-clone", "no-container"} {
+			if !strings.Contains(err.Error(), "sandbox "+id+":") {
+				t.Errorf("SyncSandboxes() error = %q, want it to name %s", err, id)
+			}
+		}
+		if strings.Contains(err.Error(), "healthy") {
+			t.Errorf("SyncSandboxes() error = %q, want no error for the healthy sandbox", err)
+		}
+		got := map[string]sandtypes.Box{}
+		for _, box := range degraded {
+			got[box.ID] = box
+		}
+		if len(got) != 2 || got["no-clone"].SandboxWorkDirError == "" || got["no-container"].SandboxContainerError == "" {
+			t.Fatalf("SyncSandboxes() degraded = %+v, want no-clone and no-container with their errors", degraded)
+		}
+
+		if _, err := boxer.SyncSandboxes(ctx, SyncOpts{}); err != nil {
+			t.Fatalf("SyncSandboxes() without KeepGoing error = %v, want nil", err)
+		}
+	})
+
+	t.Run("inspects all containers in one batched call", func(t *testing.T) {
+		mockContainer := &hostops.MockContainerOps{}
+		boxer := newTestBoxer(t, mockContainer, &mockImageOps{})
+		var want []string
+		for i := range 5 {
+			box := &sandtypes.Box{
+				ID:             fmt.Sprintf("sandbox-%d", i),
+				ContainerID:    fmt.Sprintf("container-%d", i),
+				SandboxWorkDir: t.TempDir(),
+				ImageName:      "test-image",
+			}
+			if err := boxer.SaveSandbox(ctx, box); err != nil {
+				t.Fatalf("SaveSandbox() error = %v", err)
+			}
+			want = append(want, box.ContainerID)
+		}
+
+		if err := boxer.Sync(ctx); err != nil {
+			t.Fatalf("Sync() error = %v", err)
+		}
+		if len(mockContainer.InspectCalls) != 1 {
+			t.Fatalf("Inspect calls = %v, want one batched call", mockContainer.InspectCalls)
+		}
+		got := slices.Sorted(slices.Values(mockContainer.InspectCalls[0]))
+		if !slices.Equal(got, want) {
+			t.Fatalf("batched Inspect IDs = %v, want %v", got, want)
+		}
+	})
+}
+
+func TestInspectContainersMapsBatchResultsAndFallsBackForMissing(t *testing.T) {
+	ctx := context.Background()
+	states := map[string]string{"ctr-a": "running", "ctr-b": "stopped", "ctr-c": "running"}
+	mockContainer := &hostops.MockContainerOps{}
+	mockContainer.InspectFunc = func(ctx context.Context, containerID string) ([]sandtypes.Container, error) {
+		// Leave ctr-c out of the batch answer, as a runtime that drops an ID
+		// it can't describe yet would.
+		if containerID == "ctr-c" && len(mockContainer.InspectCalls) == 1 {
+			return nil, nil
+		}
+		return []sandtypes.Container{{Status: sandtypes.ContainerStatus{State: states[containerID]}}}, nil
+	}
+	boxer := newTestBoxer(t, mockContainer, &mockImageOps{})
+
+	boxes := []*sandtypes.Box{
+		{ID: "a", ContainerID: "ctr-a"},
+		{ID: "b", ContainerID: "ctr-b"},
+		{ID: "c", ContainerID: "ctr-c"},
+	}
+	boxer.inspectContainers(ctx, boxes)
+
+	for _, box := range boxes {
+		if box.Container == nil {
+			t.Fatalf("box %s has no container, want %s", box.ID, box.ContainerID)
+		}
+		if box.Container.Configuration.ID != box.ContainerID || box.Container.Status.State != states[box.ContainerID] {
+			t.Errorf("box %s container = %s/%s, want %s/%s", box.ID, box.Container.Configuration.ID, box.Container.Status.State, box.ContainerID, states[box.ContainerID])
+		}
+	}
+	want := [][]string{{"ctr-a", "ctr-b", "ctr-c"}, {"ctr-c"}}
+	if !reflect.DeepEqual(mockContainer.InspectCalls, want) {
+		t.Fatalf("Inspect calls = %v, want %v", mockContainer.InspectCalls, want)
+	}
 }
 
 func TestBoxer_Cleanup_EndToEnd(t *testing.T) {
@@ -1086,8 +1829,8 @@ func TestBoxer_Cleanup_EndToEnd(t *testing.T) {
 			RenameFunc: func(oldpath, newpath string) error {
 				return expectedErr
 			},
-			CopyFunc: func(ctx context.Context, src, dst string) error {
-				return expectedErr
+			CopyFunc: func(ctx context.Context, src, dst string, opts hostops.CopyOpts) (hostops.CopyResult, error) {
+				return hostops.CopyResult{}, expectedErr
 			},
 		}
 
@@ -1184,12 +1927,67 @@ func TestBoxer_EnsureImage(t *testing.T) {
 		mockContainer := &hostops.MockContainerOps{}
 		boxer := newTestBoxer(t, mockContainer, mockImage)
 
-		err := boxer.EnsureImage(ctx, "test-image:latest", io.Discard)
+		err := boxer.EnsureImage(ctx, "test-image:latest", EnsureImageOpts{}, io.Discard)
 		if err != nil {
 			t.Fatalf("EnsureImage() error = %v", err)
 		}
 	})
 
+	t.Run("dockerfile dir builds instead of pulling", func(t *testing.T) {
+		dockerfileDir := t.TempDir()
+		var built *hostops.BuildImage
+		var builtDir string
+		mockImage := &mockImageOps{
+			listFunc: func(ctx context.Context) ([]sandtypes.ImageEntry, error) {
+				t.Error("List called for a Dockerfile build")
+				return nil, nil
+			},
+			pullFunc: func(ctx context.Context, image string, progress imageprogress.Sink) (func() error, error) {
+				t.Errorf("Pull(%q) called for a Dockerfile build", image)
+				return func() error { return nil }, nil
+			},
+			buildFunc: func(ctx context.Context, opts *hostops.BuildImage, contextDir string, w io.Writer) error {
+				built, builtDir = opts, contextDir
+				fmt.Fprintln(w, "#1 DONE")
+				return nil
+			},
+		}
+		boxer := newTestBoxer(t, &hostops.MockContainerOps{}, mockImage)
+
+		var out bytes.Buffer
+		err := boxer.EnsureImage(ctx, "sand-local/box:latest", EnsureImageOpts{
+			PullAlways:    true,
+			DockerfileDir: dockerfileDir,
+			BuildArgs:     map[string]string{"USERNAME": "alice"},
+		}, &out)
+		if err != nil {
+			t.Fatalf("EnsureImage() error = %v", err)
+		}
+		if built == nil || built.Tag != "sand-local/box:latest" || built.BuildArg["USERNAME"] != "alice" || builtDir != dockerfileDir {
+			t.Fatalf("Build(%+v, %q), want tag sand-local/box:latest with USERNAME=alice from %s", built, builtDir, dockerfileDir)
+		}
+		if !strings.Contains(out.String(), "#1 DONE") {
+			t.Errorf("progress = %q, want build output", out.String())
+		}
+		if images, err := boxer.queries.ListSandImages(ctx); err != nil || !slices.Equal(images, []string{"docker.io/sand-local/box:latest"}) {
+			t.Errorf("ListSandImages() = %v, %v, want the built image recorded for prune", images, err)
+		}
+	})
+
+	t.Run("missing dockerfile dir fails before building", func(t *testing.T) {
+		mockImage := &mockImageOps{
+			buildFunc: func(ctx context.Context, opts *hostops.BuildImage, contextDir string, w io.Writer) error {
+				t.Error("Build called for a missing dir")
+				return nil
+			},
+		}
+		boxer := newTestBoxer(t, &hostops.MockContainerOps{}, mockImage)
+		err := boxer.EnsureImage(ctx, "sand-local/box:latest", EnsureImageOpts{DockerfileDir: filepath.Join(t.TempDir(), "missing")}, io.Discard)
+		if err == nil {
+			t.Fatal("EnsureImage() error = nil, want missing dir error")
+		}
+	})
+
 	t.Run("image needs pull", func(t *testing.T) {
 		pullCalled := false
 		waitCalled := false
@@ -1215,7 +2013,7 @@ func TestBoxer_EnsureImage(t *testing.T) {
 		mockContainer := &hostops.MockContainerOps{}
 		boxer := newTestBoxer(t, mockContainer, mockImage)
 
-		err := boxer.EnsureImage(ctx, "new-image:latest", io.Discard)
+		err := boxer.EnsureImage(ctx, "new-image:latest", EnsureImageOpts{}, io.Discard)
 		if err != nil {
 			t.Fatalf("EnsureImage() error = %v", err)
 		}
@@ -1226,6 +2024,199 @@ func TestBoxer_EnsureImage(t *testing.T) {
 		if !waitCalled {
 			t.Error("Expected wait function to be called")
 		}
+		if images, err := boxer.queries.ListSandImages(ctx); err != nil || !slices.Equal(images, []string{"docker.io/library/new-image:latest"}) {
+			t.Errorf("ListSandImages() = %v, %v, want the pulled image recorded for prune", images, err)
+		}
+	})
+
+	t.Run("concurrent requests for a missing image share one pull", func(t *testing.T) {
+		const callers = 5
+		var pulls atomic.Int32
+		var pulled atomic.Bool
+		pullStarted := make(chan struct{})
+		release := make(chan struct{})
+		mockImage := &mockImageOps{
+			listFunc: func(ctx context.Context) ([]sandtypes.ImageEntry, error) {
+				if !pulled.Load() {
+					return nil, nil
+				}
+				return []sandtypes.ImageEntry{{Configuration: sandtypes.ImageConfiguration{Name: "new-image:latest"}}}, nil
+			},
+			pullFunc: func(ctx context.Context, image string, progress imageprogress.Sink) (func() error, error) {
+				if pulls.Add(1) == 1 {
+					close(pullStarted)
+				}
+				return func() error {
+					<-release
+					pulled.Store(true)
+					return nil
+				}, nil
+			},
+		}
+		boxer := newTestBoxer(t, &hostops.MockContainerOps{}, mockImage)
+
+		outs := make([]*syncBuffer, callers)
+		errs := make([]error, callers)
+		var wg sync.WaitGroup
+		for i := range callers {
+			outs[i] = &syncBuffer{}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs[i] = boxer.EnsureImage(ctx, "new-image:latest", EnsureImageOpts{}, outs[i])
+			}()
+		}
+		// Hold the pull until every other caller is waiting on it.
+		<-pullStarted
+		for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(time.Millisecond) {
+			waiting := 0
+			for _, out := range outs {
+				if strings.Contains(out.String(), "Waiting for another request for new-image:latest") {
+					waiting++
+				}
+			}
+			if waiting == callers-1 {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("%d of %d callers waiting on the pull", waiting, callers-1)
+			}
+		}
+		close(release)
+		wg.Wait()
+
+		for i, err := range errs {
+			if err != nil {
+				t.Errorf("EnsureImage() call %d error = %v", i, err)
+			}
+		}
+		if got := pulls.Load(); got != 1 {
+			t.Fatalf("Pull called %d times, want once for %d concurrent callers", got, callers)
+		}
+	})
+
+	const pinned = "ghcr.io/acme/base@sha256:aaa"
+	digestImageOps := func(localDigest *string, pulls *[]string, pulledDigest string) *mockImageOps {
+		return &mockImageOps{
+			listFunc: func(ctx context.Context) ([]sandtypes.ImageEntry, error) {
+				return []sandtypes.ImageEntry{{Configuration: sandtypes.ImageConfiguration{Name: "ghcr.io/acme/base:latest"}}}, nil
+			},
+			inspectFunc: func(ctx context.Context, name string) ([]*sandtypes.ImageManifest, error) {
+				if *localDigest == "" {
+					return nil, nil
+				}
+				return []*sandtypes.ImageManifest{{Name: name, Index: sandtypes.Index{Digest: *localDigest}}}, nil
+			},
+			pullFunc: func(ctx context.Context, image string, progress imageprogress.Sink) (func() error, error) {
+				*pulls = append(*pulls, image)
+				*localDigest = pulledDigest
+				return func() error { return nil }, nil
+			},
+		}
+	}
+
+	t.Run("pinned digest reuses matching local image", func(t *testing.T) {
+		localDigest := "sha256:aaa"
+		var pulls []string
+		boxer := newTestBoxer(t, &hostops.MockContainerOps{}, digestImageOps(&localDigest, &pulls, "sha256:aaa"))
+
+		if err := boxer.EnsureImage(ctx, pinned, EnsureImageOpts{}, io.Discard); err != nil {
+			t.Fatalf("EnsureImage() error = %v", err)
+		}
+		if len(pulls) != 0 {
+			t.Fatalf("pulls = %v, want none for a matching digest", pulls)
+		}
+	})
+
+	t.Run("pinned digest pulls when local digest differs", func(t *testing.T) {
+		localDigest := "sha256:old"
+		var pulls []string
+		boxer := newTestBoxer(t, &hostops.MockContainerOps{}, digestImageOps(&localDigest, &pulls, "sha256:aaa"))
+
+		if err := boxer.EnsureImage(ctx, pinned, EnsureImageOpts{}, io.Discard); err != nil {
+			t.Fatalf("EnsureImage() error = %v", err)
+		}
+		if !slices.Equal(pulls, []string{pinned}) {
+			t.Fatalf("pulls = %v, want [%s]", pulls, pinned)
+		}
+	})
+
+	t.Run("pinned digest fails verification after pull", func(t *testing.T) {
+		localDigest := ""
+		var pulls []string
+		boxer := newTestBoxer(t, &hostops.MockContainerOps{}, digestImageOps(&localDigest, &pulls, "sha256:bbb"))
+
+		err := boxer.EnsureImage(ctx, pinned, EnsureImageOpts{}, io.Discard)
+		if err == nil || !strings.Contains(err.Error(), "want sha256:aaa") {
+			t.Fatalf("EnsureImage() error = %v, want digest mismatch", err)
+		}
+	})
+
+	t.Run("tag-only reference reuses a present image without inspecting digests", func(t *testing.T) {
+		var pulls []string
+		mockImage := &mockImageOps{
+			listFunc: func(ctx context.Context) ([]sandtypes.ImageEntry, error) {
+				return []sandtypes.ImageEntry{{Configuration: sandtypes.ImageConfiguration{Name: "local/base:dev"}}}, nil
+			},
+			inspectFunc: func(ctx context.Context, name string) ([]*sandtypes.ImageManifest, error) {
+				t.Errorf("Inspect(%q) called for a tag-only local image", name)
+				return nil, nil
+			},
+			pullFunc: func(ctx context.Context, image string, progress imageprogress.Sink) (func() error, error) {
+				pulls = append(pulls, image)
+				return func() error { return nil }, nil
+			},
+		}
+		boxer := newTestBoxer(t, &hostops.MockContainerOps{}, mockImage)
+
+		if err := boxer.EnsureImage(ctx, "local/base:dev", EnsureImageOpts{}, io.Discard); err != nil {
+			t.Fatalf("EnsureImage() error = %v", err)
+		}
+		if len(pulls) != 0 {
+			t.Fatalf("pulls = %v, want none", pulls)
+		}
+	})
+
+	t.Run("pull always pulls a present tag", func(t *testing.T) {
+		var pulls []string
+		mockImage := &mockImageOps{
+			listFunc: func(ctx context.Context) ([]sandtypes.ImageEntry, error) {
+				return []sandtypes.ImageEntry{{Configuration: sandtypes.ImageConfiguration{Name: "local/base:dev"}}}, nil
+			},
+			pullFunc: func(ctx context.Context, image string, progress imageprogress.Sink) (func() error, error) {
+				pulls = append(pulls, image)
+				return func() error { return nil }, nil
+			},
+		}
+		boxer := newTestBoxer(t, &hostops.MockContainerOps{}, mockImage)
+
+		if err := boxer.EnsureImage(ctx, "local/base:dev", EnsureImageOpts{PullAlways: true}, io.Discard); err != nil {
+			t.Fatalf("EnsureImage() error = %v", err)
+		}
+		if !slices.Equal(pulls, []string{"local/base:dev"}) {
+			t.Fatalf("pulls = %v, want [local/base:dev]", pulls)
+		}
+	})
+
+	t.Run("pull auth failure names the registry", func(t *testing.T) {
+		mockImage := &mockImageOps{
+			pullFunc: func(ctx context.Context, image string, progress imageprogress.Sink) (func() error, error) {
+				return nil, errors.New("pull image \"ghcr.io/acme/private:latest\": 401 Unauthorized")
+			},
+		}
+		boxer := newTestBoxer(t, &hostops.MockContainerOps{}, mockImage)
+
+		err := boxer.EnsureImage(ctx, "ghcr.io/acme/private:latest", EnsureImageOpts{}, io.Discard)
+		var authErr *hostops.RegistryAuthError
+		if !errors.As(err, &authErr) {
+			t.Fatalf("EnsureImage() error = %v, want RegistryAuthError", err)
+		}
+		if authErr.Registry != "ghcr.io" {
+			t.Errorf("RegistryAuthError.Registry = %q, want ghcr.io", authErr.Registry)
+		}
+		if !strings.Contains(err.Error(), "authentication required for registry ghcr.io") {
+			t.Errorf("EnsureImage() error = %q, want authentication guidance", err)
+		}
 	})
 
 	t.Run("list error", func(t *testing.T) {
@@ -1239,7 +2230,7 @@ func TestBoxer_EnsureImage(t *testing.T) {
 		mockContainer := &hostops.MockContainerOps{}
 		boxer := newTestBoxer(t, mockContainer, mockImage)
 
-		err := boxer.EnsureImage(ctx, "test-image:latest", io.Discard)
+		err := boxer.EnsureImage(ctx, "test-image:latest", EnsureImageOpts{}, io.Discard)
 		if err == nil {
 			t.Fatal("Expected error from list, got nil")
 		}
@@ -1255,7 +2246,7 @@ func TestBoxer_EnsureImage(t *testing.T) {
 		mockContainer := &hostops.MockContainerOps{}
 		boxer := newTestBoxer(t, mockContainer, mockImage)
 
-		err := boxer.EnsureImage(ctx, "test-image:latest", io.Discard)
+		err := boxer.EnsureImage(ctx, "test-image:latest", EnsureImageOpts{}, io.Discard)
 		if err == nil {
 			t.Fatal("Expected error from list, got nil")
 		}
@@ -1281,7 +2272,7 @@ func TestBoxer_EnsureImage(t *testing.T) {
 		mockContainer := &hostops.MockContainerOps{}
 		boxer := newTestBoxer(t, mockContainer, mockImage)
 
-		err := boxer.EnsureImage(ctx, "test-image:latest", io.Discard)
+		err := boxer.EnsureImage(ctx, "test-image:latest", EnsureImageOpts{}, io.Discard)
 		if err == nil {
 			t.Fatal("Expected error from pull, got nil")
 		}
@@ -1303,7 +2294,7 @@ func TestBoxer_EnsureImage(t *testing.T) {
 		mockContainer := &hostops.MockContainerOps{}
 		boxer := newTestBoxer(t, mockContainer, mockImage)
 
-		err := boxer.EnsureImage(ctx, "test-image:latest", io.Discard)
+		err := boxer.EnsureImage(ctx, "test-image:latest", EnsureImageOpts{}, io.Discard)
 		if err == nil {
 			t.Fatal("Expected error from wait, got nil")
 		}
@@ -1511,3 +2502,21 @@ func TestBoxer_StopContainer(t *testing.T) {
 		}
 	})
 }
+
+// syncBuffer lets a test read what a concurrent call has written so far.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
@@ -2,14 +2,22 @@ package boxer
 
 import (
 	"context"
+	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
 	"sort"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/banksean/sand/internal/db"
 	"github.com/banksean/sand/internal/hostops"
 	"github.com/banksean/sand/internal/sandtypes"
+	"github.com/banksean/sand/internal/sshimmer"
 )
 
 func TestEnsureSharedCacheMounts_GoCachesUseMiseMount(t *testing.T) {
@@ -227,6 +235,17 @@ func TestSaveSandbox(t *testing.T) {
 			ReadOnly: true,
 			Runtime:  "type=bind,source=/host,target=/container,readonly",
 		}},
+		Labels:         map[string]string{"project": "sand", "team": ""},
+		Shell:          "/bin/bash",
+		NoDotfiles:     true,
+		NoSSH:          true,
+		AllowEmulation: true,
+		Publish:        []string{"8080:80", "127.0.0.1:5353:53/udp"},
+		DNS: sandtypes.DNSConfig{
+			Nameservers:   []string{"10.0.0.53", "10.0.1.53"},
+			SearchDomains: []string{"corp.example.com"},
+			Options:       []string{"ndots:2"},
+		},
 	}
 
 	// Create the sandbox directory
@@ -280,6 +299,21 @@ func TestSaveSandbox(t *testing.T) {
 	if loadedSandbox.MountRequests[0].Kind != sandtypes.MountKindBind || loadedSandbox.MountRequests[0].Runtime != "type=bind,source=/host,target=/container,readonly" {
 		t.Errorf("MountRequests[0] = %+v", loadedSandbox.MountRequests[0])
 	}
+	if !maps.Equal(loadedSandbox.Labels, testSandbox.Labels) {
+		t.Errorf("Labels mismatch: got %v, want %v", loadedSandbox.Labels, testSandbox.Labels)
+	}
+	if loadedSandbox.Shell != testSandbox.Shell {
+		t.Errorf("Shell mismatch: got %q, want %q", loadedSandbox.Shell, testSandbox.Shell)
+	}
+	if !loadedSandbox.NoDotfiles || !loadedSandbox.NoSSH || !loadedSandbox.AllowEmulation {
+		t.Errorf("NoDotfiles, NoSSH, AllowEmulation = %v, %v, %v; want all true", loadedSandbox.NoDotfiles, loadedSandbox.NoSSH, loadedSandbox.AllowEmulation)
+	}
+	if !slices.Equal(loadedSandbox.Publish, testSandbox.Publish) {
+		t.Errorf("Publish = %q, want %q", loadedSandbox.Publish, testSandbox.Publish)
+	}
+	if !reflect.DeepEqual(loadedSandbox.DNS, testSandbox.DNS) {
+		t.Errorf("DNS = %+v, want %+v", loadedSandbox.DNS, testSandbox.DNS)
+	}
 
 	// Test that UpsertSandbox works (update existing)
 	testSandbox.ContainerID = "updated-container-999"
@@ -296,6 +330,113 @@ func TestSaveSandbox(t *testing.T) {
 	}
 }
 
+func TestUpdateImageNameRecordsTheNewImagesDigest(t *testing.T) {
+	ctx := context.Background()
+	sb := newDBBoxer(t, t.TempDir())
+	sb.ImageService = &mockImageOps{
+		inspectFunc: func(ctx context.Context, name string) ([]*sandtypes.ImageManifest, error) {
+			return []*sandtypes.ImageManifest{{Name: name, Index: sandtypes.Index{Digest: "sha256:" + name}}}, nil
+		},
+	}
+	sbox := &sandtypes.Box{ID: "moved", ImageName: "old", ImageDigest: "sha256:old"}
+	if err := sb.SaveSandbox(ctx, sbox); err != nil {
+		t.Fatalf("SaveSandbox() error = %v", err)
+	}
+
+	if err := sb.UpdateImageName(ctx, sbox, "new"); err != nil {
+		t.Fatalf("UpdateImageName() error = %v", err)
+	}
+	if sbox.ImageName != "new" || sbox.ImageDigest != "sha256:new" {
+		t.Errorf("sandbox image = %s@%s, want new@sha256:new", sbox.ImageName, sbox.ImageDigest)
+	}
+	loaded, err := sb.Get(ctx, "moved")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if loaded.ImageName != "new" || loaded.ImageDigest != "sha256:new" {
+		t.Errorf("saved image = %s@%s, want new@sha256:new", loaded.ImageName, loaded.ImageDigest)
+	}
+}
+
+func TestSaveSandboxConcurrentWritersDoNotHitLockedDatabase(t *testing.T) {
+	appRoot := t.TempDir()
+	// Two Boxers have separate connection pools on one database file, as the
+	// daemon and a second process would.
+	boxers := []*Boxer{newDBBoxer(t, appRoot), newDBBoxer(t, appRoot)}
+	for _, b := range boxers {
+		var journalMode string
+		var busyTimeout int
+		if err := b.sqlDB.QueryRow("PRAGMA journal_mode").Scan(&journalMode); err != nil || journalMode != "wal" {
+			t.Fatalf("journal_mode = %q (%v), want wal", journalMode, err)
+		}
+		if err := b.sqlDB.QueryRow("PRAGMA busy_timeout").Scan(&busyTimeout); err != nil || busyTimeout != 5000 {
+			t.Fatalf("busy_timeout = %d (%v), want 5000", busyTimeout, err)
+		}
+	}
+
+	const perBoxer = 25
+	ctx := context.Background()
+	errs := make(chan error, len(boxers)*perBoxer)
+	var wg sync.WaitGroup
+	for i, b := range boxers {
+		for j := range perBoxer {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				id := fmt.Sprintf("box-%d-%d", i, j)
+				errs <- b.SaveSandbox(ctx, &sandtypes.Box{ID: id, SandboxWorkDir: filepath.Join(appRoot, id)})
+			}()
+		}
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("SaveSandbox() error = %v (busy: %v)", err, db.IsBusy(err))
+		}
+	}
+
+	boxes, err := boxers[0].List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(boxes) != len(boxers)*perBoxer {
+		t.Fatalf("List() = %d sandboxes, want %d", len(boxes), len(boxers)*perBoxer)
+	}
+}
+
+func TestSaveSandboxRoundTripsMounts(t *testing.T) {
+	ctx := context.Background()
+	sb := newDBBoxer(t, t.TempDir())
+	mounts := []sandtypes.MountSpec{
+		{Source: "/clones/abc/app", Target: "/app"},
+		{Source: "/clones/abc/sshkeys", Target: "/sshkeys", ReadOnly: true},
+		{Source: "/Users/me/data", Target: "/data"},
+	}
+	if err := sb.SaveSandbox(ctx, &sandtypes.Box{ID: "mounts-id", SandboxWorkDir: "/clones/abc", ImageName: "test-image", Mounts: mounts}); err != nil {
+		t.Fatalf("SaveSandbox() error = %v", err)
+	}
+
+	loaded, err := sb.Get(ctx, "mounts-id")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if !reflect.DeepEqual(loaded.Mounts, mounts) {
+		t.Fatalf("Mounts = %+v, want %+v", loaded.Mounts, mounts)
+	}
+
+	if err := sb.SaveSandbox(ctx, &sandtypes.Box{ID: "no-mounts-id", SandboxWorkDir: "/clones/def", ImageName: "test-image"}); err != nil {
+		t.Fatalf("SaveSandbox() error = %v", err)
+	}
+	loaded, err = sb.Get(ctx, "no-mounts-id")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if loaded.Mounts != nil {
+		t.Fatalf("Mounts = %+v, want nil for a sandbox saved without mounts", loaded.Mounts)
+	}
+}
+
 func TestLoadSandbox(t *testing.T) {
 	// Create a temporary directory for testing
 	tmpDir, err := os.MkdirTemp("", "sandbox-test-*")
@@ -432,6 +573,45 @@ func TestListSandboxes(t *testing.T) {
 	}
 }
 
+func TestListSyncedReturnsEverySandboxWithSyncStatus(t *testing.T) {
+	ctx := context.Background()
+	mockContainer := &hostops.MockContainerOps{
+		InspectFunc: func(ctx context.Context, containerID string) ([]sandtypes.Container, error) {
+			return []sandtypes.Container{{Status: sandtypes.ContainerStatus{State: "running"}}}, nil
+		},
+	}
+	sb := newTestBoxer(t, mockContainer, &mockImageOps{})
+	for id, workDir := range map[string]string{"healthy": t.TempDir(), "degraded": filepath.Join(t.TempDir(), "missing")} {
+		if err := sb.SaveSandbox(ctx, &sandtypes.Box{ID: id, Name: id, ContainerID: "ctr-" + id, SandboxWorkDir: workDir}); err != nil {
+			t.Fatalf("SaveSandbox(%s) error = %v", id, err)
+		}
+	}
+
+	boxes, err := sb.ListSynced(ctx)
+	if err != nil {
+		t.Fatalf("ListSynced() error = %v", err)
+	}
+	byID := map[string]sandtypes.Box{}
+	for _, box := range boxes {
+		byID[box.ID] = box
+	}
+	if len(byID) != 2 {
+		t.Fatalf("ListSynced() = %+v, want both sandboxes", boxes)
+	}
+	for id, wantWorkDirError := range map[string]string{"healthy": "", "degraded": "NO CLONE DIR"} {
+		box := byID[id]
+		if box.Container == nil || box.Container.Status.State != "running" {
+			t.Errorf("%s container = %+v, want its running status", id, box.Container)
+		}
+		if box.SandboxWorkDirError != wantWorkDirError {
+			t.Errorf("%s SandboxWorkDirError = %q, want %q", id, box.SandboxWorkDirError, wantWorkDirError)
+		}
+	}
+	if got := len(mockContainer.InspectCalls); got != 1 {
+		t.Fatalf("Inspect calls = %v, want one batched inspect for the whole list", mockContainer.InspectCalls)
+	}
+}
+
 func TestUpdateContainerID(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "sandbox-test-*")
 	if err != nil {
@@ -477,6 +657,54 @@ func TestUpdateContainerID(t *testing.T) {
 	}
 }
 
+func TestSandboxTimestamps(t *testing.T) {
+	tmpDir := t.TempDir()
+	sb := newDBBoxer(t, tmpDir)
+	ctx := context.Background()
+
+	testBox := &sandtypes.Box{
+		ID:             "test-timestamps",
+		HostOriginDir:  "/tmp/host",
+		SandboxWorkDir: tmpDir,
+		ImageName:      "test-image",
+	}
+	if err := sb.SaveSandbox(ctx, testBox); err != nil {
+		t.Fatalf("Failed to save sandbox: %v", err)
+	}
+
+	loaded, err := sb.Get(ctx, "test-timestamps")
+	if err != nil {
+		t.Fatalf("Failed to get sandbox: %v", err)
+	}
+	if loaded.CreatedAt.IsZero() {
+		t.Error("CreatedAt is zero after SaveSandbox")
+	}
+	if !loaded.LastUsedAt.IsZero() {
+		t.Errorf("LastUsedAt = %v, want zero before first use", loaded.LastUsedAt)
+	}
+	createdAt := loaded.CreatedAt
+
+	before := time.Now().Add(-time.Second)
+	if err := sb.MarkUsed(ctx, loaded); err != nil {
+		t.Fatalf("MarkUsed() error = %v", err)
+	}
+	// Re-saving must not reset created_at or clobber last_used_at.
+	if err := sb.SaveSandbox(ctx, loaded); err != nil {
+		t.Fatalf("Failed to re-save sandbox: %v", err)
+	}
+
+	loaded, err = sb.Get(ctx, "test-timestamps")
+	if err != nil {
+		t.Fatalf("Failed to get sandbox: %v", err)
+	}
+	if loaded.LastUsedAt.Before(before) {
+		t.Errorf("LastUsedAt = %v, want after %v", loaded.LastUsedAt, before)
+	}
+	if !loaded.CreatedAt.Equal(createdAt) {
+		t.Errorf("CreatedAt = %v after re-save, want %v", loaded.CreatedAt, createdAt)
+	}
+}
+
 func TestGetSandboxesByImage(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "sandbox-test-*")
 	if err != nil {
@@ -528,3 +756,104 @@ func TestGetSandboxesByImage(t *testing.T) {
 		}
 	}
 }
+
+func TestSaveSSHKeysVerifiesEachKeyFile(t *testing.T) {
+	b := &Boxer{FileOps: &hostops.MockFileOps{MkdirAllFunc: os.MkdirAll, CreateFunc: os.Create}}
+	valid, err := sshimmer.NewTestKeys("box.dev.local")
+	if err != nil {
+		t.Fatalf("NewTestKeys() error = %v", err)
+	}
+	if err := b.saveSSHKeys(t.TempDir(), valid); err != nil {
+		t.Fatalf("saveSSHKeys() with valid keys error = %v", err)
+	}
+
+	for _, tc := range []struct {
+		name    string
+		corrupt func(k *sshimmer.Keys)
+		badFile string
+	}{
+		{"truncated host key", func(k *sshimmer.Keys) { k.HostKey = k.HostKey[:len(k.HostKey)/2] }, "ssh_host_key"},
+		{"garbage host public key", func(k *sshimmer.Keys) { k.HostKeyPub = []byte("ssh-ed25519 AAAA\n") }, "ssh_host_key.pub"},
+		{"plain key instead of certificate", func(k *sshimmer.Keys) { k.HostKeyCert = k.HostKeyPub }, "ssh_host_key.pub-cert"},
+		{"empty user CA", func(k *sshimmer.Keys) { k.UserCAPub = nil }, "user_ca.pub"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			keys := *valid
+			tc.corrupt(&keys)
+			dir := t.TempDir()
+			err := b.saveSSHKeys(dir, &keys)
+			if err == nil {
+				t.Fatal("saveSSHKeys() error = nil, want a corrupt key file error")
+			}
+			if want := filepath.Join(dir, tc.badFile) + " is corrupt"; !strings.Contains(err.Error(), want) {
+				t.Fatalf("saveSSHKeys() error = %v, want it to contain %q", err, want)
+			}
+		})
+	}
+}
+
+func TestNewSandboxNameSkipsActiveNames(t *testing.T) {
+	ctx := context.Background()
+	sb := newDBBoxer(t, t.TempDir())
+	if err := sb.SaveSandbox(ctx, &sandtypes.Box{ID: "taken-id", Name: "hidden-bush", State: "active"}); err != nil {
+		t.Fatalf("SaveSandbox() error = %v", err)
+	}
+	if err := sb.SaveSandbox(ctx, &sandtypes.Box{ID: "gone-id", Name: "quiet-tree", State: "deleted"}); err != nil {
+		t.Fatalf("SaveSandbox() error = %v", err)
+	}
+
+	for name, want := range map[string]bool{"hidden-bush": true, "quiet-tree": false, "misty-lake": false} {
+		got, err := sb.sandboxNameTaken(ctx, name)
+		if err != nil {
+			t.Fatalf("sandboxNameTaken(%q) error = %v", name, err)
+		}
+		if got != want {
+			t.Errorf("sandboxNameTaken(%q) = %v, want %v", name, got, want)
+		}
+	}
+
+	name, err := sb.NewSandboxName(ctx)
+	if err != nil {
+		t.Fatalf("NewSandboxName() error = %v", err)
+	}
+	if name == "hidden-bush" || !sandtypes.IsValidSandboxID(name) {
+		t.Fatalf("NewSandboxName() = %q, want a valid unused name", name)
+	}
+}
+
+func TestExpungeForgetsTheSandboxLock(t *testing.T) {
+	ctx := context.Background()
+	sb := newDBBoxer(t, t.TempDir())
+	if err := sb.SaveSandbox(ctx, &sandtypes.Box{ID: "expunged", Name: "expunged"}); err != nil {
+		t.Fatalf("SaveSandbox() error = %v", err)
+	}
+	if err := sb.queries.SoftDeleteSandbox(ctx, db.SoftDeleteSandboxParams{ID: "expunged"}); err != nil {
+		t.Fatalf("SoftDeleteSandbox() error = %v", err)
+	}
+	if err := sb.Expunge(ctx, "expunged"); err != nil {
+		t.Fatalf("Expunge() error = %v", err)
+	}
+	if _, ok := sb.sandboxLocks.Load("expunged"); ok {
+		t.Error("Expunge() left the sandbox's lock behind")
+	}
+}
+
+func TestLockSandboxWaiterTakesTheLockThatReplacesAForgottenOne(t *testing.T) {
+	sb := newDBBoxer(t, t.TempDir())
+	unlock := sb.lockSandbox("id")
+	locked := make(chan func())
+	go func() { locked <- sb.lockSandbox("id") }()
+	time.Sleep(10 * time.Millisecond)
+	sb.forgetSandboxLock("id")
+	unlock()
+
+	unlockWaiter := <-locked
+	defer unlockWaiter()
+	v, ok := sb.sandboxLocks.Load("id")
+	if !ok {
+		t.Fatal("no lock in the map while the waiter holds it")
+	}
+	if v.(*sync.Mutex).TryLock() {
+		t.Fatal("a new caller could take the lock the waiter holds")
+	}
+}
@@ -0,0 +1,157 @@
+package boxer
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/banksean/sand/internal/hostops"
+	"github.com/banksean/sand/internal/sandtypes"
+)
+
+// inspectCachingContainerOps wraps a ContainerOps so that listing sandboxes
+// can reuse container inspect results for ttl, sparing the container CLI when
+// sand ls or a watching client lists in a tight loop. Its own Inspect is not
+// cached, so lifecycle code waiting on a state change always sees the current
+// state. Any call that changes a container's state drops the whole cache,
+// since a create or delete can also change what another ID resolves to.
+type inspectCachingContainerOps struct {
+	hostops.ContainerOps
+	ttl time.Duration
+	now func() time.Time
+
+	mu sync.Mutex
+	// generation counts invalidations, so an Inspect that raced with a state
+	// change doesn't cache what it saw before the change.
+	generation int
+	// entries holds each container's last inspection. A nil container records
+	// that a single-ID Inspect found nothing.
+	entries map[string]cachedInspect
+}
+
+type cachedInspect struct {
+	ctr *sandtypes.Container
+	at  time.Time
+}
+
+// CacheContainerInspect makes sb's List and Sync reuse container inspect
+// results for up to ttl. Starting, stopping, killing, creating or deleting a
+// container through sb invalidates them. It must be called before sb is put
+// to use.
+func (sb *Boxer) CacheContainerInspect(ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+	sb.inspectCache = &inspectCachingContainerOps{
+		ContainerOps: sb.ContainerService,
+		ttl:          ttl,
+		now:          sb.now,
+		entries:      map[string]cachedInspect{},
+	}
+	sb.ContainerService = sb.inspectCache
+}
+
+// listInspect is ContainerService.Inspect for code that only reports state,
+// which may be answered from the inspect cache.
+func (sb *Boxer) listInspect(ctx context.Context, containerID ...string) ([]sandtypes.Container, error) {
+	// A test or caller that swapped ContainerService out bypasses the cache.
+	if sb.inspectCache == nil || sb.ContainerService != hostops.ContainerOps(sb.inspectCache) {
+		return sb.ContainerService.Inspect(ctx, containerID...)
+	}
+	return sb.inspectCache.cachedInspect(ctx, containerID...)
+}
+
+func (c *inspectCachingContainerOps) cachedInspect(ctx context.Context, containerID ...string) ([]sandtypes.Container, error) {
+	ctrs, generation, ok := c.cached(containerID)
+	if ok {
+		return ctrs, nil
+	}
+	ctrs, err := c.ContainerOps.Inspect(ctx, containerID...)
+	if err != nil {
+		return nil, err
+	}
+	c.store(generation, containerID, ctrs)
+	return ctrs, nil
+}
+
+// cached returns the cached inspection of every ID in ids, in order, if each
+// is still fresh, along with the cache's current generation.
+func (c *inspectCachingContainerOps) cached(ids []string) ([]sandtypes.Container, int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(ids) == 0 {
+		return nil, c.generation, false
+	}
+	now := c.now()
+	var ctrs []sandtypes.Container
+	for _, id := range ids {
+		entry, ok := c.entries[id]
+		if !ok || now.Sub(entry.at) >= c.ttl {
+			return nil, c.generation, false
+		}
+		if entry.ctr == nil {
+			// Only a single-ID Inspect records a missing container, and
+			// those answer with an empty result.
+			if len(ids) > 1 {
+				return nil, c.generation, false
+			}
+			continue
+		}
+		ctrs = append(ctrs, *entry.ctr)
+	}
+	return ctrs, c.generation, true
+}
+
+func (c *inspectCachingContainerOps) store(generation int, ids []string, ctrs []sandtypes.Container) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation != c.generation {
+		return
+	}
+	now := c.now()
+	if len(ids) == 1 {
+		entry := cachedInspect{at: now}
+		if len(ctrs) > 0 {
+			ctr := ctrs[0]
+			entry.ctr = &ctr
+		}
+		c.entries[ids[0]] = entry
+		return
+	}
+	for i := range ctrs {
+		ctr := ctrs[i]
+		c.entries[ctr.Configuration.ID] = cachedInspect{ctr: &ctr, at: now}
+	}
+}
+
+func (c *inspectCachingContainerOps) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	clear(c.entries)
+}
+
+func (c *inspectCachingContainerOps) Create(ctx context.Context, opts *hostops.CreateContainer, image string, args []string) (string, error) {
+	defer c.invalidate()
+	return c.ContainerOps.Create(ctx, opts, image, args)
+}
+
+func (c *inspectCachingContainerOps) Start(ctx context.Context, opts *hostops.StartContainer, containerID string) (string, error) {
+	defer c.invalidate()
+	return c.ContainerOps.Start(ctx, opts, containerID)
+}
+
+func (c *inspectCachingContainerOps) Stop(ctx context.Context, opts *hostops.StopContainer, containerID string) (string, error) {
+	defer c.invalidate()
+	return c.ContainerOps.Stop(ctx, opts, containerID)
+}
+
+func (c *inspectCachingContainerOps) Kill(ctx context.Context, opts *hostops.KillContainer, containerID string) (string, error) {
+	defer c.invalidate()
+	return c.ContainerOps.Kill(ctx, opts, containerID)
+}
+
+func (c *inspectCachingContainerOps) Delete(ctx context.Context, opts *hostops.DeleteContainer, containerID string) (string, error) {
+	defer c.invalidate()
+	return c.ContainerOps.Delete(ctx, opts, containerID)
+}
@@ -0,0 +1,95 @@
+package boxer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/banksean/sand/internal/hostops"
+	"github.com/banksean/sand/internal/sandtypes"
+)
+
+// cachingTestBoxer returns a Boxer with a one-minute inspect cache, a clock
+// the test advances with the returned func, and one saved sandbox whose
+// container is running.
+func cachingTestBoxer(t *testing.T) (*Boxer, *hostops.MockContainerOps, func(time.Duration)) {
+	t.Helper()
+	mockContainer := &hostops.MockContainerOps{
+		InspectFunc: func(ctx context.Context, containerID string) ([]sandtypes.Container, error) {
+			return []sandtypes.Container{{Status: sandtypes.ContainerStatus{State: "running"}}}, nil
+		},
+	}
+	b := newTestBoxer(t, mockContainer, &mockImageOps{})
+	now := time.Now()
+	b.now = func() time.Time { return now }
+	b.CacheContainerInspect(time.Minute)
+	if err := b.SaveSandbox(context.Background(), &sandtypes.Box{ID: "cached", Name: "cached", ContainerID: "ctr-cached", SandboxWorkDir: t.TempDir()}); err != nil {
+		t.Fatalf("SaveSandbox() error = %v", err)
+	}
+	return b, mockContainer, func(d time.Duration) { now = now.Add(d) }
+}
+
+func listState(t *testing.T, b *Boxer) string {
+	t.Helper()
+	boxes, err := b.List(context.Background())
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(boxes) != 1 || boxes[0].Container == nil {
+		t.Fatalf("List() = %+v, want one sandbox with a container", boxes)
+	}
+	return boxes[0].Container.Status.State
+}
+
+func TestBoxer_ListReusesCachedInspect(t *testing.T) {
+	b, mockContainer, advance := cachingTestBoxer(t)
+
+	listState(t, b)
+	if err := b.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	listState(t, b)
+	if got := len(mockContainer.InspectCalls); got != 1 {
+		t.Fatalf("Inspect calls = %v, want one for a list, sync and list inside the cache window", mockContainer.InspectCalls)
+	}
+
+	advance(time.Minute)
+	listState(t, b)
+	if got := len(mockContainer.InspectCalls); got != 2 {
+		t.Fatalf("Inspect calls = %v, want a fresh inspect once the cache expires", mockContainer.InspectCalls)
+	}
+}
+
+func TestBoxer_StopInvalidatesCachedInspect(t *testing.T) {
+	b, mockContainer, _ := cachingTestBoxer(t)
+	ctx := context.Background()
+
+	if got := listState(t, b); got != "running" {
+		t.Fatalf("state = %q, want running", got)
+	}
+	mockContainer.InspectFunc = func(ctx context.Context, containerID string) ([]sandtypes.Container, error) {
+		return []sandtypes.Container{{Status: sandtypes.ContainerStatus{State: "stopped"}}}, nil
+	}
+	sbox, err := b.GetByID(ctx, "cached")
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if err := b.StopContainer(ctx, sbox); err != nil {
+		t.Fatalf("StopContainer() error = %v", err)
+	}
+	if got := listState(t, b); got != "stopped" {
+		t.Fatalf("state after stop = %q, want the cache refreshed to stopped", got)
+	}
+}
+
+func TestBoxer_GetContainerBypassesInspectCache(t *testing.T) {
+	b, mockContainer, _ := cachingTestBoxer(t)
+
+	listState(t, b)
+	if _, err := b.GetContainer(context.Background(), "ctr-cached"); err != nil {
+		t.Fatalf("GetContainer() error = %v", err)
+	}
+	if got := len(mockContainer.InspectCalls); got != 2 {
+		t.Fatalf("Inspect calls = %v, want GetContainer to inspect the container itself", mockContainer.InspectCalls)
+	}
+}
@@ -0,0 +1,68 @@
+package boxer
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/banksean/sand/internal/sandtypes"
+)
+
+// eventBufferSize is how many events a subscriber may fall behind by before
+// newer events are dropped for it.
+const eventBufferSize = 64
+
+// eventHub fans sandbox lifecycle events out to subscribers. The zero value
+// is ready to use.
+type eventHub struct {
+	mu   sync.Mutex
+	subs map[chan sandtypes.SandboxEvent]struct{}
+}
+
+// Subscribe returns a channel that receives sandbox lifecycle events until the
+// returned cancel func is called. Events are delivered in the order they
+// happen; a subscriber that stops reading misses events rather than blocking
+// the operations that emit them.
+func (sb *Boxer) Subscribe() (<-chan sandtypes.SandboxEvent, func()) {
+	ch := make(chan sandtypes.SandboxEvent, eventBufferSize)
+	h := &sb.events
+	h.mu.Lock()
+	if h.subs == nil {
+		h.subs = map[chan sandtypes.SandboxEvent]struct{}{}
+	}
+	h.subs[ch] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subs, ch)
+			h.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// publish sends an event for sbox to every subscriber. errMsg is only set for
+// error events.
+func (sb *Boxer) publish(ctx context.Context, typ sandtypes.SandboxEventType, sbox *sandtypes.Box, errMsg string) {
+	event := sandtypes.SandboxEvent{
+		Type:        typ,
+		SandboxID:   sbox.ID,
+		SandboxName: sbox.Name,
+		Time:        sb.now().UTC(),
+		Error:       errMsg,
+	}
+	sb.metrics.recordEvent(typ)
+	h := &sb.events
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs {
+		select {
+		case ch <- event:
+		default:
+			slog.WarnContext(ctx, "Boxer.publish dropping event for slow subscriber", "type", typ, "id", sbox.ID)
+		}
+	}
+}
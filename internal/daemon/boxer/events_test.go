@@ -0,0 +1,113 @@
+package boxer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/banksean/sand/internal/agents"
+	"github.com/banksean/sand/internal/cloning"
+	"github.com/banksean/sand/internal/hostops"
+	"github.com/banksean/sand/internal/sandtypes"
+)
+
+func TestBoxerPublishesLifecycleEventsInOrder(t *testing.T) {
+	ctx := context.Background()
+	b := newTestBoxer(t, &hostops.MockContainerOps{}, &mockImageOps{})
+	b.FileOps = &hostops.MockFileOps{
+		MkdirAllFunc: os.MkdirAll,
+		CreateFunc:   os.Create,
+	}
+	b.AgentRegistry.Register(&agents.AgentConfig{
+		Name: "test-agent",
+		Preparation: &mockWorkspacePreparation{
+			prepareFunc: func(ctx context.Context, req cloning.CloneRequest) (*cloning.CloneArtifacts, error) {
+				sandboxRoot := filepath.Join(b.appRoot, "clones", req.ID)
+				return &cloning.CloneArtifacts{
+					SandboxWorkDir: sandboxRoot,
+					PathRegistry:   cloning.NewStandardPathRegistry(sandboxRoot),
+				}, nil
+			},
+		},
+		Configuration: &mockContainerConfiguration{},
+	})
+	events, cancel := b.Subscribe()
+	defer cancel()
+
+	sbox, err := b.NewSandbox(ctx, NewSandboxOpts{AgentType: "test-agent", ID: "evt-id", Name: "evt", HostWorkDir: t.TempDir(), ImageName: "test-image:latest"})
+	if err != nil {
+		t.Fatalf("NewSandbox() error = %v", err)
+	}
+	if err := b.UpdateContainerID(ctx, sbox, "ctr-1"); err != nil {
+		t.Fatalf("UpdateContainerID() error = %v", err)
+	}
+	// The lifecycle service records this once a start succeeds.
+	if err := b.UpdateStartHooksRan(ctx, sbox, true); err != nil {
+		t.Fatalf("UpdateStartHooksRan() error = %v", err)
+	}
+	if err := b.StopContainer(ctx, sbox); err != nil {
+		t.Fatalf("StopContainer() error = %v", err)
+	}
+	if err := b.SoftDelete(ctx, sbox); err != nil {
+		t.Fatalf("SoftDelete() error = %v", err)
+	}
+
+	var got []sandtypes.SandboxEventType
+	for range 4 {
+		select {
+		case event := <-events:
+			if event.SandboxID != "evt-id" || event.SandboxName != "evt" || event.Time.IsZero() {
+				t.Errorf("event = %+v, want sandbox evt-id/evt with a timestamp", event)
+			}
+			got = append(got, event.Type)
+		case <-time.After(time.Second):
+			t.Fatalf("got events %v, want 4", got)
+		}
+	}
+	want := []sandtypes.SandboxEventType{sandtypes.SandboxCreated, sandtypes.SandboxStarted, sandtypes.SandboxStopped, sandtypes.SandboxRemoved}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("events = %v, want %v", got, want)
+	}
+	select {
+	case event := <-events:
+		t.Fatalf("unexpected extra event %+v", event)
+	default:
+	}
+}
+
+func TestBoxerSubscribeCancelStopsDelivery(t *testing.T) {
+	b := newDBBoxer(t, t.TempDir())
+	events, cancel := b.Subscribe()
+	cancel()
+	cancel()
+
+	b.publish(context.Background(), sandtypes.SandboxStopped, &sandtypes.Box{ID: "x"}, "")
+	if _, ok := <-events; ok {
+		t.Fatal("received event after cancel, want closed channel")
+	}
+}
+
+func TestBoxerSyncPublishesSyncErrorForMissingClone(t *testing.T) {
+	ctx := context.Background()
+	b := newDBBoxer(t, t.TempDir())
+	if err := b.SaveSandbox(ctx, &sandtypes.Box{ID: "gone", Name: "gone", SandboxWorkDir: filepath.Join(t.TempDir(), "missing")}); err != nil {
+		t.Fatalf("SaveSandbox() error = %v", err)
+	}
+	events, cancel := b.Subscribe()
+	defer cancel()
+
+	if err := b.Sync(ctx); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	select {
+	case event := <-events:
+		if event.Type != sandtypes.SandboxSyncError || event.SandboxID != "gone" || event.Error == "" {
+			t.Fatalf("event = %+v, want sync-error for gone", event)
+		}
+	default:
+		t.Fatal("Sync() published no event for a sandbox with a missing clone")
+	}
+}
@@ -0,0 +1,177 @@
+package boxer
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/banksean/sand/internal/cloning"
+	"github.com/banksean/sand/internal/hostops"
+	"github.com/banksean/sand/internal/sandboxlog"
+	"github.com/banksean/sand/internal/sandtypes"
+)
+
+// ForkSandbox creates sandbox id, named name, from a copy-on-write clone of
+// the active sandbox sourceName's clone directory. The new sandbox keeps the
+// source's image, mounts and environment but gets its own ssh identity, host
+// git remote and container, which is left stopped. The source sandbox is not
+// modified.
+func (sb *Boxer) ForkSandbox(ctx context.Context, sourceName, id, name string, progress io.Writer) (*sandtypes.Box, error) {
+	ctx = sandboxlog.WithSandboxID(ctx, id)
+	slog.InfoContext(ctx, "Boxer.ForkSandbox", "source", sourceName, "id", id, "name", name)
+	if !sandtypes.IsValidSandboxID(id) {
+		return nil, fmt.Errorf("sandbox ID %q is invalid: must be 1-63 lowercase alphanumeric characters or hyphens", id)
+	}
+	if err := sandtypes.ValidateSandboxName(name); err != nil {
+		return nil, err
+	}
+	source, err := sb.Get(ctx, sourceName)
+	if err != nil {
+		return nil, err
+	}
+	if source == nil {
+		return nil, fmt.Errorf("sandbox not found: %s", sourceName)
+	}
+	if source.SandboxWorkDir == "" {
+		return nil, fmt.Errorf("sandbox %s has no clone directory to copy", sourceName)
+	}
+	defer sb.lockSandbox(id)()
+
+	if _, err := sb.queries.GetSandboxByID(ctx, id); err == nil {
+		return nil, fmt.Errorf("sandbox %s already exists", id)
+	} else if err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to check for existing sandbox %s: %w", id, err)
+	}
+	if _, err := sb.queries.GetActiveSandboxByName(ctx, name); err == nil {
+		return nil, fmt.Errorf("sandbox named %s already exists", name)
+	} else if err != sql.ErrNoRows {
+		return nil, fmt.Errorf("check sandbox name %s: %w", name, err)
+	}
+
+	// The fork lives next to the source so the clone stays on one volume.
+	workDir := sb.sandboxRoot(filepath.Dir(source.SandboxWorkDir), id)
+	if _, err := sb.FileOps.Stat(workDir); err == nil {
+		return nil, fmt.Errorf("sandbox workspace destination already exists: %s", workDir)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("stat sandbox workspace destination %s: %w", workDir, err)
+	}
+
+	fork := forkedBox(source, id, name, workDir)
+	if len(fork.Mounts) == 0 {
+		// Sandboxes saved before mounts were persisted fall back to the defaults.
+		sb.hydrateMounts(fork, "")
+	}
+	created := false
+	defer func() {
+		if created {
+			return
+		}
+		if fork.ContainerID != "" {
+			if out, err := sb.ContainerService.Delete(ctx, nil, fork.ContainerID); err != nil {
+				slog.WarnContext(ctx, "Boxer.ForkSandbox delete container", "containerID", fork.ContainerID, "error", err, "out", out)
+			}
+		}
+		sb.discardPartialClone(ctx, id, name, source.HostOriginDir, workDir)
+	}()
+
+	fmt.Fprintf(progress, "[sand] cloning %s to %s\n", source.SandboxWorkDir, workDir)
+	result, err := sb.FileOps.Copy(ctx, source.SandboxWorkDir, workDir, hostops.CopyOpts{})
+	if err != nil {
+		return nil, fmt.Errorf("clone sandbox workspace: %w", err)
+	}
+	sb.metrics.recordClone(result.CopyOnWrite)
+	if !result.CopyOnWrite {
+		fmt.Fprintf(progress, "[sand] warning: copy-on-write clone of %s is unavailable; sandbox %s uses a full copy and extra disk space\n", source.SandboxWorkDir, name)
+	}
+
+	if !fork.NoSSH {
+		keys, err := sb.SSHim.NewKeys(ctx, sandboxSSHHostname(name, fork.DNSDomain), fork.Username)
+		if err != nil {
+			return nil, fmt.Errorf("generate ssh keys for forked sandbox: %w", err)
+		}
+		if err := sb.saveSSHKeys(cloning.NewStandardPathRegistry(workDir).SSHKeysDir(), keys); err != nil {
+			return nil, fmt.Errorf("save ssh keys for forked sandbox: %w", err)
+		}
+	}
+	if fork.HostOriginDir != "" {
+		remote := cloning.ClonedWorkDirGitRemotePrefix + name
+		if err := sb.GitOps.AddRemote(ctx, fork.HostOriginDir, remote, filepath.Join(workDir, "app")); err != nil {
+			slog.WarnContext(ctx, "Boxer.ForkSandbox add git remote", "remote", remote, "error", err)
+		}
+	}
+
+	enableSSHAgent := source.Container != nil && source.Container.Configuration.SSH
+	fmt.Fprintf(progress, "[sand] creating container %s\n", name)
+	if err := sb.newLifecycleService().CreateContainer(ctx, fork, enableSSHAgent); err != nil {
+		return nil, fmt.Errorf("create container for forked sandbox: %w", err)
+	}
+	if err := sb.saveSandbox(ctx, fork); err != nil {
+		return nil, err
+	}
+	created = true
+	sb.publish(ctx, sandtypes.SandboxCreated, fork, "")
+	return fork, nil
+}
+
+// forkedBox returns a copy of source's metadata for sandbox id with the clone
+// directory workDir. Mount sources inside source's clone directory are moved
+// to the same place inside workDir.
+func forkedBox(source *sandtypes.Box, id, name, workDir string) *sandtypes.Box {
+	fork := &sandtypes.Box{
+		ID:                   id,
+		Name:                 name,
+		State:                "active",
+		AgentType:            source.AgentType,
+		ProfileName:          source.ProfileName,
+		HostOriginDir:        source.HostOriginDir,
+		SandboxWorkDir:       workDir,
+		ImageName:            source.ImageName,
+		ImageDigest:          source.ImageDigest,
+		DNSDomain:            source.DNSDomain,
+		EnvFile:              source.EnvFile,
+		AllowedDomains:       slices.Clone(source.AllowedDomains),
+		Labels:               maps.Clone(source.Labels),
+		Shell:                source.Shell,
+		Network:              source.Network,
+		NoDotfiles:           source.NoDotfiles,
+		NoSSH:                source.NoSSH,
+		AllowEmulation:       source.AllowEmulation,
+		IgnoreOnCreateErrors: source.IgnoreOnCreateErrors,
+		DNS:                  source.DNS.Clone(),
+		SharedCacheMounts:    source.SharedCacheMounts,
+		CPUs:                 source.CPUs,
+		MemoryMB:             source.MemoryMB,
+		Username:             source.Username,
+		Uid:                  source.Uid,
+	}
+	if source.OriginalGitDetails != nil {
+		details := *source.OriginalGitDetails
+		fork.OriginalGitDetails = &details
+	}
+	rebase := func(path string) string {
+		if path == "" || !pathWithin(path, source.SandboxWorkDir) {
+			return path
+		}
+		rel, _ := filepath.Rel(source.SandboxWorkDir, path)
+		return filepath.Join(workDir, rel)
+	}
+	for _, m := range source.Mounts {
+		m.Source = rebase(m.Source)
+		fork.Mounts = append(fork.Mounts, m)
+	}
+	for _, r := range source.MountRequests {
+		if r.Kind == sandtypes.MountKindClone {
+			r.Clone = rebase(r.Clone)
+			r.Runtime = renderBindMount(r.Clone, r.Target, r.ReadOnly)
+		}
+		fork.MountRequests = append(fork.MountRequests, r)
+	}
+	return fork
+}
@@ -0,0 +1,189 @@
+package boxer
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/banksean/sand/internal/cloning"
+	"github.com/banksean/sand/internal/hostops"
+	"github.com/banksean/sand/internal/sandtypes"
+)
+
+func forkFileOps() hostops.FileOps {
+	return &hostops.MockFileOps{
+		MkdirAllFunc:  os.MkdirAll,
+		StatFunc:      os.Stat,
+		CreateFunc:    os.Create,
+		RemoveAllFunc: os.RemoveAll,
+		CopyFunc: func(ctx context.Context, src, dst string, opts hostops.CopyOpts) (hostops.CopyResult, error) {
+			return hostops.CopyResult{CopyOnWrite: true}, os.CopyFS(dst, os.DirFS(src))
+		},
+	}
+}
+
+func TestBoxer_ForkSandboxCopiesWorkspaceIndependently(t *testing.T) {
+	ctx := context.Background()
+	var createdName string
+	mockContainer := &hostops.MockContainerOps{
+		CreateFunc: func(_ context.Context, opts *hostops.CreateContainer, _ string, _ []string) (string, error) {
+			createdName = opts.ManagementOptions.Name
+			return "fork-container", nil
+		},
+	}
+	b := newTestBoxer(t, mockContainer, &mockImageOps{})
+	b.FileOps = forkFileOps()
+	var remotes [][3]string
+	b.GitOps = &hostops.MockGitOps{
+		AddRemoteFunc: func(ctx context.Context, dir, name, url string) error {
+			remotes = append(remotes, [3]string{dir, name, url})
+			return nil
+		},
+	}
+
+	originDir := t.TempDir()
+	sourceDir := filepath.Join(b.appRoot, "clones", "source-id")
+	if err := os.MkdirAll(filepath.Join(sourceDir, "app"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(sourceDir, "app", "notes.txt"), []byte("experiment"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	source := &sandtypes.Box{
+		ID:             "source-id",
+		Name:           "experiment",
+		ContainerID:    "experiment",
+		HostOriginDir:  originDir,
+		SandboxWorkDir: sourceDir,
+		ImageName:      "test-image:latest",
+		DNSDomain:      "dev.local",
+		EnvFile:        filepath.Join(originDir, ".env"),
+		Username:       "sean",
+		Labels:         map[string]string{"team": "infra"},
+		Mounts: []sandtypes.MountSpec{
+			{Source: filepath.Join(sourceDir, "app"), Target: "/app"},
+			{Source: filepath.Join(sourceDir, "sshkeys"), Target: "/sshkeys", ReadOnly: true},
+		},
+	}
+	if err := b.SaveSandbox(ctx, source); err != nil {
+		t.Fatalf("SaveSandbox() error = %v", err)
+	}
+
+	got, err := b.ForkSandbox(ctx, "experiment", "fork-id", "branch", io.Discard)
+	if err != nil {
+		t.Fatalf("ForkSandbox() error = %v", err)
+	}
+	forkDir := filepath.Join(b.appRoot, "clones", "fork-id")
+	if got.ID != "fork-id" || got.Name != "branch" || got.SandboxWorkDir != forkDir || got.ContainerID != "fork-container" {
+		t.Fatalf("forked sandbox = %+v", got)
+	}
+	if createdName != "branch" {
+		t.Fatalf("created container name = %q, want branch", createdName)
+	}
+	if got.ImageName != source.ImageName || got.EnvFile != source.EnvFile || got.Labels["team"] != "infra" {
+		t.Fatalf("forked sandbox metadata = %+v, want source's image, env file and labels", got)
+	}
+	if got.Mounts[0].Source != filepath.Join(forkDir, "app") || got.Mounts[1].Source != filepath.Join(forkDir, "sshkeys") {
+		t.Fatalf("forked mounts = %+v, want sources inside %s", got.Mounts, forkDir)
+	}
+	wantRemote := [3]string{originDir, cloning.ClonedWorkDirGitRemotePrefix + "branch", filepath.Join(forkDir, "app")}
+	if len(remotes) != 1 || remotes[0] != wantRemote {
+		t.Fatalf("AddRemote calls = %v, want [%v]", remotes, wantRemote)
+	}
+	if _, err := os.Stat(filepath.Join(forkDir, "sshkeys", "ssh_host_key")); err != nil {
+		t.Fatalf("forked sandbox has no ssh host key: %v", err)
+	}
+
+	// Changes on either side stay on that side.
+	if err := os.WriteFile(filepath.Join(forkDir, "app", "notes.txt"), []byte("forked"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(sourceDir, "app", "source-only.txt"), []byte("source"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(filepath.Join(sourceDir, "app", "notes.txt")); err != nil || string(data) != "experiment" {
+		t.Fatalf("source notes.txt = %q, %v; want unchanged", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(forkDir, "app", "source-only.txt")); !os.IsNotExist(err) {
+		t.Fatalf("source change leaked into fork: %v", err)
+	}
+
+	loadedSource, err := b.Get(ctx, "experiment")
+	if err != nil || loadedSource == nil {
+		t.Fatalf("Get(experiment) = %v, %v", loadedSource, err)
+	}
+	if loadedSource.ID != "source-id" || loadedSource.SandboxWorkDir != sourceDir || loadedSource.ContainerID != "experiment" {
+		t.Fatalf("source sandbox changed: %+v", loadedSource)
+	}
+	loadedFork, err := b.Get(ctx, "branch")
+	if err != nil || loadedFork == nil {
+		t.Fatalf("Get(branch) = %v, %v", loadedFork, err)
+	}
+	if loadedFork.ID != "fork-id" || loadedFork.SandboxWorkDir != forkDir || loadedFork.ContainerID != "fork-container" {
+		t.Fatalf("loaded fork = %+v", loadedFork)
+	}
+	if err := b.UpdateContainerBootstrapped(ctx, loadedFork, true); err != nil {
+		t.Fatal(err)
+	}
+	if loadedSource, _ = b.Get(ctx, "experiment"); loadedSource.ContainerBootstrapped {
+		t.Fatal("updating the fork's row changed the source's")
+	}
+}
+
+func TestBoxer_ForkSandboxRejectsNameCollision(t *testing.T) {
+	ctx := context.Background()
+	var copies int
+	b := newTestBoxer(t, &hostops.MockContainerOps{}, &mockImageOps{})
+	b.FileOps = &hostops.MockFileOps{
+		CopyFunc: func(ctx context.Context, src, dst string, opts hostops.CopyOpts) (hostops.CopyResult, error) {
+			copies++
+			return hostops.CopyResult{}, nil
+		},
+	}
+	for _, name := range []string{"first", "second"} {
+		if err := b.SaveSandbox(ctx, &sandtypes.Box{ID: name + "-id", Name: name, SandboxWorkDir: t.TempDir()}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, err := b.ForkSandbox(ctx, "first", "fork-id", "second", io.Discard)
+	if err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("ForkSandbox() error = %v, want collision error", err)
+	}
+	if copies != 0 {
+		t.Fatalf("Copy calls = %d, want none after collision", copies)
+	}
+}
+
+func TestBoxer_ForkSandboxDiscardsCloneOnContainerFailure(t *testing.T) {
+	ctx := context.Background()
+	b := newTestBoxer(t, &hostops.MockContainerOps{
+		CreateFunc: func(context.Context, *hostops.CreateContainer, string, []string) (string, error) {
+			return "", os.ErrPermission
+		},
+	}, &mockImageOps{})
+	b.FileOps = forkFileOps()
+	sourceDir := filepath.Join(b.appRoot, "clones", "source-id")
+	if err := os.MkdirAll(filepath.Join(sourceDir, "app"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.SaveSandbox(ctx, &sandtypes.Box{ID: "source-id", Name: "source", SandboxWorkDir: sourceDir}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := b.ForkSandbox(ctx, "source", "fork-id", "fork", io.Discard); err == nil {
+		t.Fatal("ForkSandbox() error = nil, want container create failure")
+	}
+	if _, err := os.Stat(filepath.Join(b.appRoot, "clones", "fork-id")); !os.IsNotExist(err) {
+		t.Fatalf("fork clone directory left behind: %v", err)
+	}
+	if fork, err := b.GetByID(ctx, "fork-id"); err != nil || fork != nil {
+		t.Fatalf("GetByID(fork-id) = %v, %v; want no row", fork, err)
+	}
+	if _, err := os.Stat(filepath.Join(sourceDir, "app")); err != nil {
+		t.Fatalf("source workspace touched: %v", err)
+	}
+}
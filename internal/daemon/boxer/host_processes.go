@@ -0,0 +1,130 @@
+package boxer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"syscall"
+
+	"github.com/banksean/sand/internal/db"
+	"github.com/banksean/sand/internal/sandtypes"
+)
+
+// terminateProcessGroup sends SIGTERM to every process in the group led by pgid.
+func terminateProcessGroup(pgid int) error {
+	return syscall.Kill(-pgid, syscall.SIGTERM)
+}
+
+// RecordHostProcess remembers a host process started on behalf of sbox, such
+// as an ssh tunnel opened by a start hook. The record lives in the DB so the
+// process can still be killed after sandd restarts. It keeps the process's
+// start time along with its pid, so a pid reused by another process after
+// this one exits is never signalled.
+func (sb *Boxer) RecordHostProcess(ctx context.Context, sbox *sandtypes.Box, name string, pid int) error {
+	// A process that can't be identified is recorded anyway, but sand will
+	// never signal it.
+	startTime, err := sb.processStartTime(pid)
+	if err != nil {
+		slog.WarnContext(ctx, "Boxer.RecordHostProcess", "name", name, "pid", pid, "error", err)
+	}
+	bootTime, err := hostBootTime()
+	if err != nil {
+		slog.WarnContext(ctx, "Boxer.RecordHostProcess", "name", name, "pid", pid, "error", err)
+	}
+	if err := sb.queries.UpsertHostProcess(ctx, db.UpsertHostProcessParams{
+		SandboxID: sbox.ID,
+		Name:      name,
+		Pid:       int64(pid),
+		StartTime: startTime,
+		BootTime:  bootTime,
+	}); err != nil {
+		return fmt.Errorf("failed to record host process %s for sandbox %s: %w", name, sbox.ID, err)
+	}
+	return nil
+}
+
+// isRecordedProcess reports whether proc's pid still belongs to the process
+// that was recorded, rather than having exited or been handed to another.
+func (sb *Boxer) isRecordedProcess(proc db.HostProcess) bool {
+	if proc.StartTime == "" {
+		return false
+	}
+	startTime, err := sb.processStartTime(int(proc.Pid))
+	return err == nil && startTime == proc.StartTime
+}
+
+// killRecordedProcess terminates proc's process group. It returns
+// syscall.ESRCH without signalling anything if proc has exited, even when
+// its pid now belongs to another process.
+func (sb *Boxer) killRecordedProcess(proc db.HostProcess) error {
+	if !sb.isRecordedProcess(proc) {
+		return syscall.ESRCH
+	}
+	return sb.killProcessGroup(int(proc.Pid))
+}
+
+// ForgetHostProcessesFromEarlierBoots drops the records of host processes
+// started before the host last booted. None of them can still be running,
+// and their pids may belong to anything now.
+func (sb *Boxer) ForgetHostProcessesFromEarlierBoots(ctx context.Context) error {
+	bootTime, err := hostBootTime()
+	if err != nil {
+		return err
+	}
+	if err := sb.queries.DeleteHostProcessesFromOtherBoots(ctx, bootTime); err != nil {
+		return fmt.Errorf("failed to forget host processes from earlier boots: %w", err)
+	}
+	return nil
+}
+
+// KillHostProcess terminates the host process recorded for sbox under name
+// and forgets it. It is not an error if there is no such record or the
+// process has already exited.
+func (sb *Boxer) KillHostProcess(ctx context.Context, sbox *sandtypes.Box, name string) error {
+	procs, err := sb.queries.ListHostProcesses(ctx, sbox.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list host processes for sandbox %s: %w", sbox.ID, err)
+	}
+	for _, proc := range procs {
+		if proc.Name != name {
+			continue
+		}
+		if err := sb.killRecordedProcess(proc); err != nil && !errors.Is(err, syscall.ESRCH) {
+			return fmt.Errorf("failed to kill host process %s (pid %d) for sandbox %s: %w", name, proc.Pid, sbox.ID, err)
+		}
+		slog.InfoContext(ctx, "Boxer.KillHostProcess", "name", name, "pid", proc.Pid)
+		if err := sb.queries.DeleteHostProcess(ctx, db.DeleteHostProcessParams{SandboxID: sbox.ID, Name: name}); err != nil {
+			return fmt.Errorf("failed to forget host process %s for sandbox %s: %w", name, sbox.ID, err)
+		}
+	}
+	return nil
+}
+
+// killHostProcesses terminates the host processes recorded for sbox and
+// forgets them. Processes that have already exited are skipped; failures are
+// logged rather than returned so they never block stopping or removing a sandbox.
+func (sb *Boxer) killHostProcesses(ctx context.Context, sbox *sandtypes.Box) {
+	procs, err := sb.queries.ListHostProcesses(ctx, sbox.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Boxer.killHostProcesses ListHostProcesses", "error", err)
+		return
+	}
+	for _, proc := range procs {
+		err := sb.killRecordedProcess(proc)
+		switch {
+		case err == nil:
+			slog.InfoContext(ctx, "Boxer.killHostProcesses", "name", proc.Name, "pid", proc.Pid)
+		case errors.Is(err, syscall.ESRCH):
+			slog.InfoContext(ctx, "Boxer.killHostProcesses already exited", "name", proc.Name, "pid", proc.Pid)
+		default:
+			slog.WarnContext(ctx, "Boxer.killHostProcesses", "name", proc.Name, "pid", proc.Pid, "error", err)
+		}
+	}
+	if len(procs) == 0 {
+		return
+	}
+	if err := sb.queries.DeleteHostProcesses(ctx, sbox.ID); err != nil {
+		slog.ErrorContext(ctx, "Boxer.killHostProcesses DeleteHostProcesses", "error", err)
+	}
+}
@@ -0,0 +1,311 @@
+package boxer
+
+import (
+	"context"
+	"os/exec"
+	"slices"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/banksean/sand/internal/db"
+	"github.com/banksean/sand/internal/hostops"
+	"github.com/banksean/sand/internal/sandtypes"
+)
+
+// fakeProcessStartTimes makes b see every pid as a process that started at
+// "start", or at started[pid] when given, so fake pids can be recorded and
+// killed.
+func fakeProcessStartTimes(b *Boxer, started map[int]string) {
+	b.processStartTime = func(pid int) (string, error) {
+		if start, ok := started[pid]; ok {
+			return start, nil
+		}
+		return "start", nil
+	}
+}
+
+func TestStopContainerKillsRecordedHostProcesses(t *testing.T) {
+	ctx := context.Background()
+	b := newDBBoxer(t, t.TempDir())
+	var killed []int
+	fakeProcessStartTimes(b, nil)
+	b.killProcessGroup = func(pgid int) error {
+		killed = append(killed, pgid)
+		return nil
+	}
+	sbox := &sandtypes.Box{ID: "tunnel-box", Name: "tunnel-box", ContainerID: "ctr-1"}
+	other := &sandtypes.Box{ID: "other-box", Name: "other-box", ContainerID: "ctr-2"}
+	for _, box := range []*sandtypes.Box{sbox, other} {
+		if err := b.SaveSandbox(ctx, box); err != nil {
+			t.Fatalf("SaveSandbox(%s) error = %v", box.ID, err)
+		}
+	}
+	if err := b.RecordHostProcess(ctx, sbox, "tunnel", 111); err != nil {
+		t.Fatalf("RecordHostProcess() error = %v", err)
+	}
+	// A restarted hook replaces the old record rather than adding another.
+	if err := b.RecordHostProcess(ctx, sbox, "tunnel", 4242); err != nil {
+		t.Fatalf("RecordHostProcess() error = %v", err)
+	}
+	if err := b.RecordHostProcess(ctx, other, "tunnel", 5353); err != nil {
+		t.Fatalf("RecordHostProcess() error = %v", err)
+	}
+
+	if err := b.StopContainer(ctx, sbox); err != nil {
+		t.Fatalf("StopContainer() error = %v", err)
+	}
+	if !slices.Equal(killed, []int{4242}) {
+		t.Fatalf("killed process groups = %v, want [4242]", killed)
+	}
+
+	killed = nil
+	if err := b.StopContainer(ctx, sbox); err != nil {
+		t.Fatalf("second StopContainer() error = %v", err)
+	}
+	if len(killed) != 0 {
+		t.Fatalf("second stop killed %v, want the record to be cleared", killed)
+	}
+	procs, err := b.queries.ListHostProcesses(ctx, other.ID)
+	if err != nil || len(procs) != 1 || procs[0].Pid != 5353 {
+		t.Fatalf("other sandbox host processes = %+v, %v; want its record untouched", procs, err)
+	}
+}
+
+func TestSoftDeleteTerminatesTunnelProcessGroup(t *testing.T) {
+	ctx := context.Background()
+	b := newDBBoxer(t, t.TempDir())
+	sbox := &sandtypes.Box{ID: "rm-box", Name: "rm-box", ContainerID: "ctr-rm"}
+	if err := b.SaveSandbox(ctx, sbox); err != nil {
+		t.Fatalf("SaveSandbox() error = %v", err)
+	}
+
+	tunnel := exec.Command("sleep", "60")
+	tunnel.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if err := tunnel.Start(); err != nil {
+		t.Fatalf("start stand-in tunnel: %v", err)
+	}
+	exited := make(chan error, 1)
+	go func() { exited <- tunnel.Wait() }()
+	t.Cleanup(func() { tunnel.Process.Kill() })
+
+	if err := b.RecordHostProcess(ctx, sbox, "tunnel", tunnel.Process.Pid); err != nil {
+		t.Fatalf("RecordHostProcess() error = %v", err)
+	}
+	if err := b.SoftDelete(ctx, sbox); err != nil {
+		t.Fatalf("SoftDelete() error = %v", err)
+	}
+	select {
+	case <-exited:
+	case <-time.After(5 * time.Second):
+		t.Fatal("tunnel process still running after SoftDelete")
+	}
+}
+
+func TestKillHostProcessesSkipsExitedProcess(t *testing.T) {
+	ctx := context.Background()
+	b := newDBBoxer(t, t.TempDir())
+	sbox := &sandtypes.Box{ID: "gone-box", Name: "gone-box", ContainerID: "ctr-gone"}
+	if err := b.SaveSandbox(ctx, sbox); err != nil {
+		t.Fatalf("SaveSandbox() error = %v", err)
+	}
+	done := exec.Command("true")
+	done.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if err := done.Run(); err != nil {
+		t.Fatalf("run exited stand-in: %v", err)
+	}
+	if err := b.RecordHostProcess(ctx, sbox, "tunnel", done.Process.Pid); err != nil {
+		t.Fatalf("RecordHostProcess() error = %v", err)
+	}
+
+	if err := b.StopContainer(ctx, sbox); err != nil {
+		t.Fatalf("StopContainer() error = %v, want an exited tunnel to be ignored", err)
+	}
+	procs, err := b.queries.ListHostProcesses(ctx, sbox.ID)
+	if err != nil || len(procs) != 0 {
+		t.Fatalf("host processes after stop = %+v, %v; want none", procs, err)
+	}
+}
+
+func TestExecuteHooksRecordsHostProcessForSandbox(t *testing.T) {
+	ctx := context.Background()
+	mockContainer := &hostops.MockContainerOps{
+		InspectFunc: func(ctx context.Context, containerID string) ([]sandtypes.Container, error) {
+			return []sandtypes.Container{{Status: sandtypes.ContainerStatus{State: "running"}}}, nil
+		},
+	}
+	b := newTestBoxer(t, mockContainer, &mockImageOps{})
+	sbox := &sandtypes.Box{ID: "hook-box", Name: "hook-box", ContainerID: "hook-container"}
+	if err := b.SaveSandbox(ctx, sbox); err != nil {
+		t.Fatalf("SaveSandbox() error = %v", err)
+	}
+
+	hooks := []sandtypes.ContainerHook{
+		sandtypes.NewContainerHook("tunnel hook", func(ctx context.Context, ctr *sandtypes.Container, exec sandtypes.HookStreamer) error {
+			recorder, ok := exec.(sandtypes.HostProcessRecorder)
+			if !ok {
+				t.Fatalf("hook streamer %T does not implement HostProcessRecorder", exec)
+			}
+			return recorder.RecordHostProcess(ctx, "tunnel", 7777)
+		}),
+	}
+	if err := b.newLifecycleService().ExecuteHooks(ctx, sbox, hooks, nil); err != nil {
+		t.Fatalf("ExecuteHooks() error = %v", err)
+	}
+
+	procs, err := b.queries.ListHostProcesses(ctx, sbox.ID)
+	if err != nil {
+		t.Fatalf("ListHostProcesses() error = %v", err)
+	}
+	if len(procs) != 1 || procs[0].Name != "tunnel" || procs[0].Pid != 7777 {
+		t.Fatalf("host processes = %+v, want tunnel/7777", procs)
+	}
+}
+
+func TestSoftDeleteForgetsSSHHost(t *testing.T) {
+	ctx := context.Background()
+	b := newDBBoxer(t, t.TempDir())
+	sshim := &mockSSHimmer{}
+	b.SSHim = sshim
+	sbox := &sandtypes.Box{ID: "ssh-box", Name: "ssh-box", ContainerID: "ctr-ssh", DNSDomain: "test"}
+	if err := b.SaveSandbox(ctx, sbox); err != nil {
+		t.Fatalf("SaveSandbox() error = %v", err)
+	}
+	if err := b.SoftDelete(ctx, sbox); err != nil {
+		t.Fatalf("SoftDelete() error = %v", err)
+	}
+	if want := []string{sandboxSSHHostname("ssh-box", "test")}; !slices.Equal(sshim.removedHosts, want) {
+		t.Fatalf("RemoveHost() calls = %v, want %v", sshim.removedHosts, want)
+	}
+}
+
+func TestKillHostProcessKillsOnlyTheNamedProcess(t *testing.T) {
+	ctx := context.Background()
+	b := newDBBoxer(t, t.TempDir())
+	var killed []int
+	fakeProcessStartTimes(b, nil)
+	b.killProcessGroup = func(pgid int) error {
+		killed = append(killed, pgid)
+		if pgid == 333 {
+			return syscall.ESRCH
+		}
+		return nil
+	}
+	sbox := &sandtypes.Box{ID: "named-box", Name: "named-box", ContainerID: "ctr-1"}
+	if err := b.SaveSandbox(ctx, sbox); err != nil {
+		t.Fatalf("SaveSandbox() error = %v", err)
+	}
+	for name, pid := range map[string]int{"tunnel": 111, "other": 222, "gone": 333} {
+		if err := b.RecordHostProcess(ctx, sbox, name, pid); err != nil {
+			t.Fatalf("RecordHostProcess(%s) error = %v", name, err)
+		}
+	}
+
+	if err := b.KillHostProcess(ctx, sbox, "tunnel"); err != nil {
+		t.Fatalf("KillHostProcess() error = %v", err)
+	}
+	if err := b.KillHostProcess(ctx, sbox, "gone"); err != nil {
+		t.Fatalf("KillHostProcess() of an exited process error = %v", err)
+	}
+	if err := b.KillHostProcess(ctx, sbox, "never-recorded"); err != nil {
+		t.Fatalf("KillHostProcess() of an unknown name error = %v", err)
+	}
+	if !slices.Equal(killed, []int{111, 333}) {
+		t.Fatalf("killed process groups = %v, want [111 333]", killed)
+	}
+	procs, err := b.queries.ListHostProcesses(ctx, sbox.ID)
+	if err != nil || len(procs) != 1 || procs[0].Name != "other" {
+		t.Fatalf("remaining host processes = %+v, %v; want only other", procs, err)
+	}
+}
+
+func TestKillHostProcessesLeavesAReusedPidAlone(t *testing.T) {
+	ctx := context.Background()
+	b := newDBBoxer(t, t.TempDir())
+	started := map[int]string{4242: "first"}
+	fakeProcessStartTimes(b, started)
+	var killed []int
+	b.killProcessGroup = func(pgid int) error {
+		killed = append(killed, pgid)
+		return nil
+	}
+	sbox := &sandtypes.Box{ID: "reused-box", Name: "reused-box", ContainerID: "ctr-1"}
+	if err := b.SaveSandbox(ctx, sbox); err != nil {
+		t.Fatalf("SaveSandbox() error = %v", err)
+	}
+	if err := b.RecordHostProcess(ctx, sbox, "tunnel", 4242); err != nil {
+		t.Fatalf("RecordHostProcess() error = %v", err)
+	}
+	// The tunnel exits and its pid goes to some other process.
+	started[4242] = "later"
+
+	if err := b.KillHostProcess(ctx, sbox, "tunnel"); err != nil {
+		t.Fatalf("KillHostProcess() error = %v", err)
+	}
+	if err := b.StopContainer(ctx, sbox); err != nil {
+		t.Fatalf("StopContainer() error = %v", err)
+	}
+	if len(killed) != 0 {
+		t.Fatalf("killed process groups = %v, want the reused pid left alone", killed)
+	}
+	procs, err := b.queries.ListHostProcesses(ctx, sbox.ID)
+	if err != nil || len(procs) != 0 {
+		t.Fatalf("host processes = %+v, %v; want the exited tunnel forgotten", procs, err)
+	}
+}
+
+func TestRecordHostProcessKeepsStartTime(t *testing.T) {
+	ctx := context.Background()
+	b := newDBBoxer(t, t.TempDir())
+	sbox := &sandtypes.Box{ID: "start-box", Name: "start-box", ContainerID: "ctr-1"}
+	if err := b.SaveSandbox(ctx, sbox); err != nil {
+		t.Fatalf("SaveSandbox() error = %v", err)
+	}
+	tunnel := exec.Command("sleep", "60")
+	if err := tunnel.Start(); err != nil {
+		t.Fatalf("start stand-in tunnel: %v", err)
+	}
+	t.Cleanup(func() { tunnel.Process.Kill(); tunnel.Wait() })
+
+	if err := b.RecordHostProcess(ctx, sbox, "tunnel", tunnel.Process.Pid); err != nil {
+		t.Fatalf("RecordHostProcess() error = %v", err)
+	}
+	procs, err := b.queries.ListHostProcesses(ctx, sbox.ID)
+	if err != nil || len(procs) != 1 {
+		t.Fatalf("host processes = %+v, %v; want the tunnel", procs, err)
+	}
+	want, err := processStartTime(tunnel.Process.Pid)
+	if err != nil {
+		t.Fatalf("processStartTime() error = %v", err)
+	}
+	if procs[0].StartTime != want || procs[0].BootTime == "" {
+		t.Fatalf("recorded start %q, boot %q; want start %q and the boot", procs[0].StartTime, procs[0].BootTime, want)
+	}
+	if !b.isRecordedProcess(procs[0]) {
+		t.Fatal("isRecordedProcess() = false for the running tunnel")
+	}
+}
+
+func TestForgetHostProcessesFromEarlierBoots(t *testing.T) {
+	ctx := context.Background()
+	b := newDBBoxer(t, t.TempDir())
+	fakeProcessStartTimes(b, nil)
+	sbox := &sandtypes.Box{ID: "boot-box", Name: "boot-box", ContainerID: "ctr-1"}
+	if err := b.SaveSandbox(ctx, sbox); err != nil {
+		t.Fatalf("SaveSandbox() error = %v", err)
+	}
+	if err := b.RecordHostProcess(ctx, sbox, "current", 111); err != nil {
+		t.Fatalf("RecordHostProcess() error = %v", err)
+	}
+	if err := b.queries.UpsertHostProcess(ctx, db.UpsertHostProcessParams{SandboxID: sbox.ID, Name: "before-reboot", Pid: 222, StartTime: "start", BootTime: "an-earlier-boot"}); err != nil {
+		t.Fatalf("UpsertHostProcess() error = %v", err)
+	}
+
+	if err := b.ForgetHostProcessesFromEarlierBoots(ctx); err != nil {
+		t.Fatalf("ForgetHostProcessesFromEarlierBoots() error = %v", err)
+	}
+	procs, err := b.queries.ListHostProcesses(ctx, sbox.ID)
+	if err != nil || len(procs) != 1 || procs[0].Name != "current" {
+		t.Fatalf("host processes = %+v, %v; want only the one from this boot", procs, err)
+	}
+}
@@ -129,7 +129,7 @@ func (s *HTTPProxyCacheService) Ensure(ctx context.Context, localDomain string,
 		progress = io.Discard
 	}
 	fmt.Fprintf(progress, "[sand] ensuring HTTP proxy cache service\n")
-	if err := s.boxer.EnsureImage(ctx, HTTPProxyCacheImage, progress); err != nil {
+	if err := s.boxer.EnsureImage(ctx, HTTPProxyCacheImage, EnsureImageOpts{}, progress); err != nil {
 		return fmt.Errorf("ensure HTTP proxy cache image: %w", err)
 	}
 	if err := s.ensureSquidFiles(); err != nil {
@@ -264,7 +264,7 @@ func (s *HTTPProxyCacheService) create(ctx context.Context, localDomain string)
 		ManagementOptions: hostops.ManagementOptions{
 			Name:      HTTPProxyCacheContainerName,
 			DNSDomain: strings.Trim(localDomain, "."),
-			Publish:   fmt.Sprintf("127.0.0.1:%d:%d/tcp", HTTPProxyCachePort, HTTPProxyCachePort),
+			Publish:   []string{fmt.Sprintf("127.0.0.1:%d:%d/tcp", HTTPProxyCachePort, HTTPProxyCachePort)},
 			Label: map[string]string{
 				httpProxyCacheServiceLabel: httpProxyCacheServiceValue,
 				httpProxyCacheVersionLabel: httpProxyCacheVersion,
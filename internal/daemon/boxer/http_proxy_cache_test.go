@@ -44,7 +44,7 @@ func TestHTTPProxyCacheEnsureCreatesAndStartsMissingContainer(t *testing.T) {
 				if opts.Name != HTTPProxyCacheContainerName {
 					t.Fatalf("create name = %q", opts.Name)
 				}
-				if opts.Publish != "127.0.0.1:3128:3128/tcp" {
+				if len(opts.Publish) != 1 || opts.Publish[0] != "127.0.0.1:3128:3128/tcp" {
 					t.Fatalf("publish = %q", opts.Publish)
 				}
 				if opts.Label[httpProxyCacheServiceLabel] != httpProxyCacheServiceValue {
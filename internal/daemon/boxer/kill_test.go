@@ -0,0 +1,134 @@
+package boxer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/banksean/sand/internal/hostops"
+	"github.com/banksean/sand/internal/sandtypes"
+)
+
+func newKillTestBoxer(t *testing.T, ops *hostops.MockContainerOps) (*Boxer, *sandtypes.Box) {
+	t.Helper()
+	ctx := context.Background()
+	b := newDBBoxer(t, t.TempDir())
+	b.ContainerService = ops
+	sbox := &sandtypes.Box{ID: "kill-box", Name: "kill-box", ContainerID: "ctr-kill"}
+	if err := b.SaveSandbox(ctx, sbox); err != nil {
+		t.Fatalf("SaveSandbox() error = %v", err)
+	}
+	if err := b.UpdateStartHooksRan(ctx, sbox, true); err != nil {
+		t.Fatalf("UpdateStartHooksRan() error = %v", err)
+	}
+	return b, sbox
+}
+
+func TestStopContainerKillsAfterStopTimesOut(t *testing.T) {
+	old := stopContainerTimeout
+	stopContainerTimeout = 10 * time.Millisecond
+	t.Cleanup(func() { stopContainerTimeout = old })
+
+	var killed []string
+	b, sbox := newKillTestBoxer(t, &hostops.MockContainerOps{
+		StopFunc: func(ctx context.Context, opts *hostops.StopContainer, containerID string) (string, error) {
+			<-ctx.Done()
+			return "", ctx.Err()
+		},
+		KillFunc: func(ctx context.Context, opts *hostops.KillContainer, containerID string) (string, error) {
+			if ctx.Err() != nil {
+				t.Errorf("Kill() called with a done context: %v", ctx.Err())
+			}
+			killed = append(killed, containerID)
+			return containerID, nil
+		},
+	})
+	events, cancel := b.Subscribe()
+	defer cancel()
+
+	if err := b.StopContainer(context.Background(), sbox); err != nil {
+		t.Fatalf("StopContainer() error = %v", err)
+	}
+	if len(killed) != 1 || killed[0] != "ctr-kill" {
+		t.Fatalf("killed = %v, want [ctr-kill]", killed)
+	}
+	if sbox.StartHooksRan {
+		t.Fatal("StartHooksRan = true after kill, want false")
+	}
+	select {
+	case event := <-events:
+		if event.Type != sandtypes.SandboxStopped {
+			t.Fatalf("event = %+v, want stopped", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("StopContainer() published no stopped event")
+	}
+}
+
+func TestStopContainerDoesNotKillOnOtherErrors(t *testing.T) {
+	killed := false
+	b, sbox := newKillTestBoxer(t, &hostops.MockContainerOps{
+		StopFunc: func(ctx context.Context, opts *hostops.StopContainer, containerID string) (string, error) {
+			return "", context.Canceled
+		},
+		KillFunc: func(ctx context.Context, opts *hostops.KillContainer, containerID string) (string, error) {
+			killed = true
+			return containerID, nil
+		},
+	})
+	if err := b.StopContainer(context.Background(), sbox); err == nil {
+		t.Fatal("StopContainer() error = nil, want the stop error")
+	}
+	if killed {
+		t.Fatal("StopContainer() killed the container after a non-timeout error")
+	}
+}
+
+func TestKillContainer(t *testing.T) {
+	var signals []string
+	ops := &hostops.MockContainerOps{
+		KillFunc: func(ctx context.Context, opts *hostops.KillContainer, containerID string) (string, error) {
+			signals = append(signals, opts.Signal)
+			return containerID, nil
+		},
+	}
+
+	t.Run("non-KILL signal leaves the sandbox running", func(t *testing.T) {
+		signals = nil
+		b, sbox := newKillTestBoxer(t, ops)
+		if err := b.KillContainer(context.Background(), sbox, "hup"); err != nil {
+			t.Fatalf("KillContainer() error = %v", err)
+		}
+		if len(signals) != 1 || signals[0] != "hup" {
+			t.Fatalf("signals = %v, want [hup]", signals)
+		}
+		if !sbox.StartHooksRan {
+			t.Fatal("StartHooksRan = false after SIGHUP, want it left alone")
+		}
+	})
+
+	t.Run("default signal stops the sandbox", func(t *testing.T) {
+		signals = nil
+		b, sbox := newKillTestBoxer(t, ops)
+		if err := b.KillContainer(context.Background(), sbox, ""); err != nil {
+			t.Fatalf("KillContainer() error = %v", err)
+		}
+		if len(signals) != 1 {
+			t.Fatalf("signals = %v, want one kill", signals)
+		}
+		if sbox.StartHooksRan {
+			t.Fatal("StartHooksRan = true after SIGKILL, want false")
+		}
+	})
+
+	t.Run("unknown signal is rejected", func(t *testing.T) {
+		signals = nil
+		b, sbox := newKillTestBoxer(t, ops)
+		if err := b.KillContainer(context.Background(), sbox, "NOPE"); err == nil {
+			t.Fatal("KillContainer() error = nil, want unknown signal error")
+		}
+		if len(signals) != 0 {
+			t.Fatalf("signals = %v, want no kill for a bad signal", signals)
+		}
+	})
+}
@@ -0,0 +1,154 @@
+package boxer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/banksean/sand/internal/sandtypes"
+)
+
+// imagePullBuckets are the upper bounds, in seconds, of the image pull
+// duration histogram buckets.
+var imagePullBuckets = []float64{1, 5, 15, 30, 60, 120, 300, 600}
+
+// metrics counts what the Boxer has done since sandd started, for
+// WriteMetrics. The zero value is ready to use.
+type metrics struct {
+	mu     sync.Mutex
+	events map[sandtypes.SandboxEventType]uint64
+	// clones counts workspace clones by whether they were copy-on-write.
+	clones map[bool]uint64
+	// pullBuckets[i] counts successful pulls that took at most imagePullBuckets[i].
+	pullBuckets  []uint64
+	pullCount    uint64
+	pullSeconds  float64
+	pullFailures uint64
+}
+
+func (m *metrics) recordEvent(typ sandtypes.SandboxEventType) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.events == nil {
+		m.events = map[sandtypes.SandboxEventType]uint64{}
+	}
+	m.events[typ]++
+}
+
+func (m *metrics) recordClone(copyOnWrite bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.clones == nil {
+		m.clones = map[bool]uint64{}
+	}
+	m.clones[copyOnWrite]++
+}
+
+func (m *metrics) recordPull(d time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err != nil {
+		m.pullFailures++
+		return
+	}
+	if m.pullBuckets == nil {
+		m.pullBuckets = make([]uint64, len(imagePullBuckets))
+	}
+	seconds := d.Seconds()
+	for i, le := range imagePullBuckets {
+		if seconds <= le {
+			m.pullBuckets[i]++
+		}
+	}
+	m.pullCount++
+	m.pullSeconds += seconds
+}
+
+// WriteMetrics writes the Boxer's metrics to w in the Prometheus text
+// exposition format. Sandbox counts are read from the database and container
+// service at call time; everything else counts from when sandd started.
+func (sb *Boxer) WriteMetrics(ctx context.Context, w io.Writer) error {
+	sboxes, err := sb.queries.ListSandboxes(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list sandboxes: %w", err)
+	}
+	boxes := make([]*sandtypes.Box, len(sboxes))
+	for i := range sboxes {
+		boxes[i] = sb.sandboxFromDB(&sboxes[i])
+	}
+	sb.inspectContainers(ctx, boxes)
+	var running, stopped int
+	for _, box := range boxes {
+		if box.ContainerID != "" && box.Container != nil && box.Container.Status.State == "running" {
+			running++
+		} else {
+			stopped++
+		}
+	}
+
+	m := &sb.metrics
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p := &promWriter{w: w}
+	p.header("sand_sandboxes", "gauge", "Active sandboxes by container state.")
+	p.sample("sand_sandboxes", `state="running"`, strconv.Itoa(running))
+	p.sample("sand_sandboxes", `state="stopped"`, strconv.Itoa(stopped))
+
+	p.header("sand_sandbox_events_total", "counter", "Sandbox lifecycle events by type.")
+	for _, typ := range []sandtypes.SandboxEventType{sandtypes.SandboxCreated, sandtypes.SandboxStarted, sandtypes.SandboxStopped, sandtypes.SandboxRemoved, sandtypes.SandboxSyncError} {
+		p.sample("sand_sandbox_events_total", fmt.Sprintf("event=%q", typ), strconv.FormatUint(m.events[typ], 10))
+	}
+
+	p.header("sand_workspace_clones_total", "counter", "Workspace clones by whether they were copy-on-write.")
+	for _, cow := range []bool{true, false} {
+		p.sample("sand_workspace_clones_total", fmt.Sprintf("copy_on_write=%q", strconv.FormatBool(cow)), strconv.FormatUint(m.clones[cow], 10))
+	}
+
+	p.header("sand_image_pull_duration_seconds", "histogram", "Time taken by successful image pulls.")
+	for i, le := range imagePullBuckets {
+		var n uint64
+		if m.pullBuckets != nil {
+			n = m.pullBuckets[i]
+		}
+		p.sample("sand_image_pull_duration_seconds_bucket", fmt.Sprintf("le=%q", formatFloat(le)), strconv.FormatUint(n, 10))
+	}
+	p.sample("sand_image_pull_duration_seconds_bucket", `le="+Inf"`, strconv.FormatUint(m.pullCount, 10))
+	p.sample("sand_image_pull_duration_seconds_sum", "", formatFloat(m.pullSeconds))
+	p.sample("sand_image_pull_duration_seconds_count", "", strconv.FormatUint(m.pullCount, 10))
+
+	p.header("sand_image_pull_failures_total", "counter", "Image pulls that failed.")
+	p.sample("sand_image_pull_failures_total", "", strconv.FormatUint(m.pullFailures, 10))
+	return p.err
+}
+
+// promWriter writes Prometheus text exposition lines, keeping the first error.
+type promWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (p *promWriter) header(name, typ, help string) {
+	p.printf("# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+func (p *promWriter) sample(name, labels, value string) {
+	if labels != "" {
+		name += "{" + labels + "}"
+	}
+	p.printf("%s %s\n", name, value)
+}
+
+func (p *promWriter) printf(format string, args ...any) {
+	if p.err != nil {
+		return
+	}
+	_, p.err = fmt.Fprintf(p.w, format, args...)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,108 @@
+package boxer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/banksean/sand/internal/agents"
+	"github.com/banksean/sand/internal/cloning"
+	"github.com/banksean/sand/internal/hostops"
+	"github.com/banksean/sand/internal/sandtypes"
+)
+
+func TestWriteMetricsCountsCreateAndStop(t *testing.T) {
+	ctx := context.Background()
+	ops := &hostops.MockContainerOps{
+		InspectFunc: func(ctx context.Context, containerID string) ([]sandtypes.Container, error) {
+			return []sandtypes.Container{{Configuration: sandtypes.ContainerConfig{ID: "ctr-1"}, Status: sandtypes.ContainerStatus{State: "stopped"}}}, nil
+		},
+	}
+	b := newTestBoxer(t, ops, &mockImageOps{})
+	b.FileOps = &hostops.MockFileOps{
+		MkdirAllFunc: os.MkdirAll,
+		CreateFunc:   os.Create,
+	}
+	b.AgentRegistry.Register(&agents.AgentConfig{
+		Name: "test-agent",
+		Preparation: &mockWorkspacePreparation{
+			prepareFunc: func(ctx context.Context, req cloning.CloneRequest) (*cloning.CloneArtifacts, error) {
+				sandboxRoot := filepath.Join(b.appRoot, "clones", req.ID)
+				return &cloning.CloneArtifacts{
+					SandboxWorkDir: sandboxRoot,
+					PathRegistry:   cloning.NewStandardPathRegistry(sandboxRoot),
+					CopyOnWrite:    true,
+				}, nil
+			},
+		},
+		Configuration: &mockContainerConfiguration{},
+	})
+
+	sbox, err := b.NewSandbox(ctx, NewSandboxOpts{AgentType: "test-agent", ID: "m-id", Name: "m", HostWorkDir: t.TempDir(), ImageName: "test-image:latest"})
+	if err != nil {
+		t.Fatalf("NewSandbox() error = %v", err)
+	}
+	if err := b.UpdateContainerID(ctx, sbox, "ctr-1"); err != nil {
+		t.Fatalf("UpdateContainerID() error = %v", err)
+	}
+	if err := b.UpdateStartHooksRan(ctx, sbox, true); err != nil {
+		t.Fatalf("UpdateStartHooksRan() error = %v", err)
+	}
+	if err := b.StopContainer(ctx, sbox); err != nil {
+		t.Fatalf("StopContainer() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := b.WriteMetrics(ctx, &buf); err != nil {
+		t.Fatalf("WriteMetrics() error = %v", err)
+	}
+	got := buf.String()
+	for _, want := range []string{
+		"# TYPE sand_sandboxes gauge\n",
+		`sand_sandboxes{state="running"} 0` + "\n",
+		`sand_sandboxes{state="stopped"} 1` + "\n",
+		`sand_sandbox_events_total{event="created"} 1` + "\n",
+		`sand_sandbox_events_total{event="started"} 1` + "\n",
+		`sand_sandbox_events_total{event="stopped"} 1` + "\n",
+		`sand_sandbox_events_total{event="removed"} 0` + "\n",
+		`sand_workspace_clones_total{copy_on_write="true"} 1` + "\n",
+		`sand_workspace_clones_total{copy_on_write="false"} 0` + "\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("metrics missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestMetricsImagePullHistogram(t *testing.T) {
+	b := newDBBoxer(t, t.TempDir())
+	b.metrics.recordPull(3*time.Second, nil)
+	b.metrics.recordPull(90*time.Second, nil)
+	b.metrics.recordPull(time.Second, errors.New("pull failed"))
+
+	var buf bytes.Buffer
+	if err := b.WriteMetrics(context.Background(), &buf); err != nil {
+		t.Fatalf("WriteMetrics() error = %v", err)
+	}
+	got := buf.String()
+	for _, want := range []string{
+		"# TYPE sand_image_pull_duration_seconds histogram\n",
+		`sand_image_pull_duration_seconds_bucket{le="1"} 0` + "\n",
+		`sand_image_pull_duration_seconds_bucket{le="5"} 1` + "\n",
+		`sand_image_pull_duration_seconds_bucket{le="60"} 1` + "\n",
+		`sand_image_pull_duration_seconds_bucket{le="120"} 2` + "\n",
+		`sand_image_pull_duration_seconds_bucket{le="+Inf"} 2` + "\n",
+		"sand_image_pull_duration_seconds_sum 93\n",
+		"sand_image_pull_duration_seconds_count 2\n",
+		"sand_image_pull_failures_total 1\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("metrics missing %q:\n%s", want, got)
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/banksean/sand/internal/cloning"
+	"github.com/banksean/sand/internal/hostops"
 	"github.com/banksean/sand/internal/sandtypes"
 )
 
@@ -66,7 +67,7 @@ func (sb *Boxer) prepareMountRequests(ctx context.Context, paths cloning.PathReg
 		}
 
 		clonePath := filepath.Join(paths.BindMountsDir(), clonedBindMountName(i, parsed.Source))
-		if err := sb.FileOps.Copy(ctx, parsed.Source, clonePath); err != nil {
+		if _, err := sb.FileOps.Copy(ctx, parsed.Source, clonePath, hostops.CopyOpts{}); err != nil {
 			return nil, fmt.Errorf("clone bind mount %q to %q: %w", parsed.Source, clonePath, err)
 		}
 
@@ -58,10 +58,10 @@ func TestPrepareMountRequestsClonesDirectories(t *testing.T) {
 			VolumeFunc: func(path string) (*hostops.VolumeInfo, error) {
 				return &hostops.VolumeInfo{Path: path, MountPoint: "/", DeviceID: 1}, nil
 			},
-			CopyFunc: func(ctx context.Context, src, dst string) error {
+			CopyFunc: func(ctx context.Context, src, dst string, opts hostops.CopyOpts) (hostops.CopyResult, error) {
 				copiedFrom = src
 				copiedTo = dst
-				return nil
+				return hostops.CopyResult{CopyOnWrite: true}, nil
 			},
 		},
 	}
@@ -0,0 +1,164 @@
+package boxer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/banksean/sand/internal/db"
+	"github.com/banksean/sand/internal/sandtypes"
+)
+
+// forwardStartupGrace is how long startSSHForward waits for ssh to fail, e.g.
+// because the port is already in use, before treating the forward as up.
+const forwardStartupGrace = time.Second
+
+// startSSHForward starts a detached ssh process carrying fwd to destination
+// and returns its pid. The process leads its own process group so it outlives
+// the request that started it and can be killed with the sandbox.
+func startSSHForward(destination string, fwd sandtypes.PortForward) (int, error) {
+	args := append([]string{"-N", "-o", "ExitOnForwardFailure=yes", "-o", "BatchMode=yes"}, fwd.SSHArgs()...)
+	args = append(args, destination)
+	// No context - the forward runs until it is stopped, not until the request ends.
+	cmd := exec.Command("ssh", args...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		return 0, fmt.Errorf("failed to start ssh forward: %w", err)
+	}
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+	select {
+	case err := <-exited:
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" && err != nil {
+			msg = err.Error()
+		}
+		return 0, fmt.Errorf("ssh forward %s %s exited: %s", fwd.Flag(), fwd, msg)
+	case <-time.After(forwardStartupGrace):
+		return cmd.Process.Pid, nil
+	}
+}
+
+// StartPortForward opens an ssh port forward between the host and sbox's
+// container and records its process so it is stopped along with the sandbox.
+func (sb *Boxer) StartPortForward(ctx context.Context, sbox *sandtypes.Box, fwd sandtypes.PortForward) (sandtypes.PortForward, error) {
+	if sbox.ContainerID == "" {
+		return sandtypes.PortForward{}, fmt.Errorf("sandbox %s has no container; start it first", sbox.Name)
+	}
+	if sbox.NoSSH {
+		return sandtypes.PortForward{}, fmt.Errorf("sandbox %s was created with --no-ssh; port forwards need its sshd", sbox.Name)
+	}
+	existing, err := sb.ListPortForwards(ctx, sbox)
+	if err != nil {
+		return sandtypes.PortForward{}, err
+	}
+	for _, e := range existing {
+		if e.ProcessName() == fwd.ProcessName() {
+			return sandtypes.PortForward{}, fmt.Errorf("sandbox %s already forwards %s %s (pid %d)", sbox.Name, e.Flag(), e, e.PID)
+		}
+	}
+
+	destination := sandboxSSHHostname(sbox.Name, sbox.DNSDomain)
+	if sbox.Username != "" {
+		destination = sbox.Username + "@" + destination
+	}
+	pid, err := sb.startForward(destination, fwd)
+	if err != nil {
+		return sandtypes.PortForward{}, err
+	}
+	if err := sb.RecordHostProcess(ctx, sbox, fwd.ProcessName(), pid); err != nil {
+		// An unrecorded forward could never be stopped, so don't leave it running.
+		if killErr := sb.killProcessGroup(pid); killErr != nil {
+			slog.WarnContext(ctx, "Boxer.StartPortForward killProcessGroup", "pid", pid, "error", killErr)
+		}
+		return sandtypes.PortForward{}, err
+	}
+	slog.InfoContext(ctx, "Boxer.StartPortForward", "id", sbox.ID, "forward", fwd.ProcessName(), "pid", pid)
+	fwd.PID = pid
+	return fwd, nil
+}
+
+// ListPortForwards returns sbox's running port forwards. Records for forwards
+// whose ssh process has exited are dropped.
+func (sb *Boxer) ListPortForwards(ctx context.Context, sbox *sandtypes.Box) ([]sandtypes.PortForward, error) {
+	procs, err := sb.portForwardProcesses(ctx, sbox)
+	if err != nil {
+		return nil, err
+	}
+	var forwards []sandtypes.PortForward
+	for _, p := range procs {
+		forwards = append(forwards, p.fwd)
+	}
+	return forwards, nil
+}
+
+// portForwardProcess is a running port forward and the record of its ssh
+// process.
+type portForwardProcess struct {
+	fwd  sandtypes.PortForward
+	proc db.HostProcess
+}
+
+// portForwardProcesses returns sbox's running port forwards with their
+// process records, dropping the records of forwards whose ssh process has
+// exited. A forward whose pid now belongs to another process has exited too.
+func (sb *Boxer) portForwardProcesses(ctx context.Context, sbox *sandtypes.Box) ([]portForwardProcess, error) {
+	procs, err := sb.queries.ListHostProcesses(ctx, sbox.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list host processes for sandbox %s: %w", sbox.ID, err)
+	}
+	var forwards []portForwardProcess
+	for _, proc := range procs {
+		fwd, ok := sandtypes.PortForwardFromProcessName(proc.Name)
+		if !ok {
+			continue
+		}
+		if !sb.isRecordedProcess(proc) {
+			slog.InfoContext(ctx, "Boxer.ListPortForwards dropping exited forward", "forward", proc.Name, "pid", proc.Pid)
+			sb.forgetHostProcess(ctx, sbox, proc.Name)
+			continue
+		}
+		fwd.PID = int(proc.Pid)
+		forwards = append(forwards, portForwardProcess{fwd: fwd, proc: proc})
+	}
+	return forwards, nil
+}
+
+// StopPortForwards stops the port forward matching fwd, or all of sbox's port
+// forwards when fwd is nil, and returns the forwards it stopped.
+func (sb *Boxer) StopPortForwards(ctx context.Context, sbox *sandtypes.Box, fwd *sandtypes.PortForward) ([]sandtypes.PortForward, error) {
+	forwards, err := sb.portForwardProcesses(ctx, sbox)
+	if err != nil {
+		return nil, err
+	}
+	var stopped []sandtypes.PortForward
+	for _, p := range forwards {
+		f := p.fwd
+		if fwd != nil && f.ProcessName() != fwd.ProcessName() {
+			continue
+		}
+		if err := sb.killRecordedProcess(p.proc); err != nil && !errors.Is(err, syscall.ESRCH) {
+			return stopped, fmt.Errorf("failed to stop forward %s %s (pid %d): %w", f.Flag(), f, f.PID, err)
+		}
+		sb.forgetHostProcess(ctx, sbox, f.ProcessName())
+		stopped = append(stopped, f)
+	}
+	if fwd != nil && len(stopped) == 0 {
+		return nil, fmt.Errorf("sandbox %s has no forward %s %s", sbox.Name, fwd.Flag(), fwd)
+	}
+	return stopped, nil
+}
+
+func (sb *Boxer) forgetHostProcess(ctx context.Context, sbox *sandtypes.Box, name string) {
+	if err := sb.queries.DeleteHostProcess(ctx, db.DeleteHostProcessParams{SandboxID: sbox.ID, Name: name}); err != nil {
+		slog.ErrorContext(ctx, "Boxer.forgetHostProcess DeleteHostProcess", "name", name, "error", err)
+	}
+}
@@ -0,0 +1,227 @@
+package boxer
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/banksean/sand/internal/sandtypes"
+)
+
+// fakeForwards makes b start a long-running stand-in process for each forward
+// instead of ssh, recording the ssh destinations it was asked for.
+func fakeForwards(t *testing.T, b *Boxer) *[]string {
+	t.Helper()
+	var destinations []string
+	b.startForward = func(destination string, fwd sandtypes.PortForward) (int, error) {
+		destinations = append(destinations, destination)
+		cmd := exec.Command("sleep", "60")
+		cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+		if err := cmd.Start(); err != nil {
+			return 0, err
+		}
+		go cmd.Wait()
+		t.Cleanup(func() { cmd.Process.Kill() })
+		return cmd.Process.Pid, nil
+	}
+	return &destinations
+}
+
+// processGroupAlive reports whether any process in the group led by pgid is
+// still running.
+func processGroupAlive(pgid int) bool {
+	err := syscall.Kill(-pgid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+func waitForExit(t *testing.T, pid int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for processGroupAlive(pid) {
+		if time.Now().After(deadline) {
+			t.Fatalf("process %d still running", pid)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestStartPortForwardRecordsForward(t *testing.T) {
+	ctx := context.Background()
+	b := newDBBoxer(t, t.TempDir())
+	destinations := fakeForwards(t, b)
+	sbox := &sandtypes.Box{ID: "fwd-id", Name: "fwd", ContainerID: "ctr-fwd", Username: "dev", DNSDomain: "test.local"}
+	if err := b.SaveSandbox(ctx, sbox); err != nil {
+		t.Fatalf("SaveSandbox() error = %v", err)
+	}
+
+	fwd, err := sandtypes.ParsePortForward("8080:3000", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	started, err := b.StartPortForward(ctx, sbox, fwd)
+	if err != nil {
+		t.Fatalf("StartPortForward() error = %v", err)
+	}
+	if started.PID == 0 || started.HostPort != 8080 || started.ContainerPort != 3000 {
+		t.Fatalf("StartPortForward() = %+v, want 8080:3000 with a pid", started)
+	}
+	if len(*destinations) != 1 || (*destinations)[0] != "dev@fwd.test.local" {
+		t.Fatalf("ssh destinations = %v, want [dev@fwd.test.local]", *destinations)
+	}
+	if _, err := b.StartPortForward(ctx, sbox, fwd); err == nil || !strings.Contains(err.Error(), "already forwards") {
+		t.Fatalf("duplicate StartPortForward() error = %v, want already forwards", err)
+	}
+
+	procs, err := b.queries.ListHostProcesses(ctx, sbox.ID)
+	if err != nil || len(procs) != 1 || procs[0].Name != fwd.ProcessName() || int(procs[0].Pid) != started.PID {
+		t.Fatalf("host processes = %+v, %v; want the forward's pid", procs, err)
+	}
+	forwards, err := b.ListPortForwards(ctx, sbox)
+	if err != nil || len(forwards) != 1 || forwards[0] != started {
+		t.Fatalf("ListPortForwards() = %+v, %v; want [%+v]", forwards, err, started)
+	}
+}
+
+func TestStartPortForwardRequiresContainer(t *testing.T) {
+	b := newDBBoxer(t, t.TempDir())
+	fakeForwards(t, b)
+	_, err := b.StartPortForward(context.Background(), &sandtypes.Box{ID: "bare", Name: "bare"}, sandtypes.PortForward{HostPort: 1, ContainerPort: 1})
+	if err == nil {
+		t.Fatal("StartPortForward() without a container succeeded, want error")
+	}
+}
+
+func TestStopPortForwardsStopsMatchingForward(t *testing.T) {
+	ctx := context.Background()
+	b := newDBBoxer(t, t.TempDir())
+	fakeForwards(t, b)
+	sbox := &sandtypes.Box{ID: "stop-id", Name: "stop", ContainerID: "ctr-stop"}
+	if err := b.SaveSandbox(ctx, sbox); err != nil {
+		t.Fatalf("SaveSandbox() error = %v", err)
+	}
+	web, err := b.StartPortForward(ctx, sbox, sandtypes.PortForward{HostPort: 8080, ContainerPort: 3000})
+	if err != nil {
+		t.Fatalf("StartPortForward(web) error = %v", err)
+	}
+	debug, err := b.StartPortForward(ctx, sbox, sandtypes.PortForward{Remote: true, HostPort: 9229, ContainerPort: 9229})
+	if err != nil {
+		t.Fatalf("StartPortForward(debug) error = %v", err)
+	}
+	// Other host processes, like the opencode tunnel, are not port forwards.
+	if err := b.RecordHostProcess(ctx, sbox, "opencode-chrome-devtools-tunnel", debug.PID); err != nil {
+		t.Fatalf("RecordHostProcess() error = %v", err)
+	}
+
+	stopped, err := b.StopPortForwards(ctx, sbox, &sandtypes.PortForward{HostPort: 8080, ContainerPort: 3000})
+	if err != nil {
+		t.Fatalf("StopPortForwards(web) error = %v", err)
+	}
+	if len(stopped) != 1 || stopped[0] != web {
+		t.Fatalf("StopPortForwards(web) = %+v, want [%+v]", stopped, web)
+	}
+	waitForExit(t, web.PID)
+	if !processGroupAlive(debug.PID) {
+		t.Fatal("stopping the web forward killed the debug forward")
+	}
+	if _, err := b.StopPortForwards(ctx, sbox, &sandtypes.PortForward{HostPort: 8080, ContainerPort: 3000}); err == nil {
+		t.Fatal("stopping an already stopped forward succeeded, want error")
+	}
+
+	stopped, err = b.StopPortForwards(ctx, sbox, nil)
+	if err != nil {
+		t.Fatalf("StopPortForwards(all) error = %v", err)
+	}
+	if len(stopped) != 1 || stopped[0] != debug {
+		t.Fatalf("StopPortForwards(all) = %+v, want [%+v]", stopped, debug)
+	}
+	waitForExit(t, debug.PID)
+	procs, err := b.queries.ListHostProcesses(ctx, sbox.ID)
+	if err != nil || len(procs) != 1 || procs[0].Name != "opencode-chrome-devtools-tunnel" {
+		t.Fatalf("host processes = %+v, %v; want only the tunnel record", procs, err)
+	}
+}
+
+func TestListPortForwardsDropsExitedForwards(t *testing.T) {
+	ctx := context.Background()
+	b := newDBBoxer(t, t.TempDir())
+	fakeForwards(t, b)
+	sbox := &sandtypes.Box{ID: "exit-id", Name: "exit", ContainerID: "ctr-exit"}
+	if err := b.SaveSandbox(ctx, sbox); err != nil {
+		t.Fatalf("SaveSandbox() error = %v", err)
+	}
+	fwd, err := b.StartPortForward(ctx, sbox, sandtypes.PortForward{HostPort: 5173, ContainerPort: 5173})
+	if err != nil {
+		t.Fatalf("StartPortForward() error = %v", err)
+	}
+	if err := syscall.Kill(fwd.PID, syscall.SIGKILL); err != nil {
+		t.Fatal(err)
+	}
+	waitForExit(t, fwd.PID)
+
+	forwards, err := b.ListPortForwards(ctx, sbox)
+	if err != nil || len(forwards) != 0 {
+		t.Fatalf("ListPortForwards() = %+v, %v; want none", forwards, err)
+	}
+	procs, err := b.queries.ListHostProcesses(ctx, sbox.ID)
+	if err != nil || len(procs) != 0 {
+		t.Fatalf("host processes = %+v, %v; want the exited forward dropped", procs, err)
+	}
+}
+
+func TestStopContainerStopsPortForwards(t *testing.T) {
+	ctx := context.Background()
+	b := newDBBoxer(t, t.TempDir())
+	fakeForwards(t, b)
+	sbox := &sandtypes.Box{ID: "ctr-stop-id", Name: "ctr-stop", ContainerID: "ctr-1"}
+	if err := b.SaveSandbox(ctx, sbox); err != nil {
+		t.Fatalf("SaveSandbox() error = %v", err)
+	}
+	fwd, err := b.StartPortForward(ctx, sbox, sandtypes.PortForward{HostPort: 8080, ContainerPort: 8080})
+	if err != nil {
+		t.Fatalf("StartPortForward() error = %v", err)
+	}
+	if err := b.StopContainer(ctx, sbox); err != nil {
+		t.Fatalf("StopContainer() error = %v", err)
+	}
+	waitForExit(t, fwd.PID)
+}
+
+func TestPortForwardsLeaveAReusedPidAlone(t *testing.T) {
+	ctx := context.Background()
+	b := newDBBoxer(t, t.TempDir())
+	started := map[int]string{}
+	fakeProcessStartTimes(b, started)
+	var killed []int
+	b.killProcessGroup = func(pgid int) error {
+		killed = append(killed, pgid)
+		return nil
+	}
+	b.startForward = func(destination string, fwd sandtypes.PortForward) (int, error) {
+		started[4242] = "forward"
+		return 4242, nil
+	}
+	sbox := &sandtypes.Box{ID: "reuse-id", Name: "reuse", ContainerID: "ctr-reuse"}
+	if err := b.SaveSandbox(ctx, sbox); err != nil {
+		t.Fatalf("SaveSandbox() error = %v", err)
+	}
+	if _, err := b.StartPortForward(ctx, sbox, sandtypes.PortForward{HostPort: 8080, ContainerPort: 3000}); err != nil {
+		t.Fatalf("StartPortForward() error = %v", err)
+	}
+	// The forward's ssh exits and its pid goes to some other process.
+	started[4242] = "later"
+
+	if stopped, err := b.StopPortForwards(ctx, sbox, nil); err != nil || len(stopped) != 0 {
+		t.Fatalf("StopPortForwards() = %+v, %v; want nothing to stop", stopped, err)
+	}
+	if len(killed) != 0 {
+		t.Fatalf("killed process groups = %v, want the reused pid left alone", killed)
+	}
+	procs, err := b.queries.ListHostProcesses(ctx, sbox.ID)
+	if err != nil || len(procs) != 0 {
+		t.Fatalf("host processes = %+v, %v; want the exited forward dropped", procs, err)
+	}
+}
@@ -0,0 +1,126 @@
+package boxer
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/banksean/sand/internal/hostops"
+	"github.com/banksean/sand/internal/sandtypes"
+)
+
+// procStatsFiles are read inside a container when the runtime cannot report
+// its resource usage.
+var procStatsFiles = []string{"/proc/meminfo", "/proc/loadavg", "/proc/uptime", "/proc/stat"}
+
+// userHZ is the kernel clock tick rate /proc/stat reports CPU time in.
+const userHZ = 100
+
+// procContainerStats reads resource usage from inside the container by
+// catting procStatsFiles, for runtimes that do not expose live stats.
+func (sb *Boxer) procContainerStats(ctx context.Context, containerID string) (sandtypes.ContainerStats, error) {
+	out, err := sb.ContainerService.Exec(ctx, &hostops.ExecContainer{}, containerID, "cat", nil, procStatsFiles...)
+	if err != nil {
+		return sandtypes.ContainerStats{}, fmt.Errorf("read /proc stats in container %s: %w", containerID, err)
+	}
+	return parseProcStats(containerID, out)
+}
+
+// parseProcStats parses the concatenated contents of procStatsFiles. Block
+// and network counters are not available this way and are reported as -1.
+func parseProcStats(containerID, out string) (sandtypes.ContainerStats, error) {
+	stats := sandtypes.ContainerStats{
+		ID:              containerID,
+		BlockReadBytes:  -1,
+		BlockWriteBytes: -1,
+		NetworkRxBytes:  -1,
+		NetworkTxBytes:  -1,
+	}
+	var memTotalKB, memAvailableKB int
+	var sawMem, sawCPU bool
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+		case strings.HasSuffix(fields[0], ":"):
+			// /proc/meminfo: "MemTotal:  8137504 kB"
+			if len(fields) < 2 {
+				continue
+			}
+			kb, err := strconv.Atoi(fields[1])
+			if err != nil {
+				continue
+			}
+			switch fields[0] {
+			case "MemTotal:":
+				memTotalKB, sawMem = kb, true
+			case "MemAvailable:":
+				memAvailableKB = kb
+			}
+		case fields[0] == "cpu":
+			// /proc/stat: "cpu user nice system idle iowait irq softirq steal ..."
+			var ticks int
+			for i, f := range fields[1:] {
+				if i == 3 || i == 4 {
+					continue // idle, iowait
+				}
+				if i >= 8 {
+					break // guest time is already counted in user
+				}
+				n, err := strconv.Atoi(f)
+				if err != nil {
+					return stats, fmt.Errorf("parse /proc/stat cpu line %q: %w", line, err)
+				}
+				ticks += n
+			}
+			stats.CPUUsageUsec = ticks * (1_000_000 / userHZ)
+			sawCPU = true
+		case len(fields) == 5 && strings.Contains(fields[3], "/"):
+			// /proc/loadavg: "0.00 0.01 0.05 1/123 456"
+			_, total, _ := strings.Cut(fields[3], "/")
+			if n, err := strconv.Atoi(total); err == nil {
+				stats.NumProcesses = n
+			}
+		case len(fields) == 2:
+			// /proc/uptime: "3600.52 7100.10"
+			if secs, err := strconv.ParseFloat(fields[0], 64); err == nil {
+				stats.UptimeSeconds = int(secs)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return stats, err
+	}
+	if !sawMem || !sawCPU {
+		return stats, fmt.Errorf("container %s /proc output is missing memory or cpu stats", containerID)
+	}
+	stats.MemoryLimitBytes = memTotalKB * 1024
+	stats.MemoryUsageBytes = (memTotalKB - memAvailableKB) * 1024
+	return stats, nil
+}
+
+// withProcStatsFallback fills in stats for any of containerIDs the runtime
+// did not report on by reading /proc inside those containers.
+func (sb *Boxer) withProcStatsFallback(ctx context.Context, containerIDs []string, stats []sandtypes.ContainerStats) []sandtypes.ContainerStats {
+	reported := make(map[string]bool, len(stats))
+	for _, s := range stats {
+		reported[s.ID] = true
+	}
+	for _, id := range containerIDs {
+		if reported[id] {
+			continue
+		}
+		s, err := sb.procContainerStats(ctx, id)
+		if err != nil {
+			slog.WarnContext(ctx, "Boxer.GetContainerStats fallback", "containerID", id, "error", err)
+			continue
+		}
+		stats = append(stats, s)
+	}
+	return stats
+}
@@ -0,0 +1,120 @@
+package boxer
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/banksean/sand/internal/hostops"
+	"github.com/banksean/sand/internal/sandtypes"
+)
+
+const sampleProcStats = `MemTotal:        4028596 kB
+MemFree:         2866704 kB
+MemAvailable:    3518260 kB
+Buffers:           44104 kB
+Cached:           669240 kB
+0.42 0.31 0.12 2/87 1234
+5123.47 20011.90
+cpu  1200 30 450 98000 120 0 20 5 300 0
+cpu0 600 15 225 49000 60 0 10 5 150 0
+intr 123456 0 0
+ctxt 987654
+btime 1760000000
+processes 4321
+procs_running 2
+`
+
+func TestParseProcStats(t *testing.T) {
+	got, err := parseProcStats("ctr-1", sampleProcStats)
+	if err != nil {
+		t.Fatalf("parseProcStats() error = %v", err)
+	}
+	want := sandtypes.ContainerStats{
+		ID:               "ctr-1",
+		MemoryUsageBytes: (4028596 - 3518260) * 1024,
+		MemoryLimitBytes: 4028596 * 1024,
+		// user+nice+system+irq+softirq+steal ticks at 100Hz.
+		CPUUsageUsec:    (1200 + 30 + 450 + 0 + 20 + 5) * 10_000,
+		NumProcesses:    87,
+		UptimeSeconds:   5123,
+		BlockReadBytes:  -1,
+		BlockWriteBytes: -1,
+		NetworkRxBytes:  -1,
+		NetworkTxBytes:  -1,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("parseProcStats() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseProcStatsRequiresMemoryAndCPU(t *testing.T) {
+	for name, out := range map[string]string{
+		"empty":       "",
+		"no meminfo":  "0.00 0.00 0.00 1/10 5\n12.0 40.0\ncpu 1 2 3 4 5 6 7 8\n",
+		"no cpu line": "MemTotal: 1024 kB\nMemAvailable: 512 kB\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := parseProcStats("ctr-1", out); err == nil {
+				t.Fatal("parseProcStats() error = nil, want missing stats error")
+			}
+		})
+	}
+}
+
+func TestParseProcStatsRejectsMalformedCPULine(t *testing.T) {
+	if _, err := parseProcStats("ctr-1", "MemTotal: 1024 kB\ncpu 1 x 3 4\n"); err == nil {
+		t.Fatal("parseProcStats() error = nil, want parse error")
+	}
+}
+
+func TestGetContainerStatsFallsBackToProc(t *testing.T) {
+	ctx := context.Background()
+	var execed []string
+	containerOps := &hostops.MockContainerOps{
+		StatsFunc: func(ctx context.Context, containerID ...string) ([]sandtypes.ContainerStats, error) {
+			// The runtime only knows about ctr-live.
+			return []sandtypes.ContainerStats{{ID: "ctr-live", MemoryUsageBytes: 1}}, nil
+		},
+		ExecFunc: func(ctx context.Context, opts *hostops.ExecContainer, containerID, cmd string, env []string, args ...string) (string, error) {
+			execed = append(execed, containerID)
+			if cmd != "cat" || !reflect.DeepEqual(args, procStatsFiles) {
+				t.Errorf("Exec(%q, %v), want cat %v", cmd, args, procStatsFiles)
+			}
+			return sampleProcStats, nil
+		},
+	}
+	b := newTestBoxer(t, containerOps, &mockImageOps{})
+
+	stats, err := b.GetContainerStats(ctx, "ctr-live", "ctr-proc")
+	if err != nil {
+		t.Fatalf("GetContainerStats() error = %v", err)
+	}
+	if len(stats) != 2 || stats[0].ID != "ctr-live" || stats[1].ID != "ctr-proc" {
+		t.Fatalf("GetContainerStats() = %+v, want ctr-live then ctr-proc", stats)
+	}
+	if stats[1].UptimeSeconds != 5123 {
+		t.Errorf("fallback UptimeSeconds = %d, want 5123", stats[1].UptimeSeconds)
+	}
+	if !reflect.DeepEqual(execed, []string{"ctr-proc"}) {
+		t.Errorf("exec'd in %v, want only ctr-proc", execed)
+	}
+}
+
+func TestGetContainerStatsRuntimeErrorWithoutFallback(t *testing.T) {
+	runtimeErr := errors.New("stats not supported")
+	containerOps := &hostops.MockContainerOps{
+		StatsFunc: func(ctx context.Context, containerID ...string) ([]sandtypes.ContainerStats, error) {
+			return nil, runtimeErr
+		},
+		ExecFunc: func(ctx context.Context, opts *hostops.ExecContainer, containerID, cmd string, env []string, args ...string) (string, error) {
+			return "", errors.New("container not running")
+		},
+	}
+	b := newTestBoxer(t, containerOps, &mockImageOps{})
+
+	if _, err := b.GetContainerStats(context.Background(), "ctr-1"); !errors.Is(err, runtimeErr) {
+		t.Fatalf("GetContainerStats() error = %v, want %v", err, runtimeErr)
+	}
+}
@@ -0,0 +1,31 @@
+//go:build darwin
+
+package boxer
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// processStartTime returns when the process with the given pid started, as
+// the kernel reports it. A pid the kernel hands out again gets a new start
+// time, so the pair identifies one process.
+func processStartTime(pid int) (string, error) {
+	kp, err := unix.SysctlKinfoProc("kern.proc.pid", pid)
+	if err != nil {
+		return "", fmt.Errorf("look up process %d: %w", pid, err)
+	}
+	start := kp.Proc.P_starttime
+	return fmt.Sprintf("%d.%06d", start.Sec, start.Usec), nil
+}
+
+// hostBootTime returns when the host last booted. Process start times are
+// only comparable within one boot.
+func hostBootTime() (string, error) {
+	boot, err := unix.SysctlTimeval("kern.boottime")
+	if err != nil {
+		return "", fmt.Errorf("look up boot time: %w", err)
+	}
+	return fmt.Sprintf("%d.%06d", boot.Sec, boot.Usec), nil
+}
@@ -0,0 +1,40 @@
+//go:build !darwin
+
+package boxer
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// processStartTime returns when the process with the given pid started, in
+// clock ticks since boot from /proc. A pid the kernel hands out again gets a
+// new start time, so the pair identifies one process.
+func processStartTime(pid int) (string, error) {
+	stat, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return "", fmt.Errorf("look up process %d: %w", pid, err)
+	}
+	// The command name in parentheses may hold spaces; the fields after it
+	// start with the state, the third field of the line.
+	var fields []string
+	if i := strings.LastIndexByte(string(stat), ')'); i >= 0 {
+		fields = strings.Fields(string(stat[i+1:]))
+	}
+	if len(fields) < 20 {
+		return "", fmt.Errorf("look up process %d: malformed /proc stat %q", pid, stat)
+	}
+	return fields[19], nil
+}
+
+// hostBootTime identifies the host's current boot. Process start times are
+// only comparable within one boot.
+func hostBootTime() (string, error) {
+	id, err := os.ReadFile("/proc/sys/kernel/random/boot_id")
+	if err != nil {
+		return "", fmt.Errorf("look up boot id: %w", err)
+	}
+	return strings.TrimSpace(string(id)), nil
+}
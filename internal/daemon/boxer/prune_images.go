@@ -0,0 +1,145 @@
+package boxer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/banksean/sand/internal/runtimedeps"
+)
+
+// infrastructureImages are images sand runs outside of sandboxes, so
+// PruneImages never removes them.
+var infrastructureImages = []string{HTTPProxyCacheImage, runtimedeps.CustomInitImage}
+
+// PruneImagesOpts controls PruneImages.
+type PruneImagesOpts struct {
+	// DryRun reports the images PruneImages would remove without removing them.
+	DryRun bool
+	// Keep names images to leave in place even though no sandbox uses them.
+	Keep []string
+}
+
+// PruneImages removes local images that sand pulled or built and that no
+// sandbox uses, including soft-deleted sandboxes that may yet be recovered.
+// It returns the images it removed, or with opts.DryRun the images it would
+// have removed.
+func (sb *Boxer) PruneImages(ctx context.Context, opts PruneImagesOpts) ([]string, error) {
+	unused, err := sb.unreferencedImages(ctx, opts.Keep)
+	if err != nil {
+		return nil, err
+	}
+	if opts.DryRun {
+		return unused, nil
+	}
+	var removed []string
+	var errs []error
+	for _, name := range unused {
+		slog.InfoContext(ctx, "Boxer.PruneImages removing", "image", name)
+		if err := sb.ImageService.Remove(ctx, name); err != nil {
+			errs = append(errs, fmt.Errorf("failed to remove image %s: %w", name, err))
+			continue
+		}
+		if err := sb.queries.DeleteSandImage(ctx, normalizeImageRef(name)); err != nil {
+			slog.WarnContext(ctx, "Boxer.PruneImages DeleteSandImage", "image", name, "error", err)
+		}
+		removed = append(removed, name)
+	}
+	return removed, errors.Join(errs...)
+}
+
+// unreferencedImages returns the sorted names of local images that sand
+// pulled or built and that no active or soft-deleted sandbox uses, leaving out
+// keep and infrastructureImages. Images sand didn't fetch itself are the
+// user's, so they are never candidates. Names are compared as normalized
+// references, so "ubuntu" and "docker.io/library/ubuntu:latest" are the same
+// image, and a sandbox pinned to a digest keeps the image with that digest.
+func (sb *Boxer) unreferencedImages(ctx context.Context, keep []string) ([]string, error) {
+	images, err := sb.ImageService.List(ctx)
+	if err != nil {
+		if runtimedeps.IsContainerSystemNotRunningError(err) {
+			return nil, runtimedeps.ContainerSystemNotRunningError(err)
+		}
+		return nil, fmt.Errorf("failed to list images: %w", err)
+	}
+	recorded, err := sb.queries.ListSandImages(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list images sand fetched: %w", err)
+	}
+	fetched := map[string]bool{}
+	for _, name := range recorded {
+		fetched[normalizeImageRef(name)] = true
+	}
+	active, err := sb.queries.ListSandboxes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sandboxes: %w", err)
+	}
+	deleted, err := sb.queries.ListDeletedSandboxes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deleted sandboxes: %w", err)
+	}
+	used := map[string]bool{}
+	for _, s := range append(active, deleted...) {
+		used[normalizeImageRef(s.ImageName)] = true
+		if digest := imageDigestFromRef(s.ImageName); digest != "" {
+			used[digest] = true
+		}
+		if s.ImageDigest.String != "" {
+			used[s.ImageDigest.String] = true
+		}
+	}
+	for _, name := range slices.Concat(keep, infrastructureImages) {
+		used[normalizeImageRef(name)] = true
+	}
+
+	var unused []string
+	for _, image := range images {
+		name := image.Configuration.Name
+		if name == "" || !fetched[normalizeImageRef(name)] || used[normalizeImageRef(name)] {
+			continue
+		}
+		if digest := image.Configuration.Descriptor.Digest; digest != "" && used[digest] {
+			continue
+		}
+		unused = append(unused, name)
+	}
+	slices.Sort(unused)
+	return slices.Compact(unused), nil
+}
+
+// recordFetchedImage notes that sand pulled or built imageName, making it one
+// PruneImages may remove once no sandbox uses it. Failing to record it only
+// means prune leaves the image alone, so it doesn't fail the pull or build.
+func (sb *Boxer) recordFetchedImage(ctx context.Context, imageName string) {
+	if err := sb.queries.RecordSandImage(ctx, normalizeImageRef(imageName)); err != nil {
+		slog.WarnContext(ctx, "Boxer.recordFetchedImage", "imageName", imageName, "error", err)
+	}
+}
+
+// normalizeImageRef returns ref in its fully qualified form, the registry and
+// repository spelled out and the tag defaulting to latest:
+// "ubuntu" becomes "docker.io/library/ubuntu:latest". Digest-pinned references
+// keep their digest and get no default tag.
+func normalizeImageRef(ref string) string {
+	name, digest, pinned := strings.Cut(ref, "@")
+	domain, rest, ok := strings.Cut(name, "/")
+	if !ok || (!strings.ContainsAny(domain, ".:") && domain != "localhost") {
+		domain, rest = "docker.io", name
+	}
+	if domain == "index.docker.io" {
+		domain = "docker.io"
+	}
+	if domain == "docker.io" && !strings.Contains(rest, "/") {
+		rest = "library/" + rest
+	}
+	if !pinned && !strings.Contains(rest, ":") {
+		rest += ":latest"
+	}
+	if pinned {
+		rest += "@" + digest
+	}
+	return domain + "/" + rest
+}
@@ -0,0 +1,163 @@
+package boxer
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/banksean/sand/internal/sandtypes"
+)
+
+func newPruneTestBoxer(t *testing.T) (*Boxer, *[]string) {
+	t.Helper()
+	ctx := context.Background()
+	b := newDBBoxer(t, t.TempDir())
+	var removed []string
+	b.ImageService = &mockImageOps{
+		listFunc: func(ctx context.Context) ([]sandtypes.ImageEntry, error) {
+			var images []sandtypes.ImageEntry
+			for _, name := range []string{"used:latest", "trashed:latest", "old:latest", "other:1", "keep:latest", HTTPProxyCacheImage} {
+				images = append(images, sandtypes.ImageEntry{Configuration: sandtypes.ImageConfiguration{Name: name}})
+			}
+			return images, nil
+		},
+		removeFunc: func(ctx context.Context, name string) error {
+			removed = append(removed, name)
+			return nil
+		},
+	}
+	for _, name := range []string{"used:latest", "trashed:latest", "old:latest", "other:1", "keep:latest"} {
+		b.recordFetchedImage(ctx, name)
+	}
+	for _, box := range []*sandtypes.Box{
+		{ID: "active", Name: "active", State: "active", ImageName: "used:latest"},
+		{ID: "trashed", Name: "trashed", State: "deleted", ImageName: "trashed:latest"},
+	} {
+		if err := b.SaveSandbox(ctx, box); err != nil {
+			t.Fatalf("SaveSandbox(%s) error = %v", box.ID, err)
+		}
+	}
+	return b, &removed
+}
+
+func TestPruneImagesDryRunListsUnreferencedImages(t *testing.T) {
+	b, removed := newPruneTestBoxer(t)
+
+	got, err := b.PruneImages(context.Background(), PruneImagesOpts{DryRun: true, Keep: []string{"keep:latest"}})
+	if err != nil {
+		t.Fatalf("PruneImages() error = %v", err)
+	}
+	if want := []string{"old:latest", "other:1"}; !slices.Equal(got, want) {
+		t.Fatalf("PruneImages() = %v, want %v", got, want)
+	}
+	if len(*removed) != 0 {
+		t.Fatalf("dry run removed %v, want nothing", *removed)
+	}
+}
+
+func TestPruneImagesRemovesUnreferencedImages(t *testing.T) {
+	b, removed := newPruneTestBoxer(t)
+
+	got, err := b.PruneImages(context.Background(), PruneImagesOpts{})
+	if err != nil {
+		t.Fatalf("PruneImages() error = %v", err)
+	}
+	want := []string{"keep:latest", "old:latest", "other:1"}
+	if !slices.Equal(got, want) || !slices.Equal(*removed, want) {
+		t.Fatalf("PruneImages() = %v and removed %v, want %v for both", got, *removed, want)
+	}
+}
+
+func TestPruneImagesKeepsGoingAfterARemoveFails(t *testing.T) {
+	b, removed := newPruneTestBoxer(t)
+	ops := b.ImageService.(*mockImageOps)
+	ops.removeFunc = func(ctx context.Context, name string) error {
+		if name == "old:latest" {
+			return errors.New("in use")
+		}
+		*removed = append(*removed, name)
+		return nil
+	}
+
+	got, err := b.PruneImages(context.Background(), PruneImagesOpts{Keep: []string{"keep:latest"}})
+	if err == nil {
+		t.Fatal("PruneImages() error = nil, want the remove error")
+	}
+	if !slices.Equal(got, []string{"other:1"}) {
+		t.Fatalf("PruneImages() = %v, want [other:1]", got)
+	}
+}
+
+func TestPruneImagesLeavesImagesSandDidNotFetch(t *testing.T) {
+	b, removed := newPruneTestBoxer(t)
+	ops := b.ImageService.(*mockImageOps)
+	ops.listFunc = func(ctx context.Context) ([]sandtypes.ImageEntry, error) {
+		return []sandtypes.ImageEntry{
+			{Configuration: sandtypes.ImageConfiguration{Name: "docker.io/library/old:latest"}},
+			{Configuration: sandtypes.ImageConfiguration{Name: "docker.io/library/mine:latest"}},
+			{Configuration: sandtypes.ImageConfiguration{Name: "sandbox-builder:latest"}},
+		}, nil
+	}
+
+	got, err := b.PruneImages(context.Background(), PruneImagesOpts{})
+	if err != nil {
+		t.Fatalf("PruneImages() error = %v", err)
+	}
+	if want := []string{"docker.io/library/old:latest"}; !slices.Equal(got, want) || !slices.Equal(*removed, want) {
+		t.Fatalf("PruneImages() = %v and removed %v, want %v for both", got, *removed, want)
+	}
+	if images, err := b.queries.ListSandImages(context.Background()); err != nil || slices.Contains(images, "docker.io/library/old:latest") {
+		t.Fatalf("ListSandImages() = %v, %v, want the pruned image forgotten", images, err)
+	}
+}
+
+func TestPruneImagesComparesNormalizedReferences(t *testing.T) {
+	ctx := context.Background()
+	b, removed := newPruneTestBoxer(t)
+	const digest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	for _, box := range []*sandtypes.Box{
+		{ID: "short", Name: "short", State: "active", ImageName: "ubuntu"},
+		{ID: "pinned", Name: "pinned", State: "active", ImageName: "ghcr.io/acme/tools@" + digest},
+	} {
+		if err := b.SaveSandbox(ctx, box); err != nil {
+			t.Fatalf("SaveSandbox(%s) error = %v", box.ID, err)
+		}
+	}
+	b.recordFetchedImage(ctx, "ubuntu")
+	b.recordFetchedImage(ctx, "ghcr.io/acme/tools@"+digest)
+	ops := b.ImageService.(*mockImageOps)
+	ops.listFunc = func(ctx context.Context) ([]sandtypes.ImageEntry, error) {
+		return []sandtypes.ImageEntry{
+			{Configuration: sandtypes.ImageConfiguration{Name: "docker.io/library/ubuntu:latest"}},
+			{Configuration: sandtypes.ImageConfiguration{Name: "ghcr.io/acme/tools@" + digest, Descriptor: sandtypes.ImageDescriptor{Digest: digest}}},
+		}, nil
+	}
+
+	got, err := b.PruneImages(ctx, PruneImagesOpts{})
+	if err != nil {
+		t.Fatalf("PruneImages() error = %v", err)
+	}
+	if len(got) != 0 || len(*removed) != 0 {
+		t.Fatalf("PruneImages() = %v and removed %v, want the images sandboxes use kept", got, *removed)
+	}
+}
+
+func TestNormalizeImageRef(t *testing.T) {
+	for ref, want := range map[string]string{
+		"ubuntu":                         "docker.io/library/ubuntu:latest",
+		"ubuntu:24.04":                   "docker.io/library/ubuntu:24.04",
+		"docker.io/library/ubuntu":       "docker.io/library/ubuntu:latest",
+		"index.docker.io/acme/app:1":     "docker.io/acme/app:1",
+		"acme/app":                       "docker.io/acme/app:latest",
+		"ghcr.io/banksean/sand/default":  "ghcr.io/banksean/sand/default:latest",
+		"localhost:5000/app":             "localhost:5000/app:latest",
+		"localhost/app:dev":              "localhost/app:dev",
+		"ubuntu@sha256:abc":              "docker.io/library/ubuntu@sha256:abc",
+		"ghcr.io/acme/tools:1@sha256:ab": "ghcr.io/acme/tools:1@sha256:ab",
+	} {
+		if got := normalizeImageRef(ref); got != want {
+			t.Errorf("normalizeImageRef(%q) = %q, want %q", ref, got, want)
+		}
+	}
+}
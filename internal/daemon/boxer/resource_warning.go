@@ -0,0 +1,93 @@
+package boxer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/banksean/sand/internal/hostops"
+	"github.com/banksean/sand/internal/sandtypes"
+)
+
+// memoryWarningPercent is how much of its memory limit a container may use
+// before Sync sets its SandboxResourceWarning.
+const memoryWarningPercent = 90
+
+// cgroupMemoryScript prints a container's memory use and limit, one per line,
+// from cgroup v2, or from cgroup v1 where v2 isn't mounted.
+const cgroupMemoryScript = `cat /sys/fs/cgroup/memory.current /sys/fs/cgroup/memory.max 2>/dev/null ||
+cat /sys/fs/cgroup/memory/memory.usage_in_bytes /sys/fs/cgroup/memory/memory.limit_in_bytes`
+
+// cgroupV1Unlimited is the smallest limit cgroup v1 reports for a cgroup with
+// no memory limit; it is the page-rounded maximum int64.
+const cgroupV1Unlimited = 1 << 62
+
+// containerMemory returns how much memory the container uses and its limit.
+// Its cgroup's limit is used if it has one; otherwise, as in VM-backed
+// containers whose processes run in the root cgroup, the limit is the memory
+// /proc/meminfo reports.
+func (sb *Boxer) containerMemory(ctx context.Context, containerID string) (used, limit int64, err error) {
+	out, err := sb.ContainerService.Exec(ctx, &hostops.ExecContainer{}, containerID, "sh", nil, "-c", cgroupMemoryScript)
+	if err == nil {
+		if used, limit, ok := parseCgroupMemory(out); ok {
+			return used, limit, nil
+		}
+	}
+	stats, err := sb.procContainerStats(ctx, containerID)
+	if err != nil {
+		return 0, 0, err
+	}
+	return int64(stats.MemoryUsageBytes), int64(stats.MemoryLimitBytes), nil
+}
+
+// parseCgroupMemory parses cgroupMemoryScript's output. It reports false if
+// the output is unreadable or the cgroup has no memory limit.
+func parseCgroupMemory(out string) (used, limit int64, ok bool) {
+	fields := strings.Fields(out)
+	if len(fields) != 2 || fields[1] == "max" {
+		return 0, 0, false
+	}
+	used, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	limit, err = strconv.ParseInt(fields[1], 10, 64)
+	if err != nil || limit <= 0 || limit >= cgroupV1Unlimited {
+		return 0, 0, false
+	}
+	return used, limit, true
+}
+
+// memoryWarning is the SandboxResourceWarning for a container using used of
+// limit bytes, or "" if it isn't near the limit.
+func memoryWarning(used, limit int64) string {
+	if limit <= 0 {
+		return ""
+	}
+	percent := used * 100 / limit
+	if percent < memoryWarningPercent {
+		return ""
+	}
+	return fmt.Sprintf("MEMORY %d%% OF LIMIT", percent)
+}
+
+// syncResourceWarning sets sb's SandboxResourceWarning from its container's
+// memory use. Containers that aren't running, or whose use can't be read,
+// get no warning.
+func (sb *Boxer) syncResourceWarning(ctx context.Context, box *sandtypes.Box) {
+	box.SandboxResourceWarning = ""
+	if box.Container == nil || box.Container.Status.State != "running" {
+		return
+	}
+	used, limit, err := sb.containerMemory(ctx, box.ContainerID)
+	if err != nil {
+		slog.DebugContext(ctx, "Boxer.Sync containerMemory", "containerID", box.ContainerID, "error", err)
+		return
+	}
+	box.SandboxResourceWarning = memoryWarning(used, limit)
+	if box.SandboxResourceWarning != "" {
+		slog.WarnContext(ctx, "Boxer.Sync memory pressure", "containerID", box.ContainerID, "used", used, "limit", limit)
+	}
+}
@@ -0,0 +1,106 @@
+package boxer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/banksean/sand/internal/hostops"
+	"github.com/banksean/sand/internal/sandtypes"
+)
+
+func TestParseCgroupMemory(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		out         string
+		used, limit int64
+		ok          bool
+	}{
+		{name: "v2 with limit", out: "1933000000\n2147483648\n", used: 1933000000, limit: 2147483648, ok: true},
+		{name: "v1 with limit", out: "524288000\n1073741824\n", used: 524288000, limit: 1073741824, ok: true},
+		{name: "v2 unlimited", out: "734003200\nmax\n"},
+		{name: "v1 unlimited", out: "734003200\n9223372036854771712\n"},
+		{name: "missing limit", out: "734003200\n"},
+		{name: "garbage", out: "cat: can't open '/sys/fs/cgroup/memory.current': No such file or directory\n"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			used, limit, ok := parseCgroupMemory(tc.out)
+			if used != tc.used || limit != tc.limit || ok != tc.ok {
+				t.Fatalf("parseCgroupMemory() = %d, %d, %v; want %d, %d, %v", used, limit, ok, tc.used, tc.limit, tc.ok)
+			}
+		})
+	}
+}
+
+func TestMemoryWarning(t *testing.T) {
+	for _, tc := range []struct {
+		used, limit int64
+		want        string
+	}{
+		{used: 1933000000, limit: 2147483648, want: "MEMORY 90% OF LIMIT"},
+		{used: 2147483648, limit: 2147483648, want: "MEMORY 100% OF LIMIT"},
+		{used: 1825361100, limit: 2147483648, want: ""},
+		{used: 100, limit: 0, want: ""},
+	} {
+		if got := memoryWarning(tc.used, tc.limit); got != tc.want {
+			t.Errorf("memoryWarning(%d, %d) = %q, want %q", tc.used, tc.limit, got, tc.want)
+		}
+	}
+}
+
+func TestSyncBoxSetsResourceWarning(t *testing.T) {
+	running := &sandtypes.Container{Status: sandtypes.ContainerStatus{State: "running"}}
+	for _, tc := range []struct {
+		name      string
+		container *sandtypes.Container
+		cgroup    string
+		cgroupErr error
+		want      string
+	}{
+		{name: "near the cgroup limit", container: running, cgroup: "1933000000\n2147483648\n", want: "MEMORY 90% OF LIMIT"},
+		{name: "well under the cgroup limit", container: running, cgroup: "536870912\n2147483648\n"},
+		// sampleProcStats has 3518260 of 4028596 kB available.
+		{name: "unlimited cgroup falls back to /proc", container: running, cgroup: "734003200\nmax\n"},
+		{name: "no cgroup files falls back to /proc", container: running, cgroupErr: errors.New("exit status 1")},
+		{name: "stopped", container: &sandtypes.Container{Status: sandtypes.ContainerStatus{State: "stopped"}}, cgroup: "2147483648\n2147483648\n"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var execs []string
+			b := newTestBoxer(t, &hostops.MockContainerOps{
+				ExecFunc: func(ctx context.Context, opts *hostops.ExecContainer, containerID, cmd string, env []string, args ...string) (string, error) {
+					execs = append(execs, cmd)
+					if cmd == "sh" {
+						return tc.cgroup, tc.cgroupErr
+					}
+					return sampleProcStats, nil
+				},
+			}, &mockImageOps{})
+			box := &sandtypes.Box{ID: "warn-id", ContainerID: "warn-ctr", SandboxWorkDir: t.TempDir(), Container: tc.container, SandboxResourceWarning: "stale"}
+			if err := b.SyncBox(context.Background(), box); err != nil {
+				t.Fatalf("SyncBox() error = %v", err)
+			}
+			if box.SandboxResourceWarning != tc.want {
+				t.Fatalf("SandboxResourceWarning = %q, want %q (execs %v)", box.SandboxResourceWarning, tc.want, execs)
+			}
+		})
+	}
+}
+
+func TestSyncBoxResourceWarningFromProcMemInfo(t *testing.T) {
+	b := newTestBoxer(t, &hostops.MockContainerOps{
+		ExecFunc: func(ctx context.Context, opts *hostops.ExecContainer, containerID, cmd string, env []string, args ...string) (string, error) {
+			if cmd == "sh" {
+				return "734003200\nmax\n", nil
+			}
+			return "MemTotal: 1000000 kB\nMemAvailable: 50000 kB\ncpu 1 2 3 4 5 6 7 8\n", nil
+		},
+	}, &mockImageOps{})
+	box := &sandtypes.Box{ID: "vm-id", ContainerID: "vm-ctr", SandboxWorkDir: t.TempDir(),
+		Container: &sandtypes.Container{Status: sandtypes.ContainerStatus{State: "running"}}}
+	if err := b.SyncBox(context.Background(), box); err != nil {
+		t.Fatalf("SyncBox() error = %v", err)
+	}
+	if box.SandboxResourceWarning != "MEMORY 95% OF LIMIT" {
+		t.Fatalf("SandboxResourceWarning = %q, want MEMORY 95%% OF LIMIT", box.SandboxResourceWarning)
+	}
+}
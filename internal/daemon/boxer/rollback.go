@@ -0,0 +1,80 @@
+package boxer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+
+	"github.com/banksean/sand/internal/cloning"
+	"github.com/banksean/sand/internal/sandboxlog"
+	"github.com/banksean/sand/internal/sandtypes"
+)
+
+// discardPartialClone removes what workspace preparation may have left behind
+// for a sandbox whose creation failed: its clone directory, which also holds
+// its ssh keys, and the host git remote pointing into that directory. It is
+// best effort; failures are logged so the original error reaches the caller.
+func (sb *Boxer) discardPartialClone(ctx context.Context, id, name, hostWorkDir, sandboxRoot string) {
+	if hostWorkDir != "" {
+		if gitTopLevel := sb.GitOps.TopLevel(ctx, hostWorkDir); gitTopLevel != "" {
+			if name == "" {
+				name = id
+			}
+			remote := cloning.ClonedWorkDirGitRemotePrefix + name
+			// Only remove the remote if it points into this sandbox's clone, so a
+			// failed create can't take out another sandbox's remote.
+			if url := sb.GitOps.RemoteURL(ctx, gitTopLevel, remote); url != "" && pathWithin(url, sandboxRoot) {
+				if err := sb.GitOps.RemoveRemote(ctx, gitTopLevel, remote); err != nil {
+					slog.WarnContext(ctx, "Boxer.discardPartialClone RemoveRemote", "remote", remote, "error", err)
+				}
+			}
+		}
+	}
+	if err := sb.FileOps.RemoveAll(sandboxRoot); err != nil {
+		slog.WarnContext(ctx, "Boxer.discardPartialClone RemoveAll", "dir", sandboxRoot, "error", err)
+	}
+}
+
+// sandboxRoot is where the clone directory of sandbox id is created: under
+// cloneRoot if it is set, or the default clone root otherwise.
+func (sb *Boxer) sandboxRoot(cloneRoot, id string) string {
+	if cloneRoot == "" {
+		cloneRoot = filepath.Join(sb.appRoot, "clones")
+	}
+	return filepath.Join(cloneRoot, id)
+}
+
+func pathWithin(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, "../")
+}
+
+// Discard unwinds a sandbox whose creation failed after it was saved, e.g.
+// because its container could not be created. Unlike SoftDelete nothing is
+// kept in the trash: the container, host processes, clone directory, git
+// remote and database row are all removed so the ID can be used again.
+func (sb *Boxer) Discard(ctx context.Context, sbox *sandtypes.Box) error {
+	ctx = sandboxlog.WithSandboxID(ctx, sbox.ID)
+	defer sb.lockSandbox(sbox.ID)()
+	slog.InfoContext(ctx, "Boxer.Discard", "id", sbox.ID, "name", sbox.Name)
+
+	if sbox.ContainerID != "" {
+		if out, err := sb.ContainerService.Delete(ctx, nil, sbox.ContainerID); err != nil {
+			slog.ErrorContext(ctx, "Boxer.Discard Containers.Delete", "error", err, "out", out)
+		}
+	}
+	sb.killHostProcesses(ctx, sbox)
+	sandboxRoot := sbox.SandboxWorkDir
+	if sandboxRoot == "" {
+		sandboxRoot = sb.sandboxRoot("", sbox.ID)
+	}
+	sb.discardPartialClone(ctx, sbox.ID, sbox.Name, sbox.HostOriginDir, sandboxRoot)
+	if err := sb.queries.DeleteSandbox(ctx, sbox.ID); err != nil {
+		return fmt.Errorf("delete sandbox %s from database: %w", sbox.ID, err)
+	}
+	sb.forgetSandboxLock(sbox.ID)
+	sb.publish(ctx, sandtypes.SandboxRemoved, sbox, "")
+	return nil
+}
@@ -0,0 +1,56 @@
+package boxer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/banksean/sand/internal/hostops"
+	"github.com/banksean/sand/internal/sandtypes"
+)
+
+func TestBoxerDiscardRemovesEverything(t *testing.T) {
+	ctx := context.Background()
+	var deleted []string
+	b := newTestBoxer(t, &hostops.MockContainerOps{
+		DeleteFunc: func(ctx context.Context, opts *hostops.DeleteContainer, containerID string) (string, error) {
+			deleted = append(deleted, containerID)
+			return "", nil
+		},
+	}, &mockImageOps{})
+	b.FileOps = &hostops.MockFileOps{RemoveAllFunc: os.RemoveAll}
+	cloneDir := filepath.Join(b.appRoot, "clones", "gone-id")
+	if err := os.MkdirAll(filepath.Join(cloneDir, "app"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	sbox := &sandtypes.Box{ID: "gone-id", Name: "gone", ContainerID: "ctr-gone", SandboxWorkDir: cloneDir}
+	if err := b.SaveSandbox(ctx, sbox); err != nil {
+		t.Fatalf("SaveSandbox() error = %v", err)
+	}
+	events, cancel := b.Subscribe()
+	defer cancel()
+
+	if err := b.Discard(ctx, sbox); err != nil {
+		t.Fatalf("Discard() error = %v", err)
+	}
+	if len(deleted) != 1 || deleted[0] != "ctr-gone" {
+		t.Errorf("deleted containers = %v, want [ctr-gone]", deleted)
+	}
+	if _, err := os.Stat(cloneDir); !os.IsNotExist(err) {
+		t.Errorf("clone dir still exists after Discard: %v", err)
+	}
+	if loaded, err := b.Get(ctx, "gone-id"); err != nil || loaded != nil {
+		t.Errorf("Get() = %v, %v; want no sandbox", loaded, err)
+	}
+	if _, ok := b.sandboxLocks.Load("gone-id"); ok {
+		t.Error("Discard() left the sandbox's lock behind")
+	}
+	// Unlike SoftDelete, nothing is left in the trash to block the ID.
+	if err := b.SaveSandbox(ctx, &sandtypes.Box{ID: "gone-id", Name: "gone"}); err != nil {
+		t.Errorf("SaveSandbox() with the discarded ID error = %v", err)
+	}
+	if event := <-events; event.Type != sandtypes.SandboxRemoved {
+		t.Errorf("event = %+v, want removed", event)
+	}
+}
@@ -16,25 +16,59 @@ type Client interface {
 	Shutdown(ctx context.Context) error
 	LogSandbox(ctx context.Context, name string, w io.Writer) error
 	ListSandboxes(ctx context.Context) ([]sandtypes.Box, error)
+	// ListSandboxesDetailed syncs every active sandbox and returns them all in
+	// one call, each with its container status and any SandboxWorkDirError or
+	// SandboxContainerError.
+	ListSandboxesDetailed(ctx context.Context) ([]sandtypes.Box, error)
 	ListDeletedSandboxes(ctx context.Context) ([]sandtypes.Box, error)
 	GetSandbox(ctx context.Context, name string) (*sandtypes.Box, error)
 	RemoveSandbox(ctx context.Context, name string) error
 	ExpungeSandbox(ctx context.Context, id string) error
 	RecoverSandbox(ctx context.Context, id string) (*sandtypes.Box, error)
 	StopSandbox(ctx context.Context, name string) error
+	// KillSandbox sends signal (SIGKILL if empty) to the named sandbox's
+	// container instead of stopping it gracefully.
+	KillSandbox(ctx context.Context, name, signal string) error
 	StartSandbox(ctx context.Context, opts StartSandboxOpts) error
+	// MarkSandboxUsed records that the named sandbox was just shelled into or exec'd against.
+	MarkSandboxUsed(ctx context.Context, name string) error
+	// SyncSandboxes re-checks every active sandbox's clone directory and
+	// container and returns the sandboxes that are degraded, with
+	// SandboxWorkDirError or SandboxContainerError saying why.
+	SyncSandboxes(ctx context.Context) ([]sandtypes.Box, error)
 	SyncHostGitMirror(ctx context.Context, name string) (string, error)
+	// FetchHostChanges updates the host mirror that remote fetches from in the
+	// sandbox's container. An empty name selects the calling sandbox when
+	// invoked from inside a container.
+	FetchHostChanges(ctx context.Context, name, remote string) (string, error)
+	// ResyncWorkspace copies host workspace changes into the sandbox clone,
+	// skipping files that were also changed inside the sandbox.
+	ResyncWorkspace(ctx context.Context, name string) (*sandtypes.ResyncResult, error)
 	ResolveAgentLaunchEnv(ctx context.Context, opts ResolveAgentLaunchEnvOpts) (map[string]string, error)
 	ExportImage(ctx context.Context, name, imageName string) error
+	// PruneImages removes local images sand fetched that no sandbox uses and
+	// returns their names. With opts.DryRun it only returns the names.
+	PruneImages(ctx context.Context, opts PruneImagesOpts) ([]string, error)
 	Stats(ctx context.Context, name ...string) ([]sandtypes.ContainerStats, error)
 	VSC(ctx context.Context, name string) error
 	CreateSandbox(ctx context.Context, opts CreateSandboxOpts, w io.Writer) (*sandtypes.Box, error)
 	RenameSandbox(ctx context.Context, oldName, newName string) (*sandtypes.Box, error)
-	// EnsureImage ensures imageName is present locally and up to date, pulling if needed.
-	// Progress lines from the daemon are written to w as they arrive.
-	EnsureImage(ctx context.Context, imageName string, w io.Writer) error
+	// ForkSandbox creates sandbox name from a copy of the active sandbox
+	// sourceName. The new sandbox's container is left stopped.
+	ForkSandbox(ctx context.Context, sourceName, name string) (*sandtypes.Box, error)
+	// EnsureImage ensures opts.ImageName is present locally and up to date, pulling if
+	// needed. Progress lines from the daemon are written to w as they arrive.
+	EnsureImage(ctx context.Context, opts EnsureImageOpts, w io.Writer) error
 	HTTPProxyCache(ctx context.Context, action string) error
 	HTTPProxyCacheStatus(ctx context.Context) (HTTPProxyCacheStatus, error)
+	// Watch streams sandbox lifecycle events as they happen. The returned
+	// channel is closed when ctx is done or the daemon ends the stream.
+	Watch(ctx context.Context) (<-chan sandtypes.SandboxEvent, error)
+	// StartPortForward opens an ssh port forward to the sandbox that sandd keeps running.
+	StartPortForward(ctx context.Context, name string, fwd sandtypes.PortForward) (sandtypes.PortForward, error)
+	ListPortForwards(ctx context.Context, name string) ([]sandtypes.PortForward, error)
+	// StopPortForwards stops the forward matching fwd, or all of the sandbox's forwards if fwd is nil.
+	StopPortForwards(ctx context.Context, name string, fwd *sandtypes.PortForward) ([]sandtypes.PortForward, error)
 }
 
 type HTTPProxyCacheStatus struct {
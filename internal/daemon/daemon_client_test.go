@@ -3,19 +3,26 @@ package daemon
 import (
 	"bytes"
 	"context"
+	"maps"
 	"net"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/banksean/sand/internal/daemon/daemonpb"
+	"github.com/banksean/sand/internal/hostops"
 	"github.com/banksean/sand/internal/sandtypes"
 	"go.opentelemetry.io/otel"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func TestGRPCSB(t *testing.T) {
@@ -106,7 +113,7 @@ func TestGRPCSB(t *testing.T) {
 		defer client.Close()
 
 		var progress bytes.Buffer
-		if err := client.EnsureImage(context.Background(), "test-image:latest", &progress); err != nil {
+		if err := client.EnsureImage(context.Background(), EnsureImageOpts{ImageName: "test-image:latest"}, &progress); err != nil {
 			t.Fatalf("EnsureImage() error = %v", err)
 		}
 		if got := progress.String(); got != "Pulling tasks 1/2\nPulling tasks 2/2\n" {
@@ -161,6 +168,9 @@ func TestGRPCImage(t *testing.T) {
 				if req.GetImageName() != "test-image:latest" {
 					t.Fatalf("EnsureImage request image = %q, want test-image:latest", req.GetImageName())
 				}
+				if !req.GetPullAlways() {
+					t.Fatal("EnsureImage request pull_always = false, want true")
+				}
 				if err := stream.Send(&daemonpb.EnsureImageResponse{
 					Event: &daemonpb.EnsureImageResponse_Progress{Progress: []byte("pulling\r")},
 				}); err != nil {
@@ -185,7 +195,7 @@ func TestGRPCImage(t *testing.T) {
 		defer client.Close()
 
 		var progress bytes.Buffer
-		if err := client.EnsureImage(context.Background(), "test-image:latest", &progress); err != nil {
+		if err := client.EnsureImage(context.Background(), EnsureImageOpts{ImageName: "test-image:latest", PullAlways: true}, &progress); err != nil {
 			t.Fatalf("EnsureImage() error = %v", err)
 		}
 		if got := progress.String(); got != "pulling\rdone\n" {
@@ -216,7 +226,7 @@ func TestGRPCImage(t *testing.T) {
 		defer client.Close()
 
 		var progress bytes.Buffer
-		err = client.EnsureImage(context.Background(), "test-image:latest", &progress)
+		err = client.EnsureImage(context.Background(), EnsureImageOpts{ImageName: "test-image:latest"}, &progress)
 		if err == nil {
 			t.Fatal("EnsureImage() error = nil, want error")
 		}
@@ -267,7 +277,7 @@ func TestGRPCStreamingClientSpansEnd(t *testing.T) {
 	if _, err := client.CreateSandbox(context.Background(), CreateSandboxOpts{ID: "test-box"}, nil); err != nil {
 		t.Fatalf("CreateSandbox() error = %v", err)
 	}
-	if err := client.EnsureImage(context.Background(), "test-image:latest", nil); err != nil {
+	if err := client.EnsureImage(context.Background(), EnsureImageOpts{ImageName: "test-image:latest"}, nil); err != nil {
 		t.Fatalf("EnsureImage() error = %v", err)
 	}
 
@@ -275,6 +285,53 @@ func TestGRPCStreamingClientSpansEnd(t *testing.T) {
 	assertEndedSpan(t, spanRecorder, "sand.daemon.v1.DaemonService/EnsureImage")
 }
 
+func TestGRPCClientBoundsOnlyPingAndVersion(t *testing.T) {
+	appDir, err := os.MkdirTemp("", "t*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(appDir)
+
+	srv := startTestGRPCDaemon(t, appDir, &testGRPCDaemonService{
+		// A wedged daemon: the ping never answers.
+		PingFunc: func(ctx context.Context, req *daemonpb.PingRequest) (*daemonpb.PingResponse, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+		// A create that outlasts the ping deadline, as one pulling a large
+		// image does.
+		CreateSandboxFunc: func(req *daemonpb.CreateSandboxRequest, stream daemonpb.DaemonService_CreateSandboxServer) error {
+			time.Sleep(300 * time.Millisecond)
+			return stream.Send(&daemonpb.CreateSandboxResponse{
+				Event: &daemonpb.CreateSandboxResponse_Box{Box: sandboxToProto(&testSandboxBox)},
+			})
+		},
+	})
+	defer srv.Stop()
+
+	client, err := NewUnixSocketGRPCClient(context.Background(), appDir)
+	if err != nil {
+		t.Fatalf("NewUnixSocketGRPCClient() error = %v", err)
+	}
+	defer client.Close()
+	if client.pingTimeout != DefaultPingTimeout {
+		t.Fatalf("pingTimeout = %s, want %s", client.pingTimeout, DefaultPingTimeout)
+	}
+	client.pingTimeout = 50 * time.Millisecond
+
+	start := time.Now()
+	if err := client.Ping(context.Background()); status.Code(err) != codes.DeadlineExceeded {
+		t.Fatalf("Ping() error = %v, want DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("Ping() returned after %s, want it cut off at its deadline", elapsed)
+	}
+
+	if _, err := client.CreateSandbox(context.Background(), CreateSandboxOpts{ID: "test-box"}, nil); err != nil {
+		t.Fatalf("CreateSandbox() error = %v, want it to run past the ping deadline", err)
+	}
+}
+
 func TestCreateSandboxOptsProtoRoundTrip(t *testing.T) {
 	opts := CreateSandboxOpts{
 		Name:           "test-box",
@@ -287,11 +344,17 @@ func TestCreateSandboxOptsProtoRoundTrip(t *testing.T) {
 		Username:       "dev",
 		Uid:            "501",
 		AllowedDomains: []string{"example.com", "api.example.com"},
+		Labels:         map[string]string{"project": "sand", "branch": "main"},
+		Shell:          "/bin/bash",
 		Mounts:         []string{"source=/host,target=/container,readonly"},
 		CloneMounts:    []string{"source=/src/data,target=/data,readonly"},
 		SharedCaches:   sandtypes.SharedCacheConfig{Mise: true, APK: true, Agents: true, Bazel: true, HTTPProxy: true},
 		CPUs:           4,
 		Memory:         8192,
+		MessageLevel:   hostops.MessageDebug,
+		AllowEmulation: true,
+		Publish:        []string{"8080:80", "8443:443"},
+		DNS:            sandtypes.DNSConfig{Nameservers: []string{"10.0.0.53"}, SearchDomains: []string{"corp.example.com"}, Options: []string{"ndots:2"}},
 	}
 
 	got := createSandboxOptsFromProto(createSandboxOptsToProto(opts))
@@ -306,9 +369,17 @@ func TestCreateSandboxOptsProtoRoundTrip(t *testing.T) {
 		got.Uid != opts.Uid ||
 		got.SharedCaches != opts.SharedCaches ||
 		got.CPUs != opts.CPUs ||
-		got.Memory != opts.Memory {
+		got.Memory != opts.Memory ||
+		got.MessageLevel != opts.MessageLevel ||
+		got.AllowEmulation != opts.AllowEmulation {
 		t.Fatalf("round trip opts = %+v, want %+v", got, opts)
 	}
+	if strings.Join(got.Publish, ",") != strings.Join(opts.Publish, ",") {
+		t.Fatalf("round trip publish = %+v, want %+v", got.Publish, opts.Publish)
+	}
+	if !reflect.DeepEqual(got.DNS, opts.DNS) {
+		t.Fatalf("round trip dns = %+v, want %+v", got.DNS, opts.DNS)
+	}
 	if strings.Join(got.AllowedDomains, ",") != strings.Join(opts.AllowedDomains, ",") {
 		t.Fatalf("round trip allowed domains = %+v, want %+v", got.AllowedDomains, opts.AllowedDomains)
 	}
@@ -318,6 +389,12 @@ func TestCreateSandboxOptsProtoRoundTrip(t *testing.T) {
 	if strings.Join(got.CloneMounts, ",") != strings.Join(opts.CloneMounts, ",") {
 		t.Fatalf("round trip clone mounts = %+v, want %+v", got.CloneMounts, opts.CloneMounts)
 	}
+	if !maps.Equal(got.Labels, opts.Labels) {
+		t.Fatalf("round trip labels = %v, want %v", got.Labels, opts.Labels)
+	}
+	if got.Shell != opts.Shell {
+		t.Fatalf("round trip shell = %q, want %q", got.Shell, opts.Shell)
+	}
 }
 
 func TestSharedCacheMountsProtoRoundTripIncludesHTTPProxyURL(t *testing.T) {
@@ -336,6 +413,28 @@ func TestSharedCacheMountsProtoRoundTripIncludesHTTPProxyURL(t *testing.T) {
 	}
 }
 
+func TestSandboxProtoRoundTripIncludesTimestamps(t *testing.T) {
+	created := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	lastUsed := created.Add(90 * time.Minute)
+
+	got := sandboxFromProto(sandboxToProto(&sandtypes.Box{
+		ID:         "box-id",
+		CreatedAt:  created,
+		LastUsedAt: lastUsed,
+	}))
+	if !got.CreatedAt.Equal(created) {
+		t.Fatalf("round trip CreatedAt = %v, want %v", got.CreatedAt, created)
+	}
+	if !got.LastUsedAt.Equal(lastUsed) {
+		t.Fatalf("round trip LastUsedAt = %v, want %v", got.LastUsedAt, lastUsed)
+	}
+
+	got = sandboxFromProto(sandboxToProto(&sandtypes.Box{ID: "never-used"}))
+	if !got.LastUsedAt.IsZero() {
+		t.Fatalf("round trip zero LastUsedAt = %v, want zero", got.LastUsedAt)
+	}
+}
+
 func assertEndedSpan(t *testing.T, spanRecorder *tracetest.SpanRecorder, name string) {
 	t.Helper()
 	for _, span := range spanRecorder.Ended() {
@@ -369,6 +468,12 @@ func TestGRPCUnary(t *testing.T) {
 		ListDeletedSandboxesFunc: func(ctx context.Context, req *daemonpb.ListSandboxesRequest) (*daemonpb.ListSandboxesResponse, error) {
 			return &daemonpb.ListSandboxesResponse{Boxes: sandboxesToProto([]sandtypes.Box{{ID: "deleted-box", State: "deleted"}})}, nil
 		},
+		SyncSandboxesFunc: func(ctx context.Context, req *daemonpb.ListSandboxesRequest) (*daemonpb.ListSandboxesResponse, error) {
+			return &daemonpb.ListSandboxesResponse{Boxes: sandboxesToProto([]sandtypes.Box{{ID: "degraded-box", SandboxWorkDirError: "NO CLONE DIR"}})}, nil
+		},
+		ListSandboxesDetailedFunc: func(ctx context.Context, req *daemonpb.ListSandboxesRequest) (*daemonpb.ListSandboxesResponse, error) {
+			return &daemonpb.ListSandboxesResponse{Boxes: sandboxesToProto([]sandtypes.Box{testSandboxBox, {ID: "degraded-box", SandboxWorkDirError: "NO CLONE DIR"}})}, nil
+		},
 		GetSandboxFunc: func(ctx context.Context, req *daemonpb.IDRequest) (*daemonpb.GetSandboxResponse, error) {
 			if req.GetId() != "test-box" {
 				t.Fatalf("GetSandbox request ID = %q, want test-box", req.GetId())
@@ -426,12 +531,30 @@ func TestGRPCUnary(t *testing.T) {
 			}
 			return &daemonpb.RecoverSandboxResponse{Box: sandboxToProto(&sandtypes.Box{ID: "deleted-box", Name: "recovered-box"})}, nil
 		},
+		ForkSandboxFunc: func(ctx context.Context, req *daemonpb.ForkSandboxRequest) (*daemonpb.ForkSandboxResponse, error) {
+			if req.GetSourceName() != "test-box" || req.GetName() != "forked-box" {
+				t.Fatalf("ForkSandbox request = %+v, want test-box -> forked-box", req)
+			}
+			return &daemonpb.ForkSandboxResponse{Box: sandboxToProto(&sandtypes.Box{ID: "fork-id", Name: "forked-box"})}, nil
+		},
 		StopSandboxFunc: func(ctx context.Context, req *daemonpb.IDRequest) (*daemonpb.StatusResponse, error) {
 			if req.GetId() != "test-box" {
 				t.Fatalf("StopSandbox request ID = %q, want test-box", req.GetId())
 			}
 			return &daemonpb.StatusResponse{Status: "ok"}, nil
 		},
+		KillSandboxFunc: func(ctx context.Context, req *daemonpb.KillSandboxRequest) (*daemonpb.StatusResponse, error) {
+			if req.GetId() != "test-box" || req.GetSignal() != "TERM" {
+				t.Fatalf("KillSandbox request = %+v, want test-box/TERM", req)
+			}
+			return &daemonpb.StatusResponse{Status: "ok"}, nil
+		},
+		PruneImagesFunc: func(ctx context.Context, req *daemonpb.PruneImagesRequest) (*daemonpb.PruneImagesResponse, error) {
+			if !req.GetDryRun() || !slices.Equal(req.GetKeep(), []string{"keep:latest"}) {
+				t.Fatalf("PruneImages request = %+v, want a dry run keeping keep:latest", req)
+			}
+			return &daemonpb.PruneImagesResponse{Images: []string{"old:latest"}}, nil
+		},
 		ExportImageFunc: func(ctx context.Context, req *daemonpb.ExportImageRequest) (*daemonpb.StatusResponse, error) {
 			if req.GetId() != "test-box" {
 				t.Fatalf("ExportImage request ID = %q, want test-box", req.GetId())
@@ -472,6 +595,9 @@ func TestGRPCUnary(t *testing.T) {
 	if len(boxes) != 1 || boxes[0].ID != "test-box" {
 		t.Fatalf("ListSandboxes() = %+v, want test-box", boxes)
 	}
+	if !maps.Equal(boxes[0].Labels, testSandboxBox.Labels) {
+		t.Fatalf("ListSandboxes() labels = %v, want %v", boxes[0].Labels, testSandboxBox.Labels)
+	}
 
 	deletedBoxes, err := client.ListDeletedSandboxes(context.Background())
 	if err != nil {
@@ -481,6 +607,22 @@ func TestGRPCUnary(t *testing.T) {
 		t.Fatalf("ListDeletedSandboxes() = %+v, want deleted-box", deletedBoxes)
 	}
 
+	degraded, err := client.SyncSandboxes(context.Background())
+	if err != nil {
+		t.Fatalf("SyncSandboxes() error = %v", err)
+	}
+	if len(degraded) != 1 || degraded[0].ID != "degraded-box" || degraded[0].SandboxWorkDirError != "NO CLONE DIR" {
+		t.Fatalf("SyncSandboxes() = %+v, want degraded-box with its clone error", degraded)
+	}
+
+	detailed, err := client.ListSandboxesDetailed(context.Background())
+	if err != nil {
+		t.Fatalf("ListSandboxesDetailed() error = %v", err)
+	}
+	if len(detailed) != 2 || detailed[0].ID != "test-box" || detailed[1].SandboxWorkDirError != "NO CLONE DIR" {
+		t.Fatalf("ListSandboxesDetailed() = %+v, want test-box and degraded-box with its clone error", detailed)
+	}
+
 	box, err := client.GetSandbox(context.Background(), "test-box")
 	if err != nil {
 		t.Fatalf("GetSandbox() error = %v", err)
@@ -488,6 +630,15 @@ func TestGRPCUnary(t *testing.T) {
 	if box.ID != "test-box" {
 		t.Fatalf("GetSandbox() ID = %q, want test-box", box.ID)
 	}
+	if !maps.Equal(box.Labels, testSandboxBox.Labels) {
+		t.Fatalf("GetSandbox() labels = %v, want %v", box.Labels, testSandboxBox.Labels)
+	}
+	if box.ImageDigest != testSandboxBox.ImageDigest {
+		t.Fatalf("GetSandbox() image digest = %q, want %q", box.ImageDigest, testSandboxBox.ImageDigest)
+	}
+	if box.Shell != testSandboxBox.Shell {
+		t.Fatalf("GetSandbox() shell = %q, want %q", box.Shell, testSandboxBox.Shell)
+	}
 
 	if err := client.StartSandbox(context.Background(), StartSandboxOpts{ID: "test-box", SSHAgent: true}); err != nil {
 		t.Fatalf("StartSandbox() error = %v", err)
@@ -527,12 +678,29 @@ func TestGRPCUnary(t *testing.T) {
 	if recovered.ID != "deleted-box" || recovered.Name != "recovered-box" {
 		t.Fatalf("RecoverSandbox() = %+v", recovered)
 	}
+	forked, err := client.ForkSandbox(context.Background(), "test-box", "forked-box")
+	if err != nil {
+		t.Fatalf("ForkSandbox() error = %v", err)
+	}
+	if forked.ID != "fork-id" || forked.Name != "forked-box" {
+		t.Fatalf("ForkSandbox() = %+v", forked)
+	}
 	if err := client.StopSandbox(context.Background(), "test-box"); err != nil {
 		t.Fatalf("StopSandbox() error = %v", err)
 	}
+	if err := client.KillSandbox(context.Background(), "test-box", "TERM"); err != nil {
+		t.Fatalf("KillSandbox() error = %v", err)
+	}
 	if err := client.ExportImage(context.Background(), "test-box", "archive.tar"); err != nil {
 		t.Fatalf("ExportImage() error = %v", err)
 	}
+	pruned, err := client.PruneImages(context.Background(), PruneImagesOpts{DryRun: true, Keep: []string{"keep:latest"}})
+	if err != nil {
+		t.Fatalf("PruneImages() error = %v", err)
+	}
+	if !slices.Equal(pruned, []string{"old:latest"}) {
+		t.Fatalf("PruneImages() = %v, want [old:latest]", pruned)
+	}
 	if err := client.VSC(context.Background(), "test-box"); err != nil {
 		t.Fatalf("VSC() error = %v", err)
 	}
@@ -545,27 +713,40 @@ func sandBox() sandtypes.Box {
 		ID:          "test-box",
 		ContainerID: "ctr-test-box",
 		ImageName:   "test-image:latest",
+		Labels:      map[string]string{"project": "sand", "agent": "codex"},
+		Shell:       "/bin/bash",
+		ImageDigest: "sha256:test",
 	}
 }
 
 type testGRPCDaemonService struct {
 	daemonpb.UnimplementedDaemonServiceServer
+	KillSandboxFunc           func(context.Context, *daemonpb.KillSandboxRequest) (*daemonpb.StatusResponse, error)
 	LogSandboxFunc            func(context.Context, *daemonpb.IDRequest) (*daemonpb.LogSandboxResponse, error)
 	ListSandboxesFunc         func(context.Context, *daemonpb.ListSandboxesRequest) (*daemonpb.ListSandboxesResponse, error)
 	ListDeletedSandboxesFunc  func(context.Context, *daemonpb.ListSandboxesRequest) (*daemonpb.ListSandboxesResponse, error)
+	SyncSandboxesFunc         func(context.Context, *daemonpb.ListSandboxesRequest) (*daemonpb.ListSandboxesResponse, error)
+	ListSandboxesDetailedFunc func(context.Context, *daemonpb.ListSandboxesRequest) (*daemonpb.ListSandboxesResponse, error)
 	GetSandboxFunc            func(context.Context, *daemonpb.IDRequest) (*daemonpb.GetSandboxResponse, error)
 	RemoveSandboxFunc         func(context.Context, *daemonpb.IDRequest) (*daemonpb.StatusResponse, error)
 	ExpungeSandboxFunc        func(context.Context, *daemonpb.IDRequest) (*daemonpb.StatusResponse, error)
 	RecoverSandboxFunc        func(context.Context, *daemonpb.IDRequest) (*daemonpb.RecoverSandboxResponse, error)
+	ForkSandboxFunc           func(context.Context, *daemonpb.ForkSandboxRequest) (*daemonpb.ForkSandboxResponse, error)
 	StopSandboxFunc           func(context.Context, *daemonpb.IDRequest) (*daemonpb.StatusResponse, error)
 	StartSandboxFunc          func(context.Context, *daemonpb.StartSandboxRequest) (*daemonpb.StatusResponse, error)
 	SyncHostGitMirrorFunc     func(context.Context, *daemonpb.IDRequest) (*daemonpb.SyncHostGitMirrorResponse, error)
 	ResolveAgentLaunchEnvFunc func(context.Context, *daemonpb.ResolveAgentLaunchEnvRequest) (*daemonpb.ResolveAgentLaunchEnvResponse, error)
 	ExportImageFunc           func(context.Context, *daemonpb.ExportImageRequest) (*daemonpb.StatusResponse, error)
+	PruneImagesFunc           func(context.Context, *daemonpb.PruneImagesRequest) (*daemonpb.PruneImagesResponse, error)
 	StatsFunc                 func(context.Context, *daemonpb.StatsRequest) (*daemonpb.StatsResponse, error)
 	VSCFunc                   func(context.Context, *daemonpb.IDRequest) (*daemonpb.StatusResponse, error)
 	CreateSandboxFunc         func(*daemonpb.CreateSandboxRequest, daemonpb.DaemonService_CreateSandboxServer) error
 	EnsureImageFunc           func(*daemonpb.EnsureImageRequest, daemonpb.DaemonService_EnsureImageServer) error
+	PingFunc                  func(context.Context, *daemonpb.PingRequest) (*daemonpb.PingResponse, error)
+}
+
+func (s *testGRPCDaemonService) Ping(ctx context.Context, req *daemonpb.PingRequest) (*daemonpb.PingResponse, error) {
+	return s.PingFunc(ctx, req)
 }
 
 func (s *testGRPCDaemonService) LogSandbox(ctx context.Context, req *daemonpb.IDRequest) (*daemonpb.LogSandboxResponse, error) {
@@ -580,6 +761,14 @@ func (s *testGRPCDaemonService) ListDeletedSandboxes(ctx context.Context, req *d
 	return s.ListDeletedSandboxesFunc(ctx, req)
 }
 
+func (s *testGRPCDaemonService) SyncSandboxes(ctx context.Context, req *daemonpb.ListSandboxesRequest) (*daemonpb.ListSandboxesResponse, error) {
+	return s.SyncSandboxesFunc(ctx, req)
+}
+
+func (s *testGRPCDaemonService) ListSandboxesDetailed(ctx context.Context, req *daemonpb.ListSandboxesRequest) (*daemonpb.ListSandboxesResponse, error) {
+	return s.ListSandboxesDetailedFunc(ctx, req)
+}
+
 func (s *testGRPCDaemonService) GetSandbox(ctx context.Context, req *daemonpb.IDRequest) (*daemonpb.GetSandboxResponse, error) {
 	return s.GetSandboxFunc(ctx, req)
 }
@@ -596,10 +785,18 @@ func (s *testGRPCDaemonService) RecoverSandbox(ctx context.Context, req *daemonp
 	return s.RecoverSandboxFunc(ctx, req)
 }
 
+func (s *testGRPCDaemonService) ForkSandbox(ctx context.Context, req *daemonpb.ForkSandboxRequest) (*daemonpb.ForkSandboxResponse, error) {
+	return s.ForkSandboxFunc(ctx, req)
+}
+
 func (s *testGRPCDaemonService) StopSandbox(ctx context.Context, req *daemonpb.IDRequest) (*daemonpb.StatusResponse, error) {
 	return s.StopSandboxFunc(ctx, req)
 }
 
+func (s *testGRPCDaemonService) KillSandbox(ctx context.Context, req *daemonpb.KillSandboxRequest) (*daemonpb.StatusResponse, error) {
+	return s.KillSandboxFunc(ctx, req)
+}
+
 func (s *testGRPCDaemonService) StartSandbox(ctx context.Context, req *daemonpb.StartSandboxRequest) (*daemonpb.StatusResponse, error) {
 	return s.StartSandboxFunc(ctx, req)
 }
@@ -616,6 +813,10 @@ func (s *testGRPCDaemonService) ExportImage(ctx context.Context, req *daemonpb.E
 	return s.ExportImageFunc(ctx, req)
 }
 
+func (s *testGRPCDaemonService) PruneImages(ctx context.Context, req *daemonpb.PruneImagesRequest) (*daemonpb.PruneImagesResponse, error) {
+	return s.PruneImagesFunc(ctx, req)
+}
+
 func (s *testGRPCDaemonService) Stats(ctx context.Context, req *daemonpb.StatsRequest) (*daemonpb.StatsResponse, error) {
 	return s.StatsFunc(ctx, req)
 }
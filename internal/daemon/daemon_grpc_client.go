@@ -9,6 +9,7 @@ import (
 	"net"
 	"path/filepath"
 	"runtime/debug"
+	"time"
 
 	"github.com/banksean/sand/internal/daemon/daemonpb"
 	"github.com/banksean/sand/internal/imageprogress"
@@ -19,10 +20,20 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 )
 
-// GRPCClient communicates with sandd over the daemon gRPC Unix socket.
+// DefaultPingTimeout bounds the calls that only check on the daemon, Ping
+// and Version, so a wedged sandd is reported rather than waited on forever.
+// Every other call, such as a create that pulls an image, runs as long as its
+// context allows.
+const DefaultPingTimeout = 5 * time.Second
+
+// GRPCClient communicates with sandd over the daemon gRPC Unix socket. One
+// client, and its connection, serves every call a sand command makes.
 type GRPCClient struct {
 	conn   *grpc.ClientConn
 	client daemonpb.DaemonServiceClient
+	// pingTimeout is the deadline for Ping and Version; zero leaves them to
+	// the caller's context.
+	pingTimeout time.Duration
 }
 
 func NewUnixSocketGRPCClient(ctx context.Context, appBaseDir string) (*GRPCClient, error) {
@@ -41,21 +52,34 @@ func NewUnixSocketGRPCClient(ctx context.Context, appBaseDir string) (*GRPCClien
 		return nil, err
 	}
 	return &GRPCClient{
-		conn:   conn,
-		client: daemonpb.NewDaemonServiceClient(conn),
+		conn:        conn,
+		client:      daemonpb.NewDaemonServiceClient(conn),
+		pingTimeout: DefaultPingTimeout,
 	}, nil
 }
 
+// withPingTimeout bounds ctx by c.pingTimeout, unless ctx already ends sooner.
+func (c *GRPCClient) withPingTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.pingTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, c.pingTimeout)
+}
+
 func (c *GRPCClient) Close() error {
 	return c.conn.Close()
 }
 
 func (c *GRPCClient) Ping(ctx context.Context) error {
+	ctx, cancel := c.withPingTimeout(ctx)
+	defer cancel()
 	_, err := c.client.Ping(ctx, &daemonpb.PingRequest{})
 	return err
 }
 
 func (c *GRPCClient) Version(ctx context.Context) (version.Info, error) {
+	ctx, cancel := c.withPingTimeout(ctx)
+	defer cancel()
 	resp, err := c.client.Version(ctx, &daemonpb.VersionRequest{})
 	if err != nil {
 		return version.Info{}, err
@@ -102,6 +126,22 @@ func (c *GRPCClient) ListSandboxes(ctx context.Context) ([]sandtypes.Box, error)
 	return sandboxesFromProto(resp.GetBoxes()), nil
 }
 
+func (c *GRPCClient) ListSandboxesDetailed(ctx context.Context) ([]sandtypes.Box, error) {
+	resp, err := c.client.ListSandboxesDetailed(ctx, &daemonpb.ListSandboxesRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return sandboxesFromProto(resp.GetBoxes()), nil
+}
+
+func (c *GRPCClient) SyncSandboxes(ctx context.Context) ([]sandtypes.Box, error) {
+	resp, err := c.client.SyncSandboxes(ctx, &daemonpb.ListSandboxesRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return sandboxesFromProto(resp.GetBoxes()), nil
+}
+
 func (c *GRPCClient) ListDeletedSandboxes(ctx context.Context) ([]sandtypes.Box, error) {
 	resp, err := c.client.ListDeletedSandboxes(ctx, &daemonpb.ListSandboxesRequest{})
 	if err != nil {
@@ -133,6 +173,16 @@ func (c *GRPCClient) StopSandbox(ctx context.Context, name string) error {
 	return err
 }
 
+func (c *GRPCClient) KillSandbox(ctx context.Context, name, signal string) error {
+	_, err := c.client.KillSandbox(ctx, &daemonpb.KillSandboxRequest{Id: name, Signal: signal})
+	return err
+}
+
+func (c *GRPCClient) MarkSandboxUsed(ctx context.Context, name string) error {
+	_, err := c.client.MarkSandboxUsed(ctx, &daemonpb.IDRequest{Id: name})
+	return err
+}
+
 func (c *GRPCClient) StartSandbox(ctx context.Context, opts StartSandboxOpts) error {
 	name := opts.Name
 	if name == "" {
@@ -141,6 +191,7 @@ func (c *GRPCClient) StartSandbox(ctx context.Context, opts StartSandboxOpts) er
 	_, err := c.client.StartSandbox(ctx, &daemonpb.StartSandboxRequest{
 		Id:       name,
 		SshAgent: opts.SSHAgent,
+		Image:    opts.Image,
 	})
 	return err
 }
@@ -153,6 +204,22 @@ func (c *GRPCClient) SyncHostGitMirror(ctx context.Context, name string) (string
 	return resp.GetMirrorPath(), nil
 }
 
+func (c *GRPCClient) FetchHostChanges(ctx context.Context, name, remote string) (string, error) {
+	resp, err := c.client.FetchHostChanges(ctx, &daemonpb.FetchHostChangesRequest{Id: name, Remote: remote})
+	if err != nil {
+		return "", err
+	}
+	return resp.GetMirrorPath(), nil
+}
+
+func (c *GRPCClient) ResyncWorkspace(ctx context.Context, name string) (*sandtypes.ResyncResult, error) {
+	resp, err := c.client.ResyncWorkspace(ctx, &daemonpb.IDRequest{Id: name})
+	if err != nil {
+		return nil, err
+	}
+	return &sandtypes.ResyncResult{Copied: resp.GetCopied(), Conflicts: resp.GetConflicts()}, nil
+}
+
 func (c *GRPCClient) ResolveAgentLaunchEnv(ctx context.Context, opts ResolveAgentLaunchEnvOpts) (map[string]string, error) {
 	resp, err := c.client.ResolveAgentLaunchEnv(ctx, &daemonpb.ResolveAgentLaunchEnvRequest{
 		Agent:                opts.Agent,
@@ -201,6 +268,17 @@ func (c *GRPCClient) ExportImage(ctx context.Context, name, destinationPath stri
 	return err
 }
 
+func (c *GRPCClient) PruneImages(ctx context.Context, opts PruneImagesOpts) ([]string, error) {
+	resp, err := c.client.PruneImages(ctx, &daemonpb.PruneImagesRequest{
+		DryRun: opts.DryRun,
+		Keep:   opts.Keep,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.GetImages(), nil
+}
+
 func (c *GRPCClient) Stats(ctx context.Context, names ...string) ([]sandtypes.ContainerStats, error) {
 	resp, err := c.client.Stats(ctx, &daemonpb.StatsRequest{Ids: names})
 	if err != nil {
@@ -246,6 +324,17 @@ func (c *GRPCClient) RenameSandbox(ctx context.Context, oldName, newName string)
 	return box, nil
 }
 
+func (c *GRPCClient) ForkSandbox(ctx context.Context, sourceName, name string) (*sandtypes.Box, error) {
+	resp, err := c.client.ForkSandbox(ctx, &daemonpb.ForkSandboxRequest{
+		SourceName: sourceName,
+		Name:       name,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sandboxFromProto(resp.GetBox()), nil
+}
+
 func (c *GRPCClient) RecoverSandbox(ctx context.Context, id string) (*sandtypes.Box, error) {
 	resp, err := c.client.RecoverSandbox(ctx, &daemonpb.IDRequest{Id: id})
 	if err != nil {
@@ -296,8 +385,13 @@ func (c *GRPCClient) CreateSandbox(ctx context.Context, opts CreateSandboxOpts,
 	}
 }
 
-func (c *GRPCClient) EnsureImage(ctx context.Context, imageName string, w io.Writer) error {
-	stream, err := c.client.EnsureImage(ctx, &daemonpb.EnsureImageRequest{ImageName: imageName})
+func (c *GRPCClient) EnsureImage(ctx context.Context, opts EnsureImageOpts, w io.Writer) error {
+	stream, err := c.client.EnsureImage(ctx, &daemonpb.EnsureImageRequest{
+		ImageName:     opts.ImageName,
+		PullAlways:    opts.PullAlways,
+		DockerfileDir: opts.DockerfileDir,
+		BuildArgs:     opts.BuildArgs,
+	})
 	if err != nil {
 		return err
 	}
@@ -338,6 +432,63 @@ func (c *GRPCClient) EnsureImage(ctx context.Context, imageName string, w io.Wri
 	}
 }
 
+func (c *GRPCClient) Watch(ctx context.Context) (<-chan sandtypes.SandboxEvent, error) {
+	stream, err := c.client.WatchEvents(ctx, &daemonpb.WatchEventsRequest{})
+	if err != nil {
+		return nil, err
+	}
+	// The daemon sends headers once it has subscribed, so events that happen
+	// after Watch returns are never missed. Waiting for them also reports a
+	// daemon that lacks WatchEvents here rather than as a closed channel.
+	if _, err := stream.Header(); err != nil {
+		return nil, err
+	}
+	events := make(chan sandtypes.SandboxEvent)
+	go func() {
+		defer close(events)
+		for {
+			event, err := stream.Recv()
+			if err != nil {
+				return
+			}
+			select {
+			case events <- sandboxEventFromProto(event):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, nil
+}
+
+func (c *GRPCClient) StartPortForward(ctx context.Context, name string, fwd sandtypes.PortForward) (sandtypes.PortForward, error) {
+	resp, err := c.client.StartPortForward(ctx, &daemonpb.PortForwardRequest{Id: name, Forward: portForwardToProto(fwd)})
+	if err != nil {
+		return sandtypes.PortForward{}, err
+	}
+	return portForwardFromProto(resp.GetForward()), nil
+}
+
+func (c *GRPCClient) ListPortForwards(ctx context.Context, name string) ([]sandtypes.PortForward, error) {
+	resp, err := c.client.ListPortForwards(ctx, &daemonpb.IDRequest{Id: name})
+	if err != nil {
+		return nil, err
+	}
+	return portForwardsFromProto(resp), nil
+}
+
+func (c *GRPCClient) StopPortForwards(ctx context.Context, name string, fwd *sandtypes.PortForward) ([]sandtypes.PortForward, error) {
+	req := &daemonpb.PortForwardRequest{Id: name}
+	if fwd != nil {
+		req.Forward = portForwardToProto(*fwd)
+	}
+	resp, err := c.client.StopPortForwards(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return portForwardsFromProto(resp), nil
+}
+
 func imageProgressUpdateFromProto(update *daemonpb.ImagePullProgressUpdate) imageprogress.Update {
 	if update == nil {
 		return imageprogress.Update{}
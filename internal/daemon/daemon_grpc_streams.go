@@ -1,12 +1,17 @@
 package daemon
 
 import (
+	"errors"
 	"log/slog"
+	"maps"
 	"sync"
 
+	"github.com/banksean/sand/internal/daemon/boxer"
 	"github.com/banksean/sand/internal/daemon/daemonpb"
+	"github.com/banksean/sand/internal/hostops"
 	"github.com/banksean/sand/internal/imageprogress"
 	"github.com/banksean/sand/internal/sandtypes"
+	"google.golang.org/grpc/metadata"
 )
 
 type grpcCreateSandboxProgressWriter struct {
@@ -105,10 +110,21 @@ func (s *daemonGRPCServer) CreateSandbox(req *daemonpb.CreateSandboxRequest, str
 	})
 }
 
+// errBuildFromSandbox rejects image builds requested on a sandbox's own
+// socket: a build reads a host directory into the image.
+var errBuildFromSandbox = errors.New("images can only be built from the host")
+
 func (s *daemonGRPCServer) EnsureImage(req *daemonpb.EnsureImageRequest, stream daemonpb.DaemonService_EnsureImageServer) error {
+	if s.sandboxID != "" && req.GetDockerfileDir() != "" {
+		return errBuildFromSandbox
+	}
 	ctx := stream.Context()
 	writer := &grpcEnsureImageProgressWriter{stream: stream}
-	if err := s.daemon.boxer.EnsureImage(ctx, req.GetImageName(), writer); err != nil {
+	if err := s.daemon.boxer.EnsureImage(ctx, req.GetImageName(), boxer.EnsureImageOpts{
+		PullAlways:    req.GetPullAlways(),
+		DockerfileDir: req.GetDockerfileDir(),
+		BuildArgs:     req.GetBuildArgs(),
+	}, writer); err != nil {
 		return stream.Send(&daemonpb.EnsureImageResponse{
 			Event: &daemonpb.EnsureImageResponse_Error{Error: err.Error()},
 		})
@@ -121,24 +137,61 @@ func (s *daemonGRPCServer) EnsureImage(req *daemonpb.EnsureImageRequest, stream
 	})
 }
 
+func (s *daemonGRPCServer) WatchEvents(_ *daemonpb.WatchEventsRequest, stream daemonpb.DaemonService_WatchEventsServer) error {
+	ctx := stream.Context()
+	events, cancel := s.daemon.boxer.Subscribe()
+	defer cancel()
+	// Tell the client we're subscribed; see GRPCClient.Watch.
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "Daemon.gRPC WatchEvents subscribed")
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.daemon.shuttingDown:
+			// Watchers would otherwise hold up a graceful shutdown for the
+			// whole grace period.
+			return nil
+		case event := <-events:
+			if err := stream.Send(sandboxEventToProto(event)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
 func createSandboxOptsToProto(opts CreateSandboxOpts) *daemonpb.CreateSandboxRequest {
 	name := opts.Name
 	if name == "" {
 		name = opts.ID
 	}
 	return &daemonpb.CreateSandboxRequest{
-		Id:             name,
-		CloneFromDir:   opts.CloneFromDir,
-		ProfileName:    opts.ProfileName,
-		ImageName:      opts.ImageName,
-		EnvFile:        opts.EnvFile,
-		Agent:          opts.Agent,
-		SshAgent:       opts.SSHAgent,
-		Username:       opts.Username,
-		Uid:            opts.Uid,
-		AllowedDomains: append([]string(nil), opts.AllowedDomains...),
-		Mounts:         append([]string(nil), opts.Mounts...),
-		CloneMounts:    append([]string(nil), opts.CloneMounts...),
+		Id:                   name,
+		CloneFromDir:         opts.CloneFromDir,
+		ProfileName:          opts.ProfileName,
+		ImageName:            opts.ImageName,
+		EnvFile:              opts.EnvFile,
+		Agent:                opts.Agent,
+		SshAgent:             opts.SSHAgent,
+		Username:             opts.Username,
+		Uid:                  opts.Uid,
+		AllowedDomains:       append([]string(nil), opts.AllowedDomains...),
+		Labels:               maps.Clone(opts.Labels),
+		Shell:                opts.Shell,
+		Network:              opts.Network,
+		CloneRoot:            opts.CloneRoot,
+		FromBranch:           opts.FromBranch,
+		NoDotfiles:           opts.NoDotfiles,
+		NoSsh:                opts.NoSSH,
+		AllowEmulation:       opts.AllowEmulation,
+		IgnoreOncreateErrors: opts.IgnoreOnCreateErrors,
+		Publish:              opts.Publish,
+		Dns:                  dnsConfigToProto(opts.DNS),
+		MessageLevel:         int32(opts.MessageLevel),
+		Mounts:               append([]string(nil), opts.Mounts...),
+		CloneMounts:          append([]string(nil), opts.CloneMounts...),
 		SharedCaches: &daemonpb.SharedCacheConfig{
 			Mise:      opts.SharedCaches.Mise,
 			Apk:       opts.SharedCaches.APK,
@@ -153,20 +206,32 @@ func createSandboxOptsToProto(opts CreateSandboxOpts) *daemonpb.CreateSandboxReq
 
 func createSandboxOptsFromProto(req *daemonpb.CreateSandboxRequest) CreateSandboxOpts {
 	opts := CreateSandboxOpts{
-		Name:           req.GetId(),
-		CloneFromDir:   req.GetCloneFromDir(),
-		ProfileName:    req.GetProfileName(),
-		ImageName:      req.GetImageName(),
-		EnvFile:        req.GetEnvFile(),
-		Agent:          req.GetAgent(),
-		SSHAgent:       req.GetSshAgent(),
-		Username:       req.GetUsername(),
-		Uid:            req.GetUid(),
-		AllowedDomains: append([]string(nil), req.GetAllowedDomains()...),
-		Mounts:         append([]string(nil), req.GetMounts()...),
-		CloneMounts:    append([]string(nil), req.GetCloneMounts()...),
-		CPUs:           int(req.GetCpus()),
-		Memory:         int(req.GetMemory()),
+		Name:                 req.GetId(),
+		CloneFromDir:         req.GetCloneFromDir(),
+		ProfileName:          req.GetProfileName(),
+		ImageName:            req.GetImageName(),
+		EnvFile:              req.GetEnvFile(),
+		Agent:                req.GetAgent(),
+		SSHAgent:             req.GetSshAgent(),
+		Username:             req.GetUsername(),
+		Uid:                  req.GetUid(),
+		AllowedDomains:       append([]string(nil), req.GetAllowedDomains()...),
+		Labels:               maps.Clone(req.GetLabels()),
+		Shell:                req.GetShell(),
+		Network:              req.GetNetwork(),
+		CloneRoot:            req.GetCloneRoot(),
+		FromBranch:           req.GetFromBranch(),
+		NoDotfiles:           req.GetNoDotfiles(),
+		NoSSH:                req.GetNoSsh(),
+		AllowEmulation:       req.GetAllowEmulation(),
+		IgnoreOnCreateErrors: req.GetIgnoreOncreateErrors(),
+		Publish:              req.GetPublish(),
+		DNS:                  dnsConfigFromProto(req.GetDns()),
+		MessageLevel:         hostops.MessageLevel(req.GetMessageLevel()),
+		Mounts:               append([]string(nil), req.GetMounts()...),
+		CloneMounts:          append([]string(nil), req.GetCloneMounts()...),
+		CPUs:                 int(req.GetCpus()),
+		Memory:               int(req.GetMemory()),
 	}
 	if sharedCaches := req.GetSharedCaches(); sharedCaches != nil {
 		opts.SharedCaches = sandtypes.SharedCacheConfig{
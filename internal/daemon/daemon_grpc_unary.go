@@ -3,6 +3,7 @@ package daemon
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os/exec"
@@ -16,6 +17,9 @@ import (
 )
 
 func (s *daemonGRPCServer) Shutdown(ctx context.Context, _ *daemonpb.ShutdownRequest) (*daemonpb.StatusResponse, error) {
+	// The request context ends when this RPC returns, but Shutdown needs one
+	// that lasts while it drains other in-flight requests.
+	ctx = context.WithoutCancel(ctx)
 	go func() {
 		time.Sleep(100 * time.Millisecond)
 		s.daemon.Shutdown(ctx)
@@ -41,6 +45,22 @@ func (s *daemonGRPCServer) ListSandboxes(ctx context.Context, _ *daemonpb.ListSa
 	return &daemonpb.ListSandboxesResponse{Boxes: sandboxesToProto(boxes)}, nil
 }
 
+func (s *daemonGRPCServer) ListSandboxesDetailed(ctx context.Context, _ *daemonpb.ListSandboxesRequest) (*daemonpb.ListSandboxesResponse, error) {
+	boxes, err := s.daemon.ListSandboxesDetailed(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &daemonpb.ListSandboxesResponse{Boxes: sandboxesToProto(boxes)}, nil
+}
+
+func (s *daemonGRPCServer) SyncSandboxes(ctx context.Context, _ *daemonpb.ListSandboxesRequest) (*daemonpb.ListSandboxesResponse, error) {
+	boxes, err := s.daemon.SyncSandboxes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &daemonpb.ListSandboxesResponse{Boxes: sandboxesToProto(boxes)}, nil
+}
+
 func (s *daemonGRPCServer) ListDeletedSandboxes(ctx context.Context, _ *daemonpb.ListSandboxesRequest) (*daemonpb.ListSandboxesResponse, error) {
 	boxes, err := s.daemon.ListDeletedSandboxes(ctx)
 	if err != nil {
@@ -97,16 +117,35 @@ func (s *daemonGRPCServer) StopSandbox(ctx context.Context, req *daemonpb.IDRequ
 	return okStatus(), nil
 }
 
+func (s *daemonGRPCServer) KillSandbox(ctx context.Context, req *daemonpb.KillSandboxRequest) (*daemonpb.StatusResponse, error) {
+	id := req.GetId()
+	ctx = sandboxlog.WithSandboxID(ctx, id)
+	if err := s.daemon.KillSandbox(ctx, id, req.GetSignal()); err != nil {
+		return nil, err
+	}
+	return okStatus(), nil
+}
+
 func (s *daemonGRPCServer) StartSandbox(ctx context.Context, req *daemonpb.StartSandboxRequest) (*daemonpb.StatusResponse, error) {
 	if err := s.daemon.StartSandbox(ctx, StartSandboxOpts{
 		Name:     req.GetId(),
 		SSHAgent: req.GetSshAgent(),
+		Image:    req.GetImage(),
 	}); err != nil {
 		return nil, err
 	}
 	return okStatus(), nil
 }
 
+func (s *daemonGRPCServer) MarkSandboxUsed(ctx context.Context, req *daemonpb.IDRequest) (*daemonpb.StatusResponse, error) {
+	id := req.GetId()
+	ctx = sandboxlog.WithSandboxID(ctx, id)
+	if err := s.daemon.MarkSandboxUsed(ctx, id); err != nil {
+		return nil, err
+	}
+	return okStatus(), nil
+}
+
 func (s *daemonGRPCServer) SyncHostGitMirror(ctx context.Context, req *daemonpb.IDRequest) (*daemonpb.SyncHostGitMirrorResponse, error) {
 	mirrorPath, err := s.daemon.SyncHostGitMirror(ctx, req.GetId())
 	if err != nil {
@@ -115,6 +154,78 @@ func (s *daemonGRPCServer) SyncHostGitMirror(ctx context.Context, req *daemonpb.
 	return &daemonpb.SyncHostGitMirrorResponse{MirrorPath: mirrorPath}, nil
 }
 
+func (s *daemonGRPCServer) FetchHostChanges(ctx context.Context, req *daemonpb.FetchHostChangesRequest) (*daemonpb.FetchHostChangesResponse, error) {
+	mirrorPath, err := s.daemon.FetchHostChanges(ctx, FetchHostChangesOpts{
+		Name:            req.GetId(),
+		Remote:          req.GetRemote(),
+		CallerSandboxID: s.sandboxID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &daemonpb.FetchHostChangesResponse{MirrorPath: mirrorPath}, nil
+}
+
+// errResyncFromSandbox rejects resync requests made on a sandbox's own
+// socket: a resync reads the host workspace, which only the host may ask for.
+var errResyncFromSandbox = errors.New("workspaces can only be resynced from the host")
+
+func (s *daemonGRPCServer) ResyncWorkspace(ctx context.Context, req *daemonpb.IDRequest) (*daemonpb.ResyncWorkspaceResponse, error) {
+	if s.sandboxID != "" {
+		return nil, errResyncFromSandbox
+	}
+	result, err := s.daemon.ResyncWorkspace(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	return &daemonpb.ResyncWorkspaceResponse{Copied: result.Copied, Conflicts: result.Conflicts}, nil
+}
+
+// errPortForwardFromSandbox rejects port forward requests made on a sandbox's
+// own socket: forwards open ports on the host, which only the host may ask for.
+var errPortForwardFromSandbox = errors.New("port forwards can only be managed from the host")
+
+func (s *daemonGRPCServer) StartPortForward(ctx context.Context, req *daemonpb.PortForwardRequest) (*daemonpb.PortForwardResponse, error) {
+	if s.sandboxID != "" {
+		return nil, errPortForwardFromSandbox
+	}
+	if req.GetForward() == nil {
+		return nil, errors.New("port forward is required")
+	}
+	fwd, err := s.daemon.StartPortForward(ctx, req.GetId(), portForwardFromProto(req.GetForward()))
+	if err != nil {
+		return nil, err
+	}
+	return &daemonpb.PortForwardResponse{Forward: portForwardToProto(fwd)}, nil
+}
+
+func (s *daemonGRPCServer) ListPortForwards(ctx context.Context, req *daemonpb.IDRequest) (*daemonpb.PortForwardsResponse, error) {
+	if s.sandboxID != "" {
+		return nil, errPortForwardFromSandbox
+	}
+	forwards, err := s.daemon.ListPortForwards(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	return portForwardsToProto(forwards), nil
+}
+
+func (s *daemonGRPCServer) StopPortForwards(ctx context.Context, req *daemonpb.PortForwardRequest) (*daemonpb.PortForwardsResponse, error) {
+	if s.sandboxID != "" {
+		return nil, errPortForwardFromSandbox
+	}
+	var fwd *sandtypes.PortForward
+	if req.GetForward() != nil {
+		f := portForwardFromProto(req.GetForward())
+		fwd = &f
+	}
+	stopped, err := s.daemon.StopPortForwards(ctx, req.GetId(), fwd)
+	if err != nil {
+		return nil, err
+	}
+	return portForwardsToProto(stopped), nil
+}
+
 func (s *daemonGRPCServer) RenameSandbox(ctx context.Context, req *daemonpb.RenameSandboxRequest) (*daemonpb.RenameSandboxResponse, error) {
 	sbox, err := s.daemon.RenameSandbox(ctx, req.GetOldName(), req.GetNewName())
 	if err != nil {
@@ -123,6 +234,14 @@ func (s *daemonGRPCServer) RenameSandbox(ctx context.Context, req *daemonpb.Rena
 	return &daemonpb.RenameSandboxResponse{Box: sandboxToProto(sbox)}, nil
 }
 
+func (s *daemonGRPCServer) ForkSandbox(ctx context.Context, req *daemonpb.ForkSandboxRequest) (*daemonpb.ForkSandboxResponse, error) {
+	sbox, err := s.daemon.ForkSandbox(ctx, req.GetSourceName(), req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	return &daemonpb.ForkSandboxResponse{Box: sandboxToProto(sbox)}, nil
+}
+
 func (s *daemonGRPCServer) ResolveAgentLaunchEnv(ctx context.Context, req *daemonpb.ResolveAgentLaunchEnvRequest) (*daemonpb.ResolveAgentLaunchEnvResponse, error) {
 	resolved, err := s.daemon.resolveCreateSandboxRequirements(CreateSandboxOpts{
 		Agent:                req.GetAgent(),
@@ -160,6 +279,24 @@ func envPolicyFromProto(policy *daemonpb.EnvPolicy) sandtypes.EnvPolicy {
 	return out
 }
 
+// errPruneFromSandbox rejects image prunes requested on a sandbox's own
+// socket: removing images affects every sandbox on the host.
+var errPruneFromSandbox = errors.New("images can only be pruned from the host")
+
+func (s *daemonGRPCServer) PruneImages(ctx context.Context, req *daemonpb.PruneImagesRequest) (*daemonpb.PruneImagesResponse, error) {
+	if s.sandboxID != "" {
+		return nil, errPruneFromSandbox
+	}
+	images, err := s.daemon.PruneImages(ctx, PruneImagesOpts{
+		DryRun: req.GetDryRun(),
+		Keep:   req.GetKeep(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &daemonpb.PruneImagesResponse{Images: images}, nil
+}
+
 func (s *daemonGRPCServer) ExportImage(ctx context.Context, req *daemonpb.ExportImageRequest) (*daemonpb.StatusResponse, error) {
 	sbox, err := s.daemon.GetSandbox(ctx, req.GetId())
 	if err != nil {
@@ -1,6 +1,7 @@
 package daemon
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"errors"
@@ -15,10 +16,12 @@ import (
 	"strings"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/banksean/sand/internal/daemon/boxer"
 	"github.com/banksean/sand/internal/daemon/daemonpb"
 	"github.com/banksean/sand/internal/daemon/lifecycle"
+	"github.com/banksean/sand/internal/hostops"
 	"github.com/banksean/sand/internal/profiles"
 	"github.com/banksean/sand/internal/runtimepaths"
 	"github.com/banksean/sand/internal/sandboxlog"
@@ -36,6 +39,10 @@ const (
 	defaultLockFile       = "sandd.lock"
 	envMCPEnable          = "SAND_MCP"
 	socketFileMode        = 0o666
+
+	// defaultShutdownGracePeriod bounds how long Shutdown waits for in-flight
+	// requests, such as a create that is still pulling an image, to finish.
+	defaultShutdownGracePeriod = 30 * time.Second
 )
 
 type Daemon struct {
@@ -43,6 +50,20 @@ type Daemon struct {
 	GRPCSocketPath string
 	LocalDomain    string
 	LogFile        string
+	// IdleStop configures automatic stopping of idle sandbox containers.
+	IdleStop IdleStopOptions
+	// ShutdownGracePeriod is how long Shutdown lets in-flight requests run
+	// before forcing their connections closed.
+	ShutdownGracePeriod time.Duration
+	// ContainerReadyTimeout is how long a started container has to report
+	// running and accept execs before its hooks run. Zero uses lifecycle.DefaultReadyTimeout.
+	ContainerReadyTimeout time.Duration
+	// ContainerCacheTTL is how long container inspect results are reused
+	// before the container service is asked again. Zero disables the cache.
+	ContainerCacheTTL time.Duration
+	// MetricsAddr is the TCP address, such as "127.0.0.1:9464", on which to
+	// serve Prometheus metrics at /metrics. Empty disables the endpoint.
+	MetricsAddr string
 
 	hostMCP *HostMCP
 	boxer   *boxer.Boxer
@@ -57,14 +78,22 @@ type Daemon struct {
 	// it doesn't appear to get re-established by apple/container automatically.
 	//
 	// For now, just be aware that restarting the daemon means that any running sandbox containers
-	// won't be able to talk to sandd on the host machine any more (unless they restart the sandbox
-	// manually, after the daemon restart).
+	// won't be able to talk to sandd on the host machine until something starts the sandbox
+	// again (e.g. sand shell), which serves its sockets anew.
 	innieServers     map[string]*http.Server
 	innieGRPCServers map[string]*grpc.Server
 
-	lockFile *os.File
-	shutdown chan any
-	grpcSrv  *grpc.Server
+	lockFile     *os.File
+	shutdown     chan any
+	shutdownOnce sync.Once
+	// shuttingDown is closed when Shutdown begins, ending long-lived streams
+	// such as WatchEvents so they don't hold up draining.
+	shuttingDown chan struct{}
+	grpcSrv      *grpc.Server
+	metricsSrv   *http.Server
+
+	// now is the clock used for idle checks; tests replace it.
+	now func() time.Time
 }
 
 // NewDaemonWithBoxer creates a Daemon with a pre-built Boxer injected.
@@ -80,15 +109,18 @@ func NewDaemonWithBoxer(appBaseDir, localDomain string, b *boxer.Boxer) *Daemon
 
 func NewDaemon(appBaseDir, localDomain string) *Daemon {
 	return &Daemon{
-		AppBaseDir:     appBaseDir,
-		GRPCSocketPath: filepath.Join(appBaseDir, DefaultGRPCSocketFile),
-		LocalDomain:    localDomain,
+		AppBaseDir:          appBaseDir,
+		GRPCSocketPath:      filepath.Join(appBaseDir, DefaultGRPCSocketFile),
+		LocalDomain:         localDomain,
+		ShutdownGracePeriod: defaultShutdownGracePeriod,
 		hostMCP: &HostMCP{
 			ChromeDevToolsPort: 9222,
 			ChromeUserDataDir:  "/tmp/chrome-profile-stable",
 		},
 		innieServers:     map[string]*http.Server{},
 		innieGRPCServers: map[string]*grpc.Server{},
+		shuttingDown:     make(chan struct{}),
+		now:              time.Now,
 	}
 }
 
@@ -103,6 +135,7 @@ func (d *Daemon) initLifecycle() {
 		ImageService:     d.boxer.ImageService,
 		AgentRegistry:    d.boxer.AgentRegistry,
 		Store:            d.boxer,
+		ReadyTimeout:     d.ContainerReadyTimeout,
 	})
 }
 
@@ -144,7 +177,11 @@ func (d *Daemon) startDaemonServer(ctx context.Context) error {
 		}
 		d.boxer = sber
 	}
+	d.boxer.CacheContainerInspect(d.ContainerCacheTTL)
 	d.initLifecycle()
+	if err := d.boxer.ForgetHostProcessesFromEarlierBoots(ctx); err != nil {
+		slog.WarnContext(ctx, "Daemon.startDaemonServer ForgetHostProcessesFromEarlierBoots", "error", err)
+	}
 	if err := d.boxer.Sync(ctx); err != nil {
 		return fmt.Errorf("failed to sync Boxer db with current environment state: %v\n", err)
 	}
@@ -153,6 +190,16 @@ func (d *Daemon) startDaemonServer(ctx context.Context) error {
 
 	go d.serveOutieGRPCSocket(ctx)
 
+	if d.MetricsAddr != "" {
+		if err := d.serveMetrics(ctx); err != nil {
+			return err
+		}
+	}
+
+	if d.IdleStop.Timeout > 0 {
+		go d.runIdleStopper(ctx)
+	}
+
 	if os.Getenv(envMCPEnable) != "" {
 		go func() {
 			if err := d.hostMCP.StartHostServices(ctx); err != nil {
@@ -170,25 +217,39 @@ func (d *Daemon) waitForShutdown(ctx context.Context) {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
+	// ctx is typically cancelled by the same SIGTERM, so shut down with a
+	// context that still allows in-flight requests their grace period.
 	select {
-	case <-ctx.Done(): // TODO: is this really necessary here?
+	case <-ctx.Done():
+		d.Shutdown(context.WithoutCancel(ctx))
 	case <-sigChan:
-		d.Shutdown(ctx)
+		d.Shutdown(context.WithoutCancel(ctx))
 	case <-d.shutdown:
 		// Shutdown already initiated
 	}
 }
 
+// Shutdown stops accepting new connections, waits up to ShutdownGracePeriod
+// for in-flight requests to finish, and then releases the daemon's socket and
+// lock files. Calls after the first are no-ops.
 func (d *Daemon) Shutdown(ctx context.Context) {
+	d.shutdownOnce.Do(func() { d.doShutdown(ctx) })
+}
+
+func (d *Daemon) doShutdown(ctx context.Context) {
 	lockFilePath := filepath.Join(d.AppBaseDir, defaultLockFile)
 
 	slog.InfoContext(ctx, "Daemon.Shutdown", "pid", os.Getpid())
+	close(d.shuttingDown)
 	// Close listener (stops accepting new connections)
 	if d.outieGRPCListener != nil {
 		d.outieGRPCListener.Close()
 	}
 	if d.grpcSrv != nil {
-		d.grpcSrv.Stop()
+		gracefulStop(ctx, d.grpcSrv, d.ShutdownGracePeriod)
+	}
+	if d.metricsSrv != nil {
+		d.metricsSrv.Close()
 	}
 
 	if d.hostMCP != nil {
@@ -226,9 +287,34 @@ func (d *Daemon) serveOutieGRPCSocket(ctx context.Context) {
 	}
 }
 
+// gracefulStop lets srv finish its in-flight RPCs, forcing them closed if
+// they are still running after grace or once ctx is done.
+func gracefulStop(ctx context.Context, srv *grpc.Server, grace time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(done)
+	}()
+
+	timer := time.NewTimer(grace)
+	defer timer.Stop()
+	select {
+	case <-done:
+		return
+	case <-timer.C:
+		slog.WarnContext(ctx, "Daemon.Shutdown grace period expired, closing in-flight requests", "gracePeriod", grace)
+	case <-ctx.Done():
+		slog.WarnContext(ctx, "Daemon.Shutdown cancelled, closing in-flight requests", "error", ctx.Err())
+	}
+	srv.Stop()
+	<-done
+}
+
 type daemonGRPCServer struct {
 	daemonpb.UnimplementedDaemonServiceServer
 	daemon *Daemon
+	// sandboxID is set for servers listening on a sandbox's own socket.
+	sandboxID string
 }
 
 func (s *daemonGRPCServer) Ping(context.Context, *daemonpb.PingRequest) (*daemonpb.PingResponse, error) {
@@ -289,6 +375,8 @@ func (d *Daemon) stopInnieServer(ctx context.Context, id string) error {
 	return nil
 }
 
+// serveInnieHttpSocket registers the sandbox's http server and serves it on
+// unixListener in the background.
 func (d *Daemon) serveInnieHttpSocket(ctx context.Context, sandboxID string, unixListener net.Listener) {
 	ctx = sandboxlog.WithSandboxID(ctx, sandboxID)
 	mux := http.NewServeMux()
@@ -306,15 +394,18 @@ func (d *Daemon) serveInnieHttpSocket(ctx context.Context, sandboxID string, uni
 	d.innieServers[sandboxID] = server
 	d.innieServersMu.Unlock()
 
-	defer unixListener.Close()
-
-	slog.InfoContext(ctx, "Daemon.serveInnieSocket starting up")
-	err := server.Serve(unixListener)
-	if err != nil && !isExpectedServeClose(err) {
-		slog.ErrorContext(ctx, "Daemon.serveInnieSocket", "error", err)
-	}
+	go func() {
+		defer unixListener.Close()
+		slog.InfoContext(ctx, "Daemon.serveInnieSocket starting up")
+		err := server.Serve(unixListener)
+		if err != nil && !isExpectedServeClose(err) {
+			slog.ErrorContext(ctx, "Daemon.serveInnieSocket", "error", err)
+		}
+	}()
 }
 
+// serveInnieGRPCSocket registers the sandbox's gRPC server and serves it on
+// unixListener in the background.
 func (d *Daemon) serveInnieGRPCSocket(ctx context.Context, sandboxID string, unixListener net.Listener) {
 	ctx = sandboxlog.WithSandboxID(ctx, sandboxID)
 	server := grpc.NewServer(
@@ -331,18 +422,29 @@ func (d *Daemon) serveInnieGRPCSocket(ctx context.Context, sandboxID string, uni
 			})
 		}),
 	)
-	daemonpb.RegisterDaemonServiceServer(server, &daemonGRPCServer{daemon: d})
+	daemonpb.RegisterDaemonServiceServer(server, &daemonGRPCServer{daemon: d, sandboxID: sandboxID})
 
 	d.innieServersMu.Lock()
 	d.innieGRPCServers[sandboxID] = server
 	d.innieServersMu.Unlock()
 
-	defer unixListener.Close()
+	go func() {
+		defer unixListener.Close()
+		slog.InfoContext(ctx, "Daemon.serveInnieGRPCSocket starting up")
+		if err := server.Serve(unixListener); err != nil && !isExpectedServeClose(err) {
+			slog.ErrorContext(ctx, "Daemon.serveInnieGRPCSocket", "error", err)
+		}
+	}()
+}
 
-	slog.InfoContext(ctx, "Daemon.serveInnieGRPCSocket starting up")
-	if err := server.Serve(unixListener); err != nil && !isExpectedServeClose(err) {
-		slog.ErrorContext(ctx, "Daemon.serveInnieGRPCSocket", "error", err)
-	}
+// servingInnie reports whether the daemon is serving the sandbox's sockets.
+// It isn't after sandd restarts with the sandbox's container still running.
+func (d *Daemon) servingInnie(id string) bool {
+	d.innieServersMu.Lock()
+	defer d.innieServersMu.Unlock()
+	_, httpOK := d.innieServers[id]
+	_, grpcOK := d.innieGRPCServers[id]
+	return httpOK && grpcOK
 }
 
 type contextServerStream struct {
@@ -471,6 +573,40 @@ func (d *Daemon) StopSandbox(ctx context.Context, name string) error {
 	return d.boxer.StopContainer(ctx, sbox)
 }
 
+// KillSandbox sends signal to a single sandbox container.
+func (d *Daemon) KillSandbox(ctx context.Context, name, signal string) error {
+	sbox, err := d.boxer.Get(ctx, name)
+	if err != nil {
+		return err
+	}
+	if sbox == nil {
+		return fmt.Errorf("sandbox not found: %s", name)
+	}
+	ctx = sandboxlog.WithSandboxID(ctx, sbox.ID)
+	sig, err := hostops.ParseSignal(signal)
+	if err != nil {
+		return err
+	}
+	if sig == syscall.SIGKILL {
+		if err := d.stopInnieServer(ctx, sbox.ID); err != nil {
+			return err
+		}
+	}
+	return d.boxer.KillContainer(ctx, sbox, signal)
+}
+
+// MarkSandboxUsed bumps a sandbox's last-used timestamp.
+func (d *Daemon) MarkSandboxUsed(ctx context.Context, name string) error {
+	sbox, err := d.boxer.Get(ctx, name)
+	if err != nil {
+		return err
+	}
+	if sbox == nil {
+		return fmt.Errorf("sandbox not found: %s", name)
+	}
+	return d.boxer.MarkUsed(sandboxlog.WithSandboxID(ctx, sbox.ID), sbox)
+}
+
 func (d *Daemon) createContainerSocket(ctx context.Context, id string) (net.Listener, error) {
 	ctx = sandboxlog.WithSandboxID(ctx, id)
 	socketsDir := runtimepaths.ContainerHTTPSocketDir()
@@ -535,18 +671,29 @@ func (d *Daemon) StartSandbox(ctx context.Context, opts StartSandboxOpts) error
 	}
 	ctx = sandboxlog.WithSandboxID(ctx, sbox.ID)
 
-	if sbox.OriginalGitDetails != nil && sbox.OriginalGitDetails.Commit != "" {
-		if _, err := d.boxer.SyncHostGitMirror(ctx, sbox); err != nil {
-			return err
-		}
+	// A second caller that raced this one (e.g. two `sand shell`s opened into a
+	// stopped sandbox) waits here, then reloads and finds it already started.
+	defer d.boxer.LockSandbox(sbox.ID)()
+	sbox, err = d.boxer.GetByID(ctx, sbox.ID)
+	if err != nil {
+		return err
+	}
+	if sbox == nil {
+		return fmt.Errorf("sandbox not found: %s", name)
 	}
 
 	needsRecreate := false
 	ctr, err := d.boxer.GetContainer(ctx, sbox.ContainerID)
-	if err != nil {
+	if err != nil && !hostops.IsContainerNotFound(err) {
 		return err
 	}
 	sbox.Container = ctr
+	if ctr == nil {
+		// The container was removed out from under the sandbox; its clone
+		// is intact, so build it a new one.
+		slog.WarnContext(ctx, "Daemon.StartSandbox container missing, recreating", "id", sbox.ID, "containerID", sbox.ContainerID, "error", err)
+		needsRecreate = true
+	}
 	if opts.SSHAgent {
 		if ctr != nil && !ctr.Configuration.SSH {
 			if ctr.Status.State == "running" {
@@ -555,6 +702,38 @@ func (d *Daemon) StartSandbox(ctx context.Context, opts StartSandboxOpts) error
 			needsRecreate = true
 		}
 	}
+	// Moving to another image replaces the container, running or not; a
+	// container that had ssh-agent forwarding keeps it.
+	changeImage := opts.Image != "" && opts.Image != sbox.ImageName
+	enableSSHAgent := opts.SSHAgent
+	if changeImage {
+		slog.InfoContext(ctx, "Daemon.StartSandbox changing image", "id", sbox.ID, "from", sbox.ImageName, "to", opts.Image)
+		needsRecreate = true
+		enableSSHAgent = enableSSHAgent || (ctr != nil && ctr.Configuration.SSH)
+	}
+	if sbox.OriginalGitDetails != nil && sbox.OriginalGitDetails.Commit != "" {
+		if _, err := d.boxer.SyncHostGitMirror(ctx, sbox); err != nil {
+			return err
+		}
+	}
+
+	if !needsRecreate && ctr != nil && ctr.Status.State == "running" && sbox.StartHooksRan {
+		slog.InfoContext(ctx, "Daemon.StartSandbox already running", "id", sbox.ID)
+		// After a sandd restart the container is still up but nothing is
+		// serving its sockets.
+		if !d.servingInnie(sbox.ID) {
+			httpListener, grpcListener, err := d.createContainerSockets(ctx, sbox.ID)
+			if err != nil {
+				return err
+			}
+			d.serveInnieHttpSocket(ctx, sbox.ID, httpListener)
+			d.serveInnieGRPCSocket(ctx, sbox.ID, grpcListener)
+		}
+		if err := d.boxer.MarkUsed(ctx, sbox); err != nil {
+			slog.WarnContext(ctx, "Daemon.StartSandbox MarkUsed", "error", err)
+		}
+		return nil
+	}
 
 	httpListener, grpcListener, err := d.createContainerSockets(ctx, sbox.ID)
 	if err != nil {
@@ -562,15 +741,27 @@ func (d *Daemon) StartSandbox(ctx context.Context, opts StartSandboxOpts) error
 	}
 
 	if needsRecreate {
-		if err := d.runtime.RecreateContainer(ctx, sbox, true); err != nil {
+		oldImage := sbox.ImageName
+		if changeImage {
+			sbox.ImageName = opts.Image
+		}
+		err := d.runtime.RecreateContainer(ctx, sbox, enableSSHAgent)
+		// The image is only recorded once a container exists for it.
+		switch {
+		case err != nil && changeImage:
+			err = d.restoreImage(ctx, sbox, oldImage, enableSSHAgent, err)
+		case err == nil && changeImage:
+			err = d.boxer.UpdateImageName(ctx, sbox, opts.Image)
+		}
+		if err != nil {
 			_ = httpListener.Close()
 			_ = grpcListener.Close()
 			return err
 		}
 	}
 
-	go d.serveInnieHttpSocket(ctx, sbox.ID, httpListener)
-	go d.serveInnieGRPCSocket(ctx, sbox.ID, grpcListener)
+	d.serveInnieHttpSocket(ctx, sbox.ID, httpListener)
+	d.serveInnieGRPCSocket(ctx, sbox.ID, grpcListener)
 
 	var startErr error
 	if needsRecreate || !sbox.ContainerBootstrapped {
@@ -583,6 +774,11 @@ func (d *Daemon) StartSandbox(ctx context.Context, opts StartSandboxOpts) error
 		_ = grpcListener.Close()
 		return startErr
 	}
+	// Starting counts as use, so the idle stopper doesn't immediately stop a
+	// sandbox that was created long ago but never shelled into.
+	if err := d.boxer.MarkUsed(ctx, sbox); err != nil {
+		slog.WarnContext(ctx, "Daemon.StartSandbox MarkUsed", "error", err)
+	}
 	return nil
 }
 
@@ -598,6 +794,111 @@ func (d *Daemon) SyncHostGitMirror(ctx context.Context, name string) (string, er
 	return d.boxer.SyncHostGitMirror(ctx, sbox)
 }
 
+// ResyncWorkspace copies host workspace changes into the named sandbox's clone.
+func (d *Daemon) ResyncWorkspace(ctx context.Context, name string) (*sandtypes.ResyncResult, error) {
+	sbox, err := d.boxer.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if sbox == nil {
+		return nil, fmt.Errorf("sandbox not found: %s", name)
+	}
+	ctx = sandboxlog.WithSandboxID(ctx, sbox.ID)
+	return d.boxer.ResyncWorkspace(ctx, sbox)
+}
+
+// FetchHostChangesOpts holds the parameters for updating the host mirror a
+// sandbox fetches host changes from.
+type FetchHostChangesOpts struct {
+	// Name is the sandbox name. It may be empty when CallerSandboxID is set.
+	Name string
+	// Remote is the clone's git remote the caller will fetch once the mirror
+	// is updated. Defaults to "origin".
+	Remote string
+	// CallerSandboxID is set when the request arrived on a sandbox's own socket,
+	// which may only fetch into that sandbox's clone.
+	CallerSandboxID string
+}
+
+// FetchHostChanges updates the shared host mirror a sandbox's origin reads
+// from, so a git fetch in the container picks up the latest host commits.
+// Nothing runs in the sandbox clone here: git would read the clone's own
+// config and hooks, which the sandbox can write.
+func (d *Daemon) FetchHostChanges(ctx context.Context, opts FetchHostChangesOpts) (string, error) {
+	remote := opts.Remote
+	if remote == "" {
+		remote = "origin"
+	}
+	if strings.HasPrefix(remote, "-") || strings.ContainsAny(remote, " \t\n") {
+		return "", fmt.Errorf("invalid git remote name %q", remote)
+	}
+	var sbox *sandtypes.Box
+	var err error
+	switch {
+	case opts.Name != "":
+		sbox, err = d.boxer.Get(ctx, opts.Name)
+	case opts.CallerSandboxID != "":
+		sbox, err = d.boxer.GetByID(ctx, opts.CallerSandboxID)
+	default:
+		return "", fmt.Errorf("sandbox name is required")
+	}
+	if err != nil {
+		return "", err
+	}
+	if sbox == nil {
+		return "", fmt.Errorf("sandbox not found: %s", cmp.Or(opts.Name, opts.CallerSandboxID))
+	}
+	if opts.CallerSandboxID != "" && sbox.ID != opts.CallerSandboxID {
+		return "", fmt.Errorf("sandbox %s may not fetch into sandbox %s", opts.CallerSandboxID, sbox.ID)
+	}
+	ctx = sandboxlog.WithSandboxID(ctx, sbox.ID)
+	return d.boxer.SyncHostGitMirror(ctx, sbox)
+}
+
+// lookupSandbox returns the named sandbox or an error if it does not exist.
+func (d *Daemon) lookupSandbox(ctx context.Context, name string) (*sandtypes.Box, error) {
+	sbox, err := d.boxer.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if sbox == nil {
+		return nil, fmt.Errorf("sandbox not found: %s", name)
+	}
+	return sbox, nil
+}
+
+// StartPortForward opens an ssh port forward between the host and the named
+// sandbox's container. The ssh process runs on behalf of sandd, so the
+// forward outlives the CLI invocation that asked for it.
+func (d *Daemon) StartPortForward(ctx context.Context, name string, fwd sandtypes.PortForward) (sandtypes.PortForward, error) {
+	sbox, err := d.lookupSandbox(ctx, name)
+	if err != nil {
+		return sandtypes.PortForward{}, err
+	}
+	ctx = sandboxlog.WithSandboxID(ctx, sbox.ID)
+	return d.boxer.StartPortForward(ctx, sbox, fwd)
+}
+
+// ListPortForwards returns the named sandbox's running port forwards.
+func (d *Daemon) ListPortForwards(ctx context.Context, name string) ([]sandtypes.PortForward, error) {
+	sbox, err := d.lookupSandbox(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return d.boxer.ListPortForwards(ctx, sbox)
+}
+
+// StopPortForwards stops the named sandbox's forward matching fwd, or all of
+// its forwards when fwd is nil.
+func (d *Daemon) StopPortForwards(ctx context.Context, name string, fwd *sandtypes.PortForward) ([]sandtypes.PortForward, error) {
+	sbox, err := d.lookupSandbox(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	ctx = sandboxlog.WithSandboxID(ctx, sbox.ID)
+	return d.boxer.StopPortForwards(ctx, sbox, fwd)
+}
+
 type CreateSandboxOpts struct {
 	ID                   string              `json:"id,omitempty"`
 	Name                 string              `json:"name,omitempty"`
@@ -613,18 +914,61 @@ type CreateSandboxOpts struct {
 	Username             string              `json:"username,omitempty"`
 	Uid                  string              `json:"uid,omitempty"`
 
-	AllowedDomains []string                    `json:"allowedDomains,omitempty"`
-	Mounts         []string                    `json:"mounts,omitempty"`
-	CloneMounts    []string                    `json:"cloneMounts,omitempty"`
-	SharedCaches   sandtypes.SharedCacheConfig `json:"sharedCaches,omitempty"`
-	CPUs           int                         `json:"cpus"`
-	Memory         int                         `json:"memory"`
+	AllowedDomains       []string                    `json:"allowedDomains,omitempty"`
+	Labels               map[string]string           `json:"labels,omitempty"`
+	Shell                string                      `json:"shell,omitempty"`
+	Network              string                      `json:"network,omitempty"`
+	CloneRoot            string                      `json:"cloneRoot,omitempty"`
+	FromBranch           string                      `json:"fromBranch,omitempty"`
+	NoDotfiles           bool                        `json:"noDotfiles,omitempty"`
+	NoSSH                bool                        `json:"noSSH,omitempty"`
+	AllowEmulation       bool                        `json:"allowEmulation,omitempty"`
+	IgnoreOnCreateErrors bool                        `json:"ignoreOnCreateErrors,omitempty"`
+	Publish              []string                    `json:"publish,omitempty"`
+	DNS                  sandtypes.DNSConfig         `json:"dns,omitempty"`
+	MessageLevel         hostops.MessageLevel        `json:"messageLevel,omitempty"`
+	Mounts               []string                    `json:"mounts,omitempty"`
+	CloneMounts          []string                    `json:"cloneMounts,omitempty"`
+	SharedCaches         sandtypes.SharedCacheConfig `json:"sharedCaches,omitempty"`
+	CPUs                 int                         `json:"cpus"`
+	Memory               int                         `json:"memory"`
+}
+
+type EnsureImageOpts struct {
+	ImageName  string `json:"imageName"`
+	PullAlways bool   `json:"pullAlways,omitempty"`
+	// DockerfileDir builds ImageName from this host directory instead of pulling it.
+	DockerfileDir string            `json:"dockerfileDir,omitempty"`
+	BuildArgs     map[string]string `json:"buildArgs,omitempty"`
+}
+
+type PruneImagesOpts struct {
+	DryRun bool     `json:"dryRun,omitempty"`
+	Keep   []string `json:"keep,omitempty"`
+}
+
+// restoreImage handles a failed move of sbox to another image. The old
+// container is deleted before its replacement is created, so it is recreated
+// from oldImage rather than leave the sandbox with no container. moveErr is
+// why the move failed; the returned error says what became of the container.
+func (d *Daemon) restoreImage(ctx context.Context, sbox *sandtypes.Box, oldImage string, enableSSHAgent bool, moveErr error) error {
+	newImage := sbox.ImageName
+	sbox.ImageName = oldImage
+	if err := d.runtime.RecreateContainer(ctx, sbox, enableSSHAgent); err != nil {
+		slog.ErrorContext(ctx, "Daemon.StartSandbox restore old image", "id", sbox.ID, "image", oldImage, "error", err)
+		return fmt.Errorf("move sandbox %s to image %s: %w; its old container was removed, and recreating it from %s failed: %v", sbox.Name, newImage, moveErr, oldImage, err)
+	}
+	return fmt.Errorf("move sandbox %s to image %s: %w; its container was recreated from %s", sbox.Name, newImage, moveErr, oldImage)
 }
 
 type StartSandboxOpts struct {
 	Name     string `json:"name,omitempty"`
 	ID       string `json:"id,omitempty"`
 	SSHAgent bool   `json:"sshAgent,omitempty"`
+	// Image, if it differs from the sandbox's image, moves the sandbox onto
+	// it: the container is recreated from the image against the existing
+	// clone. The image must already be present.
+	Image string `json:"image,omitempty"`
 }
 
 func loadSandboxProfile(projectDir, profileName string) (sandtypes.Profile, bool, error) {
@@ -671,10 +1015,24 @@ func (d *Daemon) createSandbox(ctx context.Context, opts CreateSandboxOpts, prog
 	if opts.Name == "" {
 		opts.Name = opts.ID
 	}
+	if opts.Name == "" {
+		name, err := d.boxer.NewSandboxName(ctx)
+		if err != nil {
+			return nil, err
+		}
+		opts.Name = name
+	}
 	if opts.ID == "" {
 		opts.ID = uuid.NewString()
 	}
 	ctx = sandboxlog.WithSandboxID(ctx, opts.ID)
+	// Fail before any slow setup: the name becomes part of the clone path,
+	// container name, git remote and ssh hostname.
+	if opts.Name != "" {
+		if err := sandtypes.ValidateSandboxName(opts.Name); err != nil {
+			return nil, err
+		}
+	}
 	agentType := opts.Agent
 	if agentType == "" {
 		agentType = "default"
@@ -712,6 +1070,11 @@ func (d *Daemon) createSandbox(ctx context.Context, opts CreateSandboxOpts, prog
 	if err := d.validateSelectableAgent(opts.Agent); err != nil {
 		return nil, err
 	}
+	for key := range opts.Labels {
+		if err := sandtypes.ValidateLabelKey(key); err != nil {
+			return nil, err
+		}
+	}
 
 	if opts.SharedCaches.HTTPProxy {
 		if err := d.boxer.HTTPProxyCacheService().Ensure(ctx, d.LocalDomain, progress); err != nil {
@@ -720,23 +1083,36 @@ func (d *Daemon) createSandbox(ctx context.Context, opts CreateSandboxOpts, prog
 	}
 
 	sbox, err := d.boxer.NewSandbox(ctx, boxer.NewSandboxOpts{
-		AgentType:      agentType,
-		ID:             opts.ID,
-		Name:           opts.Name,
-		HostWorkDir:    opts.CloneFromDir,
-		ProfileName:    profileName,
-		Profile:        profile,
-		ImageName:      opts.ImageName,
-		EnvFile:        opts.EnvFile,
-		Username:       opts.Username,
-		Uid:            opts.Uid,
-		AllowedDomains: opts.AllowedDomains,
-		Mounts:         opts.Mounts,
-		CloneMounts:    opts.CloneMounts,
-		SharedCaches:   opts.SharedCaches,
-		CPUs:           opts.CPUs,
-		Memory:         opts.Memory,
-		LocalDomain:    d.LocalDomain,
+		AgentType:            agentType,
+		ID:                   opts.ID,
+		Name:                 opts.Name,
+		HostWorkDir:          opts.CloneFromDir,
+		ProfileName:          profileName,
+		Profile:              profile,
+		ImageName:            opts.ImageName,
+		EnvFile:              opts.EnvFile,
+		Username:             opts.Username,
+		Uid:                  opts.Uid,
+		AllowedDomains:       opts.AllowedDomains,
+		Labels:               opts.Labels,
+		Shell:                opts.Shell,
+		Network:              opts.Network,
+		CloneRoot:            opts.CloneRoot,
+		FromBranch:           opts.FromBranch,
+		NoDotfiles:           opts.NoDotfiles,
+		NoSSH:                opts.NoSSH,
+		AllowEmulation:       opts.AllowEmulation,
+		IgnoreOnCreateErrors: opts.IgnoreOnCreateErrors,
+		Publish:              opts.Publish,
+		DNS:                  opts.DNS,
+		MessageLevel:         opts.MessageLevel,
+		Mounts:               opts.Mounts,
+		CloneMounts:          opts.CloneMounts,
+		SharedCaches:         opts.SharedCaches,
+		CPUs:                 opts.CPUs,
+		Memory:               opts.Memory,
+		LocalDomain:          d.LocalDomain,
+		Progress:             progress,
 	})
 	if err != nil {
 		return nil, err
@@ -747,23 +1123,40 @@ func (d *Daemon) createSandbox(ctx context.Context, opts CreateSandboxOpts, prog
 	ctr, err := d.boxer.GetContainer(ctx, sbox.ContainerID)
 
 	if ctr == nil {
-		unixListener, grpcListener, err := d.createContainerSockets(ctx, sbox.ID)
+		// A sandbox without a container is unusable, so undo the whole create
+		// rather than leave it behind to block a retry with the same ID.
+		var unixListener, grpcListener net.Listener
+		discard := func(err error) error {
+			// The serve goroutines may not have registered their servers yet,
+			// so close the listeners too.
+			for _, l := range []net.Listener{unixListener, grpcListener} {
+				if l != nil {
+					l.Close()
+				}
+			}
+			d.removeContainerSockets(ctx, sbox.ID)
+			if discardErr := d.boxer.Discard(context.WithoutCancel(ctx), sbox); discardErr != nil {
+				slog.ErrorContext(ctx, "createSandbox Discard", "error", discardErr)
+			}
+			return err
+		}
+		unixListener, grpcListener, err = d.createContainerSockets(ctx, sbox.ID)
 		if err != nil {
-			return nil, err
+			return nil, discard(err)
 		}
-		go d.serveInnieHttpSocket(ctx, sbox.ID, unixListener)
-		go d.serveInnieGRPCSocket(ctx, sbox.ID, grpcListener)
+		d.serveInnieHttpSocket(ctx, sbox.ID, unixListener)
+		d.serveInnieGRPCSocket(ctx, sbox.ID, grpcListener)
 
 		err = d.runtime.CreateContainer(ctx, sbox, opts.SSHAgent)
 		if err != nil {
-			return nil, err
+			return nil, discard(err)
 		}
 		if err := d.boxer.UpdateContainerID(ctx, sbox, sbox.ContainerID); err != nil {
-			return nil, err
+			return nil, discard(err)
 		}
 		ctr, err = d.boxer.GetContainer(ctx, sbox.ContainerID)
 		if err != nil || ctr == nil {
-			return nil, fmt.Errorf("failed to get container after creation: %w", err)
+			return nil, discard(fmt.Errorf("failed to get container after creation: %w", err))
 		}
 	}
 
@@ -782,6 +1175,12 @@ func (d *Daemon) ListSandboxes(ctx context.Context) ([]sandtypes.Box, error) {
 	return d.boxer.List(ctx)
 }
 
+// ListSandboxesDetailed syncs every active sandbox and returns them all, each
+// with its container status and sync errors.
+func (d *Daemon) ListSandboxesDetailed(ctx context.Context) ([]sandtypes.Box, error) {
+	return d.boxer.ListSynced(ctx)
+}
+
 func (d *Daemon) HTTPProxyCache(ctx context.Context, action string, progress io.Writer) error {
 	service := d.boxer.HTTPProxyCacheService()
 	switch action {
@@ -798,6 +1197,29 @@ func (d *Daemon) HTTPProxyCache(ctx context.Context, action string, progress io.
 	}
 }
 
+// SyncSandboxes syncs the Boxer db with the clone directories and containers
+// of every active sandbox and returns the degraded ones. Their errors are
+// reported through the returned boxes, so only a failure to sync at all is
+// returned as an error.
+func (d *Daemon) SyncSandboxes(ctx context.Context) ([]sandtypes.Box, error) {
+	degraded, err := d.boxer.SyncSandboxes(ctx, boxer.SyncOpts{KeepGoing: true})
+	if err != nil && len(degraded) == 0 {
+		return nil, err
+	}
+	if err != nil {
+		slog.WarnContext(ctx, "Daemon.SyncSandboxes", "error", err)
+	}
+	return degraded, nil
+}
+
+// PruneImages removes local images sand fetched that no sandbox uses.
+func (d *Daemon) PruneImages(ctx context.Context, opts PruneImagesOpts) ([]string, error) {
+	return d.boxer.PruneImages(ctx, boxer.PruneImagesOpts{
+		DryRun: opts.DryRun,
+		Keep:   opts.Keep,
+	})
+}
+
 func (d *Daemon) HTTPProxyCacheStatus(ctx context.Context) (HTTPProxyCacheStatus, error) {
 	status, err := d.boxer.HTTPProxyCacheService().Status(ctx, d.LocalDomain)
 	if err != nil {
@@ -848,20 +1270,33 @@ func (d *Daemon) RemoveSandbox(ctx context.Context, name string) error {
 	if err := d.stopInnieServer(ctx, sbox.ID); err != nil {
 		return err
 	}
-	// Remove the container socket, if there is one.
-	socketPath := runtimepaths.ContainerHTTPSocketPath(sbox.ID)
-	if _, err := os.Stat(socketPath); err == nil {
-		if err := os.Remove(socketPath); err != nil {
-			return err
-		}
+	if err := removeSocketFiles(sbox.ID); err != nil {
+		return err
 	}
-	grpcSocketPath := runtimepaths.ContainerGRPCSocketPath(sbox.ID)
-	if _, err := os.Stat(grpcSocketPath); err == nil {
-		if err := os.Remove(grpcSocketPath); err != nil {
-			return err
+	return d.boxer.SoftDelete(ctx, sbox)
+}
+
+// removeSocketFiles removes the sandbox's container sockets, if there are any.
+func removeSocketFiles(id string) error {
+	for _, socketPath := range []string{runtimepaths.ContainerHTTPSocketPath(id), runtimepaths.ContainerGRPCSocketPath(id)} {
+		if _, err := os.Stat(socketPath); err == nil {
+			if err := os.Remove(socketPath); err != nil {
+				return err
+			}
 		}
 	}
-	return d.boxer.SoftDelete(ctx, sbox)
+	return nil
+}
+
+// removeContainerSockets stops serving the sandbox's container sockets and
+// removes them, logging rather than returning failures.
+func (d *Daemon) removeContainerSockets(ctx context.Context, id string) {
+	if err := d.stopInnieServer(ctx, id); err != nil {
+		slog.WarnContext(ctx, "removeContainerSockets stopInnieServer", "error", err)
+	}
+	if err := removeSocketFiles(id); err != nil {
+		slog.WarnContext(ctx, "removeContainerSockets", "error", err)
+	}
 }
 
 // ExpungeSandbox hard-deletes a single soft-deleted sandbox by ID.
@@ -877,3 +1312,9 @@ func (d *Daemon) RecoverSandbox(ctx context.Context, id string) (*sandtypes.Box,
 func (d *Daemon) RenameSandbox(ctx context.Context, oldName, newName string) (*sandtypes.Box, error) {
 	return d.boxer.RenameSandbox(ctx, oldName, newName, io.Discard)
 }
+
+// ForkSandbox creates sandbox name, under a new ID, from a copy of the active
+// sandbox sourceName.
+func (d *Daemon) ForkSandbox(ctx context.Context, sourceName, name string) (*sandtypes.Box, error) {
+	return d.boxer.ForkSandbox(ctx, sourceName, uuid.NewString(), name, io.Discard)
+}
@@ -0,0 +1,194 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Status describes a sandd process as seen by `sandd status`.
+type Status struct {
+	Running    bool
+	SocketPath string
+	LogFile    string
+	// PID is read from the lock file; 0 if it couldn't be read.
+	PID int
+	// StartedAt is when the daemon wrote its lock file at startup; zero if unknown.
+	StartedAt time.Time
+	// GitCommit identifies the running daemon's build, if it reported one.
+	GitCommit string
+}
+
+// GetStatus reports on the daemon for appBaseDir. client is nil when no
+// connection could be made; a daemon only counts as running if it answers a ping.
+func GetStatus(ctx context.Context, appBaseDir, logFile string, client Client) Status {
+	st := Status{
+		SocketPath: filepath.Join(appBaseDir, DefaultGRPCSocketFile),
+		LogFile:    logFile,
+	}
+	if client == nil || client.Ping(ctx) != nil {
+		return st
+	}
+	st.Running = true
+	st.PID, st.StartedAt, _ = readLockFile(filepath.Join(appBaseDir, defaultLockFile))
+	if info, err := client.Version(ctx); err == nil {
+		st.GitCommit = info.GitCommit
+		if st.GitCommit == "" && info.BuildInfo != nil {
+			for _, setting := range info.BuildInfo.Settings {
+				if setting.Key == "vcs.revision" {
+					st.GitCommit = setting.Value
+				}
+			}
+		}
+	}
+	return st
+}
+
+// readLockFile returns the PID acquireLock wrote and when it wrote it.
+func readLockFile(path string) (int, time.Time, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("lock file %s: %w", path, err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return pid, time.Time{}, err
+	}
+	return pid, info.ModTime(), nil
+}
+
+// Write prints st for a person to read. now is used to compute the uptime.
+func (st Status) Write(w io.Writer, now time.Time) error {
+	if !st.Running {
+		_, err := fmt.Fprintf(w, "Daemon is not running (nothing answered on %s)\n", st.SocketPath)
+		return err
+	}
+	lines := []string{"Daemon is running"}
+	if st.PID != 0 {
+		lines = append(lines, fmt.Sprintf("PID:      %d", st.PID))
+	}
+	lines = append(lines, "Socket:   "+st.SocketPath)
+	if !st.StartedAt.IsZero() {
+		lines = append(lines, fmt.Sprintf("Uptime:   %s (since %s)", now.Sub(st.StartedAt).Round(time.Second), st.StartedAt.Local().Format(time.RFC3339)))
+	}
+	if st.GitCommit != "" {
+		lines = append(lines, "Version:  "+st.GitCommit)
+	}
+	if st.LogFile != "" {
+		lines = append(lines, "Log file: "+st.LogFile)
+	}
+	_, err := fmt.Fprintln(w, strings.Join(lines, "\n"))
+	return err
+}
+
+// logFollowInterval is how often TailLog checks the log for new lines when following.
+var logFollowInterval = 500 * time.Millisecond
+
+// TailLog writes the last n lines of the log at path to w. With follow, it
+// then keeps writing lines as they are appended, picking up the new file when
+// the log is rotated, until ctx is done.
+func TailLog(ctx context.Context, path string, w io.Writer, n int, follow bool) error {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("no daemon log at %s (pass the --log-file sandd was started with)", path)
+	}
+	if err != nil {
+		return err
+	}
+	defer func() { f.Close() }()
+
+	offset, err := writeLastLines(f, w, n)
+	if err != nil || !follow {
+		return err
+	}
+
+	ticker := time.NewTicker(logFollowInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		current, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		if latest, err := os.Stat(path); err == nil && !os.SameFile(current, latest) {
+			// Rotated: finish the old file, then start on the new one.
+			if _, err := copyFrom(f, w, offset); err != nil {
+				return err
+			}
+			newFile, err := os.Open(path)
+			if err != nil {
+				continue
+			}
+			f.Close()
+			f, offset = newFile, 0
+			if current, err = f.Stat(); err != nil {
+				return err
+			}
+		}
+		if current.Size() < offset {
+			// Truncated in place.
+			offset = 0
+		}
+		if offset, err = copyFrom(f, w, offset); err != nil {
+			return err
+		}
+	}
+}
+
+// copyFrom copies f from offset to its current end and returns the new offset.
+func copyFrom(f *os.File, w io.Writer, offset int64) (int64, error) {
+	written, err := io.Copy(w, io.NewSectionReader(f, offset, 1<<62))
+	return offset + written, err
+}
+
+// writeLastLines writes the last n lines of f to w and returns the offset of
+// the end of the file. It reads backwards so a large log isn't read in full.
+func writeLastLines(f *os.File, w io.Writer, n int) (int64, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	size := info.Size()
+	start := int64(0)
+	if n > 0 {
+		const blockSize = 64 * 1024
+		buf := make([]byte, blockSize)
+		newlines := 0
+		pos := size
+	scan:
+		for pos > 0 {
+			readSize := min(int64(blockSize), pos)
+			pos -= readSize
+			if _, err := f.ReadAt(buf[:readSize], pos); err != nil {
+				return 0, err
+			}
+			for i := readSize - 1; i >= 0; i-- {
+				// The newline ending the final line doesn't start a new one.
+				if buf[i] != '\n' || pos+i == size-1 {
+					continue
+				}
+				newlines++
+				if newlines == n {
+					start = pos + i + 1
+					break scan
+				}
+			}
+		}
+	}
+	return copyFrom(f, w, start)
+}
@@ -0,0 +1,179 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/banksean/sand/internal/version"
+)
+
+type statusTestClient struct {
+	Client
+	pingErr error
+}
+
+func (c *statusTestClient) Ping(ctx context.Context) error {
+	return c.pingErr
+}
+
+func (c *statusTestClient) Version(ctx context.Context) (version.Info, error) {
+	return version.Info{GitCommit: "abc123"}, nil
+}
+
+func TestGetStatusRunningDaemon(t *testing.T) {
+	appBaseDir := t.TempDir()
+	lockPath := filepath.Join(appBaseDir, defaultLockFile)
+	if err := os.WriteFile(lockPath, []byte("4242\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	started := time.Now().Add(-90 * time.Minute).Truncate(time.Second)
+	if err := os.Chtimes(lockPath, started, started); err != nil {
+		t.Fatal(err)
+	}
+
+	st := GetStatus(context.Background(), appBaseDir, "/tmp/sand/daemon/log", &statusTestClient{})
+	if !st.Running || st.PID != 4242 || st.GitCommit != "abc123" || !st.StartedAt.Equal(started) {
+		t.Fatalf("GetStatus() = %+v, want running pid 4242 at commit abc123 started %v", st, started)
+	}
+
+	var out bytes.Buffer
+	if err := st.Write(&out, started.Add(90*time.Minute)); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	for _, want := range []string{"Daemon is running", "PID:      4242", filepath.Join(appBaseDir, DefaultGRPCSocketFile), "Uptime:   1h30m0s", "abc123", "/tmp/sand/daemon/log"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Write() output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestGetStatusAbsentDaemon(t *testing.T) {
+	appBaseDir := t.TempDir()
+	// A stale lock file from an earlier run shouldn't make the daemon look alive.
+	if err := os.WriteFile(filepath.Join(appBaseDir, defaultLockFile), []byte("4242\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for name, client := range map[string]Client{
+		"no connection": nil,
+		"ping fails":    &statusTestClient{pingErr: errors.New("connection refused")},
+	} {
+		t.Run(name, func(t *testing.T) {
+			st := GetStatus(context.Background(), appBaseDir, "", client)
+			if st.Running || st.PID != 0 {
+				t.Fatalf("GetStatus() = %+v, want not running", st)
+			}
+			var out bytes.Buffer
+			if err := st.Write(&out, time.Now()); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			want := "Daemon is not running (nothing answered on " + filepath.Join(appBaseDir, DefaultGRPCSocketFile) + ")\n"
+			if out.String() != want {
+				t.Fatalf("Write() = %q, want %q", out.String(), want)
+			}
+		})
+	}
+}
+
+func TestTailLogPrintsLastLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log")
+	if err := os.WriteFile(path, []byte("one\ntwo\nthree\nfour\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		lines int
+		want  string
+	}{
+		{lines: 2, want: "three\nfour\n"},
+		{lines: 10, want: "one\ntwo\nthree\nfour\n"},
+		{lines: 0, want: "one\ntwo\nthree\nfour\n"},
+	} {
+		var out bytes.Buffer
+		if err := TailLog(context.Background(), path, &out, tc.lines, false); err != nil {
+			t.Fatalf("TailLog(%d) error = %v", tc.lines, err)
+		}
+		if out.String() != tc.want {
+			t.Errorf("TailLog(%d) = %q, want %q", tc.lines, out.String(), tc.want)
+		}
+	}
+}
+
+func TestTailLogMissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log")
+	err := TailLog(context.Background(), path, &bytes.Buffer{}, 10, false)
+	if err == nil || !strings.Contains(err.Error(), "no daemon log at "+path) {
+		t.Fatalf("TailLog() error = %v, want missing log error", err)
+	}
+}
+
+// syncBuffer lets the test read what TailLog has written while it's still running.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestTailLogFollowsAppendsAndRotation(t *testing.T) {
+	oldInterval := logFollowInterval
+	logFollowInterval = 5 * time.Millisecond
+	t.Cleanup(func() { logFollowInterval = oldInterval })
+
+	path := filepath.Join(t.TempDir(), "log")
+	if err := os.WriteFile(path, []byte("old\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	out := &syncBuffer{}
+	done := make(chan error, 1)
+	go func() { done <- TailLog(ctx, path, out, 10, true) }()
+
+	waitFor := func(want string) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for out.String() != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("output = %q, want %q", out.String(), want)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+	waitFor("old\n")
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("appended\n")
+	f.Close()
+	waitFor("old\nappended\n")
+
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("rotated\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	waitFor("old\nappended\nrotated\n")
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("TailLog() error = %v", err)
+	}
+}
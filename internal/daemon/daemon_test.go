@@ -5,9 +5,12 @@ import (
 	"context"
 	"errors"
 	"io"
+	"net"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -44,6 +47,8 @@ type testImageOps struct {
 	ListFunc    func(context.Context) ([]sandtypes.ImageEntry, error)
 	PullFunc    func(context.Context, string, imageprogress.Sink) (func() error, error)
 	InspectFunc func(context.Context, string) ([]*sandtypes.ImageManifest, error)
+	BuildFunc   func(context.Context, *hostops.BuildImage, string, io.Writer) error
+	RemoveFunc  func(context.Context, string) error
 }
 
 func (m *testImageOps) List(ctx context.Context) ([]sandtypes.ImageEntry, error) {
@@ -67,6 +72,20 @@ func (m *testImageOps) Inspect(ctx context.Context, name string) ([]*sandtypes.I
 	return nil, nil
 }
 
+func (m *testImageOps) Build(ctx context.Context, opts *hostops.BuildImage, contextDir string, w io.Writer) error {
+	if m.BuildFunc != nil {
+		return m.BuildFunc(ctx, opts, contextDir, w)
+	}
+	return nil
+}
+
+func (m *testImageOps) Remove(ctx context.Context, name string) error {
+	if m.RemoveFunc != nil {
+		return m.RemoveFunc(ctx, name)
+	}
+	return nil
+}
+
 func TestDaemonStartsGRPCSocketOnlyForHostIPC(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "dmn-test-*")
 	if err != nil {
@@ -112,6 +131,246 @@ func TestDaemonStartsGRPCSocketOnlyForHostIPC(t *testing.T) {
 	dmn.Shutdown(ctx)
 }
 
+// newBlockingStatsDaemon returns a daemon whose Stats RPC signals started and
+// then blocks until release is closed or the request is cancelled.
+func newBlockingStatsDaemon(t *testing.T, appDir string, started, release chan struct{}) *Daemon {
+	t.Helper()
+	var once sync.Once
+	b, err := boxer.NewBoxerWithDeps(appDir, boxer.BoxerDeps{
+		ContainerService: &hostops.MockContainerOps{
+			StatsFunc: func(ctx context.Context, containerID ...string) ([]sandtypes.ContainerStats, error) {
+				once.Do(func() { close(started) })
+				select {
+				case <-release:
+					return []sandtypes.ContainerStats{{ID: "slow"}}, nil
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				}
+			},
+		},
+		ImageService: &testImageOps{},
+	})
+	if err != nil {
+		t.Fatalf("NewBoxerWithDeps: %v", err)
+	}
+	t.Cleanup(func() { b.Close() })
+	return NewDaemonWithBoxer(appDir, "test", b)
+}
+
+func TestDaemonShutdownWaitsForInFlightRequests(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "dmn-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	started, release := make(chan struct{}), make(chan struct{})
+	dmn := newBlockingStatsDaemon(t, tmpDir, started, release)
+	dmn.ShutdownGracePeriod = 10 * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	go func() {
+		if err := dmn.ServeUnixSocket(ctx); err != nil {
+			t.Logf("Mux serve error: %v", err)
+		}
+	}()
+	waitForSocket(t, dmn.GRPCSocketPath)
+
+	client, err := NewUnixSocketGRPCClient(ctx, tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create gRPC client: %v", err)
+	}
+	defer client.Close()
+
+	type statsResult struct {
+		stats []sandtypes.ContainerStats
+		err   error
+	}
+	statsDone := make(chan statsResult, 1)
+	go func() {
+		stats, err := client.Stats(ctx)
+		statsDone <- statsResult{stats, err}
+	}()
+	<-started
+
+	shutdownDone := make(chan struct{})
+	go func() {
+		dmn.Shutdown(ctx)
+		close(shutdownDone)
+	}()
+
+	// New connections are refused as soon as shutdown begins.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		conn, err := net.Dial("unix", dmn.GRPCSocketPath)
+		if err != nil {
+			break
+		}
+		conn.Close()
+		if time.Now().After(deadline) {
+			t.Fatal("daemon still accepting connections after Shutdown began")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	select {
+	case <-shutdownDone:
+		t.Fatal("Shutdown returned before the in-flight request finished")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(release)
+	res := <-statsDone
+	if res.err != nil {
+		t.Fatalf("in-flight Stats() error = %v, want it to complete", res.err)
+	}
+	if len(res.stats) != 1 || res.stats[0].ID != "slow" {
+		t.Fatalf("in-flight Stats() = %+v, want the slow handler's result", res.stats)
+	}
+	select {
+	case <-shutdownDone:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Shutdown did not return after the in-flight request finished")
+	}
+}
+
+func TestDaemonShutdownForcesCloseAfterGracePeriod(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "dmn-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	started, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+	dmn := newBlockingStatsDaemon(t, tmpDir, started, release)
+	dmn.ShutdownGracePeriod = 50 * time.Millisecond
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	go func() {
+		if err := dmn.ServeUnixSocket(ctx); err != nil {
+			t.Logf("Mux serve error: %v", err)
+		}
+	}()
+	waitForSocket(t, dmn.GRPCSocketPath)
+
+	client, err := NewUnixSocketGRPCClient(ctx, tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create gRPC client: %v", err)
+	}
+	defer client.Close()
+
+	statsErr := make(chan error, 1)
+	go func() {
+		_, err := client.Stats(ctx)
+		statsErr <- err
+	}()
+	<-started
+
+	shutdownDone := make(chan struct{})
+	go func() {
+		dmn.Shutdown(ctx)
+		close(shutdownDone)
+	}()
+	select {
+	case <-shutdownDone:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Shutdown did not force-close the slow request after the grace period")
+	}
+	if err := <-statsErr; err == nil {
+		t.Fatal("Stats() error = nil, want the request to be cut off")
+	}
+}
+
+func TestDaemonGRPCWatchStreamsEventsUntilShutdown(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "dmn-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	b, err := boxer.NewBoxerWithDeps(tmpDir, boxer.BoxerDeps{
+		ContainerService: &hostops.MockContainerOps{},
+		ImageService:     &testImageOps{},
+		GitOps:           &hostops.MockGitOps{},
+	})
+	if err != nil {
+		t.Fatalf("NewBoxerWithDeps: %v", err)
+	}
+	defer b.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := b.SaveSandbox(ctx, &sandtypes.Box{ID: "watched-id", Name: "watched", ContainerID: "ctr-1", SandboxWorkDir: t.TempDir()}); err != nil {
+		t.Fatalf("SaveSandbox() error = %v", err)
+	}
+	dmn := NewDaemonWithBoxer(tmpDir, "test", b)
+
+	go func() {
+		if err := dmn.ServeUnixSocket(ctx); err != nil {
+			t.Logf("Mux serve error: %v", err)
+		}
+	}()
+	waitForSocket(t, dmn.GRPCSocketPath)
+
+	client, err := NewUnixSocketGRPCClient(ctx, tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create gRPC client: %v", err)
+	}
+	defer client.Close()
+
+	events, err := client.Watch(ctx)
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+	if err := client.StopSandbox(ctx, "watched"); err != nil {
+		t.Fatalf("StopSandbox() error = %v", err)
+	}
+	select {
+	case event := <-events:
+		if event.Type != sandtypes.SandboxStopped || event.SandboxID != "watched-id" || event.SandboxName != "watched" {
+			t.Fatalf("event = %+v, want stopped for watched", event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event received after StopSandbox")
+	}
+
+	// An open watch must not hold up shutdown for the grace period.
+	dmn.ShutdownGracePeriod = time.Minute
+	shutdownDone := make(chan struct{})
+	go func() {
+		dmn.Shutdown(ctx)
+		close(shutdownDone)
+	}()
+	select {
+	case <-shutdownDone:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Shutdown waited on the open watch stream")
+	}
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Fatal("received event after shutdown, want closed channel")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Watch channel not closed after shutdown")
+	}
+}
+
+func TestCreateSandboxRejectsInvalidName(t *testing.T) {
+	d := newDaemonForTest(t, t.TempDir())
+	_, err := d.createSandbox(context.Background(), CreateSandboxOpts{Name: "Feature/Login Fix"}, io.Discard)
+	if err == nil {
+		t.Fatal("createSandbox() error = nil, want invalid name error")
+	}
+	if got := err.Error(); !strings.Contains(got, "is invalid") || !strings.Contains(got, `try "feature-login-fix"`) {
+		t.Fatalf("createSandbox() error = %v, want invalid name error with a suggestion", err)
+	}
+	if list, err := d.ListSandboxes(context.Background()); err != nil || len(list) != 0 {
+		t.Fatalf("ListSandboxes() = %v, %v; want no sandboxes", list, err)
+	}
+}
+
 func TestDaemonGRPCEnsureImage(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "dmn-test-*")
 	if err != nil {
@@ -136,7 +395,7 @@ func TestDaemonGRPCEnsureImage(t *testing.T) {
 	}
 	defer client.Close()
 
-	if err := client.EnsureImage(ctx, "test-image:latest", io.Discard); err != nil {
+	if err := client.EnsureImage(ctx, EnsureImageOpts{ImageName: "test-image:latest"}, io.Discard); err != nil {
 		t.Fatalf("gRPC EnsureImage() failed: %v", err)
 	}
 
@@ -192,7 +451,7 @@ func TestDaemonGRPCEnsureImageStreamsStructuredProgress(t *testing.T) {
 	defer client.Close()
 
 	var progress bytes.Buffer
-	if err := client.EnsureImage(ctx, "test-image:latest", &progress); err != nil {
+	if err := client.EnsureImage(ctx, EnsureImageOpts{ImageName: "test-image:latest"}, &progress); err != nil {
 		t.Fatalf("gRPC EnsureImage() failed: %v", err)
 	}
 	got := progress.String()
@@ -203,6 +462,76 @@ func TestDaemonGRPCEnsureImageStreamsStructuredProgress(t *testing.T) {
 	dmn.Shutdown(ctx)
 }
 
+func TestDaemonGRPCEnsureImageStreamsBuildLogs(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		buildErr error
+		wantLogs string
+	}{
+		{name: "success", wantLogs: "#1 FROM alpine\n#2 RUN apk add git\n#3 DONE\n"},
+		{name: "failure", buildErr: errors.New("exit status 1"), wantLogs: "#1 FROM alpine\n#2 RUN apk add git\n"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir, err := os.MkdirTemp("", "sand-*")
+			if err != nil {
+				t.Fatalf("MkdirTemp: %v", err)
+			}
+			t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+			dockerfileDir := t.TempDir()
+
+			b, err := boxer.NewBoxerWithDeps(tmpDir, boxer.BoxerDeps{
+				ContainerService: &hostops.MockContainerOps{},
+				ImageService: &testImageOps{
+					BuildFunc: func(ctx context.Context, opts *hostops.BuildImage, contextDir string, w io.Writer) error {
+						// Each write is its own message on the stream.
+						for _, line := range strings.SplitAfter(tt.wantLogs, "\n") {
+							if line != "" {
+								io.WriteString(w, line)
+							}
+						}
+						return tt.buildErr
+					},
+				},
+			})
+			if err != nil {
+				t.Fatalf("NewBoxerWithDeps: %v", err)
+			}
+			t.Cleanup(func() { b.Close() })
+			dmn := NewDaemonWithBoxer(tmpDir, "test", b)
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			go func() {
+				if err := dmn.ServeUnixSocket(ctx); err != nil {
+					t.Logf("Mux serve error: %v", err)
+				}
+			}()
+			waitForSocket(t, filepath.Join(tmpDir, DefaultGRPCSocketFile))
+
+			client, err := NewUnixSocketGRPCClient(ctx, tmpDir)
+			if err != nil {
+				t.Fatalf("Failed to create gRPC client: %v", err)
+			}
+			defer client.Close()
+
+			var progress bytes.Buffer
+			err = client.EnsureImage(ctx, EnsureImageOpts{ImageName: "sand-local/box:latest", DockerfileDir: dockerfileDir}, &progress)
+			if tt.buildErr == nil && err != nil {
+				t.Fatalf("gRPC EnsureImage() failed: %v", err)
+			}
+			if tt.buildErr != nil && (err == nil || !strings.Contains(err.Error(), "failed to build image sand-local/box:latest") || !strings.Contains(err.Error(), tt.buildErr.Error())) {
+				t.Fatalf("gRPC EnsureImage() error = %v, want the build failure", err)
+			}
+			want := "Building sand-local/box:latest from " + dockerfileDir + "\n" + tt.wantLogs
+			if got := progress.String(); got != want {
+				t.Fatalf("EnsureImage() progress = %q, want build logs in order %q", got, want)
+			}
+
+			dmn.Shutdown(ctx)
+		})
+	}
+}
+
 func TestDaemonGRPCCreateSandboxStreamsError(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "dmn-test-*")
 	if err != nil {
@@ -244,6 +573,38 @@ func TestDaemonGRPCCreateSandboxStreamsError(t *testing.T) {
 	dmn.Shutdown(ctx)
 }
 
+func TestCreateSandboxDiscardsSandboxWhenContainerCreateFails(t *testing.T) {
+	ctx := context.Background()
+	createErr := errors.New("container create failed")
+	d := newRequirementTestDaemon(t, requirementTestRegistry(), &hostops.MockContainerOps{
+		CreateFunc: func(ctx context.Context, _ *hostops.CreateContainer, image string, args []string) (string, error) {
+			return "", createErr
+		},
+		InspectFunc: func(ctx context.Context, containerID string) ([]sandtypes.Container, error) {
+			return nil, nil
+		},
+	})
+	d.runtime.ImageService = &testImageOps{InspectFunc: func(context.Context, string) ([]*sandtypes.ImageManifest, error) {
+		return []*sandtypes.ImageManifest{{Variants: []sandtypes.ImageVariant{{
+			Config: sandtypes.ImageVariantConfig{Config: sandtypes.ImageVariantContainerConfig{Cmd: []string{"sleep", "infinity"}}},
+		}}}}, nil
+	}}
+	t.Cleanup(func() { _ = removeSocketFiles("rollback-box") })
+
+	_, err := d.createSandbox(ctx, CreateSandboxOpts{ID: "rollback-box", ImageName: "test-image:latest"}, io.Discard)
+	if !errors.Is(err, createErr) {
+		t.Fatalf("createSandbox() error = %v, want %v", err, createErr)
+	}
+	if sbox, err := d.boxer.Get(ctx, "rollback-box"); err != nil || sbox != nil {
+		t.Fatalf("Get() = %v, %v; want the failed sandbox discarded", sbox, err)
+	}
+	for _, socketPath := range []string{runtimepaths.ContainerHTTPSocketPath("rollback-box"), runtimepaths.ContainerGRPCSocketPath("rollback-box")} {
+		if _, err := os.Stat(socketPath); !os.IsNotExist(err) {
+			t.Errorf("socket %s still exists after failed create: %v", socketPath, err)
+		}
+	}
+}
+
 func TestDaemonCreatesContainerHTTPAndGRPCSockets(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "dmn-test-*")
 	if err != nil {
@@ -392,6 +753,159 @@ func TestStartSandboxRecreatesStoppedContainerAfterSocketCreation(t *testing.T)
 	}
 }
 
+// imageChangeTestSandbox saves a sandbox whose running container has
+// ssh-agent forwarding and whose clone holds a marker file, and returns a
+// daemon over it and the clone's directory. create stands in for creating
+// the replacement container.
+func imageChangeTestSandbox(t *testing.T, sandboxID string, create func(image string) (string, error)) (*Daemon, *boxer.Boxer, string) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	ctx := context.Background()
+	t.Cleanup(func() {
+		_ = os.Remove(runtimepaths.ContainerHTTPSocketPath(sandboxID))
+		_ = os.Remove(runtimepaths.ContainerGRPCSocketPath(sandboxID))
+	})
+	containerSvc := &hostops.MockContainerOps{
+		InspectFunc: func(_ context.Context, containerID string) ([]sandtypes.Container, error) {
+			return []sandtypes.Container{{
+				Status:        sandtypes.ContainerStatus{State: "running"},
+				Configuration: sandtypes.ContainerConfig{SSH: true},
+			}}, nil
+		},
+		CreateFunc: func(_ context.Context, opts *hostops.CreateContainer, image string, _ []string) (string, error) {
+			if !opts.ManagementOptions.SSH {
+				t.Error("replacement container did not keep ssh-agent forwarding")
+			}
+			return create(image)
+		},
+		StartFunc: func(_ context.Context, _ *hostops.StartContainer, containerID string) (string, error) {
+			return "started", nil
+		},
+	}
+	registry := agents.NewAgentRegistry()
+	registry.Register(&agents.AgentConfig{
+		Name:          "default",
+		Configuration: noHookContainerConfig{},
+	})
+	b, err := boxer.NewBoxerWithDeps(tmpDir, boxer.BoxerDeps{
+		ContainerService: containerSvc,
+		ImageService:     &testImageOps{},
+		GitOps:           &hostops.MockGitOps{},
+		AgentRegistry:    registry,
+	})
+	if err != nil {
+		t.Fatalf("NewBoxerWithDeps: %v", err)
+	}
+	t.Cleanup(func() { b.Close() })
+
+	workDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(workDir, "app"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(workDir, "app", "work.txt"), []byte("uncommitted"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.SaveSandbox(ctx, &sandtypes.Box{
+		ID:                    sandboxID,
+		Name:                  sandboxID,
+		AgentType:             "default",
+		ContainerID:           "old-container",
+		ContainerBootstrapped: true,
+		StartHooksRan:         true,
+		HostOriginDir:         t.TempDir(),
+		SandboxWorkDir:        workDir,
+		ImageName:             "old-image:latest",
+	}); err != nil {
+		t.Fatalf("SaveSandbox: %v", err)
+	}
+	return NewDaemonWithBoxer(tmpDir, "test", b), b, workDir
+}
+
+func TestStartSandboxWithNewImageRecreatesContainerKeepingTheClone(t *testing.T) {
+	ctx := context.Background()
+	var createdFrom []string
+	dmn, b, workDir := imageChangeTestSandbox(t, "reimage", func(image string) (string, error) {
+		createdFrom = append(createdFrom, image)
+		return "new-container", nil
+	})
+
+	if err := dmn.StartSandbox(ctx, StartSandboxOpts{ID: "reimage", Image: "new-image:latest"}); err != nil {
+		t.Fatalf("StartSandbox() error = %v", err)
+	}
+
+	if len(createdFrom) != 1 || createdFrom[0] != "new-image:latest" {
+		t.Fatalf("containers created from %v, want [new-image:latest]", createdFrom)
+	}
+	loaded, err := b.Get(ctx, "reimage")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if loaded.ID != "reimage" || loaded.SandboxWorkDir != workDir {
+		t.Fatalf("sandbox = %q in %q, want reimage in %q", loaded.ID, loaded.SandboxWorkDir, workDir)
+	}
+	if loaded.ImageName != "new-image:latest" || loaded.ContainerID != "new-container" {
+		t.Fatalf("sandbox has image %q, container %q; want new-image:latest, new-container", loaded.ImageName, loaded.ContainerID)
+	}
+	if data, err := os.ReadFile(filepath.Join(workDir, "app", "work.txt")); err != nil || string(data) != "uncommitted" {
+		t.Fatalf("clone's work.txt = %q, %v; want it untouched", data, err)
+	}
+
+	// Asking for the image it is already on starts the sandbox as usual.
+	createdFrom = nil
+	if err := dmn.StartSandbox(ctx, StartSandboxOpts{ID: "reimage", Image: "new-image:latest"}); err != nil {
+		t.Fatalf("second StartSandbox() error = %v", err)
+	}
+	if len(createdFrom) != 0 {
+		t.Fatalf("containers created from %v, want none for the sandbox's own image", createdFrom)
+	}
+}
+
+func TestStartSandboxWithNewImageKeepsOldImageWhenCreateFails(t *testing.T) {
+	ctx := context.Background()
+	dmn, b, _ := imageChangeTestSandbox(t, "reimage-fail", func(string) (string, error) {
+		return "", errors.New("no such image")
+	})
+
+	err := dmn.StartSandbox(ctx, StartSandboxOpts{ID: "reimage-fail", Image: "new-image:latest"})
+	if err == nil || !strings.Contains(err.Error(), "no such image") || !strings.Contains(err.Error(), "old container was removed") {
+		t.Fatalf("StartSandbox() error = %v, want the create failure and that the old container is gone", err)
+	}
+	loaded, err := b.Get(ctx, "reimage-fail")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if loaded.ImageName != "old-image:latest" {
+		t.Fatalf("ImageName = %q, want old-image:latest to be kept", loaded.ImageName)
+	}
+}
+
+func TestStartSandboxWithNewImageRecreatesTheOldContainerWhenCreateFails(t *testing.T) {
+	ctx := context.Background()
+	var createdFrom []string
+	dmn, b, workDir := imageChangeTestSandbox(t, "reimage-restore", func(image string) (string, error) {
+		createdFrom = append(createdFrom, image)
+		if image == "new-image:latest" {
+			return "", errors.New("image has no arm64 variant")
+		}
+		return "restored-container", nil
+	})
+
+	err := dmn.StartSandbox(ctx, StartSandboxOpts{ID: "reimage-restore", Image: "new-image:latest"})
+	if err == nil || !strings.Contains(err.Error(), "no arm64 variant") || !strings.Contains(err.Error(), "recreated from old-image:latest") {
+		t.Fatalf("StartSandbox() error = %v, want the create failure and that the container was recreated", err)
+	}
+	if want := []string{"new-image:latest", "old-image:latest"}; !slices.Equal(createdFrom, want) {
+		t.Fatalf("containers created from %v, want %v", createdFrom, want)
+	}
+	loaded, err := b.Get(ctx, "reimage-restore")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if loaded.ImageName != "old-image:latest" || loaded.ContainerID != "restored-container" || loaded.SandboxWorkDir != workDir {
+		t.Fatalf("sandbox has image %q, container %q in %q; want old-image:latest, restored-container in %q", loaded.ImageName, loaded.ContainerID, loaded.SandboxWorkDir, workDir)
+	}
+}
+
 func TestStartSandboxRunsFirstStartHooksForUnbootstrappedContainer(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "sdt-*")
 	if err != nil {
@@ -551,6 +1065,135 @@ func TestStartSandboxRunsRestartHooksForBootstrappedContainer(t *testing.T) {
 	}
 }
 
+func TestStartSandboxRunsHooksOncePerContainerRun(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "sdt-*")
+	if err != nil {
+		t.Fatalf("MkdirTemp: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	ctx := context.Background()
+	sandboxID := "shared-shells"
+	t.Cleanup(func() {
+		_ = os.Remove(runtimepaths.ContainerHTTPSocketPath(sandboxID))
+		_ = os.Remove(runtimepaths.ContainerGRPCSocketPath(sandboxID))
+	})
+
+	var mu sync.Mutex
+	state := "stopped"
+	var execCalls []string
+	containerSvc := &hostops.MockContainerOps{
+		InspectFunc: func(_ context.Context, containerID string) ([]sandtypes.Container, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			return []sandtypes.Container{{
+				Status:        sandtypes.ContainerStatus{State: state},
+				Configuration: sandtypes.ContainerConfig{SSH: true},
+			}}, nil
+		},
+		StartFunc: func(_ context.Context, _ *hostops.StartContainer, containerID string) (string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			state = "running"
+			return "started", nil
+		},
+		StopFunc: func(_ context.Context, _ *hostops.StopContainer, containerID string) (string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			state = "stopped"
+			return "stopped", nil
+		},
+		ExecFunc: func(ctx context.Context, opts *hostops.ExecContainer, containerID, cmd string, env []string, args ...string) (string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			execCalls = append(execCalls, strings.Join(append([]string{cmd}, args...), " "))
+			return "", nil
+		},
+	}
+	hookRuns := func(hook string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		n := 0
+		for _, call := range execCalls {
+			if call == hook {
+				n++
+			}
+		}
+		return n
+	}
+
+	registry := agents.NewAgentRegistry()
+	registry.Register(&agents.AgentConfig{
+		Name:          "default",
+		Configuration: testBootstrapContainerConfig{},
+	})
+	b, err := boxer.NewBoxerWithDeps(tmpDir, boxer.BoxerDeps{
+		ContainerService: containerSvc,
+		ImageService:     &testImageOps{},
+		GitOps:           &hostops.MockGitOps{},
+		AgentRegistry:    registry,
+	})
+	if err != nil {
+		t.Fatalf("NewBoxerWithDeps: %v", err)
+	}
+	defer b.Close()
+
+	if err := b.SaveSandbox(ctx, &sandtypes.Box{
+		ID:                    sandboxID,
+		Name:                  sandboxID,
+		AgentType:             "default",
+		ContainerID:           "container-id",
+		ContainerBootstrapped: true,
+		HostOriginDir:         t.TempDir(),
+		SandboxWorkDir:        t.TempDir(),
+		ImageName:             "test-image:latest",
+	}); err != nil {
+		t.Fatalf("SaveSandbox: %v", err)
+	}
+
+	dmn := NewDaemonWithBoxer(tmpDir, "test", b)
+	// Two shells opened at once into the stopped sandbox.
+	var wg sync.WaitGroup
+	errs := make(chan error, 2)
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- dmn.StartSandbox(ctx, StartSandboxOpts{ID: sandboxID})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("StartSandbox() error = %v", err)
+		}
+	}
+	// A later shell into the now-running sandbox.
+	if err := dmn.StartSandbox(ctx, StartSandboxOpts{ID: sandboxID}); err != nil {
+		t.Fatalf("StartSandbox() on running sandbox error = %v", err)
+	}
+	if got := hookRuns("restart-hook"); got != 1 {
+		t.Fatalf("restart-hook ran %d times across three starts, want 1 (exec calls %v)", got, execCalls)
+	}
+
+	loaded, err := b.Get(ctx, sandboxID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if !loaded.StartHooksRan {
+		t.Fatal("StartHooksRan = false, want true while running")
+	}
+	if err := b.StopContainer(ctx, loaded); err != nil {
+		t.Fatalf("StopContainer() error = %v", err)
+	}
+	if err := dmn.StartSandbox(ctx, StartSandboxOpts{ID: sandboxID}); err != nil {
+		t.Fatalf("StartSandbox() after stop error = %v", err)
+	}
+	if got := hookRuns("restart-hook"); got != 2 {
+		t.Fatalf("restart-hook ran %d times after stop and start, want 2", got)
+	}
+}
+
 func TestStartSandboxLeavesContainerUnbootstrappedWhenFirstStartHookFails(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "sdt-*")
 	if err != nil {
@@ -627,6 +1270,10 @@ func (noHookContainerConfig) GetStartHooks(containerruntime.Artifacts) []sandtyp
 	return nil
 }
 
+func (noHookContainerConfig) GetStopHooks(containerruntime.Artifacts) []sandtypes.ContainerHook {
+	return nil
+}
+
 type testBootstrapContainerConfig struct {
 	firstStartErr error
 }
@@ -656,6 +1303,10 @@ func (testBootstrapContainerConfig) GetStartHooks(containerruntime.Artifacts) []
 	}
 }
 
+func (testBootstrapContainerConfig) GetStopHooks(containerruntime.Artifacts) []sandtypes.ContainerHook {
+	return nil
+}
+
 func containsString(values []string, want string) bool {
 	for _, value := range values {
 		if value == want {
@@ -799,3 +1450,78 @@ func TestDaemonPingNotRunning(t *testing.T) {
 	}
 	t.Logf("Ping failed as expected: %v", err)
 }
+
+func TestStartSandboxServesSocketsOfARunningSandboxAfterRestart(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "sdt-*")
+	if err != nil {
+		t.Fatalf("MkdirTemp: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	ctx := context.Background()
+	sandboxID := "still-running"
+	t.Cleanup(func() {
+		_ = os.Remove(runtimepaths.ContainerHTTPSocketPath(sandboxID))
+		_ = os.Remove(runtimepaths.ContainerGRPCSocketPath(sandboxID))
+	})
+
+	containerSvc := &hostops.MockContainerOps{
+		InspectFunc: func(_ context.Context, containerID string) ([]sandtypes.Container, error) {
+			return []sandtypes.Container{{Status: sandtypes.ContainerStatus{State: "running"}}}, nil
+		},
+		StartFunc: func(_ context.Context, _ *hostops.StartContainer, containerID string) (string, error) {
+			t.Error("Start called for a running sandbox")
+			return "", nil
+		},
+	}
+	hostRepo := t.TempDir()
+	var mirrored []string
+	gitOps := &hostops.MockGitOps{
+		TopLevelFunc: func(ctx context.Context, dir string) string { return hostRepo },
+		CloneMirrorFunc: func(ctx context.Context, sourceDir, mirrorDir string) error {
+			mirrored = append(mirrored, sourceDir)
+			return nil
+		},
+	}
+	b, err := boxer.NewBoxerWithDeps(tmpDir, boxer.BoxerDeps{
+		ContainerService: containerSvc,
+		ImageService:     &testImageOps{},
+		GitOps:           gitOps,
+		FileOps:          &hostops.MockFileOps{StatFunc: os.Stat},
+		AgentRegistry:    agents.NewAgentRegistry(),
+	})
+	if err != nil {
+		t.Fatalf("NewBoxerWithDeps: %v", err)
+	}
+	defer b.Close()
+	if err := b.SaveSandbox(ctx, &sandtypes.Box{
+		ID:                    sandboxID,
+		Name:                  sandboxID,
+		ContainerID:           "container-id",
+		ContainerBootstrapped: true,
+		StartHooksRan:         true,
+		HostOriginDir:         hostRepo,
+		SandboxWorkDir:        t.TempDir(),
+		ImageName:             "test-image:latest",
+		OriginalGitDetails:    &sandtypes.GitDetails{Commit: "abc123"},
+	}); err != nil {
+		t.Fatalf("SaveSandbox: %v", err)
+	}
+
+	// A fresh daemon, as after sandd restarts, serves nothing for the sandbox.
+	dmn := NewDaemonWithBoxer(tmpDir, "test", b)
+	t.Cleanup(func() { _ = dmn.stopInnieServer(context.Background(), sandboxID) })
+	if err := dmn.StartSandbox(ctx, StartSandboxOpts{ID: sandboxID}); err != nil {
+		t.Fatalf("StartSandbox() error = %v", err)
+	}
+	if !dmn.servingInnie(sandboxID) {
+		t.Fatal("servingInnie() = false, want the running sandbox's sockets served again")
+	}
+	for _, path := range []string{runtimepaths.ContainerHTTPSocketPath(sandboxID), runtimepaths.ContainerGRPCSocketPath(sandboxID)} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Stat(%s) error = %v, want the socket created", path, err)
+		}
+	}
+	if len(mirrored) != 1 || mirrored[0] != hostRepo {
+		t.Fatalf("mirrored %v, want the host git mirror synced for a running sandbox", mirrored)
+	}
+}
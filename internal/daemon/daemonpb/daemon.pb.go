@@ -682,6 +682,7 @@ type StartSandboxRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	SshAgent      bool                   `protobuf:"varint,2,opt,name=ssh_agent,json=sshAgent,proto3" json:"ssh_agent,omitempty"`
+	Image         string                 `protobuf:"bytes,3,opt,name=image,proto3" json:"image,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -730,6 +731,65 @@ func (x *StartSandboxRequest) GetSshAgent() bool {
 	return false
 }
 
+func (x *StartSandboxRequest) GetImage() string {
+	if x != nil {
+		return x.Image
+	}
+	return ""
+}
+
+type KillSandboxRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Signal        string                 `protobuf:"bytes,2,opt,name=signal,proto3" json:"signal,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *KillSandboxRequest) Reset() {
+	*x = KillSandboxRequest{}
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *KillSandboxRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KillSandboxRequest) ProtoMessage() {}
+
+func (x *KillSandboxRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KillSandboxRequest.ProtoReflect.Descriptor instead.
+func (*KillSandboxRequest) Descriptor() ([]byte, []int) {
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *KillSandboxRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *KillSandboxRequest) GetSignal() string {
+	if x != nil {
+		return x.Signal
+	}
+	return ""
+}
+
 type SyncHostGitMirrorResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	MirrorPath    string                 `protobuf:"bytes,1,opt,name=mirror_path,json=mirrorPath,proto3" json:"mirror_path,omitempty"`
@@ -739,7 +799,7 @@ type SyncHostGitMirrorResponse struct {
 
 func (x *SyncHostGitMirrorResponse) Reset() {
 	*x = SyncHostGitMirrorResponse{}
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[15]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -751,7 +811,7 @@ func (x *SyncHostGitMirrorResponse) String() string {
 func (*SyncHostGitMirrorResponse) ProtoMessage() {}
 
 func (x *SyncHostGitMirrorResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[15]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -764,7 +824,7 @@ func (x *SyncHostGitMirrorResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SyncHostGitMirrorResponse.ProtoReflect.Descriptor instead.
 func (*SyncHostGitMirrorResponse) Descriptor() ([]byte, []int) {
-	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{15}
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *SyncHostGitMirrorResponse) GetMirrorPath() string {
@@ -774,6 +834,266 @@ func (x *SyncHostGitMirrorResponse) GetMirrorPath() string {
 	return ""
 }
 
+type FetchHostChangesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Remote        string                 `protobuf:"bytes,2,opt,name=remote,proto3" json:"remote,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FetchHostChangesRequest) Reset() {
+	*x = FetchHostChangesRequest{}
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FetchHostChangesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FetchHostChangesRequest) ProtoMessage() {}
+
+func (x *FetchHostChangesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FetchHostChangesRequest.ProtoReflect.Descriptor instead.
+func (*FetchHostChangesRequest) Descriptor() ([]byte, []int) {
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *FetchHostChangesRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *FetchHostChangesRequest) GetRemote() string {
+	if x != nil {
+		return x.Remote
+	}
+	return ""
+}
+
+type FetchHostChangesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MirrorPath    string                 `protobuf:"bytes,1,opt,name=mirror_path,json=mirrorPath,proto3" json:"mirror_path,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FetchHostChangesResponse) Reset() {
+	*x = FetchHostChangesResponse{}
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FetchHostChangesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FetchHostChangesResponse) ProtoMessage() {}
+
+func (x *FetchHostChangesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FetchHostChangesResponse.ProtoReflect.Descriptor instead.
+func (*FetchHostChangesResponse) Descriptor() ([]byte, []int) {
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *FetchHostChangesResponse) GetMirrorPath() string {
+	if x != nil {
+		return x.MirrorPath
+	}
+	return ""
+}
+
+type ResyncWorkspaceResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Copied        []string               `protobuf:"bytes,1,rep,name=copied,proto3" json:"copied,omitempty"`
+	Conflicts     []string               `protobuf:"bytes,2,rep,name=conflicts,proto3" json:"conflicts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResyncWorkspaceResponse) Reset() {
+	*x = ResyncWorkspaceResponse{}
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResyncWorkspaceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResyncWorkspaceResponse) ProtoMessage() {}
+
+func (x *ResyncWorkspaceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResyncWorkspaceResponse.ProtoReflect.Descriptor instead.
+func (*ResyncWorkspaceResponse) Descriptor() ([]byte, []int) {
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ResyncWorkspaceResponse) GetCopied() []string {
+	if x != nil {
+		return x.Copied
+	}
+	return nil
+}
+
+func (x *ResyncWorkspaceResponse) GetConflicts() []string {
+	if x != nil {
+		return x.Conflicts
+	}
+	return nil
+}
+
+type WatchEventsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchEventsRequest) Reset() {
+	*x = WatchEventsRequest{}
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchEventsRequest) ProtoMessage() {}
+
+func (x *WatchEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchEventsRequest.ProtoReflect.Descriptor instead.
+func (*WatchEventsRequest) Descriptor() ([]byte, []int) {
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{20}
+}
+
+type SandboxEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	SandboxId     string                 `protobuf:"bytes,2,opt,name=sandbox_id,json=sandboxId,proto3" json:"sandbox_id,omitempty"`
+	SandboxName   string                 `protobuf:"bytes,3,opt,name=sandbox_name,json=sandboxName,proto3" json:"sandbox_name,omitempty"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=time,proto3" json:"time,omitempty"`
+	Error         string                 `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SandboxEvent) Reset() {
+	*x = SandboxEvent{}
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SandboxEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SandboxEvent) ProtoMessage() {}
+
+func (x *SandboxEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SandboxEvent.ProtoReflect.Descriptor instead.
+func (*SandboxEvent) Descriptor() ([]byte, []int) {
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *SandboxEvent) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *SandboxEvent) GetSandboxId() string {
+	if x != nil {
+		return x.SandboxId
+	}
+	return ""
+}
+
+func (x *SandboxEvent) GetSandboxName() string {
+	if x != nil {
+		return x.SandboxName
+	}
+	return ""
+}
+
+func (x *SandboxEvent) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *SandboxEvent) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
 type ResolveAgentLaunchEnvRequest struct {
 	state                protoimpl.MessageState `protogen:"open.v1"`
 	Agent                string                 `protobuf:"bytes,1,opt,name=agent,proto3" json:"agent,omitempty"`
@@ -787,7 +1107,7 @@ type ResolveAgentLaunchEnvRequest struct {
 
 func (x *ResolveAgentLaunchEnvRequest) Reset() {
 	*x = ResolveAgentLaunchEnvRequest{}
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[16]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[22]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -799,7 +1119,7 @@ func (x *ResolveAgentLaunchEnvRequest) String() string {
 func (*ResolveAgentLaunchEnvRequest) ProtoMessage() {}
 
 func (x *ResolveAgentLaunchEnvRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[16]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[22]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -812,7 +1132,7 @@ func (x *ResolveAgentLaunchEnvRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ResolveAgentLaunchEnvRequest.ProtoReflect.Descriptor instead.
 func (*ResolveAgentLaunchEnvRequest) Descriptor() ([]byte, []int) {
-	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{16}
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{22}
 }
 
 func (x *ResolveAgentLaunchEnvRequest) GetAgent() string {
@@ -859,7 +1179,7 @@ type ResolveAgentLaunchEnvResponse struct {
 
 func (x *ResolveAgentLaunchEnvResponse) Reset() {
 	*x = ResolveAgentLaunchEnvResponse{}
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[17]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[23]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -871,7 +1191,7 @@ func (x *ResolveAgentLaunchEnvResponse) String() string {
 func (*ResolveAgentLaunchEnvResponse) ProtoMessage() {}
 
 func (x *ResolveAgentLaunchEnvResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[17]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[23]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -884,7 +1204,7 @@ func (x *ResolveAgentLaunchEnvResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ResolveAgentLaunchEnvResponse.ProtoReflect.Descriptor instead.
 func (*ResolveAgentLaunchEnvResponse) Descriptor() ([]byte, []int) {
-	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{17}
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{23}
 }
 
 func (x *ResolveAgentLaunchEnvResponse) GetEnv() map[string]string {
@@ -904,7 +1224,7 @@ type EnvPolicy struct {
 
 func (x *EnvPolicy) Reset() {
 	*x = EnvPolicy{}
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[18]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[24]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -916,7 +1236,7 @@ func (x *EnvPolicy) String() string {
 func (*EnvPolicy) ProtoMessage() {}
 
 func (x *EnvPolicy) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[18]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[24]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -929,7 +1249,7 @@ func (x *EnvPolicy) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use EnvPolicy.ProtoReflect.Descriptor instead.
 func (*EnvPolicy) Descriptor() ([]byte, []int) {
-	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{18}
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{24}
 }
 
 func (x *EnvPolicy) GetFiles() []*EnvFileRef {
@@ -956,7 +1276,7 @@ type EnvFileRef struct {
 
 func (x *EnvFileRef) Reset() {
 	*x = EnvFileRef{}
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[19]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[25]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -968,7 +1288,7 @@ func (x *EnvFileRef) String() string {
 func (*EnvFileRef) ProtoMessage() {}
 
 func (x *EnvFileRef) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[19]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[25]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -981,7 +1301,7 @@ func (x *EnvFileRef) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use EnvFileRef.ProtoReflect.Descriptor instead.
 func (*EnvFileRef) Descriptor() ([]byte, []int) {
-	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{19}
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{25}
 }
 
 func (x *EnvFileRef) GetPath() string {
@@ -1008,7 +1328,7 @@ type EnvVarRule struct {
 
 func (x *EnvVarRule) Reset() {
 	*x = EnvVarRule{}
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[20]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[26]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1020,7 +1340,7 @@ func (x *EnvVarRule) String() string {
 func (*EnvVarRule) ProtoMessage() {}
 
 func (x *EnvVarRule) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[20]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[26]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1033,7 +1353,7 @@ func (x *EnvVarRule) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use EnvVarRule.ProtoReflect.Descriptor instead.
 func (*EnvVarRule) Descriptor() ([]byte, []int) {
-	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{20}
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{26}
 }
 
 func (x *EnvVarRule) GetName() string {
@@ -1060,7 +1380,7 @@ type ExportImageRequest struct {
 
 func (x *ExportImageRequest) Reset() {
 	*x = ExportImageRequest{}
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[21]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[27]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1072,7 +1392,7 @@ func (x *ExportImageRequest) String() string {
 func (*ExportImageRequest) ProtoMessage() {}
 
 func (x *ExportImageRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[21]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[27]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1085,7 +1405,7 @@ func (x *ExportImageRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ExportImageRequest.ProtoReflect.Descriptor instead.
 func (*ExportImageRequest) Descriptor() ([]byte, []int) {
-	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{21}
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{27}
 }
 
 func (x *ExportImageRequest) GetId() string {
@@ -1102,6 +1422,102 @@ func (x *ExportImageRequest) GetDestinationPath() string {
 	return ""
 }
 
+type PruneImagesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DryRun        bool                   `protobuf:"varint,1,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	Keep          []string               `protobuf:"bytes,2,rep,name=keep,proto3" json:"keep,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PruneImagesRequest) Reset() {
+	*x = PruneImagesRequest{}
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PruneImagesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PruneImagesRequest) ProtoMessage() {}
+
+func (x *PruneImagesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PruneImagesRequest.ProtoReflect.Descriptor instead.
+func (*PruneImagesRequest) Descriptor() ([]byte, []int) {
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *PruneImagesRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *PruneImagesRequest) GetKeep() []string {
+	if x != nil {
+		return x.Keep
+	}
+	return nil
+}
+
+type PruneImagesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Images        []string               `protobuf:"bytes,1,rep,name=images,proto3" json:"images,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PruneImagesResponse) Reset() {
+	*x = PruneImagesResponse{}
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PruneImagesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PruneImagesResponse) ProtoMessage() {}
+
+func (x *PruneImagesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PruneImagesResponse.ProtoReflect.Descriptor instead.
+func (*PruneImagesResponse) Descriptor() ([]byte, []int) {
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *PruneImagesResponse) GetImages() []string {
+	if x != nil {
+		return x.Images
+	}
+	return nil
+}
+
 type StatsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Ids           []string               `protobuf:"bytes,1,rep,name=ids,proto3" json:"ids,omitempty"`
@@ -1111,7 +1527,7 @@ type StatsRequest struct {
 
 func (x *StatsRequest) Reset() {
 	*x = StatsRequest{}
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[22]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[30]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1123,7 +1539,7 @@ func (x *StatsRequest) String() string {
 func (*StatsRequest) ProtoMessage() {}
 
 func (x *StatsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[22]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[30]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1136,7 +1552,7 @@ func (x *StatsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StatsRequest.ProtoReflect.Descriptor instead.
 func (*StatsRequest) Descriptor() ([]byte, []int) {
-	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{22}
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{30}
 }
 
 func (x *StatsRequest) GetIds() []string {
@@ -1155,7 +1571,7 @@ type StatsResponse struct {
 
 func (x *StatsResponse) Reset() {
 	*x = StatsResponse{}
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[23]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[31]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1167,7 +1583,7 @@ func (x *StatsResponse) String() string {
 func (*StatsResponse) ProtoMessage() {}
 
 func (x *StatsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[23]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[31]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1180,7 +1596,7 @@ func (x *StatsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StatsResponse.ProtoReflect.Descriptor instead.
 func (*StatsResponse) Descriptor() ([]byte, []int) {
-	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{23}
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{31}
 }
 
 func (x *StatsResponse) GetStats() []*ContainerStats {
@@ -1191,40 +1607,53 @@ func (x *StatsResponse) GetStats() []*ContainerStats {
 }
 
 type Sandbox struct {
-	state                 protoimpl.MessageState `protogen:"open.v1"`
-	Id                    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Name                  string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
-	State                 string                 `protobuf:"bytes,3,opt,name=state,proto3" json:"state,omitempty"`
-	AgentType             string                 `protobuf:"bytes,4,opt,name=agent_type,json=agentType,proto3" json:"agent_type,omitempty"`
-	ProfileName           string                 `protobuf:"bytes,5,opt,name=profile_name,json=profileName,proto3" json:"profile_name,omitempty"`
-	ContainerId           string                 `protobuf:"bytes,6,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
-	HostOriginDir         string                 `protobuf:"bytes,7,opt,name=host_origin_dir,json=hostOriginDir,proto3" json:"host_origin_dir,omitempty"`
-	SandboxWorkDir        string                 `protobuf:"bytes,8,opt,name=sandbox_work_dir,json=sandboxWorkDir,proto3" json:"sandbox_work_dir,omitempty"`
-	TrashWorkDir          string                 `protobuf:"bytes,9,opt,name=trash_work_dir,json=trashWorkDir,proto3" json:"trash_work_dir,omitempty"`
-	DeletedAt             *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"`
-	ImageName             string                 `protobuf:"bytes,11,opt,name=image_name,json=imageName,proto3" json:"image_name,omitempty"`
-	DnsDomain             string                 `protobuf:"bytes,12,opt,name=dns_domain,json=dnsDomain,proto3" json:"dns_domain,omitempty"`
-	EnvFile               string                 `protobuf:"bytes,13,opt,name=env_file,json=envFile,proto3" json:"env_file,omitempty"`
-	AllowedDomains        []string               `protobuf:"bytes,14,rep,name=allowed_domains,json=allowedDomains,proto3" json:"allowed_domains,omitempty"`
-	Mounts                []*MountSpec           `protobuf:"bytes,15,rep,name=mounts,proto3" json:"mounts,omitempty"`
-	MountRequests         []*MountRequest        `protobuf:"bytes,16,rep,name=mount_requests,json=mountRequests,proto3" json:"mount_requests,omitempty"`
-	SharedCacheMounts     *SharedCacheMounts     `protobuf:"bytes,17,opt,name=shared_cache_mounts,json=sharedCacheMounts,proto3" json:"shared_cache_mounts,omitempty"`
-	Cpus                  int32                  `protobuf:"varint,18,opt,name=cpus,proto3" json:"cpus,omitempty"`
-	MemoryMb              int32                  `protobuf:"varint,19,opt,name=memory_mb,json=memoryMb,proto3" json:"memory_mb,omitempty"`
-	SandboxWorkDirError   string                 `protobuf:"bytes,20,opt,name=sandbox_work_dir_error,json=sandboxWorkDirError,proto3" json:"sandbox_work_dir_error,omitempty"`
-	SandboxContainerError string                 `protobuf:"bytes,21,opt,name=sandbox_container_error,json=sandboxContainerError,proto3" json:"sandbox_container_error,omitempty"`
-	Username              string                 `protobuf:"bytes,22,opt,name=username,proto3" json:"username,omitempty"`
-	Uid                   string                 `protobuf:"bytes,23,opt,name=uid,proto3" json:"uid,omitempty"`
-	OriginalGitDetails    *GitDetails            `protobuf:"bytes,24,opt,name=original_git_details,json=originalGitDetails,proto3" json:"original_git_details,omitempty"`
-	CurrentGitDetails     *GitDetails            `protobuf:"bytes,25,opt,name=current_git_details,json=currentGitDetails,proto3" json:"current_git_details,omitempty"`
-	Container             *Container             `protobuf:"bytes,26,opt,name=container,proto3" json:"container,omitempty"`
-	unknownFields         protoimpl.UnknownFields
-	sizeCache             protoimpl.SizeCache
+	state                  protoimpl.MessageState `protogen:"open.v1"`
+	Id                     string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name                   string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	State                  string                 `protobuf:"bytes,3,opt,name=state,proto3" json:"state,omitempty"`
+	AgentType              string                 `protobuf:"bytes,4,opt,name=agent_type,json=agentType,proto3" json:"agent_type,omitempty"`
+	ProfileName            string                 `protobuf:"bytes,5,opt,name=profile_name,json=profileName,proto3" json:"profile_name,omitempty"`
+	ContainerId            string                 `protobuf:"bytes,6,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
+	HostOriginDir          string                 `protobuf:"bytes,7,opt,name=host_origin_dir,json=hostOriginDir,proto3" json:"host_origin_dir,omitempty"`
+	SandboxWorkDir         string                 `protobuf:"bytes,8,opt,name=sandbox_work_dir,json=sandboxWorkDir,proto3" json:"sandbox_work_dir,omitempty"`
+	TrashWorkDir           string                 `protobuf:"bytes,9,opt,name=trash_work_dir,json=trashWorkDir,proto3" json:"trash_work_dir,omitempty"`
+	DeletedAt              *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"`
+	ImageName              string                 `protobuf:"bytes,11,opt,name=image_name,json=imageName,proto3" json:"image_name,omitempty"`
+	DnsDomain              string                 `protobuf:"bytes,12,opt,name=dns_domain,json=dnsDomain,proto3" json:"dns_domain,omitempty"`
+	EnvFile                string                 `protobuf:"bytes,13,opt,name=env_file,json=envFile,proto3" json:"env_file,omitempty"`
+	AllowedDomains         []string               `protobuf:"bytes,14,rep,name=allowed_domains,json=allowedDomains,proto3" json:"allowed_domains,omitempty"`
+	Mounts                 []*MountSpec           `protobuf:"bytes,15,rep,name=mounts,proto3" json:"mounts,omitempty"`
+	MountRequests          []*MountRequest        `protobuf:"bytes,16,rep,name=mount_requests,json=mountRequests,proto3" json:"mount_requests,omitempty"`
+	SharedCacheMounts      *SharedCacheMounts     `protobuf:"bytes,17,opt,name=shared_cache_mounts,json=sharedCacheMounts,proto3" json:"shared_cache_mounts,omitempty"`
+	Cpus                   int32                  `protobuf:"varint,18,opt,name=cpus,proto3" json:"cpus,omitempty"`
+	MemoryMb               int32                  `protobuf:"varint,19,opt,name=memory_mb,json=memoryMb,proto3" json:"memory_mb,omitempty"`
+	SandboxWorkDirError    string                 `protobuf:"bytes,20,opt,name=sandbox_work_dir_error,json=sandboxWorkDirError,proto3" json:"sandbox_work_dir_error,omitempty"`
+	SandboxContainerError  string                 `protobuf:"bytes,21,opt,name=sandbox_container_error,json=sandboxContainerError,proto3" json:"sandbox_container_error,omitempty"`
+	Username               string                 `protobuf:"bytes,22,opt,name=username,proto3" json:"username,omitempty"`
+	Uid                    string                 `protobuf:"bytes,23,opt,name=uid,proto3" json:"uid,omitempty"`
+	OriginalGitDetails     *GitDetails            `protobuf:"bytes,24,opt,name=original_git_details,json=originalGitDetails,proto3" json:"original_git_details,omitempty"`
+	CurrentGitDetails      *GitDetails            `protobuf:"bytes,25,opt,name=current_git_details,json=currentGitDetails,proto3" json:"current_git_details,omitempty"`
+	Container              *Container             `protobuf:"bytes,26,opt,name=container,proto3" json:"container,omitempty"`
+	CreatedAt              *timestamppb.Timestamp `protobuf:"bytes,27,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	LastUsedAt             *timestamppb.Timestamp `protobuf:"bytes,28,opt,name=last_used_at,json=lastUsedAt,proto3" json:"last_used_at,omitempty"`
+	Labels                 map[string]string      `protobuf:"bytes,29,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Shell                  string                 `protobuf:"bytes,30,opt,name=shell,proto3" json:"shell,omitempty"`
+	ImageDigest            string                 `protobuf:"bytes,31,opt,name=image_digest,json=imageDigest,proto3" json:"image_digest,omitempty"`
+	Network                string                 `protobuf:"bytes,32,opt,name=network,proto3" json:"network,omitempty"`
+	NoDotfiles             bool                   `protobuf:"varint,33,opt,name=no_dotfiles,json=noDotfiles,proto3" json:"no_dotfiles,omitempty"`
+	NoSsh                  bool                   `protobuf:"varint,34,opt,name=no_ssh,json=noSsh,proto3" json:"no_ssh,omitempty"`
+	AllowEmulation         bool                   `protobuf:"varint,35,opt,name=allow_emulation,json=allowEmulation,proto3" json:"allow_emulation,omitempty"`
+	Publish                []string               `protobuf:"bytes,36,rep,name=publish,proto3" json:"publish,omitempty"`
+	SandboxResourceWarning string                 `protobuf:"bytes,37,opt,name=sandbox_resource_warning,json=sandboxResourceWarning,proto3" json:"sandbox_resource_warning,omitempty"`
+	Dns                    *DNSConfig             `protobuf:"bytes,38,opt,name=dns,proto3" json:"dns,omitempty"`
+	IgnoreOncreateErrors   bool                   `protobuf:"varint,39,opt,name=ignore_oncreate_errors,json=ignoreOncreateErrors,proto3" json:"ignore_oncreate_errors,omitempty"`
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
 }
 
 func (x *Sandbox) Reset() {
 	*x = Sandbox{}
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[24]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[32]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1236,7 +1665,7 @@ func (x *Sandbox) String() string {
 func (*Sandbox) ProtoMessage() {}
 
 func (x *Sandbox) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[24]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[32]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1249,7 +1678,7 @@ func (x *Sandbox) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Sandbox.ProtoReflect.Descriptor instead.
 func (*Sandbox) Descriptor() ([]byte, []int) {
-	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{24}
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{32}
 }
 
 func (x *Sandbox) GetId() string {
@@ -1387,53 +1816,212 @@ func (x *Sandbox) GetMemoryMb() int32 {
 
 func (x *Sandbox) GetSandboxWorkDirError() string {
 	if x != nil {
-		return x.SandboxWorkDirError
+		return x.SandboxWorkDirError
+	}
+	return ""
+}
+
+func (x *Sandbox) GetSandboxContainerError() string {
+	if x != nil {
+		return x.SandboxContainerError
+	}
+	return ""
+}
+
+func (x *Sandbox) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *Sandbox) GetUid() string {
+	if x != nil {
+		return x.Uid
+	}
+	return ""
+}
+
+func (x *Sandbox) GetOriginalGitDetails() *GitDetails {
+	if x != nil {
+		return x.OriginalGitDetails
+	}
+	return nil
+}
+
+func (x *Sandbox) GetCurrentGitDetails() *GitDetails {
+	if x != nil {
+		return x.CurrentGitDetails
+	}
+	return nil
+}
+
+func (x *Sandbox) GetContainer() *Container {
+	if x != nil {
+		return x.Container
+	}
+	return nil
+}
+
+func (x *Sandbox) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Sandbox) GetLastUsedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastUsedAt
+	}
+	return nil
+}
+
+func (x *Sandbox) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *Sandbox) GetShell() string {
+	if x != nil {
+		return x.Shell
+	}
+	return ""
+}
+
+func (x *Sandbox) GetImageDigest() string {
+	if x != nil {
+		return x.ImageDigest
+	}
+	return ""
+}
+
+func (x *Sandbox) GetNetwork() string {
+	if x != nil {
+		return x.Network
+	}
+	return ""
+}
+
+func (x *Sandbox) GetNoDotfiles() bool {
+	if x != nil {
+		return x.NoDotfiles
+	}
+	return false
+}
+
+func (x *Sandbox) GetNoSsh() bool {
+	if x != nil {
+		return x.NoSsh
+	}
+	return false
+}
+
+func (x *Sandbox) GetAllowEmulation() bool {
+	if x != nil {
+		return x.AllowEmulation
+	}
+	return false
+}
+
+func (x *Sandbox) GetPublish() []string {
+	if x != nil {
+		return x.Publish
+	}
+	return nil
+}
+
+func (x *Sandbox) GetSandboxResourceWarning() string {
+	if x != nil {
+		return x.SandboxResourceWarning
 	}
 	return ""
 }
 
-func (x *Sandbox) GetSandboxContainerError() string {
+func (x *Sandbox) GetDns() *DNSConfig {
 	if x != nil {
-		return x.SandboxContainerError
+		return x.Dns
 	}
-	return ""
+	return nil
 }
 
-func (x *Sandbox) GetUsername() string {
+func (x *Sandbox) GetIgnoreOncreateErrors() bool {
 	if x != nil {
-		return x.Username
+		return x.IgnoreOncreateErrors
 	}
-	return ""
+	return false
 }
 
-func (x *Sandbox) GetUid() string {
+type DNSConfig struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Nameservers   []string               `protobuf:"bytes,1,rep,name=nameservers,proto3" json:"nameservers,omitempty"`
+	SearchDomains []string               `protobuf:"bytes,2,rep,name=search_domains,json=searchDomains,proto3" json:"search_domains,omitempty"`
+	Options       []string               `protobuf:"bytes,3,rep,name=options,proto3" json:"options,omitempty"`
+	Disabled      bool                   `protobuf:"varint,4,opt,name=disabled,proto3" json:"disabled,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DNSConfig) Reset() {
+	*x = DNSConfig{}
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DNSConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DNSConfig) ProtoMessage() {}
+
+func (x *DNSConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[33]
 	if x != nil {
-		return x.Uid
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *Sandbox) GetOriginalGitDetails() *GitDetails {
+// Deprecated: Use DNSConfig.ProtoReflect.Descriptor instead.
+func (*DNSConfig) Descriptor() ([]byte, []int) {
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *DNSConfig) GetNameservers() []string {
 	if x != nil {
-		return x.OriginalGitDetails
+		return x.Nameservers
 	}
 	return nil
 }
 
-func (x *Sandbox) GetCurrentGitDetails() *GitDetails {
+func (x *DNSConfig) GetSearchDomains() []string {
 	if x != nil {
-		return x.CurrentGitDetails
+		return x.SearchDomains
 	}
 	return nil
 }
 
-func (x *Sandbox) GetContainer() *Container {
+func (x *DNSConfig) GetOptions() []string {
 	if x != nil {
-		return x.Container
+		return x.Options
 	}
 	return nil
 }
 
+func (x *DNSConfig) GetDisabled() bool {
+	if x != nil {
+		return x.Disabled
+	}
+	return false
+}
+
 type MountSpec struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Source        string                 `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
@@ -1445,7 +2033,7 @@ type MountSpec struct {
 
 func (x *MountSpec) Reset() {
 	*x = MountSpec{}
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[25]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[34]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1457,7 +2045,7 @@ func (x *MountSpec) String() string {
 func (*MountSpec) ProtoMessage() {}
 
 func (x *MountSpec) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[25]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[34]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1470,7 +2058,7 @@ func (x *MountSpec) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MountSpec.ProtoReflect.Descriptor instead.
 func (*MountSpec) Descriptor() ([]byte, []int) {
-	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{25}
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{34}
 }
 
 func (x *MountSpec) GetSource() string {
@@ -1509,7 +2097,7 @@ type MountRequest struct {
 
 func (x *MountRequest) Reset() {
 	*x = MountRequest{}
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[26]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[35]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1521,7 +2109,7 @@ func (x *MountRequest) String() string {
 func (*MountRequest) ProtoMessage() {}
 
 func (x *MountRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[26]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[35]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1534,7 +2122,7 @@ func (x *MountRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MountRequest.ProtoReflect.Descriptor instead.
 func (*MountRequest) Descriptor() ([]byte, []int) {
-	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{26}
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{35}
 }
 
 func (x *MountRequest) GetKind() string {
@@ -1600,7 +2188,7 @@ type SharedCacheMounts struct {
 
 func (x *SharedCacheMounts) Reset() {
 	*x = SharedCacheMounts{}
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[27]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[36]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1612,7 +2200,7 @@ func (x *SharedCacheMounts) String() string {
 func (*SharedCacheMounts) ProtoMessage() {}
 
 func (x *SharedCacheMounts) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[27]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[36]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1625,7 +2213,7 @@ func (x *SharedCacheMounts) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SharedCacheMounts.ProtoReflect.Descriptor instead.
 func (*SharedCacheMounts) Descriptor() ([]byte, []int) {
-	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{27}
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{36}
 }
 
 func (x *SharedCacheMounts) GetMiseCacheHostDir() string {
@@ -1685,7 +2273,7 @@ type GitDetails struct {
 
 func (x *GitDetails) Reset() {
 	*x = GitDetails{}
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[28]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[37]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1697,7 +2285,7 @@ func (x *GitDetails) String() string {
 func (*GitDetails) ProtoMessage() {}
 
 func (x *GitDetails) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[28]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[37]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1710,7 +2298,7 @@ func (x *GitDetails) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GitDetails.ProtoReflect.Descriptor instead.
 func (*GitDetails) Descriptor() ([]byte, []int) {
-	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{28}
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{37}
 }
 
 func (x *GitDetails) GetRemoteOrigin() string {
@@ -1773,7 +2361,7 @@ type Container struct {
 
 func (x *Container) Reset() {
 	*x = Container{}
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[29]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[38]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1785,7 +2373,7 @@ func (x *Container) String() string {
 func (*Container) ProtoMessage() {}
 
 func (x *Container) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[29]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[38]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1798,7 +2386,7 @@ func (x *Container) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Container.ProtoReflect.Descriptor instead.
 func (*Container) Descriptor() ([]byte, []int) {
-	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{29}
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{38}
 }
 
 func (x *Container) GetNetworks() []*ContainerNetworkStatus {
@@ -1836,7 +2424,7 @@ type ContainerNetworkStatus struct {
 
 func (x *ContainerNetworkStatus) Reset() {
 	*x = ContainerNetworkStatus{}
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[30]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[39]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1848,7 +2436,7 @@ func (x *ContainerNetworkStatus) String() string {
 func (*ContainerNetworkStatus) ProtoMessage() {}
 
 func (x *ContainerNetworkStatus) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[30]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[39]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1861,7 +2449,7 @@ func (x *ContainerNetworkStatus) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ContainerNetworkStatus.ProtoReflect.Descriptor instead.
 func (*ContainerNetworkStatus) Descriptor() ([]byte, []int) {
-	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{30}
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{39}
 }
 
 func (x *ContainerNetworkStatus) GetHostname() string {
@@ -1915,7 +2503,7 @@ type ContainerStatus struct {
 
 func (x *ContainerStatus) Reset() {
 	*x = ContainerStatus{}
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[31]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[40]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1927,7 +2515,7 @@ func (x *ContainerStatus) String() string {
 func (*ContainerStatus) ProtoMessage() {}
 
 func (x *ContainerStatus) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[31]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[40]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1940,7 +2528,7 @@ func (x *ContainerStatus) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ContainerStatus.ProtoReflect.Descriptor instead.
 func (*ContainerStatus) Descriptor() ([]byte, []int) {
-	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{31}
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{40}
 }
 
 func (x *ContainerStatus) GetState() string {
@@ -1970,7 +2558,7 @@ type ContainerConfig struct {
 
 func (x *ContainerConfig) Reset() {
 	*x = ContainerConfig{}
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[32]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[41]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1982,7 +2570,7 @@ func (x *ContainerConfig) String() string {
 func (*ContainerConfig) ProtoMessage() {}
 
 func (x *ContainerConfig) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[32]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[41]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1995,7 +2583,7 @@ func (x *ContainerConfig) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ContainerConfig.ProtoReflect.Descriptor instead.
 func (*ContainerConfig) Descriptor() ([]byte, []int) {
-	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{32}
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{41}
 }
 
 func (x *ContainerConfig) GetMounts() []*Mount {
@@ -2094,7 +2682,7 @@ type Mount struct {
 
 func (x *Mount) Reset() {
 	*x = Mount{}
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[33]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[42]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2106,7 +2694,7 @@ func (x *Mount) String() string {
 func (*Mount) ProtoMessage() {}
 
 func (x *Mount) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[33]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[42]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2119,7 +2707,7 @@ func (x *Mount) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Mount.ProtoReflect.Descriptor instead.
 func (*Mount) Descriptor() ([]byte, []int) {
-	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{33}
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{42}
 }
 
 func (x *Mount) GetType() *MountType {
@@ -2160,7 +2748,7 @@ type MountType struct {
 
 func (x *MountType) Reset() {
 	*x = MountType{}
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[34]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[43]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2172,7 +2760,7 @@ func (x *MountType) String() string {
 func (*MountType) ProtoMessage() {}
 
 func (x *MountType) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[34]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[43]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2185,7 +2773,7 @@ func (x *MountType) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MountType.ProtoReflect.Descriptor instead.
 func (*MountType) Descriptor() ([]byte, []int) {
-	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{34}
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{43}
 }
 
 func (x *MountType) GetTmpfs() bool {
@@ -2213,7 +2801,7 @@ type Platform struct {
 
 func (x *Platform) Reset() {
 	*x = Platform{}
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[35]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[44]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2225,7 +2813,7 @@ func (x *Platform) String() string {
 func (*Platform) ProtoMessage() {}
 
 func (x *Platform) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[35]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[44]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2238,7 +2826,7 @@ func (x *Platform) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Platform.ProtoReflect.Descriptor instead.
 func (*Platform) Descriptor() ([]byte, []int) {
-	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{35}
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{44}
 }
 
 func (x *Platform) GetOs() string {
@@ -2276,7 +2864,7 @@ type InitProcess struct {
 
 func (x *InitProcess) Reset() {
 	*x = InitProcess{}
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[36]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[45]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2288,7 +2876,7 @@ func (x *InitProcess) String() string {
 func (*InitProcess) ProtoMessage() {}
 
 func (x *InitProcess) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[36]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[45]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2301,7 +2889,7 @@ func (x *InitProcess) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use InitProcess.ProtoReflect.Descriptor instead.
 func (*InitProcess) Descriptor() ([]byte, []int) {
-	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{36}
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{45}
 }
 
 func (x *InitProcess) GetEnvironment() []string {
@@ -2355,7 +2943,7 @@ type User struct {
 
 func (x *User) Reset() {
 	*x = User{}
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[37]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[46]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2367,7 +2955,7 @@ func (x *User) String() string {
 func (*User) ProtoMessage() {}
 
 func (x *User) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[37]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[46]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2380,7 +2968,7 @@ func (x *User) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use User.ProtoReflect.Descriptor instead.
 func (*User) Descriptor() ([]byte, []int) {
-	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{37}
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{46}
 }
 
 func (x *User) GetId() *UserID {
@@ -2400,7 +2988,7 @@ type UserID struct {
 
 func (x *UserID) Reset() {
 	*x = UserID{}
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[38]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[47]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2412,7 +3000,7 @@ func (x *UserID) String() string {
 func (*UserID) ProtoMessage() {}
 
 func (x *UserID) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[38]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[47]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2425,7 +3013,7 @@ func (x *UserID) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UserID.ProtoReflect.Descriptor instead.
 func (*UserID) Descriptor() ([]byte, []int) {
-	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{38}
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{47}
 }
 
 func (x *UserID) GetUid() int32 {
@@ -2453,7 +3041,7 @@ type DNS struct {
 
 func (x *DNS) Reset() {
 	*x = DNS{}
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[39]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[48]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2465,7 +3053,7 @@ func (x *DNS) String() string {
 func (*DNS) ProtoMessage() {}
 
 func (x *DNS) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[39]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[48]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2478,7 +3066,7 @@ func (x *DNS) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DNS.ProtoReflect.Descriptor instead.
 func (*DNS) Descriptor() ([]byte, []int) {
-	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{39}
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{48}
 }
 
 func (x *DNS) GetOptions() []string {
@@ -2512,7 +3100,7 @@ type ContainerNetwork struct {
 
 func (x *ContainerNetwork) Reset() {
 	*x = ContainerNetwork{}
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[40]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[49]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2524,7 +3112,7 @@ func (x *ContainerNetwork) String() string {
 func (*ContainerNetwork) ProtoMessage() {}
 
 func (x *ContainerNetwork) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[40]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[49]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2537,7 +3125,7 @@ func (x *ContainerNetwork) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ContainerNetwork.ProtoReflect.Descriptor instead.
 func (*ContainerNetwork) Descriptor() ([]byte, []int) {
-	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{40}
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{49}
 }
 
 func (x *ContainerNetwork) GetOptions() *NetworkOptions {
@@ -2564,7 +3152,7 @@ type NetworkOptions struct {
 
 func (x *NetworkOptions) Reset() {
 	*x = NetworkOptions{}
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[41]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[50]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2576,7 +3164,7 @@ func (x *NetworkOptions) String() string {
 func (*NetworkOptions) ProtoMessage() {}
 
 func (x *NetworkOptions) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[41]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[50]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2589,7 +3177,7 @@ func (x *NetworkOptions) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use NetworkOptions.ProtoReflect.Descriptor instead.
 func (*NetworkOptions) Descriptor() ([]byte, []int) {
-	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{41}
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{50}
 }
 
 func (x *NetworkOptions) GetHostname() string {
@@ -2616,7 +3204,7 @@ type Image struct {
 
 func (x *Image) Reset() {
 	*x = Image{}
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[42]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[51]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2628,7 +3216,7 @@ func (x *Image) String() string {
 func (*Image) ProtoMessage() {}
 
 func (x *Image) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[42]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[51]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2641,7 +3229,7 @@ func (x *Image) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Image.ProtoReflect.Descriptor instead.
 func (*Image) Descriptor() ([]byte, []int) {
-	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{42}
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{51}
 }
 
 func (x *Image) GetReference() string {
@@ -2669,7 +3257,7 @@ type Descriptor struct {
 
 func (x *Descriptor) Reset() {
 	*x = Descriptor{}
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[43]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[52]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2681,7 +3269,7 @@ func (x *Descriptor) String() string {
 func (*Descriptor) ProtoMessage() {}
 
 func (x *Descriptor) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[43]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[52]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2694,7 +3282,7 @@ func (x *Descriptor) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Descriptor.ProtoReflect.Descriptor instead.
 func (*Descriptor) Descriptor() ([]byte, []int) {
-	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{43}
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{52}
 }
 
 func (x *Descriptor) GetDigest() string {
@@ -2728,7 +3316,7 @@ type Resources struct {
 
 func (x *Resources) Reset() {
 	*x = Resources{}
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[44]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[53]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2740,7 +3328,7 @@ func (x *Resources) String() string {
 func (*Resources) ProtoMessage() {}
 
 func (x *Resources) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[44]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[53]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2753,7 +3341,7 @@ func (x *Resources) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Resources.ProtoReflect.Descriptor instead.
 func (*Resources) Descriptor() ([]byte, []int) {
-	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{44}
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{53}
 }
 
 func (x *Resources) GetCpus() int32 {
@@ -2781,13 +3369,14 @@ type ContainerStats struct {
 	NetworkRxBytes   int64                  `protobuf:"varint,7,opt,name=network_rx_bytes,json=networkRxBytes,proto3" json:"network_rx_bytes,omitempty"`
 	NetworkTxBytes   int64                  `protobuf:"varint,8,opt,name=network_tx_bytes,json=networkTxBytes,proto3" json:"network_tx_bytes,omitempty"`
 	NumProcesses     int64                  `protobuf:"varint,9,opt,name=num_processes,json=numProcesses,proto3" json:"num_processes,omitempty"`
+	UptimeSeconds    int64                  `protobuf:"varint,10,opt,name=uptime_seconds,json=uptimeSeconds,proto3" json:"uptime_seconds,omitempty"`
 	unknownFields    protoimpl.UnknownFields
 	sizeCache        protoimpl.SizeCache
 }
 
 func (x *ContainerStats) Reset() {
 	*x = ContainerStats{}
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[45]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[54]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2799,7 +3388,7 @@ func (x *ContainerStats) String() string {
 func (*ContainerStats) ProtoMessage() {}
 
 func (x *ContainerStats) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[45]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[54]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2812,7 +3401,7 @@ func (x *ContainerStats) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ContainerStats.ProtoReflect.Descriptor instead.
 func (*ContainerStats) Descriptor() ([]byte, []int) {
-	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{45}
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{54}
 }
 
 func (x *ContainerStats) GetBlockReadBytes() int64 {
@@ -2878,6 +3467,13 @@ func (x *ContainerStats) GetNumProcesses() int64 {
 	return 0
 }
 
+func (x *ContainerStats) GetUptimeSeconds() int64 {
+	if x != nil {
+		return x.UptimeSeconds
+	}
+	return 0
+}
+
 type SharedCacheConfig struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Mise          bool                   `protobuf:"varint,1,opt,name=mise,proto3" json:"mise,omitempty"`
@@ -2891,7 +3487,7 @@ type SharedCacheConfig struct {
 
 func (x *SharedCacheConfig) Reset() {
 	*x = SharedCacheConfig{}
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[46]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[55]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2903,7 +3499,7 @@ func (x *SharedCacheConfig) String() string {
 func (*SharedCacheConfig) ProtoMessage() {}
 
 func (x *SharedCacheConfig) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[46]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[55]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2916,7 +3512,7 @@ func (x *SharedCacheConfig) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SharedCacheConfig.ProtoReflect.Descriptor instead.
 func (*SharedCacheConfig) Descriptor() ([]byte, []int) {
-	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{46}
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{55}
 }
 
 func (x *SharedCacheConfig) GetMise() bool {
@@ -2955,29 +3551,41 @@ func (x *SharedCacheConfig) GetHttpProxy() bool {
 }
 
 type CreateSandboxRequest struct {
-	state          protoimpl.MessageState `protogen:"open.v1"`
-	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	CloneFromDir   string                 `protobuf:"bytes,2,opt,name=clone_from_dir,json=cloneFromDir,proto3" json:"clone_from_dir,omitempty"`
-	ImageName      string                 `protobuf:"bytes,3,opt,name=image_name,json=imageName,proto3" json:"image_name,omitempty"`
-	EnvFile        string                 `protobuf:"bytes,4,opt,name=env_file,json=envFile,proto3" json:"env_file,omitempty"`
-	Agent          string                 `protobuf:"bytes,5,opt,name=agent,proto3" json:"agent,omitempty"`
-	SshAgent       bool                   `protobuf:"varint,6,opt,name=ssh_agent,json=sshAgent,proto3" json:"ssh_agent,omitempty"`
-	Username       string                 `protobuf:"bytes,7,opt,name=username,proto3" json:"username,omitempty"`
-	Uid            string                 `protobuf:"bytes,8,opt,name=uid,proto3" json:"uid,omitempty"`
-	AllowedDomains []string               `protobuf:"bytes,9,rep,name=allowed_domains,json=allowedDomains,proto3" json:"allowed_domains,omitempty"`
-	Mounts         []string               `protobuf:"bytes,10,rep,name=mounts,proto3" json:"mounts,omitempty"`
-	SharedCaches   *SharedCacheConfig     `protobuf:"bytes,11,opt,name=shared_caches,json=sharedCaches,proto3" json:"shared_caches,omitempty"`
-	Cpus           int32                  `protobuf:"varint,12,opt,name=cpus,proto3" json:"cpus,omitempty"`
-	Memory         int32                  `protobuf:"varint,13,opt,name=memory,proto3" json:"memory,omitempty"`
-	ProfileName    string                 `protobuf:"bytes,14,opt,name=profile_name,json=profileName,proto3" json:"profile_name,omitempty"`
-	CloneMounts    []string               `protobuf:"bytes,15,rep,name=clone_mounts,json=cloneMounts,proto3" json:"clone_mounts,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	Id                   string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	CloneFromDir         string                 `protobuf:"bytes,2,opt,name=clone_from_dir,json=cloneFromDir,proto3" json:"clone_from_dir,omitempty"`
+	ImageName            string                 `protobuf:"bytes,3,opt,name=image_name,json=imageName,proto3" json:"image_name,omitempty"`
+	EnvFile              string                 `protobuf:"bytes,4,opt,name=env_file,json=envFile,proto3" json:"env_file,omitempty"`
+	Agent                string                 `protobuf:"bytes,5,opt,name=agent,proto3" json:"agent,omitempty"`
+	SshAgent             bool                   `protobuf:"varint,6,opt,name=ssh_agent,json=sshAgent,proto3" json:"ssh_agent,omitempty"`
+	Username             string                 `protobuf:"bytes,7,opt,name=username,proto3" json:"username,omitempty"`
+	Uid                  string                 `protobuf:"bytes,8,opt,name=uid,proto3" json:"uid,omitempty"`
+	AllowedDomains       []string               `protobuf:"bytes,9,rep,name=allowed_domains,json=allowedDomains,proto3" json:"allowed_domains,omitempty"`
+	Mounts               []string               `protobuf:"bytes,10,rep,name=mounts,proto3" json:"mounts,omitempty"`
+	SharedCaches         *SharedCacheConfig     `protobuf:"bytes,11,opt,name=shared_caches,json=sharedCaches,proto3" json:"shared_caches,omitempty"`
+	Cpus                 int32                  `protobuf:"varint,12,opt,name=cpus,proto3" json:"cpus,omitempty"`
+	Memory               int32                  `protobuf:"varint,13,opt,name=memory,proto3" json:"memory,omitempty"`
+	ProfileName          string                 `protobuf:"bytes,14,opt,name=profile_name,json=profileName,proto3" json:"profile_name,omitempty"`
+	CloneMounts          []string               `protobuf:"bytes,15,rep,name=clone_mounts,json=cloneMounts,proto3" json:"clone_mounts,omitempty"`
+	Labels               map[string]string      `protobuf:"bytes,16,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Shell                string                 `protobuf:"bytes,17,opt,name=shell,proto3" json:"shell,omitempty"`
+	Network              string                 `protobuf:"bytes,18,opt,name=network,proto3" json:"network,omitempty"`
+	CloneRoot            string                 `protobuf:"bytes,19,opt,name=clone_root,json=cloneRoot,proto3" json:"clone_root,omitempty"`
+	FromBranch           string                 `protobuf:"bytes,20,opt,name=from_branch,json=fromBranch,proto3" json:"from_branch,omitempty"`
+	NoDotfiles           bool                   `protobuf:"varint,21,opt,name=no_dotfiles,json=noDotfiles,proto3" json:"no_dotfiles,omitempty"`
+	NoSsh                bool                   `protobuf:"varint,22,opt,name=no_ssh,json=noSsh,proto3" json:"no_ssh,omitempty"`
+	MessageLevel         int32                  `protobuf:"varint,23,opt,name=message_level,json=messageLevel,proto3" json:"message_level,omitempty"`
+	AllowEmulation       bool                   `protobuf:"varint,24,opt,name=allow_emulation,json=allowEmulation,proto3" json:"allow_emulation,omitempty"`
+	Publish              []string               `protobuf:"bytes,25,rep,name=publish,proto3" json:"publish,omitempty"`
+	Dns                  *DNSConfig             `protobuf:"bytes,26,opt,name=dns,proto3" json:"dns,omitempty"`
+	IgnoreOncreateErrors bool                   `protobuf:"varint,27,opt,name=ignore_oncreate_errors,json=ignoreOncreateErrors,proto3" json:"ignore_oncreate_errors,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
 }
 
 func (x *CreateSandboxRequest) Reset() {
 	*x = CreateSandboxRequest{}
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[47]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[56]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2989,7 +3597,7 @@ func (x *CreateSandboxRequest) String() string {
 func (*CreateSandboxRequest) ProtoMessage() {}
 
 func (x *CreateSandboxRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[47]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[56]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3002,7 +3610,7 @@ func (x *CreateSandboxRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateSandboxRequest.ProtoReflect.Descriptor instead.
 func (*CreateSandboxRequest) Descriptor() ([]byte, []int) {
-	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{47}
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{56}
 }
 
 func (x *CreateSandboxRequest) GetId() string {
@@ -3107,7 +3715,91 @@ func (x *CreateSandboxRequest) GetCloneMounts() []string {
 	if x != nil {
 		return x.CloneMounts
 	}
-	return nil
+	return nil
+}
+
+func (x *CreateSandboxRequest) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *CreateSandboxRequest) GetShell() string {
+	if x != nil {
+		return x.Shell
+	}
+	return ""
+}
+
+func (x *CreateSandboxRequest) GetNetwork() string {
+	if x != nil {
+		return x.Network
+	}
+	return ""
+}
+
+func (x *CreateSandboxRequest) GetCloneRoot() string {
+	if x != nil {
+		return x.CloneRoot
+	}
+	return ""
+}
+
+func (x *CreateSandboxRequest) GetFromBranch() string {
+	if x != nil {
+		return x.FromBranch
+	}
+	return ""
+}
+
+func (x *CreateSandboxRequest) GetNoDotfiles() bool {
+	if x != nil {
+		return x.NoDotfiles
+	}
+	return false
+}
+
+func (x *CreateSandboxRequest) GetNoSsh() bool {
+	if x != nil {
+		return x.NoSsh
+	}
+	return false
+}
+
+func (x *CreateSandboxRequest) GetMessageLevel() int32 {
+	if x != nil {
+		return x.MessageLevel
+	}
+	return 0
+}
+
+func (x *CreateSandboxRequest) GetAllowEmulation() bool {
+	if x != nil {
+		return x.AllowEmulation
+	}
+	return false
+}
+
+func (x *CreateSandboxRequest) GetPublish() []string {
+	if x != nil {
+		return x.Publish
+	}
+	return nil
+}
+
+func (x *CreateSandboxRequest) GetDns() *DNSConfig {
+	if x != nil {
+		return x.Dns
+	}
+	return nil
+}
+
+func (x *CreateSandboxRequest) GetIgnoreOncreateErrors() bool {
+	if x != nil {
+		return x.IgnoreOncreateErrors
+	}
+	return false
 }
 
 type CreateSandboxResponse struct {
@@ -3124,7 +3816,7 @@ type CreateSandboxResponse struct {
 
 func (x *CreateSandboxResponse) Reset() {
 	*x = CreateSandboxResponse{}
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[48]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[57]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3136,7 +3828,7 @@ func (x *CreateSandboxResponse) String() string {
 func (*CreateSandboxResponse) ProtoMessage() {}
 
 func (x *CreateSandboxResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[48]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[57]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3149,7 +3841,7 @@ func (x *CreateSandboxResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateSandboxResponse.ProtoReflect.Descriptor instead.
 func (*CreateSandboxResponse) Descriptor() ([]byte, []int) {
-	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{48}
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{57}
 }
 
 func (x *CreateSandboxResponse) GetEvent() isCreateSandboxResponse_Event {
@@ -3218,7 +3910,7 @@ type RenameSandboxRequest struct {
 
 func (x *RenameSandboxRequest) Reset() {
 	*x = RenameSandboxRequest{}
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[49]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[58]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3230,7 +3922,7 @@ func (x *RenameSandboxRequest) String() string {
 func (*RenameSandboxRequest) ProtoMessage() {}
 
 func (x *RenameSandboxRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[49]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[58]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3243,7 +3935,7 @@ func (x *RenameSandboxRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RenameSandboxRequest.ProtoReflect.Descriptor instead.
 func (*RenameSandboxRequest) Descriptor() ([]byte, []int) {
-	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{49}
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{58}
 }
 
 func (x *RenameSandboxRequest) GetOldName() string {
@@ -3269,7 +3961,7 @@ type RenameSandboxResponse struct {
 
 func (x *RenameSandboxResponse) Reset() {
 	*x = RenameSandboxResponse{}
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[50]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[59]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3281,7 +3973,7 @@ func (x *RenameSandboxResponse) String() string {
 func (*RenameSandboxResponse) ProtoMessage() {}
 
 func (x *RenameSandboxResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[50]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[59]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3294,7 +3986,7 @@ func (x *RenameSandboxResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RenameSandboxResponse.ProtoReflect.Descriptor instead.
 func (*RenameSandboxResponse) Descriptor() ([]byte, []int) {
-	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{50}
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{59}
 }
 
 func (x *RenameSandboxResponse) GetBox() *Sandbox {
@@ -3304,6 +3996,102 @@ func (x *RenameSandboxResponse) GetBox() *Sandbox {
 	return nil
 }
 
+type ForkSandboxRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SourceName    string                 `protobuf:"bytes,1,opt,name=source_name,json=sourceName,proto3" json:"source_name,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ForkSandboxRequest) Reset() {
+	*x = ForkSandboxRequest{}
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[60]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ForkSandboxRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ForkSandboxRequest) ProtoMessage() {}
+
+func (x *ForkSandboxRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[60]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ForkSandboxRequest.ProtoReflect.Descriptor instead.
+func (*ForkSandboxRequest) Descriptor() ([]byte, []int) {
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{60}
+}
+
+func (x *ForkSandboxRequest) GetSourceName() string {
+	if x != nil {
+		return x.SourceName
+	}
+	return ""
+}
+
+func (x *ForkSandboxRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type ForkSandboxResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Box           *Sandbox               `protobuf:"bytes,1,opt,name=box,proto3" json:"box,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ForkSandboxResponse) Reset() {
+	*x = ForkSandboxResponse{}
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[61]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ForkSandboxResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ForkSandboxResponse) ProtoMessage() {}
+
+func (x *ForkSandboxResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[61]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ForkSandboxResponse.ProtoReflect.Descriptor instead.
+func (*ForkSandboxResponse) Descriptor() ([]byte, []int) {
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{61}
+}
+
+func (x *ForkSandboxResponse) GetBox() *Sandbox {
+	if x != nil {
+		return x.Box
+	}
+	return nil
+}
+
 type RecoverSandboxResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Box           *Sandbox               `protobuf:"bytes,1,opt,name=box,proto3" json:"box,omitempty"`
@@ -3313,7 +4101,7 @@ type RecoverSandboxResponse struct {
 
 func (x *RecoverSandboxResponse) Reset() {
 	*x = RecoverSandboxResponse{}
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[51]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[62]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3325,7 +4113,7 @@ func (x *RecoverSandboxResponse) String() string {
 func (*RecoverSandboxResponse) ProtoMessage() {}
 
 func (x *RecoverSandboxResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[51]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[62]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3338,7 +4126,7 @@ func (x *RecoverSandboxResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RecoverSandboxResponse.ProtoReflect.Descriptor instead.
 func (*RecoverSandboxResponse) Descriptor() ([]byte, []int) {
-	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{51}
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{62}
 }
 
 func (x *RecoverSandboxResponse) GetBox() *Sandbox {
@@ -3349,15 +4137,20 @@ func (x *RecoverSandboxResponse) GetBox() *Sandbox {
 }
 
 type EnsureImageRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	ImageName     string                 `protobuf:"bytes,1,opt,name=image_name,json=imageName,proto3" json:"image_name,omitempty"`
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	ImageName  string                 `protobuf:"bytes,1,opt,name=image_name,json=imageName,proto3" json:"image_name,omitempty"`
+	PullAlways bool                   `protobuf:"varint,2,opt,name=pull_always,json=pullAlways,proto3" json:"pull_always,omitempty"`
+	// When set, build the image from the Dockerfile in this host directory
+	// instead of pulling it.
+	DockerfileDir string            `protobuf:"bytes,3,opt,name=dockerfile_dir,json=dockerfileDir,proto3" json:"dockerfile_dir,omitempty"`
+	BuildArgs     map[string]string `protobuf:"bytes,4,rep,name=build_args,json=buildArgs,proto3" json:"build_args,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *EnsureImageRequest) Reset() {
 	*x = EnsureImageRequest{}
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[52]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[63]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3369,7 +4162,7 @@ func (x *EnsureImageRequest) String() string {
 func (*EnsureImageRequest) ProtoMessage() {}
 
 func (x *EnsureImageRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[52]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[63]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3382,7 +4175,7 @@ func (x *EnsureImageRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use EnsureImageRequest.ProtoReflect.Descriptor instead.
 func (*EnsureImageRequest) Descriptor() ([]byte, []int) {
-	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{52}
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{63}
 }
 
 func (x *EnsureImageRequest) GetImageName() string {
@@ -3392,6 +4185,27 @@ func (x *EnsureImageRequest) GetImageName() string {
 	return ""
 }
 
+func (x *EnsureImageRequest) GetPullAlways() bool {
+	if x != nil {
+		return x.PullAlways
+	}
+	return false
+}
+
+func (x *EnsureImageRequest) GetDockerfileDir() string {
+	if x != nil {
+		return x.DockerfileDir
+	}
+	return ""
+}
+
+func (x *EnsureImageRequest) GetBuildArgs() map[string]string {
+	if x != nil {
+		return x.BuildArgs
+	}
+	return nil
+}
+
 type EnsureImageResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Types that are valid to be assigned to Event:
@@ -3407,7 +4221,7 @@ type EnsureImageResponse struct {
 
 func (x *EnsureImageResponse) Reset() {
 	*x = EnsureImageResponse{}
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[53]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[64]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3419,7 +4233,7 @@ func (x *EnsureImageResponse) String() string {
 func (*EnsureImageResponse) ProtoMessage() {}
 
 func (x *EnsureImageResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[53]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[64]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3432,7 +4246,7 @@ func (x *EnsureImageResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use EnsureImageResponse.ProtoReflect.Descriptor instead.
 func (*EnsureImageResponse) Descriptor() ([]byte, []int) {
-	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{53}
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{64}
 }
 
 func (x *EnsureImageResponse) GetEvent() isEnsureImageResponse_Event {
@@ -3529,7 +4343,7 @@ type ImagePullProgressUpdate struct {
 
 func (x *ImagePullProgressUpdate) Reset() {
 	*x = ImagePullProgressUpdate{}
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[54]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[65]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3541,7 +4355,7 @@ func (x *ImagePullProgressUpdate) String() string {
 func (*ImagePullProgressUpdate) ProtoMessage() {}
 
 func (x *ImagePullProgressUpdate) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[54]
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[65]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3554,7 +4368,7 @@ func (x *ImagePullProgressUpdate) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ImagePullProgressUpdate.ProtoReflect.Descriptor instead.
 func (*ImagePullProgressUpdate) Descriptor() ([]byte, []int) {
-	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{54}
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{65}
 }
 
 func (x *ImagePullProgressUpdate) GetDescription() string {
@@ -3662,6 +4476,216 @@ func (x *ImagePullProgressUpdate) GetSetTotalSize() int64 {
 	return 0
 }
 
+type PortForward struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Remote        bool                   `protobuf:"varint,1,opt,name=remote,proto3" json:"remote,omitempty"`
+	HostPort      int32                  `protobuf:"varint,2,opt,name=host_port,json=hostPort,proto3" json:"host_port,omitempty"`
+	ContainerPort int32                  `protobuf:"varint,3,opt,name=container_port,json=containerPort,proto3" json:"container_port,omitempty"`
+	Pid           int32                  `protobuf:"varint,4,opt,name=pid,proto3" json:"pid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PortForward) Reset() {
+	*x = PortForward{}
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[66]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PortForward) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PortForward) ProtoMessage() {}
+
+func (x *PortForward) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[66]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PortForward.ProtoReflect.Descriptor instead.
+func (*PortForward) Descriptor() ([]byte, []int) {
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{66}
+}
+
+func (x *PortForward) GetRemote() bool {
+	if x != nil {
+		return x.Remote
+	}
+	return false
+}
+
+func (x *PortForward) GetHostPort() int32 {
+	if x != nil {
+		return x.HostPort
+	}
+	return 0
+}
+
+func (x *PortForward) GetContainerPort() int32 {
+	if x != nil {
+		return x.ContainerPort
+	}
+	return 0
+}
+
+func (x *PortForward) GetPid() int32 {
+	if x != nil {
+		return x.Pid
+	}
+	return 0
+}
+
+type PortForwardRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// forward is required to start a forward; when stopping, leaving it unset
+	// stops all of the sandbox's forwards.
+	Forward       *PortForward `protobuf:"bytes,2,opt,name=forward,proto3" json:"forward,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PortForwardRequest) Reset() {
+	*x = PortForwardRequest{}
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[67]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PortForwardRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PortForwardRequest) ProtoMessage() {}
+
+func (x *PortForwardRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[67]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PortForwardRequest.ProtoReflect.Descriptor instead.
+func (*PortForwardRequest) Descriptor() ([]byte, []int) {
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{67}
+}
+
+func (x *PortForwardRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *PortForwardRequest) GetForward() *PortForward {
+	if x != nil {
+		return x.Forward
+	}
+	return nil
+}
+
+type PortForwardResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Forward       *PortForward           `protobuf:"bytes,1,opt,name=forward,proto3" json:"forward,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PortForwardResponse) Reset() {
+	*x = PortForwardResponse{}
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[68]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PortForwardResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PortForwardResponse) ProtoMessage() {}
+
+func (x *PortForwardResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[68]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PortForwardResponse.ProtoReflect.Descriptor instead.
+func (*PortForwardResponse) Descriptor() ([]byte, []int) {
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{68}
+}
+
+func (x *PortForwardResponse) GetForward() *PortForward {
+	if x != nil {
+		return x.Forward
+	}
+	return nil
+}
+
+type PortForwardsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Forwards      []*PortForward         `protobuf:"bytes,1,rep,name=forwards,proto3" json:"forwards,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PortForwardsResponse) Reset() {
+	*x = PortForwardsResponse{}
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[69]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PortForwardsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PortForwardsResponse) ProtoMessage() {}
+
+func (x *PortForwardsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_daemon_daemonpb_daemon_proto_msgTypes[69]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PortForwardsResponse.ProtoReflect.Descriptor instead.
+func (*PortForwardsResponse) Descriptor() ([]byte, []int) {
+	return file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP(), []int{69}
+}
+
+func (x *PortForwardsResponse) GetForwards() []*PortForward {
+	if x != nil {
+		return x.Forwards
+	}
+	return nil
+}
+
 var File_internal_daemon_daemonpb_daemon_proto protoreflect.FileDescriptor
 
 const file_internal_daemon_daemonpb_daemon_proto_rawDesc = "" +
@@ -3702,13 +4726,34 @@ const file_internal_daemon_daemonpb_daemon_proto_rawDesc = "" +
 	"\x15ListSandboxesResponse\x12-\n" +
 	"\x05boxes\x18\x01 \x03(\v2\x17.sand.daemon.v1.SandboxR\x05boxes\"?\n" +
 	"\x12GetSandboxResponse\x12)\n" +
-	"\x03box\x18\x01 \x01(\v2\x17.sand.daemon.v1.SandboxR\x03box\"B\n" +
+	"\x03box\x18\x01 \x01(\v2\x17.sand.daemon.v1.SandboxR\x03box\"X\n" +
 	"\x13StartSandboxRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1b\n" +
-	"\tssh_agent\x18\x02 \x01(\bR\bsshAgent\"<\n" +
+	"\tssh_agent\x18\x02 \x01(\bR\bsshAgent\x12\x14\n" +
+	"\x05image\x18\x03 \x01(\tR\x05image\"<\n" +
+	"\x12KillSandboxRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
+	"\x06signal\x18\x02 \x01(\tR\x06signal\"<\n" +
 	"\x19SyncHostGitMirrorResponse\x12\x1f\n" +
 	"\vmirror_path\x18\x01 \x01(\tR\n" +
-	"mirrorPath\"\xe4\x01\n" +
+	"mirrorPath\"A\n" +
+	"\x17FetchHostChangesRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
+	"\x06remote\x18\x02 \x01(\tR\x06remote\";\n" +
+	"\x18FetchHostChangesResponse\x12\x1f\n" +
+	"\vmirror_path\x18\x01 \x01(\tR\n" +
+	"mirrorPath\"O\n" +
+	"\x17ResyncWorkspaceResponse\x12\x16\n" +
+	"\x06copied\x18\x01 \x03(\tR\x06copied\x12\x1c\n" +
+	"\tconflicts\x18\x02 \x03(\tR\tconflicts\"\x14\n" +
+	"\x12WatchEventsRequest\"\xaa\x01\n" +
+	"\fSandboxEvent\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x1d\n" +
+	"\n" +
+	"sandbox_id\x18\x02 \x01(\tR\tsandboxId\x12!\n" +
+	"\fsandbox_name\x18\x03 \x01(\tR\vsandboxName\x12.\n" +
+	"\x04time\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12\x14\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\"\xe4\x01\n" +
 	"\x1cResolveAgentLaunchEnvRequest\x12\x14\n" +
 	"\x05agent\x18\x01 \x01(\tR\x05agent\x12\x19\n" +
 	"\benv_file\x18\x02 \x01(\tR\aenvFile\x12!\n" +
@@ -3734,11 +4779,16 @@ const file_internal_daemon_daemonpb_daemon_proto_rawDesc = "" +
 	"\x05scope\x18\x02 \x01(\tR\x05scope\"O\n" +
 	"\x12ExportImageRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12)\n" +
-	"\x10destination_path\x18\x02 \x01(\tR\x0fdestinationPath\" \n" +
+	"\x10destination_path\x18\x02 \x01(\tR\x0fdestinationPath\"A\n" +
+	"\x12PruneImagesRequest\x12\x17\n" +
+	"\adry_run\x18\x01 \x01(\bR\x06dryRun\x12\x12\n" +
+	"\x04keep\x18\x02 \x03(\tR\x04keep\"-\n" +
+	"\x13PruneImagesResponse\x12\x16\n" +
+	"\x06images\x18\x01 \x03(\tR\x06images\" \n" +
 	"\fStatsRequest\x12\x10\n" +
 	"\x03ids\x18\x01 \x03(\tR\x03ids\"E\n" +
 	"\rStatsResponse\x124\n" +
-	"\x05stats\x18\x01 \x03(\v2\x1e.sand.daemon.v1.ContainerStatsR\x05stats\"\xc7\b\n" +
+	"\x05stats\x18\x01 \x03(\v2\x1e.sand.daemon.v1.ContainerStatsR\x05stats\"\xa3\r\n" +
 	"\aSandbox\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
@@ -3770,7 +4820,31 @@ const file_internal_daemon_daemonpb_daemon_proto_rawDesc = "" +
 	"\x03uid\x18\x17 \x01(\tR\x03uid\x12L\n" +
 	"\x14original_git_details\x18\x18 \x01(\v2\x1a.sand.daemon.v1.GitDetailsR\x12originalGitDetails\x12J\n" +
 	"\x13current_git_details\x18\x19 \x01(\v2\x1a.sand.daemon.v1.GitDetailsR\x11currentGitDetails\x127\n" +
-	"\tcontainer\x18\x1a \x01(\v2\x19.sand.daemon.v1.ContainerR\tcontainer\"X\n" +
+	"\tcontainer\x18\x1a \x01(\v2\x19.sand.daemon.v1.ContainerR\tcontainer\x129\n" +
+	"\n" +
+	"created_at\x18\x1b \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12<\n" +
+	"\flast_used_at\x18\x1c \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"lastUsedAt\x12;\n" +
+	"\x06labels\x18\x1d \x03(\v2#.sand.daemon.v1.Sandbox.LabelsEntryR\x06labels\x12\x14\n" +
+	"\x05shell\x18\x1e \x01(\tR\x05shell\x12!\n" +
+	"\fimage_digest\x18\x1f \x01(\tR\vimageDigest\x12\x18\n" +
+	"\anetwork\x18  \x01(\tR\anetwork\x12\x1f\n" +
+	"\vno_dotfiles\x18! \x01(\bR\n" +
+	"noDotfiles\x12\x15\n" +
+	"\x06no_ssh\x18\" \x01(\bR\x05noSsh\x12'\n" +
+	"\x0fallow_emulation\x18# \x01(\bR\x0eallowEmulation\x12\x18\n" +
+	"\apublish\x18$ \x03(\tR\apublish\x128\n" +
+	"\x18sandbox_resource_warning\x18% \x01(\tR\x16sandboxResourceWarning\x12+\n" +
+	"\x03dns\x18& \x01(\v2\x19.sand.daemon.v1.DNSConfigR\x03dns\x124\n" +
+	"\x16ignore_oncreate_errors\x18' \x01(\bR\x14ignoreOncreateErrors\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x8a\x01\n" +
+	"\tDNSConfig\x12 \n" +
+	"\vnameservers\x18\x01 \x03(\tR\vnameservers\x12%\n" +
+	"\x0esearch_domains\x18\x02 \x03(\tR\rsearchDomains\x12\x18\n" +
+	"\aoptions\x18\x03 \x03(\tR\aoptions\x12\x1a\n" +
+	"\bdisabled\x18\x04 \x01(\bR\bdisabled\"X\n" +
 	"\tMountSpec\x12\x16\n" +
 	"\x06source\x18\x01 \x01(\tR\x06source\x12\x16\n" +
 	"\x06target\x18\x02 \x01(\tR\x06target\x12\x1b\n" +
@@ -3875,7 +4949,7 @@ const file_internal_daemon_daemonpb_daemon_proto_rawDesc = "" +
 	"media_type\x18\x03 \x01(\tR\tmediaType\"G\n" +
 	"\tResources\x12\x12\n" +
 	"\x04cpus\x18\x01 \x01(\x05R\x04cpus\x12&\n" +
-	"\x0fmemory_in_bytes\x18\x02 \x01(\x03R\rmemoryInBytes\"\xf1\x02\n" +
+	"\x0fmemory_in_bytes\x18\x02 \x01(\x03R\rmemoryInBytes\"\x98\x03\n" +
 	"\x0eContainerStats\x12(\n" +
 	"\x10block_read_bytes\x18\x01 \x01(\x03R\x0eblockReadBytes\x12*\n" +
 	"\x11block_write_bytes\x18\x02 \x01(\x03R\x0fblockWriteBytes\x12$\n" +
@@ -3885,14 +4959,16 @@ const file_internal_daemon_daemonpb_daemon_proto_rawDesc = "" +
 	"\x12memory_usage_bytes\x18\x06 \x01(\x03R\x10memoryUsageBytes\x12(\n" +
 	"\x10network_rx_bytes\x18\a \x01(\x03R\x0enetworkRxBytes\x12(\n" +
 	"\x10network_tx_bytes\x18\b \x01(\x03R\x0enetworkTxBytes\x12#\n" +
-	"\rnum_processes\x18\t \x01(\x03R\fnumProcesses\"\x86\x01\n" +
+	"\rnum_processes\x18\t \x01(\x03R\fnumProcesses\x12%\n" +
+	"\x0euptime_seconds\x18\n" +
+	" \x01(\x03R\ruptimeSeconds\"\x86\x01\n" +
 	"\x11SharedCacheConfig\x12\x12\n" +
 	"\x04mise\x18\x01 \x01(\bR\x04mise\x12\x10\n" +
 	"\x03apk\x18\x02 \x01(\bR\x03apk\x12\x16\n" +
 	"\x06agents\x18\x03 \x01(\bR\x06agents\x12\x14\n" +
 	"\x05bazel\x18\x04 \x01(\bR\x05bazel\x12\x1d\n" +
 	"\n" +
-	"http_proxy\x18\x05 \x01(\bR\thttpProxy\"\xe2\x03\n" +
+	"http_proxy\x18\x05 \x01(\bR\thttpProxy\"\xda\a\n" +
 	"\x14CreateSandboxRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12$\n" +
 	"\x0eclone_from_dir\x18\x02 \x01(\tR\fcloneFromDir\x12\x1d\n" +
@@ -3910,7 +4986,25 @@ const file_internal_daemon_daemonpb_daemon_proto_rawDesc = "" +
 	"\x04cpus\x18\f \x01(\x05R\x04cpus\x12\x16\n" +
 	"\x06memory\x18\r \x01(\x05R\x06memory\x12!\n" +
 	"\fprofile_name\x18\x0e \x01(\tR\vprofileName\x12!\n" +
-	"\fclone_mounts\x18\x0f \x03(\tR\vcloneMounts\"\x83\x01\n" +
+	"\fclone_mounts\x18\x0f \x03(\tR\vcloneMounts\x12H\n" +
+	"\x06labels\x18\x10 \x03(\v20.sand.daemon.v1.CreateSandboxRequest.LabelsEntryR\x06labels\x12\x14\n" +
+	"\x05shell\x18\x11 \x01(\tR\x05shell\x12\x18\n" +
+	"\anetwork\x18\x12 \x01(\tR\anetwork\x12\x1d\n" +
+	"\n" +
+	"clone_root\x18\x13 \x01(\tR\tcloneRoot\x12\x1f\n" +
+	"\vfrom_branch\x18\x14 \x01(\tR\n" +
+	"fromBranch\x12\x1f\n" +
+	"\vno_dotfiles\x18\x15 \x01(\bR\n" +
+	"noDotfiles\x12\x15\n" +
+	"\x06no_ssh\x18\x16 \x01(\bR\x05noSsh\x12#\n" +
+	"\rmessage_level\x18\x17 \x01(\x05R\fmessageLevel\x12'\n" +
+	"\x0fallow_emulation\x18\x18 \x01(\bR\x0eallowEmulation\x12\x18\n" +
+	"\apublish\x18\x19 \x03(\tR\apublish\x12+\n" +
+	"\x03dns\x18\x1a \x01(\v2\x19.sand.daemon.v1.DNSConfigR\x03dns\x124\n" +
+	"\x16ignore_oncreate_errors\x18\x1b \x01(\bR\x14ignoreOncreateErrors\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x83\x01\n" +
 	"\x15CreateSandboxResponse\x12\x1c\n" +
 	"\bprogress\x18\x01 \x01(\tH\x00R\bprogress\x12+\n" +
 	"\x03box\x18\x02 \x01(\v2\x17.sand.daemon.v1.SandboxH\x00R\x03box\x12\x16\n" +
@@ -3920,12 +5014,26 @@ const file_internal_daemon_daemonpb_daemon_proto_rawDesc = "" +
 	"\bold_name\x18\x01 \x01(\tR\aoldName\x12\x19\n" +
 	"\bnew_name\x18\x02 \x01(\tR\anewName\"B\n" +
 	"\x15RenameSandboxResponse\x12)\n" +
+	"\x03box\x18\x01 \x01(\v2\x17.sand.daemon.v1.SandboxR\x03box\"I\n" +
+	"\x12ForkSandboxRequest\x12\x1f\n" +
+	"\vsource_name\x18\x01 \x01(\tR\n" +
+	"sourceName\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\"@\n" +
+	"\x13ForkSandboxResponse\x12)\n" +
 	"\x03box\x18\x01 \x01(\v2\x17.sand.daemon.v1.SandboxR\x03box\"C\n" +
 	"\x16RecoverSandboxResponse\x12)\n" +
-	"\x03box\x18\x01 \x01(\v2\x17.sand.daemon.v1.SandboxR\x03box\"3\n" +
+	"\x03box\x18\x01 \x01(\v2\x17.sand.daemon.v1.SandboxR\x03box\"\x8b\x02\n" +
 	"\x12EnsureImageRequest\x12\x1d\n" +
 	"\n" +
-	"image_name\x18\x01 \x01(\tR\timageName\"\xb6\x01\n" +
+	"image_name\x18\x01 \x01(\tR\timageName\x12\x1f\n" +
+	"\vpull_always\x18\x02 \x01(\bR\n" +
+	"pullAlways\x12%\n" +
+	"\x0edockerfile_dir\x18\x03 \x01(\tR\rdockerfileDir\x12P\n" +
+	"\n" +
+	"build_args\x18\x04 \x03(\v21.sand.daemon.v1.EnsureImageRequest.BuildArgsEntryR\tbuildArgs\x1a<\n" +
+	"\x0eBuildArgsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xb6\x01\n" +
 	"\x13EnsureImageResponse\x12\x1c\n" +
 	"\bprogress\x18\x01 \x01(\fH\x00R\bprogress\x12\x16\n" +
 	"\x05error\x18\x02 \x01(\tH\x00R\x05error\x12\x10\n" +
@@ -3969,32 +5077,56 @@ const file_internal_daemon_daemonpb_daemon_proto_rawDesc = "" +
 	"\t_add_sizeB\v\n" +
 	"\t_set_sizeB\x11\n" +
 	"\x0f_add_total_sizeB\x11\n" +
-	"\x0f_set_total_size2\xe5\x0e\n" +
+	"\x0f_set_total_size\"{\n" +
+	"\vPortForward\x12\x16\n" +
+	"\x06remote\x18\x01 \x01(\bR\x06remote\x12\x1b\n" +
+	"\thost_port\x18\x02 \x01(\x05R\bhostPort\x12%\n" +
+	"\x0econtainer_port\x18\x03 \x01(\x05R\rcontainerPort\x12\x10\n" +
+	"\x03pid\x18\x04 \x01(\x05R\x03pid\"[\n" +
+	"\x12PortForwardRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x125\n" +
+	"\aforward\x18\x02 \x01(\v2\x1b.sand.daemon.v1.PortForwardR\aforward\"L\n" +
+	"\x13PortForwardResponse\x125\n" +
+	"\aforward\x18\x01 \x01(\v2\x1b.sand.daemon.v1.PortForwardR\aforward\"O\n" +
+	"\x14PortForwardsResponse\x127\n" +
+	"\bforwards\x18\x01 \x03(\v2\x1b.sand.daemon.v1.PortForwardR\bforwards2\x9b\x17\n" +
 	"\rDaemonService\x12A\n" +
 	"\x04Ping\x12\x1b.sand.daemon.v1.PingRequest\x1a\x1c.sand.daemon.v1.PingResponse\x12J\n" +
 	"\aVersion\x12\x1e.sand.daemon.v1.VersionRequest\x1a\x1f.sand.daemon.v1.VersionResponse\x12K\n" +
 	"\bShutdown\x12\x1f.sand.daemon.v1.ShutdownRequest\x1a\x1e.sand.daemon.v1.StatusResponse\x12K\n" +
 	"\n" +
 	"LogSandbox\x12\x19.sand.daemon.v1.IDRequest\x1a\".sand.daemon.v1.LogSandboxResponse\x12\\\n" +
-	"\rListSandboxes\x12$.sand.daemon.v1.ListSandboxesRequest\x1a%.sand.daemon.v1.ListSandboxesResponse\x12c\n" +
-	"\x14ListDeletedSandboxes\x12$.sand.daemon.v1.ListSandboxesRequest\x1a%.sand.daemon.v1.ListSandboxesResponse\x12K\n" +
+	"\rListSandboxes\x12$.sand.daemon.v1.ListSandboxesRequest\x1a%.sand.daemon.v1.ListSandboxesResponse\x12d\n" +
+	"\x15ListSandboxesDetailed\x12$.sand.daemon.v1.ListSandboxesRequest\x1a%.sand.daemon.v1.ListSandboxesResponse\x12c\n" +
+	"\x14ListDeletedSandboxes\x12$.sand.daemon.v1.ListSandboxesRequest\x1a%.sand.daemon.v1.ListSandboxesResponse\x12\\\n" +
+	"\rSyncSandboxes\x12$.sand.daemon.v1.ListSandboxesRequest\x1a%.sand.daemon.v1.ListSandboxesResponse\x12K\n" +
 	"\n" +
 	"GetSandbox\x12\x19.sand.daemon.v1.IDRequest\x1a\".sand.daemon.v1.GetSandboxResponse\x12J\n" +
 	"\rRemoveSandbox\x12\x19.sand.daemon.v1.IDRequest\x1a\x1e.sand.daemon.v1.StatusResponse\x12K\n" +
 	"\x0eExpungeSandbox\x12\x19.sand.daemon.v1.IDRequest\x1a\x1e.sand.daemon.v1.StatusResponse\x12S\n" +
 	"\x0eRecoverSandbox\x12\x19.sand.daemon.v1.IDRequest\x1a&.sand.daemon.v1.RecoverSandboxResponse\x12H\n" +
-	"\vStopSandbox\x12\x19.sand.daemon.v1.IDRequest\x1a\x1e.sand.daemon.v1.StatusResponse\x12S\n" +
-	"\fStartSandbox\x12#.sand.daemon.v1.StartSandboxRequest\x1a\x1e.sand.daemon.v1.StatusResponse\x12Y\n" +
-	"\x11SyncHostGitMirror\x12\x19.sand.daemon.v1.IDRequest\x1a).sand.daemon.v1.SyncHostGitMirrorResponse\x12t\n" +
+	"\vStopSandbox\x12\x19.sand.daemon.v1.IDRequest\x1a\x1e.sand.daemon.v1.StatusResponse\x12Q\n" +
+	"\vKillSandbox\x12\".sand.daemon.v1.KillSandboxRequest\x1a\x1e.sand.daemon.v1.StatusResponse\x12S\n" +
+	"\fStartSandbox\x12#.sand.daemon.v1.StartSandboxRequest\x1a\x1e.sand.daemon.v1.StatusResponse\x12L\n" +
+	"\x0fMarkSandboxUsed\x12\x19.sand.daemon.v1.IDRequest\x1a\x1e.sand.daemon.v1.StatusResponse\x12Y\n" +
+	"\x11SyncHostGitMirror\x12\x19.sand.daemon.v1.IDRequest\x1a).sand.daemon.v1.SyncHostGitMirrorResponse\x12e\n" +
+	"\x10FetchHostChanges\x12'.sand.daemon.v1.FetchHostChangesRequest\x1a(.sand.daemon.v1.FetchHostChangesResponse\x12U\n" +
+	"\x0fResyncWorkspace\x12\x19.sand.daemon.v1.IDRequest\x1a'.sand.daemon.v1.ResyncWorkspaceResponse\x12t\n" +
 	"\x15ResolveAgentLaunchEnv\x12,.sand.daemon.v1.ResolveAgentLaunchEnvRequest\x1a-.sand.daemon.v1.ResolveAgentLaunchEnvResponse\x12Q\n" +
-	"\vExportImage\x12\".sand.daemon.v1.ExportImageRequest\x1a\x1e.sand.daemon.v1.StatusResponse\x12D\n" +
+	"\vExportImage\x12\".sand.daemon.v1.ExportImageRequest\x1a\x1e.sand.daemon.v1.StatusResponse\x12V\n" +
+	"\vPruneImages\x12\".sand.daemon.v1.PruneImagesRequest\x1a#.sand.daemon.v1.PruneImagesResponse\x12D\n" +
 	"\x05Stats\x12\x1c.sand.daemon.v1.StatsRequest\x1a\x1d.sand.daemon.v1.StatsResponse\x12@\n" +
 	"\x03VSC\x12\x19.sand.daemon.v1.IDRequest\x1a\x1e.sand.daemon.v1.StatusResponse\x12^\n" +
 	"\rCreateSandbox\x12$.sand.daemon.v1.CreateSandboxRequest\x1a%.sand.daemon.v1.CreateSandboxResponse0\x01\x12\\\n" +
-	"\rRenameSandbox\x12$.sand.daemon.v1.RenameSandboxRequest\x1a%.sand.daemon.v1.RenameSandboxResponse\x12X\n" +
+	"\rRenameSandbox\x12$.sand.daemon.v1.RenameSandboxRequest\x1a%.sand.daemon.v1.RenameSandboxResponse\x12V\n" +
+	"\vForkSandbox\x12\".sand.daemon.v1.ForkSandboxRequest\x1a#.sand.daemon.v1.ForkSandboxResponse\x12X\n" +
 	"\vEnsureImage\x12\".sand.daemon.v1.EnsureImageRequest\x1a#.sand.daemon.v1.EnsureImageResponse0\x01\x12W\n" +
 	"\x0eHTTPProxyCache\x12%.sand.daemon.v1.HTTPProxyCacheRequest\x1a\x1e.sand.daemon.v1.StatusResponse\x12q\n" +
-	"\x14HTTPProxyCacheStatus\x12+.sand.daemon.v1.HTTPProxyCacheStatusRequest\x1a,.sand.daemon.v1.HTTPProxyCacheStatusResponseB3Z1github.com/banksean/sand/internal/daemon/daemonpbb\x06proto3"
+	"\x14HTTPProxyCacheStatus\x12+.sand.daemon.v1.HTTPProxyCacheStatusRequest\x1a,.sand.daemon.v1.HTTPProxyCacheStatusResponse\x12Q\n" +
+	"\vWatchEvents\x12\".sand.daemon.v1.WatchEventsRequest\x1a\x1c.sand.daemon.v1.SandboxEvent0\x01\x12[\n" +
+	"\x10StartPortForward\x12\".sand.daemon.v1.PortForwardRequest\x1a#.sand.daemon.v1.PortForwardResponse\x12S\n" +
+	"\x10ListPortForwards\x12\x19.sand.daemon.v1.IDRequest\x1a$.sand.daemon.v1.PortForwardsResponse\x12\\\n" +
+	"\x10StopPortForwards\x12\".sand.daemon.v1.PortForwardRequest\x1a$.sand.daemon.v1.PortForwardsResponseB3Z1github.com/banksean/sand/internal/daemon/daemonpbb\x06proto3"
 
 var (
 	file_internal_daemon_daemonpb_daemon_proto_rawDescOnce sync.Once
@@ -4008,7 +5140,7 @@ func file_internal_daemon_daemonpb_daemon_proto_rawDescGZIP() []byte {
 	return file_internal_daemon_daemonpb_daemon_proto_rawDescData
 }
 
-var file_internal_daemon_daemonpb_daemon_proto_msgTypes = make([]protoimpl.MessageInfo, 56)
+var file_internal_daemon_daemonpb_daemon_proto_msgTypes = make([]protoimpl.MessageInfo, 74)
 var file_internal_daemon_daemonpb_daemon_proto_goTypes = []any{
 	(*PingRequest)(nil),                   // 0: sand.daemon.v1.PingRequest
 	(*PingResponse)(nil),                  // 1: sand.daemon.v1.PingResponse
@@ -4025,133 +5157,187 @@ var file_internal_daemon_daemonpb_daemon_proto_goTypes = []any{
 	(*ListSandboxesResponse)(nil),         // 12: sand.daemon.v1.ListSandboxesResponse
 	(*GetSandboxResponse)(nil),            // 13: sand.daemon.v1.GetSandboxResponse
 	(*StartSandboxRequest)(nil),           // 14: sand.daemon.v1.StartSandboxRequest
-	(*SyncHostGitMirrorResponse)(nil),     // 15: sand.daemon.v1.SyncHostGitMirrorResponse
-	(*ResolveAgentLaunchEnvRequest)(nil),  // 16: sand.daemon.v1.ResolveAgentLaunchEnvRequest
-	(*ResolveAgentLaunchEnvResponse)(nil), // 17: sand.daemon.v1.ResolveAgentLaunchEnvResponse
-	(*EnvPolicy)(nil),                     // 18: sand.daemon.v1.EnvPolicy
-	(*EnvFileRef)(nil),                    // 19: sand.daemon.v1.EnvFileRef
-	(*EnvVarRule)(nil),                    // 20: sand.daemon.v1.EnvVarRule
-	(*ExportImageRequest)(nil),            // 21: sand.daemon.v1.ExportImageRequest
-	(*StatsRequest)(nil),                  // 22: sand.daemon.v1.StatsRequest
-	(*StatsResponse)(nil),                 // 23: sand.daemon.v1.StatsResponse
-	(*Sandbox)(nil),                       // 24: sand.daemon.v1.Sandbox
-	(*MountSpec)(nil),                     // 25: sand.daemon.v1.MountSpec
-	(*MountRequest)(nil),                  // 26: sand.daemon.v1.MountRequest
-	(*SharedCacheMounts)(nil),             // 27: sand.daemon.v1.SharedCacheMounts
-	(*GitDetails)(nil),                    // 28: sand.daemon.v1.GitDetails
-	(*Container)(nil),                     // 29: sand.daemon.v1.Container
-	(*ContainerNetworkStatus)(nil),        // 30: sand.daemon.v1.ContainerNetworkStatus
-	(*ContainerStatus)(nil),               // 31: sand.daemon.v1.ContainerStatus
-	(*ContainerConfig)(nil),               // 32: sand.daemon.v1.ContainerConfig
-	(*Mount)(nil),                         // 33: sand.daemon.v1.Mount
-	(*MountType)(nil),                     // 34: sand.daemon.v1.MountType
-	(*Platform)(nil),                      // 35: sand.daemon.v1.Platform
-	(*InitProcess)(nil),                   // 36: sand.daemon.v1.InitProcess
-	(*User)(nil),                          // 37: sand.daemon.v1.User
-	(*UserID)(nil),                        // 38: sand.daemon.v1.UserID
-	(*DNS)(nil),                           // 39: sand.daemon.v1.DNS
-	(*ContainerNetwork)(nil),              // 40: sand.daemon.v1.ContainerNetwork
-	(*NetworkOptions)(nil),                // 41: sand.daemon.v1.NetworkOptions
-	(*Image)(nil),                         // 42: sand.daemon.v1.Image
-	(*Descriptor)(nil),                    // 43: sand.daemon.v1.Descriptor
-	(*Resources)(nil),                     // 44: sand.daemon.v1.Resources
-	(*ContainerStats)(nil),                // 45: sand.daemon.v1.ContainerStats
-	(*SharedCacheConfig)(nil),             // 46: sand.daemon.v1.SharedCacheConfig
-	(*CreateSandboxRequest)(nil),          // 47: sand.daemon.v1.CreateSandboxRequest
-	(*CreateSandboxResponse)(nil),         // 48: sand.daemon.v1.CreateSandboxResponse
-	(*RenameSandboxRequest)(nil),          // 49: sand.daemon.v1.RenameSandboxRequest
-	(*RenameSandboxResponse)(nil),         // 50: sand.daemon.v1.RenameSandboxResponse
-	(*RecoverSandboxResponse)(nil),        // 51: sand.daemon.v1.RecoverSandboxResponse
-	(*EnsureImageRequest)(nil),            // 52: sand.daemon.v1.EnsureImageRequest
-	(*EnsureImageResponse)(nil),           // 53: sand.daemon.v1.EnsureImageResponse
-	(*ImagePullProgressUpdate)(nil),       // 54: sand.daemon.v1.ImagePullProgressUpdate
-	nil,                                   // 55: sand.daemon.v1.ResolveAgentLaunchEnvResponse.EnvEntry
-	(*timestamppb.Timestamp)(nil),         // 56: google.protobuf.Timestamp
+	(*KillSandboxRequest)(nil),            // 15: sand.daemon.v1.KillSandboxRequest
+	(*SyncHostGitMirrorResponse)(nil),     // 16: sand.daemon.v1.SyncHostGitMirrorResponse
+	(*FetchHostChangesRequest)(nil),       // 17: sand.daemon.v1.FetchHostChangesRequest
+	(*FetchHostChangesResponse)(nil),      // 18: sand.daemon.v1.FetchHostChangesResponse
+	(*ResyncWorkspaceResponse)(nil),       // 19: sand.daemon.v1.ResyncWorkspaceResponse
+	(*WatchEventsRequest)(nil),            // 20: sand.daemon.v1.WatchEventsRequest
+	(*SandboxEvent)(nil),                  // 21: sand.daemon.v1.SandboxEvent
+	(*ResolveAgentLaunchEnvRequest)(nil),  // 22: sand.daemon.v1.ResolveAgentLaunchEnvRequest
+	(*ResolveAgentLaunchEnvResponse)(nil), // 23: sand.daemon.v1.ResolveAgentLaunchEnvResponse
+	(*EnvPolicy)(nil),                     // 24: sand.daemon.v1.EnvPolicy
+	(*EnvFileRef)(nil),                    // 25: sand.daemon.v1.EnvFileRef
+	(*EnvVarRule)(nil),                    // 26: sand.daemon.v1.EnvVarRule
+	(*ExportImageRequest)(nil),            // 27: sand.daemon.v1.ExportImageRequest
+	(*PruneImagesRequest)(nil),            // 28: sand.daemon.v1.PruneImagesRequest
+	(*PruneImagesResponse)(nil),           // 29: sand.daemon.v1.PruneImagesResponse
+	(*StatsRequest)(nil),                  // 30: sand.daemon.v1.StatsRequest
+	(*StatsResponse)(nil),                 // 31: sand.daemon.v1.StatsResponse
+	(*Sandbox)(nil),                       // 32: sand.daemon.v1.Sandbox
+	(*DNSConfig)(nil),                     // 33: sand.daemon.v1.DNSConfig
+	(*MountSpec)(nil),                     // 34: sand.daemon.v1.MountSpec
+	(*MountRequest)(nil),                  // 35: sand.daemon.v1.MountRequest
+	(*SharedCacheMounts)(nil),             // 36: sand.daemon.v1.SharedCacheMounts
+	(*GitDetails)(nil),                    // 37: sand.daemon.v1.GitDetails
+	(*Container)(nil),                     // 38: sand.daemon.v1.Container
+	(*ContainerNetworkStatus)(nil),        // 39: sand.daemon.v1.ContainerNetworkStatus
+	(*ContainerStatus)(nil),               // 40: sand.daemon.v1.ContainerStatus
+	(*ContainerConfig)(nil),               // 41: sand.daemon.v1.ContainerConfig
+	(*Mount)(nil),                         // 42: sand.daemon.v1.Mount
+	(*MountType)(nil),                     // 43: sand.daemon.v1.MountType
+	(*Platform)(nil),                      // 44: sand.daemon.v1.Platform
+	(*InitProcess)(nil),                   // 45: sand.daemon.v1.InitProcess
+	(*User)(nil),                          // 46: sand.daemon.v1.User
+	(*UserID)(nil),                        // 47: sand.daemon.v1.UserID
+	(*DNS)(nil),                           // 48: sand.daemon.v1.DNS
+	(*ContainerNetwork)(nil),              // 49: sand.daemon.v1.ContainerNetwork
+	(*NetworkOptions)(nil),                // 50: sand.daemon.v1.NetworkOptions
+	(*Image)(nil),                         // 51: sand.daemon.v1.Image
+	(*Descriptor)(nil),                    // 52: sand.daemon.v1.Descriptor
+	(*Resources)(nil),                     // 53: sand.daemon.v1.Resources
+	(*ContainerStats)(nil),                // 54: sand.daemon.v1.ContainerStats
+	(*SharedCacheConfig)(nil),             // 55: sand.daemon.v1.SharedCacheConfig
+	(*CreateSandboxRequest)(nil),          // 56: sand.daemon.v1.CreateSandboxRequest
+	(*CreateSandboxResponse)(nil),         // 57: sand.daemon.v1.CreateSandboxResponse
+	(*RenameSandboxRequest)(nil),          // 58: sand.daemon.v1.RenameSandboxRequest
+	(*RenameSandboxResponse)(nil),         // 59: sand.daemon.v1.RenameSandboxResponse
+	(*ForkSandboxRequest)(nil),            // 60: sand.daemon.v1.ForkSandboxRequest
+	(*ForkSandboxResponse)(nil),           // 61: sand.daemon.v1.ForkSandboxResponse
+	(*RecoverSandboxResponse)(nil),        // 62: sand.daemon.v1.RecoverSandboxResponse
+	(*EnsureImageRequest)(nil),            // 63: sand.daemon.v1.EnsureImageRequest
+	(*EnsureImageResponse)(nil),           // 64: sand.daemon.v1.EnsureImageResponse
+	(*ImagePullProgressUpdate)(nil),       // 65: sand.daemon.v1.ImagePullProgressUpdate
+	(*PortForward)(nil),                   // 66: sand.daemon.v1.PortForward
+	(*PortForwardRequest)(nil),            // 67: sand.daemon.v1.PortForwardRequest
+	(*PortForwardResponse)(nil),           // 68: sand.daemon.v1.PortForwardResponse
+	(*PortForwardsResponse)(nil),          // 69: sand.daemon.v1.PortForwardsResponse
+	nil,                                   // 70: sand.daemon.v1.ResolveAgentLaunchEnvResponse.EnvEntry
+	nil,                                   // 71: sand.daemon.v1.Sandbox.LabelsEntry
+	nil,                                   // 72: sand.daemon.v1.CreateSandboxRequest.LabelsEntry
+	nil,                                   // 73: sand.daemon.v1.EnsureImageRequest.BuildArgsEntry
+	(*timestamppb.Timestamp)(nil),         // 74: google.protobuf.Timestamp
 }
 var file_internal_daemon_daemonpb_daemon_proto_depIdxs = []int32{
-	24, // 0: sand.daemon.v1.ListSandboxesResponse.boxes:type_name -> sand.daemon.v1.Sandbox
-	24, // 1: sand.daemon.v1.GetSandboxResponse.box:type_name -> sand.daemon.v1.Sandbox
-	18, // 2: sand.daemon.v1.ResolveAgentLaunchEnvRequest.profile_env:type_name -> sand.daemon.v1.EnvPolicy
-	55, // 3: sand.daemon.v1.ResolveAgentLaunchEnvResponse.env:type_name -> sand.daemon.v1.ResolveAgentLaunchEnvResponse.EnvEntry
-	19, // 4: sand.daemon.v1.EnvPolicy.files:type_name -> sand.daemon.v1.EnvFileRef
-	20, // 5: sand.daemon.v1.EnvPolicy.vars:type_name -> sand.daemon.v1.EnvVarRule
-	45, // 6: sand.daemon.v1.StatsResponse.stats:type_name -> sand.daemon.v1.ContainerStats
-	56, // 7: sand.daemon.v1.Sandbox.deleted_at:type_name -> google.protobuf.Timestamp
-	25, // 8: sand.daemon.v1.Sandbox.mounts:type_name -> sand.daemon.v1.MountSpec
-	26, // 9: sand.daemon.v1.Sandbox.mount_requests:type_name -> sand.daemon.v1.MountRequest
-	27, // 10: sand.daemon.v1.Sandbox.shared_cache_mounts:type_name -> sand.daemon.v1.SharedCacheMounts
-	28, // 11: sand.daemon.v1.Sandbox.original_git_details:type_name -> sand.daemon.v1.GitDetails
-	28, // 12: sand.daemon.v1.Sandbox.current_git_details:type_name -> sand.daemon.v1.GitDetails
-	29, // 13: sand.daemon.v1.Sandbox.container:type_name -> sand.daemon.v1.Container
-	30, // 14: sand.daemon.v1.Container.networks:type_name -> sand.daemon.v1.ContainerNetworkStatus
-	31, // 15: sand.daemon.v1.Container.status:type_name -> sand.daemon.v1.ContainerStatus
-	32, // 16: sand.daemon.v1.Container.configuration:type_name -> sand.daemon.v1.ContainerConfig
-	33, // 17: sand.daemon.v1.ContainerConfig.mounts:type_name -> sand.daemon.v1.Mount
-	35, // 18: sand.daemon.v1.ContainerConfig.platform:type_name -> sand.daemon.v1.Platform
-	36, // 19: sand.daemon.v1.ContainerConfig.init_process:type_name -> sand.daemon.v1.InitProcess
-	39, // 20: sand.daemon.v1.ContainerConfig.dns:type_name -> sand.daemon.v1.DNS
-	40, // 21: sand.daemon.v1.ContainerConfig.networks:type_name -> sand.daemon.v1.ContainerNetwork
-	42, // 22: sand.daemon.v1.ContainerConfig.image:type_name -> sand.daemon.v1.Image
-	44, // 23: sand.daemon.v1.ContainerConfig.resources:type_name -> sand.daemon.v1.Resources
-	34, // 24: sand.daemon.v1.Mount.type:type_name -> sand.daemon.v1.MountType
-	37, // 25: sand.daemon.v1.InitProcess.user:type_name -> sand.daemon.v1.User
-	38, // 26: sand.daemon.v1.User.id:type_name -> sand.daemon.v1.UserID
-	41, // 27: sand.daemon.v1.ContainerNetwork.options:type_name -> sand.daemon.v1.NetworkOptions
-	43, // 28: sand.daemon.v1.Image.descriptor:type_name -> sand.daemon.v1.Descriptor
-	46, // 29: sand.daemon.v1.CreateSandboxRequest.shared_caches:type_name -> sand.daemon.v1.SharedCacheConfig
-	24, // 30: sand.daemon.v1.CreateSandboxResponse.box:type_name -> sand.daemon.v1.Sandbox
-	24, // 31: sand.daemon.v1.RenameSandboxResponse.box:type_name -> sand.daemon.v1.Sandbox
-	24, // 32: sand.daemon.v1.RecoverSandboxResponse.box:type_name -> sand.daemon.v1.Sandbox
-	54, // 33: sand.daemon.v1.EnsureImageResponse.pull_progress:type_name -> sand.daemon.v1.ImagePullProgressUpdate
-	0,  // 34: sand.daemon.v1.DaemonService.Ping:input_type -> sand.daemon.v1.PingRequest
-	2,  // 35: sand.daemon.v1.DaemonService.Version:input_type -> sand.daemon.v1.VersionRequest
-	8,  // 36: sand.daemon.v1.DaemonService.Shutdown:input_type -> sand.daemon.v1.ShutdownRequest
-	9,  // 37: sand.daemon.v1.DaemonService.LogSandbox:input_type -> sand.daemon.v1.IDRequest
-	11, // 38: sand.daemon.v1.DaemonService.ListSandboxes:input_type -> sand.daemon.v1.ListSandboxesRequest
-	11, // 39: sand.daemon.v1.DaemonService.ListDeletedSandboxes:input_type -> sand.daemon.v1.ListSandboxesRequest
-	9,  // 40: sand.daemon.v1.DaemonService.GetSandbox:input_type -> sand.daemon.v1.IDRequest
-	9,  // 41: sand.daemon.v1.DaemonService.RemoveSandbox:input_type -> sand.daemon.v1.IDRequest
-	9,  // 42: sand.daemon.v1.DaemonService.ExpungeSandbox:input_type -> sand.daemon.v1.IDRequest
-	9,  // 43: sand.daemon.v1.DaemonService.RecoverSandbox:input_type -> sand.daemon.v1.IDRequest
-	9,  // 44: sand.daemon.v1.DaemonService.StopSandbox:input_type -> sand.daemon.v1.IDRequest
-	14, // 45: sand.daemon.v1.DaemonService.StartSandbox:input_type -> sand.daemon.v1.StartSandboxRequest
-	9,  // 46: sand.daemon.v1.DaemonService.SyncHostGitMirror:input_type -> sand.daemon.v1.IDRequest
-	16, // 47: sand.daemon.v1.DaemonService.ResolveAgentLaunchEnv:input_type -> sand.daemon.v1.ResolveAgentLaunchEnvRequest
-	21, // 48: sand.daemon.v1.DaemonService.ExportImage:input_type -> sand.daemon.v1.ExportImageRequest
-	22, // 49: sand.daemon.v1.DaemonService.Stats:input_type -> sand.daemon.v1.StatsRequest
-	9,  // 50: sand.daemon.v1.DaemonService.VSC:input_type -> sand.daemon.v1.IDRequest
-	47, // 51: sand.daemon.v1.DaemonService.CreateSandbox:input_type -> sand.daemon.v1.CreateSandboxRequest
-	49, // 52: sand.daemon.v1.DaemonService.RenameSandbox:input_type -> sand.daemon.v1.RenameSandboxRequest
-	52, // 53: sand.daemon.v1.DaemonService.EnsureImage:input_type -> sand.daemon.v1.EnsureImageRequest
-	5,  // 54: sand.daemon.v1.DaemonService.HTTPProxyCache:input_type -> sand.daemon.v1.HTTPProxyCacheRequest
-	6,  // 55: sand.daemon.v1.DaemonService.HTTPProxyCacheStatus:input_type -> sand.daemon.v1.HTTPProxyCacheStatusRequest
-	1,  // 56: sand.daemon.v1.DaemonService.Ping:output_type -> sand.daemon.v1.PingResponse
-	3,  // 57: sand.daemon.v1.DaemonService.Version:output_type -> sand.daemon.v1.VersionResponse
-	4,  // 58: sand.daemon.v1.DaemonService.Shutdown:output_type -> sand.daemon.v1.StatusResponse
-	10, // 59: sand.daemon.v1.DaemonService.LogSandbox:output_type -> sand.daemon.v1.LogSandboxResponse
-	12, // 60: sand.daemon.v1.DaemonService.ListSandboxes:output_type -> sand.daemon.v1.ListSandboxesResponse
-	12, // 61: sand.daemon.v1.DaemonService.ListDeletedSandboxes:output_type -> sand.daemon.v1.ListSandboxesResponse
-	13, // 62: sand.daemon.v1.DaemonService.GetSandbox:output_type -> sand.daemon.v1.GetSandboxResponse
-	4,  // 63: sand.daemon.v1.DaemonService.RemoveSandbox:output_type -> sand.daemon.v1.StatusResponse
-	4,  // 64: sand.daemon.v1.DaemonService.ExpungeSandbox:output_type -> sand.daemon.v1.StatusResponse
-	51, // 65: sand.daemon.v1.DaemonService.RecoverSandbox:output_type -> sand.daemon.v1.RecoverSandboxResponse
-	4,  // 66: sand.daemon.v1.DaemonService.StopSandbox:output_type -> sand.daemon.v1.StatusResponse
-	4,  // 67: sand.daemon.v1.DaemonService.StartSandbox:output_type -> sand.daemon.v1.StatusResponse
-	15, // 68: sand.daemon.v1.DaemonService.SyncHostGitMirror:output_type -> sand.daemon.v1.SyncHostGitMirrorResponse
-	17, // 69: sand.daemon.v1.DaemonService.ResolveAgentLaunchEnv:output_type -> sand.daemon.v1.ResolveAgentLaunchEnvResponse
-	4,  // 70: sand.daemon.v1.DaemonService.ExportImage:output_type -> sand.daemon.v1.StatusResponse
-	23, // 71: sand.daemon.v1.DaemonService.Stats:output_type -> sand.daemon.v1.StatsResponse
-	4,  // 72: sand.daemon.v1.DaemonService.VSC:output_type -> sand.daemon.v1.StatusResponse
-	48, // 73: sand.daemon.v1.DaemonService.CreateSandbox:output_type -> sand.daemon.v1.CreateSandboxResponse
-	50, // 74: sand.daemon.v1.DaemonService.RenameSandbox:output_type -> sand.daemon.v1.RenameSandboxResponse
-	53, // 75: sand.daemon.v1.DaemonService.EnsureImage:output_type -> sand.daemon.v1.EnsureImageResponse
-	4,  // 76: sand.daemon.v1.DaemonService.HTTPProxyCache:output_type -> sand.daemon.v1.StatusResponse
-	7,  // 77: sand.daemon.v1.DaemonService.HTTPProxyCacheStatus:output_type -> sand.daemon.v1.HTTPProxyCacheStatusResponse
-	56, // [56:78] is the sub-list for method output_type
-	34, // [34:56] is the sub-list for method input_type
-	34, // [34:34] is the sub-list for extension type_name
-	34, // [34:34] is the sub-list for extension extendee
-	0,  // [0:34] is the sub-list for field type_name
+	32, // 0: sand.daemon.v1.ListSandboxesResponse.boxes:type_name -> sand.daemon.v1.Sandbox
+	32, // 1: sand.daemon.v1.GetSandboxResponse.box:type_name -> sand.daemon.v1.Sandbox
+	74, // 2: sand.daemon.v1.SandboxEvent.time:type_name -> google.protobuf.Timestamp
+	24, // 3: sand.daemon.v1.ResolveAgentLaunchEnvRequest.profile_env:type_name -> sand.daemon.v1.EnvPolicy
+	70, // 4: sand.daemon.v1.ResolveAgentLaunchEnvResponse.env:type_name -> sand.daemon.v1.ResolveAgentLaunchEnvResponse.EnvEntry
+	25, // 5: sand.daemon.v1.EnvPolicy.files:type_name -> sand.daemon.v1.EnvFileRef
+	26, // 6: sand.daemon.v1.EnvPolicy.vars:type_name -> sand.daemon.v1.EnvVarRule
+	54, // 7: sand.daemon.v1.StatsResponse.stats:type_name -> sand.daemon.v1.ContainerStats
+	74, // 8: sand.daemon.v1.Sandbox.deleted_at:type_name -> google.protobuf.Timestamp
+	34, // 9: sand.daemon.v1.Sandbox.mounts:type_name -> sand.daemon.v1.MountSpec
+	35, // 10: sand.daemon.v1.Sandbox.mount_requests:type_name -> sand.daemon.v1.MountRequest
+	36, // 11: sand.daemon.v1.Sandbox.shared_cache_mounts:type_name -> sand.daemon.v1.SharedCacheMounts
+	37, // 12: sand.daemon.v1.Sandbox.original_git_details:type_name -> sand.daemon.v1.GitDetails
+	37, // 13: sand.daemon.v1.Sandbox.current_git_details:type_name -> sand.daemon.v1.GitDetails
+	38, // 14: sand.daemon.v1.Sandbox.container:type_name -> sand.daemon.v1.Container
+	74, // 15: sand.daemon.v1.Sandbox.created_at:type_name -> google.protobuf.Timestamp
+	74, // 16: sand.daemon.v1.Sandbox.last_used_at:type_name -> google.protobuf.Timestamp
+	71, // 17: sand.daemon.v1.Sandbox.labels:type_name -> sand.daemon.v1.Sandbox.LabelsEntry
+	33, // 18: sand.daemon.v1.Sandbox.dns:type_name -> sand.daemon.v1.DNSConfig
+	39, // 19: sand.daemon.v1.Container.networks:type_name -> sand.daemon.v1.ContainerNetworkStatus
+	40, // 20: sand.daemon.v1.Container.status:type_name -> sand.daemon.v1.ContainerStatus
+	41, // 21: sand.daemon.v1.Container.configuration:type_name -> sand.daemon.v1.ContainerConfig
+	42, // 22: sand.daemon.v1.ContainerConfig.mounts:type_name -> sand.daemon.v1.Mount
+	44, // 23: sand.daemon.v1.ContainerConfig.platform:type_name -> sand.daemon.v1.Platform
+	45, // 24: sand.daemon.v1.ContainerConfig.init_process:type_name -> sand.daemon.v1.InitProcess
+	48, // 25: sand.daemon.v1.ContainerConfig.dns:type_name -> sand.daemon.v1.DNS
+	49, // 26: sand.daemon.v1.ContainerConfig.networks:type_name -> sand.daemon.v1.ContainerNetwork
+	51, // 27: sand.daemon.v1.ContainerConfig.image:type_name -> sand.daemon.v1.Image
+	53, // 28: sand.daemon.v1.ContainerConfig.resources:type_name -> sand.daemon.v1.Resources
+	43, // 29: sand.daemon.v1.Mount.type:type_name -> sand.daemon.v1.MountType
+	46, // 30: sand.daemon.v1.InitProcess.user:type_name -> sand.daemon.v1.User
+	47, // 31: sand.daemon.v1.User.id:type_name -> sand.daemon.v1.UserID
+	50, // 32: sand.daemon.v1.ContainerNetwork.options:type_name -> sand.daemon.v1.NetworkOptions
+	52, // 33: sand.daemon.v1.Image.descriptor:type_name -> sand.daemon.v1.Descriptor
+	55, // 34: sand.daemon.v1.CreateSandboxRequest.shared_caches:type_name -> sand.daemon.v1.SharedCacheConfig
+	72, // 35: sand.daemon.v1.CreateSandboxRequest.labels:type_name -> sand.daemon.v1.CreateSandboxRequest.LabelsEntry
+	33, // 36: sand.daemon.v1.CreateSandboxRequest.dns:type_name -> sand.daemon.v1.DNSConfig
+	32, // 37: sand.daemon.v1.CreateSandboxResponse.box:type_name -> sand.daemon.v1.Sandbox
+	32, // 38: sand.daemon.v1.RenameSandboxResponse.box:type_name -> sand.daemon.v1.Sandbox
+	32, // 39: sand.daemon.v1.ForkSandboxResponse.box:type_name -> sand.daemon.v1.Sandbox
+	32, // 40: sand.daemon.v1.RecoverSandboxResponse.box:type_name -> sand.daemon.v1.Sandbox
+	73, // 41: sand.daemon.v1.EnsureImageRequest.build_args:type_name -> sand.daemon.v1.EnsureImageRequest.BuildArgsEntry
+	65, // 42: sand.daemon.v1.EnsureImageResponse.pull_progress:type_name -> sand.daemon.v1.ImagePullProgressUpdate
+	66, // 43: sand.daemon.v1.PortForwardRequest.forward:type_name -> sand.daemon.v1.PortForward
+	66, // 44: sand.daemon.v1.PortForwardResponse.forward:type_name -> sand.daemon.v1.PortForward
+	66, // 45: sand.daemon.v1.PortForwardsResponse.forwards:type_name -> sand.daemon.v1.PortForward
+	0,  // 46: sand.daemon.v1.DaemonService.Ping:input_type -> sand.daemon.v1.PingRequest
+	2,  // 47: sand.daemon.v1.DaemonService.Version:input_type -> sand.daemon.v1.VersionRequest
+	8,  // 48: sand.daemon.v1.DaemonService.Shutdown:input_type -> sand.daemon.v1.ShutdownRequest
+	9,  // 49: sand.daemon.v1.DaemonService.LogSandbox:input_type -> sand.daemon.v1.IDRequest
+	11, // 50: sand.daemon.v1.DaemonService.ListSandboxes:input_type -> sand.daemon.v1.ListSandboxesRequest
+	11, // 51: sand.daemon.v1.DaemonService.ListSandboxesDetailed:input_type -> sand.daemon.v1.ListSandboxesRequest
+	11, // 52: sand.daemon.v1.DaemonService.ListDeletedSandboxes:input_type -> sand.daemon.v1.ListSandboxesRequest
+	11, // 53: sand.daemon.v1.DaemonService.SyncSandboxes:input_type -> sand.daemon.v1.ListSandboxesRequest
+	9,  // 54: sand.daemon.v1.DaemonService.GetSandbox:input_type -> sand.daemon.v1.IDRequest
+	9,  // 55: sand.daemon.v1.DaemonService.RemoveSandbox:input_type -> sand.daemon.v1.IDRequest
+	9,  // 56: sand.daemon.v1.DaemonService.ExpungeSandbox:input_type -> sand.daemon.v1.IDRequest
+	9,  // 57: sand.daemon.v1.DaemonService.RecoverSandbox:input_type -> sand.daemon.v1.IDRequest
+	9,  // 58: sand.daemon.v1.DaemonService.StopSandbox:input_type -> sand.daemon.v1.IDRequest
+	15, // 59: sand.daemon.v1.DaemonService.KillSandbox:input_type -> sand.daemon.v1.KillSandboxRequest
+	14, // 60: sand.daemon.v1.DaemonService.StartSandbox:input_type -> sand.daemon.v1.StartSandboxRequest
+	9,  // 61: sand.daemon.v1.DaemonService.MarkSandboxUsed:input_type -> sand.daemon.v1.IDRequest
+	9,  // 62: sand.daemon.v1.DaemonService.SyncHostGitMirror:input_type -> sand.daemon.v1.IDRequest
+	17, // 63: sand.daemon.v1.DaemonService.FetchHostChanges:input_type -> sand.daemon.v1.FetchHostChangesRequest
+	9,  // 64: sand.daemon.v1.DaemonService.ResyncWorkspace:input_type -> sand.daemon.v1.IDRequest
+	22, // 65: sand.daemon.v1.DaemonService.ResolveAgentLaunchEnv:input_type -> sand.daemon.v1.ResolveAgentLaunchEnvRequest
+	27, // 66: sand.daemon.v1.DaemonService.ExportImage:input_type -> sand.daemon.v1.ExportImageRequest
+	28, // 67: sand.daemon.v1.DaemonService.PruneImages:input_type -> sand.daemon.v1.PruneImagesRequest
+	30, // 68: sand.daemon.v1.DaemonService.Stats:input_type -> sand.daemon.v1.StatsRequest
+	9,  // 69: sand.daemon.v1.DaemonService.VSC:input_type -> sand.daemon.v1.IDRequest
+	56, // 70: sand.daemon.v1.DaemonService.CreateSandbox:input_type -> sand.daemon.v1.CreateSandboxRequest
+	58, // 71: sand.daemon.v1.DaemonService.RenameSandbox:input_type -> sand.daemon.v1.RenameSandboxRequest
+	60, // 72: sand.daemon.v1.DaemonService.ForkSandbox:input_type -> sand.daemon.v1.ForkSandboxRequest
+	63, // 73: sand.daemon.v1.DaemonService.EnsureImage:input_type -> sand.daemon.v1.EnsureImageRequest
+	5,  // 74: sand.daemon.v1.DaemonService.HTTPProxyCache:input_type -> sand.daemon.v1.HTTPProxyCacheRequest
+	6,  // 75: sand.daemon.v1.DaemonService.HTTPProxyCacheStatus:input_type -> sand.daemon.v1.HTTPProxyCacheStatusRequest
+	20, // 76: sand.daemon.v1.DaemonService.WatchEvents:input_type -> sand.daemon.v1.WatchEventsRequest
+	67, // 77: sand.daemon.v1.DaemonService.StartPortForward:input_type -> sand.daemon.v1.PortForwardRequest
+	9,  // 78: sand.daemon.v1.DaemonService.ListPortForwards:input_type -> sand.daemon.v1.IDRequest
+	67, // 79: sand.daemon.v1.DaemonService.StopPortForwards:input_type -> sand.daemon.v1.PortForwardRequest
+	1,  // 80: sand.daemon.v1.DaemonService.Ping:output_type -> sand.daemon.v1.PingResponse
+	3,  // 81: sand.daemon.v1.DaemonService.Version:output_type -> sand.daemon.v1.VersionResponse
+	4,  // 82: sand.daemon.v1.DaemonService.Shutdown:output_type -> sand.daemon.v1.StatusResponse
+	10, // 83: sand.daemon.v1.DaemonService.LogSandbox:output_type -> sand.daemon.v1.LogSandboxResponse
+	12, // 84: sand.daemon.v1.DaemonService.ListSandboxes:output_type -> sand.daemon.v1.ListSandboxesResponse
+	12, // 85: sand.daemon.v1.DaemonService.ListSandboxesDetailed:output_type -> sand.daemon.v1.ListSandboxesResponse
+	12, // 86: sand.daemon.v1.DaemonService.ListDeletedSandboxes:output_type -> sand.daemon.v1.ListSandboxesResponse
+	12, // 87: sand.daemon.v1.DaemonService.SyncSandboxes:output_type -> sand.daemon.v1.ListSandboxesResponse
+	13, // 88: sand.daemon.v1.DaemonService.GetSandbox:output_type -> sand.daemon.v1.GetSandboxResponse
+	4,  // 89: sand.daemon.v1.DaemonService.RemoveSandbox:output_type -> sand.daemon.v1.StatusResponse
+	4,  // 90: sand.daemon.v1.DaemonService.ExpungeSandbox:output_type -> sand.daemon.v1.StatusResponse
+	62, // 91: sand.daemon.v1.DaemonService.RecoverSandbox:output_type -> sand.daemon.v1.RecoverSandboxResponse
+	4,  // 92: sand.daemon.v1.DaemonService.StopSandbox:output_type -> sand.daemon.v1.StatusResponse
+	4,  // 93: sand.daemon.v1.DaemonService.KillSandbox:output_type -> sand.daemon.v1.StatusResponse
+	4,  // 94: sand.daemon.v1.DaemonService.StartSandbox:output_type -> sand.daemon.v1.StatusResponse
+	4,  // 95: sand.daemon.v1.DaemonService.MarkSandboxUsed:output_type -> sand.daemon.v1.StatusResponse
+	16, // 96: sand.daemon.v1.DaemonService.SyncHostGitMirror:output_type -> sand.daemon.v1.SyncHostGitMirrorResponse
+	18, // 97: sand.daemon.v1.DaemonService.FetchHostChanges:output_type -> sand.daemon.v1.FetchHostChangesResponse
+	19, // 98: sand.daemon.v1.DaemonService.ResyncWorkspace:output_type -> sand.daemon.v1.ResyncWorkspaceResponse
+	23, // 99: sand.daemon.v1.DaemonService.ResolveAgentLaunchEnv:output_type -> sand.daemon.v1.ResolveAgentLaunchEnvResponse
+	4,  // 100: sand.daemon.v1.DaemonService.ExportImage:output_type -> sand.daemon.v1.StatusResponse
+	29, // 101: sand.daemon.v1.DaemonService.PruneImages:output_type -> sand.daemon.v1.PruneImagesResponse
+	31, // 102: sand.daemon.v1.DaemonService.Stats:output_type -> sand.daemon.v1.StatsResponse
+	4,  // 103: sand.daemon.v1.DaemonService.VSC:output_type -> sand.daemon.v1.StatusResponse
+	57, // 104: sand.daemon.v1.DaemonService.CreateSandbox:output_type -> sand.daemon.v1.CreateSandboxResponse
+	59, // 105: sand.daemon.v1.DaemonService.RenameSandbox:output_type -> sand.daemon.v1.RenameSandboxResponse
+	61, // 106: sand.daemon.v1.DaemonService.ForkSandbox:output_type -> sand.daemon.v1.ForkSandboxResponse
+	64, // 107: sand.daemon.v1.DaemonService.EnsureImage:output_type -> sand.daemon.v1.EnsureImageResponse
+	4,  // 108: sand.daemon.v1.DaemonService.HTTPProxyCache:output_type -> sand.daemon.v1.StatusResponse
+	7,  // 109: sand.daemon.v1.DaemonService.HTTPProxyCacheStatus:output_type -> sand.daemon.v1.HTTPProxyCacheStatusResponse
+	21, // 110: sand.daemon.v1.DaemonService.WatchEvents:output_type -> sand.daemon.v1.SandboxEvent
+	68, // 111: sand.daemon.v1.DaemonService.StartPortForward:output_type -> sand.daemon.v1.PortForwardResponse
+	69, // 112: sand.daemon.v1.DaemonService.ListPortForwards:output_type -> sand.daemon.v1.PortForwardsResponse
+	69, // 113: sand.daemon.v1.DaemonService.StopPortForwards:output_type -> sand.daemon.v1.PortForwardsResponse
+	80, // [80:114] is the sub-list for method output_type
+	46, // [46:80] is the sub-list for method input_type
+	46, // [46:46] is the sub-list for extension type_name
+	46, // [46:46] is the sub-list for extension extendee
+	0,  // [0:46] is the sub-list for field type_name
 }
 
 func init() { file_internal_daemon_daemonpb_daemon_proto_init() }
@@ -4159,25 +5345,25 @@ func file_internal_daemon_daemonpb_daemon_proto_init() {
 	if File_internal_daemon_daemonpb_daemon_proto != nil {
 		return
 	}
-	file_internal_daemon_daemonpb_daemon_proto_msgTypes[48].OneofWrappers = []any{
+	file_internal_daemon_daemonpb_daemon_proto_msgTypes[57].OneofWrappers = []any{
 		(*CreateSandboxResponse_Progress)(nil),
 		(*CreateSandboxResponse_Box)(nil),
 		(*CreateSandboxResponse_Error)(nil),
 	}
-	file_internal_daemon_daemonpb_daemon_proto_msgTypes[53].OneofWrappers = []any{
+	file_internal_daemon_daemonpb_daemon_proto_msgTypes[64].OneofWrappers = []any{
 		(*EnsureImageResponse_Progress)(nil),
 		(*EnsureImageResponse_Error)(nil),
 		(*EnsureImageResponse_Ok)(nil),
 		(*EnsureImageResponse_PullProgress)(nil),
 	}
-	file_internal_daemon_daemonpb_daemon_proto_msgTypes[54].OneofWrappers = []any{}
+	file_internal_daemon_daemonpb_daemon_proto_msgTypes[65].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_internal_daemon_daemonpb_daemon_proto_rawDesc), len(file_internal_daemon_daemonpb_daemon_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   56,
+			NumMessages:   74,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
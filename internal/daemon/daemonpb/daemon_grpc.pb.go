@@ -24,23 +24,35 @@ const (
 	DaemonService_Shutdown_FullMethodName              = "/sand.daemon.v1.DaemonService/Shutdown"
 	DaemonService_LogSandbox_FullMethodName            = "/sand.daemon.v1.DaemonService/LogSandbox"
 	DaemonService_ListSandboxes_FullMethodName         = "/sand.daemon.v1.DaemonService/ListSandboxes"
+	DaemonService_ListSandboxesDetailed_FullMethodName = "/sand.daemon.v1.DaemonService/ListSandboxesDetailed"
 	DaemonService_ListDeletedSandboxes_FullMethodName  = "/sand.daemon.v1.DaemonService/ListDeletedSandboxes"
+	DaemonService_SyncSandboxes_FullMethodName         = "/sand.daemon.v1.DaemonService/SyncSandboxes"
 	DaemonService_GetSandbox_FullMethodName            = "/sand.daemon.v1.DaemonService/GetSandbox"
 	DaemonService_RemoveSandbox_FullMethodName         = "/sand.daemon.v1.DaemonService/RemoveSandbox"
 	DaemonService_ExpungeSandbox_FullMethodName        = "/sand.daemon.v1.DaemonService/ExpungeSandbox"
 	DaemonService_RecoverSandbox_FullMethodName        = "/sand.daemon.v1.DaemonService/RecoverSandbox"
 	DaemonService_StopSandbox_FullMethodName           = "/sand.daemon.v1.DaemonService/StopSandbox"
+	DaemonService_KillSandbox_FullMethodName           = "/sand.daemon.v1.DaemonService/KillSandbox"
 	DaemonService_StartSandbox_FullMethodName          = "/sand.daemon.v1.DaemonService/StartSandbox"
+	DaemonService_MarkSandboxUsed_FullMethodName       = "/sand.daemon.v1.DaemonService/MarkSandboxUsed"
 	DaemonService_SyncHostGitMirror_FullMethodName     = "/sand.daemon.v1.DaemonService/SyncHostGitMirror"
+	DaemonService_FetchHostChanges_FullMethodName      = "/sand.daemon.v1.DaemonService/FetchHostChanges"
+	DaemonService_ResyncWorkspace_FullMethodName       = "/sand.daemon.v1.DaemonService/ResyncWorkspace"
 	DaemonService_ResolveAgentLaunchEnv_FullMethodName = "/sand.daemon.v1.DaemonService/ResolveAgentLaunchEnv"
 	DaemonService_ExportImage_FullMethodName           = "/sand.daemon.v1.DaemonService/ExportImage"
+	DaemonService_PruneImages_FullMethodName           = "/sand.daemon.v1.DaemonService/PruneImages"
 	DaemonService_Stats_FullMethodName                 = "/sand.daemon.v1.DaemonService/Stats"
 	DaemonService_VSC_FullMethodName                   = "/sand.daemon.v1.DaemonService/VSC"
 	DaemonService_CreateSandbox_FullMethodName         = "/sand.daemon.v1.DaemonService/CreateSandbox"
 	DaemonService_RenameSandbox_FullMethodName         = "/sand.daemon.v1.DaemonService/RenameSandbox"
+	DaemonService_ForkSandbox_FullMethodName           = "/sand.daemon.v1.DaemonService/ForkSandbox"
 	DaemonService_EnsureImage_FullMethodName           = "/sand.daemon.v1.DaemonService/EnsureImage"
 	DaemonService_HTTPProxyCache_FullMethodName        = "/sand.daemon.v1.DaemonService/HTTPProxyCache"
 	DaemonService_HTTPProxyCacheStatus_FullMethodName  = "/sand.daemon.v1.DaemonService/HTTPProxyCacheStatus"
+	DaemonService_WatchEvents_FullMethodName           = "/sand.daemon.v1.DaemonService/WatchEvents"
+	DaemonService_StartPortForward_FullMethodName      = "/sand.daemon.v1.DaemonService/StartPortForward"
+	DaemonService_ListPortForwards_FullMethodName      = "/sand.daemon.v1.DaemonService/ListPortForwards"
+	DaemonService_StopPortForwards_FullMethodName      = "/sand.daemon.v1.DaemonService/StopPortForwards"
 )
 
 // DaemonServiceClient is the client API for DaemonService service.
@@ -52,23 +64,35 @@ type DaemonServiceClient interface {
 	Shutdown(ctx context.Context, in *ShutdownRequest, opts ...grpc.CallOption) (*StatusResponse, error)
 	LogSandbox(ctx context.Context, in *IDRequest, opts ...grpc.CallOption) (*LogSandboxResponse, error)
 	ListSandboxes(ctx context.Context, in *ListSandboxesRequest, opts ...grpc.CallOption) (*ListSandboxesResponse, error)
+	ListSandboxesDetailed(ctx context.Context, in *ListSandboxesRequest, opts ...grpc.CallOption) (*ListSandboxesResponse, error)
 	ListDeletedSandboxes(ctx context.Context, in *ListSandboxesRequest, opts ...grpc.CallOption) (*ListSandboxesResponse, error)
+	SyncSandboxes(ctx context.Context, in *ListSandboxesRequest, opts ...grpc.CallOption) (*ListSandboxesResponse, error)
 	GetSandbox(ctx context.Context, in *IDRequest, opts ...grpc.CallOption) (*GetSandboxResponse, error)
 	RemoveSandbox(ctx context.Context, in *IDRequest, opts ...grpc.CallOption) (*StatusResponse, error)
 	ExpungeSandbox(ctx context.Context, in *IDRequest, opts ...grpc.CallOption) (*StatusResponse, error)
 	RecoverSandbox(ctx context.Context, in *IDRequest, opts ...grpc.CallOption) (*RecoverSandboxResponse, error)
 	StopSandbox(ctx context.Context, in *IDRequest, opts ...grpc.CallOption) (*StatusResponse, error)
+	KillSandbox(ctx context.Context, in *KillSandboxRequest, opts ...grpc.CallOption) (*StatusResponse, error)
 	StartSandbox(ctx context.Context, in *StartSandboxRequest, opts ...grpc.CallOption) (*StatusResponse, error)
+	MarkSandboxUsed(ctx context.Context, in *IDRequest, opts ...grpc.CallOption) (*StatusResponse, error)
 	SyncHostGitMirror(ctx context.Context, in *IDRequest, opts ...grpc.CallOption) (*SyncHostGitMirrorResponse, error)
+	FetchHostChanges(ctx context.Context, in *FetchHostChangesRequest, opts ...grpc.CallOption) (*FetchHostChangesResponse, error)
+	ResyncWorkspace(ctx context.Context, in *IDRequest, opts ...grpc.CallOption) (*ResyncWorkspaceResponse, error)
 	ResolveAgentLaunchEnv(ctx context.Context, in *ResolveAgentLaunchEnvRequest, opts ...grpc.CallOption) (*ResolveAgentLaunchEnvResponse, error)
 	ExportImage(ctx context.Context, in *ExportImageRequest, opts ...grpc.CallOption) (*StatusResponse, error)
+	PruneImages(ctx context.Context, in *PruneImagesRequest, opts ...grpc.CallOption) (*PruneImagesResponse, error)
 	Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error)
 	VSC(ctx context.Context, in *IDRequest, opts ...grpc.CallOption) (*StatusResponse, error)
 	CreateSandbox(ctx context.Context, in *CreateSandboxRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[CreateSandboxResponse], error)
 	RenameSandbox(ctx context.Context, in *RenameSandboxRequest, opts ...grpc.CallOption) (*RenameSandboxResponse, error)
+	ForkSandbox(ctx context.Context, in *ForkSandboxRequest, opts ...grpc.CallOption) (*ForkSandboxResponse, error)
 	EnsureImage(ctx context.Context, in *EnsureImageRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[EnsureImageResponse], error)
 	HTTPProxyCache(ctx context.Context, in *HTTPProxyCacheRequest, opts ...grpc.CallOption) (*StatusResponse, error)
 	HTTPProxyCacheStatus(ctx context.Context, in *HTTPProxyCacheStatusRequest, opts ...grpc.CallOption) (*HTTPProxyCacheStatusResponse, error)
+	WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SandboxEvent], error)
+	StartPortForward(ctx context.Context, in *PortForwardRequest, opts ...grpc.CallOption) (*PortForwardResponse, error)
+	ListPortForwards(ctx context.Context, in *IDRequest, opts ...grpc.CallOption) (*PortForwardsResponse, error)
+	StopPortForwards(ctx context.Context, in *PortForwardRequest, opts ...grpc.CallOption) (*PortForwardsResponse, error)
 }
 
 type daemonServiceClient struct {
@@ -129,6 +153,16 @@ func (c *daemonServiceClient) ListSandboxes(ctx context.Context, in *ListSandbox
 	return out, nil
 }
 
+func (c *daemonServiceClient) ListSandboxesDetailed(ctx context.Context, in *ListSandboxesRequest, opts ...grpc.CallOption) (*ListSandboxesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSandboxesResponse)
+	err := c.cc.Invoke(ctx, DaemonService_ListSandboxesDetailed_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *daemonServiceClient) ListDeletedSandboxes(ctx context.Context, in *ListSandboxesRequest, opts ...grpc.CallOption) (*ListSandboxesResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(ListSandboxesResponse)
@@ -139,6 +173,16 @@ func (c *daemonServiceClient) ListDeletedSandboxes(ctx context.Context, in *List
 	return out, nil
 }
 
+func (c *daemonServiceClient) SyncSandboxes(ctx context.Context, in *ListSandboxesRequest, opts ...grpc.CallOption) (*ListSandboxesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSandboxesResponse)
+	err := c.cc.Invoke(ctx, DaemonService_SyncSandboxes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *daemonServiceClient) GetSandbox(ctx context.Context, in *IDRequest, opts ...grpc.CallOption) (*GetSandboxResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(GetSandboxResponse)
@@ -189,6 +233,16 @@ func (c *daemonServiceClient) StopSandbox(ctx context.Context, in *IDRequest, op
 	return out, nil
 }
 
+func (c *daemonServiceClient) KillSandbox(ctx context.Context, in *KillSandboxRequest, opts ...grpc.CallOption) (*StatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StatusResponse)
+	err := c.cc.Invoke(ctx, DaemonService_KillSandbox_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *daemonServiceClient) StartSandbox(ctx context.Context, in *StartSandboxRequest, opts ...grpc.CallOption) (*StatusResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(StatusResponse)
@@ -199,6 +253,16 @@ func (c *daemonServiceClient) StartSandbox(ctx context.Context, in *StartSandbox
 	return out, nil
 }
 
+func (c *daemonServiceClient) MarkSandboxUsed(ctx context.Context, in *IDRequest, opts ...grpc.CallOption) (*StatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StatusResponse)
+	err := c.cc.Invoke(ctx, DaemonService_MarkSandboxUsed_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *daemonServiceClient) SyncHostGitMirror(ctx context.Context, in *IDRequest, opts ...grpc.CallOption) (*SyncHostGitMirrorResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(SyncHostGitMirrorResponse)
@@ -209,6 +273,26 @@ func (c *daemonServiceClient) SyncHostGitMirror(ctx context.Context, in *IDReque
 	return out, nil
 }
 
+func (c *daemonServiceClient) FetchHostChanges(ctx context.Context, in *FetchHostChangesRequest, opts ...grpc.CallOption) (*FetchHostChangesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FetchHostChangesResponse)
+	err := c.cc.Invoke(ctx, DaemonService_FetchHostChanges_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *daemonServiceClient) ResyncWorkspace(ctx context.Context, in *IDRequest, opts ...grpc.CallOption) (*ResyncWorkspaceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ResyncWorkspaceResponse)
+	err := c.cc.Invoke(ctx, DaemonService_ResyncWorkspace_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *daemonServiceClient) ResolveAgentLaunchEnv(ctx context.Context, in *ResolveAgentLaunchEnvRequest, opts ...grpc.CallOption) (*ResolveAgentLaunchEnvResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(ResolveAgentLaunchEnvResponse)
@@ -229,6 +313,16 @@ func (c *daemonServiceClient) ExportImage(ctx context.Context, in *ExportImageRe
 	return out, nil
 }
 
+func (c *daemonServiceClient) PruneImages(ctx context.Context, in *PruneImagesRequest, opts ...grpc.CallOption) (*PruneImagesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PruneImagesResponse)
+	err := c.cc.Invoke(ctx, DaemonService_PruneImages_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *daemonServiceClient) Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(StatsResponse)
@@ -278,6 +372,16 @@ func (c *daemonServiceClient) RenameSandbox(ctx context.Context, in *RenameSandb
 	return out, nil
 }
 
+func (c *daemonServiceClient) ForkSandbox(ctx context.Context, in *ForkSandboxRequest, opts ...grpc.CallOption) (*ForkSandboxResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ForkSandboxResponse)
+	err := c.cc.Invoke(ctx, DaemonService_ForkSandbox_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *daemonServiceClient) EnsureImage(ctx context.Context, in *EnsureImageRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[EnsureImageResponse], error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	stream, err := c.cc.NewStream(ctx, &DaemonService_ServiceDesc.Streams[1], DaemonService_EnsureImage_FullMethodName, cOpts...)
@@ -317,6 +421,55 @@ func (c *daemonServiceClient) HTTPProxyCacheStatus(ctx context.Context, in *HTTP
 	return out, nil
 }
 
+func (c *daemonServiceClient) WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SandboxEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &DaemonService_ServiceDesc.Streams[2], DaemonService_WatchEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchEventsRequest, SandboxEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type DaemonService_WatchEventsClient = grpc.ServerStreamingClient[SandboxEvent]
+
+func (c *daemonServiceClient) StartPortForward(ctx context.Context, in *PortForwardRequest, opts ...grpc.CallOption) (*PortForwardResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PortForwardResponse)
+	err := c.cc.Invoke(ctx, DaemonService_StartPortForward_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *daemonServiceClient) ListPortForwards(ctx context.Context, in *IDRequest, opts ...grpc.CallOption) (*PortForwardsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PortForwardsResponse)
+	err := c.cc.Invoke(ctx, DaemonService_ListPortForwards_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *daemonServiceClient) StopPortForwards(ctx context.Context, in *PortForwardRequest, opts ...grpc.CallOption) (*PortForwardsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PortForwardsResponse)
+	err := c.cc.Invoke(ctx, DaemonService_StopPortForwards_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // DaemonServiceServer is the server API for DaemonService service.
 // All implementations must embed UnimplementedDaemonServiceServer
 // for forward compatibility.
@@ -326,23 +479,35 @@ type DaemonServiceServer interface {
 	Shutdown(context.Context, *ShutdownRequest) (*StatusResponse, error)
 	LogSandbox(context.Context, *IDRequest) (*LogSandboxResponse, error)
 	ListSandboxes(context.Context, *ListSandboxesRequest) (*ListSandboxesResponse, error)
+	ListSandboxesDetailed(context.Context, *ListSandboxesRequest) (*ListSandboxesResponse, error)
 	ListDeletedSandboxes(context.Context, *ListSandboxesRequest) (*ListSandboxesResponse, error)
+	SyncSandboxes(context.Context, *ListSandboxesRequest) (*ListSandboxesResponse, error)
 	GetSandbox(context.Context, *IDRequest) (*GetSandboxResponse, error)
 	RemoveSandbox(context.Context, *IDRequest) (*StatusResponse, error)
 	ExpungeSandbox(context.Context, *IDRequest) (*StatusResponse, error)
 	RecoverSandbox(context.Context, *IDRequest) (*RecoverSandboxResponse, error)
 	StopSandbox(context.Context, *IDRequest) (*StatusResponse, error)
+	KillSandbox(context.Context, *KillSandboxRequest) (*StatusResponse, error)
 	StartSandbox(context.Context, *StartSandboxRequest) (*StatusResponse, error)
+	MarkSandboxUsed(context.Context, *IDRequest) (*StatusResponse, error)
 	SyncHostGitMirror(context.Context, *IDRequest) (*SyncHostGitMirrorResponse, error)
+	FetchHostChanges(context.Context, *FetchHostChangesRequest) (*FetchHostChangesResponse, error)
+	ResyncWorkspace(context.Context, *IDRequest) (*ResyncWorkspaceResponse, error)
 	ResolveAgentLaunchEnv(context.Context, *ResolveAgentLaunchEnvRequest) (*ResolveAgentLaunchEnvResponse, error)
 	ExportImage(context.Context, *ExportImageRequest) (*StatusResponse, error)
+	PruneImages(context.Context, *PruneImagesRequest) (*PruneImagesResponse, error)
 	Stats(context.Context, *StatsRequest) (*StatsResponse, error)
 	VSC(context.Context, *IDRequest) (*StatusResponse, error)
 	CreateSandbox(*CreateSandboxRequest, grpc.ServerStreamingServer[CreateSandboxResponse]) error
 	RenameSandbox(context.Context, *RenameSandboxRequest) (*RenameSandboxResponse, error)
+	ForkSandbox(context.Context, *ForkSandboxRequest) (*ForkSandboxResponse, error)
 	EnsureImage(*EnsureImageRequest, grpc.ServerStreamingServer[EnsureImageResponse]) error
 	HTTPProxyCache(context.Context, *HTTPProxyCacheRequest) (*StatusResponse, error)
 	HTTPProxyCacheStatus(context.Context, *HTTPProxyCacheStatusRequest) (*HTTPProxyCacheStatusResponse, error)
+	WatchEvents(*WatchEventsRequest, grpc.ServerStreamingServer[SandboxEvent]) error
+	StartPortForward(context.Context, *PortForwardRequest) (*PortForwardResponse, error)
+	ListPortForwards(context.Context, *IDRequest) (*PortForwardsResponse, error)
+	StopPortForwards(context.Context, *PortForwardRequest) (*PortForwardsResponse, error)
 	mustEmbedUnimplementedDaemonServiceServer()
 }
 
@@ -368,9 +533,15 @@ func (UnimplementedDaemonServiceServer) LogSandbox(context.Context, *IDRequest)
 func (UnimplementedDaemonServiceServer) ListSandboxes(context.Context, *ListSandboxesRequest) (*ListSandboxesResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method ListSandboxes not implemented")
 }
+func (UnimplementedDaemonServiceServer) ListSandboxesDetailed(context.Context, *ListSandboxesRequest) (*ListSandboxesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListSandboxesDetailed not implemented")
+}
 func (UnimplementedDaemonServiceServer) ListDeletedSandboxes(context.Context, *ListSandboxesRequest) (*ListSandboxesResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method ListDeletedSandboxes not implemented")
 }
+func (UnimplementedDaemonServiceServer) SyncSandboxes(context.Context, *ListSandboxesRequest) (*ListSandboxesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SyncSandboxes not implemented")
+}
 func (UnimplementedDaemonServiceServer) GetSandbox(context.Context, *IDRequest) (*GetSandboxResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method GetSandbox not implemented")
 }
@@ -386,18 +557,33 @@ func (UnimplementedDaemonServiceServer) RecoverSandbox(context.Context, *IDReque
 func (UnimplementedDaemonServiceServer) StopSandbox(context.Context, *IDRequest) (*StatusResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method StopSandbox not implemented")
 }
+func (UnimplementedDaemonServiceServer) KillSandbox(context.Context, *KillSandboxRequest) (*StatusResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method KillSandbox not implemented")
+}
 func (UnimplementedDaemonServiceServer) StartSandbox(context.Context, *StartSandboxRequest) (*StatusResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method StartSandbox not implemented")
 }
+func (UnimplementedDaemonServiceServer) MarkSandboxUsed(context.Context, *IDRequest) (*StatusResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method MarkSandboxUsed not implemented")
+}
 func (UnimplementedDaemonServiceServer) SyncHostGitMirror(context.Context, *IDRequest) (*SyncHostGitMirrorResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method SyncHostGitMirror not implemented")
 }
+func (UnimplementedDaemonServiceServer) FetchHostChanges(context.Context, *FetchHostChangesRequest) (*FetchHostChangesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method FetchHostChanges not implemented")
+}
+func (UnimplementedDaemonServiceServer) ResyncWorkspace(context.Context, *IDRequest) (*ResyncWorkspaceResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ResyncWorkspace not implemented")
+}
 func (UnimplementedDaemonServiceServer) ResolveAgentLaunchEnv(context.Context, *ResolveAgentLaunchEnvRequest) (*ResolveAgentLaunchEnvResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method ResolveAgentLaunchEnv not implemented")
 }
 func (UnimplementedDaemonServiceServer) ExportImage(context.Context, *ExportImageRequest) (*StatusResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method ExportImage not implemented")
 }
+func (UnimplementedDaemonServiceServer) PruneImages(context.Context, *PruneImagesRequest) (*PruneImagesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method PruneImages not implemented")
+}
 func (UnimplementedDaemonServiceServer) Stats(context.Context, *StatsRequest) (*StatsResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method Stats not implemented")
 }
@@ -410,6 +596,9 @@ func (UnimplementedDaemonServiceServer) CreateSandbox(*CreateSandboxRequest, grp
 func (UnimplementedDaemonServiceServer) RenameSandbox(context.Context, *RenameSandboxRequest) (*RenameSandboxResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method RenameSandbox not implemented")
 }
+func (UnimplementedDaemonServiceServer) ForkSandbox(context.Context, *ForkSandboxRequest) (*ForkSandboxResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ForkSandbox not implemented")
+}
 func (UnimplementedDaemonServiceServer) EnsureImage(*EnsureImageRequest, grpc.ServerStreamingServer[EnsureImageResponse]) error {
 	return status.Error(codes.Unimplemented, "method EnsureImage not implemented")
 }
@@ -419,6 +608,18 @@ func (UnimplementedDaemonServiceServer) HTTPProxyCache(context.Context, *HTTPPro
 func (UnimplementedDaemonServiceServer) HTTPProxyCacheStatus(context.Context, *HTTPProxyCacheStatusRequest) (*HTTPProxyCacheStatusResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method HTTPProxyCacheStatus not implemented")
 }
+func (UnimplementedDaemonServiceServer) WatchEvents(*WatchEventsRequest, grpc.ServerStreamingServer[SandboxEvent]) error {
+	return status.Error(codes.Unimplemented, "method WatchEvents not implemented")
+}
+func (UnimplementedDaemonServiceServer) StartPortForward(context.Context, *PortForwardRequest) (*PortForwardResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method StartPortForward not implemented")
+}
+func (UnimplementedDaemonServiceServer) ListPortForwards(context.Context, *IDRequest) (*PortForwardsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListPortForwards not implemented")
+}
+func (UnimplementedDaemonServiceServer) StopPortForwards(context.Context, *PortForwardRequest) (*PortForwardsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method StopPortForwards not implemented")
+}
 func (UnimplementedDaemonServiceServer) mustEmbedUnimplementedDaemonServiceServer() {}
 func (UnimplementedDaemonServiceServer) testEmbeddedByValue()                       {}
 
@@ -530,6 +731,24 @@ func _DaemonService_ListSandboxes_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DaemonService_ListSandboxesDetailed_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSandboxesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServiceServer).ListSandboxesDetailed(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DaemonService_ListSandboxesDetailed_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServiceServer).ListSandboxesDetailed(ctx, req.(*ListSandboxesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _DaemonService_ListDeletedSandboxes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ListSandboxesRequest)
 	if err := dec(in); err != nil {
@@ -548,6 +767,24 @@ func _DaemonService_ListDeletedSandboxes_Handler(srv interface{}, ctx context.Co
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DaemonService_SyncSandboxes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSandboxesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServiceServer).SyncSandboxes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DaemonService_SyncSandboxes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServiceServer).SyncSandboxes(ctx, req.(*ListSandboxesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _DaemonService_GetSandbox_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(IDRequest)
 	if err := dec(in); err != nil {
@@ -638,6 +875,24 @@ func _DaemonService_StopSandbox_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DaemonService_KillSandbox_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(KillSandboxRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServiceServer).KillSandbox(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DaemonService_KillSandbox_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServiceServer).KillSandbox(ctx, req.(*KillSandboxRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _DaemonService_StartSandbox_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(StartSandboxRequest)
 	if err := dec(in); err != nil {
@@ -656,6 +911,24 @@ func _DaemonService_StartSandbox_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DaemonService_MarkSandboxUsed_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServiceServer).MarkSandboxUsed(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DaemonService_MarkSandboxUsed_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServiceServer).MarkSandboxUsed(ctx, req.(*IDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _DaemonService_SyncHostGitMirror_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(IDRequest)
 	if err := dec(in); err != nil {
@@ -674,6 +947,42 @@ func _DaemonService_SyncHostGitMirror_Handler(srv interface{}, ctx context.Conte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DaemonService_FetchHostChanges_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FetchHostChangesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServiceServer).FetchHostChanges(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DaemonService_FetchHostChanges_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServiceServer).FetchHostChanges(ctx, req.(*FetchHostChangesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DaemonService_ResyncWorkspace_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServiceServer).ResyncWorkspace(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DaemonService_ResyncWorkspace_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServiceServer).ResyncWorkspace(ctx, req.(*IDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _DaemonService_ResolveAgentLaunchEnv_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ResolveAgentLaunchEnvRequest)
 	if err := dec(in); err != nil {
@@ -710,6 +1019,24 @@ func _DaemonService_ExportImage_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DaemonService_PruneImages_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PruneImagesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServiceServer).PruneImages(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DaemonService_PruneImages_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServiceServer).PruneImages(ctx, req.(*PruneImagesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _DaemonService_Stats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(StatsRequest)
 	if err := dec(in); err != nil {
@@ -775,6 +1102,24 @@ func _DaemonService_RenameSandbox_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DaemonService_ForkSandbox_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ForkSandboxRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServiceServer).ForkSandbox(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DaemonService_ForkSandbox_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServiceServer).ForkSandbox(ctx, req.(*ForkSandboxRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _DaemonService_EnsureImage_Handler(srv interface{}, stream grpc.ServerStream) error {
 	m := new(EnsureImageRequest)
 	if err := stream.RecvMsg(m); err != nil {
@@ -822,6 +1167,71 @@ func _DaemonService_HTTPProxyCacheStatus_Handler(srv interface{}, ctx context.Co
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DaemonService_WatchEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DaemonServiceServer).WatchEvents(m, &grpc.GenericServerStream[WatchEventsRequest, SandboxEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type DaemonService_WatchEventsServer = grpc.ServerStreamingServer[SandboxEvent]
+
+func _DaemonService_StartPortForward_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PortForwardRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServiceServer).StartPortForward(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DaemonService_StartPortForward_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServiceServer).StartPortForward(ctx, req.(*PortForwardRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DaemonService_ListPortForwards_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServiceServer).ListPortForwards(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DaemonService_ListPortForwards_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServiceServer).ListPortForwards(ctx, req.(*IDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DaemonService_StopPortForwards_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PortForwardRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServiceServer).StopPortForwards(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DaemonService_StopPortForwards_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServiceServer).StopPortForwards(ctx, req.(*PortForwardRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // DaemonService_ServiceDesc is the grpc.ServiceDesc for DaemonService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -849,10 +1259,18 @@ var DaemonService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ListSandboxes",
 			Handler:    _DaemonService_ListSandboxes_Handler,
 		},
+		{
+			MethodName: "ListSandboxesDetailed",
+			Handler:    _DaemonService_ListSandboxesDetailed_Handler,
+		},
 		{
 			MethodName: "ListDeletedSandboxes",
 			Handler:    _DaemonService_ListDeletedSandboxes_Handler,
 		},
+		{
+			MethodName: "SyncSandboxes",
+			Handler:    _DaemonService_SyncSandboxes_Handler,
+		},
 		{
 			MethodName: "GetSandbox",
 			Handler:    _DaemonService_GetSandbox_Handler,
@@ -873,14 +1291,30 @@ var DaemonService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "StopSandbox",
 			Handler:    _DaemonService_StopSandbox_Handler,
 		},
+		{
+			MethodName: "KillSandbox",
+			Handler:    _DaemonService_KillSandbox_Handler,
+		},
 		{
 			MethodName: "StartSandbox",
 			Handler:    _DaemonService_StartSandbox_Handler,
 		},
+		{
+			MethodName: "MarkSandboxUsed",
+			Handler:    _DaemonService_MarkSandboxUsed_Handler,
+		},
 		{
 			MethodName: "SyncHostGitMirror",
 			Handler:    _DaemonService_SyncHostGitMirror_Handler,
 		},
+		{
+			MethodName: "FetchHostChanges",
+			Handler:    _DaemonService_FetchHostChanges_Handler,
+		},
+		{
+			MethodName: "ResyncWorkspace",
+			Handler:    _DaemonService_ResyncWorkspace_Handler,
+		},
 		{
 			MethodName: "ResolveAgentLaunchEnv",
 			Handler:    _DaemonService_ResolveAgentLaunchEnv_Handler,
@@ -889,6 +1323,10 @@ var DaemonService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ExportImage",
 			Handler:    _DaemonService_ExportImage_Handler,
 		},
+		{
+			MethodName: "PruneImages",
+			Handler:    _DaemonService_PruneImages_Handler,
+		},
 		{
 			MethodName: "Stats",
 			Handler:    _DaemonService_Stats_Handler,
@@ -901,6 +1339,10 @@ var DaemonService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "RenameSandbox",
 			Handler:    _DaemonService_RenameSandbox_Handler,
 		},
+		{
+			MethodName: "ForkSandbox",
+			Handler:    _DaemonService_ForkSandbox_Handler,
+		},
 		{
 			MethodName: "HTTPProxyCache",
 			Handler:    _DaemonService_HTTPProxyCache_Handler,
@@ -909,6 +1351,18 @@ var DaemonService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "HTTPProxyCacheStatus",
 			Handler:    _DaemonService_HTTPProxyCacheStatus_Handler,
 		},
+		{
+			MethodName: "StartPortForward",
+			Handler:    _DaemonService_StartPortForward_Handler,
+		},
+		{
+			MethodName: "ListPortForwards",
+			Handler:    _DaemonService_ListPortForwards_Handler,
+		},
+		{
+			MethodName: "StopPortForwards",
+			Handler:    _DaemonService_StopPortForwards_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -921,6 +1375,11 @@ var DaemonService_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _DaemonService_EnsureImage_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "WatchEvents",
+			Handler:       _DaemonService_WatchEvents_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "internal/daemon/daemonpb/daemon.proto",
 }
@@ -0,0 +1,113 @@
+package daemon
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/banksean/sand/internal/daemon/boxer"
+	"github.com/banksean/sand/internal/daemon/daemonpb"
+	"github.com/banksean/sand/internal/hostops"
+	"github.com/banksean/sand/internal/sandtypes"
+)
+
+// newFetchHostChangesDaemon returns a daemon with sandboxes alpha and beta,
+// and the mirrors it has cloned or updated. Any git fetch it runs on the host
+// fails the test: it would run with the sandbox clone's config.
+func newFetchHostChangesDaemon(t *testing.T) (*Daemon, *[]string) {
+	t.Helper()
+	appDir := t.TempDir()
+	var mirrors []string
+	gitOps := &hostops.MockGitOps{
+		TopLevelFunc: func(ctx context.Context, dir string) string { return dir },
+		FetchFunc: func(ctx context.Context, dir, remote string) error {
+			t.Errorf("git fetch %s ran on the host in %s", remote, dir)
+			return nil
+		},
+		CloneMirrorFunc: func(ctx context.Context, sourceDir, mirrorDir string) error {
+			mirrors = append(mirrors, mirrorDir)
+			return os.MkdirAll(mirrorDir, 0o755)
+		},
+		UpdateMirrorFunc: func(ctx context.Context, mirrorDir string) error {
+			mirrors = append(mirrors, mirrorDir)
+			return nil
+		},
+	}
+	b, err := boxer.NewBoxerWithDeps(appDir, boxer.BoxerDeps{
+		ContainerService: &hostops.MockContainerOps{},
+		ImageService:     &testImageOps{},
+		GitOps:           gitOps,
+		FileOps:          &hostops.MockFileOps{StatFunc: os.Stat},
+	})
+	if err != nil {
+		t.Fatalf("NewBoxerWithDeps: %v", err)
+	}
+	t.Cleanup(func() { b.Close() })
+
+	for _, name := range []string{"alpha", "beta"} {
+		if err := b.SaveSandbox(context.Background(), &sandtypes.Box{
+			ID:             name + "-id",
+			Name:           name,
+			ContainerID:    "ctr-" + name,
+			HostOriginDir:  t.TempDir(),
+			SandboxWorkDir: filepath.Join(appDir, "clones", name+"-id"),
+			ImageName:      "test-image:latest",
+		}); err != nil {
+			t.Fatalf("SaveSandbox(%s): %v", name, err)
+		}
+	}
+	return NewDaemonWithBoxer(appDir, "test", b), &mirrors
+}
+
+func TestFetchHostChangesOnlyUpdatesHostMirror(t *testing.T) {
+	d, mirrors := newFetchHostChangesDaemon(t)
+
+	mirrorPath, err := d.FetchHostChanges(context.Background(), FetchHostChangesOpts{Name: "beta"})
+	if err != nil {
+		t.Fatalf("FetchHostChanges: %v", err)
+	}
+	if !strings.HasPrefix(mirrorPath, filepath.Join(d.AppBaseDir, "git-mirrors")) {
+		t.Errorf("mirror path = %q, want under %s", mirrorPath, filepath.Join(d.AppBaseDir, "git-mirrors"))
+	}
+	if len(*mirrors) != 1 || (*mirrors)[0] != mirrorPath {
+		t.Fatalf("mirrors updated = %q, want [%q]", *mirrors, mirrorPath)
+	}
+}
+
+func TestFetchHostChangesFromSandboxSocket(t *testing.T) {
+	d, mirrors := newFetchHostChangesDaemon(t)
+	srv := &daemonGRPCServer{daemon: d, sandboxID: "alpha-id"}
+
+	if _, err := srv.FetchHostChanges(context.Background(), &daemonpb.FetchHostChangesRequest{Remote: "upstream"}); err != nil {
+		t.Fatalf("FetchHostChanges from own socket: %v", err)
+	}
+	if len(*mirrors) != 1 {
+		t.Fatalf("mirrors updated = %q, want alpha's", *mirrors)
+	}
+
+	if _, err := srv.FetchHostChanges(context.Background(), &daemonpb.FetchHostChangesRequest{Id: "beta"}); err == nil {
+		t.Fatal("FetchHostChanges for another sandbox succeeded, want error")
+	}
+	if len(*mirrors) != 1 {
+		t.Fatalf("mirrors updated after rejected request = %q, want only the first", *mirrors)
+	}
+}
+
+func TestFetchHostChangesRejectsBadInput(t *testing.T) {
+	d, mirrors := newFetchHostChangesDaemon(t)
+
+	for _, opts := range []FetchHostChangesOpts{
+		{},
+		{Name: "missing"},
+		{Name: "alpha", Remote: "--upload-pack=evil"},
+	} {
+		if _, err := d.FetchHostChanges(context.Background(), opts); err == nil {
+			t.Errorf("FetchHostChanges(%+v) succeeded, want error", opts)
+		}
+	}
+	if len(*mirrors) != 0 {
+		t.Fatalf("mirrors updated = %q, want none", *mirrors)
+	}
+}
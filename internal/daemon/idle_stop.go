@@ -0,0 +1,90 @@
+package daemon
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/banksean/sand/internal/sandboxlog"
+	"github.com/banksean/sand/internal/sandtypes"
+)
+
+// DefaultIdleCheckInterval is how often the daemon scans for idle sandboxes
+// when auto-stop is enabled and no interval is configured.
+const DefaultIdleCheckInterval = 5 * time.Minute
+
+// IdleStopOptions configures the daemon's background auto-stop of idle sandbox containers.
+type IdleStopOptions struct {
+	// Timeout is how long a running sandbox may go unused before its container is stopped.
+	// Zero disables auto-stop.
+	Timeout time.Duration
+	// Interval is how often to scan for idle sandboxes. Defaults to DefaultIdleCheckInterval.
+	Interval time.Duration
+}
+
+// runIdleStopper periodically stops idle sandbox containers until ctx is
+// cancelled or the daemon shuts down.
+func (d *Daemon) runIdleStopper(ctx context.Context) {
+	interval := d.IdleStop.Interval
+	if interval <= 0 {
+		interval = DefaultIdleCheckInterval
+	}
+	slog.InfoContext(ctx, "Daemon.runIdleStopper", "timeout", d.IdleStop.Timeout, "interval", interval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-d.shutdown:
+			return
+		case <-ticker.C:
+			d.stopIdleSandboxes(ctx)
+		}
+	}
+}
+
+// stopIdleSandboxes stops the container of every running sandbox that has not
+// been used within d.IdleStop.Timeout, and returns the names it stopped.
+func (d *Daemon) stopIdleSandboxes(ctx context.Context) []string {
+	if d.IdleStop.Timeout <= 0 {
+		return nil
+	}
+	sboxes, err := d.boxer.List(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "Daemon.stopIdleSandboxes List", "error", err)
+		return nil
+	}
+	now := d.now()
+	var stopped []string
+	for i := range sboxes {
+		sbox := &sboxes[i]
+		if sbox.Container == nil || sbox.Container.Status.State != "running" {
+			continue
+		}
+		lastActive := lastActivity(sbox)
+		if lastActive.IsZero() || now.Sub(lastActive) < d.IdleStop.Timeout {
+			continue
+		}
+		sctx := sandboxlog.WithSandboxID(ctx, sbox.ID)
+		if err := d.stopInnieServer(sctx, sbox.ID); err != nil {
+			slog.WarnContext(sctx, "Daemon.stopIdleSandboxes stopInnieServer", "name", sbox.Name, "error", err)
+		}
+		if err := d.boxer.StopContainer(sctx, sbox); err != nil {
+			slog.ErrorContext(sctx, "Daemon.stopIdleSandboxes StopContainer", "name", sbox.Name, "error", err)
+			continue
+		}
+		slog.InfoContext(sctx, "Daemon.stopIdleSandboxes stopped idle sandbox", "name", sbox.Name, "lastActive", lastActive, "idle", now.Sub(lastActive))
+		stopped = append(stopped, sbox.Name)
+	}
+	return stopped
+}
+
+// lastActivity is the last time sbox was used, falling back to its creation
+// time for sandboxes that have never been shelled into.
+func lastActivity(sbox *sandtypes.Box) time.Time {
+	if !sbox.LastUsedAt.IsZero() {
+		return sbox.LastUsedAt
+	}
+	return sbox.CreatedAt
+}
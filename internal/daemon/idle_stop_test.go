@@ -0,0 +1,114 @@
+package daemon
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/banksean/sand/internal/daemon/boxer"
+	"github.com/banksean/sand/internal/hostops"
+	"github.com/banksean/sand/internal/sandtypes"
+)
+
+func TestStopIdleSandboxesStopsOnlyIdleRunningContainers(t *testing.T) {
+	ctx := context.Background()
+	appDir := t.TempDir()
+
+	var mu sync.Mutex
+	clock := time.Now()
+	now := func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return clock
+	}
+	advance := func(d time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		clock = clock.Add(d)
+	}
+
+	var stopped []string
+	containerOps := &hostops.MockContainerOps{
+		InspectFunc: func(ctx context.Context, containerID string) ([]sandtypes.Container, error) {
+			state := "running"
+			if containerID == "ctr-stopped" {
+				state = "stopped"
+			}
+			return []sandtypes.Container{{Status: sandtypes.ContainerStatus{State: state}}}, nil
+		},
+		StopFunc: func(ctx context.Context, opts *hostops.StopContainer, containerID string) (string, error) {
+			stopped = append(stopped, containerID)
+			return "", nil
+		},
+	}
+	b, err := boxer.NewBoxerWithDeps(appDir, boxer.BoxerDeps{
+		ContainerService: containerOps,
+		ImageService:     &testImageOps{},
+		GitOps:           &hostops.MockGitOps{},
+		Now:              now,
+	})
+	if err != nil {
+		t.Fatalf("NewBoxerWithDeps: %v", err)
+	}
+	t.Cleanup(func() { b.Close() })
+	d := NewDaemonWithBoxer(appDir, "test", b)
+	d.now = now
+	d.IdleStop = IdleStopOptions{Timeout: 2 * time.Hour}
+
+	for _, name := range []string{"stale", "fresh", "stopped"} {
+		if err := b.SaveSandbox(ctx, &sandtypes.Box{
+			ID:             name + "-id",
+			Name:           name,
+			ContainerID:    "ctr-" + name,
+			SandboxWorkDir: t.TempDir(),
+			ImageName:      "test-image:latest",
+		}); err != nil {
+			t.Fatalf("SaveSandbox(%s): %v", name, err)
+		}
+	}
+	markUsed := func(name string) {
+		t.Helper()
+		sbox, err := b.Get(ctx, name)
+		if err != nil || sbox == nil {
+			t.Fatalf("Get(%s) = %v, %v", name, sbox, err)
+		}
+		if err := b.MarkUsed(ctx, sbox); err != nil {
+			t.Fatalf("MarkUsed(%s): %v", name, err)
+		}
+	}
+	markUsed("stale")
+
+	if got := d.stopIdleSandboxes(ctx); len(got) != 0 {
+		t.Fatalf("stopIdleSandboxes() before timeout = %v, want none", got)
+	}
+
+	advance(3 * time.Hour)
+	markUsed("fresh")
+
+	got := d.stopIdleSandboxes(ctx)
+	if !slices.Equal(got, []string{"stale"}) {
+		t.Fatalf("stopIdleSandboxes() = %v, want [stale]", got)
+	}
+	if !slices.Equal(stopped, []string{"ctr-stale"}) {
+		t.Fatalf("Stop calls = %v, want [ctr-stale]", stopped)
+	}
+}
+
+func TestStopIdleSandboxesDisabledByDefault(t *testing.T) {
+	d := newDaemonForTest(t, t.TempDir())
+	d.now = func() time.Time { return time.Now().Add(1000 * time.Hour) }
+	if err := d.boxer.SaveSandbox(context.Background(), &sandtypes.Box{
+		ID:             "box-id",
+		Name:           "box",
+		ContainerID:    "ctr-box",
+		SandboxWorkDir: t.TempDir(),
+		ImageName:      "test-image:latest",
+	}); err != nil {
+		t.Fatalf("SaveSandbox: %v", err)
+	}
+	if got := d.stopIdleSandboxes(context.Background()); len(got) != 0 {
+		t.Fatalf("stopIdleSandboxes() with no timeout = %v, want none", got)
+	}
+}
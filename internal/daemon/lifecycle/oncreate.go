@@ -0,0 +1,182 @@
+package lifecycle
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/banksean/sand/internal/cloning"
+	"github.com/banksean/sand/internal/hostops"
+	"github.com/banksean/sand/internal/sandtypes"
+)
+
+// onCreateOutputLines is how much of a failed oncreate script's output its
+// error carries. All of it is streamed as the script runs.
+const onCreateOutputLines = 20
+
+// onCreateScript is a user's provisioning script, run after sand's own hooks
+// the first time a sandbox's container starts.
+type onCreateScript struct {
+	// label names the script in progress and errors.
+	label string
+	// hostPath is where the script is read from.
+	hostPath string
+	// clone, if set, is the sandbox clone hostPath is in. The sandbox can
+	// write there, so the script is opened through an os.Root of clone: a
+	// symlink can't point sand at a file elsewhere on the host.
+	clone string
+	// containerPath is where it is copied to run.
+	containerPath string
+}
+
+// onCreateScripts returns the oncreate scripts for sb in the order they run:
+// the user's ~/.config/sand/oncreate.sh, for every sandbox, then the
+// project's .sand/oncreate.sh, read from sb's clone.
+func onCreateScripts(sb *sandtypes.Box) []onCreateScript {
+	clone := cloning.NewStandardPathRegistry(sb.SandboxWorkDir).WorkDir()
+	return []onCreateScript{
+		{
+			label:         "~/.config/sand/oncreate.sh",
+			hostPath:      filepath.Join(hostops.HomeDir(), ".config", "sand", "oncreate.sh"),
+			containerPath: "/tmp/sand-oncreate-user.sh",
+		},
+		{
+			label:         ".sand/oncreate.sh",
+			hostPath:      filepath.Join(clone, ".sand", "oncreate.sh"),
+			clone:         clone,
+			containerPath: "/tmp/sand-oncreate-project.sh",
+		},
+	}
+}
+
+// stat describes the script, following symlinks as long as they stay inside
+// the clone.
+func (s onCreateScript) stat() (fs.FileInfo, error) {
+	if s.clone == "" {
+		return os.Stat(s.hostPath)
+	}
+	root, rel, err := s.openClone()
+	if err != nil {
+		return nil, err
+	}
+	defer root.Close()
+	return root.Stat(rel)
+}
+
+// open opens the script for reading.
+func (s onCreateScript) open() (*os.File, error) {
+	if s.clone == "" {
+		return os.Open(s.hostPath)
+	}
+	root, rel, err := s.openClone()
+	if err != nil {
+		return nil, err
+	}
+	defer root.Close()
+	return root.Open(rel)
+}
+
+// openClone returns the script's clone as an os.Root and the script's path
+// within it.
+func (s onCreateScript) openClone() (*os.Root, string, error) {
+	rel, err := filepath.Rel(s.clone, s.hostPath)
+	if err != nil {
+		return nil, "", err
+	}
+	root, err := os.OpenRoot(s.clone)
+	if err != nil {
+		return nil, "", err
+	}
+	return root, rel, nil
+}
+
+// read returns the script's contents, failing unless it is a regular file.
+func (s onCreateScript) read() ([]byte, error) {
+	f, err := s.open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("%s is not a regular file", s.hostPath)
+	}
+	return io.ReadAll(f)
+}
+
+// onCreateHooks returns a hook for each of sb's oncreate scripts that exists.
+// A failing script fails the start unless sb.IgnoreOnCreateErrors is set, in
+// which case it is reported to progress instead. A script that can't be read,
+// such as one that links outside the clone, is skipped with a warning.
+func onCreateHooks(ctx context.Context, sb *sandtypes.Box, progress io.Writer) []sandtypes.ContainerHook {
+	var hooks []sandtypes.ContainerHook
+	for _, script := range onCreateScripts(sb) {
+		info, err := script.stat()
+		if err != nil {
+			if !os.IsNotExist(err) {
+				warnSkippedScript(ctx, script, err, progress)
+			}
+			continue
+		}
+		if info.IsDir() {
+			continue
+		}
+		if !info.Mode().IsRegular() {
+			warnSkippedScript(ctx, script, fmt.Errorf("%s is not a regular file", script.hostPath), progress)
+			continue
+		}
+		hooks = append(hooks, onCreateHook(script, sb.IgnoreOnCreateErrors, progress))
+	}
+	return hooks
+}
+
+func warnSkippedScript(ctx context.Context, script onCreateScript, err error, progress io.Writer) {
+	slog.WarnContext(ctx, "lifecycle.onCreateHooks", "path", script.hostPath, "error", err)
+	if progress != nil {
+		fmt.Fprintf(progress, "[sand] warning: not running %s: %v\n", script.label, err)
+	}
+}
+
+func onCreateHook(script onCreateScript, ignoreErrors bool, progress io.Writer) sandtypes.ContainerHook {
+	return sandtypes.NewPhasedContainerHook("run "+script.label, sandtypes.HookPhasePost, func(ctx context.Context, ctr *sandtypes.Container, exec sandtypes.HookStreamer) error {
+		err := runOnCreateScript(ctx, script, exec)
+		if err == nil || !ignoreErrors {
+			return err
+		}
+		slog.WarnContext(ctx, "lifecycle.onCreateHook ignoring failure", "script", script.label, "error", err)
+		if progress != nil {
+			fmt.Fprintf(progress, "[sand] warning: %v\n[sand] continuing because of --ignore-oncreate-errors\n", err)
+		}
+		return nil
+	})
+}
+
+// runOnCreateScript copies script into the container and runs it in /app. A
+// script with a #! line is run by its interpreter, any other with sh.
+func runOnCreateScript(ctx context.Context, script onCreateScript, exec sandtypes.HookStreamer) error {
+	body, err := script.read()
+	if err != nil {
+		return fmt.Errorf("read %s: %w", script.hostPath, err)
+	}
+	var copyErr bytes.Buffer
+	if err := exec.ExecStreamInput(ctx, bytes.NewReader(body), nil, &copyErr, "sh", "-c", `cat > "$1" && chmod 755 "$1"`, "sh", script.containerPath); err != nil {
+		return sandtypes.ErrorWithOutput(fmt.Errorf("copy %s into the container: %w", script.label, err), "output", copyErr.String(), onCreateOutputLines)
+	}
+	cmd, args := "sh", []string{script.containerPath}
+	if bytes.HasPrefix(body, []byte("#!")) {
+		cmd, args = script.containerPath, nil
+	}
+	var out bytes.Buffer
+	if err := exec.ExecStream(ctx, &out, &out, cmd, args...); err != nil {
+		return sandtypes.ErrorWithOutput(fmt.Errorf("%s failed: %w", script.label, err), "output", out.String(), onCreateOutputLines)
+	}
+	return nil
+}
@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/banksean/sand/internal/agents"
 	"github.com/banksean/sand/internal/cloning"
@@ -31,14 +32,31 @@ type Store interface {
 	GetContainer(ctx context.Context, containerID string) (*sandtypes.Container, error)
 	UpdateContainerID(ctx context.Context, sbox *sandtypes.Box, containerID string) error
 	UpdateContainerBootstrapped(ctx context.Context, sbox *sandtypes.Box, bootstrapped bool) error
+	UpdateStartHooksRan(ctx context.Context, sbox *sandtypes.Box, ran bool) error
+	RecordHostProcess(ctx context.Context, sbox *sandtypes.Box, name string, pid int) error
+	KillHostProcess(ctx context.Context, sbox *sandtypes.Box, name string) error
 }
 
+// DefaultReadyTimeout is how long a started container has to become ready
+// for hooks when Service.ReadyTimeout is unset.
+const DefaultReadyTimeout = 30 * time.Second
+
+// bootLogLines is how much of a container's boot log a failed start's error
+// carries.
+const bootLogLines = 30
+
+// readyPollInterval is how often waitForContainerReady checks on a container.
+var readyPollInterval = 250 * time.Millisecond
+
 type Service struct {
 	AppRoot          string
 	ContainerService hostops.ContainerOps
 	ImageService     hostops.ImageOps
 	AgentRegistry    *agents.AgentRegistry
 	Store            Store
+	// ReadyTimeout bounds how long a start waits for the container to be
+	// running and accept execs before running hooks. Zero means DefaultReadyTimeout.
+	ReadyTimeout time.Duration
 }
 
 type Deps struct {
@@ -47,6 +65,7 @@ type Deps struct {
 	ImageService     hostops.ImageOps
 	AgentRegistry    *agents.AgentRegistry
 	Store            Store
+	ReadyTimeout     time.Duration
 }
 
 func NewService(deps Deps) *Service {
@@ -56,6 +75,7 @@ func NewService(deps Deps) *Service {
 		ImageService:     deps.ImageService,
 		AgentRegistry:    deps.AgentRegistry,
 		Store:            deps.Store,
+		ReadyTimeout:     deps.ReadyTimeout,
 	}
 }
 
@@ -66,6 +86,18 @@ type hookExecutor struct {
 	container   hostops.ContainerOps
 	progress    io.Writer
 	env         []string
+	sbox        *sandtypes.Box
+	store       Store
+}
+
+// RecordHostProcess implements [sandtypes.HostProcessRecorder].
+func (h hookExecutor) RecordHostProcess(ctx context.Context, name string, pid int) error {
+	return h.store.RecordHostProcess(ctx, h.sbox, name, pid)
+}
+
+// KillHostProcess implements [sandtypes.HostProcessKiller].
+func (h hookExecutor) KillHostProcess(ctx context.Context, name string) error {
+	return h.store.KillHostProcess(ctx, h.sbox, name)
 }
 
 func (h hookExecutor) Exec(ctx context.Context, shellCmd string, args ...string) (string, error) {
@@ -127,7 +159,7 @@ func startHooks(hooks []sandtypes.ContainerHook) []sandtypes.ContainerHook {
 }
 
 func InnieSocketPermissionHook() sandtypes.ContainerHook {
-	return sandtypes.NewContainerHook("repair host service socket permissions", func(ctx context.Context, ctr *sandtypes.Container, exec sandtypes.HookStreamer) error {
+	return sandtypes.NewPhasedContainerHook("repair host service socket permissions", sandtypes.HookPhasePre, func(ctx context.Context, ctr *sandtypes.Container, exec sandtypes.HookStreamer) error {
 		var out bytes.Buffer
 		if err := hookscript.Execute(ctx, exec, "innie-socket-permissions.txt", innieSocketPermissionScript, &out); err != nil {
 			if out.String() != "" {
@@ -168,6 +200,8 @@ func runtimeArtifactsFromBox(sb *sandtypes.Box) containerruntime.Artifacts {
 		Username:          sb.Username,
 		Uid:               sb.Uid,
 		SharedCacheMounts: sb.SharedCacheMounts,
+		NoDotfiles:        sb.NoDotfiles,
+		NoSSH:             sb.NoSSH,
 	}
 }
 
@@ -187,6 +221,8 @@ func (s *Service) EffectiveMounts(sb *sandtypes.Box) []sandtypes.MountSpec {
 		DotfilesDir:       pathRegistry.DotfilesDir(),
 		SSHKeysDir:        pathRegistry.SSHKeysDir(),
 		SharedCacheMounts: sb.SharedCacheMounts,
+		NoDotfiles:        sb.NoDotfiles,
+		NoSSH:             sb.NoSSH,
 	})
 }
 
@@ -201,8 +237,8 @@ func (s *Service) CreateContainer(ctx context.Context, sb *sandtypes.Box, enable
 	mountOpts = append(effectiveRuntimeMounts(sb), mountOpts...)
 
 	volumeOpts := []string{}
-	volumeOpts = append(volumeOpts, runtimepaths.ContainerHTTPSocketPath(sb.ID)+":/run/host-services/sandd.sock")
-	volumeOpts = append(volumeOpts, runtimepaths.ContainerGRPCSocketPath(sb.ID)+":/run/host-services/sandd.grpc.sock")
+	volumeOpts = append(volumeOpts, runtimepaths.ContainerHTTPSocketPath(sb.ID)+":"+runtimepaths.HostServicesHTTPSocket)
+	volumeOpts = append(volumeOpts, runtimepaths.ContainerGRPCSocketPath(sb.ID)+":"+runtimepaths.HostServicesGRPCSocket)
 	if sb.SharedCacheMounts.HTTPProxyCAHostPath != "" {
 		volumeOpts = append(volumeOpts, sb.SharedCacheMounts.HTTPProxyCAHostPath+":"+sandtypes.HTTPProxyCACertContainerPath+":ro")
 	}
@@ -210,10 +246,17 @@ func (s *Service) CreateContainer(ctx context.Context, sb *sandtypes.Box, enable
 	mgmtOpts := hostops.ManagementOptions{
 		Name:      sandboxContainerName(sb),
 		SSH:       enableSSHAgent,
-		DNSDomain: sb.DNSDomain,
+		DNSDomain: runtimedeps.NormalizeDNSDomain(sb.DNSDomain),
+		Network:   sb.Network,
 		Remove:    false,
 		Mount:     mountOpts,
 		Volume:    volumeOpts,
+		Label:     containerLabels(sb),
+		Publish:   sb.Publish,
+		DNS:       sb.DNS.Nameservers,
+		DNSSearch: sb.DNS.SearchDomains,
+		DNSOption: sb.DNS.Options,
+		NoDNS:     sb.DNS.Disabled,
 	}
 	resOpts := hostops.ResourceOptions{
 		CPUs:   sb.CPUs,
@@ -221,29 +264,34 @@ func (s *Service) CreateContainer(ctx context.Context, sb *sandtypes.Box, enable
 	}
 	if len(sb.AllowedDomains) > 0 {
 		mgmtOpts.InitImage = runtimedeps.CustomInitImage
-		mgmtOpts.DNS = "127.0.0.1"
+		// The init image's filtering resolver takes the place of any
+		// configured nameservers.
+		mgmtOpts.DNS = []string{"127.0.0.1"}
 		mgmtOpts.Kernel = filepath.Join(s.AppRoot, "kernel", runtimedeps.CustomKernelReleaseVersion, "vmlinux")
 	}
 	if err := s.checkImageHasEntrypoint(ctx, sb.ImageName); err != nil {
 		mgmtOpts.Entrypoint = "/bin/sh"
 	}
 
-	if platform, err := s.selectImagePlatform(ctx, sb.ImageName); err != nil {
+	platform, err := s.selectImagePlatform(ctx, sb.ImageName, sb.AllowEmulation)
+	var archErr *ImageArchError
+	switch {
+	case errors.As(err, &archErr):
+		return err
+	case err != nil:
 		slog.WarnContext(ctx, "selectImagePlatform", "image", sb.ImageName, "error", err)
-	} else if platform != "" {
+	case platform != "":
 		mgmtOpts.Platform = platform
 	}
 
-	containerID, err := s.ContainerService.Create(ctx,
-		&hostops.CreateContainer{
-			ProcessOptions: hostops.ProcessOptions{
-				Interactive: true,
-				TTY:         true,
-			},
-			ManagementOptions: mgmtOpts,
-			ResourceOptions:   resOpts,
+	containerID, err := s.createNamedContainer(ctx, sb, &hostops.CreateContainer{
+		ProcessOptions: hostops.ProcessOptions{
+			Interactive: true,
+			TTY:         true,
 		},
-		sb.ImageName, nil)
+		ManagementOptions: mgmtOpts,
+		ResourceOptions:   resOpts,
+	})
 	if err != nil {
 		slog.ErrorContext(ctx, "createContainer", "imageName", sb.ImageName, "error", err, "output", containerID)
 		return fmt.Errorf("failed to create container for sandbox %s: %w", sb.ID, err)
@@ -253,6 +301,57 @@ func (s *Service) CreateContainer(ctx context.Context, sb *sandtypes.Box, enable
 	return nil
 }
 
+// sandboxIDLabel marks a container with the ID of the sandbox it was created for.
+const sandboxIDLabel = "sand.sandbox-id"
+
+// maxContainerNameAttempts is how many names createNamedContainer tries,
+// including the sandbox's own, before giving up on a name collision.
+const maxContainerNameAttempts = 5
+
+func containerLabels(sb *sandtypes.Box) map[string]string {
+	labels := make(map[string]string, len(sb.Labels)+1)
+	for k, v := range sb.Labels {
+		labels[k] = v
+	}
+	labels[sandboxIDLabel] = sb.ID
+	return labels
+}
+
+// createNamedContainer creates the container described by opts. If its name
+// is already taken, by a leftover container from a run that crashed before
+// recording it for example, the existing container is reused when it was
+// created for sb; otherwise a numbered suffix is added to the name. The
+// returned ID is the container's actual name.
+func (s *Service) createNamedContainer(ctx context.Context, sb *sandtypes.Box, opts *hostops.CreateContainer) (string, error) {
+	baseName := opts.ManagementOptions.Name
+	for attempt := 1; ; attempt++ {
+		containerID, err := s.ContainerService.Create(ctx, opts, sb.ImageName, nil)
+		if err == nil || !hostops.IsContainerNameConflict(err) {
+			return containerID, err
+		}
+		name := opts.ManagementOptions.Name
+		if s.containerCreatedFor(ctx, name, sb) {
+			slog.InfoContext(ctx, "createContainer reusing existing container", "name", name)
+			return name, nil
+		}
+		if attempt == maxContainerNameAttempts {
+			return "", err
+		}
+		opts.ManagementOptions.Name = fmt.Sprintf("%s-%d", baseName, attempt+1)
+		slog.WarnContext(ctx, "createContainer name in use, retrying", "name", name, "nextName", opts.ManagementOptions.Name, "error", err)
+	}
+}
+
+// containerCreatedFor reports whether the container called name carries sb's ID label.
+func (s *Service) containerCreatedFor(ctx context.Context, name string, sb *sandtypes.Box) bool {
+	containers, err := s.ContainerService.Inspect(ctx, name)
+	if err != nil || len(containers) == 0 {
+		return false
+	}
+	id, ok := containers[0].Configuration.Labels[sandboxIDLabel].(string)
+	return ok && id == sb.ID
+}
+
 func effectiveRuntimeMounts(sb *sandtypes.Box) []string {
 	return sandtypes.RuntimeMountRequests(sb.MountRequests)
 }
@@ -266,7 +365,7 @@ func (s *Service) RecreateContainer(ctx context.Context, sb *sandtypes.Box, enab
 		}
 
 		out, err = s.ContainerService.Delete(ctx, nil, sb.ContainerID)
-		if err != nil {
+		if err != nil && !hostops.IsContainerNotFound(err) {
 			return fmt.Errorf("delete old container for sandbox %s: %w", sb.ID, err)
 		}
 	}
@@ -280,7 +379,30 @@ func (s *Service) RecreateContainer(ctx context.Context, sb *sandtypes.Box, enab
 	return nil
 }
 
-func (s *Service) selectImagePlatform(ctx context.Context, imageName string) (string, error) {
+// hostArch is the architecture an image needs a variant for to run without
+// emulation.
+var hostArch = runtime.GOARCH
+
+// ImageArchError reports that an image has no variant for the host's
+// architecture, so its container would only boot under emulation.
+type ImageArchError struct {
+	Image    string
+	HostArch string
+	// Platforms are the image's os/arch variants.
+	Platforms []string
+}
+
+func (e *ImageArchError) Error() string {
+	return fmt.Sprintf("image %s has no %s variant (it has %s); use --allow-emulation to run it under emulation",
+		e.Image, e.HostArch, strings.Join(e.Platforms, ", "))
+}
+
+// selectImagePlatform returns the platform to create imageName's container
+// for: empty when the image has a linux variant for the host's architecture,
+// or another variant's platform when allowEmulation is set. Without
+// allowEmulation, an image with only other architectures' variants is an
+// *ImageArchError.
+func (s *Service) selectImagePlatform(ctx context.Context, imageName string, allowEmulation bool) (string, error) {
 	if imageName == "" {
 		return "", nil
 	}
@@ -288,20 +410,27 @@ func (s *Service) selectImagePlatform(ctx context.Context, imageName string) (st
 	if err != nil {
 		return "", err
 	}
-	if len(imgs) == 0 || len(imgs[0].Variants) == 0 {
+	if len(imgs) == 0 {
 		return "", nil
 	}
-	hostArch := runtime.GOARCH
+	var platforms []string
 	for _, v := range imgs[0].Variants {
-		if v.Platform.OS == "linux" && v.Platform.Architecture == hostArch {
+		if v.Platform.OS != "linux" || v.Platform.Architecture == "" {
+			continue
+		}
+		if v.Platform.Architecture == hostArch {
 			return "", nil
 		}
+		platforms = append(platforms, v.Platform.OS+"/"+v.Platform.Architecture)
 	}
-	v := imgs[0].Variants[0]
-	if v.Platform.OS == "" || v.Platform.Architecture == "" {
+	if len(platforms) == 0 {
 		return "", nil
 	}
-	return v.Platform.OS + "/" + v.Platform.Architecture, nil
+	if !allowEmulation {
+		return "", &ImageArchError{Image: imageName, HostArch: hostArch, Platforms: platforms}
+	}
+	slog.InfoContext(ctx, "selectImagePlatform emulating", "image", imageName, "hostArch", hostArch, "platform", platforms[0])
+	return platforms[0], nil
 }
 
 func (s *Service) checkImageHasEntrypoint(ctx context.Context, imageName string) error {
@@ -326,17 +455,23 @@ func (s *Service) StartNewContainer(ctx context.Context, sb *sandtypes.Box, prog
 	ctx = sandboxlog.WithSandboxID(ctx, sb.ID)
 	artifacts := runtimeArtifactsFromBox(sb)
 	agentConfig := s.AgentRegistry.Get(sb.AgentType)
-	hooks := startHooks(agentConfig.Configuration.GetFirstStartHooks(artifacts))
+	hooks := startHooks(append(agentConfig.Configuration.GetFirstStartHooks(artifacts), onCreateHooks(ctx, sb, progress)...))
 
 	slog.InfoContext(ctx, "lifecycle.StartNewContainer", "box", *sb, "ContainerHooks", len(hooks))
 	if err := s.startContainerProcess(ctx, sb.ID, sb.ContainerID); err != nil {
-		return err
+		return s.withBootLog(ctx, sb.ContainerID, err)
+	}
+	if err := s.waitForContainerReady(ctx, sb.ID, sb.ContainerID); err != nil {
+		return s.withBootLog(ctx, sb.ContainerID, err)
 	}
 
 	if err := s.ExecuteHooks(ctx, sb, hooks, progress); err != nil {
+		return s.withBootLog(ctx, sb.ContainerID, err)
+	}
+	if err := s.Store.UpdateContainerBootstrapped(ctx, sb, true); err != nil {
 		return err
 	}
-	return s.Store.UpdateContainerBootstrapped(ctx, sb, true)
+	return s.Store.UpdateStartHooksRan(ctx, sb, true)
 }
 
 func (s *Service) StartExistingContainer(ctx context.Context, sb *sandtypes.Box) error {
@@ -347,9 +482,45 @@ func (s *Service) StartExistingContainer(ctx context.Context, sb *sandtypes.Box)
 
 	slog.InfoContext(ctx, "lifecycle.StartExistingContainer", "box", *sb, "ContainerHooks", len(hooks))
 	if err := s.startContainerProcess(ctx, sb.ID, sb.ContainerID); err != nil {
+		return s.withBootLog(ctx, sb.ContainerID, err)
+	}
+	if err := s.waitForContainerReady(ctx, sb.ID, sb.ContainerID); err != nil {
+		return s.withBootLog(ctx, sb.ContainerID, err)
+	}
+
+	if err := s.ExecuteHooks(ctx, sb, hooks, nil); err != nil {
+		return s.withBootLog(ctx, sb.ContainerID, err)
+	}
+	return s.Store.UpdateStartHooksRan(ctx, sb, true)
+}
+
+// withBootLog adds the end of the container's boot log to err from a failed
+// start, since the hook or readiness error alone rarely says why the container
+// couldn't come up.
+func (s *Service) withBootLog(ctx context.Context, containerID string, err error) error {
+	bootLog, logErr := s.ContainerService.Logs(ctx, &hostops.LogsContainer{Boot: true, Lines: bootLogLines}, containerID)
+	if logErr != nil {
+		slog.WarnContext(ctx, "lifecycle.withBootLog", "containerID", containerID, "error", logErr)
 		return err
 	}
+	return sandtypes.ErrorWithOutput(err, "container boot log", bootLog, bootLogLines)
+}
 
+// ExecuteStopHooks runs the agent's stop hooks against sb's still-running
+// container. Like ExecuteHooks, every hook runs and their errors are joined.
+func (s *Service) ExecuteStopHooks(ctx context.Context, sb *sandtypes.Box) error {
+	ctx = sandboxlog.WithSandboxID(ctx, sb.ID)
+	agentConfig, ok := s.AgentRegistry.Lookup(sb.AgentType)
+	if !ok {
+		if agentConfig, ok = s.AgentRegistry.Lookup("default"); !ok {
+			return nil
+		}
+	}
+	hooks := agentConfig.Configuration.GetStopHooks(runtimeArtifactsFromBox(sb))
+	if len(hooks) == 0 {
+		return nil
+	}
+	slog.InfoContext(ctx, "lifecycle.ExecuteStopHooks", "containerID", sb.ContainerID, "ContainerHooks", len(hooks))
 	return s.ExecuteHooks(ctx, sb, hooks, nil)
 }
 
@@ -365,10 +536,60 @@ func (s *Service) startContainerProcess(ctx context.Context, sandboxID, containe
 	return nil
 }
 
+// waitForContainerReady polls a just-started container until it reports
+// running and can exec a trivial command, so the first hook doesn't race the
+// container's filesystem and network coming up.
+func (s *Service) waitForContainerReady(ctx context.Context, sandboxID, containerID string) error {
+	timeout := s.ReadyTimeout
+	if timeout <= 0 {
+		timeout = DefaultReadyTimeout
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(readyPollInterval)
+	defer ticker.Stop()
+	for {
+		state, err := s.containerReadiness(waitCtx, containerID)
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-waitCtx.Done():
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			slog.ErrorContext(ctx, "lifecycle.waitForContainerReady timed out", "containerID", containerID, "state", state, "error", err)
+			return fmt.Errorf("container for sandbox %s not ready after %s (state %q): %w", sandboxID, timeout, state, err)
+		case <-ticker.C:
+		}
+	}
+}
+
+// containerReadiness returns the container's state and a non-nil error if it
+// isn't ready for hooks yet.
+func (s *Service) containerReadiness(ctx context.Context, containerID string) (string, error) {
+	containers, err := s.ContainerService.Inspect(ctx, containerID)
+	if err != nil {
+		return "", err
+	}
+	if len(containers) == 0 {
+		return "", fmt.Errorf("container %s not found", containerID)
+	}
+	state := containers[0].Status.State
+	if state != "running" {
+		return state, fmt.Errorf("container is %s", state)
+	}
+	if out, err := s.ContainerService.Exec(ctx, nil, containerID, "true", nil); err != nil {
+		return state, fmt.Errorf("exec true: %w (output: %s)", err, out)
+	}
+	return state, nil
+}
+
 func (s *Service) ExecuteHooks(ctx context.Context, sb *sandtypes.Box, hooks []sandtypes.ContainerHook, progress io.Writer) error {
 	ctx = sandboxlog.WithSandboxID(ctx, sb.ID)
 	var hookErrs []error
-	for _, hook := range hooks {
+	for _, hook := range sandtypes.OrderHooks(hooks) {
 		slog.InfoContext(ctx, "lifecycle.ExecuteHooks running hook", "hook", hook.Name())
 		if progress != nil {
 			fmt.Fprintf(progress, "[sand] %s\n", hook.Name())
@@ -384,6 +605,8 @@ func (s *Service) ExecuteHooks(ctx context.Context, sb *sandtypes.Box, hooks []s
 			container:   s.ContainerService,
 			progress:    progress,
 			env:         hookExecutionEnv(sb.SharedCacheMounts),
+			sbox:        sb,
+			store:       s.Store,
 		}
 		if err := hook.Run(ctx, ctr, exec); err != nil {
 			slog.ErrorContext(ctx, "lifecycle.ExecuteHooks hook error", "hook", hook.Name(), "error", err)
@@ -0,0 +1,463 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/banksean/sand/internal/agents"
+	"github.com/banksean/sand/internal/containerruntime"
+	"github.com/banksean/sand/internal/hostops"
+	"github.com/banksean/sand/internal/runtimepaths"
+	"github.com/banksean/sand/internal/sandtypes"
+)
+
+func TestCreateContainerBindsPerSandboxHostSockets(t *testing.T) {
+	volumes := map[string][]string{}
+	svc := NewService(Deps{
+		ContainerService: &hostops.MockContainerOps{
+			CreateFunc: func(_ context.Context, opts *hostops.CreateContainer, _ string, _ []string) (string, error) {
+				volumes[opts.ManagementOptions.Name] = opts.ManagementOptions.Volume
+				return "ctr-" + opts.ManagementOptions.Name, nil
+			},
+		},
+	})
+
+	for _, id := range []string{"sandbox-a", "sandbox-b"} {
+		sb := &sandtypes.Box{ID: id, Name: id, SandboxWorkDir: t.TempDir()}
+		if err := svc.CreateContainer(context.Background(), sb, false); err != nil {
+			t.Fatalf("CreateContainer(%s): %v", id, err)
+		}
+	}
+
+	for _, id := range []string{"sandbox-a", "sandbox-b"} {
+		got := volumes[id]
+		for _, want := range []string{
+			runtimepaths.ContainerHTTPSocketPath(id) + ":" + runtimepaths.HostServicesHTTPSocket,
+			runtimepaths.ContainerGRPCSocketPath(id) + ":" + runtimepaths.HostServicesGRPCSocket,
+		} {
+			if !slices.Contains(got, want) {
+				t.Errorf("%s volumes = %v, want %q", id, got, want)
+			}
+		}
+	}
+	if runtimepaths.ContainerGRPCSocketPath("sandbox-a") == runtimepaths.ContainerGRPCSocketPath("sandbox-b") {
+		t.Fatal("sandboxes share a host gRPC socket path")
+	}
+}
+
+func TestCreateContainerAttachesRequestedNetwork(t *testing.T) {
+	var got []string
+	svc := NewService(Deps{
+		ContainerService: &hostops.MockContainerOps{
+			CreateFunc: func(_ context.Context, opts *hostops.CreateContainer, _ string, _ []string) (string, error) {
+				got = append(got, opts.ManagementOptions.Network)
+				return "ctr-" + opts.ManagementOptions.Name, nil
+			},
+		},
+	})
+
+	for _, network := range []string{"isolated", ""} {
+		sb := &sandtypes.Box{ID: "net-" + network, Name: "net-" + network, SandboxWorkDir: t.TempDir(), Network: network}
+		if err := svc.CreateContainer(context.Background(), sb, false); err != nil {
+			t.Fatalf("CreateContainer(%q): %v", network, err)
+		}
+	}
+	if want := []string{"isolated", ""}; !slices.Equal(got, want) {
+		t.Fatalf("create networks = %q, want %q", got, want)
+	}
+}
+
+func TestCreateContainerNameCollision(t *testing.T) {
+	inUse := fmt.Errorf(`container with id "dev": %w`, hostops.ErrContainerNameConflict)
+	for _, tc := range []struct {
+		name       string
+		ownerID    string
+		wantID     string
+		wantCreate []string
+	}{
+		{
+			name:       "leftover container for this sandbox is reused",
+			ownerID:    "sandbox-1",
+			wantID:     "dev",
+			wantCreate: []string{"dev"},
+		},
+		{
+			name:       "another container's name gets a suffix",
+			ownerID:    "sandbox-2",
+			wantID:     "dev-2",
+			wantCreate: []string{"dev", "dev-2"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var created []string
+			svc := NewService(Deps{
+				ContainerService: &hostops.MockContainerOps{
+					CreateFunc: func(_ context.Context, opts *hostops.CreateContainer, _ string, _ []string) (string, error) {
+						name := opts.ManagementOptions.Name
+						created = append(created, name)
+						if opts.ManagementOptions.Label[sandboxIDLabel] != "sandbox-1" {
+							t.Errorf("labels = %v, want %s=sandbox-1", opts.ManagementOptions.Label, sandboxIDLabel)
+						}
+						if name == "dev" {
+							return "", inUse
+						}
+						return name, nil
+					},
+					InspectFunc: func(_ context.Context, containerID string) ([]sandtypes.Container, error) {
+						if containerID != "dev" {
+							return nil, nil
+						}
+						ctr := sandtypes.Container{}
+						ctr.Configuration.ID = "dev"
+						ctr.Configuration.Labels = map[string]any{sandboxIDLabel: tc.ownerID}
+						return []sandtypes.Container{ctr}, nil
+					},
+				},
+			})
+
+			sb := &sandtypes.Box{ID: "sandbox-1", Name: "dev", SandboxWorkDir: t.TempDir()}
+			if err := svc.CreateContainer(context.Background(), sb, false); err != nil {
+				t.Fatalf("CreateContainer() error = %v", err)
+			}
+			if sb.ContainerID != tc.wantID {
+				t.Errorf("ContainerID = %q, want %q", sb.ContainerID, tc.wantID)
+			}
+			if !slices.Equal(created, tc.wantCreate) {
+				t.Errorf("created names = %v, want %v", created, tc.wantCreate)
+			}
+		})
+	}
+}
+
+func TestCreateContainerGivesUpAfterRepeatedCollisions(t *testing.T) {
+	attempts := 0
+	svc := NewService(Deps{
+		ContainerService: &hostops.MockContainerOps{
+			CreateFunc: func(context.Context, *hostops.CreateContainer, string, []string) (string, error) {
+				attempts++
+				return "", hostops.ErrContainerNameConflict
+			},
+			InspectFunc: func(context.Context, string) ([]sandtypes.Container, error) {
+				return nil, nil
+			},
+		},
+	})
+	sb := &sandtypes.Box{ID: "sandbox-1", Name: "dev", SandboxWorkDir: t.TempDir()}
+	if err := svc.CreateContainer(context.Background(), sb, false); err == nil {
+		t.Fatal("CreateContainer() error = nil, want name collision error")
+	}
+	if attempts != maxContainerNameAttempts || sb.ContainerID != "" {
+		t.Fatalf("attempts = %d, ContainerID = %q; want %d attempts and no container", attempts, sb.ContainerID, maxContainerNameAttempts)
+	}
+}
+
+type readinessTestStore struct {
+	Store
+}
+
+func (readinessTestStore) GetContainer(context.Context, string) (*sandtypes.Container, error) {
+	return &sandtypes.Container{}, nil
+}
+
+func (readinessTestStore) UpdateStartHooksRan(context.Context, *sandtypes.Box, bool) error {
+	return nil
+}
+
+type readinessTestConfig struct {
+	containerruntime.ContainerConfiguration
+	hook sandtypes.ContainerHook
+}
+
+func (c readinessTestConfig) GetStartHooks(containerruntime.Artifacts) []sandtypes.ContainerHook {
+	return []sandtypes.ContainerHook{c.hook}
+}
+
+// newReadinessTestService returns a Service whose container reports each of
+// states in turn on Inspect, then the last one forever, and the calls it saw.
+func newReadinessTestService(t *testing.T, states []string, timeout time.Duration) (*Service, *[]string) {
+	t.Helper()
+	oldInterval := readyPollInterval
+	readyPollInterval = time.Millisecond
+	t.Cleanup(func() { readyPollInterval = oldInterval })
+
+	var calls []string
+	inspects := 0
+	registry := agents.NewAgentRegistry()
+	registry.Register(&agents.AgentConfig{
+		Name: "default",
+		Configuration: readinessTestConfig{hook: sandtypes.NewContainerHook("agent hook", func(context.Context, *sandtypes.Container, sandtypes.HookStreamer) error {
+			calls = append(calls, "hook")
+			return nil
+		})},
+	})
+	svc := NewService(Deps{
+		ContainerService: &hostops.MockContainerOps{
+			InspectFunc: func(context.Context, string) ([]sandtypes.Container, error) {
+				state := states[min(inspects, len(states)-1)]
+				inspects++
+				calls = append(calls, "inspect:"+state)
+				return []sandtypes.Container{{Status: sandtypes.ContainerStatus{State: state}}}, nil
+			},
+			ExecFunc: func(_ context.Context, _ *hostops.ExecContainer, _, cmd string, _ []string, args ...string) (string, error) {
+				calls = append(calls, "exec:"+strings.Join(append([]string{cmd}, args...), " "))
+				return "", nil
+			},
+		},
+		AgentRegistry: registry,
+		Store:         readinessTestStore{},
+		ReadyTimeout:  timeout,
+	})
+	return svc, &calls
+}
+
+func TestStartExistingContainerWaitsUntilReadyBeforeHooks(t *testing.T) {
+	svc, calls := newReadinessTestService(t, []string{"starting", "starting", "running"}, time.Second)
+	sb := &sandtypes.Box{ID: "sandbox-1", AgentType: "default", ContainerID: "ctr-1"}
+	if err := svc.StartExistingContainer(context.Background(), sb); err != nil {
+		t.Fatalf("StartExistingContainer() error = %v", err)
+	}
+
+	want := []string{"inspect:starting", "inspect:starting", "inspect:running", "exec:true"}
+	if len(*calls) < len(want) || !slices.Equal((*calls)[:len(want)], want) {
+		t.Fatalf("calls = %v, want them to start with %v", *calls, want)
+	}
+	if (*calls)[len(*calls)-1] != "hook" {
+		t.Fatalf("calls = %v, want the agent hook to run last", *calls)
+	}
+}
+
+func TestStartExistingContainerTimesOutWhenNeverReady(t *testing.T) {
+	svc, calls := newReadinessTestService(t, []string{"starting"}, 20*time.Millisecond)
+	sb := &sandtypes.Box{ID: "sandbox-1", AgentType: "default", ContainerID: "ctr-1"}
+	err := svc.StartExistingContainer(context.Background(), sb)
+	if err == nil || !strings.Contains(err.Error(), "not ready after 20ms") || !strings.Contains(err.Error(), `state "starting"`) {
+		t.Fatalf("StartExistingContainer() error = %v, want readiness timeout", err)
+	}
+	if slices.Contains(*calls, "hook") {
+		t.Fatalf("calls = %v, want no hooks for a container that never became ready", *calls)
+	}
+}
+
+func TestStartExistingContainerHookFailureIncludesDiagnostics(t *testing.T) {
+	registry := agents.NewAgentRegistry()
+	registry.Register(&agents.AgentConfig{
+		Name:          "default",
+		Configuration: readinessTestConfig{hook: containerruntime.NewBaseContainerConfiguration().GetStartHooks(containerruntime.Artifacts{})[0]},
+	})
+	var logsOpts *hostops.LogsContainer
+	svc := NewService(Deps{
+		ContainerService: &hostops.MockContainerOps{
+			InspectFunc: func(context.Context, string) ([]sandtypes.Container, error) {
+				return []sandtypes.Container{{Status: sandtypes.ContainerStatus{State: "running"}}}, nil
+			},
+			ExecFunc: func(_ context.Context, _ *hostops.ExecContainer, _, cmd string, _ []string, args ...string) (string, error) {
+				if cmd == "/usr/sbin/sshd" {
+					return "/etc/ssh/sshd_config: line 3: Bad configuration option: Foo\n", errors.New("exit status 255")
+				}
+				return "", nil
+			},
+			LogsFunc: func(_ context.Context, opts *hostops.LogsContainer, containerID string) (string, error) {
+				logsOpts = opts
+				return "[    0.80] vminitd: started\n[    1.20] eth0: link up\n", nil
+			},
+		},
+		AgentRegistry: registry,
+		Store:         readinessTestStore{},
+		ReadyTimeout:  time.Second,
+	})
+
+	err := svc.StartExistingContainer(context.Background(), &sandtypes.Box{ID: "sandbox-1", AgentType: "default", ContainerID: "ctr-1"})
+	if err == nil {
+		t.Fatal("StartExistingContainer() error = nil, want the start sshd failure")
+	}
+	for _, want := range []string{
+		"start sshd: failed to execute command for sandbox sandbox-1: exit status 255",
+		"  output:\n    /etc/ssh/sshd_config: line 3: Bad configuration option: Foo",
+		"  container boot log:\n    [    0.80] vminitd: started\n    [    1.20] eth0: link up",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("StartExistingContainer() error =\n%v\nwant it to contain\n%s", err, want)
+		}
+	}
+	if logsOpts == nil || !logsOpts.Boot || logsOpts.Lines != bootLogLines {
+		t.Errorf("Logs opts = %+v, want the last %d lines of the boot log", logsOpts, bootLogLines)
+	}
+}
+
+// variantImageOps reports every image as having one linux variant per arch.
+type variantImageOps struct {
+	hostops.ImageOps
+	archs []string
+}
+
+func (m *variantImageOps) Inspect(_ context.Context, name string) ([]*sandtypes.ImageManifest, error) {
+	img := &sandtypes.ImageManifest{Name: name}
+	for _, arch := range m.archs {
+		img.Variants = append(img.Variants, sandtypes.ImageVariant{
+			Platform: sandtypes.Platform{OS: "linux", Architecture: arch},
+			Config:   sandtypes.ImageVariantConfig{Config: sandtypes.ImageVariantContainerConfig{Cmd: []string{"sh"}}},
+		})
+	}
+	return []*sandtypes.ImageManifest{img}, nil
+}
+
+func TestCreateContainerChecksImageArchitecture(t *testing.T) {
+	oldHostArch := hostArch
+	hostArch = "arm64"
+	defer func() { hostArch = oldHostArch }()
+
+	for _, tc := range []struct {
+		name           string
+		archs          []string
+		allowEmulation bool
+		wantPlatform   string
+		wantErr        string
+	}{
+		{name: "host variant", archs: []string{"amd64", "arm64"}},
+		{name: "no host variant", archs: []string{"amd64", "386"}, wantErr: "image img:1 has no arm64 variant (it has linux/amd64, linux/386)"},
+		{name: "emulation allowed", archs: []string{"amd64"}, allowEmulation: true, wantPlatform: "linux/amd64"},
+		{name: "no platform metadata", archs: []string{""}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var created []string
+			svc := NewService(Deps{
+				ContainerService: &hostops.MockContainerOps{
+					CreateFunc: func(_ context.Context, opts *hostops.CreateContainer, _ string, _ []string) (string, error) {
+						created = append(created, opts.ManagementOptions.Platform)
+						return "ctr-" + opts.ManagementOptions.Name, nil
+					},
+				},
+				ImageService: &variantImageOps{archs: tc.archs},
+			})
+			sb := &sandtypes.Box{ID: "arch", Name: "arch", SandboxWorkDir: t.TempDir(), ImageName: "img:1", AllowEmulation: tc.allowEmulation}
+
+			err := svc.CreateContainer(context.Background(), sb, false)
+			if tc.wantErr != "" {
+				var archErr *ImageArchError
+				if !errors.As(err, &archErr) || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("CreateContainer() error = %v, want an ImageArchError containing %q", err, tc.wantErr)
+				}
+				if len(created) != 0 {
+					t.Fatalf("created containers with platforms %q, want none", created)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateContainer() error = %v", err)
+			}
+			if want := []string{tc.wantPlatform}; !slices.Equal(created, want) {
+				t.Fatalf("created container platforms = %q, want %q", created, want)
+			}
+		})
+	}
+}
+
+func TestRecreateContainerKeepsPublishedPorts(t *testing.T) {
+	var published [][]string
+	svc := NewService(Deps{
+		ContainerService: &hostops.MockContainerOps{
+			CreateFunc: func(_ context.Context, opts *hostops.CreateContainer, _ string, _ []string) (string, error) {
+				published = append(published, opts.ManagementOptions.Publish)
+				return "ctr-" + opts.ManagementOptions.Name, nil
+			},
+		},
+		Store: containerIDTestStore{},
+	})
+	sb := &sandtypes.Box{ID: "pub", Name: "pub", ContainerID: "ctr-old", SandboxWorkDir: t.TempDir(), Publish: []string{"8080:80", "127.0.0.1:5353:53/udp"}}
+
+	if err := svc.RecreateContainer(context.Background(), sb, false); err != nil {
+		t.Fatalf("RecreateContainer() error = %v", err)
+	}
+	if len(published) != 1 || !slices.Equal(published[0], sb.Publish) {
+		t.Fatalf("recreated container published %q, want %q", published, sb.Publish)
+	}
+}
+
+func TestCreateContainerPassesDNSConfig(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		sb         sandtypes.Box
+		wantDNS    []string
+		wantSearch []string
+		wantOption []string
+		wantNoDNS  bool
+	}{
+		{
+			name:       "configured",
+			sb:         sandtypes.Box{DNS: sandtypes.DNSConfig{Nameservers: []string{"10.0.0.53", "10.0.1.53"}, SearchDomains: []string{"corp.example.com"}, Options: []string{"ndots:2"}}},
+			wantDNS:    []string{"10.0.0.53", "10.0.1.53"},
+			wantSearch: []string{"corp.example.com"},
+			wantOption: []string{"ndots:2"},
+		},
+		{
+			name:      "disabled",
+			sb:        sandtypes.Box{DNS: sandtypes.DNSConfig{Disabled: true}},
+			wantNoDNS: true,
+		},
+		{
+			name:       "allowed domains resolve through the filter",
+			sb:         sandtypes.Box{AllowedDomains: []string{"github.com"}, DNS: sandtypes.DNSConfig{Nameservers: []string{"10.0.0.53"}, SearchDomains: []string{"corp.example.com"}}},
+			wantDNS:    []string{"127.0.0.1"},
+			wantSearch: []string{"corp.example.com"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var got hostops.ManagementOptions
+			svc := NewService(Deps{ContainerService: &hostops.MockContainerOps{
+				CreateFunc: func(_ context.Context, opts *hostops.CreateContainer, _ string, _ []string) (string, error) {
+					got = opts.ManagementOptions
+					return "ctr", nil
+				},
+			}})
+			sb := tc.sb
+			sb.ID, sb.Name, sb.SandboxWorkDir = "dns", "dns", t.TempDir()
+			if err := svc.CreateContainer(context.Background(), &sb, false); err != nil {
+				t.Fatalf("CreateContainer() error = %v", err)
+			}
+			if !slices.Equal(got.DNS, tc.wantDNS) || !slices.Equal(got.DNSSearch, tc.wantSearch) || !slices.Equal(got.DNSOption, tc.wantOption) || got.NoDNS != tc.wantNoDNS {
+				t.Fatalf("create opts DNS = %q, search %q, options %q, no-dns %v; want %q, %q, %q, %v",
+					got.DNS, got.DNSSearch, got.DNSOption, got.NoDNS, tc.wantDNS, tc.wantSearch, tc.wantOption, tc.wantNoDNS)
+			}
+		})
+	}
+}
+
+type containerIDTestStore struct {
+	Store
+}
+
+func (containerIDTestStore) UpdateContainerID(context.Context, *sandtypes.Box, string) error {
+	return nil
+}
+
+func TestExecuteHooksRunsHooksInPhaseOrder(t *testing.T) {
+	var ran []string
+	hook := func(name string, phase sandtypes.HookPhase) sandtypes.ContainerHook {
+		return sandtypes.NewPhasedContainerHook(name, phase, func(context.Context, *sandtypes.Container, sandtypes.HookStreamer) error {
+			ran = append(ran, name)
+			return nil
+		})
+	}
+	// Appended the way a composed configuration might: the tunnel before the
+	// sshd it rides on.
+	hooks := []sandtypes.ContainerHook{
+		hook("ssh tunnel", sandtypes.HookPhasePost),
+		hook("install agent", sandtypes.HookPhaseMain),
+		hook("start sshd", sandtypes.HookPhasePre),
+		hook("configure agent", sandtypes.HookPhaseMain),
+		hook("check github ssh", sandtypes.HookPhasePost),
+	}
+	svc := NewService(Deps{ContainerService: &hostops.MockContainerOps{}, Store: readinessTestStore{}})
+	if err := svc.ExecuteHooks(context.Background(), &sandtypes.Box{ID: "sandbox-1", ContainerID: "ctr-1"}, hooks, nil); err != nil {
+		t.Fatalf("ExecuteHooks() error = %v", err)
+	}
+	want := []string{"start sshd", "install agent", "configure agent", "ssh tunnel", "check github ssh"}
+	if !slices.Equal(ran, want) {
+		t.Fatalf("hooks ran in order %v, want %v", ran, want)
+	}
+}
@@ -0,0 +1,46 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+)
+
+// metricsContentType is the Prometheus text exposition format version that
+// boxer.WriteMetrics produces.
+const metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// serveMetrics listens on MetricsAddr and serves /metrics until Shutdown.
+func (d *Daemon) serveMetrics(ctx context.Context) error {
+	ln, err := net.Listen("tcp", d.MetricsAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen for metrics on %s: %w", d.MetricsAddr, err)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /metrics", d.handleMetrics)
+	d.metricsSrv = &http.Server{Handler: mux}
+
+	slog.InfoContext(ctx, "Daemon.serveMetrics starting up", "addr", ln.Addr().String())
+	go func() {
+		if err := d.metricsSrv.Serve(ln); err != nil && err != http.ErrServerClosed {
+			slog.ErrorContext(ctx, "Daemon.serveMetrics", "error", err)
+		}
+	}()
+	return nil
+}
+
+func (d *Daemon) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	// Render into a buffer so a failure part way through becomes a 500
+	// rather than a truncated scrape.
+	var buf bytes.Buffer
+	if err := d.boxer.WriteMetrics(r.Context(), &buf); err != nil {
+		slog.ErrorContext(r.Context(), "Daemon.handleMetrics", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", metricsContentType)
+	w.Write(buf.Bytes())
+}
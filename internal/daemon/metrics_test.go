@@ -0,0 +1,38 @@
+package daemon
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/banksean/sand/internal/sandtypes"
+)
+
+func TestHandleMetricsServesPrometheusText(t *testing.T) {
+	dmn := newDaemonForTest(t, t.TempDir())
+	if err := dmn.boxer.SaveSandbox(context.Background(), &sandtypes.Box{ID: "m1", Name: "m1"}); err != nil {
+		t.Fatalf("SaveSandbox() error = %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	dmn.handleMetrics(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body:\n%s", rec.Code, rec.Body)
+	}
+	if got := rec.Header().Get("Content-Type"); got != metricsContentType {
+		t.Fatalf("Content-Type = %q, want %q", got, metricsContentType)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		"# TYPE sand_sandboxes gauge\n",
+		`sand_sandboxes{state="stopped"} 1` + "\n",
+		"# TYPE sand_image_pull_duration_seconds histogram\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body missing %q:\n%s", want, body)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/banksean/sand/internal/daemon/daemonpb"
+	"github.com/banksean/sand/internal/sandtypes"
+)
+
+func TestPortForwardProtoRoundTrip(t *testing.T) {
+	want := []sandtypes.PortForward{
+		{HostPort: 8080, ContainerPort: 3000, PID: 42},
+		{Remote: true, HostPort: 9222, ContainerPort: 9222, PID: 43},
+	}
+	got := portForwardsFromProto(portForwardsToProto(want))
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("round trip = %+v, want %+v", got, want)
+	}
+}
+
+func TestPortForwardsRejectedFromSandboxSocket(t *testing.T) {
+	srv := &daemonGRPCServer{sandboxID: "beta"}
+	ctx := context.Background()
+	req := &daemonpb.PortForwardRequest{Id: "beta", Forward: portForwardToProto(sandtypes.PortForward{HostPort: 22, ContainerPort: 22})}
+
+	if _, err := srv.StartPortForward(ctx, req); !errors.Is(err, errPortForwardFromSandbox) {
+		t.Errorf("StartPortForward() error = %v, want %v", err, errPortForwardFromSandbox)
+	}
+	if _, err := srv.ListPortForwards(ctx, &daemonpb.IDRequest{Id: "beta"}); !errors.Is(err, errPortForwardFromSandbox) {
+		t.Errorf("ListPortForwards() error = %v, want %v", err, errPortForwardFromSandbox)
+	}
+	if _, err := srv.StopPortForwards(ctx, req); !errors.Is(err, errPortForwardFromSandbox) {
+		t.Errorf("StopPortForwards() error = %v, want %v", err, errPortForwardFromSandbox)
+	}
+}
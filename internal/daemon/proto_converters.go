@@ -1,6 +1,7 @@
 package daemon
 
 import (
+	"maps"
 	"time"
 
 	"github.com/banksean/sand/internal/daemon/daemonpb"
@@ -31,32 +32,45 @@ func sandboxToProto(box *sandtypes.Box) *daemonpb.Sandbox {
 		return nil
 	}
 	return &daemonpb.Sandbox{
-		Id:                    box.ID,
-		Name:                  box.Name,
-		State:                 box.State,
-		AgentType:             box.AgentType,
-		ProfileName:           box.ProfileName,
-		ContainerId:           box.ContainerID,
-		HostOriginDir:         box.HostOriginDir,
-		SandboxWorkDir:        box.SandboxWorkDir,
-		TrashWorkDir:          box.TrashWorkDir,
-		DeletedAt:             timeToProto(box.DeletedAt),
-		ImageName:             box.ImageName,
-		DnsDomain:             box.DNSDomain,
-		EnvFile:               box.EnvFile,
-		AllowedDomains:        append([]string(nil), box.AllowedDomains...),
-		Mounts:                mountSpecsToProto(box.Mounts),
-		MountRequests:         mountRequestsToProto(box.MountRequests),
-		SharedCacheMounts:     sharedCacheMountsToProto(box.SharedCacheMounts),
-		Cpus:                  int32(box.CPUs),
-		MemoryMb:              int32(box.MemoryMB),
-		SandboxWorkDirError:   box.SandboxWorkDirError,
-		SandboxContainerError: box.SandboxContainerError,
-		Username:              box.Username,
-		Uid:                   box.Uid,
-		OriginalGitDetails:    gitDetailsToProto(box.OriginalGitDetails),
-		CurrentGitDetails:     gitDetailsToProto(box.CurrentGitDetails),
-		Container:             containerToProto(box.Container),
+		Id:                     box.ID,
+		Name:                   box.Name,
+		State:                  box.State,
+		AgentType:              box.AgentType,
+		ProfileName:            box.ProfileName,
+		ContainerId:            box.ContainerID,
+		HostOriginDir:          box.HostOriginDir,
+		SandboxWorkDir:         box.SandboxWorkDir,
+		TrashWorkDir:           box.TrashWorkDir,
+		DeletedAt:              timeToProto(box.DeletedAt),
+		CreatedAt:              timeToProto(box.CreatedAt),
+		LastUsedAt:             timeToProto(box.LastUsedAt),
+		ImageName:              box.ImageName,
+		DnsDomain:              box.DNSDomain,
+		EnvFile:                box.EnvFile,
+		AllowedDomains:         append([]string(nil), box.AllowedDomains...),
+		Labels:                 maps.Clone(box.Labels),
+		Shell:                  box.Shell,
+		Network:                box.Network,
+		NoDotfiles:             box.NoDotfiles,
+		NoSsh:                  box.NoSSH,
+		AllowEmulation:         box.AllowEmulation,
+		IgnoreOncreateErrors:   box.IgnoreOnCreateErrors,
+		Publish:                append([]string(nil), box.Publish...),
+		Dns:                    dnsConfigToProto(box.DNS),
+		ImageDigest:            box.ImageDigest,
+		Mounts:                 mountSpecsToProto(box.Mounts),
+		MountRequests:          mountRequestsToProto(box.MountRequests),
+		SharedCacheMounts:      sharedCacheMountsToProto(box.SharedCacheMounts),
+		Cpus:                   int32(box.CPUs),
+		MemoryMb:               int32(box.MemoryMB),
+		SandboxWorkDirError:    box.SandboxWorkDirError,
+		SandboxContainerError:  box.SandboxContainerError,
+		SandboxResourceWarning: box.SandboxResourceWarning,
+		Username:               box.Username,
+		Uid:                    box.Uid,
+		OriginalGitDetails:     gitDetailsToProto(box.OriginalGitDetails),
+		CurrentGitDetails:      gitDetailsToProto(box.CurrentGitDetails),
+		Container:              containerToProto(box.Container),
 	}
 }
 
@@ -65,33 +79,100 @@ func sandboxFromProto(box *daemonpb.Sandbox) *sandtypes.Box {
 		return nil
 	}
 	return &sandtypes.Box{
-		ID:                    box.GetId(),
-		Name:                  box.GetName(),
-		State:                 box.GetState(),
-		AgentType:             box.GetAgentType(),
-		ProfileName:           box.GetProfileName(),
-		ContainerID:           box.GetContainerId(),
-		HostOriginDir:         box.GetHostOriginDir(),
-		SandboxWorkDir:        box.GetSandboxWorkDir(),
-		TrashWorkDir:          box.GetTrashWorkDir(),
-		DeletedAt:             timeFromProto(box.GetDeletedAt()),
-		ImageName:             box.GetImageName(),
-		DNSDomain:             box.GetDnsDomain(),
-		EnvFile:               box.GetEnvFile(),
-		AllowedDomains:        append([]string(nil), box.GetAllowedDomains()...),
-		Mounts:                mountSpecsFromProto(box.GetMounts()),
-		MountRequests:         mountRequestsFromProto(box.GetMountRequests()),
-		SharedCacheMounts:     sharedCacheMountsFromProto(box.GetSharedCacheMounts()),
-		CPUs:                  int(box.GetCpus()),
-		MemoryMB:              int(box.GetMemoryMb()),
-		SandboxWorkDirError:   box.GetSandboxWorkDirError(),
-		SandboxContainerError: box.GetSandboxContainerError(),
-		Username:              box.GetUsername(),
-		Uid:                   box.GetUid(),
-		OriginalGitDetails:    gitDetailsFromProto(box.GetOriginalGitDetails()),
-		CurrentGitDetails:     gitDetailsFromProto(box.GetCurrentGitDetails()),
-		Container:             containerFromProto(box.GetContainer()),
-	}
+		ID:                     box.GetId(),
+		Name:                   box.GetName(),
+		State:                  box.GetState(),
+		AgentType:              box.GetAgentType(),
+		ProfileName:            box.GetProfileName(),
+		ContainerID:            box.GetContainerId(),
+		HostOriginDir:          box.GetHostOriginDir(),
+		SandboxWorkDir:         box.GetSandboxWorkDir(),
+		TrashWorkDir:           box.GetTrashWorkDir(),
+		DeletedAt:              timeFromProto(box.GetDeletedAt()),
+		CreatedAt:              timeFromProto(box.GetCreatedAt()),
+		LastUsedAt:             timeFromProto(box.GetLastUsedAt()),
+		ImageName:              box.GetImageName(),
+		DNSDomain:              box.GetDnsDomain(),
+		EnvFile:                box.GetEnvFile(),
+		AllowedDomains:         append([]string(nil), box.GetAllowedDomains()...),
+		Labels:                 maps.Clone(box.GetLabels()),
+		Shell:                  box.GetShell(),
+		Network:                box.GetNetwork(),
+		NoDotfiles:             box.GetNoDotfiles(),
+		NoSSH:                  box.GetNoSsh(),
+		AllowEmulation:         box.GetAllowEmulation(),
+		IgnoreOnCreateErrors:   box.GetIgnoreOncreateErrors(),
+		Publish:                append([]string(nil), box.GetPublish()...),
+		DNS:                    dnsConfigFromProto(box.GetDns()),
+		ImageDigest:            box.GetImageDigest(),
+		Mounts:                 mountSpecsFromProto(box.GetMounts()),
+		MountRequests:          mountRequestsFromProto(box.GetMountRequests()),
+		SharedCacheMounts:      sharedCacheMountsFromProto(box.GetSharedCacheMounts()),
+		CPUs:                   int(box.GetCpus()),
+		MemoryMB:               int(box.GetMemoryMb()),
+		SandboxWorkDirError:    box.GetSandboxWorkDirError(),
+		SandboxContainerError:  box.GetSandboxContainerError(),
+		SandboxResourceWarning: box.GetSandboxResourceWarning(),
+		Username:               box.GetUsername(),
+		Uid:                    box.GetUid(),
+		OriginalGitDetails:     gitDetailsFromProto(box.GetOriginalGitDetails()),
+		CurrentGitDetails:      gitDetailsFromProto(box.GetCurrentGitDetails()),
+		Container:              containerFromProto(box.GetContainer()),
+	}
+}
+
+func sandboxEventToProto(event sandtypes.SandboxEvent) *daemonpb.SandboxEvent {
+	return &daemonpb.SandboxEvent{
+		Type:        string(event.Type),
+		SandboxId:   event.SandboxID,
+		SandboxName: event.SandboxName,
+		Time:        timeToProto(event.Time),
+		Error:       event.Error,
+	}
+}
+
+func sandboxEventFromProto(event *daemonpb.SandboxEvent) sandtypes.SandboxEvent {
+	return sandtypes.SandboxEvent{
+		Type:        sandtypes.SandboxEventType(event.GetType()),
+		SandboxID:   event.GetSandboxId(),
+		SandboxName: event.GetSandboxName(),
+		Time:        timeFromProto(event.GetTime()),
+		Error:       event.GetError(),
+	}
+}
+
+func portForwardToProto(fwd sandtypes.PortForward) *daemonpb.PortForward {
+	return &daemonpb.PortForward{
+		Remote:        fwd.Remote,
+		HostPort:      int32(fwd.HostPort),
+		ContainerPort: int32(fwd.ContainerPort),
+		Pid:           int32(fwd.PID),
+	}
+}
+
+func portForwardFromProto(fwd *daemonpb.PortForward) sandtypes.PortForward {
+	return sandtypes.PortForward{
+		Remote:        fwd.GetRemote(),
+		HostPort:      int(fwd.GetHostPort()),
+		ContainerPort: int(fwd.GetContainerPort()),
+		PID:           int(fwd.GetPid()),
+	}
+}
+
+func portForwardsToProto(forwards []sandtypes.PortForward) *daemonpb.PortForwardsResponse {
+	resp := &daemonpb.PortForwardsResponse{Forwards: make([]*daemonpb.PortForward, 0, len(forwards))}
+	for _, fwd := range forwards {
+		resp.Forwards = append(resp.Forwards, portForwardToProto(fwd))
+	}
+	return resp
+}
+
+func portForwardsFromProto(resp *daemonpb.PortForwardsResponse) []sandtypes.PortForward {
+	forwards := make([]sandtypes.PortForward, 0, len(resp.GetForwards()))
+	for _, fwd := range resp.GetForwards() {
+		forwards = append(forwards, portForwardFromProto(fwd))
+	}
+	return forwards
 }
 
 func timeToProto(t time.Time) *timestamppb.Timestamp {
@@ -436,6 +517,30 @@ func dnsFromProto(dns *daemonpb.DNS) sandtypes.DNS {
 	}
 }
 
+func dnsConfigToProto(config sandtypes.DNSConfig) *daemonpb.DNSConfig {
+	if config.IsZero() {
+		return nil
+	}
+	return &daemonpb.DNSConfig{
+		Nameservers:   append([]string(nil), config.Nameservers...),
+		SearchDomains: append([]string(nil), config.SearchDomains...),
+		Options:       append([]string(nil), config.Options...),
+		Disabled:      config.Disabled,
+	}
+}
+
+func dnsConfigFromProto(config *daemonpb.DNSConfig) sandtypes.DNSConfig {
+	if config == nil {
+		return sandtypes.DNSConfig{}
+	}
+	return sandtypes.DNSConfig{
+		Nameservers:   append([]string(nil), config.GetNameservers()...),
+		SearchDomains: append([]string(nil), config.GetSearchDomains()...),
+		Options:       append([]string(nil), config.GetOptions()...),
+		Disabled:      config.GetDisabled(),
+	}
+}
+
 func containerNetworksToProto(networks []sandtypes.ContainerNetwork) []*daemonpb.ContainerNetwork {
 	out := make([]*daemonpb.ContainerNetwork, 0, len(networks))
 	for _, network := range networks {
@@ -513,6 +618,7 @@ func containerStatsToProto(stats []sandtypes.ContainerStats) []*daemonpb.Contain
 			NetworkRxBytes:   int64(stat.NetworkRxBytes),
 			NetworkTxBytes:   int64(stat.NetworkTxBytes),
 			NumProcesses:     int64(stat.NumProcesses),
+			UptimeSeconds:    int64(stat.UptimeSeconds),
 		})
 	}
 	return out
@@ -534,6 +640,7 @@ func containerStatsFromProto(stats []*daemonpb.ContainerStats) []sandtypes.Conta
 			NetworkRxBytes:   int(stat.GetNetworkRxBytes()),
 			NetworkTxBytes:   int(stat.GetNetworkTxBytes()),
 			NumProcesses:     int(stat.GetNumProcesses()),
+			UptimeSeconds:    int(stat.GetUptimeSeconds()),
 		})
 	}
 	return out
@@ -0,0 +1,16 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/banksean/sand/internal/daemon/daemonpb"
+)
+
+func TestPruneImagesRejectedFromSandboxSocket(t *testing.T) {
+	srv := &daemonGRPCServer{sandboxID: "beta"}
+	if _, err := srv.PruneImages(context.Background(), &daemonpb.PruneImagesRequest{}); !errors.Is(err, errPruneFromSandbox) {
+		t.Fatalf("PruneImages() error = %v, want %v", err, errPruneFromSandbox)
+	}
+}
@@ -568,11 +568,10 @@ func (p *requirementTestPreparation) Prepare(_ context.Context, req cloning.Clon
 
 type requirementTestSSHimmer struct{}
 
-func (s *requirementTestSSHimmer) NewKeys(_ context.Context, _, _ string) (*sshimmer.Keys, error) {
-	return &sshimmer.Keys{
-		HostKey:     []byte("fake-host-key"),
-		HostKeyPub:  []byte("fake-host-key-pub"),
-		HostKeyCert: []byte("fake-host-key-cert"),
-		UserCAPub:   []byte("fake-user-ca-pub"),
-	}, nil
+func (s *requirementTestSSHimmer) RemoveHost(_ context.Context, _ string) error {
+	return nil
+}
+
+func (s *requirementTestSSHimmer) NewKeys(_ context.Context, hostName, _ string) (*sshimmer.Keys, error) {
+	return sshimmer.NewTestKeys(hostName)
 }
@@ -0,0 +1,16 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/banksean/sand/internal/daemon/daemonpb"
+)
+
+func TestResyncWorkspaceRejectedFromSandboxSocket(t *testing.T) {
+	srv := &daemonGRPCServer{sandboxID: "beta"}
+	if _, err := srv.ResyncWorkspace(context.Background(), &daemonpb.IDRequest{Id: "beta"}); !errors.Is(err, errResyncFromSandbox) {
+		t.Fatalf("ResyncWorkspace() error = %v, want %v", err, errResyncFromSandbox)
+	}
+}
@@ -0,0 +1,61 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// SQLite primary result codes for a database held by another connection. The
+// low byte of an extended result code is its primary code.
+const (
+	sqliteBusy   = 5
+	sqliteLocked = 6
+)
+
+// IsBusy reports whether err is SQLite refusing a statement because another
+// connection holds a lock it needs.
+func IsBusy(err error) bool {
+	var coded interface{ Code() int }
+	if !errors.As(err, &coded) {
+		return false
+	}
+	switch coded.Code() & 0xff {
+	case sqliteBusy, sqliteLocked:
+		return true
+	}
+	return false
+}
+
+// busyRetryDB retries writes that fail with SQLITE_BUSY. busy_timeout covers
+// most contention, but SQLite can still return BUSY at once, without waiting,
+// when waiting could deadlock, and writes from another process can outlast
+// the timeout.
+type busyRetryDB struct {
+	DBTX
+	attempts int
+	backoff  time.Duration
+}
+
+// NewWithBusyRetry is New with writes retried, with backoff, while the
+// database is busy.
+func NewWithBusyRetry(db DBTX) *Queries {
+	return New(&busyRetryDB{DBTX: db, attempts: 5, backoff: 50 * time.Millisecond})
+}
+
+func (b *busyRetryDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	delay := b.backoff
+	for attempt := 1; ; attempt++ {
+		res, err := b.DBTX.ExecContext(ctx, query, args...)
+		if err == nil || !IsBusy(err) || attempt == b.attempts {
+			return res, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, errors.Join(err, ctx.Err())
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
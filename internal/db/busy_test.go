@@ -0,0 +1,90 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// codedError stands in for the driver's error type, which reports SQLite's
+// result code through Code.
+type codedError int
+
+func (e codedError) Error() string { return fmt.Sprintf("sqlite error %d", int(e)) }
+func (e codedError) Code() int     { return int(e) }
+
+// failingExecDB fails its first len(errs) ExecContext calls with errs, in order.
+type failingExecDB struct {
+	DBTX
+	errs  []error
+	calls int
+}
+
+func (f *failingExecDB) ExecContext(context.Context, string, ...interface{}) (sql.Result, error) {
+	f.calls++
+	if f.calls <= len(f.errs) {
+		return nil, f.errs[f.calls-1]
+	}
+	return nil, nil
+}
+
+func TestIsBusy(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want bool
+	}{
+		{codedError(sqliteBusy), true},
+		{codedError(sqliteLocked), true},
+		// SQLITE_BUSY_SNAPSHOT is an extended code of SQLITE_BUSY.
+		{fmt.Errorf("upsert: %w", codedError(517)), true},
+		{codedError(19), false}, // SQLITE_CONSTRAINT
+		{errors.New("database is locked"), false},
+		{nil, false},
+	} {
+		if got := IsBusy(tc.err); got != tc.want {
+			t.Errorf("IsBusy(%v) = %v, want %v", tc.err, got, tc.want)
+		}
+	}
+}
+
+func TestBusyRetryDBRetriesOnlyBusyWrites(t *testing.T) {
+	busy := codedError(sqliteBusy)
+	for _, tc := range []struct {
+		name      string
+		errs      []error
+		wantCalls int
+		wantErr   error
+	}{
+		{name: "succeeds after busy", errs: []error{busy, busy}, wantCalls: 3},
+		{name: "gives up", errs: []error{busy, busy, busy, busy}, wantCalls: 3, wantErr: busy},
+		{name: "other errors", errs: []error{codedError(19)}, wantCalls: 1, wantErr: codedError(19)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			inner := &failingExecDB{errs: tc.errs}
+			b := &busyRetryDB{DBTX: inner, attempts: 3, backoff: time.Millisecond}
+			_, err := b.ExecContext(context.Background(), "UPDATE sandboxes SET name = ?", "x")
+			if !errors.Is(err, tc.wantErr) || (tc.wantErr == nil && err != nil) {
+				t.Errorf("ExecContext() error = %v, want %v", err, tc.wantErr)
+			}
+			if inner.calls != tc.wantCalls {
+				t.Errorf("ExecContext made %d calls, want %d", inner.calls, tc.wantCalls)
+			}
+		})
+	}
+}
+
+func TestBusyRetryDBStopsWhenContextIsDone(t *testing.T) {
+	inner := &failingExecDB{errs: []error{codedError(sqliteBusy), codedError(sqliteBusy)}}
+	b := &busyRetryDB{DBTX: inner, attempts: 5, backoff: time.Hour}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := b.ExecContext(ctx, "DELETE FROM sandboxes"); !errors.Is(err, context.Canceled) || !IsBusy(err) {
+		t.Fatalf("ExecContext() error = %v, want the busy error and context.Canceled", err)
+	}
+	if inner.calls != 1 {
+		t.Fatalf("ExecContext made %d calls, want 1", inner.calls)
+	}
+}
@@ -21,7 +21,11 @@ var migrationsFS embed.FS
 func Connect(appRoot string) (*sql.DB, error) {
 	// TODO: move this db connection and migration code to a dedicated function.
 	dbPath := filepath.Join(appRoot, "sand.db")
-	sqlDB, err := sql.Open("sqlite", dbPath)
+	// Pragmas in the DSN are applied to every connection the pool opens, not
+	// just the first: busy_timeout retries for up to 5 seconds when a
+	// concurrent writer holds the lock, and WAL mode lets readers proceed
+	// alongside a writer.
+	sqlDB, err := sql.Open("sqlite", dbPath+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database at %s: %w", dbPath, err)
 	}
@@ -32,16 +36,6 @@ func Connect(appRoot string) (*sql.DB, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database driver: %w", err)
 	}
-	// Enable WAL mode for better concurrency
-	if _, err := sqlDB.Exec("PRAGMA journal_mode=WAL"); err != nil {
-		sqlDB.Close()
-		return nil, fmt.Errorf("failed to enable WAL mode: %w", err)
-	}
-	// Retry for up to 5 seconds when the database is locked by a concurrent writer.
-	if _, err := sqlDB.Exec("PRAGMA busy_timeout=5000"); err != nil {
-		sqlDB.Close()
-		return nil, fmt.Errorf("failed to set busy_timeout: %w", err)
-	}
 
 	// Initialize or migrate db schema
 	sourceDriver, err := iofs.New(migrationsFS, "migrations")
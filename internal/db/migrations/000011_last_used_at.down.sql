@@ -0,0 +1 @@
+ALTER TABLE sandboxes DROP COLUMN last_used_at;
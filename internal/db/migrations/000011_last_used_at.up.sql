@@ -0,0 +1 @@
+ALTER TABLE sandboxes ADD COLUMN last_used_at DATETIME;
@@ -0,0 +1 @@
+ALTER TABLE sandboxes DROP COLUMN start_hooks_ran;
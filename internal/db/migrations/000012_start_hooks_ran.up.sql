@@ -0,0 +1 @@
+ALTER TABLE sandboxes ADD COLUMN start_hooks_ran BOOLEAN NOT NULL DEFAULT 0;
@@ -0,0 +1 @@
+ALTER TABLE sandboxes ADD COLUMN labels TEXT;
@@ -0,0 +1 @@
+ALTER TABLE sandboxes ADD COLUMN image_digest TEXT;
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS host_processes (
+    sandbox_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    pid INTEGER NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (sandbox_id, name)
+);
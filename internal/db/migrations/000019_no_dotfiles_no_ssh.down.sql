@@ -0,0 +1,2 @@
+ALTER TABLE sandboxes DROP COLUMN no_ssh;
+ALTER TABLE sandboxes DROP COLUMN no_dotfiles;
@@ -0,0 +1,2 @@
+ALTER TABLE sandboxes ADD COLUMN no_dotfiles BOOLEAN NOT NULL DEFAULT 0;
+ALTER TABLE sandboxes ADD COLUMN no_ssh BOOLEAN NOT NULL DEFAULT 0;
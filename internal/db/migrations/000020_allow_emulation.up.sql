@@ -0,0 +1 @@
+ALTER TABLE sandboxes ADD COLUMN allow_emulation BOOLEAN NOT NULL DEFAULT 0;
@@ -0,0 +1 @@
+ALTER TABLE sandboxes DROP COLUMN ignore_oncreate_errors;
@@ -0,0 +1 @@
+ALTER TABLE sandboxes ADD COLUMN ignore_oncreate_errors BOOLEAN NOT NULL DEFAULT 0;
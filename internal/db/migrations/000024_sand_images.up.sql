@@ -0,0 +1,4 @@
+CREATE TABLE IF NOT EXISTS sand_images (
+    name TEXT PRIMARY KEY NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,2 @@
+ALTER TABLE host_processes DROP COLUMN boot_time;
+ALTER TABLE host_processes DROP COLUMN start_time;
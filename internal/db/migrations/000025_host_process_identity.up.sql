@@ -0,0 +1,2 @@
+ALTER TABLE host_processes ADD COLUMN start_time TEXT NOT NULL DEFAULT '';
+ALTER TABLE host_processes ADD COLUMN boot_time TEXT NOT NULL DEFAULT '';
@@ -8,6 +8,20 @@ import (
 	"database/sql"
 )
 
+type HostProcess struct {
+	SandboxID string       `json:"sandbox_id"`
+	Name      string       `json:"name"`
+	Pid       int64        `json:"pid"`
+	CreatedAt sql.NullTime `json:"created_at"`
+	StartTime string       `json:"start_time"`
+	BootTime  string       `json:"boot_time"`
+}
+
+type SandImage struct {
+	Name      string       `json:"name"`
+	CreatedAt sql.NullTime `json:"created_at"`
+}
+
 type Sandbox struct {
 	ID                    string         `json:"id"`
 	ContainerID           sql.NullString `json:"container_id"`
@@ -35,4 +49,17 @@ type Sandbox struct {
 	ProfileName           sql.NullString `json:"profile_name"`
 	MountSpecs            sql.NullString `json:"mount_specs"`
 	ContainerBootstrapped bool           `json:"container_bootstrapped"`
+	LastUsedAt            sql.NullTime   `json:"last_used_at"`
+	StartHooksRan         bool           `json:"start_hooks_ran"`
+	Labels                sql.NullString `json:"labels"`
+	Shell                 sql.NullString `json:"shell"`
+	ImageDigest           sql.NullString `json:"image_digest"`
+	Mounts                sql.NullString `json:"mounts"`
+	Network               sql.NullString `json:"network"`
+	NoDotfiles            bool           `json:"no_dotfiles"`
+	NoSsh                 bool           `json:"no_ssh"`
+	AllowEmulation        bool           `json:"allow_emulation"`
+	Publish               sql.NullString `json:"publish"`
+	DnsConfig             sql.NullString `json:"dns_config"`
+	IgnoreOncreateErrors  bool           `json:"ignore_oncreate_errors"`
 }
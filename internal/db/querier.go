@@ -9,17 +9,28 @@ import (
 )
 
 type Querier interface {
+	DeleteHostProcess(ctx context.Context, arg DeleteHostProcessParams) error
+	DeleteHostProcesses(ctx context.Context, sandboxID string) error
+	DeleteHostProcessesFromOtherBoots(ctx context.Context, bootTime string) error
+	DeleteSandImage(ctx context.Context, name string) error
 	DeleteSandbox(ctx context.Context, id string) error
 	GetActiveSandboxByName(ctx context.Context, name string) (Sandbox, error)
 	GetSandboxByID(ctx context.Context, id string) (Sandbox, error)
 	GetSandboxesByImage(ctx context.Context, imageName string) ([]Sandbox, error)
 	ListDeletedSandboxes(ctx context.Context) ([]Sandbox, error)
+	ListHostProcesses(ctx context.Context, sandboxID string) ([]HostProcess, error)
+	ListSandImages(ctx context.Context) ([]string, error)
 	ListSandboxes(ctx context.Context) ([]Sandbox, error)
+	MarkSandboxUsed(ctx context.Context, arg MarkSandboxUsedParams) error
+	RecordSandImage(ctx context.Context, name string) error
 	RecoverSandbox(ctx context.Context, arg RecoverSandboxParams) error
 	RenameSandbox(ctx context.Context, arg RenameSandboxParams) error
 	SoftDeleteSandbox(ctx context.Context, arg SoftDeleteSandboxParams) error
 	UpdateContainerBootstrapped(ctx context.Context, arg UpdateContainerBootstrappedParams) error
 	UpdateContainerID(ctx context.Context, arg UpdateContainerIDParams) error
+	UpdateImageName(ctx context.Context, arg UpdateImageNameParams) error
+	UpdateStartHooksRan(ctx context.Context, arg UpdateStartHooksRanParams) error
+	UpsertHostProcess(ctx context.Context, arg UpsertHostProcessParams) error
 	UpsertSandbox(ctx context.Context, arg UpsertSandboxParams) error
 }
 
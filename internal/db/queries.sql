@@ -23,10 +23,10 @@ INSERT INTO sandboxes (
     id, name, state, container_id, host_origin_dir, sandbox_work_dir,
     image_name, dns_domain, env_file, agent_type, profile_name,
     original_git_origin, original_git_branch, original_git_commit,
-    original_git_is_dirty, allowed_domains, mount_specs,
-    container_bootstrapped, cpu, memory_mb, default_username, default_uid,
-    deleted_at, trash_work_dir
-) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+    original_git_is_dirty, allowed_domains, mount_specs, mounts, labels, shell, network, image_digest,
+    no_dotfiles, no_ssh, allow_emulation, publish, dns_config, ignore_oncreate_errors, container_bootstrapped, start_hooks_ran, cpu, memory_mb, default_username,
+    default_uid, deleted_at, trash_work_dir
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 ON CONFLICT(id) DO UPDATE SET
     name = excluded.name,
     state = excluded.state,
@@ -45,7 +45,19 @@ ON CONFLICT(id) DO UPDATE SET
     original_git_is_dirty = excluded.original_git_is_dirty,
     allowed_domains = excluded.allowed_domains,
     mount_specs = excluded.mount_specs,
+    mounts = excluded.mounts,
+    labels = excluded.labels,
+    shell = excluded.shell,
+    network = excluded.network,
+    image_digest = excluded.image_digest,
+    no_dotfiles = excluded.no_dotfiles,
+    no_ssh = excluded.no_ssh,
+    allow_emulation = excluded.allow_emulation,
+    publish = excluded.publish,
+    dns_config = excluded.dns_config,
+    ignore_oncreate_errors = excluded.ignore_oncreate_errors,
     container_bootstrapped = excluded.container_bootstrapped,
+    start_hooks_ran = excluded.start_hooks_ran,
     cpu = excluded.cpu,
     memory_mb = excluded.memory_mb,
     default_username = excluded.default_username,
@@ -57,6 +69,7 @@ ON CONFLICT(id) DO UPDATE SET
 UPDATE sandboxes
 SET container_id = ?,
     container_bootstrapped = 0,
+    start_hooks_ran = 0,
     updated_at = CURRENT_TIMESTAMP
 WHERE id = ?;
 
@@ -66,6 +79,24 @@ SET container_bootstrapped = ?,
     updated_at = CURRENT_TIMESTAMP
 WHERE id = ?;
 
+-- name: UpdateStartHooksRan :exec
+UPDATE sandboxes
+SET start_hooks_ran = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: UpdateImageName :exec
+UPDATE sandboxes
+SET image_name = ?,
+    image_digest = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: MarkSandboxUsed :exec
+UPDATE sandboxes
+SET last_used_at = ?
+WHERE id = ?;
+
 -- name: RenameSandbox :exec
 UPDATE sandboxes
 SET name = ?,
@@ -78,6 +109,7 @@ SET name = ?,
     state = 'active',
     container_id = ?,
     container_bootstrapped = 0,
+    start_hooks_ran = 0,
     deleted_at = NULL,
     trash_work_dir = NULL,
     updated_at = CURRENT_TIMESTAMP
@@ -100,3 +132,43 @@ WHERE id = ?;
 SELECT * FROM sandboxes
 WHERE image_name = ? AND state = 'active'
 ORDER BY created_at DESC;
+
+-- name: UpsertHostProcess :exec
+INSERT INTO host_processes (sandbox_id, name, pid, start_time, boot_time)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT(sandbox_id, name) DO UPDATE SET
+    pid = excluded.pid,
+    start_time = excluded.start_time,
+    boot_time = excluded.boot_time,
+    created_at = CURRENT_TIMESTAMP;
+
+-- name: ListHostProcesses :many
+SELECT * FROM host_processes
+WHERE sandbox_id = ?
+ORDER BY name;
+
+-- name: DeleteHostProcesses :exec
+DELETE FROM host_processes
+WHERE sandbox_id = ?;
+
+-- name: DeleteHostProcess :exec
+DELETE FROM host_processes
+WHERE sandbox_id = ? AND name = ?;
+
+-- name: DeleteHostProcessesFromOtherBoots :exec
+DELETE FROM host_processes
+WHERE boot_time != ?;
+
+-- name: RecordSandImage :exec
+INSERT INTO sand_images (name)
+VALUES (?)
+ON CONFLICT(name) DO UPDATE SET
+    created_at = CURRENT_TIMESTAMP;
+
+-- name: ListSandImages :many
+SELECT name FROM sand_images
+ORDER BY name;
+
+-- name: DeleteSandImage :exec
+DELETE FROM sand_images
+WHERE name = ?;
@@ -10,6 +10,51 @@ import (
 	"database/sql"
 )
 
+const deleteHostProcess = `-- name: DeleteHostProcess :exec
+DELETE FROM host_processes
+WHERE sandbox_id = ? AND name = ?
+`
+
+type DeleteHostProcessParams struct {
+	SandboxID string `json:"sandbox_id"`
+	Name      string `json:"name"`
+}
+
+func (q *Queries) DeleteHostProcess(ctx context.Context, arg DeleteHostProcessParams) error {
+	_, err := q.db.ExecContext(ctx, deleteHostProcess, arg.SandboxID, arg.Name)
+	return err
+}
+
+const deleteHostProcesses = `-- name: DeleteHostProcesses :exec
+DELETE FROM host_processes
+WHERE sandbox_id = ?
+`
+
+func (q *Queries) DeleteHostProcesses(ctx context.Context, sandboxID string) error {
+	_, err := q.db.ExecContext(ctx, deleteHostProcesses, sandboxID)
+	return err
+}
+
+const deleteHostProcessesFromOtherBoots = `-- name: DeleteHostProcessesFromOtherBoots :exec
+DELETE FROM host_processes
+WHERE boot_time != ?
+`
+
+func (q *Queries) DeleteHostProcessesFromOtherBoots(ctx context.Context, bootTime string) error {
+	_, err := q.db.ExecContext(ctx, deleteHostProcessesFromOtherBoots, bootTime)
+	return err
+}
+
+const deleteSandImage = `-- name: DeleteSandImage :exec
+DELETE FROM sand_images
+WHERE name = ?
+`
+
+func (q *Queries) DeleteSandImage(ctx context.Context, name string) error {
+	_, err := q.db.ExecContext(ctx, deleteSandImage, name)
+	return err
+}
+
 const deleteSandbox = `-- name: DeleteSandbox :exec
 DELETE FROM sandboxes
 WHERE id = ?
@@ -21,7 +66,7 @@ func (q *Queries) DeleteSandbox(ctx context.Context, id string) error {
 }
 
 const getActiveSandboxByName = `-- name: GetActiveSandboxByName :one
-SELECT id, container_id, host_origin_dir, sandbox_work_dir, image_name, dns_domain, env_file, created_at, updated_at, agent_type, original_git_origin, original_git_branch, original_git_commit, original_git_is_dirty, allowed_domains, cpu, memory_mb, default_username, default_uid, name, state, deleted_at, trash_work_dir, profile_name, mount_specs, container_bootstrapped FROM sandboxes
+SELECT id, container_id, host_origin_dir, sandbox_work_dir, image_name, dns_domain, env_file, created_at, updated_at, agent_type, original_git_origin, original_git_branch, original_git_commit, original_git_is_dirty, allowed_domains, cpu, memory_mb, default_username, default_uid, name, state, deleted_at, trash_work_dir, profile_name, mount_specs, container_bootstrapped, last_used_at, start_hooks_ran, labels, shell, image_digest, mounts, network, no_dotfiles, no_ssh, allow_emulation, publish, dns_config, ignore_oncreate_errors FROM sandboxes
 WHERE name = ? AND state = 'active'
 LIMIT 1
 `
@@ -56,12 +101,25 @@ func (q *Queries) GetActiveSandboxByName(ctx context.Context, name string) (Sand
 		&i.ProfileName,
 		&i.MountSpecs,
 		&i.ContainerBootstrapped,
+		&i.LastUsedAt,
+		&i.StartHooksRan,
+		&i.Labels,
+		&i.Shell,
+		&i.ImageDigest,
+		&i.Mounts,
+		&i.Network,
+		&i.NoDotfiles,
+		&i.NoSsh,
+		&i.AllowEmulation,
+		&i.Publish,
+		&i.DnsConfig,
+		&i.IgnoreOncreateErrors,
 	)
 	return i, err
 }
 
 const getSandboxByID = `-- name: GetSandboxByID :one
-SELECT id, container_id, host_origin_dir, sandbox_work_dir, image_name, dns_domain, env_file, created_at, updated_at, agent_type, original_git_origin, original_git_branch, original_git_commit, original_git_is_dirty, allowed_domains, cpu, memory_mb, default_username, default_uid, name, state, deleted_at, trash_work_dir, profile_name, mount_specs, container_bootstrapped FROM sandboxes
+SELECT id, container_id, host_origin_dir, sandbox_work_dir, image_name, dns_domain, env_file, created_at, updated_at, agent_type, original_git_origin, original_git_branch, original_git_commit, original_git_is_dirty, allowed_domains, cpu, memory_mb, default_username, default_uid, name, state, deleted_at, trash_work_dir, profile_name, mount_specs, container_bootstrapped, last_used_at, start_hooks_ran, labels, shell, image_digest, mounts, network, no_dotfiles, no_ssh, allow_emulation, publish, dns_config, ignore_oncreate_errors FROM sandboxes
 WHERE id = ?
 LIMIT 1
 `
@@ -96,12 +154,25 @@ func (q *Queries) GetSandboxByID(ctx context.Context, id string) (Sandbox, error
 		&i.ProfileName,
 		&i.MountSpecs,
 		&i.ContainerBootstrapped,
+		&i.LastUsedAt,
+		&i.StartHooksRan,
+		&i.Labels,
+		&i.Shell,
+		&i.ImageDigest,
+		&i.Mounts,
+		&i.Network,
+		&i.NoDotfiles,
+		&i.NoSsh,
+		&i.AllowEmulation,
+		&i.Publish,
+		&i.DnsConfig,
+		&i.IgnoreOncreateErrors,
 	)
 	return i, err
 }
 
 const getSandboxesByImage = `-- name: GetSandboxesByImage :many
-SELECT id, container_id, host_origin_dir, sandbox_work_dir, image_name, dns_domain, env_file, created_at, updated_at, agent_type, original_git_origin, original_git_branch, original_git_commit, original_git_is_dirty, allowed_domains, cpu, memory_mb, default_username, default_uid, name, state, deleted_at, trash_work_dir, profile_name, mount_specs, container_bootstrapped FROM sandboxes
+SELECT id, container_id, host_origin_dir, sandbox_work_dir, image_name, dns_domain, env_file, created_at, updated_at, agent_type, original_git_origin, original_git_branch, original_git_commit, original_git_is_dirty, allowed_domains, cpu, memory_mb, default_username, default_uid, name, state, deleted_at, trash_work_dir, profile_name, mount_specs, container_bootstrapped, last_used_at, start_hooks_ran, labels, shell, image_digest, mounts, network, no_dotfiles, no_ssh, allow_emulation, publish, dns_config, ignore_oncreate_errors FROM sandboxes
 WHERE image_name = ? AND state = 'active'
 ORDER BY created_at DESC
 `
@@ -142,6 +213,19 @@ func (q *Queries) GetSandboxesByImage(ctx context.Context, imageName string) ([]
 			&i.ProfileName,
 			&i.MountSpecs,
 			&i.ContainerBootstrapped,
+			&i.LastUsedAt,
+			&i.StartHooksRan,
+			&i.Labels,
+			&i.Shell,
+			&i.ImageDigest,
+			&i.Mounts,
+			&i.Network,
+			&i.NoDotfiles,
+			&i.NoSsh,
+			&i.AllowEmulation,
+			&i.Publish,
+			&i.DnsConfig,
+			&i.IgnoreOncreateErrors,
 		); err != nil {
 			return nil, err
 		}
@@ -157,7 +241,7 @@ func (q *Queries) GetSandboxesByImage(ctx context.Context, imageName string) ([]
 }
 
 const listDeletedSandboxes = `-- name: ListDeletedSandboxes :many
-SELECT id, container_id, host_origin_dir, sandbox_work_dir, image_name, dns_domain, env_file, created_at, updated_at, agent_type, original_git_origin, original_git_branch, original_git_commit, original_git_is_dirty, allowed_domains, cpu, memory_mb, default_username, default_uid, name, state, deleted_at, trash_work_dir, profile_name, mount_specs, container_bootstrapped FROM sandboxes
+SELECT id, container_id, host_origin_dir, sandbox_work_dir, image_name, dns_domain, env_file, created_at, updated_at, agent_type, original_git_origin, original_git_branch, original_git_commit, original_git_is_dirty, allowed_domains, cpu, memory_mb, default_username, default_uid, name, state, deleted_at, trash_work_dir, profile_name, mount_specs, container_bootstrapped, last_used_at, start_hooks_ran, labels, shell, image_digest, mounts, network, no_dotfiles, no_ssh, allow_emulation, publish, dns_config, ignore_oncreate_errors FROM sandboxes
 WHERE state = 'deleted'
 ORDER BY deleted_at DESC, created_at DESC
 `
@@ -198,6 +282,19 @@ func (q *Queries) ListDeletedSandboxes(ctx context.Context) ([]Sandbox, error) {
 			&i.ProfileName,
 			&i.MountSpecs,
 			&i.ContainerBootstrapped,
+			&i.LastUsedAt,
+			&i.StartHooksRan,
+			&i.Labels,
+			&i.Shell,
+			&i.ImageDigest,
+			&i.Mounts,
+			&i.Network,
+			&i.NoDotfiles,
+			&i.NoSsh,
+			&i.AllowEmulation,
+			&i.Publish,
+			&i.DnsConfig,
+			&i.IgnoreOncreateErrors,
 		); err != nil {
 			return nil, err
 		}
@@ -212,8 +309,72 @@ func (q *Queries) ListDeletedSandboxes(ctx context.Context) ([]Sandbox, error) {
 	return items, nil
 }
 
+const listHostProcesses = `-- name: ListHostProcesses :many
+SELECT sandbox_id, name, pid, created_at, start_time, boot_time FROM host_processes
+WHERE sandbox_id = ?
+ORDER BY name
+`
+
+func (q *Queries) ListHostProcesses(ctx context.Context, sandboxID string) ([]HostProcess, error) {
+	rows, err := q.db.QueryContext(ctx, listHostProcesses, sandboxID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []HostProcess
+	for rows.Next() {
+		var i HostProcess
+		if err := rows.Scan(
+			&i.SandboxID,
+			&i.Name,
+			&i.Pid,
+			&i.CreatedAt,
+			&i.StartTime,
+			&i.BootTime,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSandImages = `-- name: ListSandImages :many
+SELECT name FROM sand_images
+ORDER BY name
+`
+
+func (q *Queries) ListSandImages(ctx context.Context) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listSandImages)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		items = append(items, name)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listSandboxes = `-- name: ListSandboxes :many
-SELECT id, container_id, host_origin_dir, sandbox_work_dir, image_name, dns_domain, env_file, created_at, updated_at, agent_type, original_git_origin, original_git_branch, original_git_commit, original_git_is_dirty, allowed_domains, cpu, memory_mb, default_username, default_uid, name, state, deleted_at, trash_work_dir, profile_name, mount_specs, container_bootstrapped FROM sandboxes
+SELECT id, container_id, host_origin_dir, sandbox_work_dir, image_name, dns_domain, env_file, created_at, updated_at, agent_type, original_git_origin, original_git_branch, original_git_commit, original_git_is_dirty, allowed_domains, cpu, memory_mb, default_username, default_uid, name, state, deleted_at, trash_work_dir, profile_name, mount_specs, container_bootstrapped, last_used_at, start_hooks_ran, labels, shell, image_digest, mounts, network, no_dotfiles, no_ssh, allow_emulation, publish, dns_config, ignore_oncreate_errors FROM sandboxes
 WHERE state = 'active'
 ORDER BY created_at DESC
 `
@@ -254,6 +415,19 @@ func (q *Queries) ListSandboxes(ctx context.Context) ([]Sandbox, error) {
 			&i.ProfileName,
 			&i.MountSpecs,
 			&i.ContainerBootstrapped,
+			&i.LastUsedAt,
+			&i.StartHooksRan,
+			&i.Labels,
+			&i.Shell,
+			&i.ImageDigest,
+			&i.Mounts,
+			&i.Network,
+			&i.NoDotfiles,
+			&i.NoSsh,
+			&i.AllowEmulation,
+			&i.Publish,
+			&i.DnsConfig,
+			&i.IgnoreOncreateErrors,
 		); err != nil {
 			return nil, err
 		}
@@ -268,12 +442,41 @@ func (q *Queries) ListSandboxes(ctx context.Context) ([]Sandbox, error) {
 	return items, nil
 }
 
+const markSandboxUsed = `-- name: MarkSandboxUsed :exec
+UPDATE sandboxes
+SET last_used_at = ?
+WHERE id = ?
+`
+
+type MarkSandboxUsedParams struct {
+	LastUsedAt sql.NullTime `json:"last_used_at"`
+	ID         string       `json:"id"`
+}
+
+func (q *Queries) MarkSandboxUsed(ctx context.Context, arg MarkSandboxUsedParams) error {
+	_, err := q.db.ExecContext(ctx, markSandboxUsed, arg.LastUsedAt, arg.ID)
+	return err
+}
+
+const recordSandImage = `-- name: RecordSandImage :exec
+INSERT INTO sand_images (name)
+VALUES (?)
+ON CONFLICT(name) DO UPDATE SET
+    created_at = CURRENT_TIMESTAMP
+`
+
+func (q *Queries) RecordSandImage(ctx context.Context, name string) error {
+	_, err := q.db.ExecContext(ctx, recordSandImage, name)
+	return err
+}
+
 const recoverSandbox = `-- name: RecoverSandbox :exec
 UPDATE sandboxes
 SET name = ?,
     state = 'active',
     container_id = ?,
     container_bootstrapped = 0,
+    start_hooks_ran = 0,
     deleted_at = NULL,
     trash_work_dir = NULL,
     updated_at = CURRENT_TIMESTAMP
@@ -349,6 +552,7 @@ const updateContainerID = `-- name: UpdateContainerID :exec
 UPDATE sandboxes
 SET container_id = ?,
     container_bootstrapped = 0,
+    start_hooks_ran = 0,
     updated_at = CURRENT_TIMESTAMP
 WHERE id = ?
 `
@@ -363,15 +567,80 @@ func (q *Queries) UpdateContainerID(ctx context.Context, arg UpdateContainerIDPa
 	return err
 }
 
+const updateImageName = `-- name: UpdateImageName :exec
+UPDATE sandboxes
+SET image_name = ?,
+    image_digest = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+`
+
+type UpdateImageNameParams struct {
+	ImageName   string         `json:"image_name"`
+	ImageDigest sql.NullString `json:"image_digest"`
+	ID          string         `json:"id"`
+}
+
+func (q *Queries) UpdateImageName(ctx context.Context, arg UpdateImageNameParams) error {
+	_, err := q.db.ExecContext(ctx, updateImageName, arg.ImageName, arg.ImageDigest, arg.ID)
+	return err
+}
+
+const updateStartHooksRan = `-- name: UpdateStartHooksRan :exec
+UPDATE sandboxes
+SET start_hooks_ran = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+`
+
+type UpdateStartHooksRanParams struct {
+	StartHooksRan bool   `json:"start_hooks_ran"`
+	ID            string `json:"id"`
+}
+
+func (q *Queries) UpdateStartHooksRan(ctx context.Context, arg UpdateStartHooksRanParams) error {
+	_, err := q.db.ExecContext(ctx, updateStartHooksRan, arg.StartHooksRan, arg.ID)
+	return err
+}
+
+const upsertHostProcess = `-- name: UpsertHostProcess :exec
+INSERT INTO host_processes (sandbox_id, name, pid, start_time, boot_time)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT(sandbox_id, name) DO UPDATE SET
+    pid = excluded.pid,
+    start_time = excluded.start_time,
+    boot_time = excluded.boot_time,
+    created_at = CURRENT_TIMESTAMP
+`
+
+type UpsertHostProcessParams struct {
+	SandboxID string `json:"sandbox_id"`
+	Name      string `json:"name"`
+	Pid       int64  `json:"pid"`
+	StartTime string `json:"start_time"`
+	BootTime  string `json:"boot_time"`
+}
+
+func (q *Queries) UpsertHostProcess(ctx context.Context, arg UpsertHostProcessParams) error {
+	_, err := q.db.ExecContext(ctx, upsertHostProcess,
+		arg.SandboxID,
+		arg.Name,
+		arg.Pid,
+		arg.StartTime,
+		arg.BootTime,
+	)
+	return err
+}
+
 const upsertSandbox = `-- name: UpsertSandbox :exec
 INSERT INTO sandboxes (
     id, name, state, container_id, host_origin_dir, sandbox_work_dir,
     image_name, dns_domain, env_file, agent_type, profile_name,
     original_git_origin, original_git_branch, original_git_commit,
-    original_git_is_dirty, allowed_domains, mount_specs,
-    container_bootstrapped, cpu, memory_mb, default_username, default_uid,
-    deleted_at, trash_work_dir
-) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+    original_git_is_dirty, allowed_domains, mount_specs, mounts, labels, shell, network, image_digest,
+    no_dotfiles, no_ssh, allow_emulation, publish, dns_config, ignore_oncreate_errors, container_bootstrapped, start_hooks_ran, cpu, memory_mb, default_username,
+    default_uid, deleted_at, trash_work_dir
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 ON CONFLICT(id) DO UPDATE SET
     name = excluded.name,
     state = excluded.state,
@@ -390,7 +659,19 @@ ON CONFLICT(id) DO UPDATE SET
     original_git_is_dirty = excluded.original_git_is_dirty,
     allowed_domains = excluded.allowed_domains,
     mount_specs = excluded.mount_specs,
+    mounts = excluded.mounts,
+    labels = excluded.labels,
+    shell = excluded.shell,
+    network = excluded.network,
+    image_digest = excluded.image_digest,
+    no_dotfiles = excluded.no_dotfiles,
+    no_ssh = excluded.no_ssh,
+    allow_emulation = excluded.allow_emulation,
+    publish = excluded.publish,
+    dns_config = excluded.dns_config,
+    ignore_oncreate_errors = excluded.ignore_oncreate_errors,
     container_bootstrapped = excluded.container_bootstrapped,
+    start_hooks_ran = excluded.start_hooks_ran,
     cpu = excluded.cpu,
     memory_mb = excluded.memory_mb,
     default_username = excluded.default_username,
@@ -417,7 +698,19 @@ type UpsertSandboxParams struct {
 	OriginalGitIsDirty    bool           `json:"original_git_is_dirty"`
 	AllowedDomains        sql.NullString `json:"allowed_domains"`
 	MountSpecs            sql.NullString `json:"mount_specs"`
+	Mounts                sql.NullString `json:"mounts"`
+	Labels                sql.NullString `json:"labels"`
+	Shell                 sql.NullString `json:"shell"`
+	Network               sql.NullString `json:"network"`
+	ImageDigest           sql.NullString `json:"image_digest"`
+	NoDotfiles            bool           `json:"no_dotfiles"`
+	NoSsh                 bool           `json:"no_ssh"`
+	AllowEmulation        bool           `json:"allow_emulation"`
+	Publish               sql.NullString `json:"publish"`
+	DnsConfig             sql.NullString `json:"dns_config"`
+	IgnoreOncreateErrors  bool           `json:"ignore_oncreate_errors"`
 	ContainerBootstrapped bool           `json:"container_bootstrapped"`
+	StartHooksRan         bool           `json:"start_hooks_ran"`
 	Cpu                   sql.NullInt64  `json:"cpu"`
 	MemoryMb              sql.NullInt64  `json:"memory_mb"`
 	DefaultUsername       sql.NullString `json:"default_username"`
@@ -445,7 +738,19 @@ func (q *Queries) UpsertSandbox(ctx context.Context, arg UpsertSandboxParams) er
 		arg.OriginalGitIsDirty,
 		arg.AllowedDomains,
 		arg.MountSpecs,
+		arg.Mounts,
+		arg.Labels,
+		arg.Shell,
+		arg.Network,
+		arg.ImageDigest,
+		arg.NoDotfiles,
+		arg.NoSsh,
+		arg.AllowEmulation,
+		arg.Publish,
+		arg.DnsConfig,
+		arg.IgnoreOncreateErrors,
 		arg.ContainerBootstrapped,
+		arg.StartHooksRan,
 		arg.Cpu,
 		arg.MemoryMb,
 		arg.DefaultUsername,
@@ -1,6 +1,20 @@
 -- Code generated by go generate; DO NOT EDIT.
 -- Source of truth: internal/db/migrations/*.up.sql.
 
+CREATE TABLE host_processes (
+    sandbox_id TEXT PRIMARY KEY NOT NULL,
+    name TEXT PRIMARY KEY NOT NULL,
+    pid INTEGER NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    start_time TEXT NOT NULL DEFAULT '',
+    boot_time TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE sand_images (
+    name TEXT PRIMARY KEY NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
 CREATE TABLE sandboxes (
     id TEXT PRIMARY KEY,
     container_id TEXT,
@@ -27,7 +41,20 @@ CREATE TABLE sandboxes (
     trash_work_dir TEXT,
     profile_name TEXT,
     mount_specs TEXT,
-    container_bootstrapped BOOLEAN NOT NULL DEFAULT 1
+    container_bootstrapped BOOLEAN NOT NULL DEFAULT 1,
+    last_used_at DATETIME,
+    start_hooks_ran BOOLEAN NOT NULL DEFAULT 0,
+    labels TEXT,
+    shell TEXT,
+    image_digest TEXT,
+    mounts TEXT,
+    network TEXT,
+    no_dotfiles BOOLEAN NOT NULL DEFAULT 0,
+    no_ssh BOOLEAN NOT NULL DEFAULT 0,
+    allow_emulation BOOLEAN NOT NULL DEFAULT 0,
+    publish TEXT,
+    dns_config TEXT,
+    ignore_oncreate_errors BOOLEAN NOT NULL DEFAULT 0
 );
 
 CREATE UNIQUE INDEX idx_active_sandbox_name ON sandboxes(name) WHERE state = 'active';
@@ -43,6 +43,7 @@ func NewEngine(exec sandtypes.HookStreamer) *script.Engine {
 			"write-http-proxy-env":   writeHTTPProxyEnvCmd(exec),
 			"install-npm-agent":      installNPMAgentCmd(exec),
 			"install-opencode-agent": installOpenCodeAgentCmd(exec),
+			"ensure-gnu-tar":         ensureGNUTarCmd(exec),
 		},
 		Conds: map[string]script.Cond{
 			"cmd":    commandExistsCond(exec),
@@ -128,6 +129,18 @@ func installOpenCodeAgentCmd(exec sandtypes.HookStreamer) script.Cmd {
 	})
 }
 
+func ensureGNUTarCmd(exec sandtypes.HookStreamer) script.Cmd {
+	return script.Command(script.CmdUsage{Summary: "replace busybox tar with GNU tar"}, func(s *script.State, args ...string) (script.WaitFunc, error) {
+		if len(args) != 0 {
+			return nil, script.ErrUsage
+		}
+		err := ensureGNUTar(s.Context(), exec)
+		return func(*script.State) (string, string, error) {
+			return "", "", err
+		}, nil
+	})
+}
+
 func commandExistsCond(exec sandtypes.HookStreamer) script.Cond {
 	return script.PrefixCondition("command exists in container", func(s *script.State, suffix string) (bool, error) {
 		if suffix == "" {
@@ -562,6 +575,43 @@ func installOpenCodeAgent(ctx context.Context, exec sandtypes.HookStreamer, comm
 	return nil
 }
 
+// ensureGNUTar installs GNU tar when tar is busybox's, which lacks options
+// such as --wildcards that opencode uses to unpack ripgrep. Images with
+// neither apk nor apt-get are left alone.
+func ensureGNUTar(ctx context.Context, exec sandtypes.HookStreamer) error {
+	if !tarIsBusyBox(ctx, exec) {
+		return nil
+	}
+	switch {
+	case commandExists(ctx, exec, "apk"):
+		if err := stream(ctx, exec, "apk", "add", "--no-cache", "tar"); err != nil {
+			return fmt.Errorf("install GNU tar with apk: %w", err)
+		}
+	case commandExists(ctx, exec, "apt-get"):
+		if err := stream(ctx, exec, "apt-get", "update"); err != nil {
+			return fmt.Errorf("apt-get update for GNU tar: %w", err)
+		}
+		if err := stream(ctx, exec, "apt-get", "install", "-y", "--no-install-recommends", "tar"); err != nil {
+			return fmt.Errorf("install GNU tar with apt-get: %w", err)
+		}
+	}
+	return nil
+}
+
+// tarIsBusyBox reports whether the tar on the container's PATH is a link to
+// busybox.
+func tarIsBusyBox(ctx context.Context, exec sandtypes.HookStreamer) bool {
+	tarPath, err := exec.Exec(ctx, "which", "tar")
+	if err != nil || strings.TrimSpace(tarPath) == "" {
+		return false
+	}
+	target, err := exec.Exec(ctx, "readlink", "-f", strings.TrimSpace(tarPath))
+	if err != nil {
+		return false
+	}
+	return path.Base(strings.TrimSpace(target)) == "busybox"
+}
+
 func commandExists(ctx context.Context, exec sandtypes.HookStreamer, command string) bool {
 	_, err := exec.Exec(ctx, "which", command)
 	return err == nil
@@ -359,6 +359,99 @@ func TestAgentCacheRootFallsBackWhenSharedCacheIsUnavailable(t *testing.T) {
 	}
 }
 
+func TestTarIsBusyBox(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		results map[string]fakeResult
+		want    bool
+	}{
+		{
+			name: "busybox applet",
+			results: map[string]fakeResult{
+				"which tar":            {out: "/bin/tar\n"},
+				"readlink -f /bin/tar": {out: "/bin/busybox\n"},
+			},
+			want: true,
+		},
+		{
+			name: "GNU tar",
+			results: map[string]fakeResult{
+				"which tar":                {out: "/usr/bin/tar\n"},
+				"readlink -f /usr/bin/tar": {out: "/usr/bin/tar\n"},
+			},
+		},
+		{
+			name:    "no tar",
+			results: map[string]fakeResult{"which tar": {err: errors.New("missing")}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tarIsBusyBox(context.Background(), &fakeStreamer{execResults: tc.results}); got != tc.want {
+				t.Fatalf("tarIsBusyBox() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestEnsureGNUTarInstallsOnlyOverBusyBox(t *testing.T) {
+	busybox := map[string]fakeResult{
+		"which tar":            {out: "/bin/tar\n"},
+		"readlink -f /bin/tar": {out: "/bin/busybox\n"},
+	}
+	for _, tc := range []struct {
+		name    string
+		results map[string]fakeResult
+		want    []string
+	}{
+		{
+			name:    "apk",
+			results: busybox,
+			want:    []string{"stream:apk add --no-cache tar"},
+		},
+		{
+			name: "apt-get",
+			results: map[string]fakeResult{
+				"which tar":            busybox["which tar"],
+				"readlink -f /bin/tar": busybox["readlink -f /bin/tar"],
+				"which apk":            {err: errors.New("missing")},
+			},
+			want: []string{"stream:apt-get update", "stream:apt-get install -y --no-install-recommends tar"},
+		},
+		{
+			name: "no package manager",
+			results: map[string]fakeResult{
+				"which tar":            busybox["which tar"],
+				"readlink -f /bin/tar": busybox["readlink -f /bin/tar"],
+				"which apk":            {err: errors.New("missing")},
+				"which apt-get":        {err: errors.New("missing")},
+			},
+		},
+		{
+			name: "GNU tar already",
+			results: map[string]fakeResult{
+				"which tar":                {out: "/usr/bin/tar\n"},
+				"readlink -f /usr/bin/tar": {out: "/usr/bin/tar\n"},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			exec := &fakeStreamer{execResults: tc.results}
+			if err := Execute(context.Background(), exec, "gnu-tar.txt", "ensure-gnu-tar\n", io.Discard); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			var streamed []string
+			for _, call := range exec.calls {
+				if strings.HasPrefix(call, "stream:") {
+					streamed = append(streamed, call)
+				}
+			}
+			if !reflect.DeepEqual(streamed, tc.want) {
+				t.Fatalf("streamed commands = %#v, want %#v", streamed, tc.want)
+			}
+		})
+	}
+}
+
 func containsCall(calls []string, want string) bool {
 	for _, call := range calls {
 		if call == want {
@@ -0,0 +1,44 @@
+package hostops
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// logsCommand builds the "container logs" invocation for Logs; tests replace it.
+var logsCommand = exec.CommandContext
+
+// containerLogsArgs returns the "container logs" arguments for opts.
+func containerLogsArgs(opts *LogsContainer, containerID string) []string {
+	args := []string{"logs"}
+	if opts != nil {
+		if opts.Boot {
+			args = append(args, "--boot")
+		}
+		if opts.Lines > 0 {
+			args = append(args, "-n", strconv.Itoa(opts.Lines))
+		}
+	}
+	return append(args, containerID)
+}
+
+// containerLogs runs "container logs". The XPC API hands logs back as file
+// descriptors, which the XPC client doesn't decode, so they are read through
+// the CLI.
+func containerLogs(ctx context.Context, opts *LogsContainer, containerID string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := logsCommand(ctx, "container", containerLogsArgs(opts, containerID)...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("container logs %s: %w: %s", containerID, err, msg)
+		}
+		return "", fmt.Errorf("container logs %s: %w", containerID, err)
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,43 @@
+package hostops
+
+import (
+	"context"
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestContainerLogsRunsContainerLogs(t *testing.T) {
+	var gotName string
+	var gotArgs []string
+	old := logsCommand
+	logsCommand = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		gotName, gotArgs = name, args
+		return exec.CommandContext(ctx, "sh", "-c", `echo "vminitd: booted"`)
+	}
+	t.Cleanup(func() { logsCommand = old })
+
+	out, err := containerLogs(context.Background(), &LogsContainer{Boot: true, Lines: 20}, "ctr-1")
+	if err != nil {
+		t.Fatalf("containerLogs() error = %v", err)
+	}
+	if want := []string{"logs", "--boot", "-n", "20", "ctr-1"}; gotName != "container" || !slices.Equal(gotArgs, want) {
+		t.Fatalf("command = %s %v, want container %v", gotName, gotArgs, want)
+	}
+	if out != "vminitd: booted\n" {
+		t.Fatalf("containerLogs() = %q, want the command's stdout", out)
+	}
+}
+
+func TestContainerLogsReportsStderr(t *testing.T) {
+	old := logsCommand
+	logsCommand = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		return exec.CommandContext(ctx, "sh", "-c", "echo 'container not found' >&2; exit 1")
+	}
+	t.Cleanup(func() { logsCommand = old })
+
+	if _, err := containerLogs(context.Background(), nil, "ctr-1"); err == nil || !strings.Contains(err.Error(), "container not found") {
+		t.Fatalf("containerLogs() error = %v, want the CLI's stderr", err)
+	}
+}
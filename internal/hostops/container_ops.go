@@ -2,7 +2,12 @@ package hostops
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
+	"strconv"
+	"strings"
+	"syscall"
 
 	"github.com/banksean/sand/internal/imageprogress"
 	"github.com/banksean/sand/internal/sandtypes"
@@ -12,11 +17,19 @@ type ContainerOps interface {
 	Create(ctx context.Context, opts *CreateContainer, image string, args []string) (string, error)
 	Start(ctx context.Context, opts *StartContainer, containerID string) (string, error)
 	Stop(ctx context.Context, opts *StopContainer, containerID string) (string, error)
+	// Kill sends a signal, SIGKILL by default, straight to the container's
+	// init process without waiting for it to exit.
+	Kill(ctx context.Context, opts *KillContainer, containerID string) (string, error)
 	Delete(ctx context.Context, opts *DeleteContainer, containerID string) (string, error)
 	Exec(ctx context.Context, opts *ExecContainer, containerID, cmd string, env []string, args ...string) (string, error)
 	ExecStream(ctx context.Context, opts *ExecContainer, containerID, cmd string, env []string, stdin io.Reader, stdout, stderr io.Writer, cmdArgs ...string) (func() error, error)
-	Inspect(ctx context.Context, containerID string) ([]sandtypes.Container, error)
+	// Inspect describes the given containers in one call. A container that
+	// doesn't exist is an error when it is the only one asked for, and is
+	// simply absent from the result otherwise.
+	Inspect(ctx context.Context, containerID ...string) ([]sandtypes.Container, error)
 	Stats(ctx context.Context, containerID ...string) ([]sandtypes.ContainerStats, error)
+	// Logs returns the container's stdio log, or its boot log with opts.Boot.
+	Logs(ctx context.Context, opts *LogsContainer, containerID string) (string, error)
 	Export(ctx context.Context, opts *ExportContainer, imageName string) (string, error)
 }
 
@@ -24,10 +37,71 @@ type ImageOps interface {
 	List(ctx context.Context) ([]sandtypes.ImageEntry, error)
 	Pull(ctx context.Context, image string, progress imageprogress.Sink) (func() error, error)
 	Inspect(ctx context.Context, name string) ([]*sandtypes.ImageManifest, error)
+	// Build builds the image in contextDir, writing build logs to w as they
+	// are produced.
+	Build(ctx context.Context, opts *BuildImage, contextDir string, w io.Writer) error
+	// Remove deletes the named local image and any content only it used.
+	Remove(ctx context.Context, name string) error
 }
 
+// ErrContainerNameConflict is wrapped by errors from Create when the
+// requested container name is taken.
+var ErrContainerNameConflict = errors.New("container name already in use")
+
+// ErrContainerNotFound is wrapped by errors from Inspect and Delete when the
+// container doesn't exist.
+var ErrContainerNotFound = errors.New("container not found")
+
+// IsContainerNameConflict reports whether err from Create means the requested
+// container name is taken.
+func IsContainerNameConflict(err error) bool {
+	return errors.Is(err, ErrContainerNameConflict)
+}
+
+// IsContainerNotFound reports whether err from Inspect or Delete means the
+// container is gone, e.g. removed with `container rm` or by a runtime reset.
+func IsContainerNotFound(err error) bool {
+	return errors.Is(err, ErrContainerNotFound)
+}
+
+// signalNumbers maps the signal names sand accepts to their numbers.
+var signalNumbers = map[string]syscall.Signal{
+	"HUP":  syscall.SIGHUP,
+	"INT":  syscall.SIGINT,
+	"QUIT": syscall.SIGQUIT,
+	"KILL": syscall.SIGKILL,
+	"USR1": syscall.SIGUSR1,
+	"USR2": syscall.SIGUSR2,
+	"TERM": syscall.SIGTERM,
+}
+
+// ParseSignal parses a signal given by name, with or without the SIG
+// prefix and in any case, or by number. An empty name means SIGKILL.
+func ParseSignal(name string) (syscall.Signal, error) {
+	if name == "" {
+		return syscall.SIGKILL, nil
+	}
+	if n, err := strconv.Atoi(name); err == nil {
+		if n <= 0 || n > 64 {
+			return 0, fmt.Errorf("invalid signal number %d", n)
+		}
+		return syscall.Signal(n), nil
+	}
+	sig, ok := signalNumbers[strings.TrimPrefix(strings.ToUpper(name), "SIG")]
+	if !ok {
+		return 0, fmt.Errorf("unknown signal %q", name)
+	}
+	return sig, nil
+}
+
+// NewAppleContainerOps returns ContainerOps for the Apple container runtime,
+// retrying transient failures according to DefaultRetryPolicy.
 func NewAppleContainerOps() (ContainerOps, error) {
-	return newAppleContainerOps()
+	ops, err := newAppleContainerOps()
+	if err != nil {
+		return nil, err
+	}
+	return NewRetryingContainerOps(ops, DefaultRetryPolicy), nil
 }
 
 func NewAppleImageOps() (ImageOps, error) {
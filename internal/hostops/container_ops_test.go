@@ -0,0 +1,30 @@
+package hostops
+
+import (
+	"syscall"
+	"testing"
+)
+
+func TestParseSignal(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		want syscall.Signal
+	}{
+		{"", syscall.SIGKILL},
+		{"KILL", syscall.SIGKILL},
+		{"sigterm", syscall.SIGTERM},
+		{"SIGINT", syscall.SIGINT},
+		{"hup", syscall.SIGHUP},
+		{"15", syscall.SIGTERM},
+	} {
+		got, err := ParseSignal(tc.name)
+		if err != nil || got != tc.want {
+			t.Errorf("ParseSignal(%q) = %v, %v; want %v", tc.name, got, err, tc.want)
+		}
+	}
+	for _, name := range []string{"NOPE", "0", "-9", "65"} {
+		if _, err := ParseSignal(name); err == nil {
+			t.Errorf("ParseSignal(%q) error = nil, want an error", name)
+		}
+	}
+}
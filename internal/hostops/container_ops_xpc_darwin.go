@@ -6,6 +6,7 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -85,17 +86,19 @@ func (o *xpcContainerOps) Create(ctx context.Context, opts *CreateContainer, ima
 		SSH:            opts.SSH,
 		Virtualization: opts.Virtualization,
 		Labels:         opts.Label,
+		// Rosetta runs amd64 images on Apple silicon.
+		Rosetta: platform.Architecture == "amd64" && runtime.GOARCH == "arm64",
 	}
 	cfg.Mounts, err = parseFilesystems(append(append([]string{}, opts.Mount...), opts.Volume...))
 	if err != nil {
 		return "", err
 	}
-	if opts.Publish != "" {
-		publishedPort, err := parsePublishPort(opts.Publish)
+	for _, spec := range opts.Publish {
+		publishedPort, err := ParsePublishPort(spec)
 		if err != nil {
 			return "", err
 		}
-		cfg.PublishedPorts = []xpc.PublishPort{publishedPort}
+		cfg.PublishedPorts = append(cfg.PublishedPorts, publishedPort)
 	}
 	cfg.Networks = defaultNetworkAttachments(id, opts.DNSDomain, opts.Network)
 	if !opts.NoDNS {
@@ -113,7 +116,7 @@ func (o *xpcContainerOps) Create(ctx context.Context, opts *CreateContainer, ima
 		return "", err
 	}
 	if err := o.client.CreateContainer(ctx, cfg, xpc.ContainerCreateOptions{AutoRemove: opts.Remove}, kernel, opts.InitImage, nil); err != nil {
-		return "", err
+		return "", xpcContainerError(err, xpc.XPCErrorCodeExists, ErrContainerNameConflict)
 	}
 	return id, nil
 }
@@ -147,10 +150,26 @@ func (o *xpcContainerOps) Stop(ctx context.Context, opts *StopContainer, contain
 	return containerID, nil
 }
 
+func (o *xpcContainerOps) Kill(ctx context.Context, opts *KillContainer, containerID string) (string, error) {
+	var name string
+	if opts != nil {
+		name = opts.Signal
+	}
+	sig, err := ParseSignal(name)
+	if err != nil {
+		return "", err
+	}
+	// The container's init process shares the container's ID.
+	if err := o.client.KillProcess(ctx, containerID, containerID, int64(sig)); err != nil {
+		return "", err
+	}
+	return containerID, nil
+}
+
 func (o *xpcContainerOps) Delete(ctx context.Context, opts *DeleteContainer, containerID string) (string, error) {
 	force := opts != nil && opts.Force
 	if err := o.client.DeleteContainer(ctx, containerID, force); err != nil {
-		return "", err
+		return "", xpcContainerError(err, xpc.XPCErrorCodeNotFound, ErrContainerNotFound)
 	}
 	return containerID, nil
 }
@@ -285,12 +304,26 @@ func (o *xpcContainerOps) ExecStream(ctx context.Context, opts *ExecContainer, c
 	}, nil
 }
 
-func (o *xpcContainerOps) Inspect(ctx context.Context, containerID string) ([]sandtypes.Container, error) {
-	ctr, err := o.client.GetContainer(ctx, containerID)
+func (o *xpcContainerOps) Inspect(ctx context.Context, containerID ...string) ([]sandtypes.Container, error) {
+	switch len(containerID) {
+	case 0:
+		return nil, nil
+	case 1:
+		ctr, err := o.client.GetContainer(ctx, containerID[0])
+		if err != nil {
+			return nil, xpcContainerError(err, xpc.XPCErrorCodeNotFound, ErrContainerNotFound)
+		}
+		return []sandtypes.Container{xpcSnapshotToContainer(ctr)}, nil
+	}
+	ctrs, err := o.client.ListContainers(ctx, xpc.ContainerListFilters{IDs: containerID})
 	if err != nil {
 		return nil, err
 	}
-	return []sandtypes.Container{xpcSnapshotToContainer(ctr)}, nil
+	ret := make([]sandtypes.Container, 0, len(ctrs))
+	for _, ctr := range ctrs {
+		ret = append(ret, xpcSnapshotToContainer(ctr))
+	}
+	return ret, nil
 }
 
 func (o *xpcContainerOps) Stats(ctx context.Context, containerID ...string) ([]sandtypes.ContainerStats, error) {
@@ -305,6 +338,10 @@ func (o *xpcContainerOps) Stats(ctx context.Context, containerID ...string) ([]s
 	return ret, nil
 }
 
+func (o *xpcContainerOps) Logs(ctx context.Context, opts *LogsContainer, containerID string) (string, error) {
+	return containerLogs(ctx, opts, containerID)
+}
+
 func (o *xpcContainerOps) Export(ctx context.Context, opts *ExportContainer, containerID string) (string, error) {
 	if opts == nil || opts.Output == "" {
 		return "", fmt.Errorf("export output path is required")
@@ -315,6 +352,26 @@ func (o *xpcContainerOps) Export(ctx context.Context, opts *ExportContainer, con
 	return "", nil
 }
 
+// xpcContainerError makes err match kind with errors.Is when it is an
+// XPCError with the given code, keeping the runtime's message.
+func xpcContainerError(err error, code string, kind error) error {
+	var xpcErr xpc.XPCError
+	if !errors.As(err, &xpcErr) || xpcErr.Code != code {
+		return err
+	}
+	return &containerError{kind: kind, err: err}
+}
+
+// containerError is an error from the container runtime that also matches
+// one of the ErrContainer sentinels.
+type containerError struct {
+	kind error
+	err  error
+}
+
+func (e *containerError) Error() string   { return e.err.Error() }
+func (e *containerError) Unwrap() []error { return []error{e.kind, e.err} }
+
 func (o *xpcContainerOps) imageDescription(ctx context.Context, imageName string, platform xpc.Platform) (xpc.ImageDescription, *sandtypes.ImageVariantContainerConfig, error) {
 	desc, err := o.imageOps.findImage(ctx, imageName)
 	if err != nil {
@@ -540,60 +597,6 @@ func parseVolumeFilesystem(spec string) (xpc.Filesystem, error) {
 	}, nil
 }
 
-func parsePublishPort(spec string) (xpc.PublishPort, error) {
-	proto := xpc.PublishProtocolTCP
-	if before, after, ok := strings.Cut(spec, "/"); ok {
-		spec = before
-		switch after {
-		case "", "tcp":
-			proto = xpc.PublishProtocolTCP
-		case "udp":
-			proto = xpc.PublishProtocolUDP
-		default:
-			return xpc.PublishPort{}, fmt.Errorf("invalid publish protocol %q", after)
-		}
-	}
-
-	parts := strings.Split(spec, ":")
-	var hostAddress string
-	var hostPortRaw string
-	var containerPortRaw string
-	switch len(parts) {
-	case 2:
-		hostPortRaw = parts[0]
-		containerPortRaw = parts[1]
-	case 3:
-		hostAddress = parts[0]
-		hostPortRaw = parts[1]
-		containerPortRaw = parts[2]
-	default:
-		return xpc.PublishPort{}, fmt.Errorf("invalid publish spec %q", spec)
-	}
-	hostPort, err := parseUint16(hostPortRaw, "host port")
-	if err != nil {
-		return xpc.PublishPort{}, err
-	}
-	containerPort, err := parseUint16(containerPortRaw, "container port")
-	if err != nil {
-		return xpc.PublishPort{}, err
-	}
-	return xpc.PublishPort{
-		HostAddress:   xpc.IPAddress(hostAddress),
-		HostPort:      hostPort,
-		ContainerPort: containerPort,
-		Proto:         proto,
-		Count:         1,
-	}, nil
-}
-
-func parseUint16(raw, name string) (uint16, error) {
-	value, err := strconv.ParseUint(raw, 10, 16)
-	if err != nil {
-		return 0, fmt.Errorf("invalid %s %q", name, raw)
-	}
-	return uint16(value), nil
-}
-
 func processFiles(stdin io.Reader, stdout, stderr io.Writer, tty bool) ([3]*os.File, func(), error) {
 	if tty && (isTerminalFile(stdin) || isTerminalFile(stdout) || isTerminalFile(stderr)) {
 		return [3]*os.File{}, func() {}, fmt.Errorf("terminal-backed XPC exec is unsupported; use SSH for interactive sessions")
@@ -872,11 +875,10 @@ func stringPtrOrNil(value string) *string {
 	return &value
 }
 
-func stringList(value string) []string {
-	if value == "" {
-		return []string{}
-	}
-	return []string{value}
+// stringList copies values, as an empty list rather than nil when there are
+// none, so that it encodes as [] instead of null.
+func stringList(values []string) []string {
+	return append([]string{}, values...)
 }
 
 func defaultInt(value, fallback int) int {
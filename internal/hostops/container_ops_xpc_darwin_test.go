@@ -29,48 +29,18 @@ func TestNullStdioUsesDevNull(t *testing.T) {
 	}
 }
 
-func TestParsePublishPort(t *testing.T) {
-	tests := []struct {
-		name          string
-		spec          string
-		hostAddress   xpc.IPAddress
-		hostPort      uint16
-		containerPort uint16
-		proto         xpc.PublishProtocol
+func TestDefaultNetworkAttachmentsUsesRequestedNetwork(t *testing.T) {
+	for _, tc := range []struct {
+		network string
+		want    string
 	}{
-		{
-			name:          "host and container port",
-			spec:          "3000:3000/tcp",
-			hostPort:      3000,
-			containerPort: 3000,
-			proto:         xpc.PublishProtocolTCP,
-		},
-		{
-			name:          "host address",
-			spec:          "127.0.0.1:3128:3128/tcp",
-			hostAddress:   "127.0.0.1",
-			hostPort:      3128,
-			containerPort: 3128,
-			proto:         xpc.PublishProtocolTCP,
-		},
-		{
-			name:          "udp",
-			spec:          "5353:5353/udp",
-			hostPort:      5353,
-			containerPort: 5353,
-			proto:         xpc.PublishProtocolUDP,
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got, err := parsePublishPort(tt.spec)
-			if err != nil {
-				t.Fatalf("parsePublishPort: %v", err)
-			}
-			if got.HostAddress != tt.hostAddress || got.HostPort != tt.hostPort || got.ContainerPort != tt.containerPort || got.Proto != tt.proto || got.Count != 1 {
-				t.Fatalf("parsePublishPort(%q) = %+v", tt.spec, got)
-			}
-		})
+		{network: "isolated", want: "isolated"},
+		{network: "", want: "default"},
+	} {
+		got := defaultNetworkAttachments("box", "dev.local", tc.network)
+		if len(got) != 1 || got[0].Network != tc.want || got[0].Options.Hostname != "box.dev.local." {
+			t.Errorf("defaultNetworkAttachments(%q) = %+v, want one attachment to %q", tc.network, got, tc.want)
+		}
 	}
 }
 
@@ -238,3 +208,20 @@ func TestCreateProcessConfigUsesImageEntrypointWithExplicitArgs(t *testing.T) {
 		t.Fatalf("Arguments = %#v, want %#v", cfg.Arguments, wantArgs)
 	}
 }
+
+func TestXPCContainerErrorMatchesTheRuntimesCode(t *testing.T) {
+	missing := xpc.XPCError{Code: xpc.XPCErrorCodeNotFound, Message: "container with ID dev not found"}
+	err := xpcContainerError(missing, xpc.XPCErrorCodeNotFound, ErrContainerNotFound)
+	if !IsContainerNotFound(err) || err.Error() != missing.Error() {
+		t.Errorf("xpcContainerError(%v) = %v, want it to match ErrContainerNotFound with the runtime's message", missing, err)
+	}
+	// A message that only mentions "not found" isn't a missing container.
+	other := xpc.XPCError{Code: "internalError", Message: "kernel not found"}
+	if err := xpcContainerError(other, xpc.XPCErrorCodeNotFound, ErrContainerNotFound); IsContainerNotFound(err) {
+		t.Errorf("xpcContainerError(%v) matches ErrContainerNotFound", other)
+	}
+	taken := xpc.XPCError{Code: xpc.XPCErrorCodeExists, Message: "container with ID dev already exists"}
+	if err := xpcContainerError(taken, xpc.XPCErrorCodeExists, ErrContainerNameConflict); !IsContainerNameConflict(err) {
+		t.Errorf("xpcContainerError(%v) = %v, want it to match ErrContainerNameConflict", taken, err)
+	}
+}
@@ -0,0 +1,124 @@
+package hostops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/banksean/sand/internal/sandtypes"
+)
+
+// RetryPolicy controls how NewRetryingContainerOps retries failed container operations.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first. Values
+	// less than 1 are treated as 1.
+	MaxAttempts int
+	// BaseDelay is the wait before the first retry. Each later retry waits twice
+	// as long as the one before it, up to MaxDelay.
+	BaseDelay time.Duration
+	// MaxDelay caps the wait between attempts. Zero means no cap.
+	MaxDelay time.Duration
+	// Retryable reports whether err is transient. Defaults to IsRetryableContainerError.
+	Retryable func(error) bool
+}
+
+// DefaultRetryPolicy is used for container operations created by NewAppleContainerOps.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	BaseDelay:   250 * time.Millisecond,
+	MaxDelay:    2 * time.Second,
+}
+
+// transientContainerErrors are substrings of errors from the container
+// runtime that indicate it is restarting or still warming up, rather than that
+// the request itself was bad.
+var transientContainerErrors = []string{
+	"connection interrupted",
+	"connection invalid",
+	"xpc connection is closed",
+	"system service is not running",
+	"temporarily unavailable",
+	"resource busy",
+}
+
+// IsRetryableContainerError reports whether err looks like a transient
+// failure of the container runtime that is worth retrying.
+func IsRetryableContainerError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, pattern := range transientContainerErrors {
+		if strings.Contains(msg, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// retryingContainerOps retries Create, Start, Stop, and Inspect on transient
+// errors. All other methods pass through to the wrapped ContainerOps.
+type retryingContainerOps struct {
+	ContainerOps
+	policy RetryPolicy
+}
+
+// NewRetryingContainerOps wraps ops so that Create, Start, Stop, and Inspect
+// are retried with exponential backoff when they fail with a transient error.
+func NewRetryingContainerOps(ops ContainerOps, policy RetryPolicy) ContainerOps {
+	if policy.Retryable == nil {
+		policy.Retryable = IsRetryableContainerError
+	}
+	return &retryingContainerOps{ContainerOps: ops, policy: policy}
+}
+
+func (r *retryingContainerOps) Create(ctx context.Context, opts *CreateContainer, image string, args []string) (string, error) {
+	return withRetry(ctx, r.policy, "Create", func() (string, error) {
+		return r.ContainerOps.Create(ctx, opts, image, args)
+	})
+}
+
+func (r *retryingContainerOps) Start(ctx context.Context, opts *StartContainer, containerID string) (string, error) {
+	return withRetry(ctx, r.policy, "Start", func() (string, error) {
+		return r.ContainerOps.Start(ctx, opts, containerID)
+	})
+}
+
+func (r *retryingContainerOps) Stop(ctx context.Context, opts *StopContainer, containerID string) (string, error) {
+	return withRetry(ctx, r.policy, "Stop", func() (string, error) {
+		return r.ContainerOps.Stop(ctx, opts, containerID)
+	})
+}
+
+func (r *retryingContainerOps) Inspect(ctx context.Context, containerID ...string) ([]sandtypes.Container, error) {
+	return withRetry(ctx, r.policy, "Inspect", func() ([]sandtypes.Container, error) {
+		return r.ContainerOps.Inspect(ctx, containerID...)
+	})
+}
+
+func withRetry[T any](ctx context.Context, policy RetryPolicy, op string, fn func() (T, error)) (T, error) {
+	attempts := max(policy.MaxAttempts, 1)
+	delay := policy.BaseDelay
+	for attempt := 1; ; attempt++ {
+		ret, err := fn()
+		if err == nil || attempt >= attempts || !policy.Retryable(err) {
+			if err != nil && attempt > 1 {
+				err = fmt.Errorf("%s failed after %d attempts: %w", op, attempt, err)
+			}
+			return ret, err
+		}
+		slog.WarnContext(ctx, "ContainerOps retrying after transient error", "op", op, "attempt", attempt, "delay", delay, "error", err)
+		select {
+		case <-ctx.Done():
+			return ret, fmt.Errorf("%s: %w (last error: %v)", op, ctx.Err(), err)
+		case <-time.After(delay):
+		}
+		delay *= 2
+		if policy.MaxDelay > 0 && delay > policy.MaxDelay {
+			delay = policy.MaxDelay
+		}
+	}
+}
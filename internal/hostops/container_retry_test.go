@@ -0,0 +1,123 @@
+package hostops
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/banksean/sand/internal/sandtypes"
+)
+
+var testRetryPolicy = RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}
+
+func TestRetryingContainerOpsRetriesTransientFailures(t *testing.T) {
+	calls := 0
+	ops := NewRetryingContainerOps(&MockContainerOps{
+		StartFunc: func(ctx context.Context, opts *StartContainer, containerID string) (string, error) {
+			calls++
+			if calls < 3 {
+				return "", errors.New("XPC connection error: Connection interrupted")
+			}
+			return containerID, nil
+		},
+	}, testRetryPolicy)
+
+	got, err := ops.Start(context.Background(), &StartContainer{}, "ctr")
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if got != "ctr" || calls != 3 {
+		t.Fatalf("Start() = %q after %d calls, want %q after 3", got, calls, "ctr")
+	}
+}
+
+func TestRetryingContainerOpsGivesUpOnPermanentFailures(t *testing.T) {
+	calls := 0
+	permanent := errors.New("image not found: nope:latest")
+	ops := NewRetryingContainerOps(&MockContainerOps{
+		CreateFunc: func(ctx context.Context, opts *CreateContainer, image string, args []string) (string, error) {
+			calls++
+			return "", permanent
+		},
+	}, testRetryPolicy)
+
+	if _, err := ops.Create(context.Background(), &CreateContainer{}, "nope:latest", nil); !errors.Is(err, permanent) {
+		t.Fatalf("Create() error = %v, want %v", err, permanent)
+	}
+	if calls != 1 {
+		t.Fatalf("Create() made %d calls, want 1", calls)
+	}
+}
+
+func TestRetryingContainerOpsStopsAfterMaxAttempts(t *testing.T) {
+	calls := 0
+	ops := NewRetryingContainerOps(&MockContainerOps{
+		InspectFunc: func(ctx context.Context, containerID string) ([]sandtypes.Container, error) {
+			calls++
+			return nil, errors.New("container system service is not running")
+		},
+	}, testRetryPolicy)
+
+	_, err := ops.Inspect(context.Background(), "ctr")
+	if err == nil || !strings.Contains(err.Error(), "after 3 attempts") {
+		t.Fatalf("Inspect() error = %v, want failure after 3 attempts", err)
+	}
+	if calls != 3 {
+		t.Fatalf("Inspect() made %d calls, want 3", calls)
+	}
+}
+
+func TestRetryingContainerOpsHonorsContextCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	ops := NewRetryingContainerOps(&MockContainerOps{
+		StopFunc: func(ctx context.Context, opts *StopContainer, containerID string) (string, error) {
+			calls++
+			cancel()
+			return "", errors.New("resource busy")
+		},
+	}, RetryPolicy{MaxAttempts: 5, BaseDelay: time.Hour})
+
+	if _, err := ops.Stop(ctx, &StopContainer{}, "ctr"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Stop() error = %v, want context.Canceled", err)
+	}
+	if calls != 1 {
+		t.Fatalf("Stop() made %d calls, want 1", calls)
+	}
+}
+
+func TestRetryingContainerOpsPassesThroughOtherMethods(t *testing.T) {
+	calls := 0
+	ops := NewRetryingContainerOps(&MockContainerOps{
+		DeleteFunc: func(ctx context.Context, opts *DeleteContainer, containerID string) (string, error) {
+			calls++
+			return "", errors.New("connection interrupted")
+		},
+	}, testRetryPolicy)
+
+	if _, err := ops.Delete(context.Background(), &DeleteContainer{}, "ctr"); err == nil {
+		t.Fatal("Delete() error = nil, want error")
+	}
+	if calls != 1 {
+		t.Fatalf("Delete() made %d calls, want 1 (not retried)", calls)
+	}
+}
+
+func TestIsRetryableContainerError(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{context.Canceled, false},
+		{errors.New("XPC connection error: Connection invalid"), true},
+		{errors.New("start container: Resource busy"), true},
+		{errors.New("container ctr not found"), false},
+	} {
+		if got := IsRetryableContainerError(tc.err); got != tc.want {
+			t.Errorf("IsRetryableContainerError(%v) = %v, want %v", tc.err, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package hostops
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// CopyProgress reports FileOps.Copy working through the top-level entries of
+// a directory. cp gives no progress of its own, so entries are the finest
+// granularity available, and each is reported when it starts and finishes.
+type CopyProgress struct {
+	// Entry is the name of the top-level entry, relative to the source.
+	Entry string
+	// Done is how many entries have been copied, including Entry if Finished.
+	Done int
+	// Total is the number of top-level entries in the source.
+	Total int
+	// Finished is false when Entry is about to be copied and true once it has been.
+	Finished bool
+	// Elapsed is how long Entry took to copy; it is only set once Finished.
+	Elapsed time.Duration
+}
+
+type copyProgressKey struct{}
+
+// WithCopyProgress returns a context that makes FileOps.Copy call report as it
+// copies a directory. Copies without one run as a single cp invocation.
+func WithCopyProgress(ctx context.Context, report func(CopyProgress)) context.Context {
+	return context.WithValue(ctx, copyProgressKey{}, report)
+}
+
+// CopyProgressReporter returns the reporter set by WithCopyProgress, or nil.
+// FileOps implementations call it to report their progress.
+func CopyProgressReporter(ctx context.Context) func(CopyProgress) {
+	report, _ := ctx.Value(copyProgressKey{}).(func(CopyProgress))
+	return report
+}
+
+// copyEntries copies directory src to dst, which must not exist, one top-level
+// entry at a time so that report can follow along. Like Copy, it clones where
+// it can and falls back to a full copy once cloning turns out to be unsupported.
+func copyEntries(ctx context.Context, src, dst string, info os.FileInfo, report func(CopyProgress)) (CopyResult, error) {
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return CopyResult{}, fmt.Errorf("copy failed: %w", err)
+	}
+	if err := os.Mkdir(dst, info.Mode().Perm()); err != nil {
+		return CopyResult{}, fmt.Errorf("copy failed: %w", err)
+	}
+	flags := "-Rc"
+	for i, entry := range entries {
+		name := entry.Name()
+		entrySrc, entryDst := filepath.Join(src, name), filepath.Join(dst, name)
+		report(CopyProgress{Entry: name, Done: i, Total: len(entries)})
+		start := time.Now()
+		output, err := runCopy(ctx, flags, entrySrc, entryDst)
+		if err != nil && flags == "-Rc" && cloneUnsupported(output) {
+			slog.WarnContext(ctx, "FileOps.Copy copy-on-write clone unavailable; falling back to a full copy", "src", src, "dst", dst, "output", string(output))
+			flags = "-R"
+			if err := os.RemoveAll(entryDst); err != nil {
+				return CopyResult{}, fmt.Errorf("remove partial copy %s: %w", entryDst, err)
+			}
+			output, err = runCopy(ctx, flags, entrySrc, entryDst)
+		}
+		if err != nil {
+			return CopyResult{}, fmt.Errorf("copy failed: %w (output: %s)", err, output)
+		}
+		report(CopyProgress{Entry: name, Done: i + 1, Total: len(entries), Finished: true, Elapsed: time.Since(start)})
+	}
+	return CopyResult{CopyOnWrite: flags == "-Rc"}, nil
+}
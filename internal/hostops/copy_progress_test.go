@@ -0,0 +1,83 @@
+package hostops
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestDefaultFileOpsCopyReportsProgressPerEntry(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "src")
+	for _, name := range []string{"a.txt", "b.txt", "sub/c.txt"} {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(src, name)), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(src, name), []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dst := filepath.Join(t.TempDir(), "dst")
+	calls := fakeCopyCommand(t, "cp: clonefile failed: Operation not supported")
+
+	var got []CopyProgress
+	ctx := WithCopyProgress(context.Background(), func(p CopyProgress) { got = append(got, p) })
+	result, err := (&defaultFileOps{}).Copy(ctx, src, dst, CopyOpts{})
+	if err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	if result.CopyOnWrite {
+		t.Error("Copy() CopyOnWrite = true, want false after fallback")
+	}
+
+	var events []string
+	for _, p := range got {
+		state := "start"
+		if p.Finished {
+			state = "done"
+		}
+		if p.Total != 3 {
+			t.Errorf("progress %+v, want Total 3", p)
+		}
+		events = append(events, state+" "+p.Entry)
+	}
+	wantEvents := []string{"start a.txt", "done a.txt", "start b.txt", "done b.txt", "start sub", "done sub"}
+	if !slices.Equal(events, wantEvents) {
+		t.Fatalf("progress events = %v, want %v", events, wantEvents)
+	}
+	if last := got[len(got)-1]; last.Done != 3 {
+		t.Errorf("last progress Done = %d, want 3", last.Done)
+	}
+
+	// Once cloning fails, the rest of the entries go straight to a full copy.
+	wantCalls := [][]string{
+		{"cp", "-Rc", filepath.Join(src, "a.txt"), filepath.Join(dst, "a.txt")},
+		{"cp", "-R", filepath.Join(src, "a.txt"), filepath.Join(dst, "a.txt")},
+		{"cp", "-R", filepath.Join(src, "b.txt"), filepath.Join(dst, "b.txt")},
+		{"cp", "-R", filepath.Join(src, "sub"), filepath.Join(dst, "sub")},
+	}
+	if !slices.EqualFunc(*calls, wantCalls, slices.Equal[[]string]) {
+		t.Fatalf("cp calls = %v, want %v", *calls, wantCalls)
+	}
+	if data, err := os.ReadFile(filepath.Join(dst, "sub", "c.txt")); err != nil || string(data) != "sub/c.txt" {
+		t.Fatalf("copied file = %q, %v", data, err)
+	}
+}
+
+func TestDefaultFileOpsCopyWithProgressIntoExistingDirUsesSingleCopy(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "src")
+	if err := os.MkdirAll(src, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	dst := t.TempDir()
+	calls := fakeCopyCommand(t, "cp: Permission denied")
+
+	reported := false
+	ctx := WithCopyProgress(context.Background(), func(CopyProgress) { reported = true })
+	_, _ = (&defaultFileOps{}).Copy(ctx, src, dst, CopyOpts{})
+	// cp into an existing dir nests src inside it, which per-entry copies can't mimic.
+	if reported || len(*calls) != 1 {
+		t.Fatalf("reported = %v, cp calls = %v; want one cp and no progress", reported, *calls)
+	}
+}
@@ -0,0 +1,168 @@
+package hostops
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SymlinkPolicy controls what FileOps.Copy does with the symlinks it finds
+// in the tree it copies.
+type SymlinkPolicy int
+
+const (
+	// SymlinksPreserve copies symlinks as symlinks, whatever they point to.
+	SymlinksPreserve SymlinkPolicy = iota
+	// SymlinksDereference replaces each symlink with a copy of its target.
+	// Dangling symlinks are skipped.
+	SymlinksDereference
+	// SymlinksSkipDangling copies symlinks as symlinks, leaving out those
+	// whose target doesn't exist.
+	SymlinksSkipDangling
+	// SymlinksWithinSource keeps symlinks whose target is inside the copied
+	// tree as symlinks, rewriting absolute ones to relative so they resolve
+	// wherever the copy ends up, and skips the rest, which would point at
+	// paths that may not exist where the copy is used.
+	SymlinksWithinSource
+)
+
+// applySymlinkPolicy rewrites the symlinks cp copied from src into root, the
+// top of the copy, as policy asks. Targets are resolved from src, where
+// relative links still point where they did. Only links cp copied are
+// considered, not ones inside targets this dereferences.
+func applySymlinkPolicy(ctx context.Context, src, root string, policy SymlinkPolicy, result *CopyResult) error {
+	if policy == SymlinksPreserve {
+		return nil
+	}
+	var links []string
+	if err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			links = append(links, path)
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("find copied symlinks in %s: %w", root, err)
+	}
+	realSrc, err := filepath.EvalSymlinks(src)
+	if err != nil {
+		realSrc = src
+	}
+
+	if policy == SymlinksWithinSource {
+		return keepLinksWithinSource(ctx, src, realSrc, root, links, result)
+	}
+
+	for _, link := range links {
+		rel, err := filepath.Rel(root, link)
+		if err != nil {
+			return err
+		}
+		srcLink := filepath.Join(src, rel)
+		target, err := filepath.EvalSymlinks(srcLink)
+		dereference := err == nil && policy != SymlinksSkipDangling
+		switch {
+		case err != nil:
+			slog.WarnContext(ctx, "FileOps.Copy skipping dangling symlink", "link", srcLink, "error", err)
+		case policy == SymlinksSkipDangling:
+			continue
+		}
+		if err := os.Remove(link); err != nil {
+			return fmt.Errorf("remove copied symlink %s: %w", link, err)
+		}
+		if !dereference {
+			result.SkippedSymlinks = append(result.SkippedSymlinks, srcLink)
+			continue
+		}
+		if err := copyTarget(ctx, target, link, result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// keepLinksWithinSource applies SymlinksWithinSource to the links in root,
+// copied from src (realSrc with its own symlinks resolved). Only a link's own
+// target is looked at, so links kept as they were are exactly what git, say,
+// has recorded for them.
+func keepLinksWithinSource(ctx context.Context, src, realSrc, root string, links []string, result *CopyResult) error {
+	for _, link := range links {
+		rel, err := filepath.Rel(root, link)
+		if err != nil {
+			return err
+		}
+		srcLink := filepath.Join(src, rel)
+		target, err := os.Readlink(link)
+		if err != nil {
+			return fmt.Errorf("read copied symlink %s: %w", link, err)
+		}
+		// The target's path from the top of the tree, if it is in it.
+		inTree, ok := "", false
+		if filepath.IsAbs(target) {
+			for _, top := range []string{src, realSrc} {
+				if within(target, top) {
+					inTree, _ = filepath.Rel(top, target)
+					ok = true
+					break
+				}
+			}
+		} else {
+			inTree = filepath.Join(filepath.Dir(rel), target)
+			ok = within(inTree, ".")
+		}
+
+		switch {
+		case !ok:
+			slog.WarnContext(ctx, "FileOps.Copy skipping symlink that points outside the copy", "link", srcLink, "target", target)
+			if err := os.Remove(link); err != nil {
+				return fmt.Errorf("remove copied symlink %s: %w", link, err)
+			}
+			result.SkippedSymlinks = append(result.SkippedSymlinks, srcLink)
+		case filepath.IsAbs(target):
+			relTarget, err := filepath.Rel(filepath.Dir(rel), inTree)
+			if err != nil {
+				return err
+			}
+			if err := os.Remove(link); err != nil {
+				return fmt.Errorf("remove copied symlink %s: %w", link, err)
+			}
+			if err := os.Symlink(relTarget, link); err != nil {
+				return fmt.Errorf("relink copied symlink %s: %w", link, err)
+			}
+		}
+	}
+	return nil
+}
+
+// copyTarget copies a symlink's resolved target to dst, cloning if the rest of
+// the copy was cloned.
+func copyTarget(ctx context.Context, target, dst string, result *CopyResult) error {
+	if result.CopyOnWrite {
+		output, err := runCopy(ctx, "-Rc", target, dst)
+		if err == nil {
+			return nil
+		}
+		if !cloneUnsupported(output) {
+			return fmt.Errorf("copy symlink target %s: %w (output: %s)", target, err, output)
+		}
+		result.CopyOnWrite = false
+		if err := os.RemoveAll(dst); err != nil {
+			return fmt.Errorf("remove partial copy %s: %w", dst, err)
+		}
+	}
+	if output, err := runCopy(ctx, "-R", target, dst); err != nil {
+		return fmt.Errorf("copy symlink target %s: %w (output: %s)", target, err, output)
+	}
+	return nil
+}
+
+func within(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
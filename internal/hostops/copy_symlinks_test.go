@@ -0,0 +1,211 @@
+package hostops
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// symlinkTree makes a source directory holding a file, a directory, and links
+// to each of them, to a file outside the source, and to nothing.
+func symlinkTree(t *testing.T) string {
+	t.Helper()
+	outside := filepath.Join(t.TempDir(), "outside.txt")
+	if err := os.WriteFile(outside, []byte("outside"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	src := filepath.Join(t.TempDir(), "src")
+	if err := os.MkdirAll(filepath.Join(src, "dir"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range map[string]string{"real.txt": "real", "dir/nested.txt": "nested"} {
+		if err := os.WriteFile(filepath.Join(src, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, target := range map[string]string{
+		"internal":     "real.txt",
+		"abs-internal": filepath.Join(src, "real.txt"),
+		"dirlink":      "dir",
+		"external":     outside,
+		"dangling":     "missing.txt",
+	} {
+		if err := os.Symlink(target, filepath.Join(src, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return src
+}
+
+func copyWithPolicy(t *testing.T, policy SymlinkPolicy) (string, string, CopyResult) {
+	t.Helper()
+	src := symlinkTree(t)
+	dst := filepath.Join(t.TempDir(), "dst")
+	fakeCopyCommand(t, "cp: clonefile failed: Operation not supported")
+	result, err := (&defaultFileOps{}).Copy(context.Background(), src, dst, CopyOpts{Symlinks: policy})
+	if err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	return src, dst, result
+}
+
+func assertSymlink(t *testing.T, path string) {
+	t.Helper()
+	if fi, err := os.Lstat(path); err != nil || fi.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("%s: want a symlink, got %v, %v", path, fi, err)
+	}
+}
+
+func assertLink(t *testing.T, path, want string) {
+	t.Helper()
+	if target, err := os.Readlink(path); err != nil || target != want {
+		t.Errorf("%s links to %q, %v; want %q", path, target, err, want)
+	}
+}
+
+func assertContents(t *testing.T, path, want string) {
+	t.Helper()
+	fi, err := os.Lstat(path)
+	if err != nil || !fi.Mode().IsRegular() {
+		t.Errorf("%s: want a regular file, got %v, %v", path, fi, err)
+		return
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != want {
+		t.Errorf("%s = %q, %v; want %q", path, data, err, want)
+	}
+}
+
+func assertMissing(t *testing.T, path string) {
+	t.Helper()
+	if _, err := os.Lstat(path); !os.IsNotExist(err) {
+		t.Errorf("%s: want it left out of the copy, got %v", path, err)
+	}
+}
+
+func TestDefaultFileOpsCopyPreservesSymlinksByDefault(t *testing.T) {
+	src := symlinkTree(t)
+	dst := filepath.Join(t.TempDir(), "dst")
+	fakeCopyCommand(t, "cp: clonefile failed: Operation not supported")
+	result, err := (&defaultFileOps{}).Copy(context.Background(), src, dst, CopyOpts{})
+	if err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	for _, name := range []string{"internal", "abs-internal", "dirlink", "external", "dangling"} {
+		assertSymlink(t, filepath.Join(dst, name))
+	}
+	if len(result.SkippedSymlinks) != 0 {
+		t.Fatalf("SkippedSymlinks = %v, want none", result.SkippedSymlinks)
+	}
+}
+
+func TestDefaultFileOpsCopySymlinksWithinSource(t *testing.T) {
+	src, dst, result := copyWithPolicy(t, SymlinksWithinSource)
+	assertLink(t, filepath.Join(dst, "internal"), "real.txt")
+	assertLink(t, filepath.Join(dst, "abs-internal"), "real.txt")
+	assertLink(t, filepath.Join(dst, "dirlink"), "dir")
+	if data, err := os.ReadFile(filepath.Join(dst, "abs-internal")); err != nil || string(data) != "real" {
+		t.Errorf("abs-internal in the copy reads %q, %v; want the copy's real.txt", data, err)
+	}
+	assertContents(t, filepath.Join(dst, "dirlink", "nested.txt"), "nested")
+	// A link within the tree is kept whether or not its target exists, as
+	// git would have it.
+	assertLink(t, filepath.Join(dst, "dangling"), "missing.txt")
+	assertMissing(t, filepath.Join(dst, "external"))
+	if want := []string{filepath.Join(src, "external")}; !slices.Equal(result.SkippedSymlinks, want) {
+		t.Fatalf("SkippedSymlinks = %v, want %v", result.SkippedSymlinks, want)
+	}
+	// The source keeps its links.
+	assertSymlink(t, filepath.Join(src, "external"))
+}
+
+func TestDefaultFileOpsCopySymlinksWithinSourceRelativizesNestedLinks(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "src")
+	for name, target := range map[string]string{
+		"a/b/up":      filepath.Join(src, "top.txt"),
+		"a/b/escape":  "../../../outside.txt",
+		"a/b/sibling": "../c.txt",
+	} {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(src, name)), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(target, filepath.Join(src, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dst := filepath.Join(t.TempDir(), "dst")
+	fakeCopyCommand(t, "cp: clonefile failed: Operation not supported")
+	result, err := (&defaultFileOps{}).Copy(context.Background(), src, dst, CopyOpts{Symlinks: SymlinksWithinSource})
+	if err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	assertLink(t, filepath.Join(dst, "a", "b", "up"), filepath.Join("..", "..", "top.txt"))
+	assertLink(t, filepath.Join(dst, "a", "b", "sibling"), "../c.txt")
+	assertMissing(t, filepath.Join(dst, "a", "b", "escape"))
+	if want := []string{filepath.Join(src, "a", "b", "escape")}; !slices.Equal(result.SkippedSymlinks, want) {
+		t.Fatalf("SkippedSymlinks = %v, want %v", result.SkippedSymlinks, want)
+	}
+}
+
+func TestDefaultFileOpsCopySymlinksWithinSourceLeavesGitStatusClean(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "src")
+	if err := os.MkdirAll(filepath.Join(src, "docs"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "README.md"), []byte("readme\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("../README.md", filepath.Join(src, "docs", "README.md")); err != nil {
+		t.Fatal(err)
+	}
+	runTestGit(t, src, "init", "-q")
+	runTestGit(t, src, "add", ".")
+	runTestGit(t, src, "commit", "-q", "-m", "tracked symlink")
+
+	dst := filepath.Join(t.TempDir(), "dst")
+	fakeCopyCommand(t, "cp: clonefile failed: Operation not supported")
+	if _, err := (&defaultFileOps{}).Copy(context.Background(), src, dst, CopyOpts{Symlinks: SymlinksWithinSource}); err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	status := exec.Command("git", "status", "--porcelain")
+	status.Dir = dst
+	status.Env = gitEnvWithoutRepoOverrides(os.Environ())
+	if out, err := status.CombinedOutput(); err != nil || len(out) != 0 {
+		t.Fatalf("git status in the copy = %q, %v; want it clean", out, err)
+	}
+}
+
+func TestDefaultFileOpsCopySymlinksDereference(t *testing.T) {
+	src, dst, result := copyWithPolicy(t, SymlinksDereference)
+	assertContents(t, filepath.Join(dst, "internal"), "real")
+	assertContents(t, filepath.Join(dst, "external"), "outside")
+	assertContents(t, filepath.Join(dst, "dirlink", "nested.txt"), "nested")
+	assertMissing(t, filepath.Join(dst, "dangling"))
+	if want := []string{filepath.Join(src, "dangling")}; !slices.Equal(result.SkippedSymlinks, want) {
+		t.Fatalf("SkippedSymlinks = %v, want %v", result.SkippedSymlinks, want)
+	}
+}
+
+func TestDefaultFileOpsCopySymlinksSkipDangling(t *testing.T) {
+	src, dst, result := copyWithPolicy(t, SymlinksSkipDangling)
+	for _, name := range []string{"internal", "abs-internal", "dirlink", "external"} {
+		assertSymlink(t, filepath.Join(dst, name))
+	}
+	assertMissing(t, filepath.Join(dst, "dangling"))
+	if want := []string{filepath.Join(src, "dangling")}; !slices.Equal(result.SkippedSymlinks, want) {
+		t.Fatalf("SkippedSymlinks = %v, want %v", result.SkippedSymlinks, want)
+	}
+}
+
+func TestDefaultFileOpsCopySymlinkPolicyIntoExistingDir(t *testing.T) {
+	src := symlinkTree(t)
+	dst := t.TempDir()
+	fakeCopyCommand(t, "cp: clonefile failed: Operation not supported")
+	if _, err := (&defaultFileOps{}).Copy(context.Background(), src, dst, CopyOpts{Symlinks: SymlinksWithinSource}); err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	assertLink(t, filepath.Join(dst, "src", "internal"), "real.txt")
+	assertMissing(t, filepath.Join(dst, "src", "external"))
+}
@@ -0,0 +1,73 @@
+package hostops
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"syscall"
+)
+
+// DiskUsage is how much space a directory tree takes up.
+type DiskUsage struct {
+	// Logical is the sum of the sizes of the tree's entries, what ls and
+	// du --apparent-size report.
+	Logical uint64
+	// Allocated is the space the filesystem reports allocated to the tree's
+	// entries, counting each hard-linked inode once. APFS reports a
+	// copy-on-write clone's blocks as allocated to every copy although they
+	// are stored once, so for a cloned tree this is an upper bound on what
+	// removing it would free.
+	Allocated uint64
+	// Files is the number of entries measured, not counting hard links to an
+	// inode already measured.
+	Files int
+}
+
+// Add returns the sum of u and v.
+func (u DiskUsage) Add(v DiskUsage) DiskUsage {
+	return DiskUsage{
+		Logical:   u.Logical + v.Logical,
+		Allocated: u.Allocated + v.Allocated,
+		Files:     u.Files + v.Files,
+	}
+}
+
+type inodeID struct {
+	dev, ino uint64
+}
+
+// MeasureDiskUsage walks the tree at root, without following symlinks, and
+// returns the space it takes up.
+func MeasureDiskUsage(root string) (DiskUsage, error) {
+	var usage DiskUsage
+	seen := map[inodeID]bool{}
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+		allocated := uint64(0)
+		if st, ok := fi.Sys().(*syscall.Stat_t); ok {
+			if st.Nlink > 1 && !fi.IsDir() {
+				id := inodeID{dev: uint64(st.Dev), ino: uint64(st.Ino)}
+				if seen[id] {
+					return nil
+				}
+				seen[id] = true
+			}
+			// st_blocks counts 512-byte units whatever the filesystem's block size.
+			allocated = uint64(st.Blocks) * 512
+		}
+		usage.Logical += uint64(fi.Size())
+		usage.Allocated += allocated
+		usage.Files++
+		return nil
+	})
+	if err != nil {
+		return DiskUsage{}, fmt.Errorf("measure disk usage of %s: %w", root, err)
+	}
+	return usage, nil
+}
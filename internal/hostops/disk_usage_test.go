@@ -0,0 +1,123 @@
+package hostops
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// diskUsageTree makes a directory holding a 4 KiB file and a subdirectory
+// with a 1 KiB file.
+func diskUsageTree(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "sub"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	for name, size := range map[string]int{"four.bin": 4096, "sub/one.bin": 1024} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(strings.Repeat("x", size)), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+// dirSizes returns the logical size of dirs, which varies by filesystem, so
+// tests can subtract it and compare against the files they wrote.
+func dirSizes(t *testing.T, dirs ...string) uint64 {
+	t.Helper()
+	var total uint64
+	for _, dir := range dirs {
+		fi, err := os.Lstat(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		total += uint64(fi.Size())
+	}
+	return total
+}
+
+func TestMeasureDiskUsageCountsEveryEntry(t *testing.T) {
+	root := diskUsageTree(t)
+	usage, err := MeasureDiskUsage(root)
+	if err != nil {
+		t.Fatalf("MeasureDiskUsage() error = %v", err)
+	}
+	if want := 4096 + 1024 + dirSizes(t, root, filepath.Join(root, "sub")); usage.Logical != want {
+		t.Errorf("Logical = %d, want %d", usage.Logical, want)
+	}
+	if usage.Files != 4 {
+		t.Errorf("Files = %d, want 4", usage.Files)
+	}
+	if usage.Allocated < 4096+1024 {
+		t.Errorf("Allocated = %d, want at least the files' 5 KiB", usage.Allocated)
+	}
+}
+
+func TestMeasureDiskUsageCountsHardLinksOnce(t *testing.T) {
+	root := diskUsageTree(t)
+	before, err := MeasureDiskUsage(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Link(filepath.Join(root, "four.bin"), filepath.Join(root, "sub", "four-again.bin")); err != nil {
+		t.Fatal(err)
+	}
+
+	after, err := MeasureDiskUsage(root)
+	if err != nil {
+		t.Fatalf("MeasureDiskUsage() error = %v", err)
+	}
+	// Directory sizes may grow with the new entry; the files must not.
+	fileBytes := after.Logical - dirSizes(t, root, filepath.Join(root, "sub"))
+	if after.Files != before.Files || fileBytes != 4096+1024 {
+		t.Fatalf("after linking = %+v (%d bytes of files), before = %+v; want the linked file counted once", after, fileBytes, before)
+	}
+}
+
+func TestMeasureDiskUsageReportsSparseFilesAsLogicalOnly(t *testing.T) {
+	root := t.TempDir()
+	f, err := os.Create(filepath.Join(root, "sparse.img"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Truncate(64 << 20); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	usage, err := MeasureDiskUsage(root)
+	if err != nil {
+		t.Fatalf("MeasureDiskUsage() error = %v", err)
+	}
+	if usage.Logical < 64<<20 {
+		t.Fatalf("Logical = %d, want at least the file's 64 MiB", usage.Logical)
+	}
+	if usage.Allocated >= usage.Logical {
+		t.Fatalf("Allocated = %d, want less than Logical %d for a sparse file", usage.Allocated, usage.Logical)
+	}
+}
+
+func TestMeasureDiskUsageDoesNotFollowSymlinks(t *testing.T) {
+	outside := diskUsageTree(t)
+	root := t.TempDir()
+	target := filepath.Join(outside, "four.bin")
+	if err := os.Symlink(target, filepath.Join(root, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	usage, err := MeasureDiskUsage(root)
+	if err != nil {
+		t.Fatalf("MeasureDiskUsage() error = %v", err)
+	}
+	if got := usage.Logical - dirSizes(t, root); got != uint64(len(target)) {
+		t.Fatalf("link measured as %d bytes, want the link itself (%d bytes), not its 4 KiB target", got, len(target))
+	}
+}
+
+func TestMeasureDiskUsageMissingRoot(t *testing.T) {
+	if _, err := MeasureDiskUsage(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Fatal("MeasureDiskUsage() error = nil, want an error for a missing tree")
+	}
+}
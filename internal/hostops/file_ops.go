@@ -6,12 +6,16 @@ import (
 	"log/slog"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 )
 
 type FileOps interface {
 	MkdirAll(path string, perm os.FileMode) error
-	Copy(ctx context.Context, src, dst string) error
+	// Mkdir creates the directory path, failing with an fs.ErrExist error if
+	// it is already there.
+	Mkdir(path string, perm os.FileMode) error
+	Copy(ctx context.Context, src, dst string, opts CopyOpts) (CopyResult, error)
 	Stat(path string) (os.FileInfo, error)
 	Lstat(path string) (os.FileInfo, error)
 	Readlink(path string) (string, error)
@@ -28,15 +32,112 @@ func (f *defaultFileOps) MkdirAll(path string, perm os.FileMode) error {
 	return os.MkdirAll(path, perm)
 }
 
-func (f *defaultFileOps) Copy(ctx context.Context, src, dst string) error {
-	cmd := exec.CommandContext(ctx, "cp", "-Rc", src, dst)
+func (f *defaultFileOps) Mkdir(path string, perm os.FileMode) error {
+	return os.Mkdir(path, perm)
+}
+
+// CopyOpts controls FileOps.Copy.
+type CopyOpts struct {
+	// Symlinks says what to do with the symlinks in the copied tree.
+	Symlinks SymlinkPolicy
+}
+
+// CopyResult describes how FileOps.Copy copied src to dst.
+type CopyResult struct {
+	// CopyOnWrite is true if dst was cloned with clonefile(2) and shares
+	// storage with src. It is false when Copy had to fall back to a full copy.
+	CopyOnWrite bool
+	// SkippedSymlinks lists the source paths of symlinks that the copy's
+	// SymlinkPolicy left out.
+	SkippedSymlinks []string
+}
+
+// copyCommand builds the cp invocations for defaultFileOps.Copy; tests replace it.
+var copyCommand = exec.CommandContext
+
+// Copy recursively copies src to dst, cloning with clonefile(2) where the
+// filesystem supports it. If the clone fails because the destination is not on
+// APFS or is on a different volume than src, Copy falls back to a plain
+// recursive copy and reports CopyOnWrite=false.
+//
+// If ctx carries a reporter from WithCopyProgress and src is a directory
+// being copied to a new dst, the copy is made one top-level entry at a time.
+// Symlinks are handled according to opts.Symlinks.
+func (f *defaultFileOps) Copy(ctx context.Context, src, dst string, opts CopyOpts) (CopyResult, error) {
+	dstInfo, statErr := os.Lstat(dst)
+	dstExisted := statErr == nil
+	// cp copies into an existing directory rather than over it.
+	root := dst
+	if dstExisted && dstInfo.IsDir() {
+		root = filepath.Join(dst, filepath.Base(src))
+	}
+
+	result, err := copyTree(ctx, src, dst, dstExisted)
+	if err != nil {
+		return CopyResult{}, err
+	}
+	if err := applySymlinkPolicy(ctx, src, root, opts.Symlinks, &result); err != nil {
+		return CopyResult{}, err
+	}
+	return result, nil
+}
+
+func copyTree(ctx context.Context, src, dst string, dstExisted bool) (CopyResult, error) {
+	if report := CopyProgressReporter(ctx); report != nil && !dstExisted {
+		if info, err := os.Lstat(src); err == nil && info.IsDir() {
+			return copyEntries(ctx, src, dst, info, report)
+		}
+	}
+
+	output, err := runCopy(ctx, "-Rc", src, dst)
+	if err == nil {
+		return CopyResult{CopyOnWrite: true}, nil
+	}
+	if !cloneUnsupported(output) {
+		return CopyResult{}, fmt.Errorf("copy failed: %w (output: %s)", err, output)
+	}
+
+	slog.WarnContext(ctx, "FileOps.Copy copy-on-write clone unavailable; falling back to a full copy", "src", src, "dst", dst, "output", string(output))
+	if !dstExisted {
+		// Discard whatever the failed clone left behind so cp -R recreates dst
+		// rather than copying src into a subdirectory of it.
+		if err := os.RemoveAll(dst); err != nil {
+			return CopyResult{}, fmt.Errorf("remove partial copy %s: %w", dst, err)
+		}
+	}
+	output, err = runCopy(ctx, "-R", src, dst)
+	if err != nil {
+		return CopyResult{}, fmt.Errorf("copy failed: %w (output: %s)", err, output)
+	}
+	return CopyResult{}, nil
+}
+
+func runCopy(ctx context.Context, flags, src, dst string) ([]byte, error) {
+	cmd := copyCommand(ctx, "cp", flags, src, dst)
 	slog.InfoContext(ctx, "FileOps.Copy", "cmd", strings.Join(cmd.Args, " "))
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		slog.InfoContext(ctx, "FileOps.Copy", "error", err, "output", string(output))
-		return fmt.Errorf("copy failed: %w (output: %s)", err, output)
 	}
-	return nil
+	return output, err
+}
+
+// cloneUnsupported reports whether cp's output indicates that -c failed
+// because clonefile(2) can't be used for this source and destination.
+func cloneUnsupported(output []byte) bool {
+	out := strings.ToLower(string(output))
+	for _, marker := range []string{
+		"clonefile",
+		"operation not supported",
+		"cross-device link",
+		"illegal option",
+		"invalid option",
+	} {
+		if strings.Contains(out, marker) {
+			return true
+		}
+	}
+	return false
 }
 
 type VolumeInfo struct {
@@ -0,0 +1,68 @@
+package hostops
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// fakeCopyCommand replaces copyCommand for the duration of a test. cp -Rc
+// fails with cloneOutput; any other invocation runs the real command.
+func fakeCopyCommand(t *testing.T, cloneOutput string) *[][]string {
+	t.Helper()
+	var calls [][]string
+	old := copyCommand
+	copyCommand = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		calls = append(calls, append([]string{name}, args...))
+		if len(args) > 0 && args[0] == "-Rc" {
+			return exec.CommandContext(ctx, "sh", "-c", `printf '%s\n' "$1" >&2; exit 1`, "sh", cloneOutput)
+		}
+		return exec.CommandContext(ctx, name, args...)
+	}
+	t.Cleanup(func() { copyCommand = old })
+	return &calls
+}
+
+func TestDefaultFileOpsCopyFallsBackWhenCloneUnsupported(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "src")
+	if err := os.MkdirAll(filepath.Join(src, "sub"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "sub", "file.txt"), []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	dst := filepath.Join(t.TempDir(), "dst")
+	calls := fakeCopyCommand(t, "cp: "+src+": clonefile failed: Operation not supported")
+
+	result, err := (&defaultFileOps{}).Copy(context.Background(), src, dst, CopyOpts{})
+	if err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	if result.CopyOnWrite {
+		t.Fatal("Copy() CopyOnWrite = true, want false after fallback")
+	}
+	want := [][]string{{"cp", "-Rc", src, dst}, {"cp", "-R", src, dst}}
+	if !slices.EqualFunc(*calls, want, slices.Equal[[]string]) {
+		t.Fatalf("cp calls = %v, want %v", *calls, want)
+	}
+	data, err := os.ReadFile(filepath.Join(dst, "sub", "file.txt"))
+	if err != nil || string(data) != "hello" {
+		t.Fatalf("copied file = %q, %v; want %q", data, err, "hello")
+	}
+}
+
+func TestDefaultFileOpsCopyDoesNotFallBackOnOtherErrors(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "src")
+	dst := filepath.Join(t.TempDir(), "dst")
+	calls := fakeCopyCommand(t, "cp: "+src+": Permission denied")
+
+	if _, err := (&defaultFileOps{}).Copy(context.Background(), src, dst, CopyOpts{}); err == nil {
+		t.Fatal("Copy() error = nil, want error")
+	}
+	if len(*calls) != 1 {
+		t.Fatalf("cp calls = %v, want only the cp -Rc attempt", *calls)
+	}
+}
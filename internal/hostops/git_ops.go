@@ -1,10 +1,15 @@
 package hostops
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strconv"
 	"strings"
 )
@@ -18,11 +23,21 @@ type GitOps interface {
 	CloneMirror(ctx context.Context, sourceDir, mirrorDir string) error
 	UpdateMirror(ctx context.Context, mirrorDir string) error
 	UpdateRef(ctx context.Context, dir, ref, value string) error
+	// TopLevel returns the root of the working tree containing dir, or "" if dir
+	// is not inside a git working tree. For a linked worktree this is the
+	// worktree's own directory, not the main checkout. For a submodule it is the
+	// submodule's directory, so sandboxing from inside a submodule clones just
+	// that submodule rather than its superproject.
 	TopLevel(ctx context.Context, dir string) string
 	// RemoteURL returns the URL of the named remote (e.g. "origin"), or "" if not found.
 	RemoteURL(ctx context.Context, dir, name string) string
+	// Remotes returns the URL of each of dir's remotes, by remote name.
+	Remotes(ctx context.Context, dir string) (map[string]string, error)
 	// LocalBranchExists reports whether refs/heads/branch exists in dir.
 	LocalBranchExists(ctx context.Context, dir, branch string) bool
+	// CheckoutClean checks out branch in dir, discarding uncommitted changes to
+	// tracked files and removing untracked ones. Ignored files are left alone.
+	CheckoutClean(ctx context.Context, dir, branch string) error
 	// SetBranchUpstream configures branch to pull from remote/branch without validating that the upstream exists yet.
 	SetBranchUpstream(ctx context.Context, dir, branch, remote string) error
 	// Branch returns the current branch name, or "" if detached/unavailable.
@@ -33,6 +48,22 @@ type GitOps interface {
 	IsDirty(ctx context.Context, dir string) bool
 	// CommitDivergence returns head's ahead/behind counts relative to base.
 	CommitDivergence(ctx context.Context, dir, base, head string) (ahead, behind int, ok bool)
+
+	// The methods below read repositories a sandbox can write to, so they run
+	// git through HardenedGitCommand.
+
+	// FetchRefs fetches refspecs from the repository at source into the
+	// repository at gitDir, pruning refs source no longer has. The fetch's
+	// output also goes to output, if it is set.
+	FetchRefs(ctx context.Context, gitDir, source string, refspecs []string, output io.Writer) error
+	// Status writes git status of the working tree at dir.
+	Status(ctx context.Context, dir string, stdout, stderr io.Writer) error
+	// Log writes git log of ref in the repository at gitDir.
+	Log(ctx context.Context, gitDir, ref string, stdout, stderr io.Writer) error
+	// Diff writes git diff --no-index of the trees a and b in dir, each
+	// labeled with its own name, passing args ahead of them. It reports
+	// whether the trees differ.
+	Diff(ctx context.Context, dir, a, b string, args []string, stdout, stderr io.Writer) (bool, error)
 }
 
 type defaultGitOps struct{}
@@ -139,14 +170,38 @@ func (g *defaultGitOps) UpdateRef(ctx context.Context, dir, ref, value string) e
 func (g *defaultGitOps) TopLevel(ctx context.Context, dir string) string {
 	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--show-toplevel")
 	cmd.Dir = dir
+	cmd.Env = gitEnvWithoutRepoOverrides(os.Environ())
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
 	slog.InfoContext(ctx, "GitOps.TopLevel", "cmd", strings.Join(cmd.Args, " "), "dir", dir)
-	output, err := cmd.CombinedOutput()
+	output, err := cmd.Output()
 	if err != nil {
-		slog.InfoContext(ctx, "GitOps.TopLevel", "error", err, "output", string(output))
+		slog.InfoContext(ctx, "GitOps.TopLevel", "error", err, "output", stderr.String())
 		return ""
 	}
 
-	return strings.TrimSpace(string(output))
+	// rev-parse succeeds with empty output when dir is inside a .git directory
+	// or a bare repository, neither of which has a working tree to clone.
+	topLevel := strings.TrimSpace(string(output))
+	if topLevel == "" {
+		return ""
+	}
+	return filepath.Clean(topLevel)
+}
+
+// gitEnvWithoutRepoOverrides drops variables that make git ignore cmd.Dir when
+// locating the repository, such as those set while running inside a git hook.
+func gitEnvWithoutRepoOverrides(env []string) []string {
+	out := make([]string, 0, len(env))
+	for _, kv := range env {
+		name, _, _ := strings.Cut(kv, "=")
+		switch name {
+		case "GIT_DIR", "GIT_WORK_TREE", "GIT_COMMON_DIR", "GIT_INDEX_FILE", "GIT_PREFIX":
+			continue
+		}
+		out = append(out, kv)
+	}
+	return out
 }
 
 func (g *defaultGitOps) RemoteURL(ctx context.Context, dir, name string) string {
@@ -161,6 +216,36 @@ func (g *defaultGitOps) RemoteURL(ctx context.Context, dir, name string) string
 	return strings.TrimSpace(string(output))
 }
 
+func (g *defaultGitOps) Remotes(ctx context.Context, dir string) (map[string]string, error) {
+	cmd := exec.CommandContext(ctx, "git", "config", "--null", "--get-regexp", `^remote\..*\.url$`)
+	cmd.Dir = dir
+	cmd.Env = gitEnvWithoutRepoOverrides(os.Environ())
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	slog.InfoContext(ctx, "GitOps.Remotes", "cmd", strings.Join(cmd.Args, " "), "dir", dir)
+	output, err := cmd.Output()
+	remotes := map[string]string{}
+	if err != nil {
+		// git config exits 1 when nothing matches: dir has no remotes.
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 && stderr.Len() == 0 {
+			return remotes, nil
+		}
+		slog.InfoContext(ctx, "GitOps.Remotes", "error", err, "output", stderr.String())
+		return nil, fmt.Errorf("git config --get-regexp remote urls failed: %w (output: %s)", err, stderr.String())
+	}
+	// With --null each entry is the key, a newline, then the value.
+	for _, entry := range strings.Split(string(output), "\x00") {
+		key, url, ok := strings.Cut(entry, "\n")
+		if !ok {
+			continue
+		}
+		name := strings.TrimSuffix(strings.TrimPrefix(key, "remote."), ".url")
+		remotes[name] = url
+	}
+	return remotes, nil
+}
+
 func (g *defaultGitOps) LocalBranchExists(ctx context.Context, dir, branch string) bool {
 	cmd := exec.CommandContext(ctx, "git", "show-ref", "--verify", "--quiet", "refs/heads/"+branch)
 	cmd.Dir = dir
@@ -172,6 +257,23 @@ func (g *defaultGitOps) LocalBranchExists(ctx context.Context, dir, branch strin
 	return true
 }
 
+func (g *defaultGitOps) CheckoutClean(ctx context.Context, dir, branch string) error {
+	for _, args := range [][]string{
+		{"checkout", "--force", branch, "--"},
+		{"clean", "-ffd"},
+	} {
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Dir = dir
+		slog.InfoContext(ctx, "GitOps.CheckoutClean", "cmd", strings.Join(cmd.Args, " "), "dir", dir)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			slog.InfoContext(ctx, "GitOps.CheckoutClean", "error", err, "output", string(output))
+			return fmt.Errorf("git %s failed: %w (output: %s)", args[0], err, output)
+		}
+	}
+	return nil
+}
+
 func (g *defaultGitOps) SetBranchUpstream(ctx context.Context, dir, branch, remote string) error {
 	remoteCmd := exec.CommandContext(ctx, "git", "config", "branch."+branch+".remote", remote)
 	remoteCmd.Dir = dir
@@ -261,3 +363,104 @@ func (g *defaultGitOps) CommitDivergence(ctx context.Context, dir, base, head st
 	}
 	return ahead, behind, true
 }
+
+// HardenedGitCommand returns a git command that ignores git configuration,
+// hooks, attributes, pagers and external diff tools from both the user's
+// environment and the repository, so reading a repository a sandbox controls
+// can't run anything on the host.
+func HardenedGitCommand(ctx context.Context, dir string, args ...string) *exec.Cmd {
+	base := []string{
+		"-c", "core.pager=cat",
+		"-c", "pager.status=false",
+		"-c", "pager.log=false",
+		"-c", "core.fsmonitor=false",
+		"-c", "core.untrackedCache=false",
+		"-c", "core.hooksPath=/dev/null",
+		"-c", "core.attributesFile=/dev/null",
+		"-c", "diff.external=",
+	}
+	cmd := exec.CommandContext(ctx, "git", append(base, args...)...)
+	cmd.Dir = dir
+	cmd.Env = hardenedGitEnv()
+	return cmd
+}
+
+func hardenedGitEnv() []string {
+	env := make([]string, 0, len(os.Environ())+12)
+	for _, kv := range os.Environ() {
+		key, _, ok := strings.Cut(kv, "=")
+		if !ok {
+			continue
+		}
+		if strings.HasPrefix(key, "GIT_") || key == "PAGER" || key == "SSH_ASKPASS" {
+			continue
+		}
+		env = append(env, kv)
+	}
+	return append(
+		env,
+		"HOME=/dev/null",
+		"XDG_CONFIG_HOME=/dev/null",
+		"GIT_CONFIG_NOSYSTEM=1",
+		"GIT_CONFIG_GLOBAL=/dev/null",
+		"GIT_CONFIG_SYSTEM=/dev/null",
+		"GIT_ATTR_NOSYSTEM=1",
+		"GIT_TERMINAL_PROMPT=0",
+		"GIT_PAGER=cat",
+		"PAGER=cat",
+		"GIT_EXTERNAL_DIFF=",
+		"GIT_ASKPASS=/bin/false",
+		"SSH_ASKPASS=/bin/false",
+	)
+}
+
+func (g *defaultGitOps) FetchRefs(ctx context.Context, gitDir, source string, refspecs []string, output io.Writer) error {
+	cmd := HardenedGitCommand(ctx, "", append([]string{"--git-dir", gitDir, "fetch", "--prune", source}, refspecs...)...)
+	slog.InfoContext(ctx, "GitOps.FetchRefs", "cmd", strings.Join(cmd.Args, " "), "gitDir", gitDir)
+	out, err := cmd.CombinedOutput()
+	if output != nil {
+		output.Write(out) //nolint:errcheck
+	}
+	if err != nil {
+		slog.InfoContext(ctx, "GitOps.FetchRefs", "error", err, "output", string(out))
+		return fmt.Errorf("git fetch failed: %w (output: %s)", err, out)
+	}
+	return nil
+}
+
+func (g *defaultGitOps) Status(ctx context.Context, dir string, stdout, stderr io.Writer) error {
+	cmd := HardenedGitCommand(ctx, dir, "status")
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	slog.InfoContext(ctx, "GitOps.Status", "cmd", strings.Join(cmd.Args, " "), "dir", dir)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git status failed: %w", err)
+	}
+	return nil
+}
+
+func (g *defaultGitOps) Log(ctx context.Context, gitDir, ref string, stdout, stderr io.Writer) error {
+	cmd := HardenedGitCommand(ctx, "", "--git-dir", gitDir, "log", ref)
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	slog.InfoContext(ctx, "GitOps.Log", "cmd", strings.Join(cmd.Args, " "), "gitDir", gitDir)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git log failed: %w", err)
+	}
+	return nil
+}
+
+func (g *defaultGitOps) Diff(ctx context.Context, dir, a, b string, args []string, stdout, stderr io.Writer) (bool, error) {
+	diffArgs := []string{"diff", "--no-index", "--no-ext-diff", "--src-prefix=" + a + "/", "--dst-prefix=" + b + "/"}
+	diffArgs = append(append(diffArgs, args...), a, b)
+	cmd := HardenedGitCommand(ctx, dir, diffArgs...)
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	slog.InfoContext(ctx, "GitOps.Diff", "cmd", strings.Join(cmd.Args, " "), "dir", dir)
+	err := cmd.Run()
+	// git diff --no-index exits 1 when the trees differ.
+	if exitErr := (*exec.ExitError)(nil); errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("git diff failed: %w", err)
+	}
+	return false, nil
+}
@@ -0,0 +1,239 @@
+package hostops
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTopLevelPlainRepo(t *testing.T) {
+	repo := newTestGitRepo(t)
+	subdir := filepath.Join(repo, "a", "b")
+	if err := os.MkdirAll(subdir, 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+
+	g := NewDefaultGitOps()
+	for _, dir := range []string{repo, subdir} {
+		if got := g.TopLevel(context.Background(), dir); got != repo {
+			t.Errorf("TopLevel(%q) = %q, want %q", dir, got, repo)
+		}
+	}
+}
+
+func TestTopLevelLinkedWorktree(t *testing.T) {
+	repo := newTestGitRepo(t)
+	worktree := filepath.Join(resolvedTempDir(t), "wt")
+	runTestGit(t, repo, "worktree", "add", "-b", "feature", worktree)
+	subdir := filepath.Join(worktree, "pkg")
+	if err := os.MkdirAll(subdir, 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+
+	g := NewDefaultGitOps()
+	for _, dir := range []string{worktree, subdir} {
+		if got := g.TopLevel(context.Background(), dir); got != worktree {
+			t.Errorf("TopLevel(%q) = %q, want worktree %q (main checkout is %q)", dir, got, worktree, repo)
+		}
+	}
+}
+
+func TestTopLevelSubmoduleReturnsSubmodule(t *testing.T) {
+	sub := newTestGitRepo(t)
+	super := newTestGitRepo(t)
+	runTestGit(t, super, "-c", "protocol.file.allow=always", "submodule", "add", sub, "vendor/sub")
+
+	got := NewDefaultGitOps().TopLevel(context.Background(), filepath.Join(super, "vendor", "sub"))
+	if want := filepath.Join(super, "vendor", "sub"); got != want {
+		t.Fatalf("TopLevel(submodule) = %q, want %q", got, want)
+	}
+}
+
+func TestTopLevelNotARepo(t *testing.T) {
+	dir := resolvedTempDir(t)
+	t.Setenv("GIT_CEILING_DIRECTORIES", filepath.Dir(dir))
+
+	if got := NewDefaultGitOps().TopLevel(context.Background(), dir); got != "" {
+		t.Fatalf("TopLevel(non-repo) = %q, want empty", got)
+	}
+}
+
+func TestTopLevelInsideGitDir(t *testing.T) {
+	repo := newTestGitRepo(t)
+
+	if got := NewDefaultGitOps().TopLevel(context.Background(), filepath.Join(repo, ".git")); got != "" {
+		t.Fatalf("TopLevel(.git) = %q, want empty", got)
+	}
+}
+
+func TestTopLevelIgnoresGitDirEnv(t *testing.T) {
+	repo := newTestGitRepo(t)
+	other := newTestGitRepo(t)
+	t.Setenv("GIT_DIR", filepath.Join(other, ".git"))
+
+	if got := NewDefaultGitOps().TopLevel(context.Background(), repo); got != repo {
+		t.Fatalf("TopLevel with GIT_DIR set = %q, want %q", got, repo)
+	}
+}
+
+// resolvedTempDir returns t.TempDir() with symlinks resolved, matching the
+// paths git reports (e.g. /private/var rather than /var on macOS).
+func resolvedTempDir(t *testing.T) string {
+	t.Helper()
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("EvalSymlinks: %v", err)
+	}
+	return dir
+}
+
+func newTestGitRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	repo := resolvedTempDir(t)
+	runTestGit(t, repo, "init", "-b", "main")
+	if err := os.WriteFile(filepath.Join(repo, "README"), []byte("hello\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	runTestGit(t, repo, "add", "README")
+	runTestGit(t, repo, "commit", "-m", "initial")
+	return repo
+}
+
+func runTestGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	args = append([]string{"-c", "user.name=sand test", "-c", "user.email=sand@example.com"}, args...)
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = gitEnvWithoutRepoOverrides(os.Environ())
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, output)
+	}
+}
+
+func TestCheckoutCleanDiscardsWorkingTreeChanges(t *testing.T) {
+	repo := newTestGitRepo(t)
+	runTestGit(t, repo, "checkout", "-b", "release")
+	if err := os.WriteFile(filepath.Join(repo, "RELEASE"), []byte("v1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runTestGit(t, repo, "add", "RELEASE")
+	runTestGit(t, repo, "commit", "-m", "release")
+	runTestGit(t, repo, "checkout", "main")
+	if err := os.WriteFile(filepath.Join(repo, "README"), []byte("edited\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "scratch.txt"), []byte("untracked\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	g := &defaultGitOps{}
+	ctx := context.Background()
+	if err := g.CheckoutClean(ctx, repo, "release"); err != nil {
+		t.Fatalf("CheckoutClean() error = %v", err)
+	}
+	if got := g.Branch(ctx, repo); got != "release" {
+		t.Errorf("Branch() = %q, want release", got)
+	}
+	if g.IsDirty(ctx, repo) {
+		t.Error("IsDirty() = true, want a clean checkout")
+	}
+	if _, err := os.Stat(filepath.Join(repo, "scratch.txt")); !os.IsNotExist(err) {
+		t.Errorf("untracked file survived the checkout: %v", err)
+	}
+	if err := g.CheckoutClean(ctx, repo, "missing"); err == nil {
+		t.Error("CheckoutClean(missing) error = nil, want an error for an unknown branch")
+	}
+}
+func TestHardenedGitEnvScrubsGitEnvironment(t *testing.T) {
+	t.Setenv("GIT_DIR", "/tmp/hostile.git")
+	t.Setenv("GIT_CONFIG_GLOBAL", "/tmp/hostile-config")
+	t.Setenv("GIT_EXTERNAL_DIFF", "cat")
+	t.Setenv("PAGER", "less")
+
+	env := strings.Join(hardenedGitEnv(), "\n")
+	for _, forbidden := range []string{
+		"GIT_DIR=/tmp/hostile.git",
+		"GIT_CONFIG_GLOBAL=/tmp/hostile-config",
+		"GIT_EXTERNAL_DIFF=cat",
+		"PAGER=less",
+	} {
+		if strings.Contains(env, forbidden) {
+			t.Fatalf("hardened env contains %q:\n%s", forbidden, env)
+		}
+	}
+	for _, want := range []string{
+		"GIT_CONFIG_NOSYSTEM=1",
+		"GIT_CONFIG_GLOBAL=/dev/null",
+		"GIT_CONFIG_SYSTEM=/dev/null",
+		"GIT_TERMINAL_PROMPT=0",
+		"GIT_EXTERNAL_DIFF=",
+	} {
+		if !strings.Contains(env, want) {
+			t.Fatalf("hardened env missing %q:\n%s", want, env)
+		}
+	}
+}
+
+func TestDiffLabelsTreesAndReportsDifferences(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	root := t.TempDir()
+	for dir, body := range map[string]string{"sandbox": "new\n", "host": "old\n"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, dir, "file.txt"), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	g := NewDefaultGitOps()
+	var out bytes.Buffer
+	differ, err := g.Diff(context.Background(), root, "sandbox", "host", []string{"--name-only"}, &out, &out)
+	if err != nil || !differ {
+		t.Fatalf("Diff() = %v, %v; want differing trees", differ, err)
+	}
+	if got := strings.TrimSpace(out.String()); got != "host/file.txt" {
+		t.Fatalf("Diff() output = %q, want the file labeled with its tree", got)
+	}
+
+	if err := os.WriteFile(filepath.Join(root, "host", "file.txt"), []byte("new\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	if differ, err := g.Diff(context.Background(), root, "sandbox", "host", nil, &out, &out); err != nil || differ || out.Len() != 0 {
+		t.Fatalf("Diff() of identical trees = %v, %v, %q; want no difference", differ, err, out.String())
+	}
+}
+
+func TestRemotesListsEachRemoteURL(t *testing.T) {
+	repo := newTestGitRepo(t)
+	g := NewDefaultGitOps()
+	remotes, err := g.Remotes(context.Background(), repo)
+	if err != nil || len(remotes) != 0 {
+		t.Fatalf("Remotes() of a repo without remotes = %v, %v; want none", remotes, err)
+	}
+
+	runTestGit(t, repo, "remote", "add", "origin", "git@github.com:banksean/sand.git")
+	runTestGit(t, repo, "remote", "add", "sand/my.box", "/clones/my box/app")
+	remotes, err = g.Remotes(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("Remotes() error = %v", err)
+	}
+	want := map[string]string{
+		"origin":      "git@github.com:banksean/sand.git",
+		"sand/my.box": "/clones/my box/app",
+	}
+	if !reflect.DeepEqual(remotes, want) {
+		t.Fatalf("Remotes() = %v, want %v", remotes, want)
+	}
+}
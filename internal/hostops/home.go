@@ -0,0 +1,20 @@
+package hostops
+
+import (
+	"os"
+	"os/user"
+)
+
+// HomeDir returns the current user's home directory: $HOME if it is set,
+// otherwise the home directory in the user database, which covers sand
+// running as a service with a stripped environment. It returns "" if
+// neither is available.
+func HomeDir() string {
+	if home := os.Getenv("HOME"); home != "" {
+		return home
+	}
+	if u, err := user.Current(); err == nil {
+		return u.HomeDir
+	}
+	return ""
+}
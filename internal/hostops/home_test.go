@@ -0,0 +1,21 @@
+package hostops
+
+import (
+	"os/user"
+	"testing"
+)
+
+func TestHomeDirFallsBackToUserDatabase(t *testing.T) {
+	u, err := user.Current()
+	if err != nil {
+		t.Skipf("no current user: %v", err)
+	}
+	t.Setenv("HOME", "")
+	if got := HomeDir(); got != u.HomeDir {
+		t.Fatalf("HomeDir() with HOME unset = %q, want %q", got, u.HomeDir)
+	}
+	t.Setenv("HOME", "/custom/home")
+	if got := HomeDir(); got != "/custom/home" {
+		t.Fatalf("HomeDir() = %q, want $HOME", got)
+	}
+}
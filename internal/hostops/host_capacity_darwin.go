@@ -0,0 +1,20 @@
+//go:build darwin
+
+package hostops
+
+import (
+	"runtime"
+
+	"golang.org/x/sys/unix"
+)
+
+// HostCapacity returns how many CPUs and how many bytes of memory the host
+// has. Each Apple container runs in its own VM on the host, so these bound
+// what a single sandbox can be given.
+func HostCapacity() (cpus int, memoryBytes uint64, err error) {
+	memoryBytes, err = unix.SysctlUint64("hw.memsize")
+	if err != nil {
+		return 0, 0, err
+	}
+	return runtime.NumCPU(), memoryBytes, nil
+}
@@ -0,0 +1,14 @@
+//go:build !darwin
+
+package hostops
+
+import (
+	"errors"
+	"fmt"
+)
+
+// HostCapacity isn't known off macOS: inside a sandbox, the machine that
+// runs new containers is the host, not this one.
+func HostCapacity() (cpus int, memoryBytes uint64, err error) {
+	return 0, 0, fmt.Errorf("host capacity: %w", errors.ErrUnsupported)
+}
@@ -0,0 +1,49 @@
+package hostops
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"os/exec"
+	"slices"
+)
+
+// buildCommand builds the "container build" invocation for Build; tests replace it.
+var buildCommand = exec.CommandContext
+
+// buildImageArgs returns the "container build" arguments for opts. Build args
+// are sorted so the command line is stable.
+func buildImageArgs(opts *BuildImage, contextDir string) []string {
+	args := []string{"build", "--progress", "plain"}
+	if opts != nil {
+		if opts.Tag != "" {
+			args = append(args, "--tag", opts.Tag)
+		}
+		if opts.File != "" {
+			args = append(args, "--file", opts.File)
+		}
+		for _, key := range slices.Sorted(maps.Keys(opts.BuildArg)) {
+			args = append(args, "--build-arg", key+"="+opts.BuildArg[key])
+		}
+		if opts.NoCache {
+			args = append(args, "--no-cache")
+		}
+	}
+	return append(args, contextDir)
+}
+
+// buildImage runs "container build". The XPC image service has no build
+// API; builds go through the CLI, which runs them in the builder container.
+func buildImage(ctx context.Context, opts *BuildImage, contextDir string, w io.Writer) error {
+	if w == nil {
+		w = io.Discard
+	}
+	cmd := buildCommand(ctx, "container", buildImageArgs(opts, contextDir)...)
+	cmd.Stdout = w
+	cmd.Stderr = w
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("container build %s: %w", contextDir, err)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package hostops
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestBuildImageRunsContainerBuildAndStreamsOutput(t *testing.T) {
+	var gotName string
+	var gotArgs []string
+	old := buildCommand
+	buildCommand = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		gotName, gotArgs = name, args
+		return exec.CommandContext(ctx, "sh", "-c", `echo "#1 building"; echo "#2 done" >&2`)
+	}
+	t.Cleanup(func() { buildCommand = old })
+
+	var out bytes.Buffer
+	err := buildImage(context.Background(), &BuildImage{
+		Tag:      "sand-local/box:latest",
+		BuildArg: map[string]string{"USERNAME": "alice", "UID": "501"},
+	}, "/work/image", &out)
+	if err != nil {
+		t.Fatalf("buildImage() error = %v", err)
+	}
+	want := []string{"build", "--progress", "plain", "--tag", "sand-local/box:latest", "--build-arg", "UID=501", "--build-arg", "USERNAME=alice", "/work/image"}
+	if gotName != "container" || !slices.Equal(gotArgs, want) {
+		t.Fatalf("command = %s %v, want container %v", gotName, gotArgs, want)
+	}
+	if !strings.Contains(out.String(), "#1 building") || !strings.Contains(out.String(), "#2 done") {
+		t.Fatalf("build output = %q, want stdout and stderr", out.String())
+	}
+}
+
+func TestBuildImageReportsFailure(t *testing.T) {
+	old := buildCommand
+	buildCommand = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		return exec.CommandContext(ctx, "sh", "-c", "exit 1")
+	}
+	t.Cleanup(func() { buildCommand = old })
+
+	if err := buildImage(context.Background(), &BuildImage{Tag: "x"}, "/work/image", nil); err == nil || !strings.Contains(err.Error(), "/work/image") {
+		t.Fatalf("buildImage() error = %v, want failure naming the context dir", err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"time"
@@ -43,7 +44,8 @@ func (o *xpcImageOps) Pull(ctx context.Context, image string, progress imageprog
 	if progress == nil {
 		progress = imageprogress.NewTextSink(nil)
 	}
-	_, err := o.client.PullImage(ctx, image, xpc.ImagePullOptions{}, func(update xpc.ProgressUpdate) {
+	pullOpts := xpc.ImagePullOptions{Insecure: RegistryUsesHTTP(ImageRegistry(image))}
+	_, err := o.client.PullImage(ctx, image, pullOpts, func(update xpc.ProgressUpdate) {
 		progress.Update(imageprogress.Update{
 			Description:    update.Description,
 			SubDescription: update.SubDescription,
@@ -280,3 +282,11 @@ func historyToSandtypes(history []xpc.History) []sandtypes.HistoryEntry {
 	}
 	return ret
 }
+
+func (o *xpcImageOps) Build(ctx context.Context, opts *BuildImage, contextDir string, w io.Writer) error {
+	return buildImage(ctx, opts, contextDir, w)
+}
+
+func (o *xpcImageOps) Remove(ctx context.Context, name string) error {
+	return o.client.DeleteImage(ctx, name, true)
+}
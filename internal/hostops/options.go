@@ -28,6 +28,11 @@ type StopContainer struct {
 	Debug bool `flag:"--debug"`
 }
 
+type KillContainer struct {
+	// Signal is the signal to send the container (default: SIGKILL)
+	Signal string `flag:"--signal"`
+}
+
 type DeleteContainer struct {
 	// Force forces the removal of one or more running containers
 	Force bool `flag:"--force"`
@@ -40,10 +45,30 @@ type ExecContainer struct {
 	ProcessOptions
 }
 
+// LogsContainer are the options flags for "container logs".
+type LogsContainer struct {
+	// Boot reads the VM's boot log instead of the container's stdio
+	Boot bool `flag:"--boot"`
+	// Lines limits the output to the last Lines lines; zero means all of it
+	Lines int `flag:"-n"`
+}
+
 type ExportContainer struct {
 	Output string `flag:"--output"`
 }
 
+// BuildImage are the options flags for "container build".
+type BuildImage struct {
+	// Tag is the name to give the built image
+	Tag string `flag:"--tag"`
+	// File is the path to the Dockerfile (default: Dockerfile in the build context)
+	File string `flag:"--file"`
+	// BuildArg sets key=value build-time variables
+	BuildArg map[string]string `flag:"--build-arg"`
+	// NoCache disables the build cache
+	NoCache bool `flag:"--no-cache"`
+}
+
 type ManagementOptions struct {
 	// Arch sets arch if image can target multiple architectures (default: arm64)
 	Arch string `flag:"--arch"`
@@ -51,16 +76,16 @@ type ManagementOptions struct {
 	CIDFile string `flag:"--cidfile"`
 	// Detach runs the container and detaches from the process
 	Detach bool `flag:"--detach"`
-	// DNS is the DNS nameserver IP address
-	DNS string `flag:"--dns"`
+	// DNS are the DNS nameserver IP addresses
+	DNS []string `flag:"--dns"`
 	// InitImage is the OCI image to use as the VM init process (replaces vminitd)
 	InitImage string `flag:"--init-image"`
 	// DNSDomain is the default DNS domain
 	DNSDomain string `flag:"--dns-domain"`
 	// DNSOption specifies DNS options
-	DNSOption string `flag:"--dns-option"`
+	DNSOption []string `flag:"--dns-option"`
 	// DNSSearch specifies DNS search domains
-	DNSSearch string `flag:"--dns-search"`
+	DNSSearch []string `flag:"--dns-search"`
 	// Entrypoint overrides the entrypoint of the image
 	Entrypoint string `flag:"--entrypoint"`
 	// Kernel sets a custom kernel path
@@ -77,8 +102,8 @@ type ManagementOptions struct {
 	NoDNS bool `flag:"--no-dns"`
 	// OS sets OS if image can target multiple operating systems (default: linux)
 	OS string `flag:"--os"`
-	// Publish publishes a port from container to host (format: [host-ip:]host-port:container-port[/protocol])
-	Publish string `flag:"--publish"`
+	// Publish publishes ports from container to host (format: [host-ip:]host-port:container-port[/protocol])
+	Publish []string `flag:"--publish"`
 	// Platform is the platform for the image if it's multi-platform. This takes precedence over --os and --arch
 	Platform string `flag:"--platform"`
 	// PublishSocket publishes a socket from container to host (format: host_path:container_path)
@@ -0,0 +1,88 @@
+package hostops
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/banksean/sand/internal/applecontainer/xpc"
+)
+
+// ParsePublishPort parses a --publish spec of the form
+// [host-ip:]host-port:container-port[/tcp|udp]. The protocol defaults to tcp.
+func ParsePublishPort(spec string) (xpc.PublishPort, error) {
+	proto := xpc.PublishProtocolTCP
+	ports := spec
+	if before, after, ok := strings.Cut(ports, "/"); ok {
+		ports = before
+		switch after {
+		case "", "tcp":
+			proto = xpc.PublishProtocolTCP
+		case "udp":
+			proto = xpc.PublishProtocolUDP
+		default:
+			return xpc.PublishPort{}, fmt.Errorf("invalid publish protocol %q in %q: want tcp or udp", after, spec)
+		}
+	}
+
+	parts := strings.Split(ports, ":")
+	var hostAddress string
+	var hostPortRaw string
+	var containerPortRaw string
+	switch len(parts) {
+	case 2:
+		hostPortRaw = parts[0]
+		containerPortRaw = parts[1]
+	case 3:
+		hostAddress = parts[0]
+		hostPortRaw = parts[1]
+		containerPortRaw = parts[2]
+		if net.ParseIP(hostAddress) == nil {
+			return xpc.PublishPort{}, fmt.Errorf("invalid host address %q in publish spec %q", hostAddress, spec)
+		}
+	default:
+		return xpc.PublishPort{}, fmt.Errorf("invalid publish spec %q: want [host-ip:]host-port:container-port[/proto]", spec)
+	}
+	hostPort, err := parsePort(hostPortRaw, "host port")
+	if err != nil {
+		return xpc.PublishPort{}, err
+	}
+	containerPort, err := parsePort(containerPortRaw, "container port")
+	if err != nil {
+		return xpc.PublishPort{}, err
+	}
+	return xpc.PublishPort{
+		HostAddress:   xpc.IPAddress(hostAddress),
+		HostPort:      hostPort,
+		ContainerPort: containerPort,
+		Proto:         proto,
+		Count:         1,
+	}, nil
+}
+
+// ValidatePublish reports the first of specs that ParsePublishPort rejects,
+// or two specs that publish the same host port.
+func ValidatePublish(specs []string) error {
+	seen := make(map[string]string, len(specs))
+	for _, spec := range specs {
+		port, err := ParsePublishPort(spec)
+		if err != nil {
+			return err
+		}
+		key := fmt.Sprintf("%s/%d", port.Proto, port.HostPort)
+		if prev, ok := seen[key]; ok {
+			return fmt.Errorf("publish specs %q and %q use the same host port", prev, spec)
+		}
+		seen[key] = spec
+	}
+	return nil
+}
+
+func parsePort(raw, name string) (uint16, error) {
+	value, err := strconv.ParseUint(raw, 10, 16)
+	if err != nil || value == 0 {
+		return 0, fmt.Errorf("invalid %s %q", name, raw)
+	}
+	return uint16(value), nil
+}
@@ -0,0 +1,88 @@
+package hostops
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/banksean/sand/internal/applecontainer/xpc"
+)
+
+func TestParsePublishPort(t *testing.T) {
+	tests := []struct {
+		name          string
+		spec          string
+		hostAddress   xpc.IPAddress
+		hostPort      uint16
+		containerPort uint16
+		proto         xpc.PublishProtocol
+	}{
+		{
+			name:          "host and container port",
+			spec:          "3000:3000/tcp",
+			hostPort:      3000,
+			containerPort: 3000,
+			proto:         xpc.PublishProtocolTCP,
+		},
+		{
+			name:          "host address",
+			spec:          "127.0.0.1:3128:3128/tcp",
+			hostAddress:   "127.0.0.1",
+			hostPort:      3128,
+			containerPort: 3128,
+			proto:         xpc.PublishProtocolTCP,
+		},
+		{
+			name:          "udp",
+			spec:          "5353:5353/udp",
+			hostPort:      5353,
+			containerPort: 5353,
+			proto:         xpc.PublishProtocolUDP,
+		},
+		{
+			name:          "protocol defaults to tcp",
+			spec:          "8080:80",
+			hostPort:      8080,
+			containerPort: 80,
+			proto:         xpc.PublishProtocolTCP,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePublishPort(tt.spec)
+			if err != nil {
+				t.Fatalf("ParsePublishPort: %v", err)
+			}
+			if got.HostAddress != tt.hostAddress || got.HostPort != tt.hostPort || got.ContainerPort != tt.containerPort || got.Proto != tt.proto || got.Count != 1 {
+				t.Fatalf("ParsePublishPort(%q) = %+v", tt.spec, got)
+			}
+		})
+	}
+}
+
+func TestParsePublishPortRejectsMalformedSpecs(t *testing.T) {
+	for spec, want := range map[string]string{
+		"8080":                "want [host-ip:]host-port:container-port",
+		"a:b:c:d":             "want [host-ip:]host-port:container-port",
+		"8080:80/sctp":        `invalid publish protocol "sctp"`,
+		"localhost:8080:80":   `invalid host address "localhost"`,
+		"http:80":             `invalid host port "http"`,
+		"8080:70000":          `invalid container port "70000"`,
+		"0:80":                `invalid host port "0"`,
+		"127.0.0.1::80/tcp":   `invalid host port ""`,
+		"127.0.0.1:8080:/udp": `invalid container port ""`,
+	} {
+		if _, err := ParsePublishPort(spec); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ParsePublishPort(%q) error = %v, want %q", spec, err, want)
+		}
+	}
+}
+
+func TestValidatePublishRejectsReusedHostPort(t *testing.T) {
+	if err := ValidatePublish([]string{"8080:80", "8443:443", "8080:80/udp"}); err != nil {
+		t.Fatalf("ValidatePublish() error = %v, want distinct host ports and protocols accepted", err)
+	}
+	err := ValidatePublish([]string{"8080:80", "127.0.0.1:8080:8000/tcp"})
+	if err == nil || !strings.Contains(err.Error(), "same host port") {
+		t.Fatalf("ValidatePublish() error = %v, want a reused host port rejected", err)
+	}
+}
@@ -0,0 +1,179 @@
+package hostops
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+// RegistryAuthPath returns sand's own registry credential file. It uses the
+// same format as the "auths" section of docker's config.json.
+func RegistryAuthPath() string {
+	return filepath.Join(HomeDir(), ".config", "sand", "registry-auth.json")
+}
+
+// RegistryKeychain resolves registry credentials from sand's registry-auth.json
+// first, then from the standard docker config (including credential helpers).
+//
+// The Apple container runtime does not accept credentials over its pull API;
+// it reads them from the credentials saved by `container registry login`. This
+// keychain is used for the registry requests sand makes itself, such as
+// checking whether a local image is out of date.
+func RegistryKeychain() authn.Keychain {
+	return authn.NewMultiKeychain(&fileKeychain{path: RegistryAuthPath()}, authn.DefaultKeychain)
+}
+
+// fileKeychain reads credentials from a docker-format config file.
+type fileKeychain struct {
+	path string
+}
+
+type dockerAuthEntry struct {
+	Auth          string `json:"auth,omitempty"`
+	Username      string `json:"username,omitempty"`
+	Password      string `json:"password,omitempty"`
+	IdentityToken string `json:"identitytoken,omitempty"`
+}
+
+func (k *fileKeychain) Resolve(target authn.Resource) (authn.Authenticator, error) {
+	data, err := os.ReadFile(k.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return authn.Anonymous, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read registry credentials %s: %w", k.path, err)
+	}
+	var cfg struct {
+		Auths map[string]dockerAuthEntry `json:"auths"`
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse registry credentials %s: %w", k.path, err)
+	}
+	for _, key := range registryAuthKeys(target.RegistryStr()) {
+		entry, ok := cfg.Auths[key]
+		if !ok {
+			continue
+		}
+		authCfg := authn.AuthConfig{
+			Username:      entry.Username,
+			Password:      entry.Password,
+			IdentityToken: entry.IdentityToken,
+		}
+		if entry.Auth != "" {
+			decoded, err := base64.StdEncoding.DecodeString(entry.Auth)
+			if err != nil {
+				return nil, fmt.Errorf("decode credentials for %s in %s: %w", key, k.path, err)
+			}
+			user, pass, ok := strings.Cut(string(decoded), ":")
+			if !ok {
+				return nil, fmt.Errorf("credentials for %s in %s are not user:password", key, k.path)
+			}
+			authCfg.Username, authCfg.Password = user, pass
+		}
+		return authn.FromConfig(authCfg), nil
+	}
+	return authn.Anonymous, nil
+}
+
+// registryAuthKeys lists the config keys that may hold credentials for
+// registry, matching the spellings docker itself writes.
+func registryAuthKeys(registry string) []string {
+	keys := []string{registry, "https://" + registry, "http://" + registry}
+	if registry == name.DefaultRegistry {
+		keys = append(keys, "docker.io", "https://index.docker.io/v1/")
+	}
+	return keys
+}
+
+// ImageRegistry returns the registry host for imageName, or "" if imageName
+// is not a valid image reference. Unqualified names resolve to Docker Hub.
+func ImageRegistry(imageName string) string {
+	ref, err := name.ParseReference(imageName)
+	if err != nil {
+		return ""
+	}
+	return ref.Context().RegistryStr()
+}
+
+// RegistryUsesHTTP reports whether registry should be contacted over plain
+// http, which sand does only for registries on the loopback interface. This
+// matches the Apple container CLI's default "auto" scheme.
+func RegistryUsesHTTP(registry string) bool {
+	host := registry
+	if h, _, err := net.SplitHostPort(registry); err == nil {
+		host = h
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// RegistryAuthError reports that a registry refused an image pull because
+// credentials were missing or wrong.
+type RegistryAuthError struct {
+	Registry string
+	Err      error
+}
+
+func (e *RegistryAuthError) Error() string {
+	return fmt.Sprintf("authentication required for registry %s; run `container registry login %s` and try again: %v", e.Registry, e.Registry, e.Err)
+}
+
+func (e *RegistryAuthError) Unwrap() error {
+	return e.Err
+}
+
+var registryAuthErrorPatterns = []string{
+	"unauthorized",
+	"authentication required",
+	"no basic auth credentials",
+	"access to the resource is denied",
+	"insufficient_scope",
+	"403 forbidden",
+}
+
+// IsRegistryAuthError reports whether err looks like a registry rejecting a
+// request for lack of valid credentials.
+func IsRegistryAuthError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var authErr *RegistryAuthError
+	if errors.As(err, &authErr) {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	for _, pattern := range registryAuthErrorPatterns {
+		if strings.Contains(msg, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClassifyPullError wraps err in a RegistryAuthError when pulling imageName
+// failed for lack of credentials, and returns it unchanged otherwise.
+func ClassifyPullError(imageName string, err error) error {
+	if err == nil || !IsRegistryAuthError(err) {
+		return err
+	}
+	var authErr *RegistryAuthError
+	if errors.As(err, &authErr) {
+		return err
+	}
+	registry := ImageRegistry(imageName)
+	if registry == "" {
+		return err
+	}
+	return &RegistryAuthError{Registry: registry, Err: err}
+}
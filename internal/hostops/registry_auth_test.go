@@ -0,0 +1,114 @@
+package hostops
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+func resolveFileKeychain(t *testing.T, path, image string) *authn.AuthConfig {
+	t.Helper()
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		t.Fatalf("ParseReference(%q): %v", image, err)
+	}
+	auth, err := (&fileKeychain{path: path}).Resolve(ref.Context())
+	if err != nil {
+		t.Fatalf("Resolve(%q): %v", image, err)
+	}
+	cfg, err := auth.Authorization()
+	if err != nil {
+		t.Fatalf("Authorization(%q): %v", image, err)
+	}
+	return cfg
+}
+
+func TestFileKeychainResolvesCredentials(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "registry-auth.json")
+	encoded := base64.StdEncoding.EncodeToString([]byte("robot:s3cret"))
+	config := fmt.Sprintf(`{"auths": {
+		"ghcr.io": {"auth": %q},
+		"https://registry.example.com": {"username": "alice", "password": "pw"},
+		"https://index.docker.io/v1/": {"identitytoken": "hub-token"}
+	}}`, encoded)
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	if got := resolveFileKeychain(t, path, "ghcr.io/acme/app:latest"); got.Username != "robot" || got.Password != "s3cret" {
+		t.Errorf("ghcr.io credentials = %+v, want robot/s3cret", got)
+	}
+	if got := resolveFileKeychain(t, path, "registry.example.com/team/app:v1"); got.Username != "alice" || got.Password != "pw" {
+		t.Errorf("registry.example.com credentials = %+v, want alice/pw", got)
+	}
+	if got := resolveFileKeychain(t, path, "alpine:latest"); got.IdentityToken != "hub-token" {
+		t.Errorf("docker hub credentials = %+v, want identity token", got)
+	}
+	if got := resolveFileKeychain(t, path, "quay.io/other/app:latest"); *got != (authn.AuthConfig{}) {
+		t.Errorf("unknown registry credentials = %+v, want anonymous", got)
+	}
+}
+
+func TestFileKeychainMissingFileIsAnonymous(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing.json")
+	if got := resolveFileKeychain(t, path, "ghcr.io/acme/app:latest"); *got != (authn.AuthConfig{}) {
+		t.Fatalf("credentials from missing file = %+v, want anonymous", got)
+	}
+}
+
+func TestImageRegistry(t *testing.T) {
+	for image, want := range map[string]string{
+		"ghcr.io/banksean/sand/default:latest": "ghcr.io",
+		"alpine":                               name.DefaultRegistry,
+		"localhost:5000/app:dev":               "localhost:5000",
+		"not a reference":                      "",
+	} {
+		if got := ImageRegistry(image); got != want {
+			t.Errorf("ImageRegistry(%q) = %q, want %q", image, got, want)
+		}
+	}
+}
+
+func TestRegistryUsesHTTP(t *testing.T) {
+	for registry, want := range map[string]bool{
+		"localhost:5000": true,
+		"127.0.0.1:5000": true,
+		"[::1]:5000":     true,
+		"ghcr.io":        false,
+		"10.0.0.5:5000":  false,
+	} {
+		if got := RegistryUsesHTTP(registry); got != want {
+			t.Errorf("RegistryUsesHTTP(%q) = %v, want %v", registry, got, want)
+		}
+	}
+}
+
+func TestClassifyPullError(t *testing.T) {
+	authFailure := errors.New("pull image: GET https://ghcr.io/token: UNAUTHORIZED: authentication required")
+	err := ClassifyPullError("ghcr.io/acme/private:latest", authFailure)
+	var authErr *RegistryAuthError
+	if !errors.As(err, &authErr) || authErr.Registry != "ghcr.io" {
+		t.Fatalf("ClassifyPullError(auth) = %v, want RegistryAuthError for ghcr.io", err)
+	}
+	if !errors.Is(err, authFailure) {
+		t.Errorf("ClassifyPullError(auth) does not wrap the original error")
+	}
+	if again := ClassifyPullError("ghcr.io/acme/private:latest", err); again != err {
+		t.Errorf("ClassifyPullError re-wrapped an existing RegistryAuthError: %v", again)
+	}
+
+	for _, other := range []error{
+		errors.New("XPC connection error: Connection interrupted"),
+		errors.New("open /var/lib/image: permission denied"),
+	} {
+		if got := ClassifyPullError("ghcr.io/acme/private:latest", other); got != other {
+			t.Errorf("ClassifyPullError(%v) = %v, want unchanged", other, got)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"io"
 	"os"
+	"sync"
 
 	"github.com/banksean/sand/internal/sandtypes"
 )
@@ -12,12 +13,38 @@ type MockContainerOps struct {
 	CreateFunc     func(ctx context.Context, opts *CreateContainer, image string, args []string) (string, error)
 	StartFunc      func(ctx context.Context, opts *StartContainer, containerID string) (string, error)
 	StopFunc       func(ctx context.Context, opts *StopContainer, containerID string) (string, error)
+	KillFunc       func(ctx context.Context, opts *KillContainer, containerID string) (string, error)
 	DeleteFunc     func(ctx context.Context, opts *DeleteContainer, containerID string) (string, error)
 	ExecFunc       func(ctx context.Context, opts *ExecContainer, containerID, cmd string, env []string, args ...string) (string, error)
 	ExecStreamFunc func(ctx context.Context, opts *ExecContainer, containerID, cmd string, env []string, stdin io.Reader, stdout, stderr io.Writer, cmdArgs ...string) (func() error, error)
 	InspectFunc    func(ctx context.Context, containerID string) ([]sandtypes.Container, error)
 	StatsFunc      func(ctx context.Context, containerID ...string) ([]sandtypes.ContainerStats, error)
 	ExportFunc     func(ctx context.Context, containerID, image string) (string, error)
+	LogsFunc       func(ctx context.Context, opts *LogsContainer, containerID string) (string, error)
+
+	// InspectCalls records the IDs passed to each Inspect call.
+	InspectCalls [][]string
+
+	// started tracks containers Start has succeeded for, so Inspect can report
+	// them as running the way the real runtime does even when InspectFunc
+	// describes them as stopped.
+	mu      sync.Mutex
+	started map[string]bool
+}
+
+func (m *MockContainerOps) setStarted(containerID string, started bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.started == nil {
+		m.started = map[string]bool{}
+	}
+	m.started[containerID] = started
+}
+
+func (m *MockContainerOps) isStarted(containerID string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.started[containerID]
 }
 
 // Export implements [ContainerOps].
@@ -34,19 +61,38 @@ func (m *MockContainerOps) Create(ctx context.Context, opts *CreateContainer, im
 
 func (m *MockContainerOps) Start(ctx context.Context, opts *StartContainer, containerID string) (string, error) {
 	if m.StartFunc != nil {
-		return m.StartFunc(ctx, opts, containerID)
+		out, err := m.StartFunc(ctx, opts, containerID)
+		if err == nil {
+			m.setStarted(containerID, true)
+		}
+		return out, err
 	}
+	m.setStarted(containerID, true)
 	return "started", nil
 }
 
 func (m *MockContainerOps) Stop(ctx context.Context, opts *StopContainer, containerID string) (string, error) {
+	m.setStarted(containerID, false)
 	if m.StopFunc != nil {
 		return m.StopFunc(ctx, opts, containerID)
 	}
 	return "stopped", nil
 }
 
+func (m *MockContainerOps) Kill(ctx context.Context, opts *KillContainer, containerID string) (string, error) {
+	if m.KillFunc != nil {
+		out, err := m.KillFunc(ctx, opts, containerID)
+		if err == nil {
+			m.setStarted(containerID, false)
+		}
+		return out, err
+	}
+	m.setStarted(containerID, false)
+	return "killed", nil
+}
+
 func (m *MockContainerOps) Delete(ctx context.Context, opts *DeleteContainer, containerID string) (string, error) {
+	m.setStarted(containerID, false)
 	if m.DeleteFunc != nil {
 		return m.DeleteFunc(ctx, opts, containerID)
 	}
@@ -67,9 +113,40 @@ func (m *MockContainerOps) ExecStream(ctx context.Context, opts *ExecContainer,
 	return func() error { return nil }, nil
 }
 
-func (m *MockContainerOps) Inspect(ctx context.Context, containerID string) ([]sandtypes.Container, error) {
+// Inspect calls InspectFunc once per ID and records each batch in
+// InspectCalls. Containers InspectFunc returns without an ID are given the ID
+// they were inspected by, as the real runtime would report it.
+func (m *MockContainerOps) Inspect(ctx context.Context, containerID ...string) ([]sandtypes.Container, error) {
+	m.mu.Lock()
+	m.InspectCalls = append(m.InspectCalls, append([]string(nil), containerID...))
+	m.mu.Unlock()
+	var ret []sandtypes.Container
+	for _, id := range containerID {
+		containers, err := m.inspectOne(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		for i := range containers {
+			if containers[i].Configuration.ID == "" {
+				containers[i].Configuration.ID = id
+			}
+		}
+		ret = append(ret, containers...)
+	}
+	return ret, nil
+}
+
+func (m *MockContainerOps) inspectOne(ctx context.Context, containerID string) ([]sandtypes.Container, error) {
 	if m.InspectFunc != nil {
-		return m.InspectFunc(ctx, containerID)
+		containers, err := m.InspectFunc(ctx, containerID)
+		if err == nil && m.isStarted(containerID) {
+			for i := range containers {
+				if containers[i].Status.State == "stopped" {
+					containers[i].Status.State = "running"
+				}
+			}
+		}
+		return containers, err
 	}
 	return []sandtypes.Container{
 		{
@@ -87,6 +164,13 @@ func (m *MockContainerOps) Stats(ctx context.Context, containerID ...string) ([]
 	return nil, nil
 }
 
+func (m *MockContainerOps) Logs(ctx context.Context, opts *LogsContainer, containerID string) (string, error) {
+	if m.LogsFunc != nil {
+		return m.LogsFunc(ctx, opts, containerID)
+	}
+	return "", nil
+}
+
 type MockGitOps struct {
 	AddRemoteFunc         func(ctx context.Context, dir, name, url string) error
 	RemoveRemoteFunc      func(ctx context.Context, dir, name string) error
@@ -98,12 +182,18 @@ type MockGitOps struct {
 	UpdateRefFunc         func(ctx context.Context, dir, ref, value string) error
 	TopLevelFunc          func(ctx context.Context, dir string) string
 	RemoteURLFunc         func(ctx context.Context, dir, name string) string
+	RemotesFunc           func(ctx context.Context, dir string) (map[string]string, error)
 	LocalBranchExistsFunc func(ctx context.Context, dir, branch string) bool
+	CheckoutCleanFunc     func(ctx context.Context, dir, branch string) error
 	SetBranchUpstreamFunc func(ctx context.Context, dir, branch, remote string) error
 	BranchFunc            func(ctx context.Context, dir string) string
 	CommitFunc            func(ctx context.Context, dir string) string
 	IsDirtyFunc           func(ctx context.Context, dir string) bool
 	CommitDivergenceFunc  func(ctx context.Context, dir, base, head string) (ahead, behind int, ok bool)
+	FetchRefsFunc         func(ctx context.Context, gitDir, source string, refspecs []string, output io.Writer) error
+	StatusFunc            func(ctx context.Context, dir string, stdout, stderr io.Writer) error
+	LogFunc               func(ctx context.Context, gitDir, ref string, stdout, stderr io.Writer) error
+	DiffFunc              func(ctx context.Context, dir, a, b string, args []string, stdout, stderr io.Writer) (bool, error)
 }
 
 func (m *MockGitOps) AddRemote(ctx context.Context, dir, name, url string) error {
@@ -176,6 +266,13 @@ func (m *MockGitOps) RemoteURL(ctx context.Context, dir, name string) string {
 	return ""
 }
 
+func (m *MockGitOps) Remotes(ctx context.Context, dir string) (map[string]string, error) {
+	if m.RemotesFunc != nil {
+		return m.RemotesFunc(ctx, dir)
+	}
+	return map[string]string{}, nil
+}
+
 func (m *MockGitOps) LocalBranchExists(ctx context.Context, dir, branch string) bool {
 	if m.LocalBranchExistsFunc != nil {
 		return m.LocalBranchExistsFunc(ctx, dir, branch)
@@ -183,6 +280,13 @@ func (m *MockGitOps) LocalBranchExists(ctx context.Context, dir, branch string)
 	return false
 }
 
+func (m *MockGitOps) CheckoutClean(ctx context.Context, dir, branch string) error {
+	if m.CheckoutCleanFunc != nil {
+		return m.CheckoutCleanFunc(ctx, dir, branch)
+	}
+	return nil
+}
+
 func (m *MockGitOps) SetBranchUpstream(ctx context.Context, dir, branch, remote string) error {
 	if m.SetBranchUpstreamFunc != nil {
 		return m.SetBranchUpstreamFunc(ctx, dir, branch, remote)
@@ -220,7 +324,8 @@ func (m *MockGitOps) CommitDivergence(ctx context.Context, dir, base, head strin
 
 type MockFileOps struct {
 	MkdirAllFunc  func(path string, perm os.FileMode) error
-	CopyFunc      func(ctx context.Context, src, dst string) error
+	MkdirFunc     func(path string, perm os.FileMode) error
+	CopyFunc      func(ctx context.Context, src, dst string, opts CopyOpts) (CopyResult, error)
 	StatFunc      func(path string) (os.FileInfo, error)
 	LstatFunc     func(path string) (os.FileInfo, error)
 	ReadlinkFunc  func(path string) (string, error)
@@ -238,13 +343,20 @@ func (m *MockFileOps) MkdirAll(path string, perm os.FileMode) error {
 	return nil
 }
 
-func (m *MockFileOps) Copy(ctx context.Context, src, dst string) error {
-	if m.CopyFunc != nil {
-		return m.CopyFunc(ctx, src, dst)
+func (m *MockFileOps) Mkdir(path string, perm os.FileMode) error {
+	if m.MkdirFunc != nil {
+		return m.MkdirFunc(path, perm)
 	}
 	return nil
 }
 
+func (m *MockFileOps) Copy(ctx context.Context, src, dst string, opts CopyOpts) (CopyResult, error) {
+	if m.CopyFunc != nil {
+		return m.CopyFunc(ctx, src, dst, opts)
+	}
+	return CopyResult{CopyOnWrite: true}, nil
+}
+
 func (m *MockFileOps) Stat(path string) (os.FileInfo, error) {
 	if m.StatFunc != nil {
 		return m.StatFunc(path)
@@ -300,3 +412,31 @@ func (m *MockFileOps) Volume(path string) (*VolumeInfo, error) {
 	}
 	return nil, nil
 }
+
+func (m *MockGitOps) FetchRefs(ctx context.Context, gitDir, source string, refspecs []string, output io.Writer) error {
+	if m.FetchRefsFunc != nil {
+		return m.FetchRefsFunc(ctx, gitDir, source, refspecs, output)
+	}
+	return nil
+}
+
+func (m *MockGitOps) Status(ctx context.Context, dir string, stdout, stderr io.Writer) error {
+	if m.StatusFunc != nil {
+		return m.StatusFunc(ctx, dir, stdout, stderr)
+	}
+	return nil
+}
+
+func (m *MockGitOps) Log(ctx context.Context, gitDir, ref string, stdout, stderr io.Writer) error {
+	if m.LogFunc != nil {
+		return m.LogFunc(ctx, gitDir, ref, stdout, stderr)
+	}
+	return nil
+}
+
+func (m *MockGitOps) Diff(ctx context.Context, dir, a, b string, args []string, stdout, stderr io.Writer) (bool, error) {
+	if m.DiffFunc != nil {
+		return m.DiffFunc(ctx, dir, a, b, args, stdout, stderr)
+	}
+	return false, nil
+}
@@ -7,21 +7,80 @@ import (
 	"log/slog"
 )
 
+// MessageLevel ranks a user-facing message, so that a messenger can show more
+// or less of what a command is doing. The zero value is MessageProgress.
+type MessageLevel int
+
+const (
+	// MessageDebug is for per-step detail, such as each dotfile copied.
+	MessageDebug MessageLevel = iota - 1
+	// MessageProgress is for what a command is doing now.
+	MessageProgress
+	// MessageInfo is for things the user should know even when they don't
+	// want progress, such as a file left out of a clone.
+	MessageInfo
+	// MessageError is for failures.
+	MessageError
+)
+
+// MessageLevelFor returns the least important level shown under the global
+// --quiet and --verbose flags.
+func MessageLevelFor(quiet, verbose bool) MessageLevel {
+	switch {
+	case quiet:
+		return MessageInfo
+	case verbose:
+		return MessageDebug
+	default:
+		return MessageProgress
+	}
+}
+
 type UserMessenger interface {
-	Message(ctx context.Context, msg string)
+	Message(ctx context.Context, level MessageLevel, msg string)
+}
+
+type messengerKey struct{}
+
+// WithMessenger returns a context whose messages, for code that looks them up
+// with ContextMessenger, go to m, such as to the client of one request rather
+// than the daemon's terminal.
+func WithMessenger(ctx context.Context, m UserMessenger) context.Context {
+	return context.WithValue(ctx, messengerKey{}, m)
+}
+
+// ContextMessenger returns the messenger set by WithMessenger, or fallback.
+func ContextMessenger(ctx context.Context, fallback UserMessenger) UserMessenger {
+	if m, ok := ctx.Value(messengerKey{}).(UserMessenger); ok && m != nil {
+		return m
+	}
+	return fallback
 }
 
 type terminalMessenger struct {
-	writer io.Writer
+	writer   io.Writer
+	minLevel MessageLevel
 }
 
+// NewTerminalMessenger returns a messenger that writes progress and more
+// important messages to writer.
 func NewTerminalMessenger(writer io.Writer) UserMessenger {
-	return &terminalMessenger{writer: writer}
+	return NewLeveledTerminalMessenger(writer, MessageProgress)
 }
 
-func (tm *terminalMessenger) Message(ctx context.Context, msg string) {
-	if tm.writer == nil {
-		slog.DebugContext(ctx, "userMsg (no writer)", "msg", msg)
+// NewLeveledTerminalMessenger returns a messenger that writes messages at
+// minLevel or above to writer, and logs the rest at debug level.
+func NewLeveledTerminalMessenger(writer io.Writer, minLevel MessageLevel) UserMessenger {
+	return &terminalMessenger{writer: writer, minLevel: minLevel}
+}
+
+func (tm *terminalMessenger) Message(ctx context.Context, level MessageLevel, msg string) {
+	if tm.writer == nil || level < tm.minLevel {
+		slog.DebugContext(ctx, "userMsg (not shown)", "level", level, "msg", msg)
+		return
+	}
+	if level >= MessageError {
+		fmt.Fprintln(tm.writer, msg)
 		return
 	}
 	fmt.Fprintln(tm.writer, "\033[90m"+msg+"\033[0m")
@@ -33,6 +92,6 @@ func NewNullMessenger() UserMessenger {
 	return &nullMessenger{}
 }
 
-func (nm *nullMessenger) Message(ctx context.Context, msg string) {
-	slog.DebugContext(ctx, "userMsg (null messenger)", "msg", msg)
+func (nm *nullMessenger) Message(ctx context.Context, level MessageLevel, msg string) {
+	slog.DebugContext(ctx, "userMsg (null messenger)", "level", level, "msg", msg)
 }
@@ -0,0 +1,75 @@
+package hostops
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+// messageAtEveryLevel sends one message per level and returns what m's
+// writer received.
+func messageAtEveryLevel(quiet, verbose bool) string {
+	var out bytes.Buffer
+	m := NewLeveledTerminalMessenger(&out, MessageLevelFor(quiet, verbose))
+	ctx := context.Background()
+	m.Message(ctx, MessageDebug, "debug step")
+	m.Message(ctx, MessageProgress, "progress step")
+	m.Message(ctx, MessageInfo, "info notice")
+	m.Message(ctx, MessageError, "error report")
+	return out.String()
+}
+
+func TestLeveledTerminalMessenger(t *testing.T) {
+	for _, tc := range []struct {
+		name           string
+		quiet, verbose bool
+		want, dropped  []string
+	}{
+		{
+			name:    "default",
+			want:    []string{"progress step", "info notice", "error report"},
+			dropped: []string{"debug step"},
+		},
+		{
+			name:    "quiet",
+			quiet:   true,
+			want:    []string{"info notice", "error report"},
+			dropped: []string{"debug step", "progress step"},
+		},
+		{
+			name:    "verbose",
+			verbose: true,
+			want:    []string{"debug step", "progress step", "info notice", "error report"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := messageAtEveryLevel(tc.quiet, tc.verbose)
+			for _, msg := range tc.want {
+				if !strings.Contains(got, msg) {
+					t.Errorf("output = %q, want %q", got, msg)
+				}
+			}
+			for _, msg := range tc.dropped {
+				if strings.Contains(got, msg) {
+					t.Errorf("output = %q, want %q dropped", got, msg)
+				}
+			}
+		})
+	}
+}
+
+func TestContextMessengerOverridesFallback(t *testing.T) {
+	var fallbackOut, requestOut bytes.Buffer
+	fallback := NewTerminalMessenger(&fallbackOut)
+	ctx := context.Background()
+	if got := ContextMessenger(ctx, fallback); got != fallback {
+		t.Fatalf("ContextMessenger() without one set = %v, want the fallback", got)
+	}
+
+	ctx = WithMessenger(ctx, NewTerminalMessenger(&requestOut))
+	ContextMessenger(ctx, fallback).Message(ctx, MessageProgress, "for the request")
+	if !strings.Contains(requestOut.String(), "for the request") || fallbackOut.Len() != 0 {
+		t.Fatalf("request output = %q, fallback output = %q; want the message only in the request's", requestOut.String(), fallbackOut.String())
+	}
+}
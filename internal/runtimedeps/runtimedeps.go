@@ -10,6 +10,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"strconv"
 	"strings"
@@ -17,7 +18,7 @@ import (
 	"github.com/banksean/sand/internal/applecontainer"
 	"github.com/banksean/sand/internal/hostops"
 	"github.com/banksean/sand/internal/sandtypes"
-	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/banksean/sand/internal/version"
 	"github.com/google/go-containerregistry/pkg/crane"
 )
 
@@ -54,6 +55,17 @@ const (
 
 const DefaultDNSDomain = "dev.local"
 
+// NormalizeDNSDomain returns domain without leading or trailing dots, or
+// DefaultDNSDomain if that leaves it empty. Sandbox hostnames, ssh host certs
+// and the container's --dns-domain must all agree on this value.
+func NormalizeDNSDomain(domain string) string {
+	domain = strings.Trim(domain, ".")
+	if domain == "" {
+		return DefaultDNSDomain
+	}
+	return domain
+}
+
 var ErrContainerSystemNotRunning = errors.New("container system service is not running")
 
 type containerSystem interface {
@@ -72,6 +84,9 @@ type VerifyOptions struct {
 	Stdout           io.Writer
 	PromptRemedies   bool
 	DefaultDNSDomain string
+	// Dir is the checkout the git checks look at. Empty means the working
+	// directory.
+	Dir string
 }
 
 var (
@@ -105,17 +120,14 @@ var (
 		},
 		{
 			ID:          ContainerCommand,
-			Description: fmt.Sprintf("Have https://github.com/apple/container runtime installed at version %s", AppleContainerVersion),
+			Description: fmt.Sprintf("Have https://github.com/apple/container runtime installed at version %s or newer", AppleContainerVersion),
 			Run: func(ctx context.Context, appBaseDir string, opts VerifyOptions) error {
 				version, err := systemOps.Version(ctx)
 				if err != nil {
-					return fmt.Errorf("could not get apple/container API server version: %w", err)
+					return fmt.Errorf("could not get apple/container API server version: %w. If apple/container is not installed, install %s from %s", err, AppleContainerVersion, AppleContainerInstallerURL())
 				}
 				slog.InfoContext(ctx, "verifyPrerequisites", "version", version)
-				if !strings.Contains(version, AppleContainerVersion) {
-					return fmt.Errorf("apple/container %s is required, but found %q. Install it from %s", AppleContainerVersion, version, AppleContainerInstallerURL())
-				}
-				return nil
+				return checkContainerVersion(ctx, version)
 			},
 		},
 		{
@@ -168,6 +180,7 @@ var (
 			Description: "should be invoked from a git directory",
 			Run: func(ctx context.Context, appBaseDir string, opts VerifyOptions) error {
 				gitCmd := exec.CommandContext(ctx, "git", "rev-parse", "--show-toplevel")
+				gitCmd.Dir = opts.Dir
 				out, err := gitCmd.CombinedOutput()
 				if err != nil {
 					return fmt.Errorf("%s: %s", err.Error(), strings.TrimSpace(string(out)))
@@ -180,6 +193,7 @@ var (
 			Description: "git checkout should be authenticated to origin with ssh",
 			Run: func(ctx context.Context, appBaseDir string, opts VerifyOptions) error {
 				gitCmd := exec.Command("git", "remote", "get-url", "origin")
+				gitCmd.Dir = opts.Dir
 				out, err := gitCmd.Output()
 				if err != nil {
 					return err
@@ -289,6 +303,25 @@ func VerifyWithOptions(ctx context.Context, appBaseDir string, opts VerifyOption
 	return nil
 }
 
+// Describe returns the human-readable description of the check with the given ID,
+// or the ID itself if it is not recognized.
+func Describe(checkID PrerequID) string {
+	if check, ok := diagnosticCheckMap[checkID]; ok {
+		return check.Description
+	}
+	return string(checkID)
+}
+
+// Check runs a single prerequisite check. Unlike VerifyWithOptions, the
+// returned error is not prefixed with the check's description.
+func Check(ctx context.Context, appBaseDir string, opts VerifyOptions, checkID PrerequID) error {
+	check, ok := diagnosticCheckMap[checkID]
+	if !ok {
+		return fmt.Errorf("unrecognized prerequisite check ID %q", checkID)
+	}
+	return check.Run(ctx, appBaseDir, opts)
+}
+
 func AppleContainerInstallerURL() string {
 	return fmt.Sprintf(
 		"https://github.com/apple/container/releases/download/%s/container-%s-installer-signed.pkg",
@@ -345,6 +378,40 @@ func PromptYesDefault(stdin io.Reader, stdout io.Writer, prompt string) (bool, e
 	}
 }
 
+// checkContainerVersion fails unless apiServerVersion, as the container API
+// server reports it, is AppleContainerVersion or a newer release with the same
+// major version. Newer releases are allowed with a warning since sand has only
+// been tested against AppleContainerVersion.
+func checkContainerVersion(ctx context.Context, apiServerVersion string) error {
+	want, _ := parseVersion(AppleContainerVersion)
+	got, ok := parseVersion(apiServerVersion)
+	if !ok {
+		return fmt.Errorf("apple/container %s is required, but could not find a version number in %q. Install it from %s", AppleContainerVersion, apiServerVersion, AppleContainerInstallerURL())
+	}
+	switch {
+	case got.Compare(want) < 0:
+		return fmt.Errorf("apple/container %s is required, but %s is installed and is too old. Install %s from %s", AppleContainerVersion, got, AppleContainerVersion, AppleContainerInstallerURL())
+	case got.Parts[0] != want.Parts[0]:
+		return fmt.Errorf("apple/container %s is required, but %s is installed and is a new major version sand does not support yet. Install %s from %s", AppleContainerVersion, got, AppleContainerVersion, AppleContainerInstallerURL())
+	case got.Compare(want) > 0:
+		slog.WarnContext(ctx, "checkContainerVersion: apple/container is newer than the version sand is tested with", "installed", got.String(), "tested", AppleContainerVersion)
+	}
+	return nil
+}
+
+var versionPattern = regexp.MustCompile(`\d+\.\d+\.\d+`)
+
+// parseVersion finds the first major.minor.patch version number in s, which
+// may carry other text such as "container-apiserver version 1.1.0 (build: release)".
+func parseVersion(s string) (version.Semver, bool) {
+	m := versionPattern.FindString(s)
+	if m == "" {
+		return version.Semver{}, false
+	}
+	v, err := version.ParseSemver(m)
+	return v, err == nil
+}
+
 func getMacOSMajorVersion(ctx context.Context) (int, error) {
 	cmd := exec.CommandContext(ctx, "sw_vers", "-productVersion")
 	output, err := cmd.Output()
@@ -401,7 +468,11 @@ func localImageOps() (imageInspector, error) {
 }
 
 func CheckImageDigestIsLatest(ctx context.Context, imageName, localDigest string) (bool, error) {
-	remoteDigest, err := crane.Digest(imageName, crane.WithAuth(authn.Anonymous))
+	opts := []crane.Option{crane.WithAuthFromKeychain(hostops.RegistryKeychain())}
+	if hostops.RegistryUsesHTTP(hostops.ImageRegistry(imageName)) {
+		opts = append(opts, crane.Insecure)
+	}
+	remoteDigest, err := crane.Digest(imageName, opts...)
 	if err != nil {
 		return false, fmt.Errorf("failed to get digest for %s from remote registry: %w", imageName, err)
 	}
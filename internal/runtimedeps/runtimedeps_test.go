@@ -95,6 +95,26 @@ func TestGitDirFailureIncludesDiagnosticDescription(t *testing.T) {
 	}
 }
 
+func TestGitChecksUseDir(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Chdir(t.TempDir())
+	repo := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"remote", "add", "origin", "git@github.com:banksean/sand.git"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	if err := VerifyWithOptions(context.Background(), "", VerifyOptions{Dir: repo}, GitDir, GitRemoteIsSSH); err != nil {
+		t.Fatalf("VerifyWithOptions() for a checkout outside the working directory error = %v", err)
+	}
+}
+
 func TestContainerCommandMissingUsesVersionConstantInInstallerURL(t *testing.T) {
 	replaceSystemOps(t, &fakeContainerSystem{
 		versionFunc: func(context.Context) (string, error) {
@@ -110,6 +130,7 @@ func TestContainerCommandMissingUsesVersionConstantInInstallerURL(t *testing.T)
 	for _, want := range []string{
 		"could not get apple/container API server version",
 		"not found",
+		AppleContainerInstallerURL(),
 	} {
 		if !strings.Contains(got, want) {
 			t.Fatalf("VerifyWithOptions() error = %q, want substring %q", got, want)
@@ -117,6 +138,36 @@ func TestContainerCommandMissingUsesVersionConstantInInstallerURL(t *testing.T)
 	}
 }
 
+func TestCheckContainerVersion(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		version string
+		wantErr string
+	}{
+		{name: "matching", version: AppleContainerVersion},
+		{name: "matching with build info", version: "container-apiserver version " + AppleContainerVersion + " (build: release, commit: 1a2b3c4)"},
+		{name: "newer patch", version: "1.1.7"},
+		{name: "newer minor", version: "1.4.0"},
+		{name: "too old", version: "1.0.9", wantErr: "too old"},
+		{name: "much too old", version: "0.11.0", wantErr: "too old"},
+		{name: "newer major", version: "2.0.0", wantErr: "new major version"},
+		{name: "no version number", version: "unknown", wantErr: "could not find a version number"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkContainerVersion(context.Background(), tt.version)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("checkContainerVersion(%q) error = %v, want nil", tt.version, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) || !strings.Contains(err.Error(), AppleContainerInstallerURL()) {
+				t.Fatalf("checkContainerVersion(%q) error = %v, want %q and the installer URL", tt.version, err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestContainerSystemRunningAddsStartRemedy(t *testing.T) {
 	replaceSystemOps(t, &fakeContainerSystem{
 		runningFunc: func(context.Context) error {
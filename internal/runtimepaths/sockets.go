@@ -10,6 +10,15 @@ import (
 
 const socketNameHashLen = 16
 
+// HostServicesDir is where every sandbox container sees the host-side sandd
+// sockets. Each container gets its own per-sandbox sockets bound here, so a
+// sandbox can only reach the daemon through a channel scoped to itself.
+const (
+	HostServicesDir        = "/run/host-services"
+	HostServicesHTTPSocket = HostServicesDir + "/sandd.sock"
+	HostServicesGRPCSocket = HostServicesDir + "/sandd.grpc.sock"
+)
+
 func SocketRoot() string {
 	return filepath.Join("/tmp", fmt.Sprintf("sand-%d", os.Getuid()))
 }
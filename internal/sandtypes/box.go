@@ -1,6 +1,10 @@
 package sandtypes
 
 import (
+	"fmt"
+	"net"
+	"slices"
+	"strings"
 	"time"
 )
 
@@ -27,6 +31,9 @@ type Box struct {
 	ContainerID string
 	// ContainerBootstrapped tracks whether first-start hooks have successfully run for the current container.
 	ContainerBootstrapped bool
+	// StartHooksRan tracks whether start hooks have run since the container last started,
+	// so starting an already-running sandbox (e.g. a second `sand shell`) doesn't repeat them.
+	StartHooksRan bool
 	// HostOriginDir is the origin of the sandbox, from which we clone its contents
 	HostOriginDir string
 	// SandboxWorkDir is the host OS filesystem path containing the sandbox's c-o-w clone of hostOriginDir.
@@ -35,10 +42,20 @@ type Box struct {
 	TrashWorkDir string
 	// DeletedAt is set when State is "deleted".
 	DeletedAt time.Time
+	// CreatedAt is when the sandbox was first saved.
+	CreatedAt time.Time
+	// LastUsedAt is when the sandbox was last shelled into or exec'd against.
+	// Zero if it has never been used.
+	LastUsedAt time.Time
 	// ImageName is the name of the container image
 	ImageName string
+	// ImageDigest is the digest of the local ImageName image when the sandbox
+	// was created or moved to ImageName.
+	ImageDigest string
 	// DNSDomain is the dns domain for the sandbox's network
 	DNSDomain string
+	// DNS overrides the resolver configuration of the sandbox's container.
+	DNS DNSConfig
 	// EnvFile is the host filesystem path to the sandbox-associated env file.
 	// Agent requirement resolution may read it at launch time; plain shell/exec
 	// paths only use it when explicitly requested as project env.
@@ -47,12 +64,37 @@ type Box struct {
 	// When non-empty, this overrides the default allowlist baked into the init image.
 	AllowedDomains []string
 	// Mounts defines bind mounts that should be attached when creating the container.
+	// It is persisted so a recreated container gets the same mounts as the original.
 	Mounts []MountSpec
 	// MountRequests records user-requested direct and cloned bind mount metadata.
 	MountRequests []MountRequest
+	// Labels are user-assigned key/value tags for grouping and filtering sandboxes.
+	// They are also set as labels on the sandbox's container.
+	Labels map[string]string
+	// Shell is the shell `sand shell` and `sand new` exec in the container when
+	// no --shell flag is given. Empty means probe for a default.
+	Shell string
+	// Network is the container network the sandbox is attached to. Empty means
+	// the container system's default network.
+	Network string
+	// NoDotfiles records that the host user's dotfiles were left out of the
+	// sandbox, so its container gets no /dotfiles mount.
+	NoDotfiles bool
+	// NoSSH records that the sandbox has no ssh host keys or sshd. It can
+	// still be shelled into, since shells use exec.
+	NoSSH bool
+	// AllowEmulation records that the sandbox may run an image built for
+	// another architecture than the host's, under emulation.
+	AllowEmulation bool
+	// IgnoreOnCreateErrors records that a failing oncreate script is reported
+	// as a warning instead of failing the container's first start.
+	IgnoreOnCreateErrors bool
+	// Publish holds the sandbox's container port mappings, as
+	// [host-ip:]host-port:container-port[/proto] specs.
+	Publish []string
 	// SharedCacheMounts holds additional host-managed shared caches to mount into the container.
 	// This is runtime-only metadata; it is not currently persisted in the DB.
-	SharedCacheMounts SharedCacheMounts
+	SharedCacheMounts SharedCacheMounts `json:"-"`
 	// CPUs is the number of CPUs to allocate to the sandbox
 	CPUs int
 	// MemoryMB is the amount of memory in MB to allocate to the sandbox
@@ -65,6 +107,10 @@ type Box struct {
 	// a sandbox container instance if the sandbox's work dir is not available.
 	SandboxWorkDirError   string
 	SandboxContainerError string
+	// SandboxResourceWarning is set, like the errors above, when a running
+	// container's memory use is close to its limit, so that it can be flagged
+	// before the kernel starts killing processes in it. In-memory only.
+	SandboxResourceWarning string
 	// Username is the name of the default user to create for the container
 	Username string
 	// Uid is the uid of the default user to create for the container
@@ -75,6 +121,58 @@ type Box struct {
 	Container          *Container
 }
 
+// DNSConfig is the resolver configuration a sandbox's container is created
+// with, as opposed to the DNS a running container reports. The zero value
+// keeps the runtime's defaults.
+type DNSConfig struct {
+	// Nameservers are resolver IP addresses, in the order they are tried.
+	Nameservers []string `json:"nameservers,omitempty"`
+	// SearchDomains are searched for names that aren't fully qualified.
+	SearchDomains []string `json:"searchDomains,omitempty"`
+	// Options are resolv.conf options, such as ndots:2.
+	Options []string `json:"options,omitempty"`
+	// Disabled leaves the container's resolver unconfigured by the runtime;
+	// no other field may be set with it.
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// IsZero reports whether c keeps the runtime's defaults.
+func (c DNSConfig) IsZero() bool {
+	return len(c.Nameservers) == 0 && len(c.SearchDomains) == 0 && len(c.Options) == 0 && !c.Disabled
+}
+
+// Clone returns a copy of c that shares no slices with it.
+func (c DNSConfig) Clone() DNSConfig {
+	c.Nameservers = slices.Clone(c.Nameservers)
+	c.SearchDomains = slices.Clone(c.SearchDomains)
+	c.Options = slices.Clone(c.Options)
+	return c
+}
+
+// Validate checks that c's nameservers are IP addresses and that it doesn't
+// both disable DNS and configure it.
+func (c DNSConfig) Validate() error {
+	if c.Disabled && (len(c.Nameservers) > 0 || len(c.SearchDomains) > 0 || len(c.Options) > 0) {
+		return fmt.Errorf("disabling dns can't be combined with nameservers, search domains or options")
+	}
+	for _, ns := range c.Nameservers {
+		if net.ParseIP(ns) == nil {
+			return fmt.Errorf("dns nameserver %q is not an IP address", ns)
+		}
+	}
+	for _, domain := range c.SearchDomains {
+		if domain == "" || strings.ContainsAny(domain, " \t") {
+			return fmt.Errorf("dns search domain %q is not a domain name", domain)
+		}
+	}
+	for _, opt := range c.Options {
+		if opt == "" || strings.ContainsAny(opt, " \t") {
+			return fmt.Errorf("dns option %q must be a single resolv.conf option", opt)
+		}
+	}
+	return nil
+}
+
 type SharedCacheConfig struct {
 	Mise      bool `json:"mise,omitempty"`
 	APK       bool `json:"apk,omitempty"`
@@ -116,3 +214,12 @@ type GitDetails struct {
 	Ahead        int
 	Behind       int
 }
+
+// ResyncResult lists the workspace-relative paths a workspace resync touched.
+type ResyncResult struct {
+	// Copied are host files that changed since the last sync and were copied into the clone.
+	Copied []string
+	// Conflicts are host files that changed since the last sync but were also
+	// modified inside the sandbox, and so were left as they are in the clone.
+	Conflicts []string
+}
@@ -53,6 +53,8 @@ type ContainerStats struct {
 	NetworkRxBytes   int    `json:"networkRxBytes"`
 	NetworkTxBytes   int    `json:"networkTxBytes"`
 	NumProcesses     int    `json:"numProcesses"`
+	// UptimeSeconds is how long the container has been running, or 0 if unknown.
+	UptimeSeconds int `json:"uptimeSeconds,omitempty"`
 }
 
 type Mount struct {
@@ -0,0 +1,303 @@
+package sandtypes
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// CopyIn copies the file or directory at hostPath into b's container as
+// containerPath, streaming a tar archive to tar in the container through exec.
+// Files keep their host uid and gid, which sandboxes give their user. exec
+// must not copy tar's output anywhere else, such as a progress writer.
+func (b *Box) CopyIn(ctx context.Context, exec HookStreamer, hostPath, containerPath string) error {
+	dir, name, err := splitContainerPath(containerPath)
+	if err != nil {
+		return fmt.Errorf("sandbox %s: copy %s in: %w", b.ID, hostPath, err)
+	}
+	if _, err := os.Lstat(hostPath); err != nil {
+		return fmt.Errorf("sandbox %s: copy %s in: %w", b.ID, hostPath, err)
+	}
+	if _, err := exec.Exec(ctx, "mkdir", "-p", dir); err != nil {
+		return fmt.Errorf("sandbox %s: mkdir %s: %w", b.ID, dir, err)
+	}
+
+	pr, pw := io.Pipe()
+	archived := make(chan error, 1)
+	go func() {
+		err := writeTar(pw, hostPath, name)
+		pw.CloseWithError(err)
+		archived <- err
+	}()
+	var stderr bytes.Buffer
+	execErr := exec.ExecStreamInput(ctx, pr, io.Discard, &stderr, "tar", "-x", "-f", "-", "-C", dir)
+	// Unblock the archive writer if tar exited without reading everything.
+	pr.Close()
+	if err := <-archived; err != nil && !errors.Is(err, io.ErrClosedPipe) {
+		return fmt.Errorf("sandbox %s: archive %s: %w", b.ID, hostPath, err)
+	}
+	if execErr != nil {
+		return fmt.Errorf("sandbox %s: copy %s in to %s: %w%s", b.ID, hostPath, containerPath, execErr, stderrSuffix(&stderr))
+	}
+	return nil
+}
+
+// CopyOut copies the file or directory at containerPath in b's container to
+// hostPath, extracting the tar archive that tar in the container streams
+// through exec. Entries that would land outside hostPath are refused. exec
+// must hand tar's stdout only to CopyOut; an executor that also copies it to a
+// progress writer would print the raw archive there.
+func (b *Box) CopyOut(ctx context.Context, exec HookStreamer, containerPath, hostPath string) error {
+	dir, name, err := splitContainerPath(containerPath)
+	if err != nil {
+		return fmt.Errorf("sandbox %s: copy %s out: %w", b.ID, containerPath, err)
+	}
+
+	pr, pw := io.Pipe()
+	extracted := make(chan error, 1)
+	go func() {
+		err := extractTar(pr, name, hostPath)
+		// Stop tar in the container on failure; otherwise drain the padding
+		// after the archive's end so its last writes don't block.
+		pr.CloseWithError(err)
+		if err == nil {
+			io.Copy(io.Discard, pr) //nolint:errcheck
+		}
+		extracted <- err
+	}()
+	var stderr bytes.Buffer
+	execErr := exec.ExecStream(ctx, pw, &stderr, "tar", "-c", "-f", "-", "-C", dir, name)
+	pw.CloseWithError(execErr)
+	extractErr := <-extracted
+	// When tar fails, extraction reads its error from the pipe too; report
+	// tar's. Otherwise a failed extraction is the cause of any tar error.
+	if execErr != nil && (extractErr == nil || errors.Is(extractErr, execErr)) {
+		return fmt.Errorf("sandbox %s: copy %s out: %w%s", b.ID, containerPath, execErr, stderrSuffix(&stderr))
+	}
+	if extractErr != nil {
+		return fmt.Errorf("sandbox %s: copy %s out to %s: %w", b.ID, containerPath, hostPath, extractErr)
+	}
+	return nil
+}
+
+// splitContainerPath splits an absolute container path into the directory tar
+// runs in and the name of the entry it archives or extracts.
+func splitContainerPath(containerPath string) (dir, name string, err error) {
+	if !path.IsAbs(containerPath) {
+		return "", "", fmt.Errorf("container path %q is not absolute", containerPath)
+	}
+	clean := path.Clean(containerPath)
+	if clean == "/" {
+		return "", "", fmt.Errorf("container path %q names no file", containerPath)
+	}
+	return path.Dir(clean), path.Base(clean), nil
+}
+
+func stderrSuffix(stderr *bytes.Buffer) string {
+	if out := strings.TrimSpace(stderr.String()); out != "" {
+		return ": " + out
+	}
+	return ""
+}
+
+// writeTar archives the tree at hostPath with its root entry renamed to root.
+// Symlinks are archived as links, not followed.
+func writeTar(w io.Writer, hostPath, root string) error {
+	tw := tar.NewWriter(w)
+	err := filepath.Walk(hostPath, func(p string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(hostPath, p)
+		if err != nil {
+			return err
+		}
+		link := ""
+		if info.Mode()&fs.ModeSymlink != 0 {
+			if link, err = os.Readlink(p); err != nil {
+				return err
+			}
+		}
+		hdr, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		hdr.Name = path.Join(root, filepath.ToSlash(rel))
+		if info.IsDir() {
+			hdr.Name += "/"
+		}
+		// Numeric ids only, so the host's user names don't pick the owner.
+		hdr.Uname, hdr.Gname = "", ""
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+// extractTar extracts an archive whose entries are root or below it, writing
+// root as dest. Entries below root are written through an os.Root of dest, so
+// a symlink the archive made, or one already in dest, can't carry a write out
+// of it. Hard links are made once the files they link to are written, and
+// symlinks last, so no entry is written through one. Devices, FIFOs and other
+// special files are refused rather than skipped.
+func extractTar(r io.Reader, root, dest string) error {
+	type symlink struct{ name, linkname string }
+	var symlinks, hardlinks []symlink
+	var destRoot *os.Root
+	defer func() {
+		if destRoot != nil {
+			destRoot.Close()
+		}
+	}()
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		name := path.Clean(hdr.Name)
+		rel, ok := strings.CutPrefix(name, root)
+		if !ok || (rel != "" && !strings.HasPrefix(rel, "/")) {
+			return fmt.Errorf("archive entry %q is outside %s", hdr.Name, root)
+		}
+		if rel == "" {
+			// root itself is dest, which the caller named.
+			switch hdr.Typeflag {
+			case tar.TypeDir:
+				if err := os.MkdirAll(dest, hdr.FileInfo().Mode().Perm()|0o700); err != nil {
+					return err
+				}
+			case tar.TypeReg:
+				if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+					return err
+				}
+				f, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, hdr.FileInfo().Mode().Perm())
+				if err != nil {
+					return err
+				}
+				if err := writeEntry(f, tr); err != nil {
+					return err
+				}
+			case tar.TypeSymlink:
+				symlinks = append(symlinks, symlink{linkname: hdr.Linkname})
+			default:
+				return unsupportedEntry(hdr)
+			}
+			continue
+		}
+		if destRoot == nil {
+			if err := os.MkdirAll(dest, 0o755); err != nil {
+				return err
+			}
+			if destRoot, err = os.OpenRoot(dest); err != nil {
+				return err
+			}
+		}
+		rel = filepath.FromSlash(strings.TrimPrefix(rel, "/"))
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := destRoot.MkdirAll(rel, hdr.FileInfo().Mode().Perm()|0o700); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := destRoot.MkdirAll(filepath.Dir(rel), 0o755); err != nil {
+				return err
+			}
+			f, err := destRoot.OpenFile(rel, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, hdr.FileInfo().Mode().Perm())
+			if err != nil {
+				return err
+			}
+			if err := writeEntry(f, tr); err != nil {
+				return err
+			}
+		case tar.TypeLink:
+			// A hard link names another entry of the archive, which must
+			// be below root too.
+			target, ok := strings.CutPrefix(path.Clean(hdr.Linkname), root+"/")
+			if !ok {
+				return fmt.Errorf("archive entry %q links to %q, outside %s", hdr.Name, hdr.Linkname, root)
+			}
+			hardlinks = append(hardlinks, symlink{name: rel, linkname: filepath.FromSlash(target)})
+		case tar.TypeSymlink:
+			symlinks = append(symlinks, symlink{name: rel, linkname: hdr.Linkname})
+		default:
+			return unsupportedEntry(hdr)
+		}
+	}
+	for _, l := range hardlinks {
+		if err := destRoot.MkdirAll(filepath.Dir(l.name), 0o755); err != nil {
+			return err
+		}
+		if err := destRoot.Link(l.linkname, l.name); err != nil {
+			return err
+		}
+	}
+	for _, l := range symlinks {
+		if l.name == "" {
+			if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+				return err
+			}
+			if err := os.Symlink(l.linkname, dest); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := destRoot.MkdirAll(filepath.Dir(l.name), 0o755); err != nil {
+			return err
+		}
+		if err := destRoot.Symlink(l.linkname, l.name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeEntry writes the current archive entry to f and closes it.
+func writeEntry(f *os.File, tr *tar.Reader) error {
+	_, err := io.Copy(f, tr)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// unsupportedEntry is the error for an archive entry extractTar can't make.
+func unsupportedEntry(hdr *tar.Header) error {
+	kind := fmt.Sprintf("an entry of type %q", hdr.Typeflag)
+	switch hdr.Typeflag {
+	case tar.TypeChar:
+		kind = "a character device"
+	case tar.TypeBlock:
+		kind = "a block device"
+	case tar.TypeFifo:
+		kind = "a FIFO"
+	case tar.TypeLink:
+		kind = "a hard link"
+	}
+	return fmt.Errorf("archive entry %q is %s, which can't be copied", hdr.Name, kind)
+}
@@ -0,0 +1,275 @@
+package sandtypes
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// tarStreamer stands in for a container: it records commands, keeps what is
+// piped to it, and streams out a fixed archive.
+type tarStreamer struct {
+	calls   []string
+	stdin   []byte
+	stdout  []byte
+	execErr error
+}
+
+func (s *tarStreamer) Exec(ctx context.Context, cmd string, args ...string) (string, error) {
+	s.calls = append(s.calls, strings.Join(append([]string{cmd}, args...), " "))
+	return "", nil
+}
+
+func (s *tarStreamer) ExecStream(ctx context.Context, stdout, stderr io.Writer, cmd string, args ...string) error {
+	s.calls = append(s.calls, "stream:"+strings.Join(append([]string{cmd}, args...), " "))
+	if s.execErr != nil {
+		io.WriteString(stderr, "tar: no such file")
+		return s.execErr
+	}
+	_, err := stdout.Write(s.stdout)
+	return err
+}
+
+func (s *tarStreamer) ExecStreamInput(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer, cmd string, args ...string) error {
+	s.calls = append(s.calls, "stream-input:"+strings.Join(append([]string{cmd}, args...), " "))
+	data, err := io.ReadAll(stdin)
+	s.stdin = data
+	if err != nil {
+		return err
+	}
+	return s.execErr
+}
+
+// tarEntries lists an archive's entries as name -> contents, with "dir" for
+// directories and "-> target" for symlinks.
+func tarEntries(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	entries := map[string]string{}
+	tr := tar.NewReader(bytes.NewReader(data))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return entries
+		}
+		if err != nil {
+			t.Fatalf("reading archive: %v", err)
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			entries[hdr.Name] = "dir"
+		case tar.TypeSymlink:
+			entries[hdr.Name] = "-> " + hdr.Linkname
+		default:
+			body, _ := io.ReadAll(tr)
+			entries[hdr.Name] = string(body)
+		}
+	}
+}
+
+// tarEntry is one entry for buildTar; body is a regular file's contents.
+type tarEntry struct {
+	hdr  tar.Header
+	body string
+}
+
+func buildTar(t *testing.T, entries ...tarEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, e := range entries {
+		e.hdr.Size = int64(len(e.body))
+		if err := tw.WriteHeader(&e.hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.WriteString(tw, e.body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func regEntry(name, body string) tarEntry {
+	return tarEntry{hdr: tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0o644}, body: body}
+}
+
+func TestBoxCopyInStreamsTarOfHostTree(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "project")
+	if err := os.MkdirAll(filepath.Join(src, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, body := range map[string]string{"a.txt": "alpha", "sub/b.txt": "beta"} {
+		if err := os.WriteFile(filepath.Join(src, name), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink("a.txt", filepath.Join(src, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	exec := &tarStreamer{}
+	box := &Box{ID: "sb-1"}
+	if err := box.CopyIn(context.Background(), exec, src, "/app/dest/"); err != nil {
+		t.Fatalf("CopyIn() error = %v", err)
+	}
+	wantCalls := []string{"mkdir -p /app", "stream-input:tar -x -f - -C /app"}
+	if !reflect.DeepEqual(exec.calls, wantCalls) {
+		t.Fatalf("calls = %#v, want %#v", exec.calls, wantCalls)
+	}
+	want := map[string]string{
+		"dest/":          "dir",
+		"dest/a.txt":     "alpha",
+		"dest/link":      "-> a.txt",
+		"dest/sub/":      "dir",
+		"dest/sub/b.txt": "beta",
+	}
+	if got := tarEntries(t, exec.stdin); !reflect.DeepEqual(got, want) {
+		t.Fatalf("archive = %#v, want %#v", got, want)
+	}
+}
+
+func TestBoxCopyInSingleFile(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(src, []byte("hello"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	exec := &tarStreamer{}
+	if err := (&Box{ID: "sb-1"}).CopyIn(context.Background(), exec, src, "/tmp/renamed.txt"); err != nil {
+		t.Fatalf("CopyIn() error = %v", err)
+	}
+	if got, want := tarEntries(t, exec.stdin), map[string]string{"renamed.txt": "hello"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("archive = %#v, want %#v", got, want)
+	}
+}
+
+func TestBoxCopyOutExtractsContainerTar(t *testing.T) {
+	exec := &tarStreamer{stdout: buildTar(t,
+		tarEntry{hdr: tar.Header{Name: "logs/", Typeflag: tar.TypeDir, Mode: 0o755}},
+		regEntry("logs/app.log", "started\n"),
+		regEntry("logs/nested/err.log", "boom\n"),
+		tarEntry{hdr: tar.Header{Name: "logs/latest", Typeflag: tar.TypeSymlink, Linkname: "app.log"}},
+	)}
+	dest := filepath.Join(t.TempDir(), "copied")
+	if err := (&Box{ID: "sb-1"}).CopyOut(context.Background(), exec, "/var/logs", dest); err != nil {
+		t.Fatalf("CopyOut() error = %v", err)
+	}
+	if want := []string{"stream:tar -c -f - -C /var logs"}; !reflect.DeepEqual(exec.calls, want) {
+		t.Fatalf("calls = %#v, want %#v", exec.calls, want)
+	}
+	for name, want := range map[string]string{"app.log": "started\n", "nested/err.log": "boom\n", "latest": "started\n"} {
+		got, err := os.ReadFile(filepath.Join(dest, name))
+		if err != nil || string(got) != want {
+			t.Errorf("%s = %q (%v), want %q", name, got, err, want)
+		}
+	}
+	if link, err := os.Readlink(filepath.Join(dest, "latest")); err != nil || link != "app.log" {
+		t.Errorf("latest links to %q (%v), want app.log", link, err)
+	}
+}
+
+func TestBoxCopyOutRefusesEntriesOutsideTheCopiedPath(t *testing.T) {
+	exec := &tarStreamer{stdout: buildTar(t,
+		regEntry("logs/ok.log", "fine"),
+		regEntry("logs/../../escaped", "bad"),
+	)}
+	parent := t.TempDir()
+	dest := filepath.Join(parent, "work", "copied")
+	err := (&Box{ID: "sb-1"}).CopyOut(context.Background(), exec, "/var/logs", dest)
+	if err == nil || !strings.Contains(err.Error(), "outside logs") {
+		t.Fatalf("CopyOut() error = %v, want an outside-path error", err)
+	}
+	if _, err := os.Lstat(filepath.Join(parent, "escaped")); !os.IsNotExist(err) {
+		t.Fatalf("escaped entry written next to the destination (stat error = %v)", err)
+	}
+}
+
+func TestBoxCopyOutWritesNothingThroughAnArchiveSymlink(t *testing.T) {
+	for _, entry := range []tarEntry{
+		regEntry("logs/a/b", "bad"),
+		{hdr: tar.Header{Name: "logs/a/b", Typeflag: tar.TypeSymlink, Linkname: "bad"}},
+	} {
+		outside := t.TempDir()
+		exec := &tarStreamer{stdout: buildTar(t,
+			tarEntry{hdr: tar.Header{Name: "logs/", Typeflag: tar.TypeDir, Mode: 0o755}},
+			tarEntry{hdr: tar.Header{Name: "logs/a", Typeflag: tar.TypeSymlink, Linkname: outside}},
+			entry,
+		)}
+		dest := filepath.Join(t.TempDir(), "copied")
+		if err := (&Box{ID: "sb-1"}).CopyOut(context.Background(), exec, "/var/logs", dest); err == nil {
+			t.Errorf("CopyOut() of logs/a -> %s then %s error = nil, want a refusal", outside, entry.hdr.Name)
+		}
+		if _, err := os.Lstat(filepath.Join(outside, "b")); !os.IsNotExist(err) {
+			t.Errorf("%s written through the archive's symlink (stat error = %v)", entry.hdr.Name, err)
+		}
+	}
+}
+
+func TestBoxCopyErrorsNameTheSandbox(t *testing.T) {
+	ctx := context.Background()
+	box := &Box{ID: "sb-err"}
+	failing := &tarStreamer{execErr: errors.New("exit status 1")}
+
+	err := box.CopyOut(ctx, failing, "/missing", filepath.Join(t.TempDir(), "out"))
+	if err == nil || !strings.Contains(err.Error(), "sandbox sb-err") || !strings.Contains(err.Error(), "tar: no such file") {
+		t.Errorf("CopyOut() error = %v, want the sandbox ID and tar's stderr", err)
+	}
+	src := filepath.Join(t.TempDir(), "f")
+	if err := os.WriteFile(src, []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := box.CopyIn(ctx, failing, src, "/app/f"); err == nil || !strings.Contains(err.Error(), "sandbox sb-err") {
+		t.Errorf("CopyIn() error = %v, want the sandbox ID", err)
+	}
+	if err := box.CopyIn(ctx, &tarStreamer{}, src, "relative/f"); err == nil || !strings.Contains(err.Error(), "not absolute") {
+		t.Errorf("CopyIn() error = %v, want a relative path error", err)
+	}
+}
+
+func TestBoxCopyOutRecreatesHardLinks(t *testing.T) {
+	exec := &tarStreamer{stdout: buildTar(t,
+		tarEntry{hdr: tar.Header{Name: "logs/", Typeflag: tar.TypeDir, Mode: 0o755}},
+		regEntry("logs/app.log", "started\n"),
+		tarEntry{hdr: tar.Header{Name: "logs/old/app.log", Typeflag: tar.TypeLink, Linkname: "logs/app.log"}},
+	)}
+	dest := filepath.Join(t.TempDir(), "copied")
+	if err := (&Box{ID: "sb-1"}).CopyOut(context.Background(), exec, "/var/logs", dest); err != nil {
+		t.Fatalf("CopyOut() error = %v", err)
+	}
+	orig, err := os.Stat(filepath.Join(dest, "app.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	linked, err := os.Stat(filepath.Join(dest, "old", "app.log"))
+	if err != nil {
+		t.Fatalf("hard link not copied: %v", err)
+	}
+	if !os.SameFile(orig, linked) {
+		t.Errorf("old/app.log is a separate file, want a hard link to app.log")
+	}
+}
+
+func TestBoxCopyOutRefusesLinksAndSpecialFilesItCantMake(t *testing.T) {
+	for _, entry := range []tarEntry{
+		{hdr: tar.Header{Name: "logs/passwd", Typeflag: tar.TypeLink, Linkname: "etc/passwd"}},
+		{hdr: tar.Header{Name: "logs/pipe", Typeflag: tar.TypeFifo, Mode: 0o644}},
+		{hdr: tar.Header{Name: "logs/tty", Typeflag: tar.TypeChar, Mode: 0o644}},
+	} {
+		exec := &tarStreamer{stdout: buildTar(t,
+			tarEntry{hdr: tar.Header{Name: "logs/", Typeflag: tar.TypeDir, Mode: 0o755}},
+			entry,
+		)}
+		dest := filepath.Join(t.TempDir(), "copied")
+		if err := (&Box{ID: "sb-1"}).CopyOut(context.Background(), exec, "/var/logs", dest); err == nil || !strings.Contains(err.Error(), entry.hdr.Name) {
+			t.Errorf("CopyOut() of %s error = %v, want it refused", entry.hdr.Name, err)
+		}
+	}
+}
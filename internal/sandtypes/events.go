@@ -0,0 +1,24 @@
+package sandtypes
+
+import "time"
+
+// SandboxEventType names a sandbox lifecycle transition reported by the daemon.
+type SandboxEventType string
+
+const (
+	SandboxCreated   SandboxEventType = "created"
+	SandboxStarted   SandboxEventType = "started"
+	SandboxStopped   SandboxEventType = "stopped"
+	SandboxRemoved   SandboxEventType = "removed"
+	SandboxSyncError SandboxEventType = "sync-error"
+)
+
+// SandboxEvent is emitted by the daemon as a sandbox moves through its lifecycle.
+type SandboxEvent struct {
+	Type        SandboxEventType `json:"type"`
+	SandboxID   string           `json:"sandbox_id"`
+	SandboxName string           `json:"sandbox_name"`
+	Time        time.Time        `json:"time"`
+	// Error describes what went wrong for error events such as SandboxSyncError.
+	Error string `json:"error,omitempty"`
+}
@@ -0,0 +1,67 @@
+package sandtypes
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// reservedLabelPrefix marks container labels that sand sets for its own use.
+const reservedLabelPrefix = "sand."
+
+// ParseLabels parses repeated key=value label flags. A flag without "=" sets
+// the label to the empty string. Later flags override earlier ones.
+func ParseLabels(specs []string) (map[string]string, error) {
+	if len(specs) == 0 {
+		return nil, nil
+	}
+	labels := make(map[string]string, len(specs))
+	for _, spec := range specs {
+		key, value, _ := strings.Cut(spec, "=")
+		if err := ValidateLabelKey(key); err != nil {
+			return nil, err
+		}
+		labels[key] = value
+	}
+	return labels, nil
+}
+
+// ValidateLabelKey reports whether key can be used as a sandbox label.
+func ValidateLabelKey(key string) error {
+	if key == "" {
+		return fmt.Errorf("label key is required")
+	}
+	if strings.HasPrefix(key, reservedLabelPrefix) {
+		return fmt.Errorf("label key %q uses the reserved %q prefix", key, reservedLabelPrefix)
+	}
+	if strings.IndexFunc(key, unicode.IsSpace) >= 0 || strings.ContainsAny(key, ",=") {
+		return fmt.Errorf("label key %q must not contain whitespace, ',' or '='", key)
+	}
+	return nil
+}
+
+// LabelSelector matches sandboxes by label. An empty Value with HasValue
+// false matches any sandbox that has the label at all.
+type LabelSelector struct {
+	Key      string
+	Value    string
+	HasValue bool
+}
+
+// ParseLabelSelector parses "key" or "key=value".
+func ParseLabelSelector(s string) (LabelSelector, error) {
+	key, value, hasValue := strings.Cut(s, "=")
+	if err := ValidateLabelKey(key); err != nil {
+		return LabelSelector{}, err
+	}
+	return LabelSelector{Key: key, Value: value, HasValue: hasValue}, nil
+}
+
+// Matches reports whether labels satisfies the selector.
+func (s LabelSelector) Matches(labels map[string]string) bool {
+	value, ok := labels[s.Key]
+	if !ok {
+		return false
+	}
+	return !s.HasValue || value == s.Value
+}
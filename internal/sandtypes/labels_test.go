@@ -0,0 +1,52 @@
+package sandtypes
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLabels(t *testing.T) {
+	got, err := ParseLabels([]string{"project=sand", "team=", "wip", "project=other"})
+	if err != nil {
+		t.Fatalf("ParseLabels() error = %v", err)
+	}
+	want := map[string]string{"project": "other", "team": "", "wip": ""}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseLabels() = %v, want %v", got, want)
+	}
+}
+
+func TestParseLabelsRejectsInvalidKeys(t *testing.T) {
+	for _, spec := range []string{"=value", "sand.id=x", "my key=x", "a,b=x"} {
+		if _, err := ParseLabels([]string{spec}); err == nil {
+			t.Errorf("ParseLabels(%q) error = nil, want invalid key error", spec)
+		}
+	}
+}
+
+func TestLabelSelectorMatches(t *testing.T) {
+	labels := map[string]string{"project": "sand", "wip": ""}
+	tests := []struct {
+		selector string
+		want     bool
+	}{
+		{selector: "project", want: true},
+		{selector: "project=sand", want: true},
+		{selector: "project=other", want: false},
+		{selector: "wip", want: true},
+		{selector: "wip=", want: true},
+		{selector: "missing", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			selector, err := ParseLabelSelector(tt.selector)
+			if err != nil {
+				t.Fatalf("ParseLabelSelector() error = %v", err)
+			}
+			if got := selector.Matches(labels); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package sandtypes
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/goombaio/namegenerator"
+)
+
+// maxSandboxNameLen is the DNS label limit; names become ssh hostnames.
+const maxSandboxNameLen = 63
+
+// sandboxNameRe enforces DNS label rules: 1-63 chars, lowercase alnum or hyphens,
+// not starting or ending with a hyphen. Names and IDs end up in clone paths,
+// container names, git remote names and ssh hostnames, so this keeps them
+// safe in all of those places.
+var sandboxNameRe = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// maxNewSandboxNameAttempts bounds how many generated names NewSandboxName
+// tries before giving up.
+const maxNewSandboxNameAttempts = 20
+
+// NewSandboxName returns a random adjective-noun name, such as "hidden-bush",
+// that taken reports as unused.
+func NewSandboxName(taken func(name string) (bool, error)) (string, error) {
+	gen := namegenerator.NewNameGenerator(time.Now().UTC().UnixNano())
+	for range maxNewSandboxNameAttempts {
+		name := gen.Generate()
+		used, err := taken(name)
+		if err != nil {
+			return "", fmt.Errorf("checking sandbox name %q: %w", name, err)
+		}
+		if !used {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("no unused sandbox name found after %d tries; give the sandbox a name", maxNewSandboxNameAttempts)
+}
+
+// IsValidSandboxID reports whether id is safe to use as a sandbox name or ID.
+func IsValidSandboxID(id string) bool {
+	return sandboxNameRe.MatchString(id)
+}
+
+// ValidateSandboxName returns an error describing why name can't be used for
+// a sandbox, suggesting a sanitized alternative when there is one.
+func ValidateSandboxName(name string) error {
+	if IsValidSandboxID(name) {
+		return nil
+	}
+	err := fmt.Errorf("sandbox name %q is invalid: must be 1-63 lowercase alphanumeric characters or hyphens, not starting or ending with a hyphen", name)
+	if suggestion := SanitizeID(name); suggestion != "" {
+		err = fmt.Errorf("%w (try %q)", err, suggestion)
+	}
+	return err
+}
+
+// SanitizeID turns s into a valid sandbox name by lowercasing it, replacing
+// each run of other characters with a hyphen, and trimming the result to 63
+// characters. It returns "" if nothing usable is left.
+func SanitizeID(s string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(s) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			hyphen = false
+			continue
+		}
+		if !hyphen && b.Len() > 0 {
+			b.WriteByte('-')
+			hyphen = true
+		}
+	}
+	out := b.String()
+	if len(out) > maxSandboxNameLen {
+		out = out[:maxSandboxNameLen]
+	}
+	return strings.TrimRight(out, "-")
+}
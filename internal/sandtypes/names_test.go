@@ -0,0 +1,116 @@
+package sandtypes
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsValidSandboxID(t *testing.T) {
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{id: "my-sandbox", want: true},
+		{id: "a", want: true},
+		{id: "0b5c9c0e-3f0a-4b7e-9d6c-2f1a8e7d6c5b", want: true},
+		{id: strings.Repeat("a", 63), want: true},
+		{id: "", want: false},
+		{id: strings.Repeat("a", 64), want: false},
+		{id: "../escape", want: false},
+		{id: "a/b", want: false},
+		{id: "..", want: false},
+		{id: "my sandbox", want: false},
+		{id: "MySandbox", want: false},
+		{id: "my.sandbox", want: false},
+		{id: "-leading", want: false},
+		{id: "trailing-", want: false},
+		{id: "under_score", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			if got := IsValidSandboxID(tt.id); got != tt.want {
+				t.Errorf("IsValidSandboxID(%q) = %v, want %v", tt.id, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizeID(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "my-sandbox", want: "my-sandbox"},
+		{in: "My Sandbox", want: "my-sandbox"},
+		{in: "../../etc/passwd", want: "etc-passwd"},
+		{in: "feature/JIRA-123_fix", want: "feature-jira-123-fix"},
+		{in: "--dots...and--dashes--", want: "dots-and-dashes"},
+		{in: "../..", want: ""},
+		{in: strings.Repeat("ab", 40), want: strings.Repeat("ab", 31) + "a"},
+		{in: strings.Repeat("a", 62) + "-b", want: strings.Repeat("a", 62)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got := SanitizeID(tt.in)
+			if got != tt.want {
+				t.Errorf("SanitizeID(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+			if got != "" && !IsValidSandboxID(got) {
+				t.Errorf("SanitizeID(%q) = %q, which is not a valid ID", tt.in, got)
+			}
+		})
+	}
+}
+
+func TestValidateSandboxNameSuggestsSanitizedName(t *testing.T) {
+	if err := ValidateSandboxName("ok-name"); err != nil {
+		t.Fatalf("ValidateSandboxName(ok-name) error = %v", err)
+	}
+	err := ValidateSandboxName("My Sandbox")
+	if err == nil || !strings.Contains(err.Error(), `try "my-sandbox"`) {
+		t.Fatalf("ValidateSandboxName(My Sandbox) error = %v, want a suggestion", err)
+	}
+	if err := ValidateSandboxName("../.."); err == nil || strings.Contains(err.Error(), "try") {
+		t.Fatalf("ValidateSandboxName(../..) error = %v, want an error without a suggestion", err)
+	}
+}
+
+func TestNewSandboxNameRetriesOnCollision(t *testing.T) {
+	var tried []string
+	name, err := NewSandboxName(func(name string) (bool, error) {
+		tried = append(tried, name)
+		// The first candidate is taken; every later one is free.
+		return len(tried) == 1, nil
+	})
+	if err != nil {
+		t.Fatalf("NewSandboxName() error = %v", err)
+	}
+	if len(tried) != 2 || name != tried[1] {
+		t.Fatalf("NewSandboxName() = %q after trying %q, want the second candidate", name, tried)
+	}
+}
+
+func TestNewSandboxNameGivesUpWhenEveryNameIsTaken(t *testing.T) {
+	calls := 0
+	_, err := NewSandboxName(func(string) (bool, error) {
+		calls++
+		return true, nil
+	})
+	if err == nil || calls != maxNewSandboxNameAttempts {
+		t.Fatalf("NewSandboxName() error = %v after %d tries, want an error after %d", err, calls, maxNewSandboxNameAttempts)
+	}
+}
+
+func TestNewSandboxNamePassesValidation(t *testing.T) {
+	for range 200 {
+		name, err := NewSandboxName(func(string) (bool, error) { return false, nil })
+		if err != nil {
+			t.Fatalf("NewSandboxName() error = %v", err)
+		}
+		if err := ValidateSandboxName(name); err != nil {
+			t.Fatalf("generated name fails validation: %v", err)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package sandtypes
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// portForwardProcessPrefix starts the host process name under which a port
+// forward's ssh process is recorded.
+const portForwardProcessPrefix = "forward "
+
+// PortForward is an ssh port forward between the host and a sandbox container.
+// Both ends listen on or connect to the loopback interface.
+type PortForward struct {
+	// Remote forwards ContainerPort in the container to HostPort on the host
+	// (ssh -R). Otherwise HostPort on the host is forwarded into the container
+	// (ssh -L).
+	Remote        bool `json:"remote,omitempty"`
+	HostPort      int  `json:"hostPort"`
+	ContainerPort int  `json:"containerPort"`
+	// PID is the host ssh process carrying the forward, once it is running.
+	PID int `json:"pid,omitempty"`
+}
+
+// ParsePortForward parses a host:container port spec such as "8080:3000". A
+// single port forwards the same port number on both ends.
+func ParsePortForward(spec string, remote bool) (PortForward, error) {
+	hostSpec, containerSpec, ok := strings.Cut(spec, ":")
+	if !ok {
+		containerSpec = hostSpec
+	}
+	hostPort, err := parsePort(hostSpec)
+	if err != nil {
+		return PortForward{}, fmt.Errorf("invalid forward %q: host %w", spec, err)
+	}
+	containerPort, err := parsePort(containerSpec)
+	if err != nil {
+		return PortForward{}, fmt.Errorf("invalid forward %q: container %w", spec, err)
+	}
+	return PortForward{Remote: remote, HostPort: hostPort, ContainerPort: containerPort}, nil
+}
+
+func parsePort(s string) (int, error) {
+	port, err := strconv.Atoi(s)
+	if err != nil || port < 1 || port > 65535 {
+		return 0, fmt.Errorf("port %q must be a number from 1 to 65535", s)
+	}
+	return port, nil
+}
+
+// String returns the forward's host:container spec.
+func (f PortForward) String() string {
+	return fmt.Sprintf("%d:%d", f.HostPort, f.ContainerPort)
+}
+
+// Flag returns the ssh flag that sets up the forward.
+func (f PortForward) Flag() string {
+	if f.Remote {
+		return "-R"
+	}
+	return "-L"
+}
+
+// SSHArgs returns the ssh arguments that set up the forward.
+func (f PortForward) SSHArgs() []string {
+	if f.Remote {
+		return []string{"-R", fmt.Sprintf("%d:127.0.0.1:%d", f.ContainerPort, f.HostPort)}
+	}
+	return []string{"-L", fmt.Sprintf("127.0.0.1:%d:127.0.0.1:%d", f.HostPort, f.ContainerPort)}
+}
+
+// ProcessName returns the name the forward's ssh process is recorded under
+// among the sandbox's host processes.
+func (f PortForward) ProcessName() string {
+	return portForwardProcessPrefix + f.Flag() + " " + f.String()
+}
+
+// PortForwardFromProcessName reverses [PortForward.ProcessName]. It reports
+// false for host processes that are not port forwards.
+func PortForwardFromProcessName(name string) (PortForward, bool) {
+	rest, ok := strings.CutPrefix(name, portForwardProcessPrefix)
+	if !ok {
+		return PortForward{}, false
+	}
+	flag, spec, ok := strings.Cut(rest, " ")
+	if !ok || (flag != "-L" && flag != "-R") {
+		return PortForward{}, false
+	}
+	f, err := ParsePortForward(spec, flag == "-R")
+	if err != nil {
+		return PortForward{}, false
+	}
+	return f, true
+}
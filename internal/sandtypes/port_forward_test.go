@@ -0,0 +1,63 @@
+package sandtypes
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePortForward(t *testing.T) {
+	tests := []struct {
+		spec   string
+		remote bool
+		want   PortForward
+	}{
+		{spec: "8080:3000", want: PortForward{HostPort: 8080, ContainerPort: 3000}},
+		{spec: "9229", want: PortForward{HostPort: 9229, ContainerPort: 9229}},
+		{spec: "9222:9223", remote: true, want: PortForward{Remote: true, HostPort: 9222, ContainerPort: 9223}},
+	}
+	for _, tt := range tests {
+		got, err := ParsePortForward(tt.spec, tt.remote)
+		if err != nil {
+			t.Fatalf("ParsePortForward(%q) error = %v", tt.spec, err)
+		}
+		if got != tt.want {
+			t.Errorf("ParsePortForward(%q) = %+v, want %+v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestParsePortForwardRejectsInvalidSpecs(t *testing.T) {
+	for _, spec := range []string{"", ":", "8080:", ":3000", "http:3000", "0:3000", "8080:65536", "1:2:3", "localhost:8080:3000"} {
+		if _, err := ParsePortForward(spec, false); err == nil {
+			t.Errorf("ParsePortForward(%q) error = nil, want invalid spec error", spec)
+		}
+	}
+}
+
+func TestPortForwardSSHArgs(t *testing.T) {
+	local := PortForward{HostPort: 8080, ContainerPort: 3000}
+	if got, want := local.SSHArgs(), []string{"-L", "127.0.0.1:8080:127.0.0.1:3000"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("local SSHArgs() = %v, want %v", got, want)
+	}
+	remote := PortForward{Remote: true, HostPort: 9222, ContainerPort: 9223}
+	if got, want := remote.SSHArgs(), []string{"-R", "9223:127.0.0.1:9222"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("remote SSHArgs() = %v, want %v", got, want)
+	}
+}
+
+func TestPortForwardProcessNameRoundTrip(t *testing.T) {
+	for _, f := range []PortForward{
+		{HostPort: 8080, ContainerPort: 3000},
+		{Remote: true, HostPort: 9222, ContainerPort: 9222},
+	} {
+		got, ok := PortForwardFromProcessName(f.ProcessName())
+		if !ok || got != f {
+			t.Errorf("PortForwardFromProcessName(%q) = %+v, %v; want %+v", f.ProcessName(), got, ok, f)
+		}
+	}
+	for _, name := range []string{"opencode-chrome-devtools-tunnel", "forward -X 1:2", "forward -L nope"} {
+		if _, ok := PortForwardFromProcessName(name); ok {
+			t.Errorf("PortForwardFromProcessName(%q) ok = true, want false", name)
+		}
+	}
+}
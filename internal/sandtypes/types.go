@@ -3,17 +3,19 @@
 package sandtypes
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"io"
+	"slices"
 	"strings"
 )
 
 // MountSpec describes a bind mount that should be attached to a container.
 type MountSpec struct {
-	Source   string
-	Target   string
-	ReadOnly bool
+	Source   string `json:"source"`
+	Target   string `json:"target"`
+	ReadOnly bool   `json:"readOnly,omitempty"`
 }
 
 // String renders the mount specification into the container runtime format.
@@ -83,26 +85,98 @@ type HookStreamer interface {
 	ExecStreamInput(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer, shellCmd string, args ...string) error
 }
 
+// ErrorWithOutput adds the last lines of output to err under label, indented,
+// so an error from a failed command says why it failed and not just how it
+// exited. err is returned as is when output is blank.
+func ErrorWithOutput(err error, label, output string, lines int) error {
+	output = strings.TrimRight(output, " \t\r\n")
+	if err == nil || strings.TrimSpace(output) == "" {
+		return err
+	}
+	tail := strings.Split(output, "\n")
+	if lines > 0 && len(tail) > lines {
+		tail = tail[len(tail)-lines:]
+	}
+	for i, line := range tail {
+		tail[i] = "    " + strings.TrimRight(line, "\r")
+	}
+	return fmt.Errorf("%w\n  %s:\n%s", err, label, strings.Join(tail, "\n"))
+}
+
+// HostProcessRecorder is implemented by HookStreamers that can track host
+// processes a hook leaves running, such as ssh tunnels, so they are killed
+// when the sandbox is stopped or removed. The process must lead its own
+// process group.
+type HostProcessRecorder interface {
+	RecordHostProcess(ctx context.Context, name string, pid int) error
+}
+
+// HostProcessKiller is implemented by HookStreamers that can kill a host
+// process recorded with [HostProcessRecorder]. Killing a process that was
+// never recorded, or has already exited, is not an error.
+type HostProcessKiller interface {
+	KillHostProcess(ctx context.Context, name string) error
+}
+
+// HookPhase orders container hooks: they run in phase order, and in the order
+// they were given within a phase. The zero value is HookPhaseMain.
+type HookPhase int
+
+const (
+	// HookPhasePre is for setup other hooks rely on, such as creating the
+	// container's user, copying in its keys and dotfiles, and starting sshd.
+	HookPhasePre HookPhase = iota - 1
+	// HookPhaseMain is for most hooks, such as installing an agent.
+	HookPhaseMain
+	// HookPhasePost is for hooks that need the container fully set up, such
+	// as tunnels over ssh.
+	HookPhasePost
+)
+
 // ContainerHook allows callers to inject container customisation step.
 type ContainerHook interface {
 	Name() string
+	Phase() HookPhase
 	Run(ctx context.Context, ctr *Container, exec HookStreamer) error
 }
 
 type containerHook struct {
-	name string
-	fn   func(ctx context.Context, ctr *Container, exec HookStreamer) error
+	name  string
+	phase HookPhase
+	fn    func(ctx context.Context, ctr *Container, exec HookStreamer) error
 }
 
 func (h containerHook) Name() string {
 	return h.name
 }
 
+func (h containerHook) Phase() HookPhase {
+	return h.phase
+}
+
 func (h containerHook) Run(ctx context.Context, ctr *Container, exec HookStreamer) error {
 	return h.fn(ctx, ctr, exec)
 }
 
 // NewContainerHook helps callers construct hook instances without exporting internals.
+// The hook runs in HookPhaseMain.
 func NewContainerHook(name string, fn func(ctx context.Context, ctr *Container, exec HookStreamer) error) ContainerHook {
-	return containerHook{name: name, fn: fn}
+	return NewPhasedContainerHook(name, HookPhaseMain, fn)
+}
+
+// NewPhasedContainerHook is NewContainerHook for a hook that must run before
+// or after the main phase.
+func NewPhasedContainerHook(name string, phase HookPhase, fn func(ctx context.Context, ctr *Container, exec HookStreamer) error) ContainerHook {
+	return containerHook{name: name, phase: phase, fn: fn}
+}
+
+// OrderHooks returns hooks sorted by phase, keeping the order they were given
+// in within each phase, so configurations composing hooks need not append
+// them in dependency order.
+func OrderHooks(hooks []ContainerHook) []ContainerHook {
+	ordered := slices.Clone(hooks)
+	slices.SortStableFunc(ordered, func(a, b ContainerHook) int {
+		return cmp.Compare(a.Phase(), b.Phase())
+	})
+	return ordered
 }
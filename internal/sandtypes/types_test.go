@@ -1,6 +1,9 @@
 package sandtypes
 
 import (
+	"context"
+	"errors"
+	"slices"
 	"testing"
 )
 
@@ -35,3 +38,60 @@ func TestMountSpec_String(t *testing.T) {
 		})
 	}
 }
+
+func TestOrderHooksSortsByPhaseAndKeepsOrderWithinPhase(t *testing.T) {
+	noop := func(context.Context, *Container, HookStreamer) error { return nil }
+	hooks := []ContainerHook{
+		NewPhasedContainerHook("post", HookPhasePost, noop),
+		NewContainerHook("main-1", noop),
+		NewPhasedContainerHook("pre-1", HookPhasePre, noop),
+		NewContainerHook("main-2", noop),
+		NewPhasedContainerHook("pre-2", HookPhasePre, noop),
+	}
+	var got []string
+	for _, h := range OrderHooks(hooks) {
+		got = append(got, h.Name())
+	}
+	if want := []string{"pre-1", "pre-2", "main-1", "main-2", "post"}; !slices.Equal(got, want) {
+		t.Fatalf("OrderHooks() = %v, want %v", got, want)
+	}
+	if hooks[0].Name() != "post" {
+		t.Fatalf("OrderHooks() reordered its argument: %s is first", hooks[0].Name())
+	}
+}
+
+func TestDNSConfigValidate(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		cfg     DNSConfig
+		wantErr bool
+	}{
+		{name: "empty", cfg: DNSConfig{}},
+		{name: "full", cfg: DNSConfig{Nameservers: []string{"10.0.0.53", "fd00::53"}, SearchDomains: []string{"corp.example.com"}, Options: []string{"ndots:2", "rotate"}}},
+		{name: "disabled", cfg: DNSConfig{Disabled: true}},
+		{name: "disabled with nameserver", cfg: DNSConfig{Disabled: true, Nameservers: []string{"10.0.0.53"}}, wantErr: true},
+		{name: "hostname nameserver", cfg: DNSConfig{Nameservers: []string{"dns.example.com"}}, wantErr: true},
+		{name: "blank search domain", cfg: DNSConfig{SearchDomains: []string{""}}, wantErr: true},
+		{name: "option with spaces", cfg: DNSConfig{Options: []string{"ndots:2 rotate"}}, wantErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.cfg.Validate(); (err != nil) != tc.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestErrorWithOutput(t *testing.T) {
+	cmdErr := errors.New("exit status 1")
+	err := ErrorWithOutput(cmdErr, "output", "one\r\ntwo\nthree\nfour\n\n", 3)
+	if want := "exit status 1\n  output:\n    two\n    three\n    four"; err.Error() != want {
+		t.Fatalf("ErrorWithOutput() = %q, want %q", err, want)
+	}
+	if !errors.Is(err, cmdErr) {
+		t.Fatalf("ErrorWithOutput() = %v, want it to wrap the command's error", err)
+	}
+	if got := ErrorWithOutput(cmdErr, "output", " \n", 3); got != cmdErr {
+		t.Fatalf("ErrorWithOutput() with blank output = %v, want the error unchanged", got)
+	}
+}
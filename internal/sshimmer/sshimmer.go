@@ -5,7 +5,10 @@ import (
 	"bytes"
 	"context"
 	"crypto/ed25519"
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
 	"encoding/pem"
 	"fmt"
 	"io/fs"
@@ -16,6 +19,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/banksean/sand/internal/hostops"
 	"github.com/kevinburke/ssh_config"
 	"golang.org/x/crypto/ssh"
 )
@@ -32,6 +36,7 @@ type LocalSSHimmer struct {
 	localDomain string
 
 	knownHostsPath   string
+	sshConfigPath    string
 	userIdentityPath string
 	userIdentity     []byte
 
@@ -66,7 +71,7 @@ func NewLocalSSHimmer(ctx context.Context, localDomain string) (*LocalSSHimmer,
 
 // newLocalSSHimmerWithDeps creates a new LocalSSHimmer with the specified dependencies
 func newLocalSSHimmerWithDeps(ctx context.Context, localDomain string, fs FileSystem, kg KeyGenerator) (*LocalSSHimmer, error) {
-	base := filepath.Join(os.Getenv("HOME"), ".config", "sand")
+	base := sandConfigDir(fs)
 	if _, err := fs.Stat(base); err != nil {
 		if err := fs.MkdirAll(base, 0o777); err != nil {
 			return nil, fmt.Errorf("couldn't create %s: %w", base, err)
@@ -77,6 +82,7 @@ func newLocalSSHimmerWithDeps(ctx context.Context, localDomain string, fs FileSy
 	s := &LocalSSHimmer{
 		localDomain:      localDomain,
 		knownHostsPath:   filepath.Join(base, "known_hosts"),
+		sshConfigPath:    filepath.Join(base, "ssh_config"),
 		userIdentityPath: filepath.Join(base, "user_key"),
 
 		hostCAPath:   filepath.Join(base, "host_ca"),
@@ -283,6 +289,143 @@ func (c *LocalSSHimmer) addHostCAToKnownHosts() error {
 	return nil
 }
 
+// RemoveHost forgets hostName once its sandbox is removed, so a later
+// sandbox that reuses the name can't be trusted on the strength of the old
+// one's keys. It drops hostName from sand's known_hosts, keeping the shared
+// @cert-authority line, and removes any ssh_config Host block that names only
+// hostName, keeping the wildcard block and the Include in ~/.ssh/config.
+func (s *LocalSSHimmer) RemoveHost(ctx context.Context, hostName string) error {
+	slog.InfoContext(ctx, "LocalSSHimmer.RemoveHost", "hostName", hostName)
+	if err := removeKnownHost(s.fs, s.knownHostsPath, hostName); err != nil {
+		return err
+	}
+	return removeSSHConfigHost(s.fs, s.sshConfigPath, hostName)
+}
+
+func removeKnownHost(fs FileSystem, knownHostsPath, hostName string) error {
+	content, err := fs.ReadFile(knownHostsPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("couldn't read known_hosts file: %w", err)
+	}
+	var outputLines []string
+	changed := false
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		fields := strings.Fields(line)
+		// Markers such as @cert-authority apply to patterns, not one host.
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") || strings.HasPrefix(fields[0], "@") {
+			outputLines = append(outputLines, line)
+			continue
+		}
+		var kept []string
+		for _, pattern := range strings.Split(fields[0], ",") {
+			if !knownHostMatches(pattern, hostName) {
+				kept = append(kept, pattern)
+			}
+		}
+		if len(kept) == len(strings.Split(fields[0], ",")) {
+			outputLines = append(outputLines, line)
+			continue
+		}
+		changed = true
+		if len(kept) > 0 {
+			outputLines = append(outputLines, strings.Join(kept, ",")+" "+strings.Join(fields[1:], " "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("couldn't read known_hosts file: %w", err)
+	}
+	if !changed {
+		return nil
+	}
+	if err := fs.SafeWriteFile(knownHostsPath, []byte(strings.Join(outputLines, "\n")), 0o644); err != nil {
+		return fmt.Errorf("couldn't safely write updated known_hosts to %s: %w", knownHostsPath, err)
+	}
+	return nil
+}
+
+// knownHostMatches reports whether a single known_hosts host entry, plain,
+// bracketed with a port or hashed, is for hostName.
+func knownHostMatches(pattern, hostName string) bool {
+	if strings.HasPrefix(pattern, "|1|") {
+		parts := strings.Split(pattern[len("|1|"):], "|")
+		if len(parts) != 2 {
+			return false
+		}
+		salt, err := base64.StdEncoding.DecodeString(parts[0])
+		if err != nil {
+			return false
+		}
+		mac := hmac.New(sha1.New, salt)
+		mac.Write([]byte(hostName))
+		return base64.StdEncoding.EncodeToString(mac.Sum(nil)) == parts[1]
+	}
+	if strings.HasPrefix(pattern, "[") {
+		if end := strings.Index(pattern, "]"); end > 0 {
+			pattern = pattern[1:end]
+		}
+	}
+	return strings.EqualFold(pattern, hostName)
+}
+
+func removeSSHConfigHost(fs FileSystem, sshConfigPath, hostName string) error {
+	content, err := fs.ReadFile(sshConfigPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	cfg, err := ssh_config.Decode(bytes.NewReader(content))
+	if err != nil {
+		return fmt.Errorf("couldn't decode ssh_config: %w", err)
+	}
+	hosts := cfg.Hosts[:0]
+	for _, host := range cfg.Hosts {
+		if !sshConfigHostIsOnly(host, hostName) {
+			hosts = append(hosts, host)
+		}
+	}
+	if len(hosts) == len(cfg.Hosts) {
+		return nil
+	}
+	cfg.Hosts = hosts
+	cfgBytes, err := cfg.MarshalText()
+	if err != nil {
+		return fmt.Errorf("couldn't marshal ssh_config: %w", err)
+	}
+	if err := fs.SafeWriteFile(sshConfigPath, cfgBytes, 0o644); err != nil {
+		return fmt.Errorf("couldn't safely write ssh_config: %w", err)
+	}
+	return nil
+}
+
+func sshConfigHostIsOnly(host *ssh_config.Host, hostName string) bool {
+	if len(host.Patterns) == 0 {
+		return false
+	}
+	for _, pattern := range host.Patterns {
+		if !strings.EqualFold(pattern.String(), hostName) {
+			return false
+		}
+	}
+	return true
+}
+
+// userCertPrincipals lists who a user certificate issued for username may log
+// in to a sandbox as: username itself and, so that sand shell --root and sand
+// exec --root work in sandboxes with a non-root default user, root.
+func userCertPrincipals(username string) []string {
+	if username == "root" {
+		return []string{username}
+	}
+	return []string{username, "root"}
+}
+
 func (s *LocalSSHimmer) issueUserCertificate(certPub ssh.PublicKey, username string) (*ssh.Certificate, error) {
 	// Create a new user certificate
 	cert := &ssh.Certificate{
@@ -290,7 +433,7 @@ func (s *LocalSSHimmer) issueUserCertificate(certPub ssh.PublicKey, username str
 		Serial:          1,
 		CertType:        ssh.UserCert,
 		KeyId:           "sand-user",
-		ValidPrincipals: []string{username},                             // Valid for the host OS user running sand
+		ValidPrincipals: userCertPrincipals(username),                   // Valid for the sandbox user, and root for --root sessions
 		ValidAfter:      uint64(time.Now().Add(-24 * time.Hour).Unix()), // Valid from 1 day ago
 		ValidBefore:     uint64(time.Now().Add(720 * time.Hour).Unix()), // Valid for 30 days
 		Permissions: ssh.Permissions{
@@ -376,11 +519,27 @@ func checkSSHHostResolve(ctx context.Context, hostname string) error {
 	return nil
 }
 
+// IncludeCheckOptions controls what CheckForIncludeWithOptions treats as needing an update.
+type IncludeCheckOptions struct {
+	// FixPosition makes an Include line that appears after other ssh config
+	// directives count as needing an update, so that the returned update
+	// function moves it to the top. Otherwise it only prints a warning.
+	FixPosition bool
+}
+
 // CheckForIncludeWithFS verifies that the user's ~/.ssh/ssh_config has the necessary "Include" statement
 // for sand's ssh_config file.
 func CheckForIncludeWithFS(ctx context.Context, fs FileSystem) (func() error, error) {
-	sandSSHPathInclude := "Include " + filepath.Join(os.Getenv("HOME"), ".config", "sand", "ssh_config")
-	defaultSSHPath := filepath.Join(os.Getenv("HOME"), ".ssh", "config")
+	return CheckForIncludeWithOptions(ctx, fs, IncludeCheckOptions{})
+}
+
+// CheckForIncludeWithOptions is like CheckForIncludeWithFS, with opts controlling
+// how a mis-positioned Include line is handled. If an update is needed it
+// returns a function that applies it; the function changes only the Include
+// line and leaves the rest of the file byte-for-byte intact.
+func CheckForIncludeWithOptions(ctx context.Context, fs FileSystem, opts IncludeCheckOptions) (func() error, error) {
+	sandSSHPathInclude := "Include " + filepath.Join(sandConfigDir(fs), "ssh_config")
+	defaultSSHPath := filepath.Join(fs.HomeDir(), ".ssh", "config")
 
 	slog.InfoContext(ctx, "CheckForIncludeWithFS", "sandSSHPathInclude", sandSSHPathInclude, "defaultSSHPath", defaultSSHPath)
 
@@ -410,7 +569,7 @@ func CheckForIncludeWithFS(ctx context.Context, fs FileSystem) (func() error, er
 					pos := inc.Pos()
 					sandInludePos = &pos
 				}
-			} else if firstNonIncludePos == nil && !strings.HasPrefix(strings.TrimSpace(node.String()), "#") {
+			} else if text := strings.TrimSpace(node.String()); firstNonIncludePos == nil && text != "" && !strings.HasPrefix(text, "#") {
 				pos := node.Pos()
 				firstNonIncludePos = &pos
 			}
@@ -419,14 +578,19 @@ func CheckForIncludeWithFS(ctx context.Context, fs FileSystem) (func() error, er
 
 	slog.InfoContext(ctx, "CheckForIncludeWithFS", "sandInludePos", sandInludePos)
 
+	update := func() error {
+		return modifySSHConfig(existingContent, sandSSHPathInclude, fs, defaultSSHPath)
+	}
 	if sandInludePos == nil {
-		return func() error {
-			// Include line not found, add it to the top of the file
-			return modifySSHConfig(cfg, sandSSHPathInclude, fs, defaultSSHPath)
-		}, nil
+		// Include line not found, add it to the top of the file
+		return update, nil
 	}
 
 	if firstNonIncludePos != nil && firstNonIncludePos.Line < sandInludePos.Line {
+		if opts.FixPosition {
+			// Include line is below other directives, move it to the top of the file
+			return update, nil
+		}
 		fmt.Printf("SSH config warning: the location of the Include statement for sand's ssh config on line %d of %s may prevent ssh from working with sand containers. try moving it to the top of the file (before any 'Host' lines) if ssh isn't working for you.\n", sandInludePos.Line, defaultSSHPath)
 	}
 	return nil, nil
@@ -505,9 +669,10 @@ func addIndentityTo(cfg *ssh_config.Config, hostPattern *ssh_config.Pattern, kno
 }
 
 func writeSandSSHConfig(localDomain string, username string, fs FileSystem) error {
-	identityPath := filepath.Join(os.Getenv("HOME"), ".config", "sand", "user_key-"+username)
-	sandSSHConfigPath := filepath.Join(os.Getenv("HOME"), ".config", "sand", "ssh_config")
-	knownHostsPath := filepath.Join(os.Getenv("HOME"), ".config", "sand", "known_hosts")
+	files := userClientFiles(fs, username)
+	identityPath := files.IdentityFile
+	sandSSHConfigPath := files.ConfigFile
+	knownHostsPath := files.KnownHostsFile
 
 	// Read the existing SSH config file
 	existingContent, err := fs.ReadFile(sandSSHConfigPath)
@@ -573,22 +738,29 @@ func writeSandSSHConfig(localDomain string, username string, fs FileSystem) erro
 	return nil
 }
 
-func modifySSHConfig(cfg *ssh_config.Config, sandSSHPathInclude string, fs FileSystem, defaultSSHPath string) error {
-	cfgBytes, err := cfg.MarshalText()
-	if err != nil {
-		return fmt.Errorf("couldn't marshal ssh_config: %w", err)
-	}
-
-	// Add the include line to the beginning
-	cfgBytes = append([]byte(sandSSHPathInclude+"\n"), cfgBytes...)
-
-	// Safely write the updated config back to the file
-	if err := fs.SafeWriteFile(defaultSSHPath, cfgBytes, 0o644); err != nil {
+// modifySSHConfig writes the raw ssh config cfgBytes back to defaultSSHPath with
+// sandSSHPathInclude as its first line, removing the line from anywhere else
+// in the file. All other lines are preserved exactly.
+func modifySSHConfig(cfgBytes []byte, sandSSHPathInclude string, fs FileSystem, defaultSSHPath string) error {
+	if err := fs.SafeWriteFile(defaultSSHPath, prependIncludeLine(cfgBytes, sandSSHPathInclude), 0o644); err != nil {
 		return fmt.Errorf("couldn't safely write ssh_config: %w", err)
 	}
 	return nil
 }
 
+// prependIncludeLine returns cfgBytes with include as its first line and any
+// other occurrences of the include line removed.
+func prependIncludeLine(cfgBytes []byte, include string) []byte {
+	out := []byte(include + "\n")
+	for line := range bytes.Lines(cfgBytes) {
+		if strings.TrimSpace(string(line)) == include {
+			continue
+		}
+		out = append(out, line...)
+	}
+	return out
+}
+
 // encodePrivateKeyToPEM encodes an Ed25519 private key for storage
 func encodePrivateKeyToPEM(privateKey ed25519.PrivateKey) []byte {
 	// No need to create a signer first, we can directly marshal the key
@@ -614,6 +786,43 @@ type FileSystem interface {
 	TempFile(dir, pattern string) (*os.File, error)
 	Rename(oldpath, newpath string) error
 	SafeWriteFile(name string, data []byte, perm fs.FileMode) error
+	// HomeDir returns the home directory that ~/.ssh and ~/.config/sand are under.
+	HomeDir() string
+}
+
+// sandConfigDir is where sand keeps its ssh keys, certificates and config.
+func sandConfigDir(fs FileSystem) string {
+	return filepath.Join(fs.HomeDir(), ".config", "sand")
+}
+
+// ClientFiles are the files an ssh client uses to connect to sandboxes as
+// one user.
+type ClientFiles struct {
+	// IdentityFile is the user's private key.
+	IdentityFile string
+	// CertificateFile is the user's key signed by sand's user CA, which
+	// sandbox sshds trust.
+	CertificateFile string
+	// KnownHostsFile trusts host certificates signed by sand's host CA.
+	KnownHostsFile string
+	// ConfigFile is the ssh_config sand includes from ~/.ssh/config.
+	ConfigFile string
+}
+
+// UserClientFiles returns where NewKeys keeps username's ssh client files.
+func UserClientFiles(username string) ClientFiles {
+	return userClientFiles(&RealFileSystem{}, username)
+}
+
+func userClientFiles(fs FileSystem, username string) ClientFiles {
+	base := sandConfigDir(fs)
+	identityPath := filepath.Join(base, "user_key-"+username)
+	return ClientFiles{
+		IdentityFile:    identityPath,
+		CertificateFile: identityPath + "-cert.pub",
+		KnownHostsFile:  filepath.Join(base, "known_hosts"),
+		ConfigFile:      filepath.Join(base, "ssh_config"),
+	}
 }
 
 func (fs *RealFileSystem) MkdirAll(name string, perm fs.FileMode) error {
@@ -651,9 +860,20 @@ func (fs *RealFileSystem) Rename(oldpath, newpath string) error {
 	return os.Rename(oldpath, newpath)
 }
 
-// SafeWriteFile writes data to a temporary file, syncs to disk, creates a backup of the existing file if it exists,
-// and then renames the temporary file to the target file name.
+func (fs *RealFileSystem) HomeDir() string {
+	return hostops.HomeDir()
+}
+
+// SafeWriteFile writes data to a temporary file, syncs to disk, and then renames the temporary file
+// over the target file name. If the target already has exactly this content, only its permissions are updated.
 func (fs *RealFileSystem) SafeWriteFile(name string, data []byte, perm fs.FileMode) error {
+	if existing, err := fs.ReadFile(name); err == nil && bytes.Equal(existing, data) {
+		if err := os.Chmod(name, perm); err != nil {
+			return fmt.Errorf("couldn't set permissions on file: %w", err)
+		}
+		return nil
+	}
+
 	// Get the directory from the target filename
 	dir := filepath.Dir(name)
 
@@ -682,19 +902,9 @@ func (fs *RealFileSystem) SafeWriteFile(name string, data []byte, perm fs.FileMo
 		return fmt.Errorf("couldn't close temporary file: %w", err)
 	}
 
-	// If the original file exists, create a backup
-	if _, err := fs.Stat(name); err == nil {
-		backupName := name + ".bak"
-		// Remove any existing backup
-		_ = os.Remove(backupName) // Ignore errors if the backup doesn't exist
-
-		// Create the backup
-		if err := fs.Rename(name, backupName); err != nil {
-			return fmt.Errorf("couldn't create backup file: %w", err)
-		}
-	}
-
-	// Rename the temporary file to the target file
+	// Rename the temporary file over the target file. rename(2) replaces name
+	// atomically, so readers see either the old or the new contents and no
+	// backup copy is needed.
 	if err := fs.Rename(tmpFilename, name); err != nil {
 		return fmt.Errorf("couldn't rename temporary file to target: %w", err)
 	}
@@ -712,7 +922,7 @@ func (fs *RealFileSystem) SafeWriteFile(name string, data []byte, perm fs.FileMo
 func CheckSSHReachability(ctx context.Context, cntrName string) (func() error, error) {
 	if err := checkSSHHostResolve(ctx, cntrName); err != nil {
 		slog.InfoContext(ctx, "CheckForIncludeWithFS")
-		return CheckForIncludeWithFS(ctx, &RealFileSystem{})
+		return CheckForIncludeWithOptions(ctx, &RealFileSystem{}, IncludeCheckOptions{FixPosition: true})
 	}
 	return nil, nil
 }
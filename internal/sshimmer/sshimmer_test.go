@@ -3,11 +3,15 @@ package sshimmer
 import (
 	"bytes"
 	"crypto/ed25519"
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
 	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -23,6 +27,7 @@ type MockFileSystem struct {
 	StatCalledWith []string
 	TempFiles      []string
 	FailOn         map[string]error // Map of function name to error to simulate failures
+	Home           string
 }
 
 func NewMockFileSystem() *MockFileSystem {
@@ -32,9 +37,14 @@ func NewMockFileSystem() *MockFileSystem {
 		OpenedFiles: make(map[string]*MockFile),
 		TempFiles:   []string{},
 		FailOn:      make(map[string]error),
+		Home:        "/home/testuser",
 	}
 }
 
+func (m *MockFileSystem) HomeDir() string {
+	return m.Home
+}
+
 func (m *MockFileSystem) Stat(name string) (fs.FileInfo, error) {
 	m.StatCalledWith = append(m.StatCalledWith, name)
 	if err, ok := m.FailOn["Stat"]; ok {
@@ -172,12 +182,6 @@ func (m *MockFileSystem) SafeWriteFile(name string, data []byte, perm fs.FileMod
 		return err
 	}
 
-	// For the mock, we'll create a backup if the file exists
-	if existingData, exists := m.Files[name]; exists {
-		backupName := name + ".bak"
-		m.Files[backupName] = existingData
-	}
-
 	// Write the new data
 	m.Files[name] = data
 
@@ -266,7 +270,7 @@ func setupTestLocalSSHimmer(t *testing.T) (*LocalSSHimmer, *MockFileSystem, *Moc
 	mockFS, mockKG, _ := setupMocks(t)
 
 	// Setup home dir in mock filesystem
-	homePath := "/home/testuser"
+	homePath := mockFS.Home
 	sandDir := filepath.Join(homePath, ".config/sand")
 	mockFS.CreatedDirs[sandDir] = true
 
@@ -276,11 +280,6 @@ func setupTestLocalSSHimmer(t *testing.T) (*LocalSSHimmer, *MockFileSystem, *Moc
 	knownHostsPath := filepath.Join(sandDir, "known_hosts")
 	mockFS.Files[knownHostsPath] = []byte("")
 
-	// Set HOME environment variable for the test
-	oldHome := os.Getenv("HOME")
-	os.Setenv("HOME", homePath)
-	t.Cleanup(func() { os.Setenv("HOME", oldHome) })
-
 	// Create LocalSSHimmer with mocks
 	ssh, err := newLocalSSHimmerWithDeps(t.Context(), "test", mockFS, mockKG)
 	if err != nil {
@@ -293,11 +292,6 @@ func setupTestLocalSSHimmer(t *testing.T) (*LocalSSHimmer, *MockFileSystem, *Moc
 func TestNewLocalSSHimmerCreatesRequiredDirectories(t *testing.T) {
 	mockFS, mockKG, _ := setupMocks(t)
 
-	// Set HOME environment variable for the test
-	oldHome := os.Getenv("HOME")
-	os.Setenv("HOME", "/home/testuser")
-	defer func() { os.Setenv("HOME", oldHome) }()
-
 	// Create empty files so the test doesn't fail
 	sandDir := "/home/testuser/.config/sand"
 	sandConfigPath := filepath.Join(sandDir, "ssh_config")
@@ -363,14 +357,28 @@ func TestNewKeys(t *testing.T) {
 	}
 }
 
+func TestNewKeysIssuesUserCertForUsername(t *testing.T) {
+	sshim, mockFS, _ := setupTestLocalSSHimmer(t)
+	if _, err := sshim.NewKeys(t.Context(), "alpha.test", "alice"); err != nil {
+		t.Fatalf("NewKeys() error = %v", err)
+	}
+	certPath := sshim.userIdentityPath + "-alice-cert.pub"
+	pub, _, _, _, err := ssh.ParseAuthorizedKey(mockFS.Files[certPath])
+	if err != nil {
+		t.Fatalf("parse %s: %v", certPath, err)
+	}
+	cert, ok := pub.(*ssh.Certificate)
+	if !ok {
+		t.Fatalf("%s holds a %T, want a certificate", certPath, pub)
+	}
+	if cert.CertType != ssh.UserCert || !reflect.DeepEqual(cert.ValidPrincipals, []string{"alice", "root"}) {
+		t.Fatalf("user cert type %d principals %v, want a user cert for alice and root", cert.CertType, cert.ValidPrincipals)
+	}
+}
+
 func TestCheckForInclude_userAccepts(t *testing.T) {
 	mockFS := NewMockFileSystem()
 
-	// Set HOME environment variable for the test
-	oldHome := os.Getenv("HOME")
-	os.Setenv("HOME", "/home/testuser")
-	defer func() { os.Setenv("HOME", oldHome) }()
-
 	// Create a mock ssh config with the expected include
 	includeLine := "Include /home/testuser/.config/sand/ssh_config"
 	initialConfig := fmt.Sprintf("%s\nHost example\n  HostName example.com\n", includeLine)
@@ -396,11 +404,6 @@ func TestCheckForInclude_userAccepts(t *testing.T) {
 func TestCheckForInclude_userDeclines(t *testing.T) {
 	mockFS := NewMockFileSystem()
 
-	// Set HOME environment variable for the test
-	oldHome := os.Getenv("HOME")
-	os.Setenv("HOME", "/home/testuser")
-	defer func() { os.Setenv("HOME", oldHome) }()
-
 	// Create a mock ssh config with the expected include
 	includeLine := "Include /home/testuser/.config/sand/ssh_config"
 	initialConfig := fmt.Sprintf("%s\nHost example\n  HostName example.com\n", includeLine)
@@ -433,11 +436,6 @@ func TestLocalSSHimmerWithErrors(t *testing.T) {
 	mockFS.FailOn["MkdirAll"] = fmt.Errorf("mock mkdir error")
 	mockKG := NewMockKeyGenerator(nil, nil, nil, nil)
 
-	// Set HOME environment variable for the test
-	oldHome := os.Getenv("HOME")
-	os.Setenv("HOME", "/home/testuser")
-	defer func() { os.Setenv("HOME", oldHome) }()
-
 	// Try to create sshimmer with failing FS
 	_, err := newLocalSSHimmerWithDeps(t.Context(), "test", mockFS, mockKG)
 	if err == nil || !strings.Contains(err.Error(), "mock mkdir error") {
@@ -608,3 +606,254 @@ func (m *MockKeyGenerator) GetCASigner() ssh.Signer {
 func (m *MockKeyGenerator) IsMock() bool {
 	return true
 }
+
+func TestRealFileSystemSafeWriteFileLeavesNoBackups(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "config")
+	fs := &RealFileSystem{}
+
+	for _, content := range []string{"first\n", "second\n", "second\n", "third\n"} {
+		if err := fs.SafeWriteFile(name, []byte(content), 0o600); err != nil {
+			t.Fatalf("SafeWriteFile(%q) error = %v", content, err)
+		}
+		got, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("ReadFile() error = %v", err)
+		}
+		if string(got) != content {
+			t.Fatalf("file contents = %q, want %q", got, content)
+		}
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0o600 {
+			t.Fatalf("file mode = %v, want 0600", info.Mode().Perm())
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "config" {
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		t.Fatalf("directory contains %v, want only config", names)
+	}
+}
+
+func TestRealFileSystemSafeWriteFileUpdatesPermsOfUnchangedFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "known_hosts")
+	if err := os.WriteFile(name, []byte("same\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := (&RealFileSystem{}).SafeWriteFile(name, []byte("same\n"), 0o600); err != nil {
+		t.Fatalf("SafeWriteFile() error = %v", err)
+	}
+	info, err := os.Stat(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Fatalf("file mode = %v, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestCheckForIncludePreservesExistingContent(t *testing.T) {
+	mockFS := NewMockFileSystem()
+	sshConfigPath := "/home/testuser/.ssh/config"
+	original := "# my personal config\n\nHost example   # trailing comment\n\tHostName example.com\n    User  me\n\n# end\n"
+	mockFS.Files[sshConfigPath] = []byte(original)
+
+	update, err := CheckForIncludeWithFS(t.Context(), mockFS)
+	if err != nil {
+		t.Fatalf("CheckForIncludeWithFS() error = %v", err)
+	}
+	if update == nil {
+		t.Fatal("CheckForIncludeWithFS() returned no update for a config without the Include line")
+	}
+	if err := update(); err != nil {
+		t.Fatalf("update() error = %v", err)
+	}
+	want := "Include /home/testuser/.config/sand/ssh_config\n" + original
+	if got := string(mockFS.Files[sshConfigPath]); got != want {
+		t.Fatalf("ssh config =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestSSHPathsFollowFileSystemHome(t *testing.T) {
+	mockFS := NewMockFileSystem()
+	mockFS.Home = "/Users/other"
+
+	mockFS.Files["/Users/other/.ssh/config"] = []byte("Host example\n")
+	update, err := CheckForIncludeWithFS(t.Context(), mockFS)
+	if err != nil || update == nil {
+		t.Fatalf("CheckForIncludeWithFS() update present = %t, error = %v; want an update", update != nil, err)
+	}
+	if err := update(); err != nil {
+		t.Fatalf("update() error = %v", err)
+	}
+	if got, want := string(mockFS.Files["/Users/other/.ssh/config"]), "Include /Users/other/.config/sand/ssh_config\nHost example\n"; got != want {
+		t.Fatalf("ssh config = %q, want %q", got, want)
+	}
+
+	mockFS.Files["/Users/other/.config/sand/ssh_config"] = []byte("")
+	if err := writeSandSSHConfig("test", "alice", mockFS); err != nil {
+		t.Fatalf("writeSandSSHConfig() error = %v", err)
+	}
+	sandConfig := string(mockFS.Files["/Users/other/.config/sand/ssh_config"])
+	for _, want := range []string{"/Users/other/.config/sand/user_key-alice", "/Users/other/.config/sand/known_hosts"} {
+		if !strings.Contains(sandConfig, want) {
+			t.Errorf("sand ssh_config missing %s:\n%s", want, sandConfig)
+		}
+	}
+	for name := range mockFS.Files {
+		if strings.HasPrefix(name, "/home/testuser") {
+			t.Errorf("wrote %s outside the file system's home", name)
+		}
+	}
+}
+
+func TestCheckForIncludeMisplacedInclude(t *testing.T) {
+	sshConfigPath := "/home/testuser/.ssh/config"
+	includeLine := "Include /home/testuser/.config/sand/ssh_config"
+	misplaced := "# comment\nHost example\n  HostName example.com\n" + includeLine + "\nHost other\n  User me\n"
+
+	t.Run("warns without FixPosition", func(t *testing.T) {
+		mockFS := NewMockFileSystem()
+		mockFS.Files[sshConfigPath] = []byte(misplaced)
+		update, err := CheckForIncludeWithOptions(t.Context(), mockFS, IncludeCheckOptions{})
+		if err != nil {
+			t.Fatalf("CheckForIncludeWithOptions() error = %v", err)
+		}
+		if update != nil {
+			t.Fatal("CheckForIncludeWithOptions() returned an update without FixPosition")
+		}
+	})
+
+	t.Run("moves with FixPosition", func(t *testing.T) {
+		mockFS := NewMockFileSystem()
+		mockFS.Files[sshConfigPath] = []byte(misplaced)
+		update, err := CheckForIncludeWithOptions(t.Context(), mockFS, IncludeCheckOptions{FixPosition: true})
+		if err != nil {
+			t.Fatalf("CheckForIncludeWithOptions() error = %v", err)
+		}
+		if update == nil {
+			t.Fatal("CheckForIncludeWithOptions() returned no update with FixPosition")
+		}
+		if err := update(); err != nil {
+			t.Fatalf("update() error = %v", err)
+		}
+		want := includeLine + "\n# comment\nHost example\n  HostName example.com\nHost other\n  User me\n"
+		if got := string(mockFS.Files[sshConfigPath]); got != want {
+			t.Fatalf("ssh config =\n%q\nwant\n%q", got, want)
+		}
+	})
+
+	t.Run("leading comments and blank lines are fine", func(t *testing.T) {
+		mockFS := NewMockFileSystem()
+		mockFS.Files[sshConfigPath] = []byte("# comment\n\n" + includeLine + "\nHost example\n")
+		update, err := CheckForIncludeWithOptions(t.Context(), mockFS, IncludeCheckOptions{FixPosition: true})
+		if err != nil {
+			t.Fatalf("CheckForIncludeWithOptions() error = %v", err)
+		}
+		if update != nil {
+			t.Fatal("CheckForIncludeWithOptions() returned an update for a correctly placed Include")
+		}
+	})
+}
+
+func hashKnownHost(t *testing.T, hostName string) string {
+	t.Helper()
+	salt := make([]byte, sha1.Size)
+	if _, err := rand.Read(salt); err != nil {
+		t.Fatal(err)
+	}
+	mac := hmac.New(sha1.New, salt)
+	mac.Write([]byte(hostName))
+	return "|1|" + base64.StdEncoding.EncodeToString(salt) + "|" + base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestRemoveHostForgetsOnlyThatHost(t *testing.T) {
+	sshim, mockFS, _ := setupTestLocalSSHimmer(t)
+	caLine := "@cert-authority *.test ssh-ed25519 AAAACAKEY"
+	mockFS.Files[sshim.knownHostsPath] = []byte(strings.Join([]string{
+		caLine,
+		"# sand sandboxes",
+		"alpha.test ssh-ed25519 AAAAALPHA",
+		"beta.test ssh-ed25519 AAAABETA",
+		"[alpha.test]:2222 ssh-ed25519 AAAAALPHAPORT",
+		"alpha.test,10.0.0.5 ssh-ed25519 AAAAALPHAIP",
+		hashKnownHost(t, "alpha.test") + " ssh-ed25519 AAAAALPHAHASHED",
+		hashKnownHost(t, "beta.test") + " ssh-ed25519 AAAABETAHASHED",
+	}, "\n"))
+	mockFS.Files[sshim.sshConfigPath] = []byte("Host *.test\n  User root\n\nHost alpha.test\n  Port 2222\n\nHost beta.test\n  Port 2223\n")
+
+	if err := sshim.RemoveHost(t.Context(), "alpha.test"); err != nil {
+		t.Fatalf("RemoveHost() error = %v", err)
+	}
+
+	knownHosts := string(mockFS.Files[sshim.knownHostsPath])
+	for _, want := range []string{caLine, "# sand sandboxes", "beta.test ssh-ed25519 AAAABETA", "AAAABETAHASHED", "10.0.0.5 ssh-ed25519 AAAAALPHAIP"} {
+		if !strings.Contains(knownHosts, want) {
+			t.Errorf("known_hosts lost %q:\n%s", want, knownHosts)
+		}
+	}
+	for _, gone := range []string{"AAAAALPHA\n", "AAAAALPHAPORT", "alpha.test,", "AAAAALPHAHASHED"} {
+		if strings.Contains(knownHosts, gone) {
+			t.Errorf("known_hosts still has %q:\n%s", gone, knownHosts)
+		}
+	}
+
+	cfg, err := ssh_config.Decode(bytes.NewReader(mockFS.Files[sshim.sshConfigPath]))
+	if err != nil {
+		t.Fatalf("decode ssh_config: %v", err)
+	}
+	var patterns []string
+	for _, host := range cfg.Hosts {
+		for _, pattern := range host.Patterns {
+			patterns = append(patterns, pattern.String())
+		}
+	}
+	if got := strings.Join(patterns, " "); got != "* *.test beta.test" {
+		t.Errorf("ssh_config host patterns = %q, want the wildcard and beta.test blocks only", got)
+	}
+}
+
+func TestRemoveHostLeavesUnrelatedFilesAlone(t *testing.T) {
+	sshim, mockFS, _ := setupTestLocalSSHimmer(t)
+	original := "beta.test ssh-ed25519 AAAABETA\n"
+	mockFS.Files[sshim.knownHostsPath] = []byte(original)
+
+	if err := sshim.RemoveHost(t.Context(), "alpha.test"); err != nil {
+		t.Fatalf("RemoveHost() error = %v", err)
+	}
+	if got := string(mockFS.Files[sshim.knownHostsPath]); got != original {
+		t.Fatalf("known_hosts = %q, want it unchanged", got)
+	}
+	if got := string(mockFS.Files[sshim.sshConfigPath]); got != "" {
+		t.Fatalf("ssh_config = %q, want it unchanged", got)
+	}
+}
+
+func TestUserClientFilesAreWhatNewKeysWrites(t *testing.T) {
+	sshim, mockFS, _ := setupTestLocalSSHimmer(t)
+	if _, err := sshim.NewKeys(t.Context(), "alpha.test", "alice"); err != nil {
+		t.Fatalf("NewKeys() error = %v", err)
+	}
+	files := userClientFiles(mockFS, "alice")
+	for _, path := range []string{files.IdentityFile, files.CertificateFile, files.KnownHostsFile, files.ConfigFile} {
+		if _, ok := mockFS.Files[path]; !ok {
+			t.Errorf("NewKeys didn't write %s", path)
+		}
+	}
+	sandConfig := string(mockFS.Files[files.ConfigFile])
+	for _, want := range []string{"IdentityFile " + files.IdentityFile, "UserKnownHostsFile " + files.KnownHostsFile} {
+		if !strings.Contains(sandConfig, want) {
+			t.Errorf("sand ssh_config missing %q:\n%s", want, sandConfig)
+		}
+	}
+}
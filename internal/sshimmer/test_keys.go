@@ -0,0 +1,40 @@
+package sshimmer
+
+import (
+	"fmt"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// NewTestKeys returns well-formed sandbox Keys for hostName, signed by a
+// throwaway CA, for tests of code that writes or checks sandbox keys.
+func NewTestKeys(hostName string) (*Keys, error) {
+	kg := &RealKeyGenerator{}
+	caPrivateKey, _, err := kg.GenerateKeyPair()
+	if err != nil {
+		return nil, fmt.Errorf("generating CA key pair: %w", err)
+	}
+	caSigner, err := ssh.NewSignerFromKey(caPrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("creating CA signer: %w", err)
+	}
+	privateKey, publicKey, err := kg.GenerateKeyPair()
+	if err != nil {
+		return nil, fmt.Errorf("generating host key pair: %w", err)
+	}
+	hostPubKey, err := kg.ConvertToSSHPublicKey(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("converting to SSH public key: %w", err)
+	}
+	s := &LocalSSHimmer{hostCA: caSigner}
+	hostCert, err := s.issueHostCertificate(hostName, hostPubKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Keys{
+		HostKey:     encodePrivateKeyToPEM(privateKey),
+		HostKeyPub:  ssh.MarshalAuthorizedKey(hostPubKey),
+		HostKeyCert: ssh.MarshalAuthorizedKey(hostCert),
+		UserCAPub:   ssh.MarshalAuthorizedKey(caSigner.PublicKey()),
+	}, nil
+}
@@ -0,0 +1,160 @@
+package version
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// LatestReleaseURL is the GitHub API endpoint for sand's latest release.
+const LatestReleaseURL = "https://api.github.com/repos/banksean/sand/releases/latest"
+
+// Release is a published release of sand.
+type Release struct {
+	Tag string `json:"tag"`
+	URL string `json:"url"`
+}
+
+// ReleaseFetcher looks up the latest published release.
+type ReleaseFetcher interface {
+	LatestRelease(ctx context.Context) (Release, error)
+}
+
+// GitHubReleaseFetcher reads the latest release from the GitHub releases API.
+type GitHubReleaseFetcher struct {
+	// URL defaults to LatestReleaseURL.
+	URL    string
+	Client *http.Client
+}
+
+func (f *GitHubReleaseFetcher) LatestRelease(ctx context.Context) (Release, error) {
+	url := f.URL
+	if url == "" {
+		url = LatestReleaseURL
+	}
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return Release{}, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return Release{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Release{}, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	var body struct {
+		TagName string `json:"tag_name"`
+		HTMLURL string `json:"html_url"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return Release{}, fmt.Errorf("GET %s: %w", url, err)
+	}
+	if body.TagName == "" {
+		return Release{}, fmt.Errorf("GET %s: response has no tag_name", url)
+	}
+	return Release{Tag: body.TagName, URL: body.HTMLURL}, nil
+}
+
+// CachedReleaseFetcher remembers what Fetcher returned in the file at Path, and
+// answers from it until it is TTL old.
+type CachedReleaseFetcher struct {
+	Fetcher ReleaseFetcher
+	Path    string
+	TTL     time.Duration
+	// Now defaults to time.Now.
+	Now func() time.Time
+}
+
+type releaseCache struct {
+	CheckedAt time.Time `json:"checkedAt"`
+	Release   Release   `json:"release"`
+}
+
+func (f *CachedReleaseFetcher) LatestRelease(ctx context.Context) (Release, error) {
+	now := time.Now
+	if f.Now != nil {
+		now = f.Now
+	}
+	if data, err := os.ReadFile(f.Path); err == nil {
+		var cache releaseCache
+		if json.Unmarshal(data, &cache) == nil && cache.Release.Tag != "" && now().Sub(cache.CheckedAt) < f.TTL {
+			return cache.Release, nil
+		}
+	}
+	release, err := f.Fetcher.LatestRelease(ctx)
+	if err != nil {
+		return Release{}, err
+	}
+	// Failing to cache only costs another request next time.
+	if data, err := json.Marshal(releaseCache{CheckedAt: now(), Release: release}); err == nil {
+		if os.MkdirAll(filepath.Dir(f.Path), 0o755) == nil {
+			os.WriteFile(f.Path, data, 0o644) //nolint:errcheck
+		}
+	}
+	return release, nil
+}
+
+// UpdateCheck is the result of comparing this build with the latest release.
+type UpdateCheck struct {
+	Current string
+	Latest  Release
+	// Comparable is false when Current isn't a release version, as for dev
+	// builds made from a branch.
+	Comparable      bool
+	UpdateAvailable bool
+}
+
+// CheckForUpdate compares current, whose GitBranch is the release tag for
+// release builds, with the latest release that fetcher reports.
+func CheckForUpdate(ctx context.Context, current Info, fetcher ReleaseFetcher) (UpdateCheck, error) {
+	latest, err := fetcher.LatestRelease(ctx)
+	if err != nil {
+		return UpdateCheck{}, err
+	}
+	check := UpdateCheck{Current: current.GitBranch, Latest: latest}
+	if current.DevBuild {
+		return check, nil
+	}
+	cmp, err := compareVersions(current.GitBranch, latest.Tag)
+	if err != nil {
+		return check, nil
+	}
+	check.Comparable = true
+	check.UpdateAvailable = cmp < 0
+	return check, nil
+}
+
+// compareVersions compares two vMAJOR.MINOR.PATCH[-PRERELEASE] versions,
+// returning -1, 0 or 1. A prerelease sorts before its release.
+func compareVersions(a, b string) (int, error) {
+	va, err := parseReleaseVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	vb, err := parseReleaseVersion(b)
+	if err != nil {
+		return 0, err
+	}
+	return va.Compare(vb), nil
+}
+
+// parseReleaseVersion parses a release tag, which is a Semver after a "v".
+func parseReleaseVersion(s string) (Semver, error) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(s), "v")
+	if !ok {
+		return Semver{}, fmt.Errorf("%q: %w", s, errNotAVersion)
+	}
+	return ParseSemver(rest)
+}
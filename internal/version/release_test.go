@@ -0,0 +1,149 @@
+package version
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fakeFetcher returns a fixed release, or err, and counts its calls.
+type fakeFetcher struct {
+	release Release
+	err     error
+	calls   int
+}
+
+func (f *fakeFetcher) LatestRelease(context.Context) (Release, error) {
+	f.calls++
+	return f.release, f.err
+}
+
+func TestCompareVersions(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		want int
+	}{
+		{"v0.3.1", "v0.3.1", 0},
+		{"v0.3.1", "v0.4.0", -1},
+		{"v0.10.0", "v0.9.9", 1},
+		{"v1.2", "v1.2.0", 0},
+		{"v1.0.0-rc1", "v1.0.0", -1},
+		{"v1.0.0-rc2", "v1.0.0-rc1", 1},
+		{"v1.0.0+darwin", "v1.0.0", 0},
+	} {
+		got, err := compareVersions(tc.a, tc.b)
+		if err != nil || got != tc.want {
+			t.Errorf("compareVersions(%q, %q) = %d, %v; want %d", tc.a, tc.b, got, err, tc.want)
+		}
+	}
+	for _, bad := range []string{"", "main", "0.3.1", "v1.x", "v1.2.3.4"} {
+		if _, err := compareVersions(bad, "v1.0.0"); !errors.Is(err, errNotAVersion) {
+			t.Errorf("compareVersions(%q, ...) error = %v, want errNotAVersion", bad, err)
+		}
+	}
+}
+
+func TestCheckForUpdate(t *testing.T) {
+	latest := Release{Tag: "v0.4.0", URL: "https://github.com/banksean/sand/releases/tag/v0.4.0"}
+	for _, tc := range []struct {
+		name    string
+		current Info
+		want    UpdateCheck
+	}{
+		{
+			name:    "older release",
+			current: Info{GitBranch: "v0.3.1"},
+			want:    UpdateCheck{Current: "v0.3.1", Latest: latest, Comparable: true, UpdateAvailable: true},
+		},
+		{
+			name:    "latest release",
+			current: Info{GitBranch: "v0.4.0"},
+			want:    UpdateCheck{Current: "v0.4.0", Latest: latest, Comparable: true},
+		},
+		{
+			name:    "dev build",
+			current: Info{GitBranch: "v0.3.1", DevBuild: true},
+			want:    UpdateCheck{Current: "v0.3.1", Latest: latest},
+		},
+		{
+			name:    "branch build",
+			current: Info{GitBranch: "main"},
+			want:    UpdateCheck{Current: "main", Latest: latest},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := CheckForUpdate(context.Background(), tc.current, &fakeFetcher{release: latest})
+			if err != nil {
+				t.Fatalf("CheckForUpdate() error = %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("CheckForUpdate() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+
+	offline := errors.New("dial tcp: no route to host")
+	if _, err := CheckForUpdate(context.Background(), Info{GitBranch: "v0.3.1"}, &fakeFetcher{err: offline}); !errors.Is(err, offline) {
+		t.Fatalf("CheckForUpdate() error = %v, want %v", err, offline)
+	}
+}
+
+func TestCachedReleaseFetcher(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	inner := &fakeFetcher{release: Release{Tag: "v0.4.0"}}
+	f := &CachedReleaseFetcher{
+		Fetcher: inner,
+		Path:    filepath.Join(t.TempDir(), "cache", "latest-release.json"),
+		TTL:     time.Hour,
+		Now:     func() time.Time { return now },
+	}
+	ctx := context.Background()
+
+	for range 2 {
+		if got, err := f.LatestRelease(ctx); err != nil || got.Tag != "v0.4.0" {
+			t.Fatalf("LatestRelease() = %+v, %v; want v0.4.0", got, err)
+		}
+	}
+	if inner.calls != 1 {
+		t.Fatalf("fetched %d times within the TTL, want 1", inner.calls)
+	}
+
+	now = now.Add(2 * time.Hour)
+	inner.release = Release{Tag: "v0.5.0"}
+	if got, err := f.LatestRelease(ctx); err != nil || got.Tag != "v0.5.0" {
+		t.Fatalf("LatestRelease() after the TTL = %+v, %v; want v0.5.0", got, err)
+	}
+
+	now = now.Add(2 * time.Hour)
+	inner.err = errors.New("offline")
+	if _, err := f.LatestRelease(ctx); !errors.Is(err, inner.err) {
+		t.Fatalf("LatestRelease() error = %v, want the fetch error", err)
+	}
+}
+
+func TestGitHubReleaseFetcher(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/latest" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"tag_name":"v0.4.0","html_url":"https://github.com/banksean/sand/releases/tag/v0.4.0","name":"ignored"}`))
+	}))
+	defer srv.Close()
+
+	f := &GitHubReleaseFetcher{URL: srv.URL + "/latest", Client: srv.Client()}
+	got, err := f.LatestRelease(context.Background())
+	want := Release{Tag: "v0.4.0", URL: "https://github.com/banksean/sand/releases/tag/v0.4.0"}
+	if err != nil || got != want {
+		t.Fatalf("LatestRelease() = %+v, %v; want %+v", got, err, want)
+	}
+
+	f.URL = srv.URL + "/missing"
+	if _, err := f.LatestRelease(context.Background()); err == nil {
+		t.Fatal("LatestRelease() error = nil for a 404")
+	}
+}
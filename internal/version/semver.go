@@ -0,0 +1,70 @@
+package version
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Semver is a MAJOR.MINOR.PATCH[-PRERELEASE] version number.
+type Semver struct {
+	Parts      [3]int
+	Prerelease string
+}
+
+var errNotAVersion = errors.New("not a release version")
+
+// ParseSemver parses MAJOR[.MINOR[.PATCH]][-PRERELEASE][+BUILD], with no
+// leading "v". A missing minor or patch number is 0, and build metadata is
+// dropped.
+func ParseSemver(s string) (Semver, error) {
+	var v Semver
+	rest, _, _ := strings.Cut(strings.TrimSpace(s), "+")
+	rest, v.Prerelease, _ = strings.Cut(rest, "-")
+	fields := strings.Split(rest, ".")
+	if len(fields) > len(v.Parts) {
+		return v, fmt.Errorf("%q: %w", s, errNotAVersion)
+	}
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return v, fmt.Errorf("%q: %w", s, errNotAVersion)
+		}
+		v.Parts[i] = n
+	}
+	return v, nil
+}
+
+// Compare returns -1, 0 or 1 as v sorts before, with or after w. A
+// prerelease sorts before its release.
+func (v Semver) Compare(w Semver) int {
+	for i := range v.Parts {
+		if v.Parts[i] != w.Parts[i] {
+			if v.Parts[i] < w.Parts[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case v.Prerelease == w.Prerelease:
+		return 0
+	case v.Prerelease == "":
+		return 1
+	case w.Prerelease == "":
+		return -1
+	case v.Prerelease < w.Prerelease:
+		return -1
+	default:
+		return 1
+	}
+}
+
+func (v Semver) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Parts[0], v.Parts[1], v.Parts[2])
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	return s
+}
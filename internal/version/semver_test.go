@@ -0,0 +1,37 @@
+package version
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseSemver(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want Semver
+	}{
+		{"1.1.0", Semver{Parts: [3]int{1, 1, 0}}},
+		{"1.2", Semver{Parts: [3]int{1, 2, 0}}},
+		{"0.4.0-rc1", Semver{Parts: [3]int{0, 4, 0}, Prerelease: "rc1"}},
+		{"2.0.0+darwin", Semver{Parts: [3]int{2, 0, 0}}},
+	} {
+		got, err := ParseSemver(tc.in)
+		if err != nil || got != tc.want {
+			t.Errorf("ParseSemver(%q) = %+v, %v; want %+v", tc.in, got, err, tc.want)
+		}
+	}
+	for _, bad := range []string{"", "v1.0.0", "1.x", "1.2.3.4", "-1.0.0"} {
+		if _, err := ParseSemver(bad); !errors.Is(err, errNotAVersion) {
+			t.Errorf("ParseSemver(%q) error = %v, want errNotAVersion", bad, err)
+		}
+	}
+}
+
+func TestSemverString(t *testing.T) {
+	if got := (Semver{Parts: [3]int{1, 1, 0}}).String(); got != "1.1.0" {
+		t.Errorf("String() = %q, want 1.1.0", got)
+	}
+	if got := (Semver{Parts: [3]int{1, 0, 0}, Prerelease: "rc1"}).String(); got != "1.0.0-rc1" {
+		t.Errorf("String() = %q, want 1.0.0-rc1", got)
+	}
+}